@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/attestation"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+func attestCmd() *cobra.Command {
+	var (
+		routesFile string
+		txFile     string
+		keyFile    string
+		outputFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "attest",
+		Short: "Sign a digest of the routes file and generated transaction with the operator's key",
+		Long: `Create an attestation binding a routes file to the transaction generated from it, signed with
+the operator's ed25519 key. Signers check it with 'verify --attestation' before signing, giving a
+verifiable chain of custody from parse to the signature ceremony.
+
+The transaction is identified by the SHA-256 of its body, which stays the same once signatures are added.
+Create an operator key with 'attest keygen'.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			key, err := attestation.LoadKey(keyFile)
+			if err != nil {
+				return err
+			}
+
+			routesData, err := os.ReadFile(routesFile)
+			if err != nil {
+				return fmt.Errorf("failed to read routes file: %w", err)
+			}
+			txData, err := output.ReadFile(txFile)
+			if err != nil {
+				return fmt.Errorf("failed to read transaction file: %w", err)
+			}
+
+			a, err := attestation.Sign(key, routesData, txData)
+			if err != nil {
+				return fmt.Errorf("failed to create attestation: %w", err)
+			}
+			if err := a.Save(outputFile); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "Routes digest:           %s\n", a.RoutesDigest)
+			fmt.Fprintf(out, "Transaction body digest: %s\n", a.TxDigest)
+			fmt.Fprintf(out, "Operator:                %s\n", a.Operator)
+			fmt.Fprintf(out, "Attestation saved to %s\n", outputFile)
+			out.emit(a)
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&routesFile, "routes", "routes.json", "Routes file the transaction was generated from")
+	cmd.Flags().StringVar(&txFile, "transaction", "unsigned-tx.json", "Generated transaction file")
+	cmd.Flags().StringVar(&keyFile, "key", "", "Operator key file (required)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "attestation.json", "Output file for the attestation")
+
+	cmd.MarkFlagRequired("key")
+	stepInputFlag(cmd, "routes", "transaction")
+	outputFlag(cmd, "output")
+
+	cmd.AddCommand(attestKeygenCmd())
+
+	return cmd
+}
+
+func attestKeygenCmd() *cobra.Command {
+	var outputFile string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Create an operator key for attestations",
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			if _, err := os.Stat(outputFile); err == nil {
+				return fmt.Errorf("%s already exists, refusing to overwrite it", outputFile)
+			}
+
+			key, err := attestation.GenerateKey()
+			if err != nil {
+				return err
+			}
+			if err := attestation.SaveKey(key, outputFile); err != nil {
+				return err
+			}
+
+			publicKey := hex.EncodeToString(key.Public().(ed25519.PublicKey))
+			fmt.Fprintf(out, "Operator key saved to %s\n", outputFile)
+			fmt.Fprintf(out, "Public key (share with signers): %s\n", publicKey)
+			out.emit(map[string]string{"key_file": outputFile, "public_key": publicKey})
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "operator.key", "Output file for the operator key")
+	outputFlag(cmd, "output")
+
+	return cmd
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/watcher"
+	"github.com/spf13/cobra"
+)
+
+func backfillCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Parse long height ranges with several workers sharing the state database",
+		Long: `Split a long height range into shards and parse them with several workers, in one process or on
+several hosts sharing a PostgreSQL state database, so backfills of months of history finish in hours.
+
+plan records the shards of a job, run starts workers that claim and parse them until the job is done,
+and status shows the progress. A worker claims the lowest unfinished shard for a lease it extends after
+every step; the shard of a worker that stops is taken over by another once the lease expires. Deposits
+are recorded as processed, as with watch, so none is routed twice.`,
+	}
+
+	cmd.AddCommand(backfillPlanCmd(), textOnly(backfillRunCmd()), backfillStatusCmd())
+
+	return cmd
+}
+
+func backfillPlanCmd() *cobra.Command {
+	var (
+		job        string
+		fromHeight int64
+		toHeight   int64
+		shardSize  int64
+		stateOpts  stateOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Split a height range into the shards of a backfill job",
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			ctx := cmd.Context()
+			store, err := openShardStore(ctx, stateOpts)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			shards, err := watcher.PlanShards(ctx, store, job, fromHeight, toHeight, shardSize)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "✓ Job %s: heights %d to %d in %d shards of up to %d heights\n", job, fromHeight, toHeight, len(shards), shards[0].ToHeight-shards[0].FromHeight+1)
+			out.emit(shards)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&job, "job", "", "Name of the backfill job (required)")
+	cmd.Flags().Int64Var(&fromHeight, "from-height", 0, "First height of the range (required)")
+	cmd.Flags().Int64Var(&toHeight, "to-height", 0, "Last height of the range (required)")
+	cmd.Flags().Int64Var(&shardSize, "shard-size", watcher.DefaultShardBlocks, "Heights per shard")
+	addShardStoreFlags(cmd, &stateOpts)
+	cmd.MarkFlagRequired("job")
+	cmd.MarkFlagRequired("from-height")
+	cmd.MarkFlagRequired("to-height")
+
+	return cmd
+}
+
+func backfillRunCmd() *cobra.Command {
+	var (
+		job          string
+		workerName   string
+		workers      int
+		multisigAddr string
+		rpcURL       string
+		configFile   string
+		source       string
+		lease        time.Duration
+		maxBlocks    int64
+		outputFile   string
+		metricsAddr  string
+		stateOpts    stateOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Claim and parse the shards of a backfill job until it is done",
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			if workers <= 0 {
+				return fmt.Errorf("--workers must be positive")
+			}
+			config := &types.Config{Chain: types.DefaultChainConfig(), Query: types.DefaultQueryConfig()}
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+			var sourceName string
+			if source != "" {
+				sourceConfig, src, err := selectSource(config, source, &multisigAddr)
+				if err != nil {
+					return err
+				}
+				config = sourceConfig
+				if !cmd.Flags().Changed("rpc-url") && src.RPCURL != "" {
+					rpcURL = src.RPCURL
+				}
+				sourceName = src.Name
+			}
+			if multisigAddr == "" {
+				return fmt.Errorf("--multisig-address is required")
+			}
+			// Routes are tagged like those of watch, so its routes file lists them
+			if sourceName == "" {
+				sourceName = multisigAddr
+			}
+			if err := config.Chain.ValidateAddress(multisigAddr); err != nil {
+				return fmt.Errorf("invalid multisig address: %w", err)
+			}
+			if workerName == "" {
+				host, _ := os.Hostname()
+				workerName = fmt.Sprintf("%s-%d", host, os.Getpid())
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := serveMetrics(ctx, out, metricsAddr); err != nil {
+				return err
+			}
+
+			store, err := openShardStore(ctx, stateOpts)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			fmt.Fprintf(out, "Backfilling job %s with %d workers from %s...\n", job, workers, rpcURL)
+			var (
+				wg   sync.WaitGroup
+				mu   sync.Mutex
+				errs = make([]error, workers)
+			)
+			for i := 0; i < workers; i++ {
+				p, err := newParser(ctx, rpcURL, config)
+				if err != nil {
+					return fmt.Errorf("failed to create parser: %w", err)
+				}
+				defer p.Close()
+
+				name := workerName
+				if workers > 1 {
+					name = fmt.Sprintf("%s-%d", workerName, i+1)
+				}
+				b, err := watcher.NewBackfill(p, store, watcher.BackfillConfig{
+					Job:          job,
+					Worker:       name,
+					MultisigAddr: multisigAddr,
+					Source:       sourceName,
+					Lease:        lease,
+					MaxBlocks:    maxBlocks,
+				})
+				if err != nil {
+					return err
+				}
+				b.OnStep = func(step watcher.Step) {
+					mu.Lock()
+					defer mu.Unlock()
+					fmt.Fprintf(out, "[%s] heights %d to %d of shard %d-%d: %d new routes\n", name, step.FromHeight, step.ToHeight, step.Shard.FromHeight, step.Shard.ToHeight, len(step.Routes))
+					for _, s := range step.Skipped {
+						fmt.Fprintf(out, "  ⚠ skipped tx %s (height %d, amount %s): %s\n", s.TxHash, s.BlockHeight, s.Amount, s.Reason)
+					}
+					if len(step.Replays) > 0 {
+						holdReplays(out, config, step.Replays)
+					}
+				}
+				b.OnError = func(err error) {
+					fmt.Fprintf(os.Stderr, "⚠ [%s] %v\n", name, err)
+				}
+
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = b.Run(ctx)
+				}(i)
+			}
+			wg.Wait()
+
+			for _, err := range errs {
+				if err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(out, "✓ Job %s done\n", job)
+
+			if outputFile != "" {
+				pending, err := writePendingRoutes(context.Background(), store, sourceName, multisigAddr, outputFile)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%d routes waiting to be generated saved to %s\n", len(pending.Routes), outputFile)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&job, "job", "", "Name of the backfill job (required)")
+	cmd.Flags().StringVar(&workerName, "worker", "", "Unique worker name (default: <hostname>-<pid>)")
+	cmd.Flags().IntVar(&workers, "workers", 1, "Workers to run in this process")
+	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig address to parse deposits to (required unless --source is set)")
+	cmd.Flags().StringVar(&rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL, or a CometBFT RPC URL such as http://localhost:26657")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file for whitelisting and query limits")
+	cmd.Flags().StringVar(&source, "source", "", "Name of a source chain from the config's sources list")
+	cmd.Flags().DurationVar(&lease, "lease", watcher.DefaultLease, "How long a claimed shard is kept without progress before other workers take it over")
+	cmd.Flags().Int64Var(&maxBlocks, "max-blocks", watcher.DefaultMaxBlocks, "Heights parsed per step")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Optional file to write the routes waiting to be generated to once the job is done")
+	addMetricsFlag(cmd, &metricsAddr)
+	addShardStoreFlags(cmd, &stateOpts)
+	cmd.MarkFlagRequired("job")
+	outputFlag(cmd, "output")
+
+	return cmd
+}
+
+func backfillStatusCmd() *cobra.Command {
+	var (
+		job       string
+		stateOpts stateOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the progress of a backfill job",
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			ctx := cmd.Context()
+			store, err := openShardStore(ctx, stateOpts)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			shards, err := store.Shards(ctx, job)
+			if err != nil {
+				return err
+			}
+			if len(shards) == 0 {
+				return fmt.Errorf("backfill job %s has no shards", job)
+			}
+
+			var total, parsed int64
+			done := 0
+			now := time.Now()
+			for _, shard := range shards {
+				total += shard.ToHeight - shard.FromHeight + 1
+				parsed += shard.Progress - shard.FromHeight + 1
+				switch {
+				case shard.Done():
+					done++
+				case shard.Worker != "" && shard.LeaseUntil.After(now):
+					fmt.Fprintf(out, "  shard %d-%d: at %d, claimed by %s until %s\n", shard.FromHeight, shard.ToHeight, shard.Progress, shard.Worker, shard.LeaseUntil.Format(time.RFC3339))
+				case shard.Worker != "":
+					fmt.Fprintf(out, "  ⚠ shard %d-%d: at %d, lease of %s expired\n", shard.FromHeight, shard.ToHeight, shard.Progress, shard.Worker)
+				default:
+					fmt.Fprintf(out, "  shard %d-%d: at %d, unclaimed\n", shard.FromHeight, shard.ToHeight, shard.Progress)
+				}
+			}
+			fmt.Fprintf(out, "Job %s: %d of %d shards done, %d of %d heights parsed (%.1f%%)\n", job, done, len(shards), parsed, total, 100*float64(parsed)/float64(total))
+			out.emit(shards)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&job, "job", "", "Name of the backfill job (required)")
+	addShardStoreFlags(cmd, &stateOpts)
+	cmd.MarkFlagRequired("job")
+
+	return cmd
+}
+
+// addShardStoreFlags registers the flags of the state database backfill workers share
+func addShardStoreFlags(cmd *cobra.Command, opts *stateOptions) {
+	cmd.Flags().StringVar(&opts.path, "state", "rebalancer.db", "SQLite database shared by the workers on this host")
+	cmd.Flags().StringVar(&opts.postgresDSN, "postgres-dsn", "", "PostgreSQL database shared by workers on several hosts")
+}
+
+// openShardStore opens the state database backfill workers coordinate through
+func openShardStore(ctx context.Context, opts stateOptions) (watcher.ShardStore, error) {
+	store, err := openStorage(ctx, opts.path, opts.postgresDSN)
+	if err != nil {
+		return nil, err
+	}
+	shardStore, ok := store.(watcher.ShardStore)
+	if !ok {
+		store.Close()
+		return nil, fmt.Errorf("%T cannot coordinate backfill workers", store)
+	}
+	return shardStore, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/notify"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+)
+
+// broadcastOptions are the settings shared by broadcast and verify --broadcast
+type broadcastOptions struct {
+	rpcURL   string
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func broadcastCmd() *cobra.Command {
+	var (
+		txFile     string
+		configFile string
+		opts       broadcastOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "broadcast",
+		Short: "Submit a fully signed transaction and wait for its inclusion",
+		Long: `Submit a fully signed multisig transaction (the Cosmos SDK JSON written by celestia-appd tx multisign)
+through the tx service's BroadcastTx, wait until it is included in a block and report the result.
+
+The transaction must carry a signature for every signer. A transaction rejected by the node or failed
+on-chain exits with an error and raises a critical notification through the configured notifiers.
+Run verify on the signed transaction first, or use verify --broadcast to do both in one step.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config := &types.Config{}
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+			n, err := notify.FromConfig(config.Notify)
+			if err != nil {
+				return err
+			}
+
+			resp, err := broadcastFile(cmd.Context(), out, txFile, opts)
+			if err != nil {
+				event := notify.Event{
+					Severity: notify.SeverityCritical,
+					Title:    "Rebalancing transaction broadcast failed",
+					Message:  err.Error(),
+					Fields:   map[string]string{"transaction": txFile},
+				}
+				if nerr := notify.Send(context.Background(), n, event); nerr != nil {
+					fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", nerr)
+				}
+				return err
+			}
+
+			fmt.Fprintf(out, "\nRecord the dispatched messages with:\n  celestia-rebalancer track --tx-hash %s\n", resp.TxHash)
+			out.emit(resp)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&txFile, "transaction", "signed-tx.json", "Fully signed transaction file")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file with notification settings")
+	cmd.Flags().StringVar(&opts.rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL")
+	addBroadcastFlags(cmd, &opts)
+	stepInputFlag(cmd, "transaction")
+
+	return cmd
+}
+
+// addBroadcastFlags registers the inclusion wait flags of broadcastOptions on cmd
+func addBroadcastFlags(cmd *cobra.Command, opts *broadcastOptions) {
+	cmd.Flags().DurationVar(&opts.timeout, "broadcast-timeout", time.Minute, "How long to wait for the transaction to be included")
+	cmd.Flags().DurationVar(&opts.interval, "poll-interval", 2*time.Second, "How often to check whether the transaction was included")
+}
+
+// broadcastFile submits the signed transaction in txFile, waits for its inclusion and prints the
+// result. An included transaction that failed is returned with an error.
+func broadcastFile(ctx context.Context, out io.Writer, txFile string, opts broadcastOptions) (*sdk.TxResponse, error) {
+	data, err := output.ReadFile(txFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction file: %w", err)
+	}
+	signedTx, err := generator.UnmarshalTxJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction file: %w", err)
+	}
+	txBytes, err := generator.EncodeSignedTx(signedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := dialChain(ctx, opts.rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	fmt.Fprintf(out, "Broadcasting %s to %s...\n", txFile, opts.rpcURL)
+	resp, err := c.BroadcastTx(txBytes)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(out, "✓ Accepted by the node as tx %s, waiting for inclusion...\n", resp.TxHash)
+
+	included, err := c.WaitForTx(resp.TxHash, opts.timeout, opts.interval)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.TxFailed(included); err != nil {
+		return nil, fmt.Errorf("included at height %d but %w", included.Height, err)
+	}
+
+	fmt.Fprintf(out, "✓ Tx %s included at height %d (gas used %d of %d)\n", included.TxHash, included.Height, included.GasUsed, included.GasWanted)
+	return included, nil
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/attestation"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/bundle"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/verifier"
+	"github.com/spf13/cobra"
+)
+
+// reportName is the name of the verification report inside a bundle
+const reportName = "verification.json"
+
+func bundleCmd() *cobra.Command {
+	var (
+		routesFile      string
+		txFiles         []string
+		batchManifest   string
+		attestationFile string
+		configFile      string
+		outputFile      string
+		strict          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Package the artifacts of a signing ceremony into a single verifiable archive",
+		Long: `Package the routes file, the unsigned transaction (or the batch sign docs and their manifest),
+the attestation and a fresh verification report into a tar.gz with a manifest of SHA-256 digests.
+The config file is not bundled since it may hold secrets; only its digest is recorded.
+
+Bundling fails if the transactions do not match the routes. Signers check a bundle with 'bundle verify'.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			v := verifier.NewVerifier()
+			configDigest := ""
+			if configFile != "" {
+				config, err := types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+				v = newVerifier(config)
+				if configDigest, err = fileDigest(configFile); err != nil {
+					return err
+				}
+			}
+			v.SetStrict(strict)
+			v.SetOutput(out)
+
+			routesData, err := os.ReadFile(routesFile)
+			if err != nil {
+				return fmt.Errorf("failed to read routes file: %w", err)
+			}
+			entries := []bundle.Entry{{Name: filepath.Base(routesFile), Kind: bundle.KindRoutes, Data: routesData}}
+
+			// Batch sign docs are taken from their manifest
+			if batchManifest != "" {
+				if cmd.Flags().Changed("transaction") {
+					return fmt.Errorf("--transaction and --batch-manifest are mutually exclusive")
+				}
+				manifest, err := generator.LoadBatchManifest(batchManifest)
+				if err != nil {
+					return err
+				}
+				data, err := os.ReadFile(batchManifest)
+				if err != nil {
+					return fmt.Errorf("failed to read batch manifest: %w", err)
+				}
+				entries = append(entries, bundle.Entry{Name: filepath.Base(batchManifest), Kind: bundle.KindBatchManifest, Data: data})
+
+				txFiles = nil
+				for _, batch := range manifest.Batches {
+					if !batch.Dropped {
+						txFiles = append(txFiles, batch.File)
+					}
+				}
+			}
+
+			// Transactions are bundled as written, possibly encrypted, and verified decrypted
+			var transactions []bundle.Entry
+			for _, txFile := range txFiles {
+				data, err := os.ReadFile(txFile)
+				if err != nil {
+					return fmt.Errorf("failed to read transaction file: %w", err)
+				}
+				entry := bundle.Entry{Name: filepath.Base(txFile), Kind: bundle.KindTransaction, Data: data}
+				entries = append(entries, entry)
+
+				if entry.Data, err = output.Decrypt(txFile, data); err != nil {
+					return err
+				}
+				transactions = append(transactions, entry)
+			}
+			if len(transactions) == 0 {
+				return fmt.Errorf("no transactions to bundle")
+			}
+
+			report, err := bundle.VerifyContents(v, routesData, transactions)
+			if err != nil {
+				return err
+			}
+			v.PrintResult(report.Result)
+			if !report.Result.Valid {
+				return fmt.Errorf("transactions do not match the routes, refusing to bundle them")
+			}
+			reportData, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal verification report: %w", err)
+			}
+			entries = append(entries, bundle.Entry{Name: reportName, Kind: bundle.KindVerification, Data: reportData})
+
+			if attestationFile != "" {
+				a, err := attestation.Load(attestationFile)
+				if err != nil {
+					return err
+				}
+				if err := checkBundledAttestation(a, routesData, transactions, nil); err != nil {
+					return err
+				}
+				data, err := os.ReadFile(attestationFile)
+				if err != nil {
+					return fmt.Errorf("failed to read attestation: %w", err)
+				}
+				entries = append(entries, bundle.Entry{Name: filepath.Base(attestationFile), Kind: bundle.KindAttestation, Data: data})
+			}
+
+			manifest, err := bundle.Write(outputFile, entries, configDigest, time.Now())
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "\nBundled %d files:\n", len(manifest.Files))
+			for _, file := range manifest.Files {
+				fmt.Fprintf(out, "  %-16s %s  %s\n", file.Kind, file.SHA256, file.Name)
+			}
+			if configDigest != "" {
+				fmt.Fprintf(out, "  %-16s %s  %s\n", "config", configDigest, filepath.Base(configFile)+" (digest only)")
+			}
+			fmt.Fprintf(out, "Bundle saved to %s\n", outputFile)
+			out.emit(manifest)
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&routesFile, "routes", "routes.json", "Routes file the transactions were generated from")
+	cmd.Flags().StringArrayVar(&txFiles, "transaction", []string{"unsigned-tx.json"}, "Unsigned transaction file (repeatable)")
+	cmd.Flags().StringVar(&batchManifest, "batch-manifest", "", "Batch manifest whose sign docs are bundled instead of --transaction")
+	cmd.Flags().StringVar(&attestationFile, "attestation", "", "Optional operator attestation to include")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file whose digest is recorded and whose authz grant is checked")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "signing-bundle.tar.gz", "Output file for the bundle")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail on any message not accounted for by a route, as verify --strict")
+	stepInputFlag(cmd, "routes")
+	outputFlag(cmd, "output")
+
+	cmd.AddCommand(bundleVerifyCmd())
+
+	return cmd
+}
+
+func bundleVerifyCmd() *cobra.Command {
+	var (
+		bundleFile   string
+		configFile   string
+		operatorKeys []string
+		extractDir   string
+		strict       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check a signing bundle's digests and re-verify its transactions against its routes",
+		Long: `Check that a signing bundle holds exactly the files listed in its manifest with matching digests,
+then re-run the verification of the bundled transactions against the bundled routes rather than
+trusting the bundled report. With --config, the config's digest must match the one recorded in
+the bundle. With --output-dir, the files are extracted once all checks pass.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			b, err := bundle.Open(bundleFile)
+			if err != nil {
+				return err
+			}
+
+			v := verifier.NewVerifier()
+			configDigest := ""
+			if configFile != "" {
+				config, err := types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+				v = newVerifier(config)
+				if configDigest, err = fileDigest(configFile); err != nil {
+					return err
+				}
+			}
+			v.SetStrict(strict)
+			v.SetOutput(out)
+
+			if err := b.Verify(configDigest); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "✓ %d files match the bundle manifest (created %s)\n", len(b.Manifest.Files), b.Manifest.CreatedAt.Format(time.RFC3339))
+			if configDigest != "" {
+				fmt.Fprintln(out, "✓ Config digest matches the bundle")
+			} else if b.Manifest.ConfigSHA256 != "" {
+				fmt.Fprintln(out, "  ⚠ no --config given, the config digest was not checked")
+			}
+
+			routes := b.Files(bundle.KindRoutes)
+			if len(routes) != 1 {
+				return fmt.Errorf("bundle holds %d routes files, want 1", len(routes))
+			}
+			var transactions []bundle.Entry
+			for _, entry := range b.Files(bundle.KindTransaction) {
+				if entry.Data, err = output.Decrypt(entry.Name, entry.Data); err != nil {
+					return err
+				}
+				transactions = append(transactions, entry)
+			}
+
+			fmt.Fprintf(out, "\nVerifying bundled transactions against routes...\n\n")
+			report, err := bundle.VerifyContents(v, routes[0].Data, transactions)
+			if err != nil {
+				return err
+			}
+			v.PrintResult(report.Result)
+			out.emit(report)
+			if !report.Result.Valid {
+				return fmt.Errorf("bundled transactions do not match the bundled routes, do not sign them")
+			}
+
+			for _, entry := range b.Files(bundle.KindAttestation) {
+				var a attestation.Attestation
+				if err := json.Unmarshal(entry.Data, &a); err != nil {
+					return fmt.Errorf("failed to parse attestation %s: %w", entry.Name, err)
+				}
+				if err := checkBundledAttestation(&a, routes[0].Data, transactions, operatorKeys); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "✓ Attestation by operator %s matches the bundled routes and transaction\n", a.Operator)
+				if len(operatorKeys) == 0 {
+					fmt.Fprintln(out, "  ⚠ no --operator-key given, the operator's identity was not checked")
+				}
+			}
+
+			if extractDir != "" {
+				if err := b.Extract(extractDir); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "\nBundle extracted to %s\n", extractDir)
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&bundleFile, "bundle", "signing-bundle.tar.gz", "Bundle to verify")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file expected to match the bundled config digest")
+	cmd.Flags().StringArrayVar(&operatorKeys, "operator-key", nil, "Trusted operator public key (hex) for the bundled attestation (repeatable)")
+	cmd.Flags().StringVar(&extractDir, "output-dir", "", "Extract the bundled files to this directory after verification")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail on any message not accounted for by a route, as verify --strict")
+	stepInputFlag(cmd, "bundle")
+
+	return cmd
+}
+
+// checkBundledAttestation checks that the attestation covers the routes and one of the transactions
+func checkBundledAttestation(a *attestation.Attestation, routesData []byte, transactions []bundle.Entry, operatorKeys []string) error {
+	var err error
+	for _, entry := range transactions {
+		if err = a.Verify(routesData, entry.Data, operatorKeys); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("attestation does not match the bundled routes and transactions: %w", err)
+}
+
+// fileDigest returns the SHA-256 of a file's contents
+func fileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return attestation.Digest(data), nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/parser"
+)
+
+// checkpointFile returns the checkpoint file of a parse run writing its routes to outputFile
+func checkpointFile(outputFile string) string {
+	return siblingFile(outputFile, "checkpoint")
+}
+
+// saveCheckpoint returns a checkpoint function replacing the file at path with every checkpoint
+func saveCheckpoint(path string) parser.CheckpointFunc {
+	return func(cp *parser.Checkpoint) error {
+		data, err := json.MarshalIndent(cp, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal checkpoint: %w", err)
+		}
+		return output.WriteAtomic(path, data, 0644)
+	}
+}
+
+// loadCheckpoint reads the checkpoint at path, returning nil if there is none
+func loadCheckpoint(path string) (*parser.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var cp parser.Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// removeCheckpoint deletes the checkpoint at path once its run has completed
+func removeCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// Report formats of --format
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// addFormatFlag registers --format on cmd, describing what the csv format adds
+func addFormatFlag(cmd *cobra.Command, format *string, csvUsage string) {
+	cmd.Flags().StringVar(format, "format", formatJSON, "Report format: json, or csv to also write "+csvUsage)
+}
+
+// checkFormat checks the value of --format
+func checkFormat(format string) error {
+	if format != formatJSON && format != formatCSV {
+		return fmt.Errorf("invalid --format %q: must be %s or %s", format, formatJSON, formatCSV)
+	}
+	return nil
+}
+
+// csvFile names the CSV file written next to path, e.g. routes.csv next to routes.json
+func csvFile(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".csv"
+}
+
+// writeCSVFile writes the CSV that write produces to path
+func writeCSVFile(path string, write func(io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+	if err := output.WriteAtomic(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// routesCSV returns a function writing routes as CSV, all with status
+func routesCSV(routes *types.Routes, status string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		return types.WriteRoutesCSV(w, routes.Routes, func(int) string { return status })
+	}
+}
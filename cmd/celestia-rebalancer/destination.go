@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/destination"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// checkDestinations runs the configured checks (ISM, collateral, delivery gas) against the
+// destination chains of the generated transfers and prints a warning for every problem found.
+// Checks that cannot be completed are reported as warnings too, since they must not block generation.
+func checkDestinations(ctx context.Context, out io.Writer, routes *types.Routes, msgs []sdk.Msg, config *types.Config) []string {
+	var transfers []*warptypes.MsgRemoteTransfer
+	for _, msg := range msgs {
+		if transfer, ok := msg.(*warptypes.MsgRemoteTransfer); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	var warnings []string
+	for _, domain := range routeDomains(routes) {
+		dest, ok := config.Destinations[domain]
+		if !ok {
+			continue
+		}
+
+		warning, err := destination.CheckISM(ctx, domain, dest)
+		if err != nil {
+			warning = fmt.Sprintf("domain %d: could not check ISM: %v", domain, err)
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+
+		collateral, err := destination.CheckCollateral(ctx, domain, dest, routes.Routes)
+		if err != nil {
+			collateral = []string{fmt.Sprintf("domain %d: could not check collateral: %v", domain, err)}
+		}
+		warnings = append(warnings, collateral...)
+
+		gas, err := destination.CheckDeliveryGas(ctx, domain, config.Chain.Domain, dest, transfers)
+		if err != nil {
+			gas = []string{fmt.Sprintf("domain %d: could not estimate delivery gas: %v", domain, err)}
+		}
+		warnings = append(warnings, gas...)
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintf(out, "⚠ %s\n", w)
+	}
+	return warnings
+}
+
+// checkDecimals compares the scale of each destination of routes with the decimals of the token its
+// router pays out and prints a warning for every mismatch. Checks that cannot be completed are
+// reported as warnings too, so a policy failing on mismatches fails closed.
+func checkDecimals(ctx context.Context, out io.Writer, routes *types.Routes, config *types.Config) []string {
+	var warnings []string
+	for _, domain := range routeDomains(routes) {
+		warning, err := destination.CheckDecimals(ctx, domain, config.Destinations[domain])
+		if err != nil {
+			warning = fmt.Sprintf("domain %d: could not check decimals: %v", domain, err)
+		}
+		if warning != "" {
+			fmt.Fprintf(out, "⚠ %s\n", warning)
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// routeDomains returns the destination domains of routes in ascending order
+func routeDomains(routes *types.Routes) []uint32 {
+	domains := make(map[uint32]bool)
+	for _, route := range routes.Routes {
+		if route.RouteInfo != nil {
+			domains[route.RouteInfo.DestinationDomain] = true
+		}
+	}
+	var sorted []uint32
+	for domain := range domains {
+		sorted = append(sorted, domain)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
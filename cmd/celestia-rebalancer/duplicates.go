@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// warnDuplicates prints the groups of routes that look like accidental double-sends and returns
+// how many groups were found
+func warnDuplicates(out io.Writer, routes *types.Routes, config types.StrategyConfig) int {
+	window := config.DuplicateWindow()
+	duplicates := strategy.FindDuplicates(routes.Routes, window)
+	for _, d := range duplicates {
+		fmt.Fprintf(out, "⚠ Possible double-send within %d blocks: %s\n", window, d)
+	}
+	return len(duplicates)
+}
+
+// checkDuplicates refuses routes that look like accidental double-sends unless allowed, so an
+// operator reviews them before both deposits are forwarded
+func checkDuplicates(out io.Writer, routes *types.Routes, config types.StrategyConfig, allow bool) error {
+	found := warnDuplicates(out, routes, config)
+	if found == 0 || allow {
+		return nil
+	}
+	return fmt.Errorf("%d groups of possible double-sends need review: quarantine the deposits that should not be forwarded, or pass --allow-duplicates to forward all of them", found)
+}
+
+// warnAmountMismatches prints the routes whose metadata amount differs from the amount deposited,
+// returning their descriptions. Such routes passed the limits but still need an operator's review.
+func warnAmountMismatches(out io.Writer, routes []types.HyperlaneRoute) []string {
+	var mismatches []string
+	for i := range routes {
+		if mismatch := routes[i].AmountMismatch(); mismatch != "" {
+			described := fmt.Sprintf("tx %s: %s", routes[i].TxHash, mismatch)
+			fmt.Fprintf(out, "⚠ Amount mismatch in %s\n", described)
+			mismatches = append(mismatches, described)
+		}
+	}
+	return mismatches
+}
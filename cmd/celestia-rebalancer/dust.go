@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/state"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// holdDust holds back routes below the min_amount of their destination. With a ledger, they are
+// recorded as accumulating and merged with the dust of earlier runs to the same recipient once
+// the total reaches the min_amount; without one they are skipped.
+func holdDust(ctx context.Context, out io.Writer, ledger *state.Ledger, routes *types.Routes, config *types.Config) (*types.Routes, *strategy.Dust, error) {
+	minAmounts := make(map[uint32]math.Int)
+	for domain, dest := range config.Destinations {
+		min, err := dest.DustThreshold()
+		if err != nil {
+			return nil, nil, fmt.Errorf("destination %d: %w", domain, err)
+		}
+		if !min.IsNil() {
+			minAmounts[domain] = min
+		}
+	}
+
+	var pending []types.HyperlaneRoute
+	if ledger != nil {
+		var err error
+		if pending, err = ledger.Accumulating(ctx, routes.MultisigAddr); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(minAmounts) == 0 && len(pending) == 0 {
+		return routes, &strategy.Dust{Kept: routes.Routes}, nil
+	}
+
+	dust, err := strategy.HoldDust(routes.Routes, pending, minAmounts)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, route := range dust.Held {
+		domain := route.RouteInfo.DestinationDomain
+		if ledger != nil {
+			fmt.Fprintf(out, "⚠ Accumulating route from tx %s (%s %s): below the min_amount %s of domain %d\n",
+				route.TxHash, route.Amount, route.Denom, minAmounts[domain], domain)
+		} else {
+			fmt.Fprintf(out, "⚠ Skipping route from tx %s (%s %s): below the min_amount %s of domain %d; use --state to accumulate it\n",
+				route.TxHash, route.Amount, route.Denom, minAmounts[domain], domain)
+		}
+	}
+	if ledger != nil {
+		if err := ledger.RecordAccumulating(ctx, routes.MultisigAddr, dust.Held); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(dust.Held) == 0 && dust.Merged == 0 {
+		return routes, dust, nil
+	}
+
+	result := *routes
+	result.Routes = dust.Kept
+	strategy.RecomputeTotal(&result)
+	return &result, dust, nil
+}
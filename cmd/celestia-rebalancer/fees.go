@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+func feesCmd() *cobra.Command {
+	var (
+		stateOpts  stateOptions
+		since      time.Duration
+		outputFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fees",
+		Short: "Report the fees and interchain gas spent on rebalancing",
+		Long: `Report what running the corridor cost: the fees of the rebalancing transactions track recorded in
+the state database, and the interchain gas (MaxFee) of the Hyperlane messages they dispatched, per
+destination domain. The interchain gas paid is at most the MaxFee, so its total is an upper bound.
+
+Only transactions tracked with --state are counted.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			ctx := cmd.Context()
+			store, err := openStorage(ctx, stateOpts.path, stateOpts.postgresDSN)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var from time.Time
+			if since > 0 {
+				from = time.Now().Add(-since)
+			}
+			report, err := state.Fees(ctx, store, from)
+			if err != nil {
+				return err
+			}
+
+			if outputFile != "" {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal fee report: %w", err)
+				}
+				if err := output.WriteAtomic(outputFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to write fee report: %w", err)
+				}
+			}
+
+			period := "all time"
+			if since > 0 {
+				period = "since " + from.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "Rebalancing costs, %s:\n", period)
+			fmt.Fprintf(out, "  Transactions:   %d (%d messages, %d gas used)\n", report.Transactions, report.Messages, report.GasUsed)
+			fmt.Fprintf(out, "  Fees paid:      %s\n", orNone(report.Fees))
+			fmt.Fprintf(out, "  Interchain gas: at most %s\n", orNone(report.InterchainGas))
+			for _, d := range report.Destinations {
+				fmt.Fprintf(out, "    domain %d: %d messages, at most %s\n", d.Domain, d.Messages, orNone(d.InterchainGas))
+			}
+			if outputFile != "" {
+				fmt.Fprintf(out, "\nFee report saved to %s\n", outputFile)
+			}
+			out.emit(report)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&stateOpts.path, "state", "rebalancer.db", "SQLite database track recorded the transactions in")
+	cmd.Flags().StringVar(&stateOpts.postgresDSN, "postgres-dsn", "", "Read the state from PostgreSQL instead of SQLite")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only count transactions tracked within this long, e.g. 720h (default: all)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Also write the report as JSON to this file")
+	outputFlag(cmd, "output")
+
+	return cmd
+}
+
+// orNone prints an empty coin amount as "none"
+func orNone(coins string) string {
+	if coins == "" {
+		return "none"
+	}
+	return coins
+}
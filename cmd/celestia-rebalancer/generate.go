@@ -0,0 +1,643 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/retry"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/state"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+)
+
+// generateOptions are the flags of generate
+type generateOptions struct {
+	routesFile     string
+	multisigAddrs  []string
+	outputFile     string
+	configFile     string
+	source         string
+	feePayer       string
+	gasLimit       uint64
+	fees           string
+	maxMsgsPerTx   int
+	accountNumber  uint64
+	sequence       uint64
+	rpcURL         string
+	balances       string
+	projectionFile string
+	checkBalance   bool
+	encryptTo      []string
+	overridesFile  string
+	checkDests     bool
+	authzGrantee   string
+	allowDups      bool
+	aggregate      bool
+	state          stateOptions
+	regenerate     bool
+	igpGasLimits   []string
+	igpMaxFees     []string
+	igpQuote       bool
+	failOn         []string
+}
+
+func generateCmd() *cobra.Command {
+	var opts generateOptions
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate unsigned multisig transaction from routes",
+		Long: `Generate an unsigned transaction containing Hyperlane MsgRemoteTransfer messages from parsed routes.
+
+By default the multisig pays the transaction fees. Set --fee-payer (or "fee.payer" in the config file)
+to have a separate account, such as an automation key, pay gas while the multisig authorizes the transfers.
+The fee payer must then sign the transaction in addition to the multisig.
+
+Set --authz-grantee (or "authz.grantee" in the config file) to wrap the transfers in an authz MsgExec
+executed by an operational account the multisig has granted MsgRemoteTransfer to. Only the grantee
+(and a distinct fee payer) then signs.
+
+With --state, routes whose deposits the state database records as already generated or broadcast are
+skipped, and the generated deposits are recorded. If a generated transaction was discarded without
+being broadcast, pass --regenerate to include its deposits again.
+
+Each transfer requests the gas_limit of its destination and pays the IGP at most its max_fee, from
+"destinations" in the config file or --igp-gas-limit and --igp-max-fee. With --igp-quote (or
+"interchain_gas.quote" in the config file), the payment is queried from --rpc-url and used as the
+MaxFee of destinations without a max_fee; a configured max_fee overrides the quote but is refused
+if it does not cover it. The IGP set as "interchain_gas.igp" quotes each destination for its gas
+limit; without it, the warp token's hooks quote their own payment.
+
+With --rpc-url or --balances, the multisig balances after execution are projected, and generation
+fails before anything is written or recorded when the routed amounts, interchain gas and fees exceed
+the multisig's funds. Pass --check-balance to require the projection. With --rpc-url, transfers are
+charged to the denom their warp token takes from the multisig, the locked denom of a collateral token
+or the synthetic token's own denom.
+
+Warnings about recipients never sent to before (with --state), metadata amounts differing from the
+deposit, and destination scales not matching the router token's decimals are printed. List their
+classes in "policy.fail_on" in the config file, or with --fail-on, to fail generation instead:
+first_seen_recipient, amount_override and decimals_mismatch.
+
+Routes are reviewed by the hooks listed in "policy.route_hooks" in the config file before anything is
+generated. A hook can reject a route, lower its amount or annotate it. Rejected routes are saved
+next to --routes with a -rejected suffix. The max_age_blocks hook queries the latest height from
+--rpc-url.
+
+With --aggregate (or "strategy.aggregate" in the config file), routes with the same destination domain,
+recipient, token ID and denom are merged into a single transfer of their summed amount, saving gas and
+signatures when many small deposits go to the same destination. Each merged route records the
+deposits it forwards and their amounts as its provenance in the planned routes, which verify checks
+and lists for signers.
+
+Destinations with "max_in_flight" in the config file take at most that many transfers that were
+dispatched but not yet delivered. With --state, the deliveries track recorded are first confirmed
+against the destination's mailbox, then routes beyond the limit are held for a later run, so a
+congested destination does not pile up transfers.
+
+Routes below the "min_amount" of their destination are not worth their interchain gas and are
+skipped. With --state they accumulate instead: once the deposits to the same recipient, token and
+denom, in this run and held in earlier ones, add up to the min_amount, they are merged into one
+transfer.
+
+For several multisigs, e.g. one per corridor, repeat --multisig-address or list "multisig_addresses"
+in the config file. Each multisig gets its own unsigned transaction from its own routes file, both
+named after --routes and --output with the multisig address appended, as parse writes them.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			base, multisigs, err := opts.configure(cmd, out)
+			if err != nil {
+				return err
+			}
+
+			// Generate one transaction per multisig, each from its own routes
+			several := len(multisigs) > 1
+			for _, multisigAddr := range multisigs {
+				if several {
+					fmt.Fprintf(out, "\n== Multisig %s ==\n", multisigAddr)
+				}
+				run := base
+				run.multisigAddr = multisigAddr
+				run.routesFile = multisigFile(opts.routesFile, multisigAddr, several)
+				run.outputFile = multisigFile(opts.outputFile, multisigAddr, several)
+				run.projectionFile = multisigFile(opts.projectionFile, multisigAddr, several)
+				if err := run.generate(); err != nil {
+					if !several {
+						return err
+					}
+					return fmt.Errorf("multisig %s: %w", multisigAddr, err)
+				}
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&opts.routesFile, "routes", "routes.json", "Input routes file")
+	cmd.Flags().StringArrayVar(&opts.multisigAddrs, "multisig-address", nil, "Multisig address (sender) (repeatable; required unless --source or multisig_addresses is set)")
+	cmd.Flags().StringVarP(&opts.outputFile, "output", "o", "unsigned-tx.json", "Output file for unsigned transaction")
+	cmd.Flags().StringVarP(&opts.configFile, "config", "c", "", "Optional config file with chain and fee settings")
+	cmd.Flags().StringVar(&opts.source, "source", "", "Name of a source chain from the config's sources list")
+	cmd.Flags().StringVar(&opts.feePayer, "fee-payer", "", "Optional account that pays fees instead of the multisig")
+	cmd.Flags().StringVar(&opts.authzGrantee, "authz-grantee", "", "Wrap transfers in an authz MsgExec executed by this grantee of the multisig")
+	cmd.Flags().BoolVar(&opts.allowDups, "allow-duplicates", false, "Forward deposits flagged as possible double-sends after reviewing them")
+	cmd.Flags().BoolVar(&opts.aggregate, "aggregate", false, "Merge routes to the same destination domain, recipient, token ID and denom into one transfer (default: the config's strategy.aggregate)")
+	cmd.Flags().Uint64Var(&opts.gasLimit, "gas-limit", 0, "Gas limit for the transaction")
+	cmd.Flags().StringVar(&opts.fees, "fees", "", "Fees to pay, e.g. 20000utia (default: gas limit times the chain's gas price)")
+	cmd.Flags().IntVar(&opts.maxMsgsPerTx, "max-msgs-per-tx", 0, "Split messages into multiple transactions with at most this many messages each")
+	cmd.Flags().Uint64Var(&opts.accountNumber, "account-number", 0, "Multisig account number, recorded in the batch manifest for offline signing")
+	cmd.Flags().Uint64Var(&opts.sequence, "sequence", 0, "Multisig account sequence assigned to the first batch")
+	cmd.Flags().StringVar(&opts.rpcURL, "rpc-url", "", "Optional gRPC endpoint to query the multisig balance for a balance projection and interchain gas quotes")
+	cmd.Flags().StringVar(&opts.balances, "balances", "", "Multisig balances for the balance projection instead of querying, e.g. 5000000utia")
+	cmd.Flags().StringVar(&opts.projectionFile, "projection-output", "", "Optional file to save the balance projection as JSON")
+	cmd.Flags().BoolVar(&opts.checkBalance, "check-balance", false, "Require the balance projection that fails before writing the transaction if the multisig cannot fund the routed amounts and fees (requires --rpc-url or --balances)")
+	cmd.Flags().StringArrayVar(&opts.encryptTo, "encrypt-to", nil, "Encrypt the sign docs to this age recipient (repeatable); files get a .age suffix")
+	cmd.Flags().BoolVar(&opts.checkDests, "check-destinations", false, "Check the destination chains configured in \"destinations\" (ISM, collateral, delivery gas) and warn about problems")
+	cmd.Flags().StringVar(&opts.overridesFile, "overrides", "", "Manual route overrides file; requires audit_log in the config")
+	cmd.Flags().StringArrayVar(&opts.igpGasLimits, "igp-gas-limit", nil, "Destination gas limit of transfers to a domain as domain=limit, e.g. 2340=200000 (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.igpMaxFees, "igp-max-fee", nil, "Most transfers to a domain pay for interchain gas as domain=coin, e.g. 2340=5000utia (repeatable)")
+	cmd.Flags().BoolVar(&opts.igpQuote, "igp-quote", false, "Query the interchain gas payment of each destination from --rpc-url and use it as MaxFee")
+	cmd.Flags().StringArrayVar(&opts.failOn, "fail-on", nil, "Fail on warnings of this class in addition to policy.fail_on: first_seen_recipient, amount_override or decimals_mismatch (repeatable)")
+	addStateFlags(cmd, &opts.state)
+	mutatesFlag(cmd, "state")
+	mutatesFlag(cmd, "postgres-dsn")
+	cmd.Flags().BoolVar(&opts.regenerate, "regenerate", false, "Include deposits generated into a transaction that was never broadcast")
+	stepInputFlag(cmd, "routes")
+	outputFlag(cmd, "output")
+
+	return cmd
+}
+
+// configure loads the config, applies the flags that take precedence over it and returns a run
+// holding the settings shared by every multisig, along with the multisigs to generate for
+func (o *generateOptions) configure(cmd *cobra.Command, out *commandOutput) (generateRun, []string, error) {
+	// Load chain and fee settings from config if provided; flags take precedence
+	config := &types.Config{Chain: types.DefaultChainConfig()}
+	if o.configFile != "" {
+		var err error
+		config, err = types.LoadConfig(o.configFile)
+		if err != nil {
+			return generateRun{}, nil, fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+
+	// Narrow the config to a single source chain if requested
+	config, _, multisigs, err := resolveMultisigs(config, o.source, o.multisigAddrs)
+	if err != nil {
+		return generateRun{}, nil, err
+	}
+	if len(multisigs) > 1 {
+		for _, flag := range []string{"balances", "account-number", "sequence"} {
+			if cmd.Flags().Changed(flag) {
+				return generateRun{}, nil, fmt.Errorf("--%s describes a single multisig and cannot be used with several", flag)
+			}
+		}
+		if o.outputFile == "" {
+			return generateRun{}, nil, fmt.Errorf("--output is required with several multisigs")
+		}
+	}
+	if o.checkBalance && o.balances == "" && o.rpcURL == "" {
+		return generateRun{}, nil, fmt.Errorf("--check-balance requires --rpc-url or --balances")
+	}
+
+	feeConfig := config.Fee
+	if cmd.Flags().Changed("fee-payer") {
+		feeConfig.Payer = o.feePayer
+	}
+	if cmd.Flags().Changed("gas-limit") {
+		feeConfig.GasLimit = o.gasLimit
+	}
+	if cmd.Flags().Changed("fees") {
+		feeConfig.Amount = o.fees
+	}
+	if cmd.Flags().Changed("authz-grantee") {
+		config.Authz.Grantee = o.authzGrantee
+	}
+	if cmd.Flags().Changed("aggregate") {
+		config.Strategy.Aggregate = o.aggregate
+	}
+
+	feeCoins, err := resolveFees(config.Chain, feeConfig)
+	if err != nil {
+		return generateRun{}, nil, err
+	}
+	if err := applyInterchainGasFlags(config, o.igpGasLimits, o.igpMaxFees); err != nil {
+		return generateRun{}, nil, err
+	}
+	if err := applyFailOnFlags(config, o.failOn); err != nil {
+		return generateRun{}, nil, err
+	}
+
+	run := generateRun{
+		opts:     o,
+		ctx:      cmd.Context(),
+		out:      out,
+		config:   config,
+		fee:      feeConfig,
+		feeCoins: feeCoins,
+	}
+	return run, multisigs, nil
+}
+
+// generateRun generates the unsigned transaction of one multisig in steps: load, plan, build,
+// check, write and record. Each step leaves its results on the run for the steps after it.
+type generateRun struct {
+	opts     *generateOptions
+	ctx      context.Context
+	out      *commandOutput
+	config   *types.Config
+	fee      types.FeeConfig
+	feeCoins sdk.Coins
+
+	multisigAddr   string
+	routesFile     string
+	outputFile     string
+	projectionFile string
+
+	gen        *generator.Generator
+	ledger     *state.Ledger
+	store      storage.Storage
+	retries    *retry.Queue
+	now        time.Time
+	routes     *types.Routes          // Routes to generate, planned once plan has run
+	deposits   []types.HyperlaneRoute // Deposits the transaction forwards, recorded as generated
+	changes    []routeChange          // Changes to the input routes, summarized with the planned routes
+	msgs       []sdk.Msg
+	txOpts     generator.TxOptions
+	projection *generator.BalanceProjection
+}
+
+// routeChange counts the routes a planning step changed
+type routeChange struct {
+	count int
+	what  string
+}
+
+// generate runs the steps of the run in order and releases the state database and retry queue
+func (r *generateRun) generate() error {
+	defer r.close()
+
+	for _, step := range []func() error{r.load, r.plan, r.build, r.check, r.write, r.record} {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close releases the state database and retry queue opened by the run
+func (r *generateRun) close() {
+	if r.store != nil {
+		r.store.Close()
+	}
+	r.retries.Close()
+}
+
+// changed notes that a planning step changed count routes
+func (r *generateRun) changed(count int, what string) {
+	r.changes = append(r.changes, routeChange{count, what})
+}
+
+// load reads the routes, refuses amounts above their deposit unless allowed and opens the state
+// database
+func (r *generateRun) load() error {
+	var err error
+	r.gen, err = generator.NewGeneratorWithConfig(r.multisigAddr, r.config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(r.out, "Generating transactions from %s...\n", r.routesFile)
+	r.routes, err = loadRoutes(r.routesFile)
+	if err != nil {
+		return err
+	}
+	for i := range r.routes.Routes {
+		if err := r.config.Limits.CheckAmountOverride(&r.routes.Routes[i]); err != nil {
+			return fmt.Errorf("%w (set limits.allow_amount_above_deposit to allow it)", err)
+		}
+	}
+	if err := enforcePolicy(r.config.Policy, types.WarningAmountOverride, warnAmountMismatches(r.out, r.routes.Routes)); err != nil {
+		return err
+	}
+
+	r.ledger, r.store, err = r.opts.state.open(r.ctx)
+	if err != nil {
+		return err
+	}
+	if r.store == nil && r.config.Policy.FailsOn(types.WarningFirstSeenRecipient) {
+		return fmt.Errorf("policy fails on %s warnings, which requires --state to know the recipients sent to before", types.WarningFirstSeenRecipient)
+	}
+	return nil
+}
+
+// plan selects the routes to forward in this run and applies the strategy, limits and message
+// caps to them. When the routes change, the transaction must be verified against the planned
+// routes rather than the input file, so they are saved next to it.
+func (r *generateRun) plan() error {
+	if err := r.selectRoutes(); err != nil {
+		return err
+	}
+
+	planned, err := strategy.Apply(r.routes, r.config.Strategy, r.config.RebalancingFee)
+	if err != nil {
+		return fmt.Errorf("failed to apply strategy: %w", err)
+	}
+	if err := planned.ApplyLimits(r.config.Limits); err != nil {
+		return fmt.Errorf("failed to apply limits: %w", err)
+	}
+	if err := planned.ApplyMessageCaps(r.config.Destinations); err != nil {
+		return fmt.Errorf("failed to apply message caps: %w", err)
+	}
+	admitted, inFlight, err := holdInFlight(r.ctx, r.out, r.ledger, planned.Routes, r.config)
+	if err != nil {
+		return err
+	}
+	planned.Routes = admitted
+	if len(planned.Routes.Routes) == 0 {
+		return fmt.Errorf("no routes left to generate: their destinations are at their in-flight limit")
+	}
+	r.changed(planned.Aggregated, "routes aggregated")
+	r.changed(len(planned.Deferred), "routes deferred")
+	r.changed(planned.Split, "routes split")
+	r.changed(planned.FannedOut, "routes fanned out to their split recipients")
+	r.changed(planned.Reordered, "routes reordered by priority")
+	r.changed(planned.Deducted, "deposits paid the rebalancing fee")
+	r.changed(inFlight, "routes held for destinations at their in-flight limit")
+
+	var summary []string
+	for _, c := range r.changes {
+		if c.count > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", c.count, c.what))
+		}
+	}
+	if len(summary) > 0 {
+		r.routes = planned.Routes
+		plannedFile := siblingFile(r.routesFile, "planned")
+		data, err := json.MarshalIndent(r.routes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal planned routes: %w", err)
+		}
+		if err := output.WriteAtomic(plannedFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write planned routes: %w", err)
+		}
+		fmt.Fprintf(r.out, "Routes changed (%s); planned routes saved to %s (verify against this file)\n",
+			strings.Join(summary, ", "), plannedFile)
+	}
+
+	if err := r.config.ValidateRoutes(r.routes.Routes); err != nil {
+		return fmt.Errorf("routes exceed the whitelist amount caps:\n%w", err)
+	}
+	return nil
+}
+
+// selectRoutes drops the routes already rebalanced, adds the retries that are due and holds back
+// the routes that must wait: quarantined, rejected by hooks, failed or below their min_amount.
+// Overrides are applied along the way and first-seen recipients are warned about.
+func (r *generateRun) selectRoutes() error {
+	var err error
+	if r.store != nil {
+		rebalanced, err := dropRebalanced(r.ctx, r.out, r.ledger, r.routes, r.opts.regenerate)
+		if err != nil {
+			return err
+		}
+		r.changed(rebalanced, "routes already rebalanced skipped")
+	}
+
+	r.now = time.Now()
+	var retried int
+	r.retries, r.routes, retried, err = withRetries(r.out, r.routes, r.config, r.now)
+	if err != nil {
+		return err
+	}
+	r.changed(retried, "routes retried or held for backoff")
+
+	var held int
+	r.routes, held, err = holdQuarantined(r.out, r.routes, r.config)
+	if err != nil {
+		return err
+	}
+	r.changed(held, "quarantined routes held")
+
+	if r.opts.overridesFile != "" {
+		var overridden int
+		r.routes, overridden, err = applyOverrides(r.out, r.routes, r.opts.overridesFile, r.config)
+		if err != nil {
+			return err
+		}
+		r.changed(overridden, "overrides applied")
+	}
+
+	var hooked *strategy.HookResult
+	r.routes, hooked, err = applyRouteHooks(r.ctx, r.out, r.routes, r.config, r.opts.rpcURL, r.routesFile, r.now)
+	if err != nil {
+		return err
+	}
+	r.changed(len(hooked.Rejected), "routes rejected by hooks")
+	r.changed(hooked.Modified, "route amounts lowered by hooks")
+	r.changed(hooked.Annotated, "routes annotated by hooks")
+
+	if err := checkDuplicates(r.out, r.routes, r.config.Strategy, r.opts.allowDups); err != nil {
+		return err
+	}
+
+	var queued int
+	r.routes, queued, err = queueFailures(r.out, r.retries, r.gen, r.routes, r.config, r.now)
+	if err != nil {
+		return err
+	}
+	r.changed(queued, "routes failed and queued for retry or dead-lettered")
+	if len(r.routes.Routes) == 0 {
+		return fmt.Errorf("no routes left to generate")
+	}
+
+	// Dust of earlier runs merged into a route is recorded as generated with this run's deposits
+	deposits := r.routes.Routes
+	var dust *strategy.Dust
+	r.routes, dust, err = holdDust(r.ctx, r.out, r.ledger, r.routes, r.config)
+	if err != nil {
+		return err
+	}
+	if len(r.routes.Routes) == 0 {
+		return fmt.Errorf("no routes left to generate: every route is below its destination's min_amount")
+	}
+	r.deposits = append(append([]types.HyperlaneRoute(nil), deposits...), dust.Released...)
+	r.changed(len(dust.Held), "routes below min_amount held")
+	r.changed(dust.Merged, "routes below min_amount merged once they reached it")
+
+	if r.ledger != nil {
+		firstSeen, err := r.ledger.FirstSeenRecipients(r.ctx, r.routes.Routes)
+		if err != nil {
+			return err
+		}
+		for _, f := range firstSeen {
+			fmt.Fprintf(r.out, "⚠ First-seen recipient in %s\n", f)
+		}
+		if err := enforcePolicy(r.config.Policy, types.WarningFirstSeenRecipient, firstSeen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build quotes the interchain gas if requested and generates the transfer messages
+func (r *generateRun) build() error {
+	if r.opts.igpQuote || r.config.InterchainGas.Quote {
+		quotes, err := quoteInterchainGas(r.ctx, r.opts.rpcURL, r.routes, r.config)
+		if err != nil {
+			return err
+		}
+		for _, quote := range quotes {
+			if quote.TokenID == "" {
+				fmt.Fprintf(r.out, "Interchain gas quote for domain %d: %s\n", quote.Domain, quote.Fee)
+			} else {
+				fmt.Fprintf(r.out, "Interchain gas quote for token %s to domain %d: %s\n", quote.TokenID, quote.Domain, quote.Fee)
+			}
+		}
+		if err := r.gen.SetFeeQuotes(quotes); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	r.msgs, err = r.gen.Generate(r.routes)
+	if err != nil {
+		return fmt.Errorf("failed to generate transactions: %w", err)
+	}
+
+	fmt.Fprintf(r.out, "Generated %d MsgRemoteTransfer messages\n", len(r.msgs))
+	if r.config.Authz.Enabled() {
+		fmt.Fprintf(r.out, "Transfers wrapped in an authz MsgExec for grantee %s\n", r.config.Authz.Grantee)
+	}
+
+	r.txOpts = generator.TxOptions{
+		GasLimit: r.fee.GasLimit,
+		Fee:      r.feeCoins,
+		FeePayer: r.fee.Payer,
+		Grantee:  r.config.Authz.Grantee,
+	}
+	return nil
+}
+
+// check checks the destinations and their decimals if requested, and refuses a transaction the
+// multisig cannot fund when its balances are known, before anything is written or recorded
+func (r *generateRun) check() error {
+	if r.opts.checkDests {
+		checkDestinations(r.ctx, r.out, r.routes, r.msgs, r.config)
+	}
+	if r.opts.checkDests || r.config.Policy.FailsOn(types.WarningDecimalsMismatch) {
+		if err := enforcePolicy(r.config.Policy, types.WarningDecimalsMismatch, checkDecimals(r.ctx, r.out, r.routes, r.config)); err != nil {
+			return err
+		}
+	}
+
+	if r.opts.balances == "" && r.opts.rpcURL == "" {
+		return nil
+	}
+
+	// Fees only reduce the multisig balance when it pays them itself
+	multisigFees := r.feeCoins
+	if signers := r.gen.Signers(r.txOpts); len(signers) > 1 || signers[0] != r.multisigAddr {
+		multisigFees = nil
+	}
+
+	var err error
+	r.projection, err = projectMultisig(r.ctx, r.gen, r.routes, r.msgs, multisigFees, r.opts.balances, r.opts.rpcURL, r.multisigAddr)
+	if err != nil {
+		return err
+	}
+	if r.projection.Overdrawn() {
+		printProjection(r.out, r.projection)
+		return fmt.Errorf("routed amounts and fees exceed the multisig's funds, no transaction was written")
+	}
+	if r.opts.checkBalance {
+		fmt.Fprintln(r.out, "✓ Multisig balance covers the routed amounts and fees")
+	}
+	return nil
+}
+
+// write writes the unsigned transaction, or its batches with --max-msgs-per-tx
+func (r *generateRun) write() error {
+	if r.opts.maxMsgsPerTx > 0 {
+		if r.outputFile == "" {
+			return fmt.Errorf("--output is required with --max-msgs-per-tx")
+		}
+		return writeBatches(r.out, r.gen, r.msgs, r.txOpts, r.outputFile, r.opts.maxMsgsPerTx, r.opts.accountNumber, r.opts.sequence, r.opts.encryptTo)
+	}
+
+	unsignedTx, err := r.gen.BuildUnsignedTx(r.msgs, r.txOpts)
+	if err != nil {
+		return fmt.Errorf("failed to build unsigned transaction: %w", err)
+	}
+
+	// Output transaction in the Cosmos SDK JSON format accepted by celestia-appd and Keplr
+	data, err := generator.MarshalTxJSON(unsignedTx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+	// Encrypted sign docs must not be printed in the clear
+	encrypted := len(r.opts.encryptTo) > 0
+	if !encrypted {
+		r.out.emit(json.RawMessage(data))
+	}
+
+	switch {
+	case r.outputFile != "":
+		written, err := output.WriteFile(r.outputFile, data, r.opts.encryptTo)
+		if err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		fmt.Fprintf(r.out, "Unsigned transaction saved to %s\n", written)
+		if encrypted {
+			r.out.emit(map[string]string{"encrypted_transaction": written})
+		}
+	case encrypted:
+		return fmt.Errorf("--output is required with --encrypt-to")
+	default:
+		fmt.Fprintln(r.out, string(data))
+	}
+	return nil
+}
+
+// record records the generated deposits in the state database and tells the signers what to do
+// next, with the multisig balances after execution when they are known
+func (r *generateRun) record() error {
+	if r.ledger != nil {
+		if err := r.ledger.RecordGenerated(r.ctx, state.Deposits(r.deposits, r.routes.Routes)); err != nil {
+			return fmt.Errorf("failed to record generated deposits: %w", err)
+		}
+		fmt.Fprintln(r.out, "Generated deposits recorded in the state database")
+	}
+
+	fmt.Fprintln(r.out, "\nRequired signers (in signature order):")
+	for i, signer := range r.gen.Signers(r.txOpts) {
+		fmt.Fprintf(r.out, "  %d. %s\n", i+1, signer)
+	}
+
+	if r.projection != nil {
+		printProjection(r.out, r.projection)
+
+		if r.projectionFile != "" {
+			data, err := json.MarshalIndent(r.projection, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal balance projection: %w", err)
+			}
+			if err := output.WriteAtomic(r.projectionFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write balance projection: %w", err)
+			}
+			fmt.Fprintf(r.out, "Balance projection saved to %s\n", r.projectionFile)
+		}
+	}
+
+	fmt.Fprintln(r.out, "\nNext steps:")
+	fmt.Fprintln(r.out, "1. Review the generated messages")
+	fmt.Fprintln(r.out, "2. Use 'celestia-rebalancer verify' to validate")
+	fmt.Fprintln(r.out, "3. Create multisig transaction using celestia-appd or Keplr")
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// applyRouteHooks runs the route hooks of the policy over routes. The chain's latest height is
+// queried from rpcURL if it is set. Rejected routes are reported and saved next to routesFile.
+func applyRouteHooks(ctx context.Context, out io.Writer, routes *types.Routes, config *types.Config, rpcURL, routesFile string, now time.Time) (*types.Routes, *strategy.HookResult, error) {
+	if len(config.Policy.RouteHooks) == 0 {
+		return routes, &strategy.HookResult{Routes: routes}, nil
+	}
+
+	hooks, err := strategy.NewRouteHooks(config.Policy.RouteHooks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("policy: %w", err)
+	}
+
+	for _, hook := range config.Policy.RouteHooks {
+		if hook.Type == types.HookMaxAgeBlocks && rpcURL == "" {
+			return nil, nil, fmt.Errorf("route hook %s requires --rpc-url to query the chain's latest height", hook.HookName())
+		}
+	}
+
+	rc := strategy.RouteContext{MultisigAddr: routes.MultisigAddr, Chain: config.Chain, Time: now}
+	if rpcURL != "" {
+		c, err := dialChain(ctx, rpcURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer c.Close()
+
+		rc.Height, err = c.LatestHeight()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	result, err := strategy.ApplyHooks(ctx, routes, hooks, rc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(result.Rejected) > 0 {
+		for _, r := range result.Rejected {
+			fmt.Fprintf(out, "⚠ Rejected route from tx %s (%s %s) by hook %s: %s\n", r.Route.TxHash, r.Route.Amount, r.Route.Denom, r.Hook, r.Reason)
+		}
+		rejectedFile := siblingFile(routesFile, "rejected")
+		data, err := json.MarshalIndent(result.Rejected, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal rejected routes: %w", err)
+		}
+		if err := output.WriteAtomic(rejectedFile, data, 0644); err != nil {
+			return nil, nil, fmt.Errorf("failed to write rejected routes: %w", err)
+		}
+		fmt.Fprintf(out, "Rejected routes saved to %s\n", rejectedFile)
+	}
+
+	return result.Routes, result, nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// applyInterchainGasFlags sets the gas_limit and max_fee of the destinations named by the
+// --igp-gas-limit and --igp-max-fee flags, given as domain=value, over the config file's values
+func applyInterchainGasFlags(config *types.Config, gasLimits, maxFees []string) error {
+	set := func(flag, value string, apply func(*types.DestinationConfig, string) error) error {
+		name, v, ok := strings.Cut(value, "=")
+		domain, err := strconv.ParseUint(name, 10, 32)
+		if !ok || err != nil {
+			return fmt.Errorf("invalid --%s %q, want domain=value", flag, value)
+		}
+		if config.Destinations == nil {
+			config.Destinations = make(map[uint32]types.DestinationConfig)
+		}
+		destination := config.Destinations[uint32(domain)]
+		if err := apply(&destination, v); err != nil {
+			return fmt.Errorf("invalid --%s %q: %w", flag, value, err)
+		}
+		config.Destinations[uint32(domain)] = destination
+		return nil
+	}
+
+	for _, value := range gasLimits {
+		err := set("igp-gas-limit", value, func(d *types.DestinationConfig, v string) error {
+			limit, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			d.GasLimit = limit
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, value := range maxFees {
+		err := set("igp-max-fee", value, func(d *types.DestinationConfig, v string) error {
+			if _, _, err := types.ParseMaxFee(v); err != nil {
+				return err
+			}
+			d.MaxFee = v
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quoteInterchainGas queries the interchain gas payment of the transfers the routes make, raised by
+// the configured margin. With an IGP configured, the IGP quotes each destination domain for its
+// gas limit; otherwise the hooks of each warp token quote each destination they transfer to.
+// Payments of nothing are left out.
+func quoteInterchainGas(ctx context.Context, rpcURL string, routes *types.Routes, config *types.Config) ([]generator.FeeQuote, error) {
+	if rpcURL == "" {
+		return nil, fmt.Errorf("quoting interchain gas requires --rpc-url")
+	}
+	c, err := dialChain(ctx, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	igp := config.InterchainGas.IGP
+	seen := make(map[string]bool)
+	var quotes []generator.FeeQuote
+	for _, route := range routes.Routes {
+		if route.RouteInfo == nil {
+			continue
+		}
+		domain := route.RouteInfo.DestinationDomain
+
+		var quote generator.FeeQuote
+		var payment sdk.Coins
+		if igp != "" {
+			key := strconv.FormatUint(uint64(domain), 10)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			gasLimit := config.Destinations[domain].QuoteGasLimit()
+			if gasLimit == 0 {
+				return nil, fmt.Errorf("destination %d sets neither gas_limit nor gas_per_transfer to quote the IGP for", domain)
+			}
+			quote = generator.FeeQuote{Domain: domain}
+			if payment, err = c.QuoteGasPayment(igp, domain, gasLimit); err != nil {
+				return nil, err
+			}
+		} else {
+			tokenID, err := types.NormalizeTokenID(route.RouteInfo.TokenID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid token_id in route from tx %s: %w", route.TxHash, err)
+			}
+			key := fmt.Sprintf("%s/%d", tokenID, domain)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			quote = generator.FeeQuote{TokenID: tokenID, Domain: domain}
+			if payment, err = c.QuoteRemoteTransfer(tokenID, domain); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(payment) == 0 {
+			continue
+		}
+		if len(payment) > 1 {
+			return nil, fmt.Errorf("transfers to domain %d are quoted in several denoms (%s), but MaxFee holds one", domain, payment)
+		}
+		amount, err := config.InterchainGas.ApplyMargin(payment[0].Amount)
+		if err != nil {
+			return nil, err
+		}
+		quote.Fee = sdk.NewCoin(payment[0].Denom, amount)
+		quotes = append(quotes, quote)
+	}
+
+	sort.Slice(quotes, func(i, j int) bool {
+		if quotes[i].Domain != quotes[j].Domain {
+			return quotes[i].Domain < quotes[j].Domain
+		}
+		return quotes[i].TokenID < quotes[j].TokenID
+	})
+	return quotes, nil
+}
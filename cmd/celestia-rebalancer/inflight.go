@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/destination"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/state"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// holdInFlight holds back the routes to destinations that reached their max_in_flight transfers
+// awaiting delivery. The pending deliveries to those destinations are confirmed against their
+// mailboxes first; those that cannot be checked still count as in flight.
+func holdInFlight(ctx context.Context, out io.Writer, ledger *state.Ledger, routes *types.Routes, config *types.Config) (*types.Routes, int, error) {
+	limits := make(map[uint32]int)
+	limited := make(map[uint32]bool)
+	for domain, dest := range config.Destinations {
+		if dest.MaxInFlight > 0 {
+			limits[domain] = dest.MaxInFlight
+			limited[domain] = true
+		}
+	}
+	if len(limits) == 0 {
+		return routes, 0, nil
+	}
+	if ledger == nil {
+		return nil, 0, fmt.Errorf("destinations with max_in_flight require --state to know the transfers awaiting delivery")
+	}
+
+	confirmed, err := ledger.ConfirmDeliveries(ctx, limited, func(ctx context.Context, d storage.Delivery) (bool, error) {
+		return destination.MessageDelivered(ctx, config.Destinations[d.Destination], d.MessageID)
+	})
+	if err != nil {
+		fmt.Fprintf(out, "⚠ Some deliveries could not be confirmed and still count as in flight:\n%v\n", err)
+	}
+	if confirmed > 0 {
+		fmt.Fprintf(out, "Confirmed %d deliveries\n", confirmed)
+	}
+
+	inFlight, err := ledger.InFlight(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	kept, held := strategy.HoldInFlight(routes.Routes, limits, inFlight)
+	for _, route := range held {
+		domain := route.RouteInfo.DestinationDomain
+		fmt.Fprintf(out, "⚠ Holding route from tx %s (%s %s): domain %d has %d transfers awaiting delivery (max_in_flight %d)\n",
+			route.TxHash, route.Amount, route.Denom, domain, inFlight[domain], limits[domain])
+	}
+	if len(held) == 0 {
+		return routes, 0, nil
+	}
+
+	result := *routes
+	result.Routes = kept
+	strategy.RecomputeTotal(&result)
+	return &result, len(held), nil
+}
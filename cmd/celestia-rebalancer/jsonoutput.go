@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Values of --output-format
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// textOnlyAnnotation marks commands that have no single result to emit, such as daemons and
+// rehearsals driving other commands. They refuse --output-format json.
+const textOnlyAnnotation = "text-output-only"
+
+// textOnly marks cmd as printing text only
+func textOnly(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[textOnlyAnnotation] = "true"
+	return cmd
+}
+
+// commandOutput is where a command prints: its progress and reports go to the embedded writer,
+// and with --output-format json the results it emits are written to stdout as one JSON document
+// when it ends
+type commandOutput struct {
+	io.Writer           // Stdout, or stderr in JSON output mode
+	stdout    io.Writer // Where the JSON document is written, nil in text mode
+	results   []any
+}
+
+// newCommandOutput returns the output of cmd for its --output-format flag. Commands run outside
+// the root command, such as the steps of a rehearsal, print text.
+func newCommandOutput(cmd *cobra.Command) (*commandOutput, error) {
+	format := outputFormatText
+	if flag := cmd.Flags().Lookup("output-format"); flag != nil {
+		format = flag.Value.String()
+	}
+	switch format {
+	case outputFormatText:
+		return &commandOutput{Writer: cmd.OutOrStdout()}, nil
+	case outputFormatJSON:
+		return &commandOutput{Writer: cmd.ErrOrStderr(), stdout: cmd.OutOrStdout()}, nil
+	}
+	return nil, fmt.Errorf("invalid --output-format %q: must be %s or %s", format, outputFormatText, outputFormatJSON)
+}
+
+// checkOutputFormat refuses JSON output for commands marked text-only
+func checkOutputFormat(cmd *cobra.Command, out *commandOutput) error {
+	if out.stdout != nil && cmd.Annotations[textOnlyAnnotation] == "true" {
+		return fmt.Errorf("command %q has no JSON output, run it with --output-format %s", cmd.CommandPath(), outputFormatText)
+	}
+	return nil
+}
+
+// withOutput adapts a command run printing to its output into a cobra RunE. The JSON document is
+// written when run returns, with the error it returned.
+func withOutput(run func(cmd *cobra.Command, args []string, out *commandOutput) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		out, err := newCommandOutput(cmd)
+		if err != nil {
+			return err
+		}
+		return out.finish(explainDeadline(cmd, run(cmd, args, out)))
+	}
+}
+
+// emit records v as the result of the command, written to stdout as JSON when the command ends in
+// JSON output mode. Commands handling several multisigs or scenarios emit one result for each.
+func (o *commandOutput) emit(v any) {
+	if o.stdout != nil {
+		o.results = append(o.results, v)
+	}
+}
+
+// finish writes the JSON document of a command that ended with err in JSON output mode:
+// {"error": ...} if it failed, else its result, or an array of its results if it emitted several.
+// It returns err.
+func (o *commandOutput) finish(err error) error {
+	if o.stdout == nil {
+		return err
+	}
+	var document any = map[string]any{}
+	switch {
+	case err != nil:
+		document = map[string]string{"error": err.Error()}
+	case len(o.results) == 1:
+		document = o.results[0]
+	case len(o.results) > 1:
+		document = o.results
+	}
+
+	data, marshalErr := json.MarshalIndent(document, "", "  ")
+	if marshalErr != nil {
+		data, _ = json.Marshal(map[string]string{"error": fmt.Sprintf("failed to marshal result: %v", marshalErr)})
+	}
+	fmt.Fprintln(o.stdout, string(data))
+	o.stdout = nil
+	return err
+}
+
+// exit ends the process with code after writing the command's JSON document, for commands that
+// report a failed check through their exit code rather than an error
+func (o *commandOutput) exit(code int) {
+	o.finish(nil)
+	os.Exit(code)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/verifier"
+)
+
+// checkLive checks the transaction against the current state of the chain at rpcURL and prints the
+// go/no-go summary for signers. It returns whether the transaction is safe to sign.
+func checkLive(ctx context.Context, out io.Writer, v *verifier.Verifier, config *types.Config, routesFile, txFile, rpcURL string) (bool, error) {
+	routes, err := loadRoutes(routesFile)
+	if err != nil {
+		return false, err
+	}
+	txData, err := output.ReadFile(txFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read transaction file: %w", err)
+	}
+	txRaw, err := generator.DecodeTxRaw(txData)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse transaction file: %w", err)
+	}
+
+	c, err := dialChain(ctx, rpcURL)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+	c.SetQueryConfig(config.Query)
+
+	fmt.Fprintf(out, "Checking the transaction against the chain at %s...\n\n", rpcURL)
+	report, err := v.CheckLive(routes, txRaw, c)
+	if err != nil {
+		return false, err
+	}
+	v.PrintLiveReport(report)
+	return report.Go, nil
+}
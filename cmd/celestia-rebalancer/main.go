@@ -1,18 +1,35 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
+	"github.com/celestiaorg/celestia-rebalancer/pkg/attestation"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/parser"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/state"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/verifier"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/spf13/cobra"
 )
 
 func main() {
+	var (
+		readOnly     bool
+		deadline     commandDeadline
+		rpc          rpcFlags
+		outDir       string
+		outputFormat string
+	)
+
 	rootCmd := &cobra.Command{
 		Use:   "celestia-rebalancer",
 		Short: "CLI tool for managing Hyperlane multisig rebalancing on Celestia",
@@ -20,28 +37,121 @@ func main() {
   1. Parsing incoming transactions to extract routing information
   2. Generating multisig transactions for Hyperlane MsgRemoteTransfer
   3. Verifying that transactions match the intended routes`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			out, err := newCommandOutput(cmd)
+			if err != nil {
+				return err
+			}
+			// The command does not run if these fail, so its JSON document is written here
+			if err := checkOutputFormat(cmd, out); err != nil {
+				return out.finish(err)
+			}
+			if err := enforceRole(cmd); err != nil {
+				return out.finish(err)
+			}
+			if err := enforceReadOnly(cmd, readOnly); err != nil {
+				return out.finish(err)
+			}
+			if err := rpc.apply(cmd); err != nil {
+				return out.finish(err)
+			}
+			if err := resolveOutDir(cmd, outDir); err != nil {
+				return out.finish(err)
+			}
+			deadline.apply(cmd)
+			return nil
+		},
 	}
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Refuse commands that mutate chain or local state, e.g. for audits against production data")
+	rootCmd.PersistentFlags().DurationVar(&rpcTimeout, "timeout", 0, "Give up on any single gRPC call to the chain after this long (default: the config's rpc.call_timeout, or no limit)")
+	rootCmd.PersistentFlags().DurationVar(&deadline.limit, "deadline", 0, "Abort the whole command after this long, e.g. for cron jobs (default: no limit)")
+	rootCmd.PersistentFlags().StringVar(&outDir, "out-dir", "", "Write relative output files to this directory, created if missing (default: the config's output_dir, or the working directory)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output-format", outputFormatText, "text, or json to print only the command's result (routes, transaction, report...) as one JSON document on stdout and everything else on stderr")
+	rpc.add(rootCmd.PersistentFlags())
 
 	rootCmd.AddCommand(
 		parseCmd(),
 		generateCmd(),
+		mutates(resequenceCmd()),
+		netCmd(),
+		planCmd(),
+		attestCmd(),
+		bundleCmd(),
+		trackCmd(),
+		feesCmd(),
+		textOnly(mutates(watchCmd())),
+		mutates(backfillCmd()),
+		tokenIDCmd(),
+		importWarpCmd(),
+		signCmd(),
+		combineCmd(),
+		mutates(broadcastCmd()),
+		secretCmd(),
+		quarantineCmd(),
 		verifyCmd(),
+		selfcheckCmd(),
+		simulateCmd(),
+		textOnly(rehearseCmd()),
 	)
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	deadline.stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// selectSource narrows config to the named source chain, taking the multisig address from
+// the source unless one was given explicitly
+func selectSource(config *types.Config, name string, multisigAddr *string) (*types.Config, *types.SourceConfig, error) {
+	if config == nil {
+		return nil, nil, fmt.Errorf("--source requires a config file")
+	}
+
+	sourceConfig, source, err := config.ForSource(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if *multisigAddr == "" {
+		*multisigAddr = source.MultisigAddr
+	}
+
+	return sourceConfig, source, nil
+}
+
+// Directions of the transfers extracted by parse
+const (
+	directionInbound  = parser.DirectionInbound
+	directionOutbound = parser.DirectionOutbound
+)
+
 func parseCmd() *cobra.Command {
 	var (
-		multisigAddr string
-		fromHeight   int64
-		toHeight     int64
-		rpcURL       string
-		outputFile   string
-		configFile   string
+		multisigAddrs []string
+		fromHeight    int64
+		toHeight      int64
+		rpcURL        string
+		outputFile    string
+		configFile    string
+		source        string
+		strict        bool
+		strictDecode  bool
+		pageSize      uint64
+		maxPages      int
+		maxTxs        int
+		cacheDir      string
+		cacheTTL      time.Duration
+		direction     string
+		txHashes      []string
+		concurrency   int
+		progress      bool
+		blockTimes    bool
+		resume        bool
+		checkpointGap int64
+		format        string
+		stateOpts     stateOptions
 	)
 
 	cmd := &cobra.Command{
@@ -65,149 +175,645 @@ Optional config file for address whitelisting:
       "1": ["0x1234567890123456789012345678901234567890"]
     }
   }
-}`,
-		RunE: func(cmd *cobra.Command, args []string) error {
+}
+
+With --direction outbound, parse instead extracts the MsgRemoteTransfers sent by the multisig (directly
+or through an authz MsgExec) into the same routes format, so auditors can review both sides of the flow.
+Every successful outgoing transfer is included; the whitelist is not applied.
+
+Instead of a height range, specific transactions can be parsed with --tx-hash (repeatable). They are
+fetched concurrently, up to --concurrency at a time.
+
+With --state, inbound deposits that the state database records as already rebalanced are left out
+of the routes, so re-parsing an overlapping height range does not forward them twice. Deposits only
+generated into a transaction that was not broadcast yet are kept with a warning.
+
+Inbound transfers to several multisigs, e.g. one per corridor, can be parsed in one run by repeating
+--multisig-address or listing "multisig_addresses" in the config file. The height range is scanned
+once and the routes of each multisig are saved to their own file, named after the output file with
+the multisig address appended, e.g. routes-celestia1abc....json.
+
+Height range runs save a checkpoint every --checkpoint-interval heights to a file named after the
+output file, e.g. routes-checkpoint.json, with the last completed height and the routes found so far.
+If the run is interrupted, re-run it with the same flags and --resume to continue after the
+checkpoint instead of starting over at --from-height. The checkpoint is removed once the run completes.
+
+With --format csv, the routes are also saved as a flat CSV file next to the output file, e.g.
+routes.csv, with one row per route: tx hash, height, sender, destination domain, recipient, token ID,
+amount, denom and status (parsed, or sent for outbound transfers), for import into accounting tools.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			if direction != directionInbound && direction != directionOutbound {
+				return fmt.Errorf("invalid --direction %q: must be %s or %s", direction, directionInbound, directionOutbound)
+			}
+			if err := checkFormat(format); err != nil {
+				return err
+			}
+			heightsSet := cmd.Flags().Changed("from-height") || cmd.Flags().Changed("to-height")
+			if len(txHashes) > 0 && heightsSet {
+				return fmt.Errorf("--tx-hash and --from-height/--to-height are mutually exclusive")
+			}
+			if len(txHashes) == 0 && (!cmd.Flags().Changed("from-height") || !cmd.Flags().Changed("to-height")) {
+				return fmt.Errorf("--from-height and --to-height are required unless --tx-hash is set")
+			}
+			if resume && (len(txHashes) > 0 || outputFile == "") {
+				return fmt.Errorf("--resume needs a height range and an --output file")
+			}
+
 			// Load config if provided
 			var config *types.Config
 			var err error
 			if configFile != "" {
-				fmt.Printf("Loading config from %s...\n", configFile)
+				fmt.Fprintf(out, "Loading config from %s...\n", configFile)
 				config, err = types.LoadConfig(configFile)
 				if err != nil {
 					return fmt.Errorf("failed to load config: %w", err)
 				}
-				fmt.Printf("✓ Config loaded with %d domains configured\n", len(config.Whitelist.Domains))
+				fmt.Fprintf(out, "✓ Config loaded with %d domains configured\n", len(config.Whitelist.Domains))
 			}
 
-			// Create parser with or without config
-			var p *parser.Parser
-			if config != nil {
-				p, err = parser.NewParserWithConfig(rpcURL, config)
-			} else {
-				p, err = parser.NewParser(rpcURL)
+			// Narrow the config to a single source chain if requested
+			config, src, multisigs, err := resolveMultisigs(config, source, multisigAddrs)
+			if err != nil {
+				return err
+			}
+			if src != nil {
+				if !cmd.Flags().Changed("rpc-url") && src.RPCURL != "" {
+					rpcURL = src.RPCURL
+				}
+				fmt.Fprintf(out, "Using source %s (multisig %s, rpc %s)\n", src.Name, multisigs[0], rpcURL)
+			}
+			if len(multisigs) > 1 && direction == directionOutbound {
+				return fmt.Errorf("--direction outbound takes a single multisig")
 			}
+
+			// Create parser with or without config
+			p, err := newParser(cmd.Context(), rpcURL, config)
 			if err != nil {
 				return fmt.Errorf("failed to create parser: %w", err)
 			}
 			defer p.Close()
+			p.SetStrict(strict)
 
-			// Parse routes
-			fmt.Printf("Parsing transactions from height %d to %d...\n", fromHeight, toHeight)
-			routes, err := p.ParseRoutes(multisigAddr, fromHeight, toHeight)
-			if err != nil {
-				return fmt.Errorf("failed to parse routes: %w", err)
+			// Query limits from the config, overridden by flags
+			query := types.DefaultQueryConfig()
+			if config != nil {
+				query = config.Query
+			}
+			if cmd.Flags().Changed("page-size") {
+				query.PageSize = pageSize
+			}
+			if cmd.Flags().Changed("max-pages-per-height") {
+				query.MaxPagesPerHeight = maxPages
+			}
+			if cmd.Flags().Changed("max-txs") {
+				query.MaxTxs = maxTxs
+			}
+			if cmd.Flags().Changed("concurrency") {
+				query.Concurrency = concurrency
+			}
+			p.SetQueryConfig(query)
+
+			if cacheDir != "" {
+				cache, err := client.NewResponseCache(cacheDir, cacheTTL)
+				if err != nil {
+					return err
+				}
+				p.SetCache(cache)
+			}
+			p.SetStrictDecode(strictDecode)
+			if progress {
+				p.SetProgress(printProgress(time.Second))
 			}
 
-			fmt.Printf("Found %d routes with total amount: %s\n", len(routes.Routes), routes.TotalAmount)
+			// Checkpoint height range runs next to the output file, and continue from the last one if asked
+			var checkpoint string
+			if len(txHashes) == 0 && outputFile != "" && checkpointGap > 0 {
+				checkpoint = checkpointFile(outputFile)
+				p.SetCheckpoint(saveCheckpoint(checkpoint), checkpointGap)
+			}
+			if resume {
+				cp, err := loadCheckpoint(checkpointFile(outputFile))
+				if err != nil {
+					return err
+				}
+				if cp == nil {
+					fmt.Fprintf(out, "No checkpoint at %s, starting at height %d\n", checkpointFile(outputFile), fromHeight)
+				} else {
+					routes := 0
+					for _, m := range cp.Multisigs {
+						routes += len(m.Routes)
+					}
+					fmt.Fprintf(out, "Resuming after height %d from %s, %d routes found so far\n", cp.Height, checkpointFile(outputFile), routes)
+					p.SetResume(cp)
+				}
+			}
 
-			// Output results
-			data, err := json.MarshalIndent(routes, "", "  ")
+			ledger, store, err := stateOpts.open(cmd.Context())
 			if err != nil {
-				return fmt.Errorf("failed to marshal routes: %w", err)
+				return err
+			}
+			if store != nil {
+				defer store.Close()
+			}
+			if blockTimes {
+				// Keep the fetched headers in the state database, if one is set
+				headers, _ := store.(storage.HeaderStorage)
+				p.SetBlockTimes(true, headers)
 			}
 
-			if outputFile != "" {
-				if err := os.WriteFile(outputFile, data, 0644); err != nil {
-					return fmt.Errorf("failed to write output file: %w", err)
+			// Parse routes, once per multisig for transactions and in a single scan for a height range
+			var results []*parser.ParseResult
+			switch {
+			case len(txHashes) > 0:
+				fmt.Fprintf(out, "Parsing %s transfers in %d transactions...\n", direction, len(txHashes))
+				for _, multisigAddr := range multisigs {
+					var result *parser.ParseResult
+					if direction == directionOutbound {
+						result, err = p.ParseOutgoingFromTxs(multisigAddr, txHashes)
+					} else {
+						result, err = p.ParseRoutesFromTxs(multisigAddr, txHashes)
+					}
+					if err != nil {
+						break
+					}
+					results = append(results, result)
+				}
+			default:
+				fmt.Fprintf(out, "Parsing %s transactions from height %d to %d...\n", direction, fromHeight, toHeight)
+				var result *parser.ParseResult
+				switch {
+				case direction == directionOutbound:
+					result, err = p.ParseOutgoing(multisigs[0], fromHeight, toHeight)
+				case len(multisigs) == 1:
+					result, err = p.ParseRoutes(multisigs[0], fromHeight, toHeight)
+				default:
+					results, err = p.ParseRoutesForMultisigs(multisigs, fromHeight, toHeight)
+				}
+				if result != nil {
+					results = []*parser.ParseResult{result}
 				}
-				fmt.Printf("Routes saved to %s\n", outputFile)
-			} else {
-				fmt.Println(string(data))
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse routes: %w", err)
 			}
 
+			for _, result := range results {
+				if len(results) > 1 {
+					fmt.Fprintf(out, "\nMultisig %s:\n", result.Routes.MultisigAddr)
+				}
+				if err := reportParse(cmd.Context(), out, result, config, ledger, direction, format, multisigFile(outputFile, result.Routes.MultisigAddr, len(results) > 1)); err != nil {
+					return err
+				}
+			}
+			if checkpoint != "" || resume {
+				return removeCheckpoint(checkpointFile(outputFile))
+			}
 			return nil
-		},
+		}),
 	}
 
-	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig address to filter transactions (required)")
-	cmd.Flags().Int64Var(&fromHeight, "from-height", 0, "Starting block height (required)")
-	cmd.Flags().Int64Var(&toHeight, "to-height", 0, "Ending block height (required)")
-	cmd.Flags().StringVar(&rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL")
+	cmd.Flags().StringArrayVar(&multisigAddrs, "multisig-address", nil, "Multisig address to filter transactions (repeatable; required unless --source or multisig_addresses is set)")
+	cmd.Flags().Int64Var(&fromHeight, "from-height", 0, "Starting block height (required unless --tx-hash is set)")
+	cmd.Flags().Int64Var(&toHeight, "to-height", 0, "Ending block height (required unless --tx-hash is set)")
+	cmd.Flags().StringArrayVar(&txHashes, "tx-hash", nil, "Parse this transaction instead of a height range (repeatable)")
+	cmd.Flags().StringVar(&rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL, or a CometBFT RPC URL such as http://localhost:26657")
 	cmd.Flags().StringVarP(&outputFile, "output", "o", "routes.json", "Output file for routes")
 	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file for address whitelisting")
-
-	cmd.MarkFlagRequired("multisig-address")
-	cmd.MarkFlagRequired("from-height")
-	cmd.MarkFlagRequired("to-height")
+	cmd.Flags().StringVar(&source, "source", "", "Name of a source chain from the config's sources list")
+	cmd.Flags().StringVar(&direction, "direction", directionInbound, "Transfers to extract: inbound (received by the multisig) or outbound (sent by the multisig)")
+	addFormatFlag(cmd, &format, "the routes as CSV next to the output file, e.g. routes.csv")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Abort on the first height that cannot be queried instead of recording it and continuing")
+	cmd.Flags().BoolVar(&strictDecode, "strict-decode", false, "Fail if any transaction or transfer message cannot be decoded")
+	cmd.Flags().BoolVar(&blockTimes, "block-times", false, "Record the time of each route's block; headers are kept in the --state database")
+	cmd.Flags().Uint64Var(&pageSize, "page-size", types.DefaultPageSize, "Transactions requested per query page")
+	cmd.Flags().IntVar(&maxPages, "max-pages-per-height", types.DefaultMaxPagesPerHeight, "Query pages fetched per height before the height is reported as failed")
+	cmd.Flags().IntVar(&maxTxs, "max-txs", 0, "Abort if the range contains more than this many transactions (0 = unlimited)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", types.DefaultConcurrency, "Transactions fetched in parallel with --tx-hash")
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Optional directory to cache block query responses in, for repeated scans")
+	cmd.Flags().BoolVar(&progress, "progress", false, "Print progress (height, transactions and routes so far) to stderr while parsing")
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 24*time.Hour, "Maximum age of cached responses (0 = never expire)")
+	cmd.Flags().Int64Var(&checkpointGap, "checkpoint-interval", parser.DefaultCheckpointInterval, "Heights between checkpoints of a height range run (0 = no checkpoints)")
+	cmd.Flags().BoolVar(&resume, "resume", false, "Continue an interrupted height range run from its checkpoint")
+	addStateFlags(cmd, &stateOpts)
+	outputFlag(cmd, "output")
 
 	return cmd
 }
 
-func generateCmd() *cobra.Command {
+// reportParse prints the routes, skipped transfers and failures of a parse result and saves the
+// routes to outputFile, or prints them if it is empty. Inbound routes whose deposits ledger records
+// as rebalanced are left out. With the csv format, the routes are also saved as CSV next to
+// outputFile, or printed as CSV instead of JSON.
+func reportParse(ctx context.Context, out *commandOutput, result *parser.ParseResult, config *types.Config, ledger *state.Ledger, direction, format, outputFile string) error {
+	routes := result.Routes
+
+	fmt.Fprintf(out, "Found %d routes with total amount: %s\n", len(routes.Routes), routes.TotalAmount)
+
+	if ledger != nil && direction == directionInbound {
+		dropped, err := dropRebalanced(ctx, out, ledger, routes, true)
+		if err != nil {
+			return err
+		}
+		if dropped > 0 {
+			fmt.Fprintf(out, "Left out %d routes already rebalanced, %d routes remain with total amount: %s\n", dropped, len(routes.Routes), routes.TotalAmount)
+		}
+	}
+
+	strategyConfig := types.StrategyConfig{}
+	if config != nil {
+		strategyConfig = config.Strategy
+	}
+	warnDuplicates(out, routes, strategyConfig)
+	warnAmountMismatches(out, routes.Routes)
+
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(out, "Skipped %d transactions:\n", len(result.Skipped))
+		for _, s := range result.Skipped {
+			fmt.Fprintf(out, "  ⚠ tx %s (height %d, amount %s): %s\n", s.TxHash, s.BlockHeight, s.Amount, s.Reason)
+		}
+
+		if outputFile != "" {
+			skippedFile := siblingFile(outputFile, "skipped")
+			data, err := json.MarshalIndent(result.Skipped, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal skipped transactions: %w", err)
+			}
+			if err := output.WriteAtomic(skippedFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write skipped transactions: %w", err)
+			}
+			fmt.Fprintf(out, "Skipped transactions saved to %s\n", skippedFile)
+		}
+	}
+
+	if len(result.DecodeErrors) > 0 {
+		fmt.Fprintf(out, "⚠ %d transactions or messages could not be decoded and may hide transfers:\n", len(result.DecodeErrors))
+		for _, d := range result.DecodeErrors {
+			fmt.Fprintf(out, "  tx %s (height %d, %s): %s\n", d.TxHash, d.Height, d.TypeURL, d.Err)
+		}
+	}
+
+	if len(result.FailedHeights) > 0 {
+		fmt.Fprintf(out, "✗ Failed to query %d heights, their transfers are missing from the routes:\n", len(result.FailedHeights))
+		for _, f := range result.FailedHeights {
+			fmt.Fprintf(out, "  height %d: %s\n", f.Height, f.Error)
+		}
+
+		if outputFile != "" {
+			failedFile := siblingFile(outputFile, "failed-heights")
+			data, err := json.MarshalIndent(result.FailedHeights, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal failed heights: %w", err)
+			}
+			if err := output.WriteAtomic(failedFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write failed heights: %w", err)
+			}
+			fmt.Fprintf(out, "Failed heights saved to %s, re-run parse over them to retry\n", failedFile)
+		}
+	}
+
+	// Output results
+	out.emit(routes)
+	data, err := json.MarshalIndent(routes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal routes: %w", err)
+	}
+
+	status := string(storage.RouteParsed)
+	if direction == directionOutbound {
+		status = "sent"
+	}
+	if outputFile == "" {
+		if format == formatCSV {
+			return routesCSV(routes, status)(out)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	if err := output.WriteAtomic(outputFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	fmt.Fprintf(out, "Routes saved to %s\n", outputFile)
+	if format == formatCSV {
+		if err := writeCSVFile(csvFile(outputFile), routesCSV(routes, status)); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Routes saved as CSV to %s\n", csvFile(outputFile))
+	}
+
+	return nil
+}
+
+// writeBatches splits msgs into several unsigned transactions with consecutive sequences and
+// writes them alongside a manifest recording the sequence assigned to each batch
+func writeBatches(out *commandOutput, gen *generator.Generator, msgs []sdk.Msg, opts generator.TxOptions, outputFile string, maxMsgsPerTx int, accountNumber, sequence uint64, encryptTo []string) error {
+	manifest := &generator.BatchManifest{
+		MultisigAddr:  gen.MultisigAddr(),
+		AccountNumber: accountNumber,
+		Recipients:    encryptTo,
+	}
+
+	for i, chunk := range generator.SplitMsgs(msgs, maxMsgsPerTx) {
+		unsignedTx, err := gen.BuildUnsignedTx(chunk, opts)
+		if err != nil {
+			return fmt.Errorf("failed to build unsigned transaction for batch %d: %w", i+1, err)
+		}
+
+		batch := generator.Batch{
+			Index:    i + 1,
+			Sequence: sequence + uint64(i),
+			File:     generator.BatchFileName(outputFile, i+1),
+			MsgCount: len(chunk),
+		}
+		generator.SetSequence(unsignedTx, batch.Sequence)
+
+		data, err := generator.MarshalTxJSON(unsignedTx)
+		if err != nil {
+			return fmt.Errorf("failed to marshal transaction for batch %d: %w", batch.Index, err)
+		}
+		batch.File, err = output.WriteFile(batch.File, data, encryptTo)
+		if err != nil {
+			return fmt.Errorf("failed to write batch %d: %w", batch.Index, err)
+		}
+
+		fmt.Fprintf(out, "Batch %d: %d messages, sequence %d, saved to %s\n", batch.Index, batch.MsgCount, batch.Sequence, batch.File)
+		manifest.Batches = append(manifest.Batches, batch)
+	}
+
+	manifestFile := generator.ManifestFileName(outputFile)
+	if err := manifest.Save(manifestFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Batch manifest saved to %s\n", manifestFile)
+	out.emit(manifest)
+
+	return nil
+}
+
+// resolveFees returns the fee coins for the fee settings. Without explicit fees, the gas limit
+// is paid at the chain's default gas price.
+func resolveFees(chain types.ChainConfig, feeConfig types.FeeConfig) (sdk.Coins, error) {
+	if feeConfig.Amount == "" {
+		return chain.FeeForGas(feeConfig.GasLimit)
+	}
+
+	feeCoins, err := sdk.ParseCoinsNormalized(feeConfig.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fees %q: %w", feeConfig.Amount, err)
+	}
+	return feeCoins, nil
+}
+
+// multisigBalances returns the balances given on the command line, or queries them from the chain
+func multisigBalances(ctx context.Context, balances, rpcURL, multisigAddr string) (sdk.Coins, error) {
+	if balances != "" {
+		coins, err := sdk.ParseCoinsNormalized(balances)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balances %q: %w", balances, err)
+		}
+		return coins, nil
+	}
+
+	c, err := dialChain(ctx, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	return c.GetBalances(multisigAddr)
+}
+
+// projectMultisig projects the multisig balances after msgs execute. With rpcURL, the warp tokens are
+// looked up so transfers are charged to the denom their token takes from the multisig.
+func projectMultisig(ctx context.Context, gen *generator.Generator, routes *types.Routes, msgs []sdk.Msg, fees sdk.Coins, balances, rpcURL, multisigAddr string) (*generator.BalanceProjection, error) {
+	before, err := multisigBalances(ctx, balances, rpcURL, multisigAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if rpcURL != "" {
+		c, err := dialChain(ctx, rpcURL)
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+
+		tokens, err := c.GetWarpTokens()
+		if err != nil {
+			return nil, err
+		}
+		denoms := make(map[string]string, len(tokens))
+		for _, token := range tokens {
+			denoms[token.Id] = client.TokenDenom(token)
+		}
+		if err := gen.SetTokenDenoms(denoms); err != nil {
+			return nil, err
+		}
+	}
+
+	projection, err := gen.ProjectBalances(routes, msgs, before, fees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to project balances: %w", err)
+	}
+	return projection, nil
+}
+
+// printProjection prints the projected balances section
+func printProjection(out io.Writer, projection *generator.BalanceProjection) {
+	fmt.Fprintf(out, "\nProjected balances for %s:\n", projection.Address)
+	fmt.Fprintf(out, "  %-12s %20s %20s %14s %20s\n", "DENOM", "BEFORE", "OUTBOUND", "FEES", "AFTER")
+	for _, d := range projection.Denoms {
+		marker := ""
+		if d.Overdrawn {
+			marker = "  ✗ OVERDRAWN"
+		}
+		fmt.Fprintf(out, "  %-12s %20s %20s %14s %20s%s\n", d.Denom, d.Before, d.Outbound, d.Fees, d.After, marker)
+	}
+}
+
+func resequenceCmd() *cobra.Command {
 	var (
-		routesFile   string
-		multisigAddr string
-		outputFile   string
+		manifestFile string
+		dropped      int
 	)
 
 	cmd := &cobra.Command{
-		Use:   "generate",
-		Short: "Generate unsigned multisig transaction from routes",
-		Long:  `Generate unsigned Hyperlane MsgRemoteTransfer transactions from parsed routes.`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// Create generator
-			gen := generator.NewGenerator(multisigAddr)
-
-			// Generate messages
-			fmt.Printf("Generating transactions from %s...\n", routesFile)
-			msgs, err := gen.GenerateFromFile(routesFile)
+		Use:   "resequence",
+		Short: "Rebuild batch sign docs after an earlier batch was dropped",
+		Long: `Mark a batch in a batch manifest (written by 'generate --max-msgs-per-tx') as dropped and
+reassign consecutive sequences to the batches after it, rewriting their unsigned transactions.
+
+Signatures collected for rewritten batches are no longer valid and must be collected again.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			manifest, err := generator.LoadBatchManifest(manifestFile)
 			if err != nil {
-				return fmt.Errorf("failed to generate transactions: %w", err)
+				return err
 			}
 
-			fmt.Printf("Generated %d MsgRemoteTransfer messages\n", len(msgs))
-
-			// Output transaction
-			// Note: This outputs the messages in JSON format
-			// For actual signing, you'll need to use celestia-appd or Keplr
-			data, err := json.MarshalIndent(msgs, "", "  ")
+			changed, err := manifest.Resequence(dropped)
 			if err != nil {
-				return fmt.Errorf("failed to marshal messages: %w", err)
+				return err
 			}
 
-			if outputFile != "" {
-				if err := os.WriteFile(outputFile, data, 0644); err != nil {
-					return fmt.Errorf("failed to write output file: %w", err)
+			for _, batch := range changed {
+				data, err := output.ReadFile(batch.File)
+				if err != nil {
+					return fmt.Errorf("failed to read batch %d: %w", batch.Index, err)
+				}
+
+				unsignedTx, err := generator.UnmarshalTxJSON(data)
+				if err != nil {
+					return fmt.Errorf("failed to parse batch %d: %w", batch.Index, err)
 				}
-				fmt.Printf("Messages saved to %s\n", outputFile)
-			} else {
-				fmt.Println(string(data))
+				generator.SetSequence(unsignedTx, batch.Sequence)
+
+				data, err = generator.MarshalTxJSON(unsignedTx)
+				if err != nil {
+					return fmt.Errorf("failed to marshal batch %d: %w", batch.Index, err)
+				}
+				if _, err := output.WriteFile(batch.File, data, manifest.Recipients); err != nil {
+					return fmt.Errorf("failed to write batch %d: %w", batch.Index, err)
+				}
+
+				fmt.Fprintf(out, "Batch %d resequenced to %d (%s)\n", batch.Index, batch.Sequence, batch.File)
 			}
 
-			fmt.Println("\nNext steps:")
-			fmt.Println("1. Review the generated messages")
-			fmt.Println("2. Use 'celestia-rebalancer verify' to validate")
-			fmt.Println("3. Create multisig transaction using celestia-appd or Keplr")
+			if err := manifest.Save(manifestFile); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "Batch %d marked as dropped, %d batches rebuilt\n", dropped, len(changed))
+			if len(changed) > 0 {
+				fmt.Fprintln(out, "Signatures for rebuilt batches must be collected again.")
+			}
+			out.emit(manifest)
 
 			return nil
-		},
+		}),
 	}
 
-	cmd.Flags().StringVar(&routesFile, "routes", "routes.json", "Input routes file")
-	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig address (sender) (required)")
-	cmd.Flags().StringVarP(&outputFile, "output", "o", "unsigned-tx.json", "Output file for unsigned transaction")
+	cmd.Flags().StringVar(&manifestFile, "manifest", "unsigned-tx-batches.json", "Batch manifest written by generate")
+	cmd.Flags().IntVar(&dropped, "dropped", 0, "Index of the batch that was dropped (required)")
 
-	cmd.MarkFlagRequired("multisig-address")
+	cmd.MarkFlagRequired("dropped")
+	stepInputFlag(cmd, "manifest")
 
 	return cmd
 }
 
 func verifyCmd() *cobra.Command {
 	var (
-		routesFile string
-		txFile     string
+		routesFile      string
+		txFile          string
+		attestationFile string
+		operatorKeys    []string
+		configFile      string
+		againstChain    bool
+		live            bool
+		replay          replayOptions
+		broadcast       bool
+		broadcastOpts   broadcastOptions
+		strict          bool
+		format          string
+		maxRouteAge     int64
+		chainHeight     int64
 	)
 
 	cmd := &cobra.Command{
 		Use:   "verify",
 		Short: "Verify that a transaction matches the intended routes",
-		Long:  `Verify that a multisig transaction contains the correct MsgRemoteTransfer messages matching the parsed routes.`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// Create verifier
+		Long: `Verify that a multisig transaction contains the correct MsgRemoteTransfer messages matching the parsed routes.
+
+With --against-chain, verify instead replays a past window: it reconstructs the routes the deposits
+between --from-height and --to-height should have produced and checks them against the transfers the
+multisig actually sent, up to --outbound-to-height. A compliance report is written to --report.
+
+With --strict, every message of the transaction must be accounted for: messages other than
+MsgRemoteTransfer (or an authz MsgExec of them), transfers not sent by the multisig and transfers
+matching no route fail verification, and each one is reported by its position in the transaction.
+
+With --live, a transaction matching its routes is also checked against the current state of the
+chain at --rpc-url, for a go/no-go summary before signing: the multisig must hold the transferred
+amounts, interchain gas and fees it pays, every token must have a router enrolled for its
+destination, the transaction must be signed at its signer's current sequence, and no deposit may
+have been refunded by the multisig since it was made. A no-go fails verification.
+
+With --broadcast, a fully signed transaction that passes verification is submitted to --rpc-url and
+verify waits for its inclusion, so nothing is broadcast that does not match the routes.
+
+With --format csv, the outcome of every route is also saved as a flat CSV file next to the
+transaction, e.g. unsigned-tx-verification.csv: tx hash, height, sender, destination domain,
+recipient, token ID, amount, denom and status (matched, unmatched, or unverified).
+
+With --max-route-age, or warn_route_age_blocks and max_route_age_blocks in the config's limits, the
+deposit height of every route is compared with the chain's current height, queried from --rpc-url or
+given with --chain-height on offline hosts. Routes older than the warning age are listed under the
+warnings and routes older than the max age fail verification, since a stale route may have been
+handled manually already and signing it would pay the deposit twice.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			// Create verifier, checking authz execution against the configured grant if any
 			v := verifier.NewVerifier()
+			config := &types.Config{Chain: types.DefaultChainConfig(), Query: types.DefaultQueryConfig()}
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+				v = newVerifier(config)
+				v.SetDisplay(config.Destinations)
+			}
+			v.SetStrict(strict)
+			v.SetOutput(out)
+			if maxRouteAge < 0 || chainHeight < 0 {
+				return fmt.Errorf("--max-route-age and --chain-height must not be negative")
+			}
+			if maxRouteAge > 0 {
+				config.Limits.MaxRouteAgeBlocks = maxRouteAge
+			}
+			v.SetRouteAges(config.Limits.WarnRouteAgeBlocks, config.Limits.MaxRouteAgeBlocks)
+
+			if againstChain && broadcast {
+				return fmt.Errorf("--broadcast cannot be combined with --against-chain")
+			}
+			if againstChain && live {
+				return fmt.Errorf("--live cannot be combined with --against-chain")
+			}
+			if err := checkFormat(format); err != nil {
+				return err
+			}
+			if againstChain && format == formatCSV {
+				return fmt.Errorf("--format csv cannot be combined with --against-chain")
+			}
+
+			if againstChain {
+				compliant, err := replayAgainstChain(cmd.Context(), out, v, config, replay)
+				if err != nil {
+					return err
+				}
+				if !compliant {
+					out.exit(1)
+				}
+				return nil
+			}
+
+			if config.Limits.ChecksRouteAge() {
+				if chainHeight == 0 {
+					c, err := dialChain(cmd.Context(), replay.rpcURL)
+					if err != nil {
+						return fmt.Errorf("failed to query the chain height for route ages, use --chain-height on offline hosts: %w", err)
+					}
+					chainHeight, err = c.LatestHeight()
+					c.Close()
+					if err != nil {
+						return fmt.Errorf("failed to query the chain height for route ages, use --chain-height on offline hosts: %w", err)
+					}
+				}
+				v.SetChainHeight(chainHeight)
+			}
 
 			// Verify
-			fmt.Printf("Verifying transaction against routes...\n\n")
+			fmt.Fprintf(out, "Verifying transaction against routes...\n\n")
 			result, err := v.VerifyFromFiles(routesFile, txFile)
 			if err != nil {
 				return fmt.Errorf("verification failed: %w", err)
@@ -215,17 +821,121 @@ func verifyCmd() *cobra.Command {
 
 			// Print result
 			v.PrintResult(result)
+			out.emit(result)
+
+			if format == formatCSV {
+				routes, err := loadRoutes(routesFile)
+				if err != nil {
+					return err
+				}
+				reportFile := csvFile(siblingFile(txFile, "verification"))
+				if err := writeCSVFile(reportFile, func(w io.Writer) error { return verifier.WriteCSV(w, routes, result) }); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Verification report saved as CSV to %s\n", reportFile)
+			}
+
+			if attestationFile != "" {
+				if err := verifyAttestation(out, attestationFile, routesFile, txFile, operatorKeys); err != nil {
+					fmt.Fprintf(out, "✗ Attestation check FAILED: %v\n", err)
+					out.exit(1)
+				}
+			}
 
 			if !result.Valid {
-				os.Exit(1)
+				out.exit(1)
+			}
+
+			if live {
+				fmt.Fprintln(out)
+				ok, err := checkLive(cmd.Context(), out, v, config, routesFile, txFile, replay.rpcURL)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					out.exit(1)
+				}
+			}
+
+			if broadcast {
+				fmt.Fprintln(out)
+				broadcastOpts.rpcURL = replay.rpcURL
+				if _, err := broadcastFile(cmd.Context(), out, txFile, broadcastOpts); err != nil {
+					return err
+				}
 			}
 
 			return nil
-		},
+		}),
 	}
 
 	cmd.Flags().StringVar(&routesFile, "routes", "routes.json", "Routes file to verify against")
-	cmd.Flags().StringVar(&txFile, "transaction", "unsigned-tx.json", "Transaction file to verify")
+	cmd.Flags().StringVar(&txFile, "transaction", "unsigned-tx.json", "Transaction file to verify: Cosmos SDK JSON, TxRaw JSON, or base64 or hex encoded tx bytes")
+	cmd.Flags().StringVar(&attestationFile, "attestation", "", "Optional operator attestation to check against the routes and transaction")
+	cmd.Flags().StringArrayVar(&operatorKeys, "operator-key", nil, "Trusted operator public key (hex) for --attestation (repeatable)")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file with the authz grant to check MsgExec transactions against")
+	cmd.Flags().BoolVar(&againstChain, "against-chain", false, "Replay a past window of deposits against the multisig's on-chain outbound transfers")
+	cmd.Flags().StringVar(&replay.multisigAddr, "multisig-address", "", "Multisig address to replay (with --against-chain)")
+	cmd.Flags().BoolVar(&live, "live", false, "Also check the transaction against the chain's current state at --rpc-url")
+	cmd.Flags().StringVar(&replay.rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL (with --against-chain, --live or --broadcast)")
+	cmd.Flags().Int64Var(&replay.fromHeight, "from-height", 0, "First height of the replayed deposit window (with --against-chain)")
+	cmd.Flags().Int64Var(&replay.toHeight, "to-height", 0, "Last height of the replayed deposit window (with --against-chain)")
+	cmd.Flags().Int64Var(&replay.outboundToHeight, "outbound-to-height", 0, "Last height searched for outbound transfers (default: --to-height)")
+	cmd.Flags().StringVar(&replay.reportFile, "report", "compliance-report.json", "Output file for the compliance report (with --against-chain)")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail on any message not accounted for by a route")
+	addFormatFlag(cmd, &format, "the outcome of every route as CSV next to the transaction, e.g. unsigned-tx-verification.csv")
+	cmd.Flags().Int64Var(&maxRouteAge, "max-route-age", 0, "Fail on routes deposited more than this many blocks before the chain height (default: the config's limits.max_route_age_blocks)")
+	cmd.Flags().Int64Var(&chainHeight, "chain-height", 0, "Chain height route ages are measured at (default: the latest height at --rpc-url)")
+	cmd.Flags().BoolVar(&broadcast, "broadcast", false, "Broadcast the fully signed transaction to --rpc-url if it passes verification")
+	addBroadcastFlags(cmd, &broadcastOpts)
+	mutatesFlag(cmd, "broadcast")
+	stepInputFlag(cmd, "routes", "transaction")
+	outputFlag(cmd, "report")
 
 	return cmd
 }
+
+// verifyAttestation checks that the attestation covers the routes and transaction files
+func verifyAttestation(out io.Writer, attestationFile, routesFile, txFile string, operatorKeys []string) error {
+	a, err := attestation.Load(attestationFile)
+	if err != nil {
+		return err
+	}
+
+	routesData, err := os.ReadFile(routesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read routes file: %w", err)
+	}
+	txData, err := output.ReadFile(txFile)
+	if err != nil {
+		return fmt.Errorf("failed to read transaction file: %w", err)
+	}
+
+	if err := a.Verify(routesData, txData, operatorKeys); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "✓ Attestation by operator %s (%s) matches the routes and transaction\n", a.Operator, a.Timestamp.Format(time.RFC3339))
+	if len(operatorKeys) == 0 {
+		fmt.Fprintln(out, "  ⚠ no --operator-key given, the operator's identity was not checked")
+	}
+	return nil
+}
+
+// printProgress returns a progress function printing to stderr at most once per interval, and
+// always when the run completes
+func printProgress(interval time.Duration) parser.ProgressFunc {
+	var last time.Time
+	return func(p parser.Progress) {
+		if !p.Done() && time.Since(last) < interval {
+			return
+		}
+		last = time.Now()
+		if p.ToHeight == 0 {
+			fmt.Fprintf(os.Stderr, "Parsed %d transactions: %d routes, %d skipped\n", p.Transactions, p.Routes, p.Skipped)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Height %d (%d/%d): %d transactions, %d routes, %d skipped, %d failed heights\n",
+			p.Height, p.Heights, p.ToHeight-p.FromHeight+1, p.Transactions, p.Routes, p.Skipped, p.FailedHeights)
+	}
+}
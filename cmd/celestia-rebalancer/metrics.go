@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/metrics"
+	"github.com/spf13/cobra"
+)
+
+// addMetricsFlag registers the flag selecting where a long-running command serves its metrics
+func addMetricsFlag(cmd *cobra.Command, addr *string) {
+	cmd.Flags().StringVar(addr, "metrics-addr", "", "Serve Prometheus metrics at /metrics on this address, e.g. :9464")
+}
+
+// serveMetrics serves the Prometheus metrics on addr in the background until ctx is done. It
+// does nothing if addr is empty, and fails if addr cannot be listened on.
+func serveMetrics(ctx context.Context, out io.Writer, addr string) error {
+	if addr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+	go func() {
+		if err := metrics.Serve(ctx, listener); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ %v\n", err)
+		}
+	}()
+	fmt.Fprintf(out, "Serving metrics at http://%s/metrics\n", listener.Addr())
+	return nil
+}
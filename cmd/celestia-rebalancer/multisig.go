@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/multisig"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/spf13/cobra"
+)
+
+// sessionOptions are the flags shared by sign and combine that identify what is signed
+type sessionOptions struct {
+	txFile        string
+	pubKeyFile    string
+	multisigAddr  string
+	configFile    string
+	chainID       string
+	accountNumber uint64
+	sequence      uint64
+}
+
+func signCmd() *cobra.Command {
+	var (
+		opts       sessionOptions
+		outputFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Write the sign document each multisig key holder signs",
+		Long: `Compute the bytes each key holder of the multisig signs for an unsigned transaction written by
+generate, and save them with the signing context as a sign document.
+
+Key holders sign the unsigned transaction in ` + multisig.SignMode.String() + ` mode with the chain ID,
+account number and sequence of the document, e.g. with celestia-appd tx sign --signature-only
+--sign-mode amino-json --offline, and compare the document's digest among themselves. Their signature
+files are then merged with combine.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			session, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			doc, err := session.SignDoc()
+			if err != nil {
+				return err
+			}
+
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal sign document: %w", err)
+			}
+			if err := output.WriteAtomic(outputFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write sign document: %w", err)
+			}
+
+			fmt.Fprintf(out, "✓ Sign document for %s (%d of %d, sequence %d) saved to %s\n", doc.Multisig, doc.Threshold, len(doc.Members), doc.Sequence, outputFile)
+			fmt.Fprintf(out, "  Digest: %s\n", doc.Digest)
+			out.emit(doc)
+			return nil
+		}),
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "sign-doc.json", "Output file for the sign document")
+	outputFlag(cmd, "output")
+
+	return cmd
+}
+
+func combineCmd() *cobra.Command {
+	var (
+		opts       sessionOptions
+		sigFiles   []string
+		outputFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "combine",
+		Short: "Combine the key holders' partial signatures into a multisig-signed transaction",
+		Long: `Verify the partial signatures of the multisig's key holders against the unsigned transaction and
+merge them into a transaction signed by the multisig, ready for verify and broadcast.
+
+Each --signature is a file written by celestia-appd tx sign (with or without --signature-only); pass
+"-" to read one from stdin. Every signature must be by a member of the multisig, over the sign
+document's chain ID, account number and sequence, and at least the multisig's threshold of members
+must have signed.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			if len(sigFiles) == 0 {
+				return fmt.Errorf("at least one --signature is required")
+			}
+			session, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			var partials []signingtypes.SignatureV2
+			for _, file := range sigFiles {
+				data, err := readSignatureFile(file)
+				if err != nil {
+					return err
+				}
+				sigs, err := session.ParseSignatures(data)
+				if err != nil {
+					return fmt.Errorf("%s: %w", file, err)
+				}
+				partials = append(partials, sigs...)
+			}
+
+			signed, err := session.Combine(partials)
+			if err != nil {
+				return err
+			}
+			if err := output.WriteAtomic(outputFile, signed, 0644); err != nil {
+				return fmt.Errorf("failed to write signed transaction: %w", err)
+			}
+
+			fmt.Fprintf(out, "✓ Combined %d signatures for %s, signed transaction saved to %s\n", len(partials), session.Address(), outputFile)
+			fmt.Fprintf(out, "\nVerify and broadcast it with:\n  celestia-rebalancer verify --transaction %s --broadcast\n", outputFile)
+			out.emit(json.RawMessage(signed))
+			return nil
+		}),
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().StringArrayVar(&sigFiles, "signature", nil, "Partial signature file of a key holder, or - for stdin (repeatable)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "signed-tx.json", "Output file for the signed transaction")
+	outputFlag(cmd, "output")
+
+	return cmd
+}
+
+// addFlags registers the session flags on cmd
+func (o *sessionOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.txFile, "transaction", "unsigned-tx.json", "Unsigned transaction file written by generate")
+	cmd.Flags().StringVar(&o.pubKeyFile, "pubkey-file", "", "Multisig public key JSON, as printed by celestia-appd keys show --pubkey (required)")
+	cmd.Flags().StringVar(&o.multisigAddr, "multisig-address", "", "Optional multisig address the public key must match")
+	cmd.Flags().StringVarP(&o.configFile, "config", "c", "", "Optional config file with chain settings")
+	cmd.Flags().StringVar(&o.chainID, "chain-id", "", "Chain ID the transaction is signed for (required)")
+	cmd.Flags().Uint64Var(&o.accountNumber, "account-number", 0, "Multisig account number")
+	cmd.Flags().Uint64Var(&o.sequence, "sequence", 0, "Multisig account sequence (default: the sequence recorded by generate)")
+	stepInputFlag(cmd, "transaction")
+}
+
+// session loads the unsigned transaction and multisig key named by the flags
+func (o *sessionOptions) session(cmd *cobra.Command) (*multisig.Session, error) {
+	if o.pubKeyFile == "" {
+		return nil, fmt.Errorf("--pubkey-file is required")
+	}
+	if o.chainID == "" {
+		return nil, fmt.Errorf("--chain-id is required")
+	}
+
+	chain := types.DefaultChainConfig()
+	if o.configFile != "" {
+		config, err := types.LoadConfig(o.configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		chain = config.Chain
+	}
+
+	txData, err := output.ReadFile(o.txFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction file: %w", err)
+	}
+	pubKeyData, err := os.ReadFile(o.pubKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key file: %w", err)
+	}
+
+	// Batches written by generate record their sequence in the first signer info
+	sequence := o.sequence
+	if !cmd.Flags().Changed("sequence") {
+		unsignedTx, err := generator.UnmarshalTxJSON(txData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transaction file: %w", err)
+		}
+		if infos := unsignedTx.AuthInfo.GetSignerInfos(); len(infos) > 0 {
+			sequence = infos[0].Sequence
+		}
+	}
+
+	session, err := multisig.NewSession(txData, pubKeyData, multisig.Params{
+		ChainID:       o.chainID,
+		Bech32Prefix:  chain.Bech32Prefix,
+		AccountNumber: o.accountNumber,
+		Sequence:      sequence,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if o.multisigAddr != "" && session.Address() != o.multisigAddr {
+		return nil, fmt.Errorf("public key is for %s, not %s", session.Address(), o.multisigAddr)
+	}
+	return session, nil
+}
+
+// readSignatureFile reads a partial signature file, or stdin for "-"
+func readSignatureFile(path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature from stdin: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature file: %w", err)
+	}
+	return data, nil
+}
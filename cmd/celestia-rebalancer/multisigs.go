@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// resolveMultisigs returns the multisigs a command runs for: those given with --multisig-address,
+// or else the config's multisig_addresses. With --source, the config is narrowed to the source and
+// its multisig is used unless a single address is given.
+func resolveMultisigs(config *types.Config, source string, addrs []string) (*types.Config, *types.SourceConfig, []string, error) {
+	var src *types.SourceConfig
+	switch {
+	case source != "":
+		if len(addrs) > 1 {
+			return nil, nil, nil, fmt.Errorf("--source selects a single multisig and cannot be combined with several --multisig-address")
+		}
+		var multisigAddr string
+		if len(addrs) == 1 {
+			multisigAddr = addrs[0]
+		}
+		var err error
+		config, src, err = selectSource(config, source, &multisigAddr)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		addrs = []string{multisigAddr}
+	case len(addrs) == 0 && config != nil:
+		addrs = config.MultisigAddrs
+	}
+	if len(addrs) == 0 || addrs[0] == "" {
+		return nil, nil, nil, fmt.Errorf("--multisig-address is required")
+	}
+
+	chain := types.DefaultChainConfig()
+	if config != nil {
+		chain = config.Chain
+	}
+	seen := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		if err := chain.ValidateAddress(addr); err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid multisig address: %w", err)
+		}
+		if seen[addr] {
+			return nil, nil, nil, fmt.Errorf("multisig %s is given twice", addr)
+		}
+		seen[addr] = true
+	}
+	return config, src, addrs, nil
+}
+
+// multisigFile returns the file of one multisig's routes or transaction when a run covers several
+// multisigs: path with the multisig address appended, e.g. routes-celestia1abc....json. A run for a
+// single multisig uses path itself.
+func multisigFile(path, multisigAddr string, several bool) string {
+	if !several || path == "" {
+		return path
+	}
+	return siblingFile(path, multisigAddr)
+}
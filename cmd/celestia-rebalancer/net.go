@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// netResult is the result of net in JSON output mode: the netted corridors and the file each
+// domain's netted routes were saved to
+type netResult struct {
+	Corridors []strategy.NettingResult `json:"corridors"`
+	Files     map[uint32]string        `json:"files"`
+}
+
+func netCmd() *cobra.Command {
+	var (
+		routesFiles []string
+		configFile  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "net",
+		Short: "Cancel offsetting amounts between opposing corridors",
+		Long: `Net route sets parsed on different source chains against each other. When deposits on
+chain A are destined to chain B while deposits on chain B are destined to chain A, the smaller
+flow is cancelled against the larger one and only the net difference is kept, reducing bridge fees.
+Only flows over the same warp route are netted against each other. Token IDs differ on each
+chain, so the config lists the token of every warp route on each domain in "warp_routes"; routes
+whose token is not listed there are never netted.
+
+Each route set is given as <source>=<routes file>, where <source> is either the name of a source
+in the config (which must define its Hyperlane "domain") or a numeric domain ID. Netted routes are
+written next to each input as <routes file>-netted.json.
+
+  celestia-rebalancer net --config config.json \
+    --routes celestia=routes-celestia.json \
+    --routes neutron=routes-neutron.json`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config, err := types.LoadConfig(configFile)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			if len(config.WarpRoutes) == 0 {
+				return fmt.Errorf("config defines no warp_routes, netting needs the token of each warp route on every domain")
+			}
+			warpRoutes, err := types.NewWarpRouteIndex(config.WarpRoutes)
+			if err != nil {
+				return fmt.Errorf("invalid warp_routes: %w", err)
+			}
+
+			routeSets := make(map[uint32]*types.Routes)
+			files := make(map[uint32]string)
+			for _, arg := range routesFiles {
+				name, file, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("invalid --routes %q, expected <source>=<file>", arg)
+				}
+
+				domain, err := resolveSourceDomain(config, name)
+				if err != nil {
+					return err
+				}
+				if _, exists := routeSets[domain]; exists {
+					return fmt.Errorf("domain %d given more than once", domain)
+				}
+
+				routes, err := loadRoutes(file)
+				if err != nil {
+					return err
+				}
+				routeSets[domain] = routes
+				files[domain] = file
+			}
+
+			results, err := strategy.NetCorridors(routeSets, warpRoutes)
+			if err != nil {
+				return fmt.Errorf("netting failed: %w", err)
+			}
+
+			if len(results) == 0 {
+				fmt.Fprintln(out, "No opposing flows found, nothing to net")
+			}
+			for _, r := range results {
+				fmt.Fprintf(out, "Corridor %d <-> %d (%s): %s vs %s, offset %s", r.DomainA, r.DomainB, r.WarpRoute, r.AmountAB, r.AmountBA, r.Offset)
+				if r.NetFrom != 0 {
+					fmt.Fprintf(out, ", net %s from domain %d\n", r.NetAmount, r.NetFrom)
+				} else {
+					fmt.Fprintln(out, ", fully offset")
+				}
+			}
+
+			netted := make(map[uint32]string)
+			for domain, routes := range routeSets {
+				data, err := json.MarshalIndent(routes, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal routes: %w", err)
+				}
+
+				nettedFile := siblingFile(files[domain], "netted")
+				if err := output.WriteAtomic(nettedFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to write netted routes: %w", err)
+				}
+				fmt.Fprintf(out, "Domain %d: %d routes, total %s, saved to %s\n", domain, len(routes.Routes), routes.TotalAmount, nettedFile)
+				netted[domain] = nettedFile
+			}
+
+			out.emit(netResult{Corridors: results, Files: netted})
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringArrayVar(&routesFiles, "routes", nil, "Route set as <source>=<routes file> (repeatable, at least two)")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file defining source domains and warp routes")
+
+	cmd.MarkFlagRequired("routes")
+	cmd.MarkFlagRequired("config")
+
+	return cmd
+}
+
+// resolveSourceDomain maps a source name from the config, or a numeric domain ID, to a Hyperlane domain
+func resolveSourceDomain(config *types.Config, name string) (uint32, error) {
+	if domain, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(domain), nil
+	}
+
+	_, source, err := config.ForSource(name)
+	if err != nil {
+		return 0, err
+	}
+	if source.Domain == 0 {
+		return 0, fmt.Errorf("source %s has no domain configured", name)
+	}
+	return source.Domain, nil
+}
+
+// loadRoutes reads a routes file
+func loadRoutes(path string) (*types.Routes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes file: %w", err)
+	}
+
+	var routes types.Routes
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return nil, fmt.Errorf("failed to parse routes file: %w", err)
+	}
+
+	return &routes, nil
+}
+
+// siblingFile derives an output path next to path by appending a suffix before the extension,
+// e.g. routes.json becomes routes-netted.json
+func siblingFile(path, suffix string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + suffix + ext
+}
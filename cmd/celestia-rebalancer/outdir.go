@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Annotations of the flags resolved against --out-dir
+const (
+	// outputAnnotation marks flags naming a file the command writes, placed in --out-dir when relative
+	outputAnnotation = "output-file"
+	// stepInputAnnotation marks flags whose default is a file written by an earlier workflow step,
+	// read from --out-dir when the flag is not given
+	stepInputAnnotation = "step-input-file"
+)
+
+// outputFlag marks the flags names of cmd as naming files the command writes
+func outputFlag(cmd *cobra.Command, names ...string) {
+	for _, name := range names {
+		cmd.Flags().SetAnnotation(name, outputAnnotation, []string{"true"})
+	}
+}
+
+// stepInputFlag marks the flags names of cmd as defaulting to a file an earlier step wrote, e.g.
+// generate's --routes defaulting to the routes.json parse wrote
+func stepInputFlag(cmd *cobra.Command, names ...string) {
+	for _, name := range names {
+		cmd.Flags().SetAnnotation(name, stepInputAnnotation, []string{"true"})
+	}
+}
+
+// resolveOutDir places the relative output files of cmd in dir, the --out-dir flag, or else in the
+// "output_dir" of the command's config, creating the directory. Inputs left at their default are
+// read from there too, so the steps of the workflow find each other's files. Files derived from an
+// input file, such as routes-planned.json next to the routes, stay where the input is.
+func resolveOutDir(cmd *cobra.Command, dir string) error {
+	if dir == "" {
+		config, err := commandConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if config == nil || config.OutputDir == "" {
+			return nil
+		}
+		dir = config.OutputDir
+	}
+
+	var resolved []*pflag.Flag
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Value.String() == "" || filepath.IsAbs(flag.Value.String()) {
+			return
+		}
+		_, output := flag.Annotations[outputAnnotation]
+		_, stepInput := flag.Annotations[stepInputAnnotation]
+		if output || (stepInput && !flag.Changed) {
+			resolved = append(resolved, flag)
+		}
+	})
+	if len(resolved) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, flag := range resolved {
+		if err := flag.Value.Set(filepath.Join(dir, flag.Value.String())); err != nil {
+			return fmt.Errorf("--%s: %w", flag.Name, err)
+		}
+	}
+	return nil
+}
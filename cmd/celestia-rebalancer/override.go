@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/audit"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// applyOverrides applies the overrides file to routes and records every override in the audit
+// trail. It returns the adjusted routes and the number of overrides applied.
+func applyOverrides(out io.Writer, routes *types.Routes, path string, config *types.Config) (*types.Routes, int, error) {
+	if config.AuditLog == "" {
+		return nil, 0, fmt.Errorf("overrides require audit_log to be set in the config")
+	}
+
+	overrides, err := types.LoadOverrides(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	adjusted, err := strategy.ApplyOverrides(routes, overrides.Overrides, config)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	log := audit.NewLog(config.AuditLog)
+	for _, route := range adjusted.Routes {
+		if route.Override == nil {
+			continue
+		}
+		details := route.OverrideSummary()
+		if err := log.Record(audit.EventOverride, route.TxHash, details); err != nil {
+			return nil, 0, err
+		}
+		fmt.Fprintf(out, "⚠ Override applied to tx %s: %s\n", route.TxHash, details)
+	}
+
+	return adjusted, len(overrides.Overrides), nil
+}
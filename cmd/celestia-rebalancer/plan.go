@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"cosmossdk.io/math"
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+)
+
+func planCmd() *cobra.Command {
+	var (
+		routesFile   string
+		multisigAddr string
+		configFile   string
+		source       string
+		rpcURL       string
+		balances     string
+		maxTotals    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show the transfers generate would produce under different strategy parameters",
+		Long: `Evaluate pending routes under several strategy parameters and print the transfers that would be
+generated for each, without writing any files. Use it to tune the "strategy" section of the config.
+
+Every total cap given with --max-total (or the configured cap if none is given) is evaluated with
+aggregation both off and on. Pass --balances or --rpc-url to include the projected multisig balances.
+
+  celestia-rebalancer plan --routes routes.json --config config.json \
+    --balances 5000000utia --max-total none --max-total 1000000`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config := &types.Config{Chain: types.DefaultChainConfig()}
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+			if source != "" {
+				sourceConfig, _, err := selectSource(config, source, &multisigAddr)
+				if err != nil {
+					return err
+				}
+				config = sourceConfig
+			}
+
+			routes, err := loadRoutes(routesFile)
+			if err != nil {
+				return err
+			}
+			routes, _, err = holdQuarantined(out, routes, config)
+			if err != nil {
+				return err
+			}
+			warnDuplicates(out, routes, config.Strategy)
+			if multisigAddr == "" {
+				multisigAddr = routes.MultisigAddr
+			}
+			if multisigAddr == "" {
+				return fmt.Errorf("--multisig-address is required when the routes file does not record one")
+			}
+
+			feeCoins, err := resolveFees(config.Chain, config.Fee)
+			if err != nil {
+				return err
+			}
+			if config.Fee.Payer != "" && config.Fee.Payer != multisigAddr {
+				feeCoins = nil
+			}
+
+			var before sdk.Coins
+			if balances != "" || rpcURL != "" {
+				before, err = multisigBalances(cmd.Context(), balances, rpcURL, multisigAddr)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Build the scenarios: each cap with aggregation off and on
+			caps := []string{config.Strategy.MaxTotalAmount}
+			if len(maxTotals) > 0 {
+				caps = nil
+				for _, c := range maxTotals {
+					if c == "none" {
+						c = ""
+					}
+					caps = append(caps, c)
+				}
+			}
+
+			gen, err := generator.NewGeneratorWithConfig(multisigAddr, config)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Planning %d routes (total %s) from %s\n", len(routes.Routes), routes.TotalAmount, routesFile)
+
+			for _, c := range caps {
+				for _, aggregate := range []bool{false, true} {
+					scenario := types.StrategyConfig{Aggregate: aggregate, MaxTotalAmount: c, Priority: config.Strategy.Priority}
+					if err := printScenario(out, gen, routes, scenario, config, scenario == config.Strategy, before, feeCoins); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&routesFile, "routes", "routes.json", "Input routes file")
+	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig address (default: the address recorded in the routes file)")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file with chain, fee and strategy settings")
+	cmd.Flags().StringVar(&source, "source", "", "Name of a source chain from the config's sources list")
+	cmd.Flags().StringVar(&rpcURL, "rpc-url", "", "Optional gRPC endpoint to query the multisig balance")
+	cmd.Flags().StringVar(&balances, "balances", "", "Multisig balances instead of querying, e.g. 5000000utia")
+	cmd.Flags().StringArrayVar(&maxTotals, "max-total", nil, `Total cap per run to evaluate, or "none" (repeatable, default: the configured cap)`)
+	stepInputFlag(cmd, "routes")
+
+	return cmd
+}
+
+// planScenario is the result of one scenario of plan in JSON output mode
+type planScenario struct {
+	MaxTotalAmount string                       `json:"max_total_amount"`
+	Aggregate      bool                         `json:"aggregate"`
+	Configured     bool                         `json:"configured"`
+	Routes         *types.Routes                `json:"routes"`
+	Deferred       []types.HyperlaneRoute       `json:"deferred,omitempty"`
+	Split          int                          `json:"split,omitempty"`
+	FannedOut      int                          `json:"fanned_out,omitempty"`
+	GasEstimate    *strategy.GasEstimate        `json:"gas_estimate,omitempty"`
+	Projection     *generator.BalanceProjection `json:"projection,omitempty"`
+}
+
+// printScenario applies one strategy to the routes and prints the resulting transfers
+func printScenario(out *commandOutput, gen *generator.Generator, routes *types.Routes, scenario types.StrategyConfig, config *types.Config, configured bool, before, fees sdk.Coins) error {
+	planned, err := strategy.Apply(routes, scenario, config.RebalancingFee)
+	if err != nil {
+		return fmt.Errorf("failed to apply strategy: %w", err)
+	}
+	if err := planned.ApplyLimits(config.Limits); err != nil {
+		return fmt.Errorf("failed to apply limits: %w", err)
+	}
+	if err := planned.ApplyMessageCaps(config.Destinations); err != nil {
+		return fmt.Errorf("failed to apply message caps: %w", err)
+	}
+	msgs, err := gen.Generate(planned.Routes)
+	if err != nil {
+		return fmt.Errorf("failed to generate transfers: %w", err)
+	}
+
+	limit := scenario.MaxTotalAmount
+	if limit == "" {
+		limit = "none"
+	}
+	aggregation := "off"
+	if scenario.Aggregate {
+		aggregation = "on"
+	}
+	label := ""
+	if configured {
+		label = " (configured)"
+	}
+
+	fmt.Fprintf(out, "\nScenario: max total %s, aggregation %s%s\n", limit, aggregation, label)
+	fmt.Fprintf(out, "  %d transfers, total %s", len(msgs), planned.Routes.TotalAmount)
+	if len(planned.Deferred) > 0 {
+		deferred := &types.Routes{Routes: planned.Deferred}
+		strategy.RecomputeTotal(deferred)
+		fmt.Fprintf(out, ", %d routes deferred (%s)", len(deferred.Routes), deferred.TotalAmount)
+	}
+	if planned.Split > 0 {
+		fmt.Fprintf(out, ", %d routes split at the per-transfer maximum", planned.Split)
+	}
+	if planned.FannedOut > 0 {
+		fmt.Fprintf(out, ", %d routes fanned out to their split recipients", planned.FannedOut)
+	}
+	fmt.Fprintln(out)
+
+	for i, msg := range msgs {
+		transfer := msg.(*warptypes.MsgRemoteTransfer)
+		route := planned.Routes.Routes[i]
+		display := config.Display(transfer.DestinationDomain)
+		fmt.Fprintf(out, "  %d. domain %d, recipient %s, amount %s, source txs %s\n", i+1,
+			transfer.DestinationDomain, route.RouteInfo.Recipient, display.FormatAmount(transfer.Amount.String(), route.Denom),
+			strings.ReplaceAll(route.TxHash, ",", ", "))
+		link := display.ExplorerLink(types.ExplorerVars{Domain: transfer.DestinationDomain, Recipient: route.RouteInfo.Recipient, TxHash: route.TxHash})
+		if link != "" {
+			fmt.Fprintf(out, "     %s\n", link)
+		}
+	}
+
+	result := planScenario{
+		MaxTotalAmount: limit,
+		Aggregate:      scenario.Aggregate,
+		Configured:     configured,
+		Routes:         planned.Routes,
+		Deferred:       planned.Deferred,
+		Split:          planned.Split,
+		FannedOut:      planned.FannedOut,
+	}
+	if len(config.Destinations) > 0 {
+		estimate, err := strategy.EstimateGas(planned.Routes, config.Destinations)
+		if err != nil {
+			return fmt.Errorf("failed to estimate interchain gas: %w", err)
+		}
+		printGasEstimate(out, estimate)
+		result.GasEstimate = estimate
+	}
+
+	if before != nil {
+		projection, err := gen.ProjectBalances(planned.Routes, msgs, before, fees)
+		if err != nil {
+			return fmt.Errorf("failed to project balances: %w", err)
+		}
+		for _, d := range projection.Denoms {
+			marker := ""
+			if d.Overdrawn {
+				marker = "  ✗ OVERDRAWN"
+			}
+			fmt.Fprintf(out, "  Balance %s: %s -> %s%s\n", d.Denom, d.Before, d.After, marker)
+		}
+		result.Projection = projection
+	}
+
+	out.emit(result)
+	return nil
+}
+
+// printGasEstimate prints the estimated interchain gas spend per destination domain
+func printGasEstimate(out io.Writer, estimate *strategy.GasEstimate) {
+	var unpriced []string
+	priced := false
+	for _, d := range estimate.Domains {
+		switch {
+		case d.Unpriced:
+			unpriced = append(unpriced, fmt.Sprintf("%d", d.Domain))
+		case d.USD.IsNil():
+			fmt.Fprintf(out, "  Interchain gas domain %d: %d transfers, %d gas, cost %s\n", d.Domain, d.Transfers, d.Gas, d.Cost)
+		default:
+			priced = true
+			fmt.Fprintf(out, "  Interchain gas domain %d: %d transfers, %d gas, cost %s (~$%s)\n", d.Domain, d.Transfers, d.Gas, d.Cost, formatUSD(d.USD))
+		}
+	}
+	if priced {
+		fmt.Fprintf(out, "  Estimated interchain gas: ~$%s\n", formatUSD(estimate.TotalUSD))
+	}
+	if len(unpriced) > 0 {
+		fmt.Fprintf(out, "  No gas settings for domains %s\n", strings.Join(unpriced, ", "))
+	}
+}
+
+// formatUSD rounds a dollar amount to cents
+func formatUSD(usd math.LegacyDec) string {
+	cents := usd.MulInt64(100).RoundInt64()
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
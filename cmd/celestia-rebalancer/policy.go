@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// applyFailOnFlags adds the warning classes given with --fail-on to the config's policy
+func applyFailOnFlags(config *types.Config, classes []string) error {
+	for _, class := range classes {
+		if err := types.ValidateWarningClass(class); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+		if !config.Policy.FailsOn(class) {
+			config.Policy.FailOn = append(config.Policy.FailOn, class)
+		}
+	}
+	return nil
+}
+
+// enforcePolicy fails when warnings of a class the policy promotes to errors were raised
+func enforcePolicy(policy types.PolicyConfig, class string, warnings []string) error {
+	if len(warnings) == 0 || !policy.FailsOn(class) {
+		return nil
+	}
+	return fmt.Errorf("policy fails on %s warnings:\n  %s", class, strings.Join(warnings, "\n  "))
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/audit"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/quarantine"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/watcher"
+	"github.com/spf13/cobra"
+)
+
+func quarantineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quarantine",
+		Short: "Exclude suspicious deposits from generation until released",
+		Long: `Manage the quarantine list named by "quarantine_file" in the config. Quarantined source transactions,
+or all deposits from a quarantined sender, are held back by generate and plan until they are
+released, so flagged deposits can be investigated without blocking the rest of the batch.
+Additions and releases are recorded in the audit trail if "audit_log" is configured.`,
+	}
+
+	cmd.AddCommand(
+		mutates(quarantineAddCmd()),
+		mutates(quarantineReleaseCmd()),
+		quarantineListCmd(),
+	)
+
+	return cmd
+}
+
+func quarantineAddCmd() *cobra.Command {
+	var configFile, txHash, sender, reason string
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Quarantine a source transaction or sender",
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config, err := quarantineConfig(configFile)
+			if err != nil {
+				return err
+			}
+
+			var entry quarantine.Entry
+			err = quarantine.Update(config.QuarantineFile, func(list *quarantine.List) error {
+				entry, err = list.Add(txHash, sender, reason)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(config, audit.EventQuarantine, entry.TxHash, entry.String()); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "Quarantined %s\n", entry)
+			out.emit(entry)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file naming the quarantine list (required)")
+	cmd.Flags().StringVar(&txHash, "tx-hash", "", "Source transaction hash to quarantine")
+	cmd.Flags().StringVar(&sender, "sender", "", "Sender address whose deposits to quarantine")
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the deposit is quarantined (required)")
+
+	cmd.MarkFlagRequired("config")
+	cmd.MarkFlagRequired("reason")
+
+	return cmd
+}
+
+func quarantineReleaseCmd() *cobra.Command {
+	var configFile, txHash, sender string
+
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Release a quarantined source transaction or sender",
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config, err := quarantineConfig(configFile)
+			if err != nil {
+				return err
+			}
+
+			var entry quarantine.Entry
+			err = quarantine.Update(config.QuarantineFile, func(list *quarantine.List) error {
+				entry, err = list.Release(txHash, sender)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(config, audit.EventRelease, entry.TxHash, entry.String()); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "Released %s\n", entry)
+			out.emit(entry)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file naming the quarantine list (required)")
+	cmd.Flags().StringVar(&txHash, "tx-hash", "", "Source transaction hash to release")
+	cmd.Flags().StringVar(&sender, "sender", "", "Sender address to release")
+
+	cmd.MarkFlagRequired("config")
+
+	return cmd
+}
+
+func quarantineListCmd() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List quarantined source transactions and senders",
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config, err := quarantineConfig(configFile)
+			if err != nil {
+				return err
+			}
+			list, err := quarantine.Load(config.QuarantineFile)
+			if err != nil {
+				return err
+			}
+
+			out.emit(list)
+			if len(list.Entries) == 0 {
+				fmt.Fprintln(out, "Quarantine list is empty")
+				return nil
+			}
+			for _, e := range list.Entries {
+				fmt.Fprintf(out, "  %s  %s\n", e.AddedAt.Format("2006-01-02 15:04:05"), e)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file naming the quarantine list (required)")
+
+	cmd.MarkFlagRequired("config")
+
+	return cmd
+}
+
+// quarantineConfig loads the config, which must name a quarantine list
+func quarantineConfig(configFile string) (*types.Config, error) {
+	config, err := types.LoadConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if config.QuarantineFile == "" {
+		return nil, fmt.Errorf("quarantine_file is not set in the config")
+	}
+	return config, nil
+}
+
+// holdQuarantined removes quarantined routes if the config names a quarantine list, and returns
+// the remaining routes and the number held back
+func holdQuarantined(out io.Writer, routes *types.Routes, config *types.Config) (*types.Routes, int, error) {
+	if config.QuarantineFile == "" {
+		return routes, 0, nil
+	}
+
+	list, err := quarantine.Load(config.QuarantineFile)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	kept, held := list.Filter(routes)
+	for _, route := range held {
+		entry, _ := list.Match(&route)
+		fmt.Fprintf(out, "⚠ Holding route from tx %s (%s %s): quarantined %s\n", route.TxHash, route.Amount, route.Denom, entry)
+	}
+	return kept, len(held), nil
+}
+
+// holdReplays quarantines the deposits that reuse a nonce their sender already used, so generate
+// holds them back until they are released, and returns a description of each replay. Replays are
+// only reported if the config names no quarantine list.
+func holdReplays(out io.Writer, config *types.Config, replays []watcher.Replay) []string {
+	var descriptions []string
+	for _, replay := range replays {
+		description := fmt.Sprintf("tx %s reuses nonce %q of %s first used by tx %s (height %d)",
+			replay.Route.TxHash, replay.First.Nonce, replay.First.Sender, replay.First.TxHash, replay.First.Height)
+		fmt.Fprintf(out, "⚠ Possible replay: %s\n", description)
+		descriptions = append(descriptions, description)
+	}
+
+	if config.QuarantineFile == "" {
+		fmt.Fprintln(out, "✗ quarantine_file is not set in the config, replays are not held back")
+		return descriptions
+	}
+	if err := quarantineReplays(out, config, replays); err != nil {
+		fmt.Fprintf(out, "✗ Failed to quarantine replays: %v\n", err)
+	}
+	return descriptions
+}
+
+// quarantineReplays adds the replayed deposits not yet quarantined to the quarantine list and
+// records the additions in the audit trail
+func quarantineReplays(out io.Writer, config *types.Config, replays []watcher.Replay) error {
+	var added []quarantine.Entry
+	err := quarantine.Update(config.QuarantineFile, func(list *quarantine.List) error {
+		for _, replay := range replays {
+			if _, quarantined := list.Match(&replay.Route); quarantined {
+				continue
+			}
+			reason := fmt.Sprintf("possible replay of nonce %q first used by tx %s", replay.First.Nonce, replay.First.TxHash)
+			entry, err := list.Add(replay.Route.TxHash, "", reason)
+			if err != nil {
+				return err
+			}
+			added = append(added, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range added {
+		if err := recordAudit(config, audit.EventQuarantine, entry.TxHash, entry.String()); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Quarantined %s\n", entry)
+	}
+	return nil
+}
+
+// recordAudit appends an entry to the audit trail if the config names one
+func recordAudit(config *types.Config, event, txHash, details string) error {
+	if config.AuditLog == "" {
+		return nil
+	}
+	return audit.NewLog(config.AuditLog).Record(event, txHash, details)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// readOnlyEnv names the environment variable that enables read-only mode for a whole host
+const readOnlyEnv = "CELESTIA_REBALANCER_READ_ONLY"
+
+// mutatesAnnotation marks commands that change chain state or the tool's persistent local state.
+// They are refused in read-only mode.
+const mutatesAnnotation = "mutates-state"
+
+// mutates marks cmd as changing chain or local state
+func mutates(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[mutatesAnnotation] = "true"
+	return cmd
+}
+
+// mutatesFlag marks the flag name of cmd as making the command change chain or local state
+func mutatesFlag(cmd *cobra.Command, name string) {
+	cmd.Flags().SetAnnotation(name, mutatesAnnotation, []string{"true"})
+}
+
+// enforceReadOnly refuses to run a state-mutating command when read-only mode is enabled with
+// --read-only, the CELESTIA_REBALANCER_READ_ONLY environment variable or "read_only" in the
+// command's config file
+func enforceReadOnly(cmd *cobra.Command, readOnly bool) error {
+	if env := os.Getenv(readOnlyEnv); !readOnly && env != "" {
+		enabled, err := strconv.ParseBool(env)
+		if err != nil {
+			return fmt.Errorf("%s: invalid value %q", readOnlyEnv, env)
+		}
+		readOnly = enabled
+	}
+	if !readOnly {
+		config, err := commandConfig(cmd)
+		if err != nil {
+			return err
+		}
+		readOnly = config != nil && config.ReadOnly
+	}
+	if !readOnly {
+		return nil
+	}
+
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[mutatesAnnotation] == "true" {
+			return fmt.Errorf("command %q mutates state and is not allowed in read-only mode", cmd.CommandPath())
+		}
+	}
+
+	var err error
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if err == nil && len(f.Annotations[mutatesAnnotation]) > 0 {
+			err = fmt.Errorf("--%s mutates state and is not allowed in read-only mode", f.Name)
+		}
+	})
+	return err
+}
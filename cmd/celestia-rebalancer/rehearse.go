@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/testutil"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/spf13/cobra"
+)
+
+func rehearseCmd() *cobra.Command {
+	var (
+		configFile   string
+		multisigAddr string
+		workDir      string
+		tokenID      string
+		blocks       int
+		perBlock     float64
+		minAmount    int64
+		maxAmount    int64
+		seed         int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rehearse",
+		Short: "Practice the parse, generate, verify and broadcast workflow against a fake chain",
+		Long: `Run the full rebalancing workflow with a production config against an in-process fake chain, so new
+operators can practice it and config changes can be tried without touching real funds.
+
+The fake chain is filled with --blocks blocks of synthetic bank deposits to the multisig, routed to
+the whitelisted recipients of the config. Then the same commands operators run are executed against
+it, and each command line is printed:
+
+  1. parse the deposits into routes
+  2. generate the unsigned transaction
+  3. verify the transaction against the routes
+  4. sign it with placeholder signatures, which the fake chain does not check
+  5. verify the signed transaction and broadcast it with verify --broadcast
+
+Every file, including a copy of the config the commands read, is written to --work-dir (default: a
+new temporary directory) for inspection. The copy leaves out what must not be touched by a
+rehearsal or needs the real chain: notifiers, the retry queue, the quarantine file, the audit log,
+interchain gas quoting and the gRPC endpoint's TLS and credentials. A policy failing on first-seen
+recipients is relaxed, since a rehearsal has no state database. Stop at any step to investigate; a step that fails fails the rehearsal.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config, err := types.LoadConfig(configFile)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			var addrs []string
+			if multisigAddr != "" {
+				addrs = []string{multisigAddr}
+			}
+			_, _, multisigs, err := resolveMultisigs(config, "", addrs)
+			if err != nil {
+				return err
+			}
+			multisigAddr = multisigs[0]
+
+			routes := whitelistRoutes(config.Whitelist, tokenID)
+			if len(routes) == 0 {
+				return fmt.Errorf("the config whitelist has no recipients to route deposits to")
+			}
+
+			if workDir == "" {
+				workDir, err = os.MkdirTemp("", "celestia-rebalancer-rehearsal-")
+				if err != nil {
+					return fmt.Errorf("failed to create work directory: %w", err)
+				}
+			} else if err := os.MkdirAll(workDir, 0700); err != nil {
+				return fmt.Errorf("failed to create work directory: %w", err)
+			}
+			rehearsalConfig := filepath.Join(workDir, "config.json")
+			if err := writeRehearsalConfig(config, rehearsalConfig); err != nil {
+				return err
+			}
+
+			// Fill the fake chain with deposits before serving it
+			chain := testutil.NewChain(time.Now().UTC(), time.Second)
+			gen, err := testutil.NewDepositGenerator(testutil.DepositConfig{
+				Sender:    "celestia1rehearsaldepositor",
+				Multisig:  multisigAddr,
+				Denom:     config.Chain.Denom,
+				Routes:    routes,
+				PerBlock:  perBlock,
+				MinAmount: minAmount,
+				MaxAmount: maxAmount,
+			}, seed)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < blocks; i++ {
+				txs, err := gen.Block()
+				if err != nil {
+					return err
+				}
+				if _, err := chain.AddBlock(txs...); err != nil {
+					return err
+				}
+			}
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				return fmt.Errorf("failed to listen for the fake chain: %w", err)
+			}
+			go chain.Serve(ctx, listener)
+			rpcURL := listener.Addr().String()
+			// The fake chain is served in plaintext without auth
+			rpcConn = types.RPCConfig{}
+
+			stats := gen.Stats()
+			fmt.Fprintf(out, "Rehearsing in %s\n", workDir)
+			fmt.Fprintf(out, "Fake chain at %s: %d blocks with %d deposits of %s%s in total to %s\n",
+				rpcURL, chain.Height(), stats.Deposits, stats.Amount, config.Chain.Denom, multisigAddr)
+
+			routesFile := filepath.Join(workDir, "routes.json")
+			unsignedFile := filepath.Join(workDir, "unsigned-tx.json")
+			signedFile := filepath.Join(workDir, "signed-tx.json")
+
+			err = rehearseStep(ctx, out, 1, "Parse the deposits into routes", parseCmd(),
+				"--config", rehearsalConfig, "--rpc-url", rpcURL, "--multisig-address", multisigAddr,
+				"--from-height", "1", "--to-height", strconv.FormatInt(chain.Height(), 10), "--output", routesFile)
+			if err != nil {
+				return err
+			}
+			err = rehearseStep(ctx, out, 2, "Generate the unsigned transaction", generateCmd(),
+				"--config", rehearsalConfig, "--multisig-address", multisigAddr, "--routes", routesFile, "--output", unsignedFile)
+			if err != nil {
+				return err
+			}
+
+			// Generate writes the routes it planned when they differ from the parsed routes
+			verifyRoutes := routesFile
+			if planned := siblingFile(routesFile, "planned"); fileExists(planned) {
+				verifyRoutes = planned
+			}
+			err = rehearseStep(ctx, out, 3, "Verify the transaction against the routes", verifyCmd(),
+				"--config", rehearsalConfig, "--routes", verifyRoutes, "--transaction", unsignedFile, "--rpc-url", rpcURL)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "\n== Step 4: Sign the transaction ==\n")
+			if err := placeholderSign(unsignedFile, signedFile); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Signed with placeholder signatures, saved to %s\n", signedFile)
+			fmt.Fprintln(out, "(For real funds, key holders sign with sign, and their signatures are merged with combine.)")
+
+			err = rehearseStep(ctx, out, 5, "Verify and broadcast the signed transaction", verifyCmd(),
+				"--config", rehearsalConfig, "--routes", verifyRoutes, "--transaction", signedFile,
+				"--broadcast", "--rpc-url", rpcURL, "--poll-interval", "100ms")
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "\n✓ Rehearsal complete, files are in %s\n", workDir)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Production config to rehearse with (required)")
+	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig that receives the deposits (default: the first of multisig_addresses)")
+	cmd.Flags().StringVar(&workDir, "work-dir", "", "Directory for the rehearsal's files (default: a new temporary directory)")
+	cmd.Flags().StringVar(&tokenID, "token-id", "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", "Token ID in the deposits' routing metadata")
+	cmd.Flags().IntVar(&blocks, "blocks", 5, "Blocks of deposits on the fake chain")
+	cmd.Flags().Float64Var(&perBlock, "deposits-per-block", 2, "Average deposits per block")
+	cmd.Flags().Int64Var(&minAmount, "min-amount", 1000000, "Smallest deposit amount")
+	cmd.Flags().Int64Var(&maxAmount, "max-amount", 100000000, "Largest deposit amount")
+	cmd.Flags().Int64Var(&seed, "seed", 1, "Seed of the synthetic deposits")
+	cmd.MarkFlagRequired("config")
+
+	return cmd
+}
+
+// rehearseStep prints the command line of a workflow step and runs cmd with args
+func rehearseStep(ctx context.Context, out io.Writer, step int, title string, cmd *cobra.Command, args ...string) error {
+	fmt.Fprintf(out, "\n== Step %d: %s ==\n", step, title)
+	fmt.Fprintf(out, "$ celestia-rebalancer %s %s\n\n", cmd.Name(), strings.Join(args, " "))
+	cmd.SetArgs(args)
+	cmd.SetOut(out)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		return fmt.Errorf("step %d (%s) failed: %w", step, cmd.Name(), err)
+	}
+	return nil
+}
+
+// writeRehearsalConfig writes a copy of config for a rehearsal, without the settings that reach
+// beyond the fake chain or need the real one. The copy may hold decrypted secrets and is only
+// readable by the owner.
+func writeRehearsalConfig(config *types.Config, path string) error {
+	rehearsal := *config
+	rehearsal.Notify = types.NotifyConfig{}
+	rehearsal.Retry.QueueFile = ""
+	rehearsal.Retry.DeadLetterFile = ""
+	rehearsal.QuarantineFile = ""
+	rehearsal.AuditLog = ""
+	rehearsal.InterchainGas.Quote = false
+	rehearsal.RPC = types.RPCConfig{CallTimeout: config.RPC.CallTimeout}
+	rehearsal.Policy.FailOn = slices.DeleteFunc(slices.Clone(config.Policy.FailOn), func(class string) bool {
+		return class == types.WarningFirstSeenRecipient
+	})
+
+	data, err := json.MarshalIndent(&rehearsal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rehearsal config: %w", err)
+	}
+	if err := output.WriteAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write rehearsal config: %w", err)
+	}
+	return nil
+}
+
+// fileExists reports whether a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// placeholderSign writes the unsigned transaction in unsignedFile to signedFile with a placeholder
+// signature for every signer, the multisig and a distinct fee payer
+func placeholderSign(unsignedFile, signedFile string) error {
+	data, err := output.ReadFile(unsignedFile)
+	if err != nil {
+		return fmt.Errorf("failed to read transaction file: %w", err)
+	}
+	t, err := generator.UnmarshalTxJSON(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse transaction file: %w", err)
+	}
+
+	signers := 1
+	if t.AuthInfo.Fee != nil && t.AuthInfo.Fee.Payer != "" {
+		signers = 2
+	}
+	for len(t.AuthInfo.SignerInfos) < signers {
+		t.AuthInfo.SignerInfos = append(t.AuthInfo.SignerInfos, &tx.SignerInfo{})
+	}
+	t.Signatures = make([][]byte, len(t.AuthInfo.SignerInfos))
+	for i := range t.Signatures {
+		t.Signatures[i] = make([]byte, 64)
+	}
+
+	data, err = generator.MarshalTxJSON(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signed transaction: %w", err)
+	}
+	return output.WriteAtomic(signedFile, data, 0644)
+}
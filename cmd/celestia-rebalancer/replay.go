@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/verifier"
+)
+
+// replayOptions selects the window replayed by verify --against-chain
+type replayOptions struct {
+	multisigAddr     string
+	rpcURL           string
+	fromHeight       int64
+	toHeight         int64
+	outboundToHeight int64
+	reportFile       string
+}
+
+// replayAgainstChain reconstructs the routes the deposits in a past window should have produced,
+// compares them with the multisig's outbound transfers and writes a compliance report. It returns
+// whether the window is compliant.
+func replayAgainstChain(ctx context.Context, out *commandOutput, v *verifier.Verifier, config *types.Config, opts replayOptions) (bool, error) {
+	if opts.multisigAddr == "" {
+		return false, fmt.Errorf("--multisig-address is required with --against-chain")
+	}
+	if opts.fromHeight <= 0 || opts.toHeight < opts.fromHeight {
+		return false, fmt.Errorf("--from-height and --to-height must describe a valid range")
+	}
+	if opts.outboundToHeight == 0 {
+		opts.outboundToHeight = opts.toHeight
+	}
+	if opts.outboundToHeight < opts.toHeight {
+		return false, fmt.Errorf("--outbound-to-height must not be below --to-height")
+	}
+	if err := config.Chain.ValidateAddress(opts.multisigAddr); err != nil {
+		return false, fmt.Errorf("invalid multisig address: %w", err)
+	}
+
+	p, err := newParser(ctx, opts.rpcURL, config)
+	if err != nil {
+		return false, fmt.Errorf("failed to create parser: %w", err)
+	}
+	defer p.Close()
+	p.SetQueryConfig(config.Query)
+
+	fmt.Fprintf(out, "Reconstructing routes from deposits between heights %d and %d...\n", opts.fromHeight, opts.toHeight)
+	inbound, err := p.ParseRoutes(opts.multisigAddr, opts.fromHeight, opts.toHeight)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse deposits: %w", err)
+	}
+
+	// Transfers forwarding the window's deposits may be sent after its last block
+	fmt.Fprintf(out, "Collecting outbound transfers between heights %d and %d...\n\n", opts.fromHeight, opts.outboundToHeight)
+	outbound, err := p.ParseOutgoing(opts.multisigAddr, opts.fromHeight, opts.outboundToHeight)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse outbound transfers: %w", err)
+	}
+
+	report := v.Replay(inbound.Routes, outbound.Routes)
+	report.FromHeight = opts.fromHeight
+	report.ToHeight = opts.toHeight
+	report.OutboundToHeight = opts.outboundToHeight
+	report.Skipped = inbound.Skipped
+	report.FailedHeights = append(inbound.FailedHeights, outbound.FailedHeights...)
+	// Transfers at heights that could not be queried are unknown, so compliance cannot be shown
+	if len(report.FailedHeights) > 0 {
+		report.Compliant = false
+	}
+
+	v.PrintComplianceReport(report)
+	out.emit(report)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal compliance report: %w", err)
+	}
+	if err := output.WriteAtomic(opts.reportFile, data, 0644); err != nil {
+		return false, fmt.Errorf("failed to write compliance report: %w", err)
+	}
+	fmt.Fprintf(out, "\nCompliance report saved to %s\n", opts.reportFile)
+
+	return report.Compliant, nil
+}
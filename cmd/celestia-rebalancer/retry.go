@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/audit"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/notify"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/retry"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// withRetries loads and locks the retry queue if the config enables it, adds the queued routes that
+// are due to routes and holds back routes that are still backing off. It also returns the number of
+// routes added or held back. The queue is nil and routes are unchanged if the retry queue is
+// disabled; otherwise the caller closes it to release the lock.
+func withRetries(out io.Writer, routes *types.Routes, config *types.Config, now time.Time) (*retry.Queue, *types.Routes, int, error) {
+	if config.Retry.QueueFile == "" {
+		return nil, routes, 0, nil
+	}
+
+	q, err := retry.LoadLocked(config.Retry)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	merged := &types.Routes{MultisigAddr: routes.MultisigAddr}
+	present := make(map[string]bool)
+	moved := 0
+	for _, route := range routes.Routes {
+		if item, waiting := q.Waiting(route.TxHash, now); waiting {
+			fmt.Fprintf(out, "⚠ Holding route from tx %s: retry %d is due at %s\n",
+				route.TxHash, item.Attempts+1, item.NextAttempt.Format(time.RFC3339))
+			moved++
+			continue
+		}
+		merged.Routes = append(merged.Routes, route)
+		present[route.TxHash] = true
+	}
+	for _, route := range q.Due(now) {
+		if !present[route.TxHash] {
+			fmt.Fprintf(out, "Retrying route from tx %s\n", route.TxHash)
+			merged.Routes = append(merged.Routes, route)
+			moved++
+		}
+	}
+
+	strategy.RecomputeTotal(merged)
+	return q, merged, moved, nil
+}
+
+// queueFailures moves routes that cannot be generated into the retry queue and removes routes that
+// can from it, then saves the queue. Routes out of attempts go to the dead-letter file and raise a
+// notification. It returns the routes to generate and the number that failed.
+func queueFailures(out io.Writer, q *retry.Queue, gen *generator.Generator, routes *types.Routes, config *types.Config, now time.Time) (*types.Routes, int, error) {
+	if q == nil {
+		return routes, 0, nil
+	}
+
+	kept := &types.Routes{MultisigAddr: routes.MultisigAddr}
+	failed := 0
+	for i := range routes.Routes {
+		route := routes.Routes[i]
+		if err := generateParts(gen, &route); err != nil {
+			failed++
+			item, exhausted := q.Fail(route, retry.StageGenerate, err, now)
+			if exhausted {
+				if err := deadLetter(out, item, routes.MultisigAddr, config, now); err != nil {
+					return nil, 0, err
+				}
+			} else {
+				fmt.Fprintf(out, "⚠ Queued route from tx %s for retry %d at %s: %v\n",
+					route.TxHash, item.Attempts+1, item.NextAttempt.Format(time.RFC3339), err)
+			}
+			continue
+		}
+		q.Succeed(route.TxHash)
+		kept.Routes = append(kept.Routes, route)
+	}
+
+	if err := q.Save(); err != nil {
+		return nil, 0, err
+	}
+
+	strategy.RecomputeTotal(kept)
+	return kept, failed, nil
+}
+
+// deadLetter records a route that is out of attempts in the dead-letter file and the audit trail,
+// and notifies the operators
+func deadLetter(out io.Writer, item retry.Item, multisig string, config *types.Config, now time.Time) error {
+	retryConfig := config.Retry.WithDefaults()
+	if err := retry.NewDeadLetters(retryConfig.DeadLetterFile).Add(item, now); err != nil {
+		return err
+	}
+
+	last := item.Failures[len(item.Failures)-1]
+	message := fmt.Sprintf("Route from tx %s (%s %s) failed %d times and was moved to %s; last error at %s: %s",
+		item.Route.TxHash, item.Route.Amount, item.Route.Denom, item.Attempts, retryConfig.DeadLetterFile, last.Stage, last.Error)
+	fmt.Fprintf(out, "✗ %s\n", message)
+
+	if err := recordAudit(config, audit.EventDeadLetter, item.Route.TxHash, message); err != nil {
+		return err
+	}
+
+	// A failed notification must not lose the dead letter, which is already recorded
+	n, err := notify.FromConfig(config.Notify)
+	if err != nil {
+		fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", err)
+		return nil
+	}
+	event := notify.Event{
+		Severity: notify.SeverityCritical,
+		Title:    "Route dead-lettered",
+		Message:  message,
+		Multisig: multisig,
+		Total:    item.Route.Amount + item.Route.Denom,
+		Fields:   map[string]string{"tx_hash": item.Route.TxHash, "attempts": strconv.Itoa(item.Attempts)},
+	}
+	if err := notify.Send(context.Background(), n, event); err != nil {
+		fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", err)
+	}
+	return nil
+}
+
+// generateParts checks that every transfer of a route can be generated, one per split recipient of
+// a fan-out route
+func generateParts(gen *generator.Generator, route *types.HyperlaneRoute) error {
+	parts, err := route.ExpandSplits()
+	if err != nil {
+		return err
+	}
+	for i := range parts {
+		if _, err := gen.GenerateRoute(&parts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// roleEnv names the environment variable that pins the role of a host
+const roleEnv = "CELESTIA_REBALANCER_ROLE"
+
+// roleCommands lists the top-level commands each restricted role may run. Commands not listed
+// are refused, so new commands stay unavailable to restricted roles until added here.
+var roleCommands = map[types.Role][]string{
+	types.RoleParser:      {"parse", "watch", "backfill", "net", "plan", "token-id", "verify"},
+	types.RoleCoordinator: {"parse", "generate", "resequence", "net", "plan", "attest", "bundle", "secret", "quarantine", "sign", "combine", "simulate", "rehearse", "broadcast", "track", "fees", "watch", "backfill", "token-id", "import-warp", "verify", "selfcheck"},
+	types.RoleSigner:      {"bundle", "sign", "verify", "selfcheck"},
+}
+
+// enforceRole refuses to run cmd if the host's role does not allow it. The role comes from the
+// CELESTIA_REBALANCER_ROLE environment variable and the "role" field of the command's --config
+// file; if both are set they must agree.
+func enforceRole(cmd *cobra.Command) error {
+	role, err := resolveRole(cmd)
+	if err != nil {
+		return err
+	}
+	if role == types.RoleUnrestricted {
+		return nil
+	}
+
+	// Find the top-level command, e.g. "attest" for "attest keygen"
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	if !top.HasParent() {
+		return nil
+	}
+
+	for _, allowed := range roleCommands[role] {
+		if top.Name() == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("command %q is not allowed for role %s", top.Name(), role)
+}
+
+// resolveRole determines the role of this host
+func resolveRole(cmd *cobra.Command) (types.Role, error) {
+	envRole := types.Role(os.Getenv(roleEnv))
+	if err := envRole.Validate(); err != nil {
+		return "", fmt.Errorf("%s: %w", roleEnv, err)
+	}
+
+	var configRole types.Role
+	config, err := commandConfig(cmd)
+	if err != nil {
+		return "", err
+	}
+	if config != nil {
+		configRole = config.Role
+	}
+
+	switch {
+	case envRole != "" && configRole != "" && envRole != configRole:
+		return "", fmt.Errorf("role %s from %s conflicts with role %s in the config", envRole, roleEnv, configRole)
+	case envRole != "":
+		return envRole, nil
+	default:
+		return configRole, nil
+	}
+}
+
+// commandConfig loads the config file given to cmd with --config, or returns nil if there is none
+func commandConfig(cmd *cobra.Command) (*types.Config, error) {
+	flag := cmd.Flags().Lookup("config")
+	if flag == nil || flag.Value.String() == "" {
+		return nil, nil
+	}
+
+	config, err := types.LoadConfig(flag.Value.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return config, nil
+}
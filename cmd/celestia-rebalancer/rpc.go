@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Environment variables holding the gRPC credentials, so they stay out of shell history and the
+// process list
+const (
+	rpcTokenEnv    = "CELESTIA_REBALANCER_RPC_TOKEN"
+	rpcPasswordEnv = "CELESTIA_REBALANCER_RPC_PASSWORD"
+)
+
+// rpcConn holds the connection settings of the chain's gRPC endpoint, resolved by rpcFlags before
+// each command runs
+var rpcConn types.RPCConfig
+
+// rpcFlags are the root flags overriding the rpc section of the command's config
+type rpcFlags struct {
+	tls         bool
+	caFile      string
+	token       string
+	username    string
+	password    string
+	fallbacks   []string
+	maxAttempts int
+}
+
+// add registers the flags on the root command's persistent flags
+func (f *rpcFlags) add(flags *pflag.FlagSet) {
+	flags.BoolVar(&f.tls, "tls", false, "Connect to the gRPC endpoint over TLS (default: the config's rpc.tls)")
+	flags.StringVar(&f.caFile, "tls-ca-file", "", "PEM bundle to verify the gRPC endpoint against instead of the system roots; implies --tls")
+	flags.StringVar(&f.token, "rpc-token", "", "Bearer token for the gRPC endpoint (default: $"+rpcTokenEnv+" or the config's rpc.bearer_token)")
+	flags.StringVar(&f.username, "rpc-username", "", "Basic auth user for the gRPC endpoint (default: the config's rpc.username)")
+	flags.StringVar(&f.password, "rpc-password", "", "Basic auth password for the gRPC endpoint (default: $"+rpcPasswordEnv+" or the config's rpc.password)")
+	flags.StringArrayVar(&f.fallbacks, "rpc-fallback", nil, "gRPC endpoint of the same chain to fail over to (repeatable, default: the config's rpc.fallback_urls)")
+	flags.IntVar(&f.maxAttempts, "rpc-max-attempts", 0, fmt.Sprintf("Attempts per gRPC call on transient errors, across endpoints; 1 disables retries (default: the config's rpc.max_attempts, or %d)", types.DefaultRPCMaxAttempts))
+}
+
+// apply resolves rpcConn and rpcTimeout for cmd: the rpc section of its config (of the --source
+// chain if one is selected), overridden by the environment and then by flags
+func (f *rpcFlags) apply(cmd *cobra.Command) error {
+	var settings types.RPCConfig
+	config, err := commandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if config != nil {
+		settings = config.RPC
+		if flag := cmd.Flags().Lookup("source"); flag != nil && flag.Value.String() != "" {
+			if sourceConfig, _, err := config.ForSource(flag.Value.String()); err == nil {
+				settings = sourceConfig.RPC
+			}
+		}
+	}
+
+	if env := os.Getenv(rpcTokenEnv); env != "" {
+		settings.BearerToken = env
+	}
+	if env := os.Getenv(rpcPasswordEnv); env != "" {
+		settings.Password = env
+	}
+	flags := cmd.Flags()
+	if flags.Changed("tls") {
+		settings.TLS = f.tls
+	}
+	if flags.Changed("tls-ca-file") {
+		settings.CAFile = f.caFile
+	}
+	if flags.Changed("rpc-token") {
+		settings.BearerToken = f.token
+	}
+	if flags.Changed("rpc-username") {
+		settings.Username = f.username
+	}
+	if flags.Changed("rpc-password") {
+		settings.Password = f.password
+	}
+	if flags.Changed("rpc-fallback") {
+		settings.FallbackURLs = f.fallbacks
+	}
+	if flags.Changed("rpc-max-attempts") {
+		settings.MaxAttempts = f.maxAttempts
+	}
+	if err := settings.Validate(); err != nil {
+		return fmt.Errorf("rpc: %w", err)
+	}
+
+	// --timeout takes precedence over the config's call_timeout
+	if !flags.Changed("timeout") {
+		rpcTimeout, _ = settings.Timeout()
+	}
+	rpcConn = settings
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func secretCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage encrypted config values",
+	}
+
+	cmd.AddCommand(secretEncryptCmd())
+
+	return cmd
+}
+
+func secretEncryptCmd() *cobra.Command {
+	var recipients []string
+
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt a value read from stdin for use in the config file",
+		Long: `Encrypt a secret (API key, webhook secret, signer credential) to one or more age recipients and
+print it in the "age:..." form accepted anywhere in the config file. The value is read from stdin so
+it does not end up in the shell history:
+
+  celestia-rebalancer secret encrypt --recipient age1... < webhook-secret.txt
+
+At load time, secrets are decrypted with the identity file named by ` + types.AgeIdentityEnv + `.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && value == "" {
+				return fmt.Errorf("failed to read secret from stdin: %w", err)
+			}
+
+			secret, err := types.EncryptSecret(strings.TrimRight(value, "\r\n"), recipients)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(out, secret)
+			out.emit(map[string]string{"secret": secret})
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringArrayVar(&recipients, "recipient", nil, "age recipient public key (repeatable, required)")
+
+	cmd.MarkFlagRequired("recipient")
+
+	return cmd
+}
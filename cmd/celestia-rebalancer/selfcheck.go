@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/verifier"
+	"github.com/spf13/cobra"
+)
+
+// newVerifier returns a verifier checking the rules config sets for generated transactions: the
+// authz grant, metadata forwarding, memo templates, the whitelist, message caps and the rebalancing
+// fee. verify, bundle and selfcheck all use it, so they check transactions alike.
+func newVerifier(config *types.Config) *verifier.Verifier {
+	v := verifier.NewVerifierWithGrant(config.Authz)
+	v.SetMetadataPolicy(config.Metadata)
+	v.SetMemoTemplates(config.Destinations)
+	v.SetWhitelist(config.Whitelist)
+	v.SetMessageCaps(config.Destinations)
+	v.SetRebalancingFee(config.RebalancingFee)
+	return v
+}
+
+func selfcheckCmd() *cobra.Command {
+	var (
+		routesFile   string
+		multisigAddr string
+		configFile   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "selfcheck",
+		Short: "Check that the generator and the verifier agree on a routes file",
+		Long: `Plan the routes of a routes file with the config's strategy, limits and message caps, generate
+their transaction as generate does, and verify it right away with the strict verifier of verify,
+without touching the chain, the state or any output file.
+
+The generator and the verifier normalize recipients, token IDs, amounts, metadata and memos
+independently, so a signer's check does not trust the operator's code. selfcheck fails if a change
+to either made them disagree: a transaction the rebalancer generates that its own verifier would
+reject. Run it in every release, e.g. with make selfcheck, and before every signing ceremony with
+the routes and config of the ceremony.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config := &types.Config{Chain: types.DefaultChainConfig()}
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+
+			routes, err := loadRoutes(routesFile)
+			if err != nil {
+				return err
+			}
+			if multisigAddr == "" {
+				multisigAddr = routes.MultisigAddr
+			}
+			if multisigAddr == "" {
+				return fmt.Errorf("--multisig-address is required when the routes file names no multisig")
+			}
+			routes.MultisigAddr = multisigAddr
+
+			planned, err := strategy.Apply(routes, config.Strategy, config.RebalancingFee)
+			if err != nil {
+				return fmt.Errorf("failed to apply strategy: %w", err)
+			}
+			if err := planned.ApplyLimits(config.Limits); err != nil {
+				return fmt.Errorf("failed to apply limits: %w", err)
+			}
+			if err := planned.ApplyMessageCaps(config.Destinations); err != nil {
+				return fmt.Errorf("failed to apply message caps: %w", err)
+			}
+			routes = planned.Routes
+			if len(routes.Routes) == 0 {
+				return fmt.Errorf("no routes left to check after planning")
+			}
+
+			gen, err := generator.NewGeneratorWithConfig(multisigAddr, config)
+			if err != nil {
+				return err
+			}
+			msgs, err := gen.Generate(routes)
+			if err != nil {
+				return fmt.Errorf("failed to generate transactions: %w", err)
+			}
+			unsignedTx, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{Grantee: config.Authz.Grantee})
+			if err != nil {
+				return fmt.Errorf("failed to build unsigned transaction: %w", err)
+			}
+
+			// Go through the JSON signers receive, so its encoding is checked too
+			data, err := generator.MarshalTxJSON(unsignedTx)
+			if err != nil {
+				return fmt.Errorf("failed to marshal transaction: %w", err)
+			}
+			txRaw, err := generator.DecodeTxRaw(data)
+			if err != nil {
+				return fmt.Errorf("failed to decode the generated transaction: %w", err)
+			}
+
+			v := newVerifier(config)
+			v.SetStrict(true)
+			v.SetOutput(out)
+			fmt.Fprintf(out, "Generated %d messages for %d planned routes, verifying them...\n\n", len(msgs), len(routes.Routes))
+			result, err := v.Verify(routes, txRaw)
+			if err != nil {
+				return fmt.Errorf("verification failed: %w", err)
+			}
+			v.PrintResult(result)
+			out.emit(result)
+
+			if !result.Valid {
+				fmt.Fprintln(out, "\n✗ Self-check FAILED: the verifier rejects what the generator produced")
+				out.exit(1)
+			}
+			fmt.Fprintln(out, "\n✓ Self-check passed: the generator and the verifier agree")
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&routesFile, "routes", "routes.json", "Routes file to generate and verify")
+	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig address sending the transfers (default: the routes file's multisig_address)")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file with the settings of the ceremony")
+	stepInputFlag(cmd, "routes")
+
+	return cmd
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/testutil"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func simulateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate transactions against the chain, or run the rebalancer against a simulated chain",
+	}
+	cmd.AddCommand(textOnly(simulateDepositsCmd()), simulateTxCmd())
+	return cmd
+}
+
+func simulateTxCmd() *cobra.Command {
+	var (
+		opts   sessionOptions
+		rpcURL string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tx",
+		Short: "Dry-run a generated transaction against the chain before collecting signatures",
+		Long: `Run an unsigned transaction written by generate through the tx service's Simulate endpoint, signed
+by the multisig with placeholder signatures of its threshold of members, and report the gas it uses.
+
+Simulation executes the ante handler and every message against the latest state without verifying
+signatures, so failures such as an insufficient multisig balance, an unknown token ID or a fee below
+the minimum show up before key holders are asked to sign. The transaction is simulated at the
+multisig's current account sequence unless --sequence is given; a later batch of a multi-batch
+generate can only be simulated once the batches before it are included.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			// Simulation does not verify signatures, so any chain ID will do
+			if opts.chainID == "" {
+				opts.chainID = "simulation"
+			}
+			session, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			c, err := dialChain(cmd.Context(), rpcURL)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			sequence := session.Sequence()
+			if !cmd.Flags().Changed("sequence") {
+				account, err := c.Account(session.Address())
+				if err != nil {
+					return err
+				}
+				if account.Sequence != sequence {
+					fmt.Fprintf(out, "⚠ Transaction records sequence %d, simulating at the account's current sequence %d\n", sequence, account.Sequence)
+				}
+				sequence = account.Sequence
+			}
+
+			txBytes, err := session.SimulationTx(sequence)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Simulating %s as %s at sequence %d against %s...\n", opts.txFile, session.Address(), sequence, rpcURL)
+			resp, err := c.SimulateTx(txBytes)
+			if err != nil {
+				fmt.Fprintf(out, "✗ %v\n", err)
+				return fmt.Errorf("transaction would fail")
+			}
+
+			gasUsed := resp.GasInfo.GasUsed
+			fmt.Fprintf(out, "✓ Simulation succeeded: %d gas used\n", gasUsed)
+			gasLimit := session.GasLimit()
+			if gasLimit < gasUsed {
+				fmt.Fprintf(out, "✗ Gas limit %d is below the gas used, regenerate with a higher --gas-limit\n", gasLimit)
+				return fmt.Errorf("gas limit too low")
+			}
+			fmt.Fprintf(out, "  Gas limit %d (%.0f%% used)\n", gasLimit, 100*float64(gasUsed)/float64(gasLimit))
+			out.emit(map[string]uint64{"sequence": sequence, "gas_used": gasUsed, "gas_limit": gasLimit})
+			return nil
+		}),
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().StringVar(&rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL")
+	cmd.Flags().Lookup("chain-id").Usage = "Chain ID the transaction is signed for (not needed for simulation)"
+
+	return cmd
+}
+
+func simulateDepositsCmd() *cobra.Command {
+	var (
+		listen         string
+		multisigAddr   string
+		sender         string
+		configFile     string
+		tokenID        string
+		blockTime      time.Duration
+		perBlock       float64
+		minAmount      int64
+		maxAmount      int64
+		duplicateRate  float64
+		rejectRate     float64
+		queryErrorRate float64
+		duration       time.Duration
+		seed           int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "deposits",
+		Short: "Serve a fake chain producing synthetic deposits to the multisig",
+		Long: `Serve a fake chain node over gRPC that produces a block every --block-time with synthetic bank
+deposits to the multisig, routed to the whitelisted recipients of the config (or of the default
+config). Point watch or backfill at it with --rpc-url to soak-test them locally:
+
+  celestia-rebalancer simulate deposits --multisig-address celestia1... --deposits-per-block 20
+  celestia-rebalancer watch --rpc-url localhost:9091 --multisig-address celestia1... --start-height 1
+
+--duplicate-rate repeats the route and amount of earlier deposits, like accidental double-sends,
+--reject-rate routes deposits to a recipient no whitelist allows, and --query-error-rate fails that
+fraction of transaction queries, so dedup, rejection and retry paths are exercised. Runs are
+reproducible with the same --seed. Stop with SIGINT or SIGTERM, or after --duration.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config := types.DefaultConfig()
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+
+			gen, err := testutil.NewDepositGenerator(testutil.DepositConfig{
+				Sender:        sender,
+				Multisig:      multisigAddr,
+				Denom:         config.Chain.WithDefaults().Denom,
+				Routes:        whitelistRoutes(config.Whitelist, tokenID),
+				PerBlock:      perBlock,
+				MinAmount:     minAmount,
+				MaxAmount:     maxAmount,
+				DuplicateRate: duplicateRate,
+				RejectRate:    rejectRate,
+			}, seed)
+			if err != nil {
+				return err
+			}
+			chain := testutil.NewChain(time.Now().UTC(), blockTime)
+			chain.SetQueryErrorRate(queryErrorRate, seed)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if duration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, duration)
+				defer cancel()
+			}
+
+			listener, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", listen, err)
+			}
+			served := make(chan error, 1)
+			go func() { served <- chain.Serve(ctx, listener) }()
+			fmt.Fprintf(out, "Simulated chain serving gRPC at %s, a block every %s with %.1f deposits on average\n", listener.Addr(), blockTime, perBlock)
+
+			ticker := time.NewTicker(blockTime)
+			defer ticker.Stop()
+			for ctx.Err() == nil {
+				txs, err := gen.Block()
+				if err != nil {
+					return err
+				}
+				height, err := chain.AddBlock(txs...)
+				if err != nil {
+					return err
+				}
+				if height%10 == 0 {
+					printDepositStats(out, height, gen.Stats(), config.Chain.WithDefaults().Denom)
+				}
+
+				select {
+				case <-ctx.Done():
+				case <-ticker.C:
+				}
+			}
+
+			if err := <-served; err != nil {
+				return fmt.Errorf("simulated chain failed: %w", err)
+			}
+			printDepositStats(out, chain.Height(), gen.Stats(), config.Chain.WithDefaults().Denom)
+			fmt.Fprintln(out, "Stopped")
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "localhost:9091", "Address to serve the simulated chain's gRPC services on")
+	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig address the deposits are sent to (required)")
+	cmd.Flags().StringVar(&sender, "sender", "celestia1simulateddepositor", "Sender of the deposits")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config whose whitelist the deposits are routed to (default: the default config)")
+	cmd.Flags().StringVar(&tokenID, "token-id", "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", "Token ID in the deposits' routing metadata")
+	cmd.Flags().DurationVar(&blockTime, "block-time", time.Second, "Time between simulated blocks")
+	cmd.Flags().Float64Var(&perBlock, "deposits-per-block", 5, "Average deposits per block")
+	cmd.Flags().Int64Var(&minAmount, "min-amount", 1000000, "Smallest deposit amount")
+	cmd.Flags().Int64Var(&maxAmount, "max-amount", 100000000, "Largest deposit amount")
+	cmd.Flags().Float64Var(&duplicateRate, "duplicate-rate", 0, "Fraction of deposits repeating an earlier deposit's route and amount")
+	cmd.Flags().Float64Var(&rejectRate, "reject-rate", 0, "Fraction of deposits routed to a recipient that is not whitelisted")
+	cmd.Flags().Float64Var(&queryErrorRate, "query-error-rate", 0, "Fraction of transaction queries that fail")
+	cmd.Flags().DurationVar(&duration, "duration", 0, "Stop after this long (default: until interrupted)")
+	cmd.Flags().Int64Var(&seed, "seed", 1, "Seed of the simulated deposits")
+	cmd.MarkFlagRequired("multisig-address")
+
+	return cmd
+}
+
+// whitelistRoutes returns a route to every whitelisted recipient, by domain
+func whitelistRoutes(whitelist types.AddressWhitelist, tokenID string) []client.RoutingMetadata {
+	domains := make([]uint32, 0, len(whitelist.Domains))
+	for domain := range whitelist.Domains {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i] < domains[j] })
+
+	var routes []client.RoutingMetadata
+	for _, domain := range domains {
+		for _, recipient := range whitelist.Domains[domain] {
+			routes = append(routes, client.RoutingMetadata{DestinationDomain: domain, Recipient: recipient, TokenID: tokenID})
+		}
+	}
+	return routes
+}
+
+// printDepositStats prints the deposits produced up to height
+func printDepositStats(out io.Writer, height int64, stats testutil.DepositStats, denom string) {
+	fmt.Fprintf(out, "Height %d: %d deposits (%d duplicates, %d to unlisted recipients), %s%s in total\n",
+		height, stats.Deposits, stats.Duplicates, stats.Rejected, stats.Amount, denom)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/state"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// stateOptions select the state database recording which deposits were rebalanced
+type stateOptions struct {
+	path        string
+	postgresDSN string
+}
+
+// addStateFlags registers the state database flags on cmd
+func addStateFlags(cmd *cobra.Command, opts *stateOptions) {
+	cmd.Flags().StringVar(&opts.path, "state", "", "SQLite database recording rebalanced deposits, e.g. rebalancer.db")
+	cmd.Flags().StringVar(&opts.postgresDSN, "postgres-dsn", "", "Keep the state in PostgreSQL instead of SQLite")
+}
+
+// open opens the state database, or returns nil if none was selected
+func (o stateOptions) open(ctx context.Context) (*state.Ledger, storage.Storage, error) {
+	if o.path == "" && o.postgresDSN == "" {
+		return nil, nil, nil
+	}
+	store, err := openStorage(ctx, o.path, o.postgresDSN)
+	if err != nil {
+		return nil, nil, err
+	}
+	return state.New(store), store, nil
+}
+
+// dropRebalanced removes the routes whose deposits the state records as rebalanced from routes
+// and returns how many were removed. Deposits generated into a transaction that was never
+// broadcast are kept, with a warning, if keepGenerated is set.
+func dropRebalanced(ctx context.Context, out io.Writer, ledger *state.Ledger, routes *types.Routes, keepGenerated bool) (int, error) {
+	_, rebalanced, err := ledger.Check(ctx, routes.Routes)
+	if err != nil {
+		return 0, err
+	}
+	if len(rebalanced) == 0 {
+		return 0, nil
+	}
+
+	var kept []types.HyperlaneRoute
+	for _, route := range routes.Routes {
+		broadcast, generated := false, false
+		for _, key := range state.DepositKeys(route) {
+			r, ok := rebalanced[key]
+			if !ok {
+				continue
+			}
+			if r.Broadcast() {
+				broadcast = true
+			} else {
+				generated = true
+			}
+		}
+		switch {
+		case !broadcast && !generated:
+			kept = append(kept, route)
+		case broadcast:
+			fmt.Fprintf(out, "  ✗ tx %s (amount %s) was already rebalanced, skipping\n", route.TxHash, route.Amount)
+		case generated && keepGenerated:
+			fmt.Fprintf(out, "  ⚠ tx %s (amount %s) was generated into a transaction that has not been broadcast\n", route.TxHash, route.Amount)
+			kept = append(kept, route)
+		case generated:
+			fmt.Fprintf(out, "  ✗ tx %s (amount %s) was already generated into a transaction, skipping\n", route.TxHash, route.Amount)
+		}
+	}
+
+	dropped := len(routes.Routes) - len(kept)
+	routes.Routes = kept
+	strategy.RecomputeTotal(routes)
+	return dropped, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/parser"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// rpcTimeout bounds each gRPC call to the chain, set by the root --timeout flag. Zero leaves calls
+// bounded only by the command's --deadline.
+var rpcTimeout time.Duration
+
+// commandDeadline bounds the whole run of a command, set by the root --deadline flag, so unattended
+// invocations cannot hang on a wedged endpoint
+type commandDeadline struct {
+	limit  time.Duration
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// apply sets the deadline on the context of cmd, which commands pass to everything they wait on
+func (d *commandDeadline) apply(cmd *cobra.Command) {
+	if d.limit <= 0 {
+		return
+	}
+	d.ctx, d.cancel = context.WithTimeout(cmd.Context(), d.limit)
+	cmd.SetContext(d.ctx)
+}
+
+// stop releases the deadline
+func (d *commandDeadline) stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// explainDeadline explains err if cmd failed because its --deadline passed. Commands run outside
+// the root command have no deadline of their own.
+func explainDeadline(cmd *cobra.Command, err error) error {
+	flag := cmd.Flags().Lookup("deadline")
+	if err == nil || flag == nil || !errors.Is(cmd.Context().Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("command did not finish within --deadline %s: %w", flag.Value, err)
+}
+
+// dialChain connects to the chain's gRPC endpoint with the TLS, credentials, fallback endpoints and
+// retries of rpcConn. Each call is bounded by --timeout and all of them by ctx, the context of the
+// command. Failovers are reported on stderr.
+func dialChain(ctx context.Context, rpcURL string) (*client.Client, error) {
+	if client.IsCometURL(rpcURL) {
+		return nil, fmt.Errorf("%s is a CometBFT RPC endpoint, this command needs the chain's gRPC endpoint", rpcURL)
+	}
+	c, err := client.NewClientWithConfig(ctx, rpcURL, rpcConn)
+	if err != nil {
+		return nil, err
+	}
+	c.SetCallTimeout(rpcTimeout)
+	c.SetFailoverHandler(reportFailover)
+	return c, nil
+}
+
+// newParser creates a parser querying the chain like dialChain, validating routes against config
+// if it is not nil. Parsers can also read the chain from a CometBFT RPC endpoint, given as an
+// http:// or https:// URL.
+func newParser(ctx context.Context, rpcURL string, config *types.Config) (*parser.Parser, error) {
+	if !client.IsCometURL(rpcURL) {
+		c, err := dialChain(ctx, rpcURL)
+		if err != nil {
+			return nil, err
+		}
+		return parser.NewParserWithClient(c, config), nil
+	}
+
+	c, err := client.NewCometClient(ctx, rpcURL, rpcConn)
+	if err != nil {
+		return nil, err
+	}
+	c.SetCallTimeout(rpcTimeout)
+	c.SetFailoverHandler(reportFailover)
+	return parser.NewParserWithClient(c, config), nil
+}
+
+// reportFailover reports on stderr that a client moved from one endpoint to another
+func reportFailover(from, to string, err error) {
+	fmt.Fprintf(os.Stderr, "⚠ endpoint %s failed (%v), failing over to %s\n", from, err, to)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func tokenIDCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token-id",
+		Short: "Derive and look up warp token IDs",
+	}
+
+	cmd.AddCommand(tokenIDDeriveCmd())
+
+	return cmd
+}
+
+func tokenIDDeriveCmd() *cobra.Command {
+	var (
+		sequence  uint64
+		class     string
+		tokenType uint32
+		denom     string
+		rpcURL    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "derive",
+		Short: "Derive a warp token ID from its creation parameters or look it up by denom",
+		Long: `Print the 32-byte warp token ID to use as "token_id" in routing metadata and configs, so it never
+has to be copied by hand.
+
+With --sequence, the ID is derived offline from the token's creation parameters: the app router class
+(` + types.TokenIDClass + `), the warp module's type (` + fmt.Sprint(types.TokenIDType) + `) and the sequence number the token was
+created with, which is the number of router apps (tokens) created on the chain before it.
+
+With --denom, the chain is queried for the warp tokens transferring the denom: collateral tokens locking
+it and the synthetic token minting it (denom "hyperlane/<token id>").`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			bySequence := cmd.Flags().Changed("sequence")
+			if bySequence == (denom != "") {
+				return fmt.Errorf("exactly one of --sequence and --denom is required")
+			}
+
+			if bySequence {
+				id, err := types.DeriveTokenID(types.TokenIDParams{Class: class, Type: tokenType, Sequence: sequence})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(out, id)
+				out.emit(map[string]string{"token_id": id})
+				return nil
+			}
+
+			c, err := dialChain(cmd.Context(), rpcURL)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			tokens, err := c.WarpTokensByDenom(denom)
+			if err != nil {
+				return err
+			}
+			if len(tokens) == 0 {
+				return fmt.Errorf("no warp token transfers %s", denom)
+			}
+			if len(tokens) > 1 {
+				fmt.Fprintf(out, "⚠ %d warp tokens transfer %s; check the owner before using an ID\n", len(tokens), denom)
+			}
+			for _, token := range tokens {
+				fmt.Fprintf(out, "%s  %s  owner %s\n", token.Id, token.TokenType, token.Owner)
+			}
+			out.emit(tokens)
+			return nil
+		}),
+	}
+
+	cmd.Flags().Uint64Var(&sequence, "sequence", 0, "Sequence number the token was created with")
+	cmd.Flags().StringVar(&class, "class", types.TokenIDClass, "Class identifier of the ID")
+	cmd.Flags().Uint32Var(&tokenType, "type", types.TokenIDType, "Type field of the ID")
+	cmd.Flags().StringVar(&denom, "denom", "", "Look up the token IDs transferring this denom on the chain")
+	cmd.Flags().StringVar(&rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL, for --denom")
+
+	return cmd
+}
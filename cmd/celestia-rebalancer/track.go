@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/notify"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func trackCmd() *cobra.Command {
+	var (
+		routesFile string
+		txHashes   []string
+		rpcURL     string
+		configFile string
+		outputFile string
+		stateOpts  stateOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "track",
+		Short: "Record the Hyperlane messages dispatched by an included rebalancing transaction",
+		Long: `Query an included rebalancing transaction, extract the Hyperlane messages it dispatched and record
+each message ID and nonce against the route it was generated from, so delivery can be tracked on the
+destination chain and in the Hyperlane explorer.
+
+Pass the routes file the transaction was generated and verified against (routes-planned.json when
+generate wrote one). For batches, repeat --tx-hash in batch order. The routes with their dispatches
+are written to routes-dispatched.json, and the message IDs are sent to the configured notifiers.
+
+With --state, the forwarded deposits are recorded as rebalanced in the state database, so parse and
+generate skip them from then on, and the fees of the transactions are recorded for the fees report.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config := &types.Config{Chain: types.DefaultChainConfig()}
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+			n, err := notify.FromConfig(config.Notify)
+			if err != nil {
+				return err
+			}
+
+			routes, err := loadRoutes(routesFile)
+			if err != nil {
+				return err
+			}
+
+			c, err := dialChain(cmd.Context(), rpcURL)
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			// Query all transactions at once; dispatches are still collected in batch order
+			c.SetQueryConfig(config.Query)
+			responses, err := c.GetTxs(txHashes)
+			if err != nil {
+				return err
+			}
+
+			var dispatches []types.Dispatch
+			var fees []storage.TxFee
+			for _, resp := range responses {
+				if err := client.TxFailed(resp); err != nil {
+					event := notify.Event{
+						Severity: notify.SeverityCritical,
+						Title:    "Rebalancing transaction failed",
+						Message:  err.Error(),
+						Multisig: routes.MultisigAddr,
+						Total:    routes.TotalAmount,
+						Fields:   map[string]string{"tx_hash": resp.TxHash},
+					}
+					if nerr := notify.Send(context.Background(), n, event); nerr != nil {
+						fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", nerr)
+					}
+					return err
+				}
+
+				txDispatches, err := client.ExtractDispatches(resp)
+				if err != nil {
+					return err
+				}
+				cost, err := client.ExtractTxCost(resp)
+				if err != nil {
+					return err
+				}
+				// Messages are dispatched in the order of their MsgRemoteTransfers
+				if len(cost.InterchainGas) == len(txDispatches) {
+					for i := range txDispatches {
+						txDispatches[i].InterchainGas = cost.InterchainGas[i]
+					}
+				}
+				fees = append(fees, storage.TxFee{
+					TxHash:    resp.TxHash,
+					Height:    resp.Height,
+					Fee:       cost.Fee,
+					GasWanted: cost.GasWanted,
+					GasUsed:   cost.GasUsed,
+					Messages:  len(txDispatches),
+				})
+				fmt.Fprintf(out, "Tx %s (height %d): %d Hyperlane messages dispatched, fee %s, %d gas used\n", resp.TxHash, resp.Height, len(txDispatches), orNone(cost.Fee), cost.GasUsed)
+				dispatches = append(dispatches, txDispatches...)
+			}
+
+			gen := generator.NewGenerator(routes.MultisigAddr)
+			if err := gen.AttachDispatches(routes, dispatches); err != nil {
+				return err
+			}
+
+			ledger, store, err := stateOpts.open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if store != nil {
+				defer store.Close()
+				if err := ledger.RecordDispatched(cmd.Context(), routes.Routes); err != nil {
+					return fmt.Errorf("failed to record dispatched deposits: %w", err)
+				}
+				if err := ledger.RecordDeliveries(cmd.Context(), routes.Routes); err != nil {
+					return fmt.Errorf("failed to record messages awaiting delivery: %w", err)
+				}
+				fmt.Fprintln(out, "Dispatched deposits recorded in the state database")
+
+				if feeStore, ok := store.(storage.FeeStorage); ok {
+					for _, fee := range fees {
+						if err := feeStore.SaveTxFee(cmd.Context(), fee); err != nil {
+							return fmt.Errorf("failed to record fees: %w", err)
+						}
+					}
+				}
+			}
+
+			if outputFile == "" {
+				outputFile = siblingFile(routesFile, "dispatched")
+			}
+			data, err := json.MarshalIndent(routes, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal routes: %w", err)
+			}
+			if err := output.WriteAtomic(outputFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write routes: %w", err)
+			}
+
+			var lines []string
+			for _, route := range routes.Routes {
+				d := route.Dispatch
+				display := config.Display(d.Destination)
+				line := fmt.Sprintf("%s %s -> domain %d: message %s (nonce %d)",
+					route.TxHash, display.FormatAmount(route.Amount, route.Denom), d.Destination, d.MessageID, d.Nonce)
+				vars := types.ExplorerVars{Domain: d.Destination, Recipient: d.Recipient, MessageID: d.MessageID, TxHash: route.TxHash}
+				if route.RouteInfo != nil {
+					vars.Recipient = route.RouteInfo.Recipient
+				}
+				if link := display.ExplorerLink(vars); link != "" {
+					line += " " + link
+				}
+				lines = append(lines, line)
+			}
+			fmt.Fprintln(out)
+			for _, line := range lines {
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+			fmt.Fprintf(out, "\nRoutes with dispatched messages saved to %s\n", outputFile)
+			out.emit(routes)
+
+			message := fmt.Sprintf("%d Hyperlane messages dispatched by %s:\n%s",
+				len(dispatches), strings.Join(txHashes, ", "), strings.Join(lines, "\n"))
+			event := notify.Event{
+				Severity: notify.SeverityInfo,
+				Title:    "Rebalancing transfers dispatched",
+				Message:  message,
+				Multisig: routes.MultisigAddr,
+				Total:    routes.TotalAmount,
+				Fields:   map[string]string{"tx_hash": strings.Join(txHashes, ", "), "messages": strconv.Itoa(len(dispatches))},
+			}
+			if err := notify.Send(context.Background(), n, event); err != nil {
+				fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", err)
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&routesFile, "routes", "routes.json", "Routes file the transaction was generated from")
+	cmd.Flags().StringArrayVar(&txHashes, "tx-hash", nil, "Hash of the included rebalancing transaction (repeatable, in batch order)")
+	cmd.Flags().StringVar(&rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file with notification settings")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for the routes with dispatches (default: <routes>-dispatched.json)")
+
+	addStateFlags(cmd, &stateOpts)
+	mutatesFlag(cmd, "state")
+	mutatesFlag(cmd, "postgres-dsn")
+
+	cmd.MarkFlagRequired("tx-hash")
+	stepInputFlag(cmd, "routes")
+	outputFlag(cmd, "output")
+
+	return cmd
+}
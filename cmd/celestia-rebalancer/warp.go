@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func importWarpCmd() *cobra.Command {
+	var (
+		deploymentFile string
+		configFile     string
+		origin         string
+		domainFlags    []string
+		outputFile     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import-warp",
+		Short: "Fill domains, token IDs and routers in the config from a warp route deployment",
+		Long: `Read the warp route artifact written by the Hyperlane deploy tooling (the warp core config listing
+the route's token on every chain) and fill the config from it instead of transcribing addresses by hand:
+
+  - chain.domain is set to the Hyperlane domain of --origin
+  - each chain the origin token connects to gets a destinations entry with token_id set to the origin
+    token's ID, and on EVM chains the router, its router_type and the scale between the decimals
+
+router_type collateral and native require rpc_url, so they are only set on destinations that have one.
+Other settings of the config are kept. The domain IDs of common chains are built in; pass --domain
+name=id for the others.
+
+The config is read as written, so age-encrypted values stay encrypted in the output. sops-encrypted
+files are refused: decrypt, import and re-encrypt them instead.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			domains := make(map[string]uint32)
+			for _, flag := range domainFlags {
+				name, value, ok := strings.Cut(flag, "=")
+				domain, err := strconv.ParseUint(value, 10, 32)
+				if !ok || name == "" || err != nil {
+					return fmt.Errorf("invalid --domain %q, want name=id", flag)
+				}
+				domains[name] = uint32(domain)
+			}
+
+			deployment, err := types.LoadWarpDeployment(deploymentFile)
+			if err != nil {
+				return err
+			}
+			imp, err := deployment.Import(origin, domains)
+			if err != nil {
+				return err
+			}
+
+			config := &types.Config{Chain: types.DefaultChainConfig()}
+			if configFile != "" {
+				config, err = readPlainConfig(configFile)
+				if err != nil {
+					return err
+				}
+			}
+
+			changes := config.ApplyWarpImport(imp)
+			for domain, dest := range config.Destinations {
+				if err := dest.Validate(); err != nil {
+					return fmt.Errorf("destination %d: %w", domain, err)
+				}
+			}
+
+			fmt.Fprintf(out, "Warp token %s on %s (domain %d)\n", imp.TokenID, origin, imp.OriginDomain)
+			for _, route := range imp.Routes {
+				switch {
+				case route.Router == "":
+					fmt.Fprintf(out, "  ⚠ %s (domain %d): not an EVM chain, router not imported\n", route.Chain, route.Domain)
+				case route.RouterType == "":
+					fmt.Fprintf(out, "  ⚠ %s (domain %d): router %s of unknown standard, set router_type by hand\n", route.Chain, route.Domain, route.Router)
+				default:
+					fmt.Fprintf(out, "  ✓ %s (domain %d): %s router %s\n", route.Chain, route.Domain, route.RouterType, route.Router)
+				}
+			}
+			for _, change := range changes {
+				fmt.Fprintf(out, "  %s\n", change)
+			}
+			if len(changes) == 0 {
+				fmt.Fprintln(out, "Config already matches the deployment")
+			}
+
+			if err := config.SaveConfig(outputFile); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Config saved to %s\n", outputFile)
+			out.emit(warpImportResult{TokenID: imp.TokenID, OriginDomain: imp.OriginDomain, Changes: changes, Config: outputFile})
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&deploymentFile, "deployment", "", "Warp route deployment artifact (JSON) from the Hyperlane deploy tooling")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file to update (default: start from an empty config)")
+	cmd.Flags().StringVar(&origin, "origin", "celestia", "Chain name of this chain in the deployment")
+	cmd.Flags().StringArrayVar(&domainFlags, "domain", nil, "Hyperlane domain ID of a chain name, as name=id (repeatable)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output config file, may be the --config file itself")
+	cmd.MarkFlagRequired("deployment")
+	cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+// warpImportResult is the result of import-warp in JSON output mode
+type warpImportResult struct {
+	TokenID      string   `json:"token_id"`
+	OriginDomain uint32   `json:"origin_domain"`
+	Changes      []string `json:"changes"`
+	Config       string   `json:"config"` // Config file the import was saved to
+}
+
+// readPlainConfig reads a config file without decrypting it, so that writing it back never
+// leaks secrets. sops-encrypted files cannot be edited this way and are refused.
+func readPlainConfig(path string) (*types.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var probe struct {
+		SOPS json.RawMessage `json:"sops"`
+	}
+	if err := json.Unmarshal(data, &probe); err == nil && len(probe.SOPS) > 0 {
+		return nil, fmt.Errorf("%s is encrypted with sops; decrypt it before importing", path)
+	}
+
+	var config types.Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/changefeed"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/notify"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage/postgres"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage/sqlite"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/watcher"
+	"github.com/spf13/cobra"
+)
+
+func watchCmd() *cobra.Command {
+	var (
+		multisigAddr string
+		rpcURL       string
+		websocketURL string
+		configFile   string
+		source       string
+		statePath    string
+		postgresDSN  string
+		startHeight  int64
+		pollInterval time.Duration
+		maxBlocks    int64
+		outputFile   string
+		metricsAddr  string
+		feedFile     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Continuously parse new blocks for transfers to the multisig",
+		Long: `Keep the gRPC connection open, poll for new blocks and parse the MsgRemoteTransfer and MsgSend
+deposits to the multisig as they are included, instead of running parse over manual height ranges.
+
+The last processed height is checkpointed in a SQLite database (--state) or PostgreSQL (--postgres-dsn),
+so a restarted watcher resumes where it left off. Deposits are recorded as processed, so none is reported
+twice. On the first run, scanning starts at --start-height, or at the latest block if it is not set.
+
+After each pass that finds new deposits, every route still waiting to be generated is written to the
+output file in the same format as parse, and the configured notifiers are told about the new deposits
+(batched into one digest per window if the config sets notify.digest). A deposit whose metadata nonce
+its sender already used is flagged as a possible replay and added to the config's quarantine list.
+Heights that cannot be queried are retried by the next pass. Stop the watcher with SIGINT or SIGTERM.
+
+When the config lists sources, watch follows all of them at once, each with its own client, multisig,
+whitelist and checkpoint, and writes the routes of each to its own file, e.g. routes-neutron.json
+next to --output. --source restricts it to one of them, whose routes go to --output itself.
+
+With --websocket-url, the watcher subscribes to the new blocks of a CometBFT node, e.g.
+ws://localhost:26657/websocket, and starts a pass as soon as a block is committed instead of waiting
+for the next poll. Deposits are then turned into routes within a block. Polling continues as a
+fallback, and a dropped subscription is re-established with the backoff of the rpc config.
+
+With --changefeed, every change of a route's status in the state, including those recorded by generate,
+track and dispatch, is appended to a JSON lines file with an increasing sequence number, for external
+systems to tail. The file is checked against the state every poll interval.
+
+With --metrics-addr, Prometheus metrics are served at /metrics while the watcher runs.`,
+		RunE: withOutput(func(cmd *cobra.Command, args []string, out *commandOutput) error {
+			config := &types.Config{Chain: types.DefaultChainConfig(), Query: types.DefaultQueryConfig()}
+			if configFile != "" {
+				var err error
+				config, err = types.LoadConfig(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+
+			sources, err := watchSources(config, source, multisigAddr, rpcURL, cmd.Flags().Changed("rpc-url"), outputFile)
+			if err != nil {
+				return err
+			}
+			if len(sources) > 1 && (websocketURL != "" || startHeight != 0) {
+				return fmt.Errorf("--websocket-url and --start-height apply to a single chain, select one with --source")
+			}
+			for _, s := range sources {
+				if err := s.config.Chain.ValidateAddress(s.multisigAddr); err != nil {
+					return fmt.Errorf("%sinvalid multisig address: %w", s.label, err)
+				}
+			}
+			n, err := notify.FromConfig(config.Notify)
+			if err != nil {
+				return err
+			}
+			if digest, ok := n.(*notify.Digest); ok {
+				digest.OnError = func(err error) {
+					fmt.Fprintf(out, "⚠ Failed to send notification digest: %v\n", err)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := serveMetrics(ctx, out, metricsAddr); err != nil {
+				return err
+			}
+
+			// A second watcher on the same checkpoint or routes file would report deposits twice
+			// and overwrite the routes, so an overlapping run refuses to start
+			for _, s := range sources {
+				for _, path := range watchLocks(statePath, postgresDSN, s.name, s.outputFile) {
+					lock, err := output.LockFile(path, 0)
+					if err != nil {
+						return fmt.Errorf("another watcher is running: %w", err)
+					}
+					defer lock.Unlock()
+				}
+			}
+
+			store, err := openStorage(ctx, statePath, postgresDSN)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if feedFile != "" {
+				lock, err := output.LockFile(feedFile, 0)
+				if err != nil {
+					return fmt.Errorf("another watcher maintains the changefeed: %w", err)
+				}
+				defer lock.Unlock()
+				feed, err := changefeed.Open(feedFile)
+				if err != nil {
+					return err
+				}
+				go feed.Run(ctx, store, pollInterval, func(err error) {
+					fmt.Fprintf(os.Stderr, "⚠ %v\n", err)
+				})
+			}
+
+			// Sources are watched side by side, each with its own client, parser and routes file;
+			// their reports are printed one pass at a time. Every watcher is set up before any starts.
+			var (
+				watchers []*watcher.Watcher
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+			)
+			for _, s := range sources {
+				p, err := newParser(ctx, s.rpcURL, s.config)
+				if err != nil {
+					return fmt.Errorf("%sfailed to create parser: %w", s.label, err)
+				}
+				defer p.Close()
+
+				w, err := watcher.New(p, store, watcher.Config{
+					Source:       s.name,
+					MultisigAddr: s.multisigAddr,
+					StartHeight:  startHeight,
+					PollInterval: pollInterval,
+					MaxBlocks:    maxBlocks,
+				})
+				if err != nil {
+					return err
+				}
+
+				w.OnError = func(err error) {
+					fmt.Fprintf(os.Stderr, "⚠ %s%v\n", s.label, err)
+				}
+				if websocketURL != "" {
+					sub, err := client.NewBlockSubscription(websocketURL, rpcConn)
+					if err != nil {
+						return err
+					}
+					sub.SetErrorHandler(func(err error) {
+						fmt.Fprintf(os.Stderr, "⚠ %v, resubscribing\n", err)
+					})
+					go sub.Run(ctx)
+					w.SetTrigger(sub.Heights())
+				}
+				w.OnPass = func(pass watcher.Pass) {
+					mu.Lock()
+					defer mu.Unlock()
+					reportPass(ctx, out, store, n, s, pass)
+				}
+
+				fmt.Fprintf(out, "%sWatching %s for transfers to %s (checkpoint %q)...\n", s.label, s.rpcURL, s.multisigAddr, s.name)
+				watchers = append(watchers, w)
+			}
+			for _, w := range watchers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					w.Run(ctx)
+				}()
+			}
+			wg.Wait()
+
+			if err := notify.Flush(context.Background(), n); err != nil {
+				fmt.Fprintf(out, "⚠ Failed to send notification digest: %v\n", err)
+			}
+			fmt.Fprintln(out, "Stopped")
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&multisigAddr, "multisig-address", "", "Multisig address to watch (required unless the config lists sources)")
+	cmd.Flags().StringVar(&rpcURL, "rpc-url", "localhost:9090", "gRPC endpoint URL, or a CometBFT RPC URL such as http://localhost:26657")
+	cmd.Flags().StringVar(&websocketURL, "websocket-url", "", "CometBFT websocket endpoint to subscribe to new blocks, such as ws://localhost:26657/websocket")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Optional config file for whitelisting, query limits and notifications")
+	cmd.Flags().StringVar(&source, "source", "", "Only watch this source chain from the config's sources list (default: all of them)")
+	cmd.Flags().StringVar(&statePath, "state", "rebalancer.db", "SQLite database holding the checkpoint and processed deposits")
+	cmd.Flags().StringVar(&postgresDSN, "postgres-dsn", "", "Keep the state in PostgreSQL instead of SQLite")
+	cmd.Flags().Int64Var(&startHeight, "start-height", 0, "First height to scan when there is no checkpoint yet (default: latest block)")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", watcher.DefaultPollInterval, "How often to check for new blocks")
+	cmd.Flags().Int64Var(&maxBlocks, "max-blocks", watcher.DefaultMaxBlocks, "Heights parsed per pass while catching up")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "routes.json", "Output file for the routes waiting to be generated")
+	cmd.Flags().StringVar(&feedFile, "changefeed", "", "Append route status changes to this JSON lines file, e.g. changefeed.jsonl")
+	addMetricsFlag(cmd, &metricsAddr)
+	outputFlag(cmd, "output", "changefeed")
+
+	return cmd
+}
+
+// watchSource is a source chain followed by watch
+type watchSource struct {
+	name         string        // Checkpoint of the source and tag of its routes in the store
+	label        string        // Prefix of the lines printed about the source, empty for a single chain
+	config       *types.Config // Config narrowed to the source, with its chain and whitelist
+	multisigAddr string
+	rpcURL       string
+	outputFile   string // Routes of the source waiting to be generated
+}
+
+// watchSources returns the source chains to watch: every source of the config, or only the one
+// named by filter, or the config's own chain if it lists no sources. Each source's rpc_url is used
+// unless --rpc-url is given for a single chain. When several sources are watched, the routes of
+// each are written next to outputFile, e.g. routes-neutron.json.
+func watchSources(config *types.Config, filter, multisigAddr, rpcURL string, rpcURLSet bool, outputFile string) ([]watchSource, error) {
+	if len(config.Sources) == 0 {
+		if filter != "" {
+			return nil, fmt.Errorf("source %s is not configured", filter)
+		}
+		if multisigAddr == "" {
+			return nil, fmt.Errorf("--multisig-address is required")
+		}
+		return []watchSource{{name: multisigAddr, config: config, multisigAddr: multisigAddr, rpcURL: rpcURL, outputFile: outputFile}}, nil
+	}
+
+	var sources []watchSource
+	for _, src := range config.Sources {
+		if filter != "" && src.Name != filter {
+			continue
+		}
+		sourceConfig, _, err := config.ForSource(src.Name)
+		if err != nil {
+			return nil, err
+		}
+		s := watchSource{name: src.Name, config: sourceConfig, multisigAddr: src.MultisigAddr, rpcURL: src.RPCURL, outputFile: outputFile}
+		if s.rpcURL == "" {
+			s.rpcURL = rpcURL
+		}
+		sources = append(sources, s)
+	}
+	switch {
+	case len(sources) == 0:
+		return nil, fmt.Errorf("source %s is not configured", filter)
+	case len(sources) == 1:
+		if multisigAddr != "" {
+			sources[0].multisigAddr = multisigAddr
+		}
+		if rpcURLSet {
+			sources[0].rpcURL = rpcURL
+		}
+	default:
+		if multisigAddr != "" {
+			return nil, fmt.Errorf("--multisig-address applies to a single chain, select one with --source")
+		}
+		for i := range sources {
+			sources[i].label = "[" + sources[i].name + "] "
+			sources[i].outputFile = siblingFile(outputFile, sources[i].name)
+		}
+	}
+	for _, s := range sources {
+		if s.multisigAddr == "" {
+			return nil, fmt.Errorf("source %s has no multisig_address", s.name)
+		}
+	}
+	return sources, nil
+}
+
+// reportPass prints a pass of the watcher of source s, writes the routes of the source waiting to
+// be generated and notifies the operators of new deposits, replays and amount mismatches
+func reportPass(ctx context.Context, out io.Writer, store storage.Storage, n notify.Notifier, s watchSource, pass watcher.Pass) {
+	fmt.Fprintf(out, "%sHeights %d to %d (latest %d): %d new routes\n", s.label, pass.FromHeight, pass.ToHeight, pass.Latest, len(pass.Routes))
+	for _, skipped := range pass.Skipped {
+		fmt.Fprintf(out, "  ⚠ skipped tx %s (height %d, amount %s): %s\n", skipped.TxHash, skipped.BlockHeight, skipped.Amount, skipped.Reason)
+	}
+	for _, f := range pass.FailedHeights {
+		fmt.Fprintf(out, "  ✗ height %d: %s, retrying next pass\n", f.Height, f.Error)
+	}
+	if len(pass.Replays) > 0 {
+		replays := holdReplays(out, s.config, pass.Replays)
+		event := notify.Event{
+			Severity: notify.SeverityCritical,
+			Title:    "Deposits replaying a nonce",
+			Message:  fmt.Sprintf("%d new deposits reuse a nonce their sender already used: %s", len(replays), strings.Join(replays, "; ")),
+			Multisig: s.multisigAddr,
+			Fields:   map[string]string{"replays": strconv.Itoa(len(replays)), "source": s.name},
+		}
+		if err := notify.Send(ctx, n, event); err != nil {
+			fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", err)
+		}
+	}
+	if len(pass.Routes) == 0 && len(pass.Replays) == 0 {
+		return
+	}
+	if mismatches := warnAmountMismatches(out, pass.Routes); len(mismatches) > 0 {
+		event := notify.Event{
+			Severity: notify.SeverityWarning,
+			Title:    "Metadata amounts differ from deposits",
+			Message:  fmt.Sprintf("%d new deposits carry a metadata amount other than the amount deposited: %s", len(mismatches), strings.Join(mismatches, "; ")),
+			Multisig: s.multisigAddr,
+			Fields:   map[string]string{"mismatches": strconv.Itoa(len(mismatches)), "source": s.name},
+		}
+		if err := notify.Send(ctx, n, event); err != nil {
+			fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", err)
+		}
+	}
+
+	pending, err := writePendingRoutes(ctx, store, s.name, s.multisigAddr, s.outputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ %s%v\n", s.label, err)
+		return
+	}
+	fmt.Fprintf(out, "%s%d routes waiting to be generated saved to %s\n", s.label, len(pending.Routes), s.outputFile)
+	if len(pass.Routes) == 0 {
+		return
+	}
+
+	found := &types.Routes{Routes: pass.Routes}
+	strategy.RecomputeTotal(found)
+	event := notify.Event{
+		Kind:     notify.KindRoutesDiscovered,
+		Severity: notify.SeverityInfo,
+		Title:    "New deposits to rebalance",
+		Message:  fmt.Sprintf("%d new deposits in heights %d to %d, %d routes waiting to be generated", len(pass.Routes), pass.FromHeight, pass.ToHeight, len(pending.Routes)),
+		Multisig: s.multisigAddr,
+		Total:    found.TotalAmount,
+		Fields:   map[string]string{"routes": strconv.Itoa(len(pass.Routes)), "to_height": strconv.FormatInt(pass.ToHeight, 10), "source": s.name},
+	}
+	if err := notify.Send(ctx, n, event); err != nil {
+		fmt.Fprintf(out, "⚠ Failed to send notification: %v\n", err)
+	}
+}
+
+// watchLocks returns the files a watcher holds locked while it runs: its routes file, and its
+// checkpoint in a SQLite database. Watchers sharing a PostgreSQL store are told apart by their
+// routes files only.
+func watchLocks(statePath, postgresDSN, checkpointName, outputFile string) []string {
+	locks := []string{outputFile}
+	if postgresDSN == "" {
+		locks = append(locks, statePath+"-"+checkpointName)
+	}
+	return locks
+}
+
+// openStorage opens the PostgreSQL store if dsn is set, otherwise the SQLite database at path
+func openStorage(ctx context.Context, path, dsn string) (storage.Storage, error) {
+	if dsn != "" {
+		return postgres.Open(ctx, dsn)
+	}
+	return sqlite.Open(ctx, path)
+}
+
+// writePendingRoutes writes the routes the store holds as parsed for source to path and returns
+// them. A store shared by several sources holds the routes of the others too.
+func writePendingRoutes(ctx context.Context, store storage.Storage, source, multisigAddr, path string) (*types.Routes, error) {
+	routes, err := watcher.PendingRoutes(ctx, store, source, multisigAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(routes, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal routes: %w", err)
+	}
+	if err := output.WriteAtomic(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write routes: %w", err)
+	}
+	return routes, nil
+}
@@ -0,0 +1,231 @@
+//go:build integration
+
+// Package integration runs the rebalancer end to end against a local hyperlane-cosmos chain in
+// docker compose: deposits with routing metadata are sent to a multisig, then parsed, generated,
+// verified, signed and broadcast with the built binary. Run it with make integration.
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/localnet"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+const (
+	remoteDomain = 1380012617
+	remoteRouter = "0x0000000000000000000000001234567890123456789012345678901234567890"
+	recipient    = "0x000000000000000000000000742d35cc6634c0532925a3b844bc9e7595f0beb0"
+)
+
+var (
+	chain      *localnet.Localnet
+	rebalancer string // Path of the built celestia-rebalancer binary
+)
+
+// TestMain starts the localnet, or attaches to a running one with LOCALNET_ATTACH=1, and builds the
+// binary unless REBALANCER_BIN names one. LOCALNET_KEEP=1 leaves a started localnet running.
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	ctx := context.Background()
+	config := localnet.DefaultConfig(filepath.Join("localnet", "docker-compose.yml"))
+
+	var err error
+	if os.Getenv("LOCALNET_ATTACH") != "" {
+		chain, err = localnet.Attach(ctx, config)
+	} else {
+		chain, err = localnet.Start(ctx, config)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() {
+		if os.Getenv("LOCALNET_ATTACH") != "" || os.Getenv("LOCALNET_KEEP") != "" {
+			chain.Close()
+			return
+		}
+		if err := chain.Stop(context.Background()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+
+	rebalancer = os.Getenv("REBALANCER_BIN")
+	if rebalancer == "" {
+		dir, err := os.MkdirTemp("", "rebalancer-integration")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		rebalancer = filepath.Join(dir, "celestia-rebalancer")
+		build := exec.Command("go", "build", "-o", rebalancer, "../cmd/celestia-rebalancer")
+		if out, err := build.CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to build celestia-rebalancer: %v\n%s", err, out)
+			return 1
+		}
+	}
+
+	return m.Run()
+}
+
+// runRebalancer runs the binary in dir and fails the test if it exits non-zero
+func runRebalancer(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(rebalancer, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("celestia-rebalancer %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+// readRoutes reads a routes file written by parse
+func readRoutes(t *testing.T, file string) *types.Routes {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var routes types.Routes
+	if err := json.Unmarshal(data, &routes); err != nil {
+		t.Fatal(err)
+	}
+	return &routes
+}
+
+func TestRebalanceLocalnet(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	cfg := chain.Config()
+	dir := t.TempDir()
+	grpcAddr := cfg.GRPCAddr
+
+	// A fresh multisig per run, so the suite can run against a kept localnet
+	multisigKey := fmt.Sprintf("multisig-%d", time.Now().Unix())
+	multisig, err := chain.AddMultisig(ctx, multisigKey, 2, "signer1", "signer2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix, _, err := bech32.DecodeAndConvert(multisig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.Send(ctx, "validator", multisig, sdk.NewCoin(cfg.Denom, math.NewInt(10_000_000)), ""); err != nil {
+		t.Fatalf("failed to fund the multisig: %v", err)
+	}
+
+	tokenID, err := chain.CreateWarpRoute(ctx, "validator", remoteDomain, remoteRouter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := types.Config{Chain: types.ChainConfig{Bech32Prefix: prefix, Denom: cfg.Denom, GasPrice: "0.002" + cfg.Denom, Domain: cfg.LocalDomain}}
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deposits with routing metadata in the memo
+	route := client.RoutingMetadata{DestinationDomain: remoteDomain, Recipient: recipient, TokenID: tokenID}
+	amounts := []int64{1_000_000, 250_000}
+	var fromHeight, toHeight int64
+	deposits := make(map[string]string)
+	for _, amount := range amounts {
+		hash, err := chain.Deposit(ctx, "depositor", multisig, sdk.NewCoin(cfg.Denom, math.NewInt(amount)), route)
+		if err != nil {
+			t.Fatalf("failed to deposit: %v", err)
+		}
+		resp, err := chain.Client().GetTx(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fromHeight == 0 {
+			fromHeight = resp.Height
+		}
+		toHeight = resp.Height
+		deposits[hash] = math.NewInt(amount).String()
+	}
+
+	// parse finds both deposits with their routing
+	runRebalancer(t, dir, "parse", "--multisig-address", multisig, "--rpc-url", grpcAddr, "--config", configFile,
+		"--from-height", fmt.Sprint(fromHeight), "--to-height", fmt.Sprint(toHeight), "--strict", "--strict-decode", "--output", "routes.json")
+	routes := readRoutes(t, filepath.Join(dir, "routes.json"))
+	if len(routes.Routes) != len(amounts) {
+		t.Fatalf("parsed %d routes, want %d: %+v", len(routes.Routes), len(amounts), routes.Routes)
+	}
+	for _, r := range routes.Routes {
+		if deposits[r.TxHash] != r.Amount || r.Denom != cfg.Denom {
+			t.Errorf("route from tx %s = %s%s, want %s%s", r.TxHash, r.Amount, r.Denom, deposits[r.TxHash], cfg.Denom)
+		}
+		if r.RouteInfo == nil || r.RouteInfo.DestinationDomain != remoteDomain || !strings.EqualFold(r.RouteInfo.TokenID, tokenID) {
+			t.Errorf("route from tx %s has routing %+v, want domain %d and token %s", r.TxHash, r.RouteInfo, remoteDomain, tokenID)
+		}
+	}
+
+	// generate and verify the unsigned transaction
+	runRebalancer(t, dir, "generate", "--multisig-address", multisig, "--routes", "routes.json", "--config", configFile,
+		"--gas-limit", "600000", "--fees", "2000"+cfg.Denom, "--output", "unsigned-tx.json")
+	runRebalancer(t, dir, "verify", "--routes", "routes.json", "--transaction", "unsigned-tx.json", "--strict")
+
+	// Sign with both multisig members, then verify and broadcast the signed transaction
+	signed, err := chain.SignMultisig(ctx, filepath.Join(dir, "unsigned-tx.json"), multisigKey, "signer1", "signer2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "signed-tx.json"), signed, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := chain.Client().GetBalances(multisig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broadcastFrom, err := chain.Client().LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runRebalancer(t, dir, "verify", "--routes", "routes.json", "--transaction", "signed-tx.json", "--strict",
+		"--broadcast", "--rpc-url", grpcAddr)
+	broadcastTo, err := chain.Client().LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The transfers left the multisig and are found by an outbound parse
+	after, err := chain.Client().GetBalances(multisig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := before.AmountOf(cfg.Denom).Sub(after.AmountOf(cfg.Denom))
+	if sent.LT(math.NewInt(1_250_000)) {
+		t.Errorf("multisig balance fell by %s%s, want at least the 1250000%s routed", sent, cfg.Denom, cfg.Denom)
+	}
+
+	runRebalancer(t, dir, "parse", "--direction", "outbound", "--multisig-address", multisig, "--rpc-url", grpcAddr, "--config", configFile,
+		"--from-height", fmt.Sprint(broadcastFrom), "--to-height", fmt.Sprint(broadcastTo), "--strict", "--strict-decode", "--output", "outbound.json")
+	outbound := readRoutes(t, filepath.Join(dir, "outbound.json"))
+	if len(outbound.Routes) != len(amounts) {
+		t.Errorf("found %d outbound transfers, want %d: %+v", len(outbound.Routes), len(amounts), outbound.Routes)
+	}
+}
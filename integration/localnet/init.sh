@@ -0,0 +1,31 @@
+#!/bin/sh
+# Initializes a single-validator chain with funded test keys on first start, then runs the node.
+# Keys: validator, depositor, signer1, signer2 in the test keyring.
+set -eu
+
+CHAIN_ID=${CHAIN_ID:-rebalancer-localnet}
+DENOM=${DENOM:-uhyp}
+HOME_DIR=${HOME_DIR:-/root/.hypd}
+
+if [ ! -f "$HOME_DIR/config/genesis.json" ]; then
+  hypd init localnet --chain-id "$CHAIN_ID" --home "$HOME_DIR" >/dev/null 2>&1
+
+  for key in validator depositor signer1 signer2; do
+    hypd keys add "$key" --keyring-backend test --home "$HOME_DIR" >/dev/null 2>&1
+    hypd genesis add-genesis-account "$key" "1000000000000$DENOM" --keyring-backend test --home "$HOME_DIR"
+  done
+
+  # Use the test denom everywhere the simapp defaults to stake
+  sed -i "s/\"stake\"/\"$DENOM\"/g" "$HOME_DIR/config/genesis.json"
+
+  hypd genesis gentx validator "1000000000$DENOM" --chain-id "$CHAIN_ID" --keyring-backend test --home "$HOME_DIR" >/dev/null 2>&1
+  hypd genesis collect-gentxs --home "$HOME_DIR" >/dev/null 2>&1
+
+  # Fast blocks, and the gRPC and RPC endpoints reachable from the host
+  sed -i 's/^timeout_commit = .*/timeout_commit = "1s"/' "$HOME_DIR/config/config.toml"
+  sed -i 's#^laddr = "tcp://127.0.0.1:26657"#laddr = "tcp://0.0.0.0:26657"#' "$HOME_DIR/config/config.toml"
+  sed -i 's#^address = "localhost:9090"#address = "0.0.0.0:9090"#' "$HOME_DIR/config/app.toml"
+  sed -i "s/^minimum-gas-prices = .*/minimum-gas-prices = \"0$DENOM\"/" "$HOME_DIR/config/app.toml"
+fi
+
+exec hypd start --home "$HOME_DIR"
@@ -0,0 +1,42 @@
+#!/bin/sh
+# Creates the Hyperlane core of the localnet and a collateral warp token for its denom, with a router
+# enrolled for a remote domain. Prints the token ID on the last line.
+# Usage: setup-warp.sh <owner-key> <local-domain> <remote-domain> <remote-router>
+set -eu
+
+OWNER=$1
+LOCAL_DOMAIN=$2
+REMOTE_DOMAIN=$3
+REMOTE_ROUTER=$4
+
+CHAIN_ID=${CHAIN_ID:-rebalancer-localnet}
+DENOM=${DENOM:-uhyp}
+HOME_DIR=${HOME_DIR:-/root/.hypd}
+
+# tx sends a transaction, waits for it and prints the ID of the object it created
+tx() {
+  hash=$(hypd tx "$@" --from "$OWNER" --chain-id "$CHAIN_ID" --keyring-backend test --home "$HOME_DIR" \
+    --fees "2000$DENOM" --gas 400000 --yes --output json | jq -r .txhash)
+  for _ in $(seq 1 30); do
+    if result=$(hypd query tx "$hash" --home "$HOME_DIR" --output json 2>/dev/null); then
+      if [ "$(echo "$result" | jq -r .code)" != "0" ]; then
+        echo "tx $hash failed: $(echo "$result" | jq -r .raw_log)" >&2
+        exit 1
+      fi
+      echo "$result" | jq -r '[.events[].attributes[] | select(.key | test("(^|_)id$")) | .value][0]' | tr -d '"'
+      return
+    fi
+    sleep 1
+  done
+  echo "tx $hash was not included" >&2
+  exit 1
+}
+
+ism=$(tx hyperlane ism create-noop)
+mailbox=$(tx hyperlane mailbox create "$ism" "$LOCAL_DOMAIN")
+hook=$(tx hyperlane hooks noop create)
+tx hyperlane mailbox set "$mailbox" --default-hook "$hook" --required-hook "$hook" >/dev/null
+token=$(tx hyperlane-transfer create-collateral-token "$mailbox" "$DENOM")
+tx hyperlane-transfer enroll-remote-router "$token" "$REMOTE_DOMAIN" "$REMOTE_ROUTER" 50000 >/dev/null
+
+echo "$token"
@@ -0,0 +1,166 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+)
+
+// Attestation is an operator's signed statement that a routes file and a generated transaction
+// belong together. It links the output of parse to the transaction taken to the signing ceremony.
+type Attestation struct {
+	RoutesDigest string    `json:"routes_sha256"`  // SHA-256 of the routes file
+	TxDigest     string    `json:"tx_body_sha256"` // SHA-256 of the transaction body bytes
+	Operator     string    `json:"operator"`       // Hex-encoded ed25519 public key of the operator
+	Timestamp    time.Time `json:"timestamp"`
+	Signature    string    `json:"signature"` // Hex-encoded ed25519 signature over the payload
+}
+
+// payload is the byte string that is signed
+func (a *Attestation) payload() []byte {
+	return []byte(fmt.Sprintf("celestia-rebalancer attestation v1\nroutes:%s\ntx:%s\ntimestamp:%s",
+		a.RoutesDigest, a.TxDigest, a.Timestamp.UTC().Format(time.RFC3339)))
+}
+
+// Sign creates an attestation for the routes file and transaction contents
+func Sign(key ed25519.PrivateKey, routesData, txData []byte) (*Attestation, error) {
+	txDigest, err := TxDigest(txData)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Attestation{
+		RoutesDigest: Digest(routesData),
+		TxDigest:     txDigest,
+		Operator:     hex.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Timestamp:    time.Now().UTC().Truncate(time.Second),
+	}
+	a.Signature = hex.EncodeToString(ed25519.Sign(key, a.payload()))
+
+	return a, nil
+}
+
+// Verify checks the signature and that the attestation covers the given routes file and transaction.
+// If trusted is non-empty, the operator must be one of the trusted hex-encoded public keys.
+func (a *Attestation) Verify(routesData, txData []byte, trusted []string) error {
+	pub, err := hex.DecodeString(a.Operator)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid operator public key %s", a.Operator)
+	}
+	sig, err := hex.DecodeString(a.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), a.payload(), sig) {
+		return fmt.Errorf("attestation signature is invalid")
+	}
+
+	if len(trusted) > 0 {
+		found := false
+		for _, key := range trusted {
+			if strings.EqualFold(key, a.Operator) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("attestation is signed by untrusted operator %s", a.Operator)
+		}
+	}
+
+	if digest := Digest(routesData); digest != a.RoutesDigest {
+		return fmt.Errorf("routes file digest %s does not match attested %s", digest, a.RoutesDigest)
+	}
+	txDigest, err := TxDigest(txData)
+	if err != nil {
+		return err
+	}
+	if txDigest != a.TxDigest {
+		return fmt.Errorf("transaction body digest %s does not match attested %s", txDigest, a.TxDigest)
+	}
+
+	return nil
+}
+
+// Digest returns the hex-encoded SHA-256 of data
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// TxDigest returns the SHA-256 of the transaction body. The body does not change when signatures
+// are added, so the same digest identifies the unsigned and the signed transaction. Any format
+// accepted by generator.DecodeTxRaw is accepted.
+func TxDigest(txData []byte) (string, error) {
+	raw, err := generator.DecodeTxRaw(txData)
+	if err != nil {
+		return "", err
+	}
+	return Digest(raw.BodyBytes), nil
+}
+
+// GenerateKey creates a new operator key
+func GenerateKey() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// LoadKey reads an operator key file containing a hex-encoded ed25519 seed
+func LoadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("key file %s does not contain a hex-encoded %d-byte ed25519 seed", path, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// SaveKey writes the key's seed to path, readable only by the owner
+func SaveKey(key ed25519.PrivateKey, path string) error {
+	if err := output.WriteAtomic(path, []byte(hex.EncodeToString(key.Seed())+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+// Load reads an attestation file
+func Load(path string) (*Attestation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation: %w", err)
+	}
+
+	var a Attestation
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse attestation: %w", err)
+	}
+	return &a, nil
+}
+
+// Save writes the attestation to path
+func (a *Attestation) Save(path string) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+	if err := output.WriteAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write attestation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	other, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	routes := []byte(`{"routes":[],"total_amount":"0"}`)
+	body := tx.TxBody{Memo: "rebalance"}
+	bodyBytes, err := body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+	txData, err := json.Marshal(tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil {
+		t.Fatalf("failed to marshal tx: %v", err)
+	}
+
+	a, err := Sign(key, routes, txData)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	operator := a.Operator
+	otherOperator := hex.EncodeToString(other.Public().(ed25519.PublicKey))
+
+	tests := []struct {
+		name    string
+		routes  []byte
+		trusted []string
+		wantErr bool
+	}{
+		{name: "valid", routes: routes},
+		{name: "trusted operator", routes: routes, trusted: []string{operator}},
+		{name: "untrusted operator", routes: routes, trusted: []string{otherOperator}, wantErr: true},
+		{name: "modified routes", routes: []byte(`{"routes":[],"total_amount":"1"}`), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.Verify(tt.routes, txData, tt.trusted)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Tampering with the attested digests invalidates the signature
+	tampered := *a
+	tampered.RoutesDigest = Digest([]byte("other"))
+	if err := tampered.Verify([]byte("other"), txData, nil); err == nil {
+		t.Error("Verify() accepted a tampered attestation")
+	}
+}
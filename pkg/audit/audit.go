@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+)
+
+// Entry is one record in the audit trail
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Event     string    `json:"event"`
+	TxHash    string    `json:"tx_hash,omitempty"` // Source transaction the event concerns, if any
+	Details   string    `json:"details"`
+}
+
+// Event names recorded in the audit trail
+const (
+	EventOverride   = "override"
+	EventQuarantine = "quarantine"
+	EventRelease    = "release"
+	EventDeadLetter = "dead-letter"
+)
+
+// Log is an append-only audit trail stored as JSON lines, so entries from earlier runs are never rewritten
+type Log struct {
+	path string
+}
+
+// NewLog returns the audit trail stored at path
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Record appends an entry to the audit trail, under the log's lock so entries appended by
+// overlapping runs are not interleaved
+func (l *Log) Record(event, txHash, details string) error {
+	entry := Entry{
+		Timestamp: time.Now().UTC(),
+		Event:     event,
+		TxHash:    txHash,
+		Details:   details,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	lock, err := output.LockFile(l.path, output.DefaultLockWait)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Entries reads all entries in the audit trail
+func (l *Log) Entries() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
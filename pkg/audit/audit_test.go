@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAppends(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	if err := log.Record(EventOverride, "A1", "amount 100 -> 80"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := NewLog(log.path).Record(EventOverride, "A2", "recipient changed"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].TxHash != "A1" || entries[1].TxHash != "A2" {
+		t.Fatalf("entries = %+v, want A1 then A2", entries)
+	}
+	if entries[0].Event != EventOverride || entries[0].Timestamp.IsZero() {
+		t.Errorf("entry = %+v, want override event with timestamp", entries[0])
+	}
+}
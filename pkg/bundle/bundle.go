@@ -0,0 +1,273 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/attestation"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+)
+
+// Kind identifies the role of a file in a signing bundle
+type Kind string
+
+const (
+	KindRoutes        Kind = "routes"
+	KindTransaction   Kind = "transaction"    // Unsigned transaction or batch sign doc
+	KindBatchManifest Kind = "batch-manifest" // Sequences assigned to batch sign docs
+	KindVerification  Kind = "verification"   // Verification report produced when bundling
+	KindAttestation   Kind = "attestation"
+)
+
+// ManifestName is the name of the manifest inside a bundle; it is always the first entry
+const ManifestName = "manifest.json"
+
+// manifestVersion is the version of the manifest format written by Write
+const manifestVersion = 1
+
+// maxBundleSize limits how much is read from a bundle, guarding against compression bombs
+const maxBundleSize = 64 << 20
+
+// File describes one file in a bundle
+type File struct {
+	Name   string `json:"name"`
+	Kind   Kind   `json:"kind"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the files of a bundle with their digests
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// ConfigSHA256 is the digest of the config file used to generate the transaction. The config
+	// itself is not bundled since it may hold secrets.
+	ConfigSHA256 string `json:"config_sha256,omitempty"`
+	Files        []File `json:"files"`
+}
+
+// Entry is a file to be written to, or read from, a bundle
+type Entry struct {
+	Name string
+	Kind Kind
+	Data []byte
+}
+
+// Bundle is an opened signing bundle
+type Bundle struct {
+	Manifest Manifest
+	contents map[string][]byte
+}
+
+// Write packages entries into a gzip-compressed tar archive at path, preceded by a manifest of
+// their digests. Entry names must be unique base names.
+func Write(path string, entries []Entry, configDigest string, now time.Time) (*Manifest, error) {
+	manifest := &Manifest{
+		Version:      manifestVersion,
+		CreatedAt:    now.UTC().Truncate(time.Second),
+		ConfigSHA256: configDigest,
+	}
+
+	names := map[string]bool{ManifestName: true}
+	for _, entry := range entries {
+		if err := validName(entry.Name); err != nil {
+			return nil, err
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("duplicate file name %s in bundle", entry.Name)
+		}
+		names[entry.Name] = true
+
+		manifest.Files = append(manifest.Files, File{
+			Name:   entry.Name,
+			Kind:   entry.Kind,
+			Size:   int64(len(entry.Data)),
+			SHA256: attestation.Digest(entry.Data),
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := add(ManifestName, manifestData); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if err := add(entry.Name, entry.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+
+	if err := output.WriteAtomic(path, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// Open reads a bundle and its manifest. The contents are not checked against the manifest until
+// Verify is called.
+func Open(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("bundle is not gzip-compressed: %w", err)
+	}
+	limited := &io.LimitedReader{R: gz, N: maxBundleSize + 1}
+	tr := tar.NewReader(limited)
+
+	b := &Bundle{contents: make(map[string][]byte)}
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("bundle entry %s is not a regular file", header.Name)
+		}
+		if err := validName(header.Name); err != nil {
+			return nil, err
+		}
+		if _, ok := b.contents[header.Name]; ok {
+			return nil, fmt.Errorf("bundle contains %s more than once", header.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from bundle: %w", header.Name, err)
+		}
+		if limited.N <= 0 {
+			return nil, fmt.Errorf("bundle exceeds %d bytes", maxBundleSize)
+		}
+		b.contents[header.Name] = data
+	}
+
+	manifestData, ok := b.contents[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("bundle has no %s", ManifestName)
+	}
+	if err := json.Unmarshal(manifestData, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if b.Manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported bundle manifest version %d", b.Manifest.Version)
+	}
+	delete(b.contents, ManifestName)
+
+	return b, nil
+}
+
+// Verify checks that the bundle holds exactly the files listed in its manifest, with matching
+// sizes and digests. If configDigest is set, the manifest must record the same config digest.
+func (b *Bundle) Verify(configDigest string) error {
+	var problems []string
+
+	listed := make(map[string]bool)
+	for _, file := range b.Manifest.Files {
+		listed[file.Name] = true
+		data, ok := b.contents[file.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is listed in the manifest but missing", file.Name))
+			continue
+		}
+		if int64(len(data)) != file.Size {
+			problems = append(problems, fmt.Sprintf("%s has %d bytes, manifest lists %d", file.Name, len(data), file.Size))
+		}
+		if digest := attestation.Digest(data); digest != file.SHA256 {
+			problems = append(problems, fmt.Sprintf("%s digest %s does not match manifest %s", file.Name, digest, file.SHA256))
+		}
+	}
+	for name := range b.contents {
+		if !listed[name] {
+			problems = append(problems, fmt.Sprintf("%s is not listed in the manifest", name))
+		}
+	}
+
+	if configDigest != "" && configDigest != b.Manifest.ConfigSHA256 {
+		problems = append(problems, fmt.Sprintf("config digest %s does not match bundled %s", configDigest, b.Manifest.ConfigSHA256))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("bundle verification failed:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// Files returns the bundled files of the given kind in manifest order
+func (b *Bundle) Files(kind Kind) []Entry {
+	var entries []Entry
+	for _, file := range b.Manifest.Files {
+		if file.Kind == kind {
+			entries = append(entries, Entry{Name: file.Name, Kind: file.Kind, Data: b.contents[file.Name]})
+		}
+	}
+	return entries
+}
+
+// Extract writes the bundled files listed in the manifest to dir, refusing to overwrite existing files
+func (b *Bundle) Extract(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for _, file := range b.Manifest.Files {
+		path := filepath.Join(dir, file.Name)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists, refusing to overwrite it", path)
+		}
+		if err := output.WriteAtomic(path, b.contents[file.Name], 0644); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", file.Name, err)
+		}
+	}
+	return nil
+}
+
+// validName accepts plain file names only, so bundles cannot write outside the extraction directory
+func validName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid bundle file name %q", name)
+	}
+	return nil
+}
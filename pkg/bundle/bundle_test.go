@@ -0,0 +1,133 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/attestation"
+)
+
+func sampleEntries() []Entry {
+	return []Entry{
+		{Name: "routes.json", Kind: KindRoutes, Data: []byte(`{"routes":[]}`)},
+		{Name: "unsigned-tx.json", Kind: KindTransaction, Data: []byte(`{"body":{}}`)},
+	}
+}
+
+func TestWriteOpenVerify(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bundle.tar.gz")
+	configDigest := attestation.Digest([]byte(`{"role":"coordinator"}`))
+
+	manifest, err := Write(path, sampleEntries(), configDigest, time.Now())
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(manifest.Files) != 2 || manifest.Files[0].SHA256 != attestation.Digest([]byte(`{"routes":[]}`)) {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+
+	b, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := b.Verify(configDigest); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := b.Verify(attestation.Digest([]byte("other config"))); err == nil {
+		t.Error("Verify() with a different config digest succeeded, want error")
+	}
+
+	txs := b.Files(KindTransaction)
+	if len(txs) != 1 || txs[0].Name != "unsigned-tx.json" || string(txs[0].Data) != `{"body":{}}` {
+		t.Errorf("Files(transaction) = %+v", txs)
+	}
+
+	out := filepath.Join(dir, "extracted")
+	if err := b.Extract(out); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, "routes.json")); err != nil || string(data) != `{"routes":[]}` {
+		t.Errorf("extracted routes.json = %q, %v", data, err)
+	}
+	if err := b.Extract(out); err == nil {
+		t.Error("Extract() over existing files succeeded, want error")
+	}
+}
+
+func TestWriteRejectsInvalidNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+	for _, entries := range [][]Entry{
+		{{Name: "../routes.json", Kind: KindRoutes}},
+		{{Name: ManifestName, Kind: KindRoutes}},
+		{{Name: "a.json", Kind: KindRoutes}, {Name: "a.json", Kind: KindTransaction}},
+	} {
+		if _, err := Write(path, entries, "", time.Now()); err == nil {
+			t.Errorf("Write(%+v) succeeded, want error", entries)
+		}
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if _, err := Write(path, sampleEntries(), "", time.Now()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	b, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(b *Bundle)
+		want   string
+	}{
+		{"modified file", func(b *Bundle) { b.contents["routes.json"] = []byte(`{"routes":[1]}`) }, "digest"},
+		{"missing file", func(b *Bundle) { delete(b.contents, "unsigned-tx.json") }, "missing"},
+		{"extra file", func(b *Bundle) { b.contents["extra.json"] = []byte("{}") }, "not listed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := &Bundle{Manifest: b.Manifest, contents: make(map[string][]byte)}
+			for name, data := range b.contents {
+				tampered.contents[name] = data
+			}
+			tt.modify(tampered)
+
+			err := tampered.Verify("")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenRejectsUnsafeEntries(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	data := []byte("x")
+	if err := tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: int64(len(data))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(data)
+	tw.Close()
+	gz.Close()
+
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "invalid bundle file name") {
+		t.Errorf("Open() error = %v, want invalid name", err)
+	}
+}
@@ -0,0 +1,69 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/verifier"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// Report is the verification report stored in a bundle
+type Report struct {
+	Transactions []string               `json:"transactions"` // Bundled transaction files covered by the report
+	Result       *verifier.VerifyResult `json:"result"`
+}
+
+// VerifyContents verifies the routes against the messages of all transactions together, so that
+// batch sign docs are checked as one set. The transactions must already be decrypted.
+func VerifyContents(v *verifier.Verifier, routesData []byte, transactions []Entry) (*Report, error) {
+	var routes types.Routes
+	if err := json.Unmarshal(routesData, &routes); err != nil {
+		return nil, fmt.Errorf("failed to parse routes file: %w", err)
+	}
+
+	report := &Report{}
+	var (
+		messages  []*codectypes.Any
+		authBytes []byte
+	)
+	for _, entry := range transactions {
+		body, authInfo, err := decodeTx(entry.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode transaction %s: %w", entry.Name, err)
+		}
+		messages = append(messages, body.Messages...)
+		if authBytes == nil {
+			authBytes = authInfo
+		}
+		report.Transactions = append(report.Transactions, entry.Name)
+	}
+
+	bodyBytes, err := (&tx.TxBody{Messages: messages}).Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode combined transaction body: %w", err)
+	}
+
+	report.Result, err = v.Verify(&routes, &tx.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authBytes})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// decodeTx returns the body and encoded auth info of a transaction in any format accepted by
+// generator.DecodeTxRaw
+func decodeTx(data []byte) (*tx.TxBody, []byte, error) {
+	raw, err := generator.DecodeTxRaw(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var body tx.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode transaction body: %w", err)
+	}
+	return &body, raw.AuthInfoBytes, nil
+}
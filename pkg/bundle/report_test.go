@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/verifier"
+)
+
+const testMultisig = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+
+func TestVerifyContentsBatches(t *testing.T) {
+	route := types.HyperlaneRoute{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}
+	second := route
+	second.TxHash = "DEF456"
+	second.Amount = "2000000"
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route, second}, MultisigAddr: testMultisig}
+	routesData, err := json.Marshal(routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One message per batch, as written by generate --max-msgs-per-tx 1
+	gen := generator.NewGenerator(testMultisig)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var transactions []Entry
+	for i, chunk := range generator.SplitMsgs(msgs, 1) {
+		unsigned, err := gen.BuildUnsignedTx(chunk, generator.TxOptions{})
+		if err != nil {
+			t.Fatalf("BuildUnsignedTx() error = %v", err)
+		}
+		data, err := generator.MarshalTxJSON(unsigned)
+		if err != nil {
+			t.Fatalf("MarshalTxJSON() error = %v", err)
+		}
+		transactions = append(transactions, Entry{Name: generator.BatchFileName("unsigned-tx.json", i+1), Kind: KindTransaction, Data: data})
+	}
+
+	report, err := VerifyContents(verifier.NewVerifier(), routesData, transactions)
+	if err != nil {
+		t.Fatalf("VerifyContents() error = %v", err)
+	}
+	if !report.Result.Valid || report.Result.MatchedCount != 2 {
+		t.Errorf("result = %+v, want both routes matched across batches", report.Result)
+	}
+	if len(report.Transactions) != 2 || report.Transactions[1] != "unsigned-tx-2.json" {
+		t.Errorf("Transactions = %v", report.Transactions)
+	}
+
+	// Leaving out a batch leaves a route unmatched
+	report, err = VerifyContents(verifier.NewVerifier(), routesData, transactions[:1])
+	if err != nil {
+		t.Fatalf("VerifyContents() error = %v", err)
+	}
+	if report.Result.Valid {
+		t.Error("result is valid with a batch missing, want invalid")
+	}
+
+	if _, err := VerifyContents(verifier.NewVerifier(), routesData, []Entry{{Name: "bad.json", Data: []byte("{}")}}); err == nil {
+		t.Error("VerifyContents() with an undecodable transaction succeeded, want error")
+	}
+}
@@ -0,0 +1,199 @@
+// Package changefeed keeps an append-only file of route lifecycle transitions, one JSON line per
+// transition with an increasing sequence number, so external systems can follow the rebalancer by
+// tailing a file instead of subscribing to a message broker.
+package changefeed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Statuses are the route statuses the feed follows
+var Statuses = []storage.RouteStatus{
+	storage.RouteParsed,
+	storage.RouteAccumulating,
+	storage.RouteGenerated,
+	storage.RouteDispatched,
+	storage.RouteDelivered,
+	storage.RouteFailed,
+}
+
+// Entry is one transition in the changefeed
+type Entry struct {
+	Seq       uint64               `json:"seq"`
+	Timestamp time.Time            `json:"timestamp"`
+	TxHash    string               `json:"tx_hash"`
+	Status    storage.RouteStatus  `json:"status"`
+	Previous  storage.RouteStatus  `json:"previous,omitempty"` // Status the feed last reported, empty for a new route
+	UpdatedAt time.Time            `json:"updated_at"`         // When the store recorded the status
+	Route     types.HyperlaneRoute `json:"route"`
+}
+
+// Feed appends the transitions of the routes in a store to a JSON lines file. The sequence numbers
+// and the last status reported for every route are restored from the file, so a restarted feed
+// continues where it stopped. Only one process may append to a feed.
+type Feed struct {
+	path     string
+	seq      uint64
+	statuses map[string]storage.RouteStatus
+	now      func() time.Time
+}
+
+// Open opens the changefeed at path, creating it if it does not exist. A last line cut short by a
+// crash is removed.
+func Open(path string) (*Feed, error) {
+	f := &Feed{path: path, statuses: make(map[string]storage.RouteStatus), now: time.Now}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changefeed: %w", err)
+	}
+	if complete := bytes.LastIndexByte(data, '\n') + 1; complete < len(data) {
+		if err := os.Truncate(path, int64(complete)); err != nil {
+			return nil, fmt.Errorf("failed to remove incomplete changefeed entry: %w", err)
+		}
+		data = data[:complete]
+	}
+
+	entries, err := decode(bytes.NewReader(data), 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		f.seq = entry.Seq
+		f.statuses[entry.Route.TransferKey()] = entry.Status
+	}
+	return f, nil
+}
+
+// Seq returns the sequence number of the last entry, or 0 if the feed is empty
+func (f *Feed) Seq() uint64 {
+	return f.seq
+}
+
+// Sync appends an entry for every route in store whose status differs from the one the feed last
+// reported, in the order the store recorded them, and returns the entries appended. A route that
+// changed status more than once since the last sync is reported with its latest status only.
+func (f *Feed) Sync(ctx context.Context, store storage.Storage) ([]Entry, error) {
+	var changed []storage.RouteRecord
+	for _, status := range Statuses {
+		records, err := store.RoutesByStatus(ctx, status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s routes: %w", status, err)
+		}
+		for _, record := range records {
+			if f.statuses[record.Route.TransferKey()] != record.Status {
+				changed = append(changed, record)
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(changed, func(i, j int) bool {
+		return changed[i].UpdatedAt.Before(changed[j].UpdatedAt)
+	})
+
+	now := f.now().UTC()
+	var buf bytes.Buffer
+	entries := make([]Entry, 0, len(changed))
+	for i, record := range changed {
+		entry := Entry{
+			Seq:       f.seq + uint64(i) + 1,
+			Timestamp: now,
+			TxHash:    record.Route.TxHash,
+			Status:    record.Status,
+			Previous:  f.statuses[record.Route.TransferKey()],
+			UpdatedAt: record.UpdatedAt,
+			Route:     record.Route,
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal changefeed entry: %w", err)
+		}
+		buf.Write(append(data, '\n'))
+		entries = append(entries, entry)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open changefeed: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to write changefeed: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync changefeed: %w", err)
+	}
+
+	for _, entry := range entries {
+		f.seq = entry.Seq
+		f.statuses[entry.Route.TransferKey()] = entry.Status
+	}
+	return entries, nil
+}
+
+// Run syncs the feed with store every interval until ctx is cancelled. Failed syncs are reported to
+// onError and retried at the next interval.
+func (f *Feed) Run(ctx context.Context, store storage.Storage, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := f.Sync(ctx, store); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Read returns the entries of the changefeed at path with a sequence number above after, so a
+// consumer can resume from the last entry it handled
+func Read(path string, after uint64) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open changefeed: %w", err)
+	}
+	defer file.Close()
+	return decode(file, after)
+}
+
+// decode reads the entries with a sequence number above after from r
+func decode(r io.Reader, after uint64) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse changefeed entry: %w", err)
+		}
+		if entry.Seq > after {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read changefeed: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,82 @@
+package changefeed
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "changefeed.jsonl")
+	store := storage.NewMemory()
+
+	feed, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SaveRoute(ctx, types.HyperlaneRoute{TxHash: "A"}, storage.RouteParsed)
+	store.SaveRoute(ctx, types.HyperlaneRoute{TxHash: "B"}, storage.RouteParsed)
+	entries, err := feed.Sync(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Seq != 1 || entries[1].Seq != 2 || entries[0].Previous != "" {
+		t.Fatalf("first sync = %+v, want two new parsed routes numbered 1 and 2", entries)
+	}
+
+	// Unchanged routes are not reported again
+	if entries, err := feed.Sync(ctx, store); err != nil || len(entries) != 0 {
+		t.Fatalf("second sync = %+v, %v, want nothing", entries, err)
+	}
+
+	// A reopened feed continues the numbering and knows the reported statuses
+	store.SaveRoute(ctx, types.HyperlaneRoute{TxHash: "A"}, storage.RouteGenerated)
+	feed, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err = feed.Sync(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Seq != 3 || entries[0].TxHash != "A" ||
+		entries[0].Status != storage.RouteGenerated || entries[0].Previous != storage.RouteParsed {
+		t.Fatalf("sync after reopening = %+v, want A moved from parsed to generated as 3", entries)
+	}
+
+	all, err := Read(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Read() returned %d entries, want 3", len(all))
+	}
+	if after, err := Read(path, 2); err != nil || len(after) != 1 || after[0].Seq != 3 {
+		t.Errorf("Read(after 2) = %+v, %v, want entry 3", after, err)
+	}
+}
+
+func TestOpenDropsIncompleteEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changefeed.jsonl")
+	data := `{"seq":1,"tx_hash":"A","status":"parsed"}` + "\n" + `{"seq":2,"tx_ha`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Seq() != 1 {
+		t.Errorf("Seq() = %d, want 1", feed.Seq())
+	}
+	entries, err := Read(path, 0)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Read() = %+v, %v, want the complete entry only", entries, err)
+	}
+}
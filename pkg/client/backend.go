@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// grpcScheme optionally prefixes gRPC endpoint URLs, to tell them apart from CometBFT RPC URLs
+const grpcScheme = "grpc://"
+
+// Backend is the chain access the parser needs. It is implemented by Client over gRPC and by
+// CometClient over the CometBFT HTTP RPC, for nodes that only expose one of them.
+type Backend interface {
+	// GetTransactionsAtHeight queries the transactions included in a single block
+	GetTransactionsAtHeight(height int64) ([]*Transaction, error)
+	// GetTransactions queries and decodes many included transactions by hash, in the order of hashes
+	GetTransactions(hashes []string) ([]*Transaction, error)
+	// LatestHeight queries the height of the latest block committed by the node
+	LatestHeight() (int64, error)
+	// BlockHeader returns the header of the committed block at height
+	BlockHeader(height int64) (*types.BlockHeader, error)
+	// GetWarpTokens queries all warp tokens registered on the chain
+	GetWarpTokens() ([]warptypes.WrappedHypToken, error)
+
+	SetQueryConfig(query types.QueryConfig)
+	SetCallTimeout(timeout time.Duration)
+	SetCache(cache *ResponseCache)
+	SetHeaderStore(store storage.HeaderStorage)
+	Close() error
+}
+
+var (
+	_ Backend = (*Client)(nil)
+	_ Backend = (*CometClient)(nil)
+)
+
+// Dial connects to the chain at rpcEndpoint through the backend its scheme selects: the CometBFT
+// HTTP RPC for http:// and https:// URLs, and gRPC for grpc:// URLs and URLs without a scheme
+func Dial(ctx context.Context, rpcEndpoint string, rpc types.RPCConfig) (Backend, error) {
+	if IsCometURL(rpcEndpoint) {
+		c, err := NewCometClient(ctx, rpcEndpoint, rpc)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	c, err := NewClientWithConfig(ctx, rpcEndpoint, rpc)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// IsCometURL reports whether url is the address of a CometBFT RPC endpoint rather than gRPC
+func IsCometURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// grpcTarget returns the gRPC dial target of url, without the optional grpc:// scheme
+func grpcTarget(url string) string {
+	return strings.TrimPrefix(url, grpcScheme)
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+)
+
+// maxCachedHeaders bounds the in-memory header cache of long-running clients
+const maxCachedHeaders = 10000
+
+// LatestHeight queries the height of the latest block committed by the node
+func (c *Client) LatestHeight() (int64, error) {
+	resp, err := c.cmtClient.GetLatestBlock(c.ctx, &cmtservice.GetLatestBlockRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query latest block: %w", err)
+	}
+	if resp.SdkBlock != nil {
+		return resp.SdkBlock.Header.Height, nil
+	}
+	// Nodes before v0.47 only fill the deprecated CometBFT block
+	if resp.Block != nil {
+		return resp.Block.Header.Height, nil
+	}
+	return 0, fmt.Errorf("latest block response has no block")
+}
+
+// SetHeaderStore keeps the block headers the client fetches in store, so they are not queried
+// again by later runs
+func (c *Client) SetHeaderStore(store storage.HeaderStorage) {
+	c.headers.store = store
+}
+
+// BlockHeader returns the header of the committed block at height. Headers are read through an
+// in-memory cache and the header store, if one is set, so each height is queried once.
+func (c *Client) BlockHeader(height int64) (*types.BlockHeader, error) {
+	return c.headers.get(c.ctx, height, func() (types.BlockHeader, error) {
+		resp, err := c.cmtClient.GetBlockByHeight(c.ctx, &cmtservice.GetBlockByHeightRequest{Height: height})
+		if err != nil {
+			return types.BlockHeader{}, fmt.Errorf("failed to query block at height %d: %w", height, err)
+		}
+		header := types.BlockHeader{Height: height}
+		switch {
+		case resp.SdkBlock != nil:
+			header.Time = resp.SdkBlock.Header.Time.UTC()
+		case resp.Block != nil:
+			header.Time = resp.Block.Header.Time.UTC()
+		default:
+			return types.BlockHeader{}, fmt.Errorf("block response at height %d has no block", height)
+		}
+		if resp.BlockId != nil {
+			header.Hash = strings.ToUpper(hex.EncodeToString(resp.BlockId.Hash))
+		}
+		return header, nil
+	})
+}
+
+// headerCache keeps the block headers a client fetched in memory and in an optional header store
+type headerCache struct {
+	store   storage.HeaderStorage
+	mu      sync.Mutex
+	headers map[int64]types.BlockHeader
+}
+
+// get returns the header at height from the cache or the store, and otherwise fetches and keeps it
+func (h *headerCache) get(ctx context.Context, height int64, fetch func() (types.BlockHeader, error)) (*types.BlockHeader, error) {
+	h.mu.Lock()
+	header, ok := h.headers[height]
+	h.mu.Unlock()
+	if ok {
+		return &header, nil
+	}
+
+	if h.store != nil {
+		stored, err := h.store.Header(ctx, height)
+		if err == nil {
+			h.add(*stored)
+			return stored, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	header, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	if h.store != nil {
+		if err := h.store.SaveHeader(ctx, header); err != nil {
+			return nil, err
+		}
+	}
+	h.add(header)
+	return &header, nil
+}
+
+// add adds header to the in-memory cache, starting over once it is full
+func (h *headerCache) add(header types.BlockHeader) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.headers == nil || len(h.headers) >= maxCachedHeaders {
+		h.headers = make(map[int64]types.BlockHeader)
+	}
+	h.headers[header.Height] = header
+}
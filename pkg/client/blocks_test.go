@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	"google.golang.org/grpc"
+)
+
+// fakeCmtService serves GetLatestBlock with a fixed response and GetBlockByHeight with blocks one
+// minute apart, counting the block queries
+type fakeCmtService struct {
+	cmtservice.ServiceClient
+	resp    *cmtservice.GetLatestBlockResponse
+	queries int
+}
+
+var genesis = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func (f *fakeCmtService) GetBlockByHeight(ctx context.Context, req *cmtservice.GetBlockByHeightRequest, opts ...grpc.CallOption) (*cmtservice.GetBlockByHeightResponse, error) {
+	f.queries++
+	return &cmtservice.GetBlockByHeightResponse{
+		BlockId:  &cmtproto.BlockID{Hash: []byte{0xab, byte(req.Height)}},
+		SdkBlock: &cmtservice.Block{Header: cmtservice.Header{Height: req.Height, Time: genesis.Add(time.Duration(req.Height) * time.Minute)}},
+	}, nil
+}
+
+func (f *fakeCmtService) GetLatestBlock(ctx context.Context, req *cmtservice.GetLatestBlockRequest, opts ...grpc.CallOption) (*cmtservice.GetLatestBlockResponse, error) {
+	return f.resp, nil
+}
+
+func TestLatestHeight(t *testing.T) {
+	service := &fakeCmtService{resp: &cmtservice.GetLatestBlockResponse{
+		SdkBlock: &cmtservice.Block{Header: cmtservice.Header{Height: 2500042}},
+	}}
+	c := &Client{cmtClient: service, ctx: context.Background()}
+
+	height, err := c.LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 2500042 {
+		t.Errorf("expected height 2500042, got %d", height)
+	}
+
+	service.resp = &cmtservice.GetLatestBlockResponse{}
+	if _, err := c.LatestHeight(); err == nil {
+		t.Error("expected an error for a response without a block")
+	}
+}
+
+func TestBlockHeaderCache(t *testing.T) {
+	service := &fakeCmtService{}
+	store := storage.NewMemory()
+	c := &Client{cmtClient: service, ctx: context.Background()}
+	c.SetHeaderStore(store)
+
+	for i := 0; i < 3; i++ {
+		header, err := c.BlockHeader(5)
+		if err != nil {
+			t.Fatalf("BlockHeader() error = %v", err)
+		}
+		if header.Hash != "AB05" || !header.Time.Equal(genesis.Add(5*time.Minute)) {
+			t.Errorf("BlockHeader() = %+v", header)
+		}
+	}
+	if service.queries != 1 {
+		t.Errorf("queried %d blocks for one height, want 1", service.queries)
+	}
+
+	// A new client reads the header back from the store
+	fresh := &Client{cmtClient: service, ctx: context.Background()}
+	fresh.SetHeaderStore(store)
+	if _, err := fresh.BlockHeader(5); err != nil {
+		t.Fatalf("BlockHeader() error = %v", err)
+	}
+	if _, err := fresh.BlockHeader(6); err != nil {
+		t.Fatalf("BlockHeader() error = %v", err)
+	}
+	if service.queries != 2 {
+		t.Errorf("queried %d blocks, want only the uncached height", service.queries)
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/metrics"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// BroadcastTx submits a signed transaction and returns once the node has checked it. A transaction
+// the node rejects in CheckTx is returned with an error describing the rejection.
+func (c *Client) BroadcastTx(txBytes []byte) (*sdk.TxResponse, error) {
+	start := time.Now()
+	resp, err := c.txClient.BroadcastTx(c.ctx, &tx.BroadcastTxRequest{
+		TxBytes: txBytes,
+		Mode:    tx.BroadcastMode_BROADCAST_MODE_SYNC,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+	if resp.TxResponse == nil {
+		return nil, fmt.Errorf("broadcast returned no response")
+	}
+	metrics.BroadcastDuration.WithLabelValues("checktx").Observe(time.Since(start).Seconds())
+	if resp.TxResponse.Code != 0 {
+		return resp.TxResponse, fmt.Errorf("tx %s rejected with code %s/%d: %s",
+			resp.TxResponse.TxHash, resp.TxResponse.Codespace, resp.TxResponse.Code, resp.TxResponse.RawLog)
+	}
+	return resp.TxResponse, nil
+}
+
+// WaitForTx polls for a broadcast transaction every interval until it is included in a block or
+// timeout passes. The included transaction may still have failed; check it with TxFailed.
+func (c *Client) WaitForTx(hash string, timeout, interval time.Duration) (*sdk.TxResponse, error) {
+	start := time.Now()
+	deadline := start.Add(timeout)
+	for {
+		resp, err := c.GetTx(hash)
+		if err == nil {
+			metrics.BroadcastDuration.WithLabelValues("inclusion").Observe(time.Since(start).Seconds())
+			return resp, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return nil, fmt.Errorf("tx %s was not included within %s: %w", hash, timeout, err)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
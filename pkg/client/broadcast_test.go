@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/grpc"
+)
+
+// fakeBroadcastService accepts broadcasts with a fixed CheckTx code and includes them after a
+// number of GetTx queries
+type fakeBroadcastService struct {
+	tx.ServiceClient
+	code         uint32
+	includeAfter int
+	queries      int
+}
+
+func (f *fakeBroadcastService) BroadcastTx(ctx context.Context, req *tx.BroadcastTxRequest, opts ...grpc.CallOption) (*tx.BroadcastTxResponse, error) {
+	if req.Mode != tx.BroadcastMode_BROADCAST_MODE_SYNC {
+		return nil, fmt.Errorf("unexpected broadcast mode %s", req.Mode)
+	}
+	return &tx.BroadcastTxResponse{TxResponse: &sdk.TxResponse{TxHash: "ABC", Code: f.code, Codespace: "sdk", RawLog: "out of gas"}}, nil
+}
+
+func (f *fakeBroadcastService) GetTx(ctx context.Context, req *tx.GetTxRequest, opts ...grpc.CallOption) (*tx.GetTxResponse, error) {
+	f.queries++
+	if f.queries <= f.includeAfter {
+		return nil, fmt.Errorf("tx not found")
+	}
+	return &tx.GetTxResponse{TxResponse: &sdk.TxResponse{TxHash: req.Hash, Height: 2500042}}, nil
+}
+
+func TestBroadcastAndWait(t *testing.T) {
+	service := &fakeBroadcastService{includeAfter: 2}
+	c := &Client{txClient: service, ctx: context.Background()}
+
+	resp, err := c.BroadcastTx([]byte("signed"))
+	if err != nil {
+		t.Fatalf("BroadcastTx() error = %v", err)
+	}
+
+	included, err := c.WaitForTx(resp.TxHash, time.Second, time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForTx() error = %v", err)
+	}
+	if included.Height != 2500042 || service.queries != 3 {
+		t.Errorf("included at height %d after %d queries, want 2500042 after 3", included.Height, service.queries)
+	}
+
+	service.queries = 0
+	service.includeAfter = 1000
+	if _, err := c.WaitForTx("ABC", 10*time.Millisecond, time.Millisecond); err == nil {
+		t.Error("WaitForTx() did not time out")
+	}
+}
+
+func TestBroadcastRejected(t *testing.T) {
+	c := &Client{txClient: &fakeBroadcastService{code: 11}, ctx: context.Background()}
+
+	resp, err := c.BroadcastTx([]byte("signed"))
+	if err == nil {
+		t.Fatal("BroadcastTx() accepted a transaction rejected in CheckTx")
+	}
+	if resp == nil || resp.Code != 11 {
+		t.Errorf("expected the rejected response to be returned, got %v", resp)
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// ResponseCache stores GetTxsEvent responses on disk, keyed by height and query, so repeated
+// scans over an overlapping range do not re-download blocks from the node
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewResponseCache creates a cache in dir. Entries older than ttl are ignored and refetched;
+// a ttl of 0 keeps entries forever.
+func NewResponseCache(dir string, ttl time.Duration) (*ResponseCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &ResponseCache{dir: dir, ttl: ttl}, nil
+}
+
+// Get returns the cached response for the request, or nil if there is no fresh entry
+func (c *ResponseCache) Get(height int64, req *tx.GetTxsEventRequest) *tx.GetTxsEventResponse {
+	path := c.path(height, req)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if c.ttl > 0 && time.Since(info.ModTime()) > c.ttl {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	// A corrupt entry is treated as a miss and overwritten by the next Put
+	var resp tx.GetTxsEventResponse
+	if err := resp.Unmarshal(data); err != nil {
+		return nil
+	}
+	return &resp
+}
+
+// Put stores a response for the request
+func (c *ResponseCache) Put(height int64, req *tx.GetTxsEventRequest, resp *tx.GetTxsEventResponse) error {
+	data, err := resp.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	// Write to a temporary file first so a concurrent reader never sees a partial entry
+	path := c.path(height, req)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// path returns the cache file for a request: the height keeps entries easy to inspect and prune,
+// the hash covers the query and paging parameters
+func (c *ResponseCache) path(height int64, req *tx.GetTxsEventRequest) string {
+	key := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%d", req.Query, req.Page, req.Limit, req.OrderBy)))
+	return filepath.Join(c.dir, fmt.Sprintf("%d-%s.pb", height, hex.EncodeToString(key[:8])))
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache, err := NewResponseCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewResponseCache() error = %v", err)
+	}
+
+	req := &tx.GetTxsEventRequest{Query: "tx.height=100", Page: 1, Limit: 100}
+	resp := &tx.GetTxsEventResponse{
+		TxResponses: []*sdk.TxResponse{{TxHash: "ABC123", Height: 100}},
+		Total:       1,
+	}
+
+	if cache.Get(100, req) != nil {
+		t.Fatal("Get() returned an entry from an empty cache")
+	}
+	if err := cache.Put(100, req, resp); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got := cache.Get(100, req)
+	if got == nil || len(got.TxResponses) != 1 || got.TxResponses[0].TxHash != "ABC123" {
+		t.Errorf("Get() = %+v, want the stored response", got)
+	}
+
+	// A different page is a different entry
+	if cache.Get(100, &tx.GetTxsEventRequest{Query: "tx.height=100", Page: 2, Limit: 100}) != nil {
+		t.Error("Get() returned an entry for a different page")
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewResponseCache(dir, time.Minute)
+	if err != nil {
+		t.Fatalf("NewResponseCache() error = %v", err)
+	}
+
+	req := &tx.GetTxsEventRequest{Query: "tx.height=100", Page: 1, Limit: 100}
+	if err := cache.Put(100, req, &tx.GetTxsEventResponse{Total: 1}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(cache.path(100, req), old, old); err != nil {
+		t.Fatalf("failed to age cache entry: %v", err)
+	}
+
+	if cache.Get(100, req) != nil {
+		t.Error("Get() returned an expired entry")
+	}
+}
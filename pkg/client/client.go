@@ -2,45 +2,163 @@ package client
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
+	pdtypes "github.com/bcp-innovations/hyperlane-cosmos/x/core/02_post_dispatch/types"
 	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	abci "github.com/cometbft/cometbft/abci/types"
 	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	"github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // Client is a gRPC client for querying Celestia blockchain data
 type Client struct {
-	conn      *grpc.ClientConn
-	txClient  tx.ServiceClient
-	ctx       context.Context
-	encConfig client.TxConfig
+	pool       *endpointPool
+	txClient   tx.ServiceClient
+	authClient authtypes.QueryClient
+	bankClient banktypes.QueryClient
+	cmtClient  cmtservice.ServiceClient
+	warpClient warptypes.QueryClient
+	pdClient   pdtypes.QueryClient // Post-dispatch module, quoting interchain gas payments
+	ctx        context.Context
+	encConfig  client.TxConfig
+	query      types.QueryConfig
+	cache      *ResponseCache // Optional on-disk cache of transaction queries
+
+	callTimeout time.Duration // Bound on each gRPC call, zero for none
+
+	headers headerCache // Block headers fetched so far, with an optional persistent store
 }
 
-// NewClient creates a new gRPC client connected to the given RPC endpoint
+// NewClient creates a new gRPC client connected to the given RPC endpoint over plaintext
 func NewClient(ctx context.Context, rpcEndpoint string) (*Client, error) {
-	conn, err := grpc.NewClient(rpcEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	return NewClientWithConfig(ctx, rpcEndpoint, types.RPCConfig{})
+}
+
+// NewClientWithConfig creates a new gRPC client connected to the given RPC endpoint with the
+// TLS, credentials, call timeout, fallback endpoints and retries of rpc
+func NewClientWithConfig(ctx context.Context, rpcEndpoint string, rpc types.RPCConfig) (*Client, error) {
+	if err := rpc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rpc config: %w", err)
+	}
+	rpc = rpc.WithDefaults()
+	timeout, _ := rpc.Timeout()
+	initial, maxBackoff, _ := rpc.Backoff()
+	c := &Client{
+		ctx:         ctx,
+		query:       types.DefaultQueryConfig(),
+		callTimeout: timeout,
+	}
+	opts, err := dialOptions(rpc)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to gRPC endpoint: %w", err)
+		return nil, err
+	}
+	opts = append(opts, grpc.WithUnaryInterceptor(c.boundCall))
+
+	pool := &endpointPool{
+		maxAttempts:    rpc.MaxAttempts,
+		initialBackoff: initial,
+		maxBackoff:     maxBackoff,
+	}
+	for _, url := range append([]string{rpcEndpoint}, rpc.FallbackURLs...) {
+		conn, err := grpc.NewClient(grpcTarget(url), opts...)
+		if err != nil {
+			pool.close()
+			return nil, fmt.Errorf("failed to connect to gRPC endpoint %s: %w", url, err)
+		}
+		pool.endpoints = append(pool.endpoints, endpoint{url: url, conn: conn})
+	}
+
+	c.pool = pool
+	c.txClient = tx.NewServiceClient(pool)
+	c.authClient = authtypes.NewQueryClient(pool)
+	c.bankClient = banktypes.NewQueryClient(pool)
+	c.cmtClient = cmtservice.NewServiceClient(pool)
+	c.warpClient = warptypes.NewQueryClient(pool)
+	c.pdClient = pdtypes.NewQueryClient(pool)
+	return c, nil
+}
+
+// SetCallTimeout bounds every gRPC call to the node, so a wedged endpoint fails the call instead of
+// hanging. Zero, the default, leaves calls bounded only by the client's context.
+func (c *Client) SetCallTimeout(timeout time.Duration) {
+	c.callTimeout = timeout
+}
+
+// boundCall is a unary interceptor applying the call timeout to each call
+func (c *Client) boundCall(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if c.callTimeout <= 0 {
+		return invoker(ctx, method, req, reply, cc, opts...)
 	}
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
 
-	return &Client{
-		conn:     conn,
-		txClient: tx.NewServiceClient(conn),
-		ctx:      ctx,
-	}, nil
+// SetCache enables caching of per-height transaction queries
+func (c *Client) SetCache(cache *ResponseCache) {
+	c.cache = cache
 }
 
-// Close closes the gRPC connection
+// SetQueryConfig sets the page size and page limit used for transaction queries
+func (c *Client) SetQueryConfig(query types.QueryConfig) {
+	c.query = query.WithDefaults()
+}
+
+// SetFailoverHandler sets a function called whenever the client fails over from one endpoint to
+// another, with the error that caused it
+func (c *Client) SetFailoverHandler(fn func(from, to string, err error)) {
+	c.pool.mu.Lock()
+	defer c.pool.mu.Unlock()
+	c.pool.onFailover = fn
+}
+
+// Endpoint returns the endpoint the client currently sends its calls to
+func (c *Client) Endpoint() string {
+	index, _ := c.pool.conn()
+	return c.pool.endpoints[index].url
+}
+
+// Close closes the gRPC connections
 func (c *Client) Close() error {
-	return c.conn.Close()
+	return c.pool.close()
+}
+
+// GetBalances queries all bank balances of an account
+func (c *Client) GetBalances(address string) (sdk.Coins, error) {
+	var balances sdk.Coins
+	var nextKey []byte
+
+	for {
+		resp, err := c.bankClient.AllBalances(c.ctx, &banktypes.QueryAllBalancesRequest{
+			Address:    address,
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query balances of %s: %w", address, err)
+		}
+
+		balances = balances.Add(resp.Balances...)
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+
+	return balances, nil
 }
 
 // Transaction represents a blockchain transaction with extracted data
@@ -48,56 +166,162 @@ type Transaction struct {
 	Hash        string
 	BlockHeight int64
 	Memo        string
-	Tx          *tx.Tx // Store the full decoded transaction
+	Tx          *tx.Tx       // Store the full decoded transaction
+	Code        uint32       // Result code; non-zero if the transaction failed
+	Events      []abci.Event // Events emitted by the transaction, e.g. IBC packet acknowledgements
+	DecodeError *DecodeError // Set when the transaction could not be decoded, in which case Tx is nil
+}
+
+// DecodeError describes a transaction or message that could not be decoded
+type DecodeError struct {
+	TxHash  string `json:"tx_hash"`
+	Height  int64  `json:"height"`
+	TypeURL string `json:"type_url"`
+	Err     string `json:"error"`
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("tx %s at height %d: failed to decode %s: %s", e.TxHash, e.Height, e.TypeURL, e.Err)
+}
+
+// DecodeErrors is returned by the extraction functions when some messages could not be decoded.
+// The messages that did decode are still returned alongside it.
+type DecodeErrors []DecodeError
+
+func (e DecodeErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%d messages failed to decode, first: %s", len(e), e[0].Error())
 }
 
-// GetTransactionsByHeight queries transactions within a height range
+// GetTransactionsByHeight queries transactions within a height range, failing on the first height that cannot be queried
 func (c *Client) GetTransactionsByHeight(fromHeight, toHeight int64) ([]*Transaction, error) {
 	var allTxs []*Transaction
 
 	// Query block by block
 	for height := fromHeight; height <= toHeight; height++ {
-		// Query transactions at this height using block search
-		query := fmt.Sprintf("tx.height=%d", height)
+		txs, err := c.GetTransactionsAtHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		allTxs = append(allTxs, txs...)
+	}
+
+	return allTxs, nil
+}
+
+// GetTransactionsAtHeight queries the transactions included in a single block, paging through
+// the results up to the configured maximum number of pages
+func (c *Client) GetTransactionsAtHeight(height int64) ([]*Transaction, error) {
+	var responses []*sdk.TxResponse
+
+	// Query transactions at this height using block search
+	query := fmt.Sprintf("tx.height=%d", height)
+	for page := uint64(1); ; page++ {
+		if page > uint64(c.query.MaxPagesPerHeight) {
+			return nil, fmt.Errorf("height %d has more than %d pages of %d transactions", height, c.query.MaxPagesPerHeight, c.query.PageSize)
+		}
+
 		req := &tx.GetTxsEventRequest{
 			Query:   query,
 			OrderBy: tx.OrderBy_ORDER_BY_ASC,
-			Page:    1,
-			Limit:   100, // Max transactions per block
+			Page:    page,
+			Limit:   c.query.PageSize,
 		}
 
-		resp, err := c.txClient.GetTxsEvent(c.ctx, req)
+		resp, err := c.getTxsEvent(height, req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query transactions at height %d: %w", height, err)
 		}
+		responses = append(responses, resp.TxResponses...)
 
-		for _, txResp := range resp.TxResponses {
-			// Decode the transaction to get the body
-			if txResp.Tx == nil {
-				continue
-			}
+		if !morePages(resp, uint64(len(responses)), c.query.PageSize) {
+			break
+		}
+	}
 
-			// Unmarshal the Any type to Tx
-			var decodedTx tx.Tx
-			if err := decodedTx.Unmarshal(txResp.Tx.Value); err != nil {
-				continue
-			}
+	var txs []*Transaction
+	for _, txResp := range responses {
+		if txn := decodeTransaction(txResp, height); txn != nil {
+			txs = append(txs, txn)
+		}
+	}
 
-			memo := ""
-			if decodedTx.Body != nil {
-				memo = decodedTx.Body.Memo
-			}
+	return txs, nil
+}
 
-			allTxs = append(allTxs, &Transaction{
-				Hash:        txResp.TxHash,
-				BlockHeight: height,
-				Memo:        memo,
-				Tx:          &decodedTx,
-			})
+// morePages reports whether another page follows resp, after fetched transactions. Nodes cap the
+// page size (CometBFT at 100), so a short page only ends the results when the total is unknown.
+func morePages(resp *tx.GetTxsEventResponse, fetched, pageSize uint64) bool {
+	switch {
+	case len(resp.TxResponses) == 0:
+		return false
+	case resp.Total > 0:
+		return fetched < resp.Total
+	default:
+		return uint64(len(resp.TxResponses)) >= pageSize
+	}
+}
+
+// decodeTransaction decodes a transaction response included at height. Undecodable transactions
+// are kept with their decode error so they can be reported; responses without a transaction are nil.
+func decodeTransaction(txResp *sdk.TxResponse, height int64) *Transaction {
+	if txResp.Tx == nil {
+		return nil
+	}
+
+	// Unmarshal the Any type to Tx
+	var decodedTx tx.Tx
+	if err := decodedTx.Unmarshal(txResp.Tx.Value); err != nil {
+		return &Transaction{
+			Hash:        txResp.TxHash,
+			BlockHeight: height,
+			DecodeError: &DecodeError{
+				TxHash:  txResp.TxHash,
+				Height:  height,
+				TypeURL: txResp.Tx.TypeUrl,
+				Err:     err.Error(),
+			},
 		}
 	}
 
-	return allTxs, nil
+	memo := ""
+	if decodedTx.Body != nil {
+		memo = decodedTx.Body.Memo
+	}
+
+	return &Transaction{
+		Hash:        txResp.TxHash,
+		BlockHeight: height,
+		Memo:        memo,
+		Tx:          &decodedTx,
+		Code:        txResp.Code,
+		Events:      txResp.Events,
+	}
+}
+
+// getTxsEvent queries a page of transactions, serving it from the response cache when one is set
+func (c *Client) getTxsEvent(height int64, req *tx.GetTxsEventRequest) (*tx.GetTxsEventResponse, error) {
+	if c.cache != nil {
+		if resp := c.cache.Get(height, req); resp != nil {
+			return resp, nil
+		}
+	}
+
+	resp, err := c.txClient.GetTxsEvent(c.ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty responses are not cached: the height may not have been produced yet
+	if c.cache != nil && len(resp.TxResponses) > 0 {
+		if err := c.cache.Put(height, req, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
 }
 
 // BankSend represents a bank send message with parsed data
@@ -107,9 +331,11 @@ type BankSend struct {
 	Amount sdk.Coins
 }
 
-// ExtractBankSends extracts all bank send messages from a transaction
+// ExtractBankSends extracts all bank send messages from a transaction.
+// Messages that fail to decode are reported in a DecodeErrors error.
 func ExtractBankSends(txn *Transaction) ([]BankSend, error) {
 	var sends []BankSend
+	var decodeErrs DecodeErrors
 
 	if txn.Tx == nil || txn.Tx.Body == nil {
 		return sends, nil
@@ -120,6 +346,7 @@ func ExtractBankSends(txn *Transaction) ([]BankSend, error) {
 		if anyMsg.TypeUrl == "/cosmos.bank.v1beta1.MsgSend" {
 			var sendMsg banktypes.MsgSend
 			if err := sendMsg.Unmarshal(anyMsg.Value); err != nil {
+				decodeErrs = append(decodeErrs, txn.decodeError(anyMsg.TypeUrl, err))
 				continue
 			}
 
@@ -131,18 +358,29 @@ func ExtractBankSends(txn *Transaction) ([]BankSend, error) {
 		}
 	}
 
+	if len(decodeErrs) > 0 {
+		return sends, decodeErrs
+	}
 	return sends, nil
 }
 
+// decodeError builds a DecodeError for a message of this transaction
+func (txn *Transaction) decodeError(typeURL string, err error) DecodeError {
+	return DecodeError{
+		TxHash:  txn.Hash,
+		Height:  txn.BlockHeight,
+		TypeURL: typeURL,
+		Err:     err.Error(),
+	}
+}
+
 // FilterTransactionsToAddress filters transactions that have bank sends to a specific address
 func FilterTransactionsToAddress(txs []*Transaction, targetAddress string) ([]*Transaction, error) {
 	var filtered []*Transaction
 
 	for _, tx := range txs {
-		sends, err := ExtractBankSends(tx)
-		if err != nil {
-			continue
-		}
+		// Decode errors are reported by the caller; filter on the messages that did decode
+		sends, _ := ExtractBankSends(tx)
 
 		for _, send := range sends {
 			if send.To == targetAddress {
@@ -163,6 +401,9 @@ type HyperlaneTransfer struct {
 	DestinationDomain  uint32
 	TokenID            string // Token ID as hex string
 	CustomHookMetadata string // Routing information for multi-hop forwarding
+	Denom              string // Denom of bank sends and IBC transfers; empty for MsgRemoteTransfer, see the token
+	MaxFee             string // Interchain gas limit of outgoing transfers as a coin; empty if unset
+	MsgIndex           int    // Message of the transaction carrying the transfer
 }
 
 // RoutingMetadata represents routing information in transaction memo
@@ -173,9 +414,12 @@ type RoutingMetadata struct {
 }
 
 // ExtractHyperlaneTransfers extracts all Hyperlane MsgRemoteTransfer messages from a transaction
-// It also extracts bank transfers with routing metadata in the memo field
+// It also extracts bank transfers with routing metadata in the memo field, and ICS-20 transfers
+// received through MsgRecvPacket with routing metadata in the packet memo.
+// Messages that fail to decode are reported in a DecodeErrors error.
 func ExtractHyperlaneTransfers(txn *Transaction) ([]HyperlaneTransfer, error) {
 	var transfers []HyperlaneTransfer
+	var decodeErrs DecodeErrors
 
 	if txn.Tx == nil || txn.Tx.Body == nil {
 		return transfers, nil
@@ -191,34 +435,25 @@ func ExtractHyperlaneTransfers(txn *Transaction) ([]HyperlaneTransfer, error) {
 		}
 	}
 
-	for _, anyMsg := range txn.Tx.Body.Messages {
+	for i, anyMsg := range txn.Tx.Body.Messages {
 		// Check if this is a MsgRemoteTransfer by type URL (outgoing transfer)
 		if anyMsg.TypeUrl == "/hyperlane.warp.v1.MsgRemoteTransfer" {
 			var msg warptypes.MsgRemoteTransfer
 			if err := msg.Unmarshal(anyMsg.Value); err != nil {
+				decodeErrs = append(decodeErrs, txn.decodeError(anyMsg.TypeUrl, err))
 				continue
 			}
 
-			// Convert recipient bytes to hex string
-			recipientHex := fmt.Sprintf("0x%x", msg.Recipient[:])
-
-			// Convert token ID bytes to hex string
-			tokenIDHex := fmt.Sprintf("0x%x", msg.TokenId[:])
-
-			transfers = append(transfers, HyperlaneTransfer{
-				From:               msg.Sender,
-				To:                 recipientHex,
-				Amount:             msg.Amount.String(),
-				DestinationDomain:  msg.DestinationDomain,
-				TokenID:            tokenIDHex,
-				CustomHookMetadata: msg.CustomHookMetadata,
-			})
+			transfer := remoteTransfer(&msg)
+			transfer.MsgIndex = i
+			transfers = append(transfers, transfer)
 		}
 
 		// Check for bank send messages with routing metadata in memo (incoming transfers)
 		if anyMsg.TypeUrl == "/cosmos.bank.v1beta1.MsgSend" && routingMeta != nil {
 			var sendMsg banktypes.MsgSend
 			if err := sendMsg.Unmarshal(anyMsg.Value); err != nil {
+				decodeErrs = append(decodeErrs, txn.decodeError(anyMsg.TypeUrl, err))
 				continue
 			}
 
@@ -226,59 +461,129 @@ func ExtractHyperlaneTransfers(txn *Transaction) ([]HyperlaneTransfer, error) {
 			if len(sendMsg.Amount) == 0 {
 				continue
 			}
-			amount := sendMsg.Amount[0].Amount.String()
+			coin := sendMsg.Amount[0]
 
 			// For bank sends, From is the multisig (recipient of bank send)
 			// and To is the final forwarding destination from routing metadata
 			transfers = append(transfers, HyperlaneTransfer{
 				From:              sendMsg.ToAddress, // The multisig that received the funds
 				To:                routingMeta.Recipient,
-				Amount:            amount,
+				Amount:            coin.Amount.String(),
 				DestinationDomain: routingMeta.DestinationDomain,
 				TokenID:           routingMeta.TokenID,
+				Denom:             coin.Denom,
+				MsgIndex:          i,
 			})
 		}
+
+		// Check for IBC transfers received by a relayer with routing metadata in the packet memo
+		if anyMsg.TypeUrl == MsgRecvPacketTypeURL {
+			packet, err := DecodeRecvPacket(anyMsg.Value)
+			if err != nil {
+				decodeErrs = append(decodeErrs, txn.decodeError(anyMsg.TypeUrl, err))
+				continue
+			}
+			transfer, err := txn.ibcTransfer(packet)
+			if err != nil {
+				decodeErrs = append(decodeErrs, txn.decodeError(anyMsg.TypeUrl, err))
+				continue
+			}
+			if transfer != nil {
+				transfer.MsgIndex = i
+				transfers = append(transfers, *transfer)
+			}
+		}
 	}
 
+	if len(decodeErrs) > 0 {
+		return transfers, decodeErrs
+	}
 	return transfers, nil
 }
 
-// FilterHyperlaneTransfersToAddress filters transactions that have Hyperlane transfers to a specific address
-func FilterHyperlaneTransfersToAddress(txs []*Transaction, targetAddress string) ([]*Transaction, error) {
-	var filtered []*Transaction
+// ExtractOutgoingTransfers extracts the MsgRemoteTransfer messages of a transaction, including
+// those executed on behalf of a granter through an authz MsgExec, as the outgoing side of the flow.
+// Transfers executed through a MsgExec have its message index.
+// Messages that fail to decode are reported in a DecodeErrors error.
+func ExtractOutgoingTransfers(txn *Transaction) ([]HyperlaneTransfer, error) {
+	var transfers []HyperlaneTransfer
+	var decodeErrs DecodeErrors
+
+	if txn.Tx == nil || txn.Tx.Body == nil {
+		return transfers, nil
+	}
 
-	// Convert target address from bech32 to hex for comparison
-	targetHex := ""
-	if !strings.HasPrefix(targetAddress, "0x") {
-		// It's a bech32 address, convert to hex
-		addr, err := sdk.AccAddressFromBech32(targetAddress)
-		if err == nil {
-			targetHex = "0x" + hex.EncodeToString(addr)
-			// Pad to 32 bytes (64 hex chars)
-			if len(targetHex) < 66 { // 2 for "0x" + 64 for 32 bytes
-				padding := strings.Repeat("0", 66-len(targetHex))
-				targetHex = "0x" + padding + targetHex[2:]
+	// execIndex is the index of the MsgExec executing msgs, -1 for the messages of the transaction
+	var extract func(msgs []*codectypes.Any, execIndex int)
+	extract = func(msgs []*codectypes.Any, execIndex int) {
+		for i, anyMsg := range msgs {
+			index := i
+			if execIndex >= 0 {
+				index = execIndex
+			}
+			switch anyMsg.TypeUrl {
+			case "/hyperlane.warp.v1.MsgRemoteTransfer":
+				var msg warptypes.MsgRemoteTransfer
+				if err := msg.Unmarshal(anyMsg.Value); err != nil {
+					decodeErrs = append(decodeErrs, txn.decodeError(anyMsg.TypeUrl, err))
+					continue
+				}
+				transfer := remoteTransfer(&msg)
+				transfer.MsgIndex = index
+				transfers = append(transfers, transfer)
+			case "/cosmos.authz.v1beta1.MsgExec":
+				var exec authz.MsgExec
+				if err := exec.Unmarshal(anyMsg.Value); err != nil {
+					decodeErrs = append(decodeErrs, txn.decodeError(anyMsg.TypeUrl, err))
+					continue
+				}
+				extract(exec.Msgs, index)
 			}
 		}
-	} else {
-		targetHex = targetAddress
 	}
+	extract(txn.Tx.Body.Messages, -1)
+
+	if len(decodeErrs) > 0 {
+		return transfers, decodeErrs
+	}
+	return transfers, nil
+}
+
+// remoteTransfer converts a MsgRemoteTransfer to a HyperlaneTransfer with hex-encoded addresses
+func remoteTransfer(msg *warptypes.MsgRemoteTransfer) HyperlaneTransfer {
+	maxFee := ""
+	if msg.MaxFee.Denom != "" && !msg.MaxFee.Amount.IsNil() {
+		maxFee = msg.MaxFee.String()
+	}
+	return HyperlaneTransfer{
+		From:               msg.Sender,
+		To:                 fmt.Sprintf("0x%x", msg.Recipient[:]),
+		Amount:             msg.Amount.String(),
+		DestinationDomain:  msg.DestinationDomain,
+		TokenID:            fmt.Sprintf("0x%x", msg.TokenId[:]),
+		CustomHookMetadata: msg.CustomHookMetadata,
+		MaxFee:             maxFee,
+	}
+}
+
+// FilterHyperlaneTransfersToAddress filters transactions that have Hyperlane transfers to a specific address
+func FilterHyperlaneTransfersToAddress(txs []*Transaction, targetAddress string) ([]*Transaction, error) {
+	var filtered []*Transaction
+
+	// Recipients are 32-byte hex; a bech32 target is compared in that form too
+	targetHex := types.NormalizeAddress(targetAddress)
 
 	for _, tx := range txs {
-		transfers, err := ExtractHyperlaneTransfers(tx)
-		if err != nil {
-			continue
-		}
+		// Decode errors are reported by the caller; filter on the messages that did decode
+		transfers, _ := ExtractHyperlaneTransfers(tx)
 
 		for _, transfer := range transfers {
-			// Compare the recipient address (hex format)
-			// Normalize both to lowercase for comparison
-			transferTo := strings.ToLower(transfer.To)
+			// Compare the recipient in 32-byte hex form, and the sender as written
+			transferTo := types.NormalizeAddress(transfer.To)
 			transferFrom := strings.ToLower(transfer.From)
-			target := strings.ToLower(targetHex)
 			targetBech32 := strings.ToLower(targetAddress)
 
-			if transferTo == target || transferFrom == targetBech32 || transferTo == targetBech32 {
+			if transferTo == targetHex || transferFrom == targetBech32 {
 				filtered = append(filtered, tx)
 				break
 			}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/bcp-innovations/hyperlane-cosmos/util"
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"google.golang.org/grpc"
+)
+
+func TestExtractOutgoingTransfers(t *testing.T) {
+	const multisig = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+
+	transfer := func(amount int64) *codectypes.Any {
+		t.Helper()
+		var recipient util.HexAddress
+		recipient[31] = 0xaa
+		anyMsg, err := codectypes.NewAnyWithValue(&warptypes.MsgRemoteTransfer{
+			Sender:            multisig,
+			DestinationDomain: 1380012617,
+			Recipient:         recipient,
+			Amount:            math.NewInt(amount),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return anyMsg
+	}
+
+	exec, err := codectypes.NewAnyWithValue(&authz.MsgExec{
+		Grantee: "celestia1grantee",
+		Msgs:    []*codectypes.Any{transfer(2000000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := &codectypes.Any{TypeUrl: "/hyperlane.warp.v1.MsgRemoteTransfer", Value: []byte{0xff}}
+
+	txn := &Transaction{
+		Hash:        "ABC123",
+		BlockHeight: 100,
+		Tx:          &tx.Tx{Body: &tx.TxBody{Messages: []*codectypes.Any{transfer(1000000), exec, broken}}},
+	}
+
+	transfers, err := ExtractOutgoingTransfers(txn)
+	var decodeErrs DecodeErrors
+	if !errors.As(err, &decodeErrs) || len(decodeErrs) != 1 {
+		t.Errorf("error = %v, want one decode error", err)
+	}
+	if len(transfers) != 2 {
+		t.Fatalf("got %d transfers, want the direct and the authz-wrapped transfer", len(transfers))
+	}
+	if transfers[0].Amount != "1000000" || transfers[1].Amount != "2000000" {
+		t.Errorf("amounts = %s, %s, want 1000000, 2000000", transfers[0].Amount, transfers[1].Amount)
+	}
+	for _, transfer := range transfers {
+		if transfer.From != multisig || transfer.DestinationDomain != 1380012617 {
+			t.Errorf("transfer = %+v", transfer)
+		}
+		if transfer.To != "0x00000000000000000000000000000000000000000000000000000000000000aa" {
+			t.Errorf("To = %s, want the 32-byte hex recipient", transfer.To)
+		}
+	}
+}
+
+// fakeTxsEventService serves GetTxsEvent pages of a block, capping the page size like a node does
+type fakeTxsEventService struct {
+	tx.ServiceClient
+	txs      []*sdk.TxResponse
+	maxLimit uint64
+	noTotal  bool // Leave the total unset, as some nodes do
+	pages    int
+}
+
+func (f *fakeTxsEventService) GetTxsEvent(ctx context.Context, req *tx.GetTxsEventRequest, opts ...grpc.CallOption) (*tx.GetTxsEventResponse, error) {
+	f.pages++
+	limit := min(req.Limit, f.maxLimit)
+	start := min(uint64(len(f.txs)), (req.Page-1)*limit)
+	end := min(uint64(len(f.txs)), start+limit)
+	resp := &tx.GetTxsEventResponse{TxResponses: f.txs[start:end]}
+	if !f.noTotal {
+		resp.Total = uint64(len(f.txs))
+	}
+	return resp, nil
+}
+
+func TestGetTransactionsAtHeightPages(t *testing.T) {
+	body, err := (&tx.Tx{Body: &tx.TxBody{}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var txs []*sdk.TxResponse
+	for i := 0; i < 250; i++ {
+		txs = append(txs, &sdk.TxResponse{TxHash: fmt.Sprintf("HASH%03d", i), Height: 100, Tx: &codectypes.Any{TypeUrl: "/cosmos.tx.v1beta1.Tx", Value: body}})
+	}
+
+	tests := []struct {
+		name      string
+		service   *fakeTxsEventService
+		query     types.QueryConfig
+		wantPages int
+		wantErr   bool
+	}{
+		{name: "pages of the requested size", service: &fakeTxsEventService{txs: txs, maxLimit: 100}, query: types.QueryConfig{PageSize: 100}, wantPages: 3},
+		{name: "page size capped by the node", service: &fakeTxsEventService{txs: txs, maxLimit: 100}, query: types.QueryConfig{PageSize: 500}, wantPages: 3},
+		{name: "total unset", service: &fakeTxsEventService{txs: txs, maxLimit: 100, noTotal: true}, query: types.QueryConfig{PageSize: 100}, wantPages: 3},
+		{name: "more pages than allowed", service: &fakeTxsEventService{txs: txs, maxLimit: 100}, query: types.QueryConfig{PageSize: 100, MaxPagesPerHeight: 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{txClient: tt.service, ctx: context.Background()}
+			c.SetQueryConfig(tt.query)
+
+			got, err := c.GetTransactionsAtHeight(100)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetTransactionsAtHeight() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTransactionsAtHeight() error = %v", err)
+			}
+			if len(got) != len(txs) {
+				t.Fatalf("got %d transactions, want %d", len(got), len(txs))
+			}
+			for i, txn := range got {
+				if txn.Hash != txs[i].TxHash {
+					t.Fatalf("transaction %d = %s, want %s", i, txn.Hash, txs[i].TxHash)
+				}
+			}
+			if tt.service.pages != tt.wantPages {
+				t.Errorf("queried %d pages, want %d", tt.service.pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	c := &Client{ctx: context.Background()}
+	var deadline time.Time
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}
+
+	if err := c.boundCall(context.Background(), "/cosmos.tx.v1beta1.Service/GetTx", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if !deadline.IsZero() {
+		t.Errorf("call without a timeout has deadline %s", deadline)
+	}
+
+	c.SetCallTimeout(time.Minute)
+	start := time.Now()
+	if err := c.boundCall(context.Background(), "/cosmos.tx.v1beta1.Service/GetTx", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if deadline.Before(start.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("call deadline %s, want a minute after the call", deadline)
+	}
+}
@@ -0,0 +1,549 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// txTypeURL is the type URL of the transactions read from blocks
+const txTypeURL = "/cosmos.tx.v1beta1.Tx"
+
+// Celestia wraps the transactions of blob submissions in a BlobTx or, inside blocks, an
+// IndexWrapper. Both carry the transaction in field 1 and their type ID in field 3.
+const (
+	blobTxTypeID       = "BLOB"
+	indexWrapperTypeID = "INDX"
+)
+
+// CometClient queries the chain through the CometBFT HTTP RPC, usually on port 26657, for
+// infrastructure that does not expose gRPC. Transactions are read from blocks and their results,
+// so the node's transaction index is only needed to look transactions up by hash.
+//
+// Calls failing with a network error, a timeout or a 5xx or 429 status are retried with the
+// backoff of the rpc config, moving on to the next fallback endpoint after every failure.
+type CometClient struct {
+	endpoints     []string
+	authorization string
+	http          *http.Client
+	ctx           context.Context
+	query         types.QueryConfig
+	cache         *ResponseCache // Optional on-disk cache of block queries
+
+	callTimeout    time.Duration // Bound on each call, zero for none
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	mu         sync.Mutex
+	current    int
+	onFailover func(from, to string, err error)
+
+	headers headerCache // Block headers fetched so far, with an optional persistent store
+}
+
+// NewCometClient creates a client of the CometBFT RPC endpoint at rpcEndpoint, an http:// or
+// https:// URL, with the TLS settings, credentials, call timeout, fallback endpoints and retries of
+// rpc. Credentials are only sent to https endpoints, which imply the tls setting.
+func NewCometClient(ctx context.Context, rpcEndpoint string, rpc types.RPCConfig) (*CometClient, error) {
+	if strings.HasPrefix(rpcEndpoint, "https://") {
+		rpc.TLS = true
+	}
+	if err := rpc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rpc config: %w", err)
+	}
+	rpc = rpc.WithDefaults()
+	timeout, _ := rpc.Timeout()
+	initial, maxBackoff, _ := rpc.Backoff()
+
+	endpoints := append([]string{rpcEndpoint}, rpc.FallbackURLs...)
+	for i, endpoint := range endpoints {
+		if !IsCometURL(endpoint) {
+			return nil, fmt.Errorf("CometBFT RPC endpoint %s is not an http:// or https:// URL", endpoint)
+		}
+		if rpc.Authorization() != "" && !strings.HasPrefix(endpoint, "https://") {
+			return nil, fmt.Errorf("credentials require https, they are not sent to %s", endpoint)
+		}
+		endpoints[i] = strings.TrimSuffix(endpoint, "/")
+	}
+
+	config, err := tlsConfig(rpc)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+
+	return &CometClient{
+		endpoints:      endpoints,
+		authorization:  rpc.Authorization(),
+		http:           &http.Client{Transport: transport},
+		ctx:            ctx,
+		query:          types.DefaultQueryConfig(),
+		callTimeout:    timeout,
+		maxAttempts:    rpc.MaxAttempts,
+		initialBackoff: initial,
+		maxBackoff:     maxBackoff,
+	}, nil
+}
+
+// SetCallTimeout bounds every call to the node. Zero leaves calls bounded only by the client's context.
+func (c *CometClient) SetCallTimeout(timeout time.Duration) {
+	c.callTimeout = timeout
+}
+
+// SetCache enables caching of per-height transaction queries
+func (c *CometClient) SetCache(cache *ResponseCache) {
+	c.cache = cache
+}
+
+// SetQueryConfig sets the concurrency of transaction lookups by hash. Blocks are read whole, so the
+// page settings do not apply.
+func (c *CometClient) SetQueryConfig(query types.QueryConfig) {
+	c.query = query.WithDefaults()
+}
+
+// SetHeaderStore keeps the block headers the client fetches in store, so they are not queried
+// again by later runs
+func (c *CometClient) SetHeaderStore(store storage.HeaderStorage) {
+	c.headers.store = store
+}
+
+// SetFailoverHandler sets a function called whenever the client moves from one endpoint to
+// another, with the error that caused it
+func (c *CometClient) SetFailoverHandler(fn func(from, to string, err error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onFailover = fn
+}
+
+// Endpoint returns the endpoint the client currently sends its calls to
+func (c *CometClient) Endpoint() string {
+	_, endpoint := c.endpoint()
+	return endpoint
+}
+
+// Close releases the client's idle connections
+func (c *CometClient) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+// LatestHeight queries the height of the latest block committed by the node
+func (c *CometClient) LatestHeight() (int64, error) {
+	var status cometStatus
+	if err := c.call("status", nil, &status); err != nil {
+		return 0, fmt.Errorf("failed to query latest block: %w", err)
+	}
+	return status.SyncInfo.LatestBlockHeight, nil
+}
+
+// BlockHeader returns the header of the committed block at height. Headers are read through an
+// in-memory cache and the header store, if one is set, so each height is queried once.
+func (c *CometClient) BlockHeader(height int64) (*types.BlockHeader, error) {
+	return c.headers.get(c.ctx, height, func() (types.BlockHeader, error) {
+		var block cometBlock
+		if err := c.call("block", heightParams(height), &block); err != nil {
+			return types.BlockHeader{}, fmt.Errorf("failed to query block at height %d: %w", height, err)
+		}
+		return block.header(), nil
+	})
+}
+
+// GetTransactionsAtHeight queries the transactions included in a single block with their results
+func (c *CometClient) GetTransactionsAtHeight(height int64) ([]*Transaction, error) {
+	resp, err := c.blockTxs(height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions at height %d: %w", height, err)
+	}
+
+	var txs []*Transaction
+	for _, txResp := range resp.TxResponses {
+		if txn := decodeTransaction(txResp, height); txn != nil {
+			txs = append(txs, txn)
+		}
+	}
+	return txs, nil
+}
+
+// GetTransactions queries and decodes many included transactions by hash, in the order of hashes,
+// with up to the configured concurrency of queries in flight. If any query fails, the errors of
+// all failed queries are returned together.
+func (c *CometClient) GetTransactions(hashes []string) ([]*Transaction, error) {
+	responses := make([]*sdk.TxResponse, len(hashes))
+	errs := make([]error, len(hashes))
+
+	sem := make(chan struct{}, c.query.WithDefaults().Concurrency)
+	var wg sync.WaitGroup
+	for i, hash := range hashes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			responses[i], errs[i] = c.getTx(hash)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var txs []*Transaction
+	for _, txResp := range responses {
+		if txn := decodeTransaction(txResp, txResp.Height); txn != nil {
+			txs = append(txs, txn)
+		}
+	}
+	return txs, nil
+}
+
+// GetWarpTokens queries all warp tokens registered on the chain through the node's ABCI query
+func (c *CometClient) GetWarpTokens() ([]warptypes.WrappedHypToken, error) {
+	var tokens []warptypes.WrappedHypToken
+	var nextKey []byte
+
+	for {
+		var resp warptypes.QueryTokensResponse
+		req := &warptypes.QueryTokensRequest{Pagination: &query.PageRequest{Key: nextKey}}
+		if err := c.abciQuery("/hyperlane.warp.v1.Query/Tokens", req, &resp); err != nil {
+			return nil, fmt.Errorf("failed to query warp tokens: %w", err)
+		}
+
+		tokens = append(tokens, resp.Tokens...)
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+
+	return tokens, nil
+}
+
+// blockTxs queries the transactions of the block at height with their results, as the gRPC tx
+// service would return them, serving them from the response cache when one is set
+func (c *CometClient) blockTxs(height int64) (*tx.GetTxsEventResponse, error) {
+	key := cometCacheKey(height)
+	if c.cache != nil {
+		if resp := c.cache.Get(height, key); resp != nil {
+			return resp, nil
+		}
+	}
+
+	var block cometBlock
+	if err := c.call("block", heightParams(height), &block); err != nil {
+		return nil, err
+	}
+	var results cometBlockResults
+	if err := c.call("block_results", heightParams(height), &results); err != nil {
+		return nil, err
+	}
+	if len(results.TxsResults) != len(block.Block.Data.Txs) {
+		return nil, fmt.Errorf("block has %d transactions but %d results", len(block.Block.Data.Txs), len(results.TxsResults))
+	}
+	c.headers.add(block.header())
+
+	resp := &tx.GetTxsEventResponse{Total: uint64(len(block.Block.Data.Txs))}
+	for i, raw := range block.Block.Data.Txs {
+		resp.TxResponses = append(resp.TxResponses, txResponse(raw, height, results.TxsResults[i]))
+	}
+
+	// Empty responses are not cached, as for the gRPC client
+	if c.cache != nil && len(resp.TxResponses) > 0 {
+		if err := c.cache.Put(height, key, resp); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// getTx queries an included transaction by hash from the node's transaction index
+func (c *CometClient) getTx(hash string) (*sdk.TxResponse, error) {
+	var result cometTx
+	params := url.Values{"hash": {"0x" + strings.TrimPrefix(strings.ToUpper(hash), "0X")}}
+	if err := c.call("tx", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to query tx %s: %w", hash, err)
+	}
+	resp := txResponse(result.Tx, result.Height, result.TxResult)
+	if result.Hash != "" {
+		resp.TxHash = strings.ToUpper(result.Hash)
+	}
+	return resp, nil
+}
+
+// abciQuery sends req to the gRPC query method at path through the node's ABCI query, decoding the
+// answer into resp
+func (c *CometClient) abciQuery(path string, req interface{ Marshal() ([]byte, error) }, resp interface{ Unmarshal([]byte) error }) error {
+	data, err := req.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	params := url.Values{
+		"path": {strconv.Quote(path)},
+		"data": {"0x" + hex.EncodeToString(data)},
+	}
+	var result cometABCIQuery
+	if err := c.call("abci_query", params, &result); err != nil {
+		return err
+	}
+	if result.Response.Code != 0 {
+		return fmt.Errorf("query %s failed with code %d: %s", path, result.Response.Code, result.Response.Log)
+	}
+	return resp.Unmarshal(result.Response.Value)
+}
+
+// call sends a request for method to the current endpoint and decodes its result into result.
+// Transient failures are retried with backoff on the next endpoint.
+func (c *CometClient) call(method string, params url.Values, result any) error {
+	backoff := c.initialBackoff
+	for attempt := 1; ; attempt++ {
+		index, endpoint := c.endpoint()
+		transient, err := c.get(endpoint, method, params, result)
+		if err == nil || !transient || c.ctx.Err() != nil {
+			return err
+		}
+		c.failover(index, err)
+		if attempt >= c.maxAttempts {
+			return err
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, c.maxBackoff)
+	}
+}
+
+// get sends a single request for method to endpoint, reporting whether a failure is worth retrying
+func (c *CometClient) get(endpoint, method string, params url.Values, result any) (transient bool, err error) {
+	ctx := c.ctx
+	if c.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.callTimeout)
+		defer cancel()
+	}
+
+	target := endpoint + "/" + method
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s request: %w", method, err)
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("%s request to %s failed: %w", method, endpoint, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, fmt.Errorf("failed to read %s response from %s: %w", method, endpoint, err)
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *cometError     `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || (envelope.Result == nil && envelope.Error == nil) {
+		transient = resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+		return transient, fmt.Errorf("%s request to %s returned no JSON-RPC response (status %s)", method, endpoint, resp.Status)
+	}
+	if envelope.Error != nil {
+		return false, fmt.Errorf("%s request failed: %w", method, envelope.Error)
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return false, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	return false, nil
+}
+
+// endpoint returns the index and URL of the current endpoint
+func (c *CometClient) endpoint() (int, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current, c.endpoints[c.current]
+}
+
+// failover moves off the endpoint at failed to the next one. It does nothing if another call
+// already moved off it.
+func (c *CometClient) failover(failed int, err error) {
+	if len(c.endpoints) < 2 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current != failed {
+		return
+	}
+	c.current = (failed + 1) % len(c.endpoints)
+	if c.onFailover != nil {
+		c.onFailover(c.endpoints[failed], c.endpoints[c.current], err)
+	}
+}
+
+// heightParams returns the parameters of a request for the block at height
+func heightParams(height int64) url.Values {
+	return url.Values{"height": {strconv.FormatInt(height, 10)}}
+}
+
+// cometCacheKey returns the request under which the transactions of the block at height are
+// cached. It cannot collide with the paged queries of the gRPC client.
+func cometCacheKey(height int64) *tx.GetTxsEventRequest {
+	return &tx.GetTxsEventRequest{Query: fmt.Sprintf("block_results.height=%d", height)}
+}
+
+// txResponse builds the response the gRPC tx service gives for raw, a transaction included at
+// height with result. Celestia blob transactions are unwrapped to the transaction inside.
+func txResponse(raw []byte, height int64, result cometTxResult) *sdk.TxResponse {
+	raw = unwrapTx(raw)
+	hash := sha256.Sum256(raw)
+	return &sdk.TxResponse{
+		Height:    height,
+		TxHash:    strings.ToUpper(hex.EncodeToString(hash[:])),
+		Codespace: result.Codespace,
+		Code:      result.Code,
+		RawLog:    result.Log,
+		Tx:        &codectypes.Any{TypeUrl: txTypeURL, Value: raw},
+		Events:    result.Events,
+	}
+}
+
+// unwrapTx returns the transaction inside a BlobTx or IndexWrapper, and any other transaction as is
+func unwrapTx(raw []byte) []byte {
+	var inner []byte
+	var typeID string
+	for b := raw; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return raw
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return raw
+			}
+			b = b[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return raw
+		}
+		b = b[n:]
+		switch num {
+		case 1:
+			inner = value
+		case 3:
+			typeID = string(value)
+		}
+	}
+	if inner != nil && (typeID == blobTxTypeID || typeID == indexWrapperTypeID) {
+		return inner
+	}
+	return raw
+}
+
+// cometError is the error of a JSON-RPC response
+type cometError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func (e *cometError) Error() string {
+	if e.Data != "" {
+		return e.Message + ": " + e.Data
+	}
+	return e.Message
+}
+
+// cometStatus is the result of the status method
+type cometStatus struct {
+	SyncInfo struct {
+		LatestBlockHeight int64 `json:"latest_block_height,string"`
+	} `json:"sync_info"`
+}
+
+// cometBlock is the result of the block method
+type cometBlock struct {
+	BlockID struct {
+		Hash string `json:"hash"`
+	} `json:"block_id"`
+	Block struct {
+		Header struct {
+			Height int64     `json:"height,string"`
+			Time   time.Time `json:"time"`
+		} `json:"header"`
+		Data struct {
+			Txs [][]byte `json:"txs"`
+		} `json:"data"`
+	} `json:"block"`
+}
+
+// header returns the header of the block
+func (b *cometBlock) header() types.BlockHeader {
+	return types.BlockHeader{
+		Height: b.Block.Header.Height,
+		Hash:   strings.ToUpper(b.BlockID.Hash),
+		Time:   b.Block.Header.Time.UTC(),
+	}
+}
+
+// cometTxResult is the execution result of a transaction
+type cometTxResult struct {
+	Code      uint32       `json:"code"`
+	Codespace string       `json:"codespace"`
+	Log       string       `json:"log"`
+	Events    []abci.Event `json:"events"`
+}
+
+// cometBlockResults is the result of the block_results method
+type cometBlockResults struct {
+	TxsResults []cometTxResult `json:"txs_results"`
+}
+
+// cometTx is the result of the tx method
+type cometTx struct {
+	Hash     string        `json:"hash"`
+	Height   int64         `json:"height,string"`
+	TxResult cometTxResult `json:"tx_result"`
+	Tx       []byte        `json:"tx"`
+}
+
+// cometABCIQuery is the result of the abci_query method
+type cometABCIQuery struct {
+	Response struct {
+		Code  uint32 `json:"code"`
+		Log   string `json:"log"`
+		Value []byte `json:"value"`
+	} `json:"response"`
+}
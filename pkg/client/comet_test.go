@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// fakeCometNode serves the status, block and block_results methods of a chain at height 100 whose
+// blocks hold tx, or fails every request with 503 while down
+type fakeCometNode struct {
+	tx    []byte
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func (n *fakeCometNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.calls.Add(1)
+	if n.down.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	height := r.URL.Query().Get("height")
+	switch r.URL.Path {
+	case "/status":
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":-1,"result":{"sync_info":{"latest_block_height":"100"}}}`)
+	case "/block":
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":-1,"result":{"block_id":{"hash":"ab12"},"block":{"header":{"height":"%s","time":"2025-03-01T12:00:00Z"},"data":{"txs":["%s"]}}}}`,
+			height, base64.StdEncoding.EncodeToString(n.tx))
+	case "/block_results":
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":-1,"result":{"txs_results":[{"code":5,"log":"insufficient funds","events":[{"type":"transfer","attributes":[{"key":"amount","value":"10utia","index":true}]}]}]}}`)
+	default:
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":-1,"error":{"code":-32601,"message":"Method not found"}}`)
+	}
+}
+
+func TestCometClientBlockTxs(t *testing.T) {
+	raw, err := (&tx.Tx{Body: &tx.TxBody{Memo: "rebalance"}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := &fakeCometNode{tx: raw}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	c, err := NewCometClient(context.Background(), server.URL, types.RPCConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	height, err := c.LatestHeight()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 100 {
+		t.Errorf("expected height 100, got %d", height)
+	}
+
+	txs, err := c.GetTransactionsAtHeight(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(txs))
+	}
+	hash := sha256.Sum256(raw)
+	got := txs[0]
+	if got.Hash != strings.ToUpper(hex.EncodeToString(hash[:])) || got.BlockHeight != 42 || got.Memo != "rebalance" || got.Code != 5 {
+		t.Errorf("unexpected transaction %+v", got)
+	}
+	if len(got.Events) != 1 || got.Events[0].Attributes[0].Value != "10utia" {
+		t.Errorf("unexpected events %+v", got.Events)
+	}
+
+	// The header of a scanned block is kept, so it is not queried again
+	calls := node.calls.Load()
+	header, err := c.BlockHeader(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Hash != "AB12" || header.Time.Hour() != 12 {
+		t.Errorf("unexpected header %+v", header)
+	}
+	if node.calls.Load() != calls {
+		t.Error("expected the header of a scanned block to be served from the cache")
+	}
+
+	if _, err := c.GetTransactions([]string{"abcd"}); err == nil || !strings.Contains(err.Error(), "Method not found") {
+		t.Errorf("expected the JSON-RPC error of an unknown method, got %v", err)
+	}
+}
+
+func TestCometClientFailover(t *testing.T) {
+	primary := &fakeCometNode{}
+	primary.down.Store(true)
+	fallback := &fakeCometNode{}
+	primaryServer := httptest.NewServer(primary)
+	defer primaryServer.Close()
+	fallbackServer := httptest.NewServer(fallback)
+	defer fallbackServer.Close()
+
+	rpc := types.RPCConfig{FallbackURLs: []string{fallbackServer.URL}, InitialBackoff: "1ms", MaxBackoff: "1ms"}
+	c, err := NewCometClient(context.Background(), primaryServer.URL, rpc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failovers []string
+	c.SetFailoverHandler(func(from, to string, err error) {
+		failovers = append(failovers, from+" -> "+to)
+	})
+
+	if _, err := c.LatestHeight(); err != nil {
+		t.Fatal(err)
+	}
+	if len(failovers) != 1 || c.Endpoint() != fallbackServer.URL {
+		t.Errorf("expected one failover to the fallback, got %v", failovers)
+	}
+
+	// Errors the node answers with are not retried
+	calls := fallback.calls.Load()
+	if err := c.call("unknown", nil, &struct{}{}); err == nil {
+		t.Fatal("expected an error for an unknown method")
+	}
+	if fallback.calls.Load() != calls+1 {
+		t.Errorf("expected a single attempt, got %d", fallback.calls.Load()-calls)
+	}
+}
+
+func TestNewCometClientCredentials(t *testing.T) {
+	rpc := types.RPCConfig{TLS: true, BearerToken: "secret"}
+	if _, err := NewCometClient(context.Background(), "http://localhost:26657", rpc); err == nil {
+		t.Error("expected credentials to be refused over http")
+	}
+	if _, err := NewCometClient(context.Background(), "https://rpc.example.com", types.RPCConfig{BearerToken: "secret"}); err != nil {
+		t.Errorf("unexpected error over https: %v", err)
+	}
+	if _, err := NewCometClient(context.Background(), "localhost:9090", types.RPCConfig{}); err == nil {
+		t.Error("expected an error for a URL without an http scheme")
+	}
+}
+
+func TestUnwrapTx(t *testing.T) {
+	inner := []byte("inner tx")
+	wrap := func(typeID string) []byte {
+		b := protowire.AppendTag(nil, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, inner)
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, []byte("blob or share indexes"))
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		return protowire.AppendBytes(b, []byte(typeID))
+	}
+
+	for _, typeID := range []string{blobTxTypeID, indexWrapperTypeID} {
+		if got := unwrapTx(wrap(typeID)); string(got) != string(inner) {
+			t.Errorf("%s: expected the inner transaction, got %q", typeID, got)
+		}
+	}
+	if raw := wrap("OTHER"); string(unwrapTx(raw)) != string(raw) {
+		t.Error("expected a transaction with another type ID to be returned as is")
+	}
+	if raw := []byte{0xff, 0xff}; string(unwrapTx(raw)) != string(raw) {
+		t.Error("expected malformed bytes to be returned as is")
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// dialOptions returns the transport and auth options of a connection with the given settings
+func dialOptions(rpc types.RPCConfig) ([]grpc.DialOption, error) {
+	creds := insecure.NewCredentials()
+	if rpc.TLSEnabled() {
+		tlsConfig, err := tlsConfig(rpc)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if header := rpc.Authorization(); header != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(authCredentials(header)))
+	}
+	return opts, nil
+}
+
+// tlsConfig returns the TLS settings of a connection, verifying the endpoint against the CA file if
+// one is set and the system roots otherwise
+func tlsConfig(rpc types.RPCConfig) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if rpc.CAFile != "" {
+		pem, err := os.ReadFile(rpc.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates in CA file %s", rpc.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// authCredentials sends its value as the authorization header of every call
+type authCredentials string
+
+// GetRequestMetadata implements credentials.PerRPCCredentials
+func (a authCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": string(a)}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials, so credentials are never sent
+// over a plaintext connection
+func (a authCredentials) RequireTransportSecurity() bool {
+	return true
+}
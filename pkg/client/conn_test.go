@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestDialOptions(t *testing.T) {
+	opts, err := dialOptions(types.RPCConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 1 {
+		t.Errorf("plaintext connection has %d options, want only the transport", len(opts))
+	}
+
+	opts, err = dialOptions(types.RPCConfig{TLS: true, BearerToken: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 2 {
+		t.Errorf("authenticated connection has %d options, want transport and credentials", len(opts))
+	}
+
+	if _, err := dialOptions(types.RPCConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("missing CA file was accepted")
+	}
+	invalid := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dialOptions(types.RPCConfig{CAFile: invalid}); err == nil {
+		t.Error("CA file without certificates was accepted")
+	}
+}
+
+func TestAuthCredentials(t *testing.T) {
+	creds := authCredentials("Bearer secret")
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md["authorization"] != "Bearer secret" {
+		t.Errorf("authorization = %q, want %q", md["authorization"], "Bearer secret")
+	}
+	if !creds.RequireTransportSecurity() {
+		t.Error("credentials may be sent over plaintext")
+	}
+}
+
+func TestNewClientWithConfigRejectsPlaintextCredentials(t *testing.T) {
+	if _, err := NewClientWithConfig(context.Background(), "localhost:9090", types.RPCConfig{BearerToken: "secret"}); err == nil {
+		t.Error("credentials over plaintext were accepted")
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/bcp-innovations/hyperlane-cosmos/util"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// dispatchEventType is the typed event emitted by the Hyperlane mailbox for every dispatched message
+const dispatchEventType = "hyperlane.core.v1.EventDispatch"
+
+// GetTx queries an included transaction by hash
+func (c *Client) GetTx(hash string) (*sdk.TxResponse, error) {
+	resp, err := c.txClient.GetTx(c.ctx, &tx.GetTxRequest{Hash: strings.ToUpper(hash)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tx %s: %w", hash, err)
+	}
+	if resp.TxResponse == nil {
+		return nil, fmt.Errorf("tx %s not found", hash)
+	}
+	return resp.TxResponse, nil
+}
+
+// ExtractDispatches returns the Hyperlane messages dispatched by a transaction, in the order they
+// were dispatched
+func ExtractDispatches(resp *sdk.TxResponse) ([]types.Dispatch, error) {
+	var dispatches []types.Dispatch
+
+	for _, event := range resp.Events {
+		if event.Type != dispatchEventType {
+			continue
+		}
+
+		var raw string
+		for _, attr := range event.Attributes {
+			if attr.Key != "message" {
+				continue
+			}
+			// Typed event attributes hold JSON-encoded values
+			if err := json.Unmarshal([]byte(attr.Value), &raw); err != nil {
+				// Older nodes strip the JSON quotes
+				raw = attr.Value
+			}
+		}
+		if raw == "" {
+			return nil, fmt.Errorf("dispatch event %d in tx %s has no message", len(dispatches), resp.TxHash)
+		}
+
+		data, err := hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("dispatch event %d in tx %s: invalid message encoding: %w", len(dispatches), resp.TxHash, err)
+		}
+		msg, err := util.ParseHyperlaneMessage(data)
+		if err != nil {
+			return nil, fmt.Errorf("dispatch event %d in tx %s: %w", len(dispatches), resp.TxHash, err)
+		}
+
+		// The message recipient is the destination router; the warp payload carries the
+		// transfer recipient and amount as two 32-byte words
+		if len(msg.Body) < 64 {
+			return nil, fmt.Errorf("dispatch event %d in tx %s: message body is not a warp transfer", len(dispatches), resp.TxHash)
+		}
+		dispatches = append(dispatches, types.Dispatch{
+			MessageID:   msg.Id().String(),
+			Nonce:       msg.Nonce,
+			Destination: msg.Destination,
+			Recipient:   "0x" + hex.EncodeToString(msg.Body[:32]),
+			Amount:      new(big.Int).SetBytes(msg.Body[32:64]).String(),
+			TxHash:      resp.TxHash,
+			Height:      resp.Height,
+		})
+	}
+
+	return dispatches, nil
+}
+
+// TxCost is what an included rebalancing transaction cost the multisig
+type TxCost struct {
+	Fee       string // Transaction fee as coins, empty if none was paid
+	GasWanted int64
+	GasUsed   int64
+	// InterchainGas holds the MaxFee of each MsgRemoteTransfer in message order, which matches the
+	// order of the dispatched messages. It is empty for transfers without one.
+	InterchainGas []string
+}
+
+// ExtractTxCost returns the transaction fee and the interchain gas limits of an included
+// rebalancing transaction
+func ExtractTxCost(resp *sdk.TxResponse) (*TxCost, error) {
+	txn := decodeTransaction(resp, resp.Height)
+	if txn == nil {
+		return nil, fmt.Errorf("tx %s response has no transaction", resp.TxHash)
+	}
+	if txn.DecodeError != nil {
+		return nil, txn.DecodeError
+	}
+
+	cost := &TxCost{GasWanted: resp.GasWanted, GasUsed: resp.GasUsed}
+	if txn.Tx.AuthInfo != nil && txn.Tx.AuthInfo.Fee != nil {
+		cost.Fee = txn.Tx.AuthInfo.Fee.Amount.String()
+	}
+	transfers, err := ExtractOutgoingTransfers(txn)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		cost.InterchainGas = append(cost.InterchainGas, transfer.MaxFee)
+	}
+	return cost, nil
+}
+
+// TxFailed returns an error describing the failure if the transaction was included but failed
+func TxFailed(resp *sdk.TxResponse) error {
+	if resp.Code == 0 {
+		return nil
+	}
+	return fmt.Errorf("tx %s failed with code %s/%d: %s", resp.TxHash, resp.Codespace, resp.Code, resp.RawLog)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/bcp-innovations/hyperlane-cosmos/util"
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// dispatchEvent builds the typed dispatch event emitted for a warp transfer
+func dispatchEvent(t *testing.T, nonce, destination uint32, recipient []byte, amount int64) abci.Event {
+	t.Helper()
+
+	body := make([]byte, 64)
+	copy(body[32-len(recipient):32], recipient)
+	new(big.Int).SetInt64(amount).FillBytes(body[32:64])
+	msg := util.HyperlaneMessage{
+		Version:     3,
+		Nonce:       nonce,
+		Origin:      1128614981,
+		Destination: destination,
+		Body:        body,
+	}
+
+	value, err := json.Marshal(msg.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return abci.Event{
+		Type: dispatchEventType,
+		Attributes: []abci.EventAttribute{
+			{Key: "destination", Value: "1380012617"},
+			{Key: "message", Value: string(value)},
+		},
+	}
+}
+
+func TestExtractDispatches(t *testing.T) {
+	recipient := []byte{0x74, 0x2d, 0x35, 0xcc}
+	resp := &sdk.TxResponse{
+		TxHash: "ABC123",
+		Height: 100,
+		Events: []abci.Event{
+			{Type: "transfer"},
+			dispatchEvent(t, 7, 1380012617, recipient, 1000000),
+			{Type: "hyperlane.warp.v1.EventSendRemoteTransfer"},
+			dispatchEvent(t, 8, 42161, recipient, 2000000),
+		},
+	}
+
+	dispatches, err := ExtractDispatches(resp)
+	if err != nil {
+		t.Fatalf("ExtractDispatches() error = %v", err)
+	}
+	if len(dispatches) != 2 {
+		t.Fatalf("got %d dispatches, want 2", len(dispatches))
+	}
+
+	first := dispatches[0]
+	if first.Nonce != 7 || first.Destination != 1380012617 || first.Amount != "1000000" {
+		t.Errorf("first dispatch = %+v", first)
+	}
+	if first.TxHash != "ABC123" || first.Height != 100 {
+		t.Errorf("first dispatch tx = %s at %d, want ABC123 at 100", first.TxHash, first.Height)
+	}
+	if !strings.HasSuffix(first.Recipient, "742d35cc") || len(first.Recipient) != 66 {
+		t.Errorf("Recipient = %s, want 32-byte hex ending in 742d35cc", first.Recipient)
+	}
+	if len(first.MessageID) != 66 || first.MessageID == dispatches[1].MessageID {
+		t.Errorf("message IDs = %s, %s, want distinct 32-byte IDs", first.MessageID, dispatches[1].MessageID)
+	}
+	if dispatches[1].Nonce != 8 || dispatches[1].Destination != 42161 {
+		t.Errorf("second dispatch = %+v", dispatches[1])
+	}
+}
+
+func TestExtractDispatchesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		event abci.Event
+	}{
+		{"no message", abci.Event{Type: dispatchEventType}},
+		{"invalid hex", abci.Event{Type: dispatchEventType, Attributes: []abci.EventAttribute{{Key: "message", Value: `"0xzz"`}}}},
+		{"not a warp transfer", abci.Event{Type: dispatchEventType, Attributes: []abci.EventAttribute{
+			{Key: "message", Value: `"` + util.HyperlaneMessage{Version: 3}.String() + `"`},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &sdk.TxResponse{TxHash: "ABC123", Events: []abci.Event{tt.event}}
+			if _, err := ExtractDispatches(resp); err == nil {
+				t.Error("ExtractDispatches() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestExtractTxCost(t *testing.T) {
+	var msgs []*codectypes.Any
+	for _, maxFee := range []sdk.Coin{sdk.NewInt64Coin("utia", 5000), {}} {
+		anyMsg, err := codectypes.NewAnyWithValue(&warptypes.MsgRemoteTransfer{
+			Sender:            "celestia1multisig",
+			DestinationDomain: 1380012617,
+			Amount:            math.NewInt(1000000),
+			MaxFee:            maxFee,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, anyMsg)
+	}
+	signed := &tx.Tx{
+		Body:     &tx.TxBody{Messages: msgs},
+		AuthInfo: &tx.AuthInfo{Fee: &tx.Fee{Amount: sdk.NewCoins(sdk.NewInt64Coin("utia", 2500)), GasLimit: 200000}},
+	}
+	data, err := signed.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &sdk.TxResponse{
+		TxHash:    "ABC123",
+		Height:    100,
+		GasWanted: 200000,
+		GasUsed:   150000,
+		Tx:        &codectypes.Any{TypeUrl: "/cosmos.tx.v1beta1.Tx", Value: data},
+	}
+	cost, err := ExtractTxCost(resp)
+	if err != nil {
+		t.Fatalf("ExtractTxCost() error = %v", err)
+	}
+	if cost.Fee != "2500utia" || cost.GasUsed != 150000 || cost.GasWanted != 200000 {
+		t.Errorf("ExtractTxCost() = %+v", cost)
+	}
+	if len(cost.InterchainGas) != 2 || cost.InterchainGas[0] != "5000utia" || cost.InterchainGas[1] != "" {
+		t.Errorf("InterchainGas = %q, want 5000utia and none", cost.InterchainGas)
+	}
+
+	if _, err := ExtractTxCost(&sdk.TxResponse{TxHash: "ABC123"}); err == nil {
+		t.Error("ExtractTxCost() succeeded for a response without a transaction")
+	}
+}
+
+func TestTxFailed(t *testing.T) {
+	if err := TxFailed(&sdk.TxResponse{TxHash: "ABC123"}); err != nil {
+		t.Errorf("TxFailed() = %v for a successful tx", err)
+	}
+	err := TxFailed(&sdk.TxResponse{TxHash: "ABC123", Code: 5, Codespace: "sdk", RawLog: "insufficient funds"})
+	if err == nil || !strings.Contains(err.Error(), "sdk/5: insufficient funds") {
+		t.Errorf("TxFailed() = %v, want the code and log", err)
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// healthCheckTimeout bounds the check of an endpoint before failing over to it
+const healthCheckTimeout = 5 * time.Second
+
+// broadcastMethod is not retried: a broadcast that failed in flight may still have reached the
+// mempool, and sending it again would fail on the sequence and hide what happened
+const broadcastMethod = "/cosmos.tx.v1beta1.Service/BroadcastTx"
+
+// endpoint is one gRPC endpoint of the chain
+type endpoint struct {
+	url  string
+	conn *grpc.ClientConn
+}
+
+// endpointPool sends the client's calls to its current endpoint. Calls failing with a transient
+// error are retried with exponential backoff, after failing over to the next healthy endpoint.
+type endpointPool struct {
+	endpoints      []endpoint
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	mu         sync.Mutex
+	current    int
+	onFailover func(from, to string, err error)
+}
+
+// conn returns the index and connection of the current endpoint
+func (p *endpointPool) conn() (int, *grpc.ClientConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current, p.endpoints[p.current].conn
+}
+
+// Invoke implements grpc.ClientConnInterface
+func (p *endpointPool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	backoff := p.initialBackoff
+	for attempt := 1; ; attempt++ {
+		index, conn := p.conn()
+		err := conn.Invoke(ctx, method, args, reply, opts...)
+		if err == nil || !transient(ctx, err) {
+			return err
+		}
+		p.failover(ctx, index, err)
+		if method == broadcastMethod || attempt >= p.maxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, p.maxBackoff)
+	}
+}
+
+// NewStream implements grpc.ClientConnInterface. Streams are not retried.
+func (p *endpointPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	_, conn := p.conn()
+	return conn.NewStream(ctx, desc, method, opts...)
+}
+
+// failover moves off the endpoint at failed to the next one that passes a health check, or simply
+// the next one if none does. It does nothing if another call already moved off it.
+func (p *endpointPool) failover(ctx context.Context, failed int, err error) {
+	if len(p.endpoints) < 2 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current != failed {
+		return
+	}
+
+	next := (failed + 1) % len(p.endpoints)
+	for i := 1; i < len(p.endpoints); i++ {
+		candidate := (failed + i) % len(p.endpoints)
+		if healthy(ctx, p.endpoints[candidate].conn) {
+			next = candidate
+			break
+		}
+	}
+	p.current = next
+	if p.onFailover != nil {
+		p.onFailover(p.endpoints[failed].url, p.endpoints[next].url, err)
+	}
+}
+
+// close closes the connections to all endpoints
+func (p *endpointPool) close() error {
+	var first error
+	for _, e := range p.endpoints {
+		if err := e.conn.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// transient reports whether err is worth retrying: the node is unreachable or overloaded, or a
+// single call timed out while the caller's context is still live
+func transient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// healthy reports whether the node behind conn answers and is not catching up. Nodes that do not
+// serve the sync status are taken as healthy if they answer at all.
+func healthy(ctx context.Context, conn *grpc.ClientConn) bool {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	resp, err := cmtservice.NewServiceClient(conn).GetSyncing(ctx, &cmtservice.GetSyncingRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return true
+	}
+	return err == nil && !resp.Syncing
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeNode serves the latest block, or fails every call with Unavailable while down
+type fakeNode struct {
+	cmtservice.UnimplementedServiceServer
+	height int64
+	down   atomic.Bool
+	calls  atomic.Int32
+}
+
+func (n *fakeNode) GetLatestBlock(ctx context.Context, req *cmtservice.GetLatestBlockRequest) (*cmtservice.GetLatestBlockResponse, error) {
+	n.calls.Add(1)
+	if n.down.Load() {
+		return nil, status.Error(codes.Unavailable, "node restarting")
+	}
+	return &cmtservice.GetLatestBlockResponse{SdkBlock: &cmtservice.Block{Header: cmtservice.Header{Height: n.height}}}, nil
+}
+
+func (n *fakeNode) GetSyncing(ctx context.Context, req *cmtservice.GetSyncingRequest) (*cmtservice.GetSyncingResponse, error) {
+	if n.down.Load() {
+		return nil, status.Error(codes.Unavailable, "node restarting")
+	}
+	return &cmtservice.GetSyncingResponse{}, nil
+}
+
+// serveNode serves node on a local port and returns its address
+func serveNode(t *testing.T, node *fakeNode) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	cmtservice.RegisterServiceServer(server, node)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestClientFailover(t *testing.T) {
+	primary := &fakeNode{height: 100}
+	primary.down.Store(true)
+	restarting := &fakeNode{height: 101}
+	restarting.down.Store(true)
+	fallback := &fakeNode{height: 102}
+
+	primaryURL := serveNode(t, primary)
+	c, err := NewClientWithConfig(context.Background(), primaryURL, types.RPCConfig{
+		FallbackURLs:   []string{serveNode(t, restarting), serveNode(t, fallback)},
+		InitialBackoff: "1ms",
+		MaxBackoff:     "1ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var failovers []string
+	c.SetFailoverHandler(func(from, to string, err error) {
+		failovers = append(failovers, from+" -> "+to)
+	})
+
+	height, err := c.LatestHeight()
+	if err != nil {
+		t.Fatalf("LatestHeight() error = %v", err)
+	}
+	if height != 102 {
+		t.Errorf("LatestHeight() = %d, want 102 from the healthy fallback", height)
+	}
+	if restarting.calls.Load() != 0 {
+		t.Errorf("unhealthy fallback got %d calls, want it skipped", restarting.calls.Load())
+	}
+	if len(failovers) != 1 || c.Endpoint() == primaryURL {
+		t.Errorf("failovers = %v, endpoint %s, want one failover off the primary", failovers, c.Endpoint())
+	}
+}
+
+func TestClientRetriesTransientErrors(t *testing.T) {
+	node := &fakeNode{height: 100}
+	node.down.Store(true)
+	c, err := NewClientWithConfig(context.Background(), serveNode(t, node), types.RPCConfig{
+		MaxAttempts:    3,
+		InitialBackoff: "1ms",
+		MaxBackoff:     "2ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.LatestHeight(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("LatestHeight() error = %v, want Unavailable", err)
+	}
+	if node.calls.Load() != 3 {
+		t.Errorf("node got %d calls, want 3 attempts", node.calls.Load())
+	}
+
+	node.down.Store(false)
+	if height, err := c.LatestHeight(); err != nil || height != 100 {
+		t.Errorf("LatestHeight() after the restart = %d, %v, want 100", height, err)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	ctx := context.Background()
+	if !transient(ctx, status.Error(codes.Unavailable, "down")) {
+		t.Error("Unavailable is not transient")
+	}
+	if transient(ctx, status.Error(codes.InvalidArgument, "bad query")) {
+		t.Error("InvalidArgument is transient")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if transient(cancelled, status.Error(codes.Unavailable, "down")) {
+		t.Error("error after the caller gave up is transient")
+	}
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MsgRecvPacketTypeURL is the type URL of the message relayers submit to deliver IBC packets
+const MsgRecvPacketTypeURL = "/ibc.core.channel.v1.MsgRecvPacket"
+
+// transferPort is the port bound by the ICS-20 transfer module
+const transferPort = "transfer"
+
+// Packet is the part of an IBC packet the rebalancer reads
+type Packet struct {
+	Sequence      uint64
+	SourcePort    string
+	SourceChannel string
+	DestPort      string
+	DestChannel   string
+	Data          []byte
+}
+
+// FungibleTokenPacketData is the ICS-20 transfer packet payload
+type FungibleTokenPacketData struct {
+	Denom    string `json:"denom"`
+	Amount   string `json:"amount"`
+	Sender   string `json:"sender"`
+	Receiver string `json:"receiver"`
+	Memo     string `json:"memo,omitempty"`
+}
+
+// ReceivedDenom returns the denom the receiving chain credits for a packet: the native denom for
+// tokens returning through the channel they left by, otherwise the ibc/ voucher denom
+func (p *Packet) ReceivedDenom(denom string) string {
+	prefix := p.SourcePort + "/" + p.SourceChannel + "/"
+	if strings.HasPrefix(denom, prefix) {
+		return strings.TrimPrefix(denom, prefix)
+	}
+	hash := sha256.Sum256([]byte(p.DestPort + "/" + p.DestChannel + "/" + denom))
+	return "ibc/" + strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
+// DecodeRecvPacket decodes the packet of a MsgRecvPacket. Only the packet is read, so the IBC
+// modules are not needed to decode it.
+func DecodeRecvPacket(msg []byte) (*Packet, error) {
+	var packet *Packet
+	err := consumeFields(msg, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		var err error
+		packet, err = decodePacket(value)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if packet == nil {
+		return nil, fmt.Errorf("message has no packet")
+	}
+	return packet, nil
+}
+
+// decodePacket decodes an ibc.core.channel.v1.Packet
+func decodePacket(b []byte) (*Packet, error) {
+	var p Packet
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num == 1 && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(value)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			p.Sequence = v
+			return nil
+		}
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 2:
+			p.SourcePort = string(value)
+		case 3:
+			p.SourceChannel = string(value)
+		case 4:
+			p.DestPort = string(value)
+		case 5:
+			p.DestChannel = string(value)
+		case 6:
+			p.Data = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid packet: %w", err)
+	}
+	return &p, nil
+}
+
+// consumeFields calls field for each field of a protobuf message, with the raw varint or the
+// contents of a length-delimited field as value
+func consumeFields(b []byte, field func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var value []byte
+		switch typ {
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(b)
+			if m < 0 {
+				return protowire.ParseError(m)
+			}
+			value, n = v, m
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			value = b[:n]
+		}
+		if err := field(num, typ, value); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// received reports whether the transaction acknowledged packet successfully. Redundant relays of a
+// packet that was already received write no acknowledgement, and error acknowledgements refund the
+// sender, so neither moved funds.
+func (txn *Transaction) received(packet *Packet) bool {
+	for _, event := range txn.Events {
+		if event.Type != "write_acknowledgement" {
+			continue
+		}
+		attrs := make(map[string]string, len(event.Attributes))
+		for _, attr := range event.Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+		if attrs["packet_dst_channel"] != packet.DestChannel || attrs["packet_sequence"] != strconv.FormatUint(packet.Sequence, 10) {
+			continue
+		}
+		var ack struct {
+			Error string `json:"error"`
+		}
+		return json.Unmarshal([]byte(attrs["packet_ack"]), &ack) == nil && ack.Error == ""
+	}
+	return false
+}
+
+// ibcTransfer turns a received ICS-20 packet with routing metadata in its memo into a transfer.
+// It returns nil for packets of other applications, without routing metadata, or that moved no funds.
+func (txn *Transaction) ibcTransfer(packet *Packet) (*HyperlaneTransfer, error) {
+	if packet.DestPort != transferPort || !txn.received(packet) {
+		return nil, nil
+	}
+	var data FungibleTokenPacketData
+	if err := json.Unmarshal(packet.Data, &data); err != nil {
+		return nil, fmt.Errorf("invalid ICS-20 packet data: %w", err)
+	}
+	if data.Memo == "" {
+		return nil, nil
+	}
+	var meta RoutingMetadata
+	if err := json.Unmarshal([]byte(data.Memo), &meta); err != nil {
+		return nil, nil
+	}
+
+	// As for bank sends, From is the multisig that received the funds
+	return &HyperlaneTransfer{
+		From:              data.Receiver,
+		To:                meta.Recipient,
+		Amount:            data.Amount,
+		Denom:             packet.ReceivedDenom(data.Denom),
+		DestinationDomain: meta.DestinationDomain,
+		TokenID:           meta.TokenID,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// recvPacket encodes a MsgRecvPacket delivering an ICS-20 packet from sourceChannel to channel-9
+func recvPacket(t *testing.T, sequence uint64, sourceChannel string, data FungibleTokenPacketData) *codectypes.Any {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var packet []byte
+	packet = protowire.AppendTag(packet, 1, protowire.VarintType)
+	packet = protowire.AppendVarint(packet, sequence)
+	for i, s := range []string{"transfer", sourceChannel, "transfer", "channel-9"} {
+		packet = protowire.AppendTag(packet, protowire.Number(i+2), protowire.BytesType)
+		packet = protowire.AppendString(packet, s)
+	}
+	packet = protowire.AppendTag(packet, 6, protowire.BytesType)
+	packet = protowire.AppendBytes(packet, raw)
+
+	var msg []byte
+	msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, packet)
+	msg = protowire.AppendTag(msg, 4, protowire.BytesType)
+	msg = protowire.AppendString(msg, "celestia1relayer")
+	return &codectypes.Any{TypeUrl: MsgRecvPacketTypeURL, Value: msg}
+}
+
+// writeAck is the event of a packet acknowledgement written on channel-9
+func writeAck(sequence uint64, ack string) abci.Event {
+	return abci.Event{Type: "write_acknowledgement", Attributes: []abci.EventAttribute{
+		{Key: "packet_sequence", Value: strconv.FormatUint(sequence, 10)},
+		{Key: "packet_dst_channel", Value: "channel-9"},
+		{Key: "packet_ack", Value: ack},
+	}}
+}
+
+func TestExtractIBCTransfers(t *testing.T) {
+	const multisig = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+	memo := `{"destination_domain":1380012617,"recipient":"0x00000000000000000000000000000000000000000000000000000000000000aa","token_id":"0x01"}`
+	deposit := func(denom, amount, memo string) FungibleTokenPacketData {
+		return FungibleTokenPacketData{Denom: denom, Amount: amount, Sender: "osmo1sender", Receiver: multisig, Memo: memo}
+	}
+
+	txn := &Transaction{
+		Hash:        "IBC1",
+		BlockHeight: 100,
+		Tx: &tx.Tx{Body: &tx.TxBody{Messages: []*codectypes.Any{
+			recvPacket(t, 1, "channel-0", deposit("transfer/channel-0/utia", "1000000", memo)), // TIA returning home
+			recvPacket(t, 2, "channel-0", deposit("uosmo", "500", memo)),                       // A foreign token
+			recvPacket(t, 3, "channel-0", deposit("transfer/channel-0/utia", "700", memo)),     // Failed on receipt
+			recvPacket(t, 4, "channel-0", deposit("transfer/channel-0/utia", "800", memo)),     // Already relayed
+			recvPacket(t, 5, "channel-0", deposit("transfer/channel-0/utia", "900", "")),       // No routing memo
+			{TypeUrl: MsgRecvPacketTypeURL, Value: []byte{0x0a, 0x05}},                         // Truncated
+		}}},
+		Events: []abci.Event{
+			writeAck(1, `{"result":"AQ=="}`),
+			writeAck(2, `{"result":"AQ=="}`),
+			writeAck(3, `{"error":"ABCI code 5: insufficient funds"}`),
+			writeAck(5, `{"result":"AQ=="}`),
+		},
+	}
+
+	transfers, err := ExtractHyperlaneTransfers(txn)
+	var decodeErrs DecodeErrors
+	if !errors.As(err, &decodeErrs) || len(decodeErrs) != 1 {
+		t.Fatalf("ExtractHyperlaneTransfers() error = %v, want the truncated message", err)
+	}
+	if len(transfers) != 2 {
+		t.Fatalf("got %d transfers, want the 2 received with routing memos: %+v", len(transfers), transfers)
+	}
+
+	got := transfers[0]
+	if got.From != multisig || got.Amount != "1000000" || got.Denom != "utia" || got.DestinationDomain != 1380012617 || got.TokenID != "0x01" {
+		t.Errorf("returning TIA transfer = %+v", got)
+	}
+	if !strings.HasPrefix(transfers[1].Denom, "ibc/") || len(transfers[1].Denom) != len("ibc/")+64 {
+		t.Errorf("foreign token denom = %s, want an ibc/ voucher", transfers[1].Denom)
+	}
+
+	filtered, err := FilterHyperlaneTransfersToAddress([]*Transaction{txn}, multisig)
+	if err != nil || len(filtered) != 1 {
+		t.Errorf("FilterHyperlaneTransfersToAddress() = %d transactions, %v, want the IBC transaction", len(filtered), err)
+	}
+}
@@ -0,0 +1,23 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	pdtypes "github.com/bcp-innovations/hyperlane-cosmos/x/core/02_post_dispatch/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// QuoteGasPayment queries the payment the interchain gas paymaster igpID charges for delivering a
+// message using gasLimit gas on domain
+func (c *Client) QuoteGasPayment(igpID string, domain uint32, gasLimit uint64) (sdk.Coins, error) {
+	resp, err := c.pdClient.QuoteGasPayment(c.ctx, &pdtypes.QueryQuoteGasPaymentRequest{
+		IgpId:             igpID,
+		DestinationDomain: strconv.FormatUint(uint64(domain), 10),
+		GasLimit:          strconv.FormatUint(gasLimit, 10),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote interchain gas of IGP %s for domain %d: %w", igpID, domain, err)
+	}
+	return resp.GasPayment, nil
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pdtypes "github.com/bcp-innovations/hyperlane-cosmos/x/core/02_post_dispatch/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+)
+
+// fakePostDispatchQuery quotes 2utia per unit of gas to domain 1380012617
+type fakePostDispatchQuery struct {
+	pdtypes.QueryClient
+	requests []*pdtypes.QueryQuoteGasPaymentRequest
+}
+
+func (f *fakePostDispatchQuery) QuoteGasPayment(ctx context.Context, req *pdtypes.QueryQuoteGasPaymentRequest, opts ...grpc.CallOption) (*pdtypes.QueryQuoteGasPaymentResponse, error) {
+	f.requests = append(f.requests, req)
+	if req.DestinationDomain != "1380012617" {
+		return nil, fmt.Errorf("no gas config for domain %s", req.DestinationDomain)
+	}
+	var gas int64
+	fmt.Sscan(req.GasLimit, &gas)
+	return &pdtypes.QueryQuoteGasPaymentResponse{GasPayment: sdk.NewCoins(sdk.NewInt64Coin("utia", 2*gas))}, nil
+}
+
+func TestQuoteGasPayment(t *testing.T) {
+	service := &fakePostDispatchQuery{}
+	c := &Client{pdClient: service, ctx: context.Background()}
+	igp := "0x726f757465725f706f73745f6469737061746368000000040000000000000001"
+
+	payment, err := c.QuoteGasPayment(igp, 1380012617, 200000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.String() != "400000utia" {
+		t.Errorf("expected a payment of 400000utia, got %s", payment)
+	}
+	if req := service.requests[0]; req.IgpId != igp || req.GasLimit != "200000" {
+		t.Errorf("expected the IGP and gas limit in the request, got %+v", req)
+	}
+
+	if _, err := c.QuoteGasPayment(igp, 1, 200000); err == nil {
+		t.Error("expected an error for a domain without a gas config")
+	}
+}
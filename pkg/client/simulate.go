@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+// Account queries the account number and current sequence of address. The public key is only set
+// once the account has signed a transaction.
+func (c *Client) Account(address string) (*authtypes.BaseAccount, error) {
+	resp, err := c.authClient.AccountInfo(c.ctx, &authtypes.QueryAccountInfoRequest{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account %s: %w", address, err)
+	}
+	if resp.Info == nil {
+		return nil, fmt.Errorf("account %s not found", address)
+	}
+	return resp.Info, nil
+}
+
+// SimulateTx runs an encoded transaction through the node's Simulate endpoint against the latest
+// state, without broadcasting it. Simulation runs the ante handler and every message but skips
+// signature verification. A transaction that would fail, e.g. for an insufficient balance or an
+// unknown token, is returned with an error describing the failure.
+func (c *Client) SimulateTx(txBytes []byte) (*tx.SimulateResponse, error) {
+	resp, err := c.txClient.Simulate(c.ctx, &tx.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		return nil, fmt.Errorf("simulation failed: %w", err)
+	}
+	if resp.GasInfo == nil {
+		return nil, fmt.Errorf("simulation returned no gas info")
+	}
+	return resp, nil
+}
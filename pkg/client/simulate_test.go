@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"google.golang.org/grpc"
+)
+
+// fakeSimulateService simulates transactions with a fixed gas use, rejecting the bytes in reject
+type fakeSimulateService struct {
+	tx.ServiceClient
+	reject string
+}
+
+func (f *fakeSimulateService) Simulate(ctx context.Context, req *tx.SimulateRequest, opts ...grpc.CallOption) (*tx.SimulateResponse, error) {
+	if string(req.TxBytes) == f.reject {
+		return nil, fmt.Errorf("rpc error: code = Unknown desc = 0utia is smaller than 1000000utia: insufficient funds")
+	}
+	return &tx.SimulateResponse{GasInfo: &sdk.GasInfo{GasUsed: 123456}, Result: &sdk.Result{}}, nil
+}
+
+// fakeAuthQuery knows a single account
+type fakeAuthQuery struct {
+	authtypes.QueryClient
+}
+
+func (f *fakeAuthQuery) AccountInfo(ctx context.Context, req *authtypes.QueryAccountInfoRequest, opts ...grpc.CallOption) (*authtypes.QueryAccountInfoResponse, error) {
+	if req.Address != "celestia1multisig" {
+		return nil, fmt.Errorf("account %s not found", req.Address)
+	}
+	return &authtypes.QueryAccountInfoResponse{Info: &authtypes.BaseAccount{Address: req.Address, AccountNumber: 12, Sequence: 7}}, nil
+}
+
+func TestSimulateTx(t *testing.T) {
+	c := &Client{txClient: &fakeSimulateService{reject: "broke"}, ctx: context.Background()}
+
+	resp, err := c.SimulateTx([]byte("funded"))
+	if err != nil {
+		t.Fatalf("SimulateTx() error = %v", err)
+	}
+	if resp.GasInfo.GasUsed != 123456 {
+		t.Errorf("gas used = %d, want 123456", resp.GasInfo.GasUsed)
+	}
+	if _, err := c.SimulateTx([]byte("broke")); err == nil {
+		t.Error("SimulateTx() accepted a transaction the node rejects")
+	}
+}
+
+func TestAccount(t *testing.T) {
+	c := &Client{authClient: &fakeAuthQuery{}, ctx: context.Background()}
+
+	account, err := c.Account("celestia1multisig")
+	if err != nil {
+		t.Fatalf("Account() error = %v", err)
+	}
+	if account.AccountNumber != 12 || account.Sequence != 7 {
+		t.Errorf("account number %d and sequence %d, want 12 and 7", account.AccountNumber, account.Sequence)
+	}
+	if _, err := c.Account("celestia1unknown"); err == nil {
+		t.Error("Account() found an unknown account")
+	}
+}
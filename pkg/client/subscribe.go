@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/gorilla/websocket"
+)
+
+// newBlockHeaderQuery subscribes to the header of every new block. Headers are subscribed to rather
+// than whole blocks, which are megabytes large on Celestia when they carry blobs.
+const newBlockHeaderQuery = "tm.event='NewBlockHeader'"
+
+// subscriptionIdleTimeout is how long a subscription may go without a message or ping from the node
+// before the connection is considered dead and re-established
+const subscriptionIdleTimeout = time.Minute
+
+// BlockSubscription follows the blocks a CometBFT node commits through its websocket endpoint,
+// usually ws://host:26657/websocket, and delivers their heights. A dropped connection is
+// re-established with the backoff of the rpc config, so heights may be skipped; consumers must
+// still poll for the blocks they missed.
+type BlockSubscription struct {
+	url           string
+	authorization string
+	dialer        *websocket.Dialer
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	heights chan int64
+	onError func(error)
+}
+
+// cometEvent is a message of a CometBFT subscription: the empty result confirming it, an event or
+// an error
+type cometEvent struct {
+	Result struct {
+		Data struct {
+			Value struct {
+				Header struct {
+					Height string `json:"height"`
+				} `json:"header"`
+			} `json:"value"`
+		} `json:"data"`
+	} `json:"result"`
+	Error *cometError `json:"error"`
+}
+
+// NewBlockSubscription creates a subscription to the new blocks of the CometBFT websocket endpoint
+// at wsURL, a ws:// or wss:// URL, with the TLS settings, credentials and backoff of rpc.
+// Credentials are only sent to wss endpoints, which imply the tls setting. Run starts it.
+func NewBlockSubscription(wsURL string, rpc types.RPCConfig) (*BlockSubscription, error) {
+	if !strings.HasPrefix(wsURL, "ws://") && !strings.HasPrefix(wsURL, "wss://") {
+		return nil, fmt.Errorf("CometBFT websocket endpoint %s is not a ws:// or wss:// URL", wsURL)
+	}
+	if strings.HasPrefix(wsURL, "wss://") {
+		rpc.TLS = true
+	}
+	if err := rpc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rpc config: %w", err)
+	}
+	if rpc.Authorization() != "" && !strings.HasPrefix(wsURL, "wss://") {
+		return nil, fmt.Errorf("credentials require wss, they are not sent to %s", wsURL)
+	}
+	rpc = rpc.WithDefaults()
+	initial, maxBackoff, _ := rpc.Backoff()
+
+	config, err := tlsConfig(rpc)
+	if err != nil {
+		return nil, err
+	}
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = config
+
+	return &BlockSubscription{
+		url:            wsURL,
+		authorization:  rpc.Authorization(),
+		dialer:         &dialer,
+		initialBackoff: initial,
+		maxBackoff:     maxBackoff,
+		heights:        make(chan int64, 1),
+	}, nil
+}
+
+// SetErrorHandler sets a function called with every connection failure, before reconnecting
+func (s *BlockSubscription) SetErrorHandler(fn func(error)) {
+	s.onError = fn
+}
+
+// Heights delivers the height of new blocks. A consumer falling behind only receives the latest
+// height, not every height it missed.
+func (s *BlockSubscription) Heights() <-chan int64 {
+	return s.heights
+}
+
+// Run keeps the subscription open until ctx is cancelled, reconnecting whenever it fails
+func (s *BlockSubscription) Run(ctx context.Context) {
+	backoff := s.initialBackoff
+	for {
+		subscribed, err := s.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if subscribed {
+			backoff = s.initialBackoff
+		}
+		if s.onError != nil {
+			s.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, s.maxBackoff)
+	}
+}
+
+// subscribe connects, subscribes and delivers heights until the connection fails. It reports
+// whether the node confirmed the subscription before then.
+func (s *BlockSubscription) subscribe(ctx context.Context) (bool, error) {
+	header := http.Header{}
+	if s.authorization != "" {
+		header.Set("Authorization", s.authorization)
+	}
+	conn, _, err := s.dialer.DialContext(ctx, s.url, header)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to %s: %w", s.url, err)
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks the read below when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	// Pings from the node keep an idle connection alive; without them it is considered dead
+	conn.SetReadDeadline(time.Now().Add(subscriptionIdleTimeout))
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(subscriptionIdleTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(10*time.Second))
+	})
+
+	request := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "subscribe",
+		"params":  map[string]string{"query": newBlockHeaderQuery},
+	}
+	if err := conn.WriteJSON(request); err != nil {
+		return false, fmt.Errorf("failed to subscribe to %s: %w", s.url, err)
+	}
+
+	subscribed := false
+	for {
+		var event cometEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			return subscribed, fmt.Errorf("subscription to %s failed: %w", s.url, err)
+		}
+		if event.Error != nil {
+			return subscribed, fmt.Errorf("failed to subscribe to %s: %w", s.url, event.Error)
+		}
+		subscribed = true
+		conn.SetReadDeadline(time.Now().Add(subscriptionIdleTimeout))
+
+		// The confirmation of the subscription carries no header
+		if event.Result.Data.Value.Header.Height == "" {
+			continue
+		}
+		height, err := strconv.ParseInt(event.Result.Data.Value.Header.Height, 10, 64)
+		if err != nil {
+			return subscribed, fmt.Errorf("invalid height %q in new block event from %s", event.Result.Data.Value.Header.Height, s.url)
+		}
+		s.deliver(height)
+	}
+}
+
+// deliver hands height to the consumer, replacing a height it has not received yet
+func (s *BlockSubscription) deliver(height int64) {
+	for {
+		select {
+		case s.heights <- height:
+			return
+		default:
+		}
+		select {
+		case <-s.heights:
+		default:
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/gorilla/websocket"
+)
+
+// fakeCometWebsocket confirms every subscription and sends the headers of heights, then closes the
+// connection
+type fakeCometWebsocket struct {
+	heights     []int64
+	connections atomic.Int32
+	query       atomic.Value
+}
+
+func (f *fakeCometWebsocket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.connections.Add(1)
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var request struct {
+		Method string            `json:"method"`
+		Params map[string]string `json:"params"`
+	}
+	if err := conn.ReadJSON(&request); err != nil {
+		return
+	}
+	f.query.Store(request.Params["query"])
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+	for _, height := range f.heights {
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":1,"result":{"query":"tm.event='NewBlockHeader'","data":{"type":"tendermint/event/NewBlockHeader","value":{"header":{"height":"%d"}}}}}`, height)))
+	}
+}
+
+func TestBlockSubscription(t *testing.T) {
+	node := &fakeCometWebsocket{heights: []int64{41, 42}}
+	server := httptest.NewServer(node)
+	defer server.Close()
+
+	sub, err := NewBlockSubscription("ws"+strings.TrimPrefix(server.URL, "http"), types.RPCConfig{InitialBackoff: "1ms", MaxBackoff: "1ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failures atomic.Int32
+	sub.SetErrorHandler(func(err error) { failures.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sub.Run(ctx)
+		close(done)
+	}()
+
+	// The connection closes after every batch of heights and is re-established
+	deadline := time.After(5 * time.Second)
+	for seen := 0; seen < 3; {
+		select {
+		case height := <-sub.Heights():
+			if height != 41 && height != 42 {
+				t.Fatalf("unexpected height %d", height)
+			}
+			seen++
+		case <-deadline:
+			t.Fatal("timed out waiting for heights")
+		}
+	}
+	cancel()
+	<-done
+
+	if node.connections.Load() < 2 || failures.Load() < 1 {
+		t.Errorf("expected reconnections after failures, got %d connections and %d failures", node.connections.Load(), failures.Load())
+	}
+	if query := node.query.Load(); query != newBlockHeaderQuery {
+		t.Errorf("subscribed to %v, want %s", query, newBlockHeaderQuery)
+	}
+}
+
+func TestBlockSubscriptionDeliversLatest(t *testing.T) {
+	sub := &BlockSubscription{heights: make(chan int64, 1)}
+	for _, height := range []int64{1, 2, 3} {
+		sub.deliver(height)
+	}
+	if height := <-sub.Heights(); height != 3 {
+		t.Errorf("expected the latest height 3, got %d", height)
+	}
+}
+
+func TestNewBlockSubscription(t *testing.T) {
+	if _, err := NewBlockSubscription("http://localhost:26657/websocket", types.RPCConfig{}); err == nil {
+		t.Error("expected an error for a URL without a websocket scheme")
+	}
+	if _, err := NewBlockSubscription("ws://localhost:26657/websocket", types.RPCConfig{TLS: true, BearerToken: "secret"}); err == nil {
+		t.Error("expected credentials to be refused over ws")
+	}
+	if _, err := NewBlockSubscription("wss://rpc.example.com/websocket", types.RPCConfig{BearerToken: "secret"}); err != nil {
+		t.Errorf("unexpected error over wss: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+)
+
+// syntheticDenomPrefix prefixes the denoms minted by synthetic warp tokens, followed by the token ID
+const syntheticDenomPrefix = "hyperlane/"
+
+// GetWarpTokens queries all warp tokens registered on the chain
+func (c *Client) GetWarpTokens() ([]warptypes.WrappedHypToken, error) {
+	var tokens []warptypes.WrappedHypToken
+	var nextKey []byte
+
+	for {
+		resp, err := c.warpClient.Tokens(c.ctx, &warptypes.QueryTokensRequest{
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query warp tokens: %w", err)
+		}
+
+		tokens = append(tokens, resp.Tokens...)
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+
+	return tokens, nil
+}
+
+// EnrolledDomains queries the domains warp token tokenID has a remote router enrolled for, i.e.
+// the domains it can transfer to
+func (c *Client) EnrolledDomains(tokenID string) ([]uint32, error) {
+	var domains []uint32
+	var nextKey []byte
+
+	for {
+		resp, err := c.warpClient.RemoteRouters(c.ctx, &warptypes.QueryRemoteRoutersRequest{
+			Id:         tokenID,
+			Pagination: &query.PageRequest{Key: nextKey},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query remote routers of token %s: %w", tokenID, err)
+		}
+
+		for _, router := range resp.RemoteRouters {
+			domains = append(domains, router.ReceiverDomain)
+		}
+
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = resp.Pagination.NextKey
+	}
+
+	return domains, nil
+}
+
+// WarpTokensByDenom returns the warp tokens that transfer denom: collateral tokens locking it and
+// the synthetic token minting it. Several collateral tokens may exist for the same denom.
+func (c *Client) WarpTokensByDenom(denom string) ([]warptypes.WrappedHypToken, error) {
+	tokens, err := c.GetWarpTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []warptypes.WrappedHypToken
+	for _, token := range tokens {
+		if token.OriginDenom == denom || syntheticDenomPrefix+token.Id == denom {
+			matches = append(matches, token)
+		}
+	}
+	return matches, nil
+}
+
+// TokenDenom returns the denom a transfer of token debits from its sender: the locked denom of a
+// collateral token, or the denom a synthetic token mints
+func TokenDenom(token warptypes.WrappedHypToken) string {
+	if token.OriginDenom != "" {
+		return token.OriginDenom
+	}
+	return syntheticDenomPrefix + token.Id
+}
+
+// QuoteRemoteTransfer queries the interchain gas payment the hooks of warp token tokenID charge for
+// a transfer to domain
+func (c *Client) QuoteRemoteTransfer(tokenID string, domain uint32) (sdk.Coins, error) {
+	resp, err := c.warpClient.QuoteRemoteTransfer(c.ctx, &warptypes.QueryQuoteRemoteTransferRequest{
+		Id:                tokenID,
+		DestinationDomain: strconv.FormatUint(uint64(domain), 10),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote transfer of token %s to domain %d: %w", tokenID, domain, err)
+	}
+	return resp.GasPayment, nil
+}
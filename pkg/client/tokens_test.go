@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc"
+)
+
+// fakeWarpQuery serves Tokens one token per page
+type fakeWarpQuery struct {
+	warptypes.QueryClient
+	tokens []warptypes.WrappedHypToken
+}
+
+func (f *fakeWarpQuery) Tokens(ctx context.Context, req *warptypes.QueryTokensRequest, opts ...grpc.CallOption) (*warptypes.QueryTokensResponse, error) {
+	i := 0
+	if req.Pagination != nil && len(req.Pagination.Key) > 0 {
+		i = int(req.Pagination.Key[0])
+	}
+	resp := &warptypes.QueryTokensResponse{Tokens: f.tokens[i : i+1]}
+	if i+1 < len(f.tokens) {
+		resp.Pagination = &query.PageResponse{NextKey: []byte{byte(i + 1)}}
+	}
+	return resp, nil
+}
+
+func TestWarpTokensByDenom(t *testing.T) {
+	collateral := "0x726f757465725f61707000000000000000000000000000010000000000000000"
+	synthetic := "0x726f757465725f61707000000000000000000000000000010000000000000001"
+	service := &fakeWarpQuery{tokens: []warptypes.WrappedHypToken{
+		{Id: collateral, OriginDenom: "utia"},
+		{Id: synthetic, OriginDenom: ""},
+	}}
+	c := &Client{warpClient: service, ctx: context.Background()}
+
+	tokens, err := c.WarpTokensByDenom("utia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].Id != collateral {
+		t.Errorf("expected the collateral token for utia, got %v", tokens)
+	}
+
+	tokens, err = c.WarpTokensByDenom("hyperlane/" + synthetic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].Id != synthetic {
+		t.Errorf("expected the synthetic token for its denom, got %v", tokens)
+	}
+
+	tokens, err = c.WarpTokensByDenom("uatom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 0 {
+		t.Errorf("expected no tokens for uatom, got %v", tokens)
+	}
+}
+
+func TestTokenDenom(t *testing.T) {
+	id := "0x726f757465725f61707000000000000000000000000000010000000000000001"
+	if got := TokenDenom(warptypes.WrappedHypToken{Id: id, OriginDenom: "utia"}); got != "utia" {
+		t.Errorf("TokenDenom(collateral) = %s, want utia", got)
+	}
+	if got := TokenDenom(warptypes.WrappedHypToken{Id: id}); got != "hyperlane/"+id {
+		t.Errorf("TokenDenom(synthetic) = %s, want hyperlane/%s", got, id)
+	}
+}
+
+// fakeQuoteQuery quotes a fixed payment per destination domain
+type fakeQuoteQuery struct {
+	warptypes.QueryClient
+	payments map[string]sdk.Coins
+}
+
+func (f *fakeQuoteQuery) QuoteRemoteTransfer(ctx context.Context, req *warptypes.QueryQuoteRemoteTransferRequest, opts ...grpc.CallOption) (*warptypes.QueryQuoteRemoteTransferResponse, error) {
+	payment, ok := f.payments[req.DestinationDomain]
+	if !ok {
+		return nil, fmt.Errorf("no route to domain %s", req.DestinationDomain)
+	}
+	return &warptypes.QueryQuoteRemoteTransferResponse{GasPayment: payment}, nil
+}
+
+func TestQuoteRemoteTransfer(t *testing.T) {
+	service := &fakeQuoteQuery{payments: map[string]sdk.Coins{"1380012617": sdk.NewCoins(sdk.NewInt64Coin("utia", 4000))}}
+	c := &Client{warpClient: service, ctx: context.Background()}
+
+	payment, err := c.QuoteRemoteTransfer("0x726f757465725f61707000000000000000000000000000010000000000000000", 1380012617)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.String() != "4000utia" {
+		t.Errorf("expected a payment of 4000utia, got %s", payment)
+	}
+
+	if _, err := c.QuoteRemoteTransfer("0x726f757465725f61707000000000000000000000000000010000000000000000", 1); err == nil {
+		t.Error("expected an error for a domain without a route")
+	}
+}
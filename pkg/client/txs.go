@@ -0,0 +1,97 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// GetTxs queries many included transactions by hash, with up to the configured concurrency of
+// queries in flight. The responses are in the order of hashes. If any query fails, the errors of
+// all failed queries are returned together.
+func (c *Client) GetTxs(hashes []string) ([]*sdk.TxResponse, error) {
+	responses := make([]*sdk.TxResponse, len(hashes))
+	errs := make([]error, len(hashes))
+
+	concurrency := c.query.WithDefaults().Concurrency
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, hash := range hashes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			responses[i], errs[i] = c.GetTx(hash)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// GetTransactions queries and decodes many included transactions by hash, in the order of hashes
+func (c *Client) GetTransactions(hashes []string) ([]*Transaction, error) {
+	responses, err := c.GetTxs(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*Transaction
+	for _, txResp := range responses {
+		if txn := decodeTransaction(txResp, txResp.Height); txn != nil {
+			txs = append(txs, txn)
+		}
+	}
+	return txs, nil
+}
+
+// BankSendsSince returns the hashes of the successful transactions from minHeight on with a bank
+// MsgSend from one address to another, e.g. a multisig refunding a depositor
+func (c *Client) BankSendsSince(from, to string, minHeight int64) ([]string, error) {
+	query := fmt.Sprintf("transfer.sender='%s' AND transfer.recipient='%s' AND tx.height>=%d", from, to, minHeight)
+
+	var hashes []string
+	fetched := uint64(0)
+	for page := uint64(1); ; page++ {
+		if page > uint64(c.query.MaxPagesPerHeight) {
+			return nil, fmt.Errorf("transfers from %s to %s span more than %d pages of %d transactions", from, to, c.query.MaxPagesPerHeight, c.query.PageSize)
+		}
+
+		resp, err := c.txClient.GetTxsEvent(c.ctx, &tx.GetTxsEventRequest{
+			Query:   query,
+			OrderBy: tx.OrderBy_ORDER_BY_ASC,
+			Page:    page,
+			Limit:   c.query.PageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query transfers from %s to %s: %w", from, to, err)
+		}
+		fetched += uint64(len(resp.TxResponses))
+
+		for _, txResp := range resp.TxResponses {
+			txn := decodeTransaction(txResp, txResp.Height)
+			if txn == nil || txn.Code != 0 || txn.Tx == nil {
+				continue
+			}
+			sends, _ := ExtractBankSends(txn)
+			for _, send := range sends {
+				if send.From == from && send.To == to {
+					hashes = append(hashes, txn.Hash)
+					break
+				}
+			}
+		}
+
+		if !morePages(resp, fetched, c.query.PageSize) {
+			break
+		}
+	}
+	return hashes, nil
+}
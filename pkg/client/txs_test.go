@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"google.golang.org/grpc"
+)
+
+// fakeTxService serves GetTx from a map of transactions, recording the peak number of concurrent queries
+type fakeTxService struct {
+	tx.ServiceClient
+	txs map[string]*sdk.TxResponse
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	release  chan struct{}
+}
+
+func (f *fakeTxService) GetTx(ctx context.Context, req *tx.GetTxRequest, opts ...grpc.CallOption) (*tx.GetTxResponse, error) {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.peak {
+		f.peak = f.inFlight
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+	if f.release != nil {
+		<-f.release
+	}
+
+	resp, ok := f.txs[req.Hash]
+	if !ok {
+		return nil, fmt.Errorf("tx not found")
+	}
+	return &tx.GetTxResponse{TxResponse: resp}, nil
+}
+
+func TestGetTxs(t *testing.T) {
+	body, err := (&tx.Tx{Body: &tx.TxBody{Memo: "rebalance"}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := &fakeTxService{txs: make(map[string]*sdk.TxResponse)}
+	var hashes []string
+	for i := 0; i < 20; i++ {
+		hash := fmt.Sprintf("HASH%02d", i)
+		hashes = append(hashes, strings.ToLower(hash))
+		service.txs[hash] = &sdk.TxResponse{TxHash: hash, Height: int64(100 + i), Tx: &codectypes.Any{TypeUrl: "/cosmos.tx.v1beta1.Tx", Value: body}}
+	}
+
+	// Hold every query until the bounded number are in flight
+	service.release = make(chan struct{})
+	go func() {
+		for range hashes {
+			service.release <- struct{}{}
+		}
+	}()
+
+	c := &Client{txClient: service, ctx: context.Background(), query: types.QueryConfig{Concurrency: 4}}
+	txs, err := c.GetTransactions(hashes)
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+	if len(txs) != len(hashes) {
+		t.Fatalf("got %d transactions, want %d", len(txs), len(hashes))
+	}
+	for i, txn := range txs {
+		if txn.Hash != fmt.Sprintf("HASH%02d", i) || txn.BlockHeight != int64(100+i) || txn.Memo != "rebalance" {
+			t.Errorf("transaction %d = %+v, want hashes in order", i, txn)
+		}
+	}
+	if service.peak > 4 {
+		t.Errorf("peak concurrency = %d, want at most 4", service.peak)
+	}
+}
+
+func TestGetTxsErrors(t *testing.T) {
+	service := &fakeTxService{txs: map[string]*sdk.TxResponse{"A": {TxHash: "A"}}}
+	c := &Client{txClient: service, ctx: context.Background()}
+
+	_, err := c.GetTxs([]string{"a", "b", "c"})
+	if err == nil {
+		t.Fatal("GetTxs() expected error for missing transactions")
+	}
+	for _, hash := range []string{"tx b", "tx c"} {
+		if !strings.Contains(err.Error(), hash) {
+			t.Errorf("error %q does not mention %s", err, hash)
+		}
+	}
+}
+
+func TestBankSendsSince(t *testing.T) {
+	const multisig, depositor = "celestia1multisig", "celestia1depositor"
+	send := func(hash, to string, code uint32) *sdk.TxResponse {
+		anyMsg, err := codectypes.NewAnyWithValue(&banktypes.MsgSend{FromAddress: multisig, ToAddress: to, Amount: sdk.NewCoins(sdk.NewInt64Coin("utia", 100))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := (&tx.Tx{Body: &tx.TxBody{Messages: []*codectypes.Any{anyMsg}}}).Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &sdk.TxResponse{TxHash: hash, Height: 200, Code: code, Tx: &codectypes.Any{TypeUrl: "/cosmos.tx.v1beta1.Tx", Value: body}}
+	}
+
+	// The event query also matches transfers that are not a MsgSend to the depositor, e.g. failed ones
+	service := &fakeTxsEventService{maxLimit: 1, txs: []*sdk.TxResponse{
+		send("REFUND", depositor, 0),
+		send("FAILED", depositor, 5),
+		send("OTHER", "celestia1other", 0),
+	}}
+	c := &Client{txClient: service, ctx: context.Background()}
+	c.SetQueryConfig(types.QueryConfig{PageSize: 1})
+
+	hashes, err := c.BankSendsSince(multisig, depositor, 100)
+	if err != nil {
+		t.Fatalf("BankSendsSince() error = %v", err)
+	}
+	if len(hashes) != 1 || hashes[0] != "REFUND" {
+		t.Errorf("BankSendsSince() = %v, want [REFUND]", hashes)
+	}
+	if service.pages != 3 {
+		t.Errorf("queried %d pages, want 3", service.pages)
+	}
+}
@@ -0,0 +1,72 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// AvailableCollateral returns what the destination router can pay out, in destination units.
+// Synthetic routers mint on arrival, so ok is false for them.
+func AvailableCollateral(ctx context.Context, config types.DestinationConfig) (available math.Int, ok bool, err error) {
+	client := NewEVMClient(config.RPCURL)
+
+	switch config.RouterType {
+	case types.RouterCollateral:
+		token, err := client.WrappedToken(ctx, config.Router)
+		if err != nil {
+			return math.Int{}, false, err
+		}
+		balance, err := client.BalanceOf(ctx, token, config.Router)
+		return balance, err == nil, err
+	case types.RouterNative:
+		balance, err := client.Balance(ctx, config.Router)
+		if err != nil {
+			return math.Int{}, false, fmt.Errorf("failed to query balance of %s: %w", config.Router, err)
+		}
+		return balance, true, nil
+	default:
+		return math.Int{}, false, nil
+	}
+}
+
+// CheckCollateral compares the transfers to a destination domain against the collateral its
+// router holds. It warns for every transfer that exceeds the collateral on its own and when all
+// transfers together exceed it, since such transfers arrive but remain unredeemable.
+func CheckCollateral(ctx context.Context, domain uint32, config types.DestinationConfig, routes []types.HyperlaneRoute) ([]string, error) {
+	available, ok, err := AvailableCollateral(ctx, config)
+	if err != nil || !ok {
+		return nil, err
+	}
+	scale, err := config.ScaleFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	total := math.ZeroInt()
+	for _, route := range routes {
+		if route.RouteInfo == nil || route.RouteInfo.DestinationDomain != domain {
+			continue
+		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+
+		scaled := amount.Mul(scale)
+		total = total.Add(scaled)
+		if scaled.GT(available) {
+			warnings = append(warnings, fmt.Sprintf("domain %d: transfer of %s from tx %s exceeds the router's available collateral of %s",
+				domain, scaled, route.TxHash, available))
+		}
+	}
+
+	if total.GT(available) && len(warnings) == 0 {
+		warnings = append(warnings, fmt.Sprintf("domain %d: transfers totalling %s exceed the router's available collateral of %s",
+			domain, total, available))
+	}
+	return warnings, nil
+}
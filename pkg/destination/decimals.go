@@ -0,0 +1,61 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// NativeDecimals are the decimals of the native token of EVM chains
+const NativeDecimals = 18
+
+// TokenDecimals returns the decimals of the token the destination router pays out: the router's
+// own for synthetic routers, the wrapped ERC-20's for collateral routers and the native token's
+// for native routers
+func TokenDecimals(ctx context.Context, config types.DestinationConfig) (uint32, error) {
+	client := NewEVMClient(config.RPCURL)
+
+	switch config.RouterType {
+	case types.RouterCollateral:
+		token, err := client.WrappedToken(ctx, config.Router)
+		if err != nil {
+			return 0, err
+		}
+		return client.Decimals(ctx, token)
+	case types.RouterNative:
+		return NativeDecimals, nil
+	default:
+		return client.Decimals(ctx, config.Router)
+	}
+}
+
+// CheckDecimals compares the decimals of the token the destination router pays out with the
+// transferred denom's display decimals times the configured scale. A mismatch means transfers
+// arrive off by powers of ten. Destinations without rpc_url, router or display decimals are not
+// checked.
+func CheckDecimals(ctx context.Context, domain uint32, config types.DestinationConfig) (string, error) {
+	if config.RPCURL == "" || config.Router == "" || config.Display == nil || config.Display.Decimals == 0 {
+		return "", nil
+	}
+	scale, err := config.ScaleFactor()
+	if err != nil {
+		return "", err
+	}
+
+	decimals, err := TokenDecimals(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	if decimals < config.Display.Decimals {
+		return fmt.Sprintf("domain %d: router token has %d decimals, fewer than the %d of the transferred denom",
+			domain, decimals, config.Display.Decimals), nil
+	}
+	want := math.NewIntWithDecimal(1, int(decimals-config.Display.Decimals))
+	if !scale.Equal(want) {
+		return fmt.Sprintf("domain %d: router token has %d decimals and the transferred denom %d, so scale should be %s, not %s",
+			domain, decimals, config.Display.Decimals, want, scale), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,17 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// MessageDelivered reports whether the message with messageID was delivered on the destination,
+// by asking its mailbox
+func MessageDelivered(ctx context.Context, config types.DestinationConfig, messageID string) (bool, error) {
+	if config.RPCURL == "" || config.Mailbox == "" {
+		return false, fmt.Errorf("destination has no rpc_url and mailbox to confirm deliveries")
+	}
+	return NewEVMClient(config.RPCURL).Delivered(ctx, config.Mailbox, messageID)
+}
@@ -0,0 +1,271 @@
+package destination
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+const (
+	testRouter = "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	testISM    = "0x1234567890123456789012345678901234567890"
+)
+
+// fakeEVM serves eth_call results keyed by the 4-byte selector in the call data, and the results
+// of other methods keyed by the method name
+func fakeEVM(t *testing.T, results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+
+		key := req.Method
+		if req.Method == "eth_call" {
+			var call struct {
+				To   string `json:"to"`
+				Data string `json:"data"`
+			}
+			json.Unmarshal(req.Params[0], &call)
+			key = call.Data[:10]
+		}
+
+		result, ok := results[key]
+		if !ok {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, result)
+	}))
+}
+
+// word left-pads hex to a 32-byte ABI word
+func word(hexValue string) string {
+	return "0x" + strings.Repeat("0", 64-len(hexValue)) + hexValue
+}
+
+func TestCheckISM(t *testing.T) {
+	server := fakeEVM(t, map[string]string{
+		"0xde523cf3": word(strings.TrimPrefix(testISM, "0x")),
+		"0x6465e69f": word("03"),
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		expected    string
+		wantWarning bool
+		wantErr     bool
+	}{
+		{name: "matching address", expected: testISM},
+		{name: "matching address and type", expected: strings.ToUpper(testISM[:2]) + testISM[2:] + ":3"},
+		{name: "changed address", expected: "0x0000000000000000000000000000000000000001", wantWarning: true},
+		{name: "changed type", expected: testISM + ":6", wantWarning: true},
+		{name: "not configured", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := types.DestinationConfig{RPCURL: server.URL, Router: testRouter, ExpectedISM: tt.expected}
+			warning, err := CheckISM(context.Background(), 2340, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckISM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("CheckISM() warning = %q, wantWarning %v", warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestCheckISMCallFails(t *testing.T) {
+	server := fakeEVM(t, map[string]string{})
+	defer server.Close()
+
+	config := types.DestinationConfig{RPCURL: server.URL, Router: testRouter, ExpectedISM: testISM}
+	if _, err := CheckISM(context.Background(), 2340, config); err == nil {
+		t.Error("CheckISM() expected error for a reverted call")
+	}
+}
+
+func TestCheckCollateral(t *testing.T) {
+	token := "0x00000000000000000000000000000000000000aa"
+	server := fakeEVM(t, map[string]string{
+		"0x996c6cc3":     word(strings.TrimPrefix(token, "0x")),
+		"0x70a08231":     word(fmt.Sprintf("%x", 250_000)),
+		"eth_getBalance": "0x3e8", // 1000
+	})
+	defer server.Close()
+
+	routes := []types.HyperlaneRoute{
+		{TxHash: "A1", Amount: "200", RouteInfo: &types.RouteInfo{DestinationDomain: 2340}},
+		{TxHash: "A2", Amount: "100", RouteInfo: &types.RouteInfo{DestinationDomain: 2340}},
+		{TxHash: "B1", Amount: "900", RouteInfo: &types.RouteInfo{DestinationDomain: 1}},
+	}
+
+	tests := []struct {
+		name         string
+		config       types.DestinationConfig
+		domain       uint32
+		wantWarnings int
+	}{
+		{name: "collateral total exceeded", config: types.DestinationConfig{RouterType: types.RouterCollateral, Scale: "1000"}, domain: 2340, wantWarnings: 1},
+		{name: "collateral sufficient", config: types.DestinationConfig{RouterType: types.RouterCollateral, Scale: "100"}, domain: 2340},
+		{name: "single transfer exceeds", config: types.DestinationConfig{RouterType: types.RouterCollateral, Scale: "2000"}, domain: 2340, wantWarnings: 1},
+		{name: "native sufficient", config: types.DestinationConfig{RouterType: types.RouterNative}, domain: 1},
+		{name: "native exceeded", config: types.DestinationConfig{RouterType: types.RouterNative, Scale: "2"}, domain: 1, wantWarnings: 1},
+		{name: "synthetic", config: types.DestinationConfig{RouterType: types.RouterSynthetic, Scale: "1000000"}, domain: 2340},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.RPCURL = server.URL
+			tt.config.Router = testRouter
+
+			warnings, err := CheckCollateral(context.Background(), tt.domain, tt.config, routes)
+			if err != nil {
+				t.Fatalf("CheckCollateral() error = %v", err)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("CheckCollateral() warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestHandleCalldata(t *testing.T) {
+	msg := &warptypes.MsgRemoteTransfer{Amount: math.NewInt(5)}
+	msg.Recipient[31] = 0xee
+	msg.TokenId[31] = 0xdd
+
+	data := handleCalldata(1128614981, msg, math.NewInt(5000))
+	if len(data) != 4+32*4+64 {
+		t.Fatalf("calldata is %d bytes, want %d", len(data), 4+32*4+64)
+	}
+	got := hex.EncodeToString(data)
+	want := "56d5d475" +
+		word("43454c45")[2:] + // origin domain 1128614981
+		word("dd")[2:] + // sender: the origin token
+		word("60")[2:] + // offset of the message
+		word("40")[2:] + // message length
+		word("ee")[2:] + // recipient
+		word("1388")[2:] // amount 5000
+	if got != want {
+		t.Errorf("calldata = %s, want %s", got, want)
+	}
+}
+
+func TestCheckDeliveryGas(t *testing.T) {
+	server := fakeEVM(t, map[string]string{"eth_estimateGas": "0x249f0"}) // 150000
+	defer server.Close()
+
+	msgs := []*warptypes.MsgRemoteTransfer{
+		{DestinationDomain: 2340, Amount: math.NewInt(100)},
+		{DestinationDomain: 2340, Amount: math.NewInt(100), GasLimit: math.NewInt(200000)},
+		{DestinationDomain: 1, Amount: math.NewInt(100)},
+	}
+
+	tests := []struct {
+		name         string
+		config       types.DestinationConfig
+		wantWarnings int
+	}{
+		{name: "underfunded by configured gas", config: types.DestinationConfig{GasPerTransfer: 100000}, wantWarnings: 1},
+		{name: "funded", config: types.DestinationConfig{GasPerTransfer: 150000}},
+		{name: "nothing to compare against", config: types.DestinationConfig{}, wantWarnings: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.RPCURL = server.URL
+			tt.config.Router = testRouter
+			tt.config.Mailbox = testISM
+
+			warnings, err := CheckDeliveryGas(context.Background(), 2340, 1128614981, tt.config, msgs)
+			if err != nil {
+				t.Fatalf("CheckDeliveryGas() error = %v", err)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("CheckDeliveryGas() warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+
+	config := types.DestinationConfig{RPCURL: server.URL, Router: testRouter, Mailbox: testISM}
+	if _, err := CheckDeliveryGas(context.Background(), 2340, 0, config, msgs); err == nil {
+		t.Error("CheckDeliveryGas() expected error without an origin domain")
+	}
+}
+
+func TestCheckDecimals(t *testing.T) {
+	token := "0x00000000000000000000000000000000000000aa"
+	server := fakeEVM(t, map[string]string{
+		"0x996c6cc3": word(strings.TrimPrefix(token, "0x")),
+		"0x313ce567": word("12"), // 18
+	})
+	defer server.Close()
+
+	display := &types.DisplayConfig{Symbol: "TIA", Decimals: 6}
+	tests := []struct {
+		name        string
+		config      types.DestinationConfig
+		wantWarning bool
+	}{
+		{name: "synthetic matching", config: types.DestinationConfig{Scale: "1000000000000", Display: display}},
+		{name: "synthetic missing scale", config: types.DestinationConfig{Display: display}, wantWarning: true},
+		{name: "collateral matching", config: types.DestinationConfig{RouterType: types.RouterCollateral, Scale: "1000000000000", Display: display}},
+		{name: "native wrong scale", config: types.DestinationConfig{RouterType: types.RouterNative, Scale: "1000000", Display: display}, wantWarning: true},
+		{name: "fewer decimals", config: types.DestinationConfig{Display: &types.DisplayConfig{Symbol: "X", Decimals: 24}}, wantWarning: true},
+		{name: "no display decimals", config: types.DestinationConfig{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.RPCURL = server.URL
+			tt.config.Router = testRouter
+
+			warning, err := CheckDecimals(context.Background(), 2340, tt.config)
+			if err != nil {
+				t.Fatalf("CheckDecimals() error = %v", err)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("CheckDecimals() warning = %q, wantWarning %v", warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestMessageDelivered(t *testing.T) {
+	server := fakeEVM(t, map[string]string{"0xe495f1d4": word("1")})
+	defer server.Close()
+
+	config := types.DestinationConfig{RPCURL: server.URL, Mailbox: testRouter}
+	messageID := "0x" + strings.Repeat("ab", 32)
+	delivered, err := MessageDelivered(context.Background(), config, messageID)
+	if err != nil {
+		t.Fatalf("MessageDelivered() error = %v", err)
+	}
+	if !delivered {
+		t.Error("MessageDelivered() = false, want true")
+	}
+
+	if _, err := MessageDelivered(context.Background(), config, "0x1234"); err == nil {
+		t.Error("MessageDelivered() accepted a short message ID")
+	}
+	if _, err := MessageDelivered(context.Background(), types.DestinationConfig{RPCURL: server.URL}, messageID); err == nil {
+		t.Error("MessageDelivered() accepted a destination without a mailbox")
+	}
+}
@@ -0,0 +1,225 @@
+package destination
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// Function selectors of the contract calls made against destination chains
+var (
+	selectorInterchainSecurityModule = []byte{0xde, 0x52, 0x3c, 0xf3} // interchainSecurityModule()
+	selectorModuleType               = []byte{0x64, 0x65, 0xe6, 0x9f} // moduleType()
+	selectorWrappedToken             = []byte{0x99, 0x6c, 0x6c, 0xc3} // wrappedToken()
+	selectorBalanceOf                = []byte{0x70, 0xa0, 0x82, 0x31} // balanceOf(address)
+	selectorDecimals                 = []byte{0x31, 0x3c, 0xe5, 0x67} // decimals()
+	selectorHandle                   = []byte{0x56, 0xd5, 0xd4, 0x75} // handle(uint32,bytes32,bytes)
+	selectorDelivered                = []byte{0xe4, 0x95, 0xf1, 0xd4} // delivered(bytes32)
+)
+
+// EVMClient makes read-only calls against an EVM chain's JSON-RPC endpoint
+type EVMClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewEVMClient creates a client for the JSON-RPC endpoint at url
+func NewEVMClient(url string) *EVMClient {
+	return &EVMClient{url: url, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call performs a JSON-RPC request and decodes its result into result
+func (c *EVMClient) call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	data, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode %s response (status %s): %w", method, resp.Status, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s failed: %s (code %d)", method, rpcResp.Error.Message, rpcResp.Error.Code)
+	}
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// Call executes a read-only contract call at the latest block and returns the ABI-encoded result
+func (c *EVMClient) Call(ctx context.Context, to string, data []byte) ([]byte, error) {
+	params := []interface{}{
+		map[string]string{"to": to, "data": "0x" + hex.EncodeToString(data)},
+		"latest",
+	}
+
+	var result string
+	if err := c.call(ctx, "eth_call", params, &result); err != nil {
+		return nil, err
+	}
+
+	out, err := hex.DecodeString(strings.TrimPrefix(result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth_call result: %w", err)
+	}
+	return out, nil
+}
+
+// callWord calls a function without arguments that returns a single 32-byte ABI word
+func (c *EVMClient) callWord(ctx context.Context, to string, selector []byte) ([]byte, error) {
+	out, err := c.Call(ctx, to, selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("call to %s returned %d bytes, want at least 32", to, len(out))
+	}
+	return out[:32], nil
+}
+
+// InterchainSecurityModule returns the address of the ISM a Hyperlane router uses
+func (c *EVMClient) InterchainSecurityModule(ctx context.Context, router string) (string, error) {
+	word, err := c.callWord(ctx, router, selectorInterchainSecurityModule)
+	if err != nil {
+		return "", fmt.Errorf("failed to query ISM of %s: %w", router, err)
+	}
+	return "0x" + hex.EncodeToString(word[12:]), nil
+}
+
+// ModuleType returns the Hyperlane module type of an ISM
+func (c *EVMClient) ModuleType(ctx context.Context, ism string) (uint8, error) {
+	word, err := c.callWord(ctx, ism, selectorModuleType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query module type of %s: %w", ism, err)
+	}
+	return word[31], nil
+}
+
+// WrappedToken returns the ERC-20 a collateral router holds
+func (c *EVMClient) WrappedToken(ctx context.Context, router string) (string, error) {
+	word, err := c.callWord(ctx, router, selectorWrappedToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to query wrapped token of %s: %w", router, err)
+	}
+	return "0x" + hex.EncodeToString(word[12:]), nil
+}
+
+// BalanceOf returns the ERC-20 balance of owner
+func (c *EVMClient) BalanceOf(ctx context.Context, token, owner string) (math.Int, error) {
+	ownerBytes, err := hex.DecodeString(strings.TrimPrefix(owner, "0x"))
+	if err != nil || len(ownerBytes) != 20 {
+		return math.Int{}, fmt.Errorf("invalid address %s", owner)
+	}
+
+	data := append(append([]byte{}, selectorBalanceOf...), make([]byte, 12)...)
+	data = append(data, ownerBytes...)
+	out, err := c.Call(ctx, token, data)
+	if err != nil {
+		return math.Int{}, fmt.Errorf("failed to query balance of %s: %w", owner, err)
+	}
+	if len(out) < 32 {
+		return math.Int{}, fmt.Errorf("balanceOf returned %d bytes, want 32", len(out))
+	}
+	return math.NewIntFromBigInt(new(big.Int).SetBytes(out[:32])), nil
+}
+
+// Decimals returns the decimals of an ERC-20
+func (c *EVMClient) Decimals(ctx context.Context, token string) (uint32, error) {
+	word, err := c.callWord(ctx, token, selectorDecimals)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query decimals of %s: %w", token, err)
+	}
+	decimals := new(big.Int).SetBytes(word)
+	if !decimals.IsUint64() || decimals.Uint64() > 255 {
+		return 0, fmt.Errorf("decimals of %s out of range: %s", token, decimals)
+	}
+	return uint32(decimals.Uint64()), nil
+}
+
+// Delivered reports whether a Hyperlane mailbox has processed the message with messageID, a
+// 0x-prefixed 32-byte hex ID
+func (c *EVMClient) Delivered(ctx context.Context, mailbox, messageID string) (bool, error) {
+	id, err := hex.DecodeString(strings.TrimPrefix(messageID, "0x"))
+	if err != nil || len(id) != 32 {
+		return false, fmt.Errorf("invalid message ID %s", messageID)
+	}
+
+	data := append(append([]byte{}, selectorDelivered...), id...)
+	out, err := c.Call(ctx, mailbox, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to query delivery of message %s: %w", messageID, err)
+	}
+	if len(out) < 32 {
+		return false, fmt.Errorf("delivered returned %d bytes, want 32", len(out))
+	}
+	return out[31] == 1, nil
+}
+
+// Balance returns the native token balance of addr
+func (c *EVMClient) Balance(ctx context.Context, addr string) (math.Int, error) {
+	var result string
+	if err := c.call(ctx, "eth_getBalance", []interface{}{addr, "latest"}, &result); err != nil {
+		return math.Int{}, err
+	}
+
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok {
+		return math.Int{}, fmt.Errorf("invalid eth_getBalance result %s", result)
+	}
+	return math.NewIntFromBigInt(balance), nil
+}
+
+// EstimateGas estimates the gas of a transaction from one address to a contract
+func (c *EVMClient) EstimateGas(ctx context.Context, from, to string, data []byte) (uint64, error) {
+	params := []interface{}{
+		map[string]string{"from": from, "to": to, "data": "0x" + hex.EncodeToString(data)},
+	}
+
+	var result string
+	if err := c.call(ctx, "eth_estimateGas", params, &result); err != nil {
+		return 0, err
+	}
+
+	gas, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok || !gas.IsUint64() {
+		return 0, fmt.Errorf("invalid eth_estimateGas result %s", result)
+	}
+	return gas.Uint64(), nil
+}
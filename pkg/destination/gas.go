@@ -0,0 +1,98 @@
+package destination
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"cosmossdk.io/math"
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// handleCalldata encodes the router's handle(origin, sender, message) call for a transfer. The
+// sender is the origin warp token and the message is the warp token message: recipient, amount
+// and empty metadata.
+func handleCalldata(origin uint32, msg *warptypes.MsgRemoteTransfer, amount math.Int) []byte {
+	body := make([]byte, 0, 64)
+	body = append(body, msg.Recipient[:]...)
+	body = append(body, abiWord(amount)...)
+
+	data := append([]byte{}, selectorHandle...)
+	var word [32]byte
+	binary.BigEndian.PutUint32(word[28:], origin)
+	data = append(data, word[:]...)
+	data = append(data, msg.TokenId[:]...)
+	// Offset of the dynamic bytes argument, its length, then the padded bytes
+	data = append(data, abiWord(math.NewInt(96))...)
+	data = append(data, abiWord(math.NewInt(int64(len(body))))...)
+	data = append(data, body...)
+	if pad := len(body) % 32; pad != 0 {
+		data = append(data, make([]byte, 32-pad)...)
+	}
+	return data
+}
+
+// abiWord encodes a non-negative integer as a 32-byte ABI word
+func abiWord(v math.Int) []byte {
+	word := make([]byte, 32)
+	b := v.BigInt().Bytes()
+	copy(word[32-len(b):], b)
+	return word
+}
+
+// EstimateHandleGas estimates the destination gas of delivering a transfer, by simulating the
+// mailbox calling the router's handle()
+func EstimateHandleGas(ctx context.Context, config types.DestinationConfig, origin uint32, msg *warptypes.MsgRemoteTransfer) (uint64, error) {
+	scale, err := config.ScaleFactor()
+	if err != nil {
+		return 0, err
+	}
+
+	data := handleCalldata(origin, msg, msg.Amount.Mul(scale))
+	gas, err := NewEVMClient(config.RPCURL).EstimateGas(ctx, config.Mailbox, config.Router, data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate handle gas: %w", err)
+	}
+	return gas, nil
+}
+
+// CheckDeliveryGas estimates the handle() gas of every transfer to the domain and warns when it
+// exceeds the gas paid for: the transfer's gas limit if set, otherwise the domain's
+// gas_per_transfer. Underfunded deliveries are likely to stall at the relayer.
+func CheckDeliveryGas(ctx context.Context, domain, origin uint32, config types.DestinationConfig, msgs []*warptypes.MsgRemoteTransfer) ([]string, error) {
+	if config.Mailbox == "" {
+		return nil, nil
+	}
+	if origin == 0 {
+		return nil, fmt.Errorf("the origin domain is not configured (chain.domain)")
+	}
+
+	var warnings []string
+	for i, msg := range msgs {
+		if msg.DestinationDomain != domain {
+			continue
+		}
+
+		gas, err := EstimateHandleGas(ctx, config, origin, msg)
+		if err != nil {
+			return nil, fmt.Errorf("transfer %d: %w", i+1, err)
+		}
+
+		var limit uint64
+		if !msg.GasLimit.IsNil() && msg.GasLimit.IsPositive() {
+			limit = msg.GasLimit.Uint64()
+		} else {
+			limit = config.GasPerTransfer
+		}
+		switch {
+		case limit == 0:
+			warnings = append(warnings, fmt.Sprintf("domain %d: transfer %d needs ~%d gas on delivery but no gas limit is configured to compare against",
+				domain, i+1, gas))
+		case gas > limit:
+			warnings = append(warnings, fmt.Sprintf("domain %d: transfer %d needs ~%d gas on delivery but only %d is paid for; the delivery is likely to stall",
+				domain, i+1, gas, limit))
+		}
+	}
+	return warnings, nil
+}
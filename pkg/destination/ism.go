@@ -0,0 +1,47 @@
+package destination
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// CheckISM compares the ISM the destination router currently uses against the expected
+// fingerprint in config. It returns a warning describing the difference, or "" if the ISM matches.
+// An error means the ISM could not be determined.
+func CheckISM(ctx context.Context, domain uint32, config types.DestinationConfig) (string, error) {
+	if config.ExpectedISM == "" {
+		return "", nil
+	}
+
+	client := NewEVMClient(config.RPCURL)
+	ism, err := client.InterchainSecurityModule(ctx, config.Router)
+	if err != nil {
+		return "", err
+	}
+
+	expectedAddr, expectedType, hasType := strings.Cut(config.ExpectedISM, ":")
+	if !strings.EqualFold(ism, expectedAddr) {
+		return fmt.Sprintf("domain %d: router %s uses ISM %s, expected %s; the security module may have been changed",
+			domain, config.Router, ism, expectedAddr), nil
+	}
+	if !hasType {
+		return "", nil
+	}
+
+	want, err := strconv.ParseUint(expectedType, 10, 8)
+	if err != nil {
+		return "", fmt.Errorf("invalid module type in expected_ism %s", config.ExpectedISM)
+	}
+	moduleType, err := client.ModuleType(ctx, ism)
+	if err != nil {
+		return "", err
+	}
+	if uint64(moduleType) != want {
+		return fmt.Sprintf("domain %d: ISM %s has module type %d, expected %d", domain, ism, moduleType, want), nil
+	}
+	return "", nil
+}
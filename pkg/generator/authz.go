@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+)
+
+// WrapExec wraps messages in an authz MsgExec so that grantee can execute them on behalf of the
+// multisig. The multisig must have granted the grantee an authorization for every wrapped message type.
+func (g *Generator) WrapExec(msgs []sdk.Msg, grantee string) (*authz.MsgExec, error) {
+	if err := g.chain.ValidateAddress(grantee); err != nil {
+		return nil, fmt.Errorf("invalid authz grantee: %w", err)
+	}
+	if grantee == g.multisigAddr {
+		return nil, fmt.Errorf("authz grantee must differ from the multisig")
+	}
+
+	anys, err := packMsgs(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Built directly rather than with authz.NewMsgExec, which encodes the grantee with the
+	// global bech32 prefix instead of the chain's
+	return &authz.MsgExec{
+		Grantee: grantee,
+		Msgs:    anys,
+	}, nil
+}
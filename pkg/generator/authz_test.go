@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+)
+
+func TestBuildUnsignedTxAuthz(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+
+	msgs, err := gen.Generate(sampleRoutes())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	opts := TxOptions{Grantee: testFeePayer, FeePayer: testFeePayer}
+	unsignedTx, err := gen.BuildUnsignedTx(msgs, opts)
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+
+	if len(unsignedTx.Body.Messages) != 1 {
+		t.Fatalf("tx has %d messages, want a single MsgExec", len(unsignedTx.Body.Messages))
+	}
+	var exec authz.MsgExec
+	if err := exec.Unmarshal(unsignedTx.Body.Messages[0].Value); err != nil {
+		t.Fatalf("failed to decode MsgExec: %v", err)
+	}
+	if exec.Grantee != testFeePayer {
+		t.Errorf("Grantee = %s, want %s", exec.Grantee, testFeePayer)
+	}
+	if len(exec.Msgs) != len(msgs) {
+		t.Fatalf("MsgExec wraps %d messages, want %d", len(exec.Msgs), len(msgs))
+	}
+	var transfer warptypes.MsgRemoteTransfer
+	if err := transfer.Unmarshal(exec.Msgs[0].Value); err != nil {
+		t.Fatalf("failed to decode wrapped transfer: %v", err)
+	}
+	if transfer.Sender != testMultisig {
+		t.Errorf("wrapped transfer sender = %s, want the multisig %s", transfer.Sender, testMultisig)
+	}
+
+	// The grantee signs and pays; the multisig does not sign at all
+	if unsignedTx.AuthInfo.Fee.Payer != "" {
+		t.Errorf("Payer = %s, want empty when the grantee pays", unsignedTx.AuthInfo.Fee.Payer)
+	}
+	if signers := gen.Signers(opts); len(signers) != 1 || signers[0] != testFeePayer {
+		t.Errorf("Signers() = %v, want [%s]", signers, testFeePayer)
+	}
+
+	data, err := MarshalTxJSON(unsignedTx)
+	if err != nil {
+		t.Fatalf("MarshalTxJSON() error = %v", err)
+	}
+	if !strings.Contains(string(data), "/cosmos.authz.v1beta1.MsgExec") {
+		t.Errorf("tx JSON does not contain MsgExec type URL: %s", data)
+	}
+}
+
+func TestWrapExecInvalidGrantee(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+
+	for _, grantee := range []string{"not-an-address", testMultisig} {
+		if _, err := gen.WrapExec(nil, grantee); err == nil {
+			t.Errorf("WrapExec(%q) succeeded, want error", grantee)
+		}
+	}
+}
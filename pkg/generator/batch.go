@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// Batch is one unsigned transaction produced when messages are split across several transactions
+type Batch struct {
+	Index    int    `json:"index"`
+	Sequence uint64 `json:"sequence"`
+	File     string `json:"file"`
+	MsgCount int    `json:"msg_count"`
+	Dropped  bool   `json:"dropped,omitempty"`
+}
+
+// BatchManifest records the account sequence assigned to each batch so that batches can be
+// signed offline in parallel without colliding on the multisig's sequence
+type BatchManifest struct {
+	MultisigAddr  string   `json:"multisig_address"`
+	AccountNumber uint64   `json:"account_number"`
+	Recipients    []string `json:"recipients,omitempty"` // age recipients the batch files are encrypted to
+	Batches       []Batch  `json:"batches"`
+}
+
+// SplitMsgs splits messages into chunks of at most maxPerTx messages.
+// A non-positive maxPerTx returns all messages in a single chunk.
+func SplitMsgs(msgs []sdk.Msg, maxPerTx int) [][]sdk.Msg {
+	if maxPerTx <= 0 || len(msgs) <= maxPerTx {
+		return [][]sdk.Msg{msgs}
+	}
+
+	var chunks [][]sdk.Msg
+	for start := 0; start < len(msgs); start += maxPerTx {
+		end := start + maxPerTx
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		chunks = append(chunks, msgs[start:end])
+	}
+	return chunks
+}
+
+// BatchFileName derives the file name of batch index (1-based) from the base output file,
+// e.g. unsigned-tx.json -> unsigned-tx-1.json
+func BatchFileName(outputFile string, index int) string {
+	ext := filepath.Ext(outputFile)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outputFile, ext), index, ext)
+}
+
+// ManifestFileName derives the batch manifest file name from the base output file
+func ManifestFileName(outputFile string) string {
+	ext := filepath.Ext(outputFile)
+	return strings.TrimSuffix(outputFile, ext) + "-batches" + ext
+}
+
+// SetSequence records the multisig's account sequence in the transaction's first signer info,
+// which is what offline signers sign over
+func SetSequence(t *tx.Tx, sequence uint64) {
+	if t.AuthInfo == nil {
+		t.AuthInfo = &tx.AuthInfo{}
+	}
+	if len(t.AuthInfo.SignerInfos) == 0 {
+		t.AuthInfo.SignerInfos = []*tx.SignerInfo{{}}
+	}
+	t.AuthInfo.SignerInfos[0].Sequence = sequence
+}
+
+// Resequence marks the batch at index as dropped and assigns consecutive sequences to the
+// remaining batches after it, starting at the dropped batch's sequence. It returns the
+// batches whose sequence changed and therefore need their sign docs rebuilt.
+func (m *BatchManifest) Resequence(index int) ([]Batch, error) {
+	pos := -1
+	for i, b := range m.Batches {
+		if b.Index == index {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		return nil, fmt.Errorf("batch %d not found in manifest", index)
+	}
+	if m.Batches[pos].Dropped {
+		return nil, fmt.Errorf("batch %d is already dropped", index)
+	}
+
+	next := m.Batches[pos].Sequence
+	m.Batches[pos].Dropped = true
+
+	var changed []Batch
+	for i := pos + 1; i < len(m.Batches); i++ {
+		if m.Batches[i].Dropped {
+			continue
+		}
+		if m.Batches[i].Sequence != next {
+			m.Batches[i].Sequence = next
+			changed = append(changed, m.Batches[i])
+		}
+		next++
+	}
+
+	return changed, nil
+}
+
+// LoadBatchManifest reads a batch manifest from a JSON file
+func LoadBatchManifest(path string) (*BatchManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch manifest: %w", err)
+	}
+
+	var manifest BatchManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse batch manifest: %w", err)
+	}
+	for i := range manifest.Batches {
+		manifest.Batches[i].File = filepath.FromSlash(manifest.Batches[i].File)
+	}
+
+	return &manifest, nil
+}
+
+// Save writes the batch manifest to a JSON file. Batch files are recorded with forward slashes, so
+// a manifest written on Windows can be read elsewhere and vice versa.
+func (m *BatchManifest) Save(path string) error {
+	saved := *m
+	saved.Batches = make([]Batch, len(m.Batches))
+	for i, batch := range m.Batches {
+		batch.File = filepath.ToSlash(batch.File)
+		saved.Batches[i] = batch
+	}
+	data, err := json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch manifest: %w", err)
+	}
+
+	if err := output.WriteAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write batch manifest: %w", err)
+	}
+
+	return nil
+}
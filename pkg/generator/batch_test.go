@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+func TestSplitMsgs(t *testing.T) {
+	msgs := make([]sdk.Msg, 5)
+
+	tests := []struct {
+		name      string
+		maxPerTx  int
+		wantSizes []int
+	}{
+		{name: "no limit", maxPerTx: 0, wantSizes: []int{5}},
+		{name: "limit above count", maxPerTx: 10, wantSizes: []int{5}},
+		{name: "even split", maxPerTx: 5, wantSizes: []int{5}},
+		{name: "uneven split", maxPerTx: 2, wantSizes: []int{2, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitMsgs(msgs, tt.maxPerTx)
+			if len(chunks) != len(tt.wantSizes) {
+				t.Fatalf("SplitMsgs() returned %d chunks, want %d", len(chunks), len(tt.wantSizes))
+			}
+			for i, chunk := range chunks {
+				if len(chunk) != tt.wantSizes[i] {
+					t.Errorf("chunk %d has %d messages, want %d", i, len(chunk), tt.wantSizes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBatchFileNames(t *testing.T) {
+	if got := BatchFileName("out/unsigned-tx.json", 2); got != "out/unsigned-tx-2.json" {
+		t.Errorf("BatchFileName() = %s, want out/unsigned-tx-2.json", got)
+	}
+	if got := ManifestFileName("unsigned-tx.json"); got != "unsigned-tx-batches.json" {
+		t.Errorf("ManifestFileName() = %s, want unsigned-tx-batches.json", got)
+	}
+}
+
+func TestSetSequence(t *testing.T) {
+	unsignedTx := &tx.Tx{AuthInfo: &tx.AuthInfo{}}
+
+	SetSequence(unsignedTx, 7)
+	if got := unsignedTx.AuthInfo.SignerInfos[0].Sequence; got != 7 {
+		t.Errorf("sequence = %d, want 7", got)
+	}
+
+	SetSequence(unsignedTx, 8)
+	if len(unsignedTx.AuthInfo.SignerInfos) != 1 {
+		t.Errorf("SetSequence() added a signer info, got %d", len(unsignedTx.AuthInfo.SignerInfos))
+	}
+	if got := unsignedTx.AuthInfo.SignerInfos[0].Sequence; got != 8 {
+		t.Errorf("sequence = %d, want 8", got)
+	}
+}
+
+func TestResequence(t *testing.T) {
+	manifest := &BatchManifest{
+		Batches: []Batch{
+			{Index: 1, Sequence: 10},
+			{Index: 2, Sequence: 11},
+			{Index: 3, Sequence: 12},
+			{Index: 4, Sequence: 13},
+		},
+	}
+
+	changed, err := manifest.Resequence(2)
+	if err != nil {
+		t.Fatalf("Resequence() error = %v", err)
+	}
+
+	if len(changed) != 2 {
+		t.Fatalf("Resequence() changed %d batches, want 2", len(changed))
+	}
+
+	wantSequences := map[int]uint64{1: 10, 3: 11, 4: 12}
+	for _, b := range manifest.Batches {
+		if b.Index == 2 {
+			if !b.Dropped {
+				t.Error("batch 2 not marked as dropped")
+			}
+			continue
+		}
+		if b.Sequence != wantSequences[b.Index] {
+			t.Errorf("batch %d sequence = %d, want %d", b.Index, b.Sequence, wantSequences[b.Index])
+		}
+	}
+
+	if _, err := manifest.Resequence(2); err == nil {
+		t.Error("Resequence() expected error for already dropped batch, got nil")
+	}
+	if _, err := manifest.Resequence(9); err == nil {
+		t.Error("Resequence() expected error for unknown batch, got nil")
+	}
+}
+
+func TestBatchManifestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batches.json")
+	manifest := &BatchManifest{
+		MultisigAddr:  testMultisig,
+		AccountNumber: 42,
+		Batches:       []Batch{{Index: 1, Sequence: 3, File: "unsigned-tx-1.json", MsgCount: 2}},
+	}
+
+	if err := manifest.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadBatchManifest(path)
+	if err != nil {
+		t.Fatalf("LoadBatchManifest() error = %v", err)
+	}
+	if loaded.AccountNumber != 42 || len(loaded.Batches) != 1 || loaded.Batches[0].Sequence != 3 {
+		t.Errorf("loaded manifest = %+v, want %+v", loaded, manifest)
+	}
+}
@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// AttachDispatches records the dispatched Hyperlane messages against the routes they were generated
+// from. Every route dispatches exactly one message, in route order, so dispatches must be given in
+// the order of the transactions and of the events within them. Each dispatch is checked against the
+// transfer generated for its route.
+func (g *Generator) AttachDispatches(routes *types.Routes, dispatches []types.Dispatch) error {
+	if len(dispatches) != len(routes.Routes) {
+		return fmt.Errorf("found %d dispatched messages, but routes has %d entries", len(dispatches), len(routes.Routes))
+	}
+
+	for i := range routes.Routes {
+		route := &routes.Routes[i]
+		dispatch := dispatches[i]
+
+		msg, err := g.GenerateRoute(route)
+		if err != nil {
+			return err
+		}
+		if dispatch.Destination != msg.DestinationDomain ||
+			!strings.EqualFold(dispatch.Recipient, msg.Recipient.String()) ||
+			dispatch.Amount != msg.Amount.String() {
+			return fmt.Errorf("dispatch %d (message %s: %s to %s on domain %d) does not match route from tx %s (%s to %s on domain %d)",
+				i, dispatch.MessageID, dispatch.Amount, dispatch.Recipient, dispatch.Destination,
+				route.TxHash, msg.Amount, msg.Recipient, msg.DestinationDomain)
+		}
+		route.Dispatch = &dispatch
+	}
+
+	return nil
+}
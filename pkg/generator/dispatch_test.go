@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestAttachDispatches(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+	dispatch := types.Dispatch{
+		MessageID:   "0xabc",
+		Nonce:       7,
+		Destination: 1380012617,
+		Recipient:   "0x000000000000000000000000742D35CC6634C0532925A3B844BC9E7595F0BEB0",
+		Amount:      "1000000",
+		TxHash:      "REBALANCE1",
+		Height:      200,
+	}
+
+	routes := sampleRoutes()
+	if err := gen.AttachDispatches(routes, []types.Dispatch{dispatch}); err != nil {
+		t.Fatalf("AttachDispatches() error = %v", err)
+	}
+	if got := routes.Routes[0].Dispatch; got == nil || got.MessageID != "0xabc" || got.Nonce != 7 {
+		t.Errorf("Dispatch = %+v, want message 0xabc with nonce 7", got)
+	}
+
+	tests := []struct {
+		name       string
+		dispatches func() []types.Dispatch
+		want       string
+	}{
+		{"count mismatch", func() []types.Dispatch { return nil }, "found 0 dispatched messages"},
+		{"amount mismatch", func() []types.Dispatch {
+			d := dispatch
+			d.Amount = "999"
+			return []types.Dispatch{d}
+		}, "does not match route"},
+		{"destination mismatch", func() []types.Dispatch {
+			d := dispatch
+			d.Destination = 42161
+			return []types.Dispatch{d}
+		}, "does not match route"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := gen.AttachDispatches(sampleRoutes(), tt.dispatches())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("AttachDispatches() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,14 @@
 package generator
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 
 	"cosmossdk.io/math"
 	"github.com/bcp-innovations/hyperlane-cosmos/util"
 	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/metrics"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -17,13 +16,89 @@ import (
 // Generator creates Hyperlane MsgRemoteTransfer transactions from routes
 type Generator struct {
 	multisigAddr string
+	chain        types.ChainConfig
+	maxTransfer  math.Int                           // Largest amount per message; nil means unlimited
+	metadata     types.MetadataConfig               // CustomHookMetadata forwarded in generated messages
+	destinations map[uint32]types.DestinationConfig // Memo templates and interchain gas of the destinations
+	whitelist    types.AddressWhitelist             // Gas limits and hooks of individual recipients
+	quotes       map[feeQuoteKey]sdk.Coin           // Interchain gas payments quoted by the chain
+	tokenDenoms  map[string]string                  // Denom each normalized token ID debits from the sender
+}
+
+// FeeQuote is the interchain gas payment the chain quoted for transferring a token to a domain
+type FeeQuote struct {
+	TokenID string // 32-byte hex token ID; empty for a quote of the IGP that applies to every token
+	Domain  uint32
+	Fee     sdk.Coin
+}
+
+// feeQuoteKey identifies the quote of a normalized token ID and destination domain
+type feeQuoteKey struct {
+	tokenID string
+	domain  uint32
 }
 
 // NewGenerator creates a new transaction generator
 func NewGenerator(multisigAddr string) *Generator {
 	return &Generator{
 		multisigAddr: multisigAddr,
+		chain:        types.DefaultChainConfig(),
+	}
+}
+
+// NewGeneratorWithConfig creates a new transaction generator for the chain and limits described in config
+func NewGeneratorWithConfig(multisigAddr string, config *types.Config) (*Generator, error) {
+	maxTransfer, err := config.Limits.MaxTransfer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Generator{
+		multisigAddr: multisigAddr,
+		chain:        config.Chain.WithDefaults(),
+		maxTransfer:  maxTransfer,
+		metadata:     config.Metadata,
+		destinations: config.Destinations,
+		whitelist:    config.Whitelist,
+	}, nil
+}
+
+// MultisigAddr returns the multisig address used as sender of generated messages
+func (g *Generator) MultisigAddr() string {
+	return g.multisigAddr
+}
+
+// SetFeeQuotes sets the interchain gas payments quoted by the chain. Transfers to a destination
+// without a configured max_fee pay the quote of their token as MaxFee; a configured max_fee below
+// the quote is refused, as the transfer could not pay for its delivery.
+func (g *Generator) SetFeeQuotes(quotes []FeeQuote) error {
+	g.quotes = make(map[feeQuoteKey]sdk.Coin, len(quotes))
+	for _, quote := range quotes {
+		var tokenID string
+		if quote.TokenID != "" {
+			var err error
+			if tokenID, err = types.NormalizeTokenID(quote.TokenID); err != nil {
+				return fmt.Errorf("invalid token_id in fee quote: %w", err)
+			}
+		}
+		g.quotes[feeQuoteKey{tokenID, quote.Domain}] = quote.Fee
+	}
+	return nil
+}
+
+// SetTokenDenoms sets the denom transfers of each warp token debit from the multisig, keyed by
+// token ID, so balance projections charge a route to the funds its transfer actually spends
+// rather than to the denom it was deposited in
+func (g *Generator) SetTokenDenoms(denoms map[string]string) error {
+	g.tokenDenoms = make(map[string]string, len(denoms))
+	for id, denom := range denoms {
+		tokenID, err := types.NormalizeTokenID(id)
+		if err != nil {
+			return fmt.Errorf("invalid token ID %s: %w", id, err)
+		}
+		g.tokenDenoms[tokenID] = denom
 	}
+	return nil
 }
 
 // GenerateFromFile reads routes from a JSON file and generates unsigned transactions
@@ -42,94 +117,156 @@ func (g *Generator) GenerateFromFile(routesFile string) ([]sdk.Msg, error) {
 	return g.Generate(&routes)
 }
 
-// Generate creates MsgRemoteTransfer messages from parsed routes
+// Generate creates MsgRemoteTransfer messages from parsed routes, one per split recipient of
+// fan-out routes
 func (g *Generator) Generate(routes *types.Routes) ([]sdk.Msg, error) {
 	var msgs []sdk.Msg
 
-	for _, route := range routes.Routes {
-		if route.RouteInfo == nil {
-			return nil, fmt.Errorf("route from tx %s has no routing info", route.TxHash)
+	for i := range routes.Routes {
+		parts, err := routes.Routes[i].ExpandSplits()
+		if err != nil {
+			metrics.GenerationErrors.Inc()
+			return nil, err
 		}
-
-		// Parse amount
-		amount, ok := math.NewIntFromString(route.Amount)
-		if !ok {
-			return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		for j := range parts {
+			msg, err := g.GenerateRoute(&parts[j])
+			if err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, msg)
 		}
+	}
 
-		// Parse token ID
-		tokenID, err := parseTokenID(route.RouteInfo.TokenID)
-		if err != nil {
-			return nil, fmt.Errorf("invalid token_id in route from tx %s: %w", route.TxHash, err)
-		}
+	return msgs, nil
+}
 
-		// Parse and pad recipient address
-		recipient, err := parseAndPadAddress(route.RouteInfo.Recipient)
-		if err != nil {
-			return nil, fmt.Errorf("invalid recipient address in route from tx %s: %w", route.TxHash, err)
-		}
+// GenerateRoute creates the MsgRemoteTransfer message for a single route
+func (g *Generator) GenerateRoute(route *types.HyperlaneRoute) (*warptypes.MsgRemoteTransfer, error) {
+	msg, err := g.generateRoute(route)
+	if err != nil {
+		metrics.GenerationErrors.Inc()
+		return nil, err
+	}
+	metrics.MessagesGenerated.Inc()
+	return msg, nil
+}
 
-		// Create MsgRemoteTransfer
-		msg := &warptypes.MsgRemoteTransfer{
-			Sender:            g.multisigAddr,
-			TokenId:           tokenID,
-			DestinationDomain: route.RouteInfo.DestinationDomain,
-			Recipient:         recipient,
-			Amount:            amount,
-		}
+// generateRoute builds the MsgRemoteTransfer of GenerateRoute
+func (g *Generator) generateRoute(route *types.HyperlaneRoute) (*warptypes.MsgRemoteTransfer, error) {
+	if route.RouteInfo == nil {
+		return nil, fmt.Errorf("route from tx %s has no routing info", route.TxHash)
+	}
+	if len(route.RouteInfo.Splits) > 0 {
+		return nil, fmt.Errorf("route from tx %s fans out to %d recipients; expand its splits first", route.TxHash, len(route.RouteInfo.Splits))
+	}
 
-		msgs = append(msgs, msg)
+	// Parse amount
+	amount, ok := math.NewIntFromString(route.Amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+	}
+	if !g.maxTransfer.IsNil() && amount.GT(g.maxTransfer) {
+		return nil, fmt.Errorf("amount %s in route from tx %s exceeds the per-transfer maximum of %s", amount, route.TxHash, g.maxTransfer)
 	}
 
-	return msgs, nil
-}
+	// Parse token ID
+	tokenID, err := parseTokenID(route.RouteInfo.TokenID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token_id in route from tx %s: %w", route.TxHash, err)
+	}
 
-// parseTokenID converts a token ID string (hex or bech32) to util.HexAddress
-// Token IDs must be exactly 32 bytes for Hyperlane
-func parseTokenID(tokenIDStr string) (util.HexAddress, error) {
-	// Remove 0x prefix if present
-	tokenIDStr = strings.TrimPrefix(tokenIDStr, "0x")
+	// Parse and pad recipient address
+	recipient, err := parseAndPadAddress(route.RouteInfo.Recipient)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address in route from tx %s: %w", route.TxHash, err)
+	}
 
-	// Decode hex string
-	tokenID, err := hex.DecodeString(tokenIDStr)
+	// Forward the deposit's metadata or a receipt when policy requires it
+	metadata, err := g.metadata.ForwardedMetadata(route)
 	if err != nil {
-		return util.HexAddress{}, fmt.Errorf("failed to decode token ID as hex: %w", err)
+		return nil, err
 	}
 
-	// Validate that token ID is exactly 32 bytes
-	if len(tokenID) != 32 {
-		return util.HexAddress{}, fmt.Errorf("token ID must be exactly 32 bytes, got %d bytes", len(tokenID))
+	// Pay for the delivery on the destination chain, with the recipient's own gas limit if it has one
+	settings := g.whitelist.RecipientSettings(route.RouteInfo.DestinationDomain, route.RouteInfo.Recipient)
+	gasLimit, maxFee, err := g.interchainGas(tokenID, route.RouteInfo.DestinationDomain)
+	if err != nil {
+		return nil, fmt.Errorf("route from tx %s: %w", route.TxHash, err)
+	}
+	if settings.GasLimit > 0 {
+		gasLimit = math.NewIntFromUint64(settings.GasLimit)
 	}
 
-	return util.HexAddress(tokenID), nil
+	// Create MsgRemoteTransfer
+	msg := &warptypes.MsgRemoteTransfer{
+		Sender:             g.multisigAddr,
+		TokenId:            tokenID,
+		DestinationDomain:  route.RouteInfo.DestinationDomain,
+		Recipient:          recipient,
+		Amount:             amount,
+		GasLimit:           gasLimit,
+		MaxFee:             maxFee,
+		CustomHookMetadata: metadata,
+	}
+	hook, ok, err := settings.Hook()
+	if err != nil {
+		return nil, fmt.Errorf("route from tx %s: %w", route.TxHash, err)
+	}
+	if ok {
+		hookID := util.HexAddress(hook)
+		msg.CustomHookId = &hookID
+	}
+	return msg, nil
 }
 
-// parseAndPadAddress parses an address and pads it to 32 bytes for Hyperlane
-// Supports both EVM addresses (0x...) and Cosmos bech32 addresses
-func parseAndPadAddress(addrStr string) (util.HexAddress, error) {
-	var addrBytes []byte
+// interchainGas returns the GasLimit and MaxFee of transfers of tokenID to domain: the destination's
+// configured values, with the quoted payment as MaxFee when none is configured. Both are left unset
+// when neither is known.
+func (g *Generator) interchainGas(tokenID util.HexAddress, domain uint32) (math.Int, sdk.Coin, error) {
+	destination := g.destinations[domain]
 
-	if strings.HasPrefix(addrStr, "0x") {
-		// EVM address - decode hex
-		addrStr = strings.TrimPrefix(addrStr, "0x")
-		decoded, err := hex.DecodeString(addrStr)
-		if err != nil {
-			return util.HexAddress{}, fmt.Errorf("failed to decode EVM address: %w", err)
-		}
-		addrBytes = decoded
-	} else {
-		// Assume Cosmos bech32 address
-		addr, err := sdk.AccAddressFromBech32(addrStr)
-		if err != nil {
-			return util.HexAddress{}, fmt.Errorf("failed to decode bech32 address: %w", err)
+	var gasLimit math.Int
+	if destination.GasLimit > 0 {
+		gasLimit = math.NewIntFromUint64(destination.GasLimit)
+	}
+
+	quote, quoted := g.quotes[feeQuoteKey{tokenID.String(), domain}]
+	if !quoted {
+		quote, quoted = g.quotes[feeQuoteKey{"", domain}]
+	}
+	if destination.MaxFee == "" {
+		if quoted {
+			return gasLimit, quote, nil
 		}
-		addrBytes = addr.Bytes()
+		return gasLimit, sdk.Coin{}, nil
+	}
+
+	amount, denom, err := types.ParseMaxFee(destination.MaxFee)
+	if err != nil {
+		return math.Int{}, sdk.Coin{}, err
+	}
+	maxFee := sdk.NewCoin(denom, amount)
+	if quoted && (quote.Denom != maxFee.Denom || maxFee.Amount.LT(quote.Amount)) {
+		return math.Int{}, sdk.Coin{}, fmt.Errorf("max_fee %s of domain %d does not cover the quoted interchain gas payment of %s", maxFee, domain, quote)
 	}
+	return gasLimit, maxFee, nil
+}
 
-	// Pad to 32 bytes (Hyperlane requirement)
-	// Cosmos addresses are 20 bytes, so we left-pad with 12 zero bytes
-	paddedAddr := make([]byte, 32)
-	copy(paddedAddr[32-len(addrBytes):], addrBytes)
+// parseTokenID converts a 32-byte hex token ID to util.HexAddress
+func parseTokenID(tokenIDStr string) (util.HexAddress, error) {
+	tokenID, err := types.TokenIDBytes(tokenIDStr)
+	if err != nil {
+		return util.HexAddress{}, err
+	}
+	return util.HexAddress(tokenID), nil
+}
 
-	return util.HexAddress(paddedAddr), nil
+// parseAndPadAddress parses an EVM (0x...) or bech32 recipient and left-pads it to the 32 bytes
+// Hyperlane requires
+func parseAndPadAddress(addrStr string) (util.HexAddress, error) {
+	padded, err := types.PadRecipient(addrStr)
+	if err != nil {
+		return util.HexAddress{}, err
+	}
+	return util.HexAddress(padded), nil
 }
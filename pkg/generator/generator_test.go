@@ -1,9 +1,12 @@
 package generator
 
 import (
+	"strings"
 	"testing"
 
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 func TestParseTokenID(t *testing.T) {
@@ -85,6 +88,12 @@ func TestParseAndPadAddress(t *testing.T) {
 			input:   "celestia1abc123def456ghi789",
 			wantErr: true, // Will fail with invalid bech32
 		},
+		{
+			name:    "cosmos bech32 address with non-celestia prefix",
+			input:   "neutron1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg39z7qgg",
+			wantErr: false,
+			wantLen: 32,
+		},
 		{
 			name:    "empty address",
 			input:   "",
@@ -171,6 +180,106 @@ func TestGenerateWithInvalidAmount(t *testing.T) {
 	}
 }
 
+func TestGenerateAboveMaxTransfer(t *testing.T) {
+	config := types.DefaultConfig()
+	config.Limits.MaxTransferAmount = "999999"
+
+	gen, err := NewGeneratorWithConfig("celestia1multisig123...", config)
+	if err != nil {
+		t.Fatalf("NewGeneratorWithConfig() error = %v", err)
+	}
+
+	routes := &types.Routes{
+		Routes: []types.HyperlaneRoute{
+			{
+				TxHash:      "ABC123",
+				BlockHeight: 1000000,
+				From:        "celestia1sender",
+				Amount:      "1000000",
+				Denom:       "utia",
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: 1380012617,
+					Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+					TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				},
+			},
+		},
+		TotalAmount:  "1000000",
+		MultisigAddr: "celestia1multisig123...",
+	}
+
+	if _, err := gen.Generate(routes); err == nil {
+		t.Error("Generate() expected error for amount above the per-transfer maximum, got nil")
+	}
+
+	config.Limits.MaxTransferAmount = "1000000"
+	gen, err = NewGeneratorWithConfig("celestia1multisig123...", config)
+	if err != nil {
+		t.Fatalf("NewGeneratorWithConfig() error = %v", err)
+	}
+	if _, err := gen.Generate(routes); err != nil {
+		t.Errorf("Generate() error = %v for amount equal to the maximum", err)
+	}
+}
+
+func TestGenerateRebalancingFee(t *testing.T) {
+	config := types.DefaultConfig()
+	config.RebalancingFee = types.RebalancingFeeConfig{BPS: 25, Min: "5000"}
+
+	gen, err := NewGeneratorWithConfig("celestia1multisig123...", config)
+	if err != nil {
+		t.Fatalf("NewGeneratorWithConfig() error = %v", err)
+	}
+
+	route := types.HyperlaneRoute{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}
+	if err := config.RebalancingFee.DeductFrom(&route); err != nil {
+		t.Fatalf("DeductFrom() error = %v", err)
+	}
+	msg, err := gen.GenerateRoute(&route)
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if msg.Amount.String() != "997500" {
+		t.Errorf("Amount = %s, want 997500 after the 0.25%% fee", msg.Amount)
+	}
+
+	// The fee is kept once per deposit, not again from each transfer of a fan-out deposit
+	route = types.HyperlaneRoute{
+		TxHash: "DEF456",
+		Amount: "1000000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			Splits: []types.RouteSplit{
+				{Recipient: "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0", Percent: "50"},
+				{Recipient: "0x1234567890123456789012345678901234567890", Percent: "50"},
+			},
+		},
+	}
+	if err := config.RebalancingFee.DeductFrom(&route); err != nil {
+		t.Fatalf("DeductFrom() error = %v", err)
+	}
+	msgs, err := gen.Generate(&types.Routes{Routes: []types.HyperlaneRoute{route}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for i, msg := range msgs {
+		if amount := msg.(*warptypes.MsgRemoteTransfer).Amount.String(); amount != "498750" {
+			t.Errorf("message %d amount = %s, want 498750, half the deposit less one fee", i, amount)
+		}
+	}
+}
+
 func TestGenerateWithInvalidTokenID(t *testing.T) {
 	gen := NewGenerator("celestia1multisig123...")
 
@@ -242,3 +351,139 @@ func TestGenerateWithMultipleRoutes(t *testing.T) {
 		t.Errorf("Generate() returned %d messages, want 2", len(msgs))
 	}
 }
+
+func TestGenerateInterchainGas(t *testing.T) {
+	tokenID := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	route := types.HyperlaneRoute{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           tokenID,
+		},
+	}
+	generate := func(destination types.DestinationConfig, quotes ...FeeQuote) (*warptypes.MsgRemoteTransfer, error) {
+		config := &types.Config{Destinations: map[uint32]types.DestinationConfig{1380012617: destination}}
+		gen, err := NewGeneratorWithConfig("celestia1multisig123...", config)
+		if err != nil {
+			t.Fatalf("NewGeneratorWithConfig() error = %v", err)
+		}
+		if err := gen.SetFeeQuotes(quotes); err != nil {
+			t.Fatalf("SetFeeQuotes() error = %v", err)
+		}
+		return gen.GenerateRoute(&route)
+	}
+
+	msg, err := generate(types.DestinationConfig{})
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if !msg.GasLimit.IsNil() || msg.MaxFee.Denom != "" {
+		t.Errorf("GasLimit = %v, MaxFee = %v, want both unset without configuration", msg.GasLimit, msg.MaxFee)
+	}
+
+	msg, err = generate(types.DestinationConfig{GasLimit: 200000, MaxFee: "5000utia"})
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if msg.GasLimit.Int64() != 200000 || msg.MaxFee.String() != "5000utia" {
+		t.Errorf("GasLimit = %v, MaxFee = %v, want 200000 and 5000utia", msg.GasLimit, msg.MaxFee)
+	}
+
+	quote := FeeQuote{TokenID: strings.ToUpper(tokenID[2:]), Domain: 1380012617, Fee: sdk.NewInt64Coin("utia", 4000)}
+	msg, err = generate(types.DestinationConfig{GasLimit: 200000}, quote)
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if msg.MaxFee.String() != "4000utia" {
+		t.Errorf("MaxFee = %v, want the quoted 4000utia", msg.MaxFee)
+	}
+
+	if msg, err = generate(types.DestinationConfig{MaxFee: "5000utia"}, quote); err != nil || msg.MaxFee.String() != "5000utia" {
+		t.Errorf("GenerateRoute() = %v, %v, want the configured max_fee covering the quote", msg, err)
+	}
+	if _, err := generate(types.DestinationConfig{MaxFee: "3000utia"}, quote); err == nil {
+		t.Error("GenerateRoute() accepted a max_fee below the quote")
+	}
+	if _, err := generate(types.DestinationConfig{MaxFee: "5000uatom"}, quote); err == nil {
+		t.Error("GenerateRoute() accepted a max_fee in another denom than the quote")
+	}
+}
+
+func TestGenerateRecipientSettings(t *testing.T) {
+	vault := "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	hookID := "0x726f757465725f706f73745f6469737061746368000000030000000000000001"
+	config := &types.Config{
+		Destinations: map[uint32]types.DestinationConfig{1380012617: {GasLimit: 200000}},
+		Whitelist: types.AddressWhitelist{
+			Domains: map[uint32][]string{1380012617: {vault, "0x1111111111111111111111111111111111111111"}},
+			Recipients: map[uint32]map[string]types.RecipientConfig{
+				// Written in another case than the route, which must not matter
+				1380012617: {strings.ToLower(vault): {GasLimit: 500000, HookID: hookID}},
+			},
+		},
+	}
+	gen, err := NewGeneratorWithConfig("celestia1multisig123...", config)
+	if err != nil {
+		t.Fatalf("NewGeneratorWithConfig() error = %v", err)
+	}
+	route := func(recipient string) *types.HyperlaneRoute {
+		return &types.HyperlaneRoute{
+			TxHash: "ABC123",
+			Amount: "1000000",
+			RouteInfo: &types.RouteInfo{
+				DestinationDomain: 1380012617,
+				Recipient:         recipient,
+				TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			},
+		}
+	}
+
+	msg, err := gen.GenerateRoute(route(vault))
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if msg.GasLimit.Int64() != 500000 {
+		t.Errorf("GasLimit = %v, want the recipient's 500000", msg.GasLimit)
+	}
+	if msg.CustomHookId == nil || msg.CustomHookId.String() != hookID {
+		t.Errorf("CustomHookId = %v, want %s", msg.CustomHookId, hookID)
+	}
+
+	msg, err = gen.GenerateRoute(route("0x1111111111111111111111111111111111111111"))
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if msg.GasLimit.Int64() != 200000 || msg.CustomHookId != nil {
+		t.Errorf("GasLimit = %v, CustomHookId = %v, want the domain's 200000 and no hook", msg.GasLimit, msg.CustomHookId)
+	}
+}
+
+func TestGenerateDomainFeeQuote(t *testing.T) {
+	route := types.HyperlaneRoute{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}
+	gen := NewGenerator("celestia1multisig123...")
+	if err := gen.SetFeeQuotes([]FeeQuote{
+		{Domain: 1380012617, Fee: sdk.NewInt64Coin("utia", 4000)},
+		{TokenID: "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890", Domain: 1380012617, Fee: sdk.NewInt64Coin("utia", 9000)},
+	}); err != nil {
+		t.Fatalf("SetFeeQuotes() error = %v", err)
+	}
+
+	// The IGP's quote for the domain applies to tokens without a quote of their own
+	msg, err := gen.GenerateRoute(&route)
+	if err != nil {
+		t.Fatalf("GenerateRoute() error = %v", err)
+	}
+	if msg.MaxFee.String() != "4000utia" {
+		t.Errorf("MaxFee = %v, want the domain's 4000utia", msg.MaxFee)
+	}
+}
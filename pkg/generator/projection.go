@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"cosmossdk.io/math"
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// DenomProjection is the projected balance of a single denom after the rebalancing transaction
+type DenomProjection struct {
+	Denom     string `json:"denom"`
+	Before    string `json:"before"`
+	Outbound  string `json:"outbound"`
+	Fees      string `json:"fees"`
+	After     string `json:"after"`
+	Overdrawn bool   `json:"overdrawn,omitempty"`
+}
+
+// BalanceProjection shows the multisig balances before and after the rebalancing transaction
+type BalanceProjection struct {
+	Address string            `json:"address"`
+	Denoms  []DenomProjection `json:"denoms"`
+}
+
+// Overdrawn reports whether any denom would end up with a negative balance
+func (p *BalanceProjection) Overdrawn() bool {
+	for _, d := range p.Denoms {
+		if d.Overdrawn {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectBalances computes the multisig balance per denom after the generated messages execute.
+// Outbound amounts are attributed to the denom set for the route's token with SetTokenDenoms, or
+// else to the route's denom (the chain's native denom if unset), and interchain gas MaxFee to its
+// own denom. fees are the transaction fees paid by the multisig;
+// pass nil when a separate fee payer covers them.
+func (g *Generator) ProjectBalances(routes *types.Routes, msgs []sdk.Msg, before sdk.Coins, fees sdk.Coins) (*BalanceProjection, error) {
+	outbound := sdk.NewCoins()
+	for _, route := range routes.Routes {
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+		if amount.IsNegative() {
+			return nil, fmt.Errorf("negative amount %s in route from tx %s", amount, route.TxHash)
+		}
+
+		denom := route.Denom
+		if route.RouteInfo != nil {
+			if tokenID, err := types.NormalizeTokenID(route.RouteInfo.TokenID); err == nil && g.tokenDenoms[tokenID] != "" {
+				denom = g.tokenDenoms[tokenID]
+			}
+		}
+		if denom == "" {
+			denom = g.chain.Denom
+		}
+		outbound = outbound.Add(sdk.NewCoin(denom, amount))
+	}
+
+	// Interchain gas payments are charged to the sender on top of the transferred amount
+	for _, msg := range msgs {
+		transfer, ok := msg.(*warptypes.MsgRemoteTransfer)
+		if !ok || transfer.MaxFee.Denom == "" || transfer.MaxFee.Amount.IsNil() {
+			continue
+		}
+		outbound = outbound.Add(transfer.MaxFee)
+	}
+
+	denoms := make(map[string]bool)
+	for _, coins := range []sdk.Coins{before, outbound, fees} {
+		for _, coin := range coins {
+			denoms[coin.Denom] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(denoms))
+	for denom := range denoms {
+		sorted = append(sorted, denom)
+	}
+	sort.Strings(sorted)
+
+	projection := &BalanceProjection{Address: g.multisigAddr}
+	for _, denom := range sorted {
+		b := before.AmountOf(denom)
+		o := outbound.AmountOf(denom)
+		f := fees.AmountOf(denom)
+		after := b.Sub(o).Sub(f)
+
+		projection.Denoms = append(projection.Denoms, DenomProjection{
+			Denom:     denom,
+			Before:    b.String(),
+			Outbound:  o.String(),
+			Fees:      f.String(),
+			After:     after.String(),
+			Overdrawn: after.IsNegative(),
+		})
+	}
+
+	return projection, nil
+}
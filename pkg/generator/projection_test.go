@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestProjectBalances(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+	routes := sampleRoutes()
+
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		before    sdk.Coins
+		fees      sdk.Coins
+		wantAfter string
+		overdrawn bool
+	}{
+		{
+			name:      "sufficient balance",
+			before:    sdk.NewCoins(sdk.NewCoin("utia", math.NewInt(5000000))),
+			fees:      sdk.NewCoins(sdk.NewCoin("utia", math.NewInt(20000))),
+			wantAfter: "3980000",
+		},
+		{
+			name:      "fees paid by fee payer",
+			before:    sdk.NewCoins(sdk.NewCoin("utia", math.NewInt(1000000))),
+			wantAfter: "0",
+		},
+		{
+			name:      "overdrawn",
+			before:    sdk.NewCoins(sdk.NewCoin("utia", math.NewInt(1000000))),
+			fees:      sdk.NewCoins(sdk.NewCoin("utia", math.NewInt(20000))),
+			wantAfter: "-20000",
+			overdrawn: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projection, err := gen.ProjectBalances(routes, msgs, tt.before, tt.fees)
+			if err != nil {
+				t.Fatalf("ProjectBalances() error = %v", err)
+			}
+
+			if len(projection.Denoms) != 1 {
+				t.Fatalf("got %d denoms, want 1", len(projection.Denoms))
+			}
+			d := projection.Denoms[0]
+			if d.Denom != "utia" || d.Outbound != "1000000" || d.After != tt.wantAfter {
+				t.Errorf("projection = %+v, want utia outbound 1000000 after %s", d, tt.wantAfter)
+			}
+			if projection.Overdrawn() != tt.overdrawn {
+				t.Errorf("Overdrawn() = %v, want %v", projection.Overdrawn(), tt.overdrawn)
+			}
+		})
+	}
+}
+
+func TestProjectBalancesNegativeAmount(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+	routes := sampleRoutes()
+	routes.Routes[0].Amount = "-1"
+
+	if _, err := gen.ProjectBalances(routes, nil, sdk.NewCoins(), nil); err == nil {
+		t.Error("ProjectBalances() expected error for negative amount, got nil")
+	}
+}
+
+func TestProjectBalancesTokenDenoms(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+	routes := sampleRoutes()
+	tokenID := routes.Routes[0].RouteInfo.TokenID
+	synthetic := "hyperlane/" + tokenID
+
+	if err := gen.SetTokenDenoms(map[string]string{tokenID: synthetic}); err != nil {
+		t.Fatalf("SetTokenDenoms() error = %v", err)
+	}
+
+	// The deposit arrived as utia, but the transfer burns the synthetic token
+	before := sdk.NewCoins(sdk.NewCoin("utia", math.NewInt(5000000)), sdk.NewCoin(synthetic, math.NewInt(400000)))
+	projection, err := gen.ProjectBalances(routes, nil, before, nil)
+	if err != nil {
+		t.Fatalf("ProjectBalances() error = %v", err)
+	}
+	if !projection.Overdrawn() {
+		t.Fatalf("Overdrawn() = false, want the synthetic balance overdrawn: %+v", projection.Denoms)
+	}
+	for _, d := range projection.Denoms {
+		if d.Denom == "utia" && d.Outbound != "0" {
+			t.Errorf("utia outbound = %s, want 0", d.Outbound)
+		}
+		if d.Denom == synthetic && d.After != "-600000" {
+			t.Errorf("%s after = %s, want -600000", synthetic, d.After)
+		}
+	}
+
+	if err := gen.SetTokenDenoms(map[string]string{"0x12": "utia"}); err == nil {
+		t.Error("SetTokenDenoms() accepted a malformed token ID")
+	}
+}
@@ -0,0 +1,292 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/std"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+)
+
+// TxOptions controls how generated messages are wrapped into an unsigned transaction
+type TxOptions struct {
+	Memo     string
+	GasLimit uint64
+	Fee      sdk.Coins
+	FeePayer string // Optional: account paying fees instead of the multisig
+
+	// Grantee wraps the messages in an authz MsgExec executed by this account under a grant
+	// from the multisig; the grantee then signs instead of the multisig
+	Grantee string
+}
+
+// cdc is used to encode transactions in the JSON format expected by celestia-appd and Keplr
+var cdc = newCodec()
+
+func newCodec() *codec.ProtoCodec {
+	registry := codectypes.NewInterfaceRegistry()
+	RegisterInterfaces(registry)
+	return codec.NewProtoCodec(registry)
+}
+
+// RegisterInterfaces registers the message and key types that appear in generated transactions
+func RegisterInterfaces(registry codectypes.InterfaceRegistry) {
+	std.RegisterInterfaces(registry)
+	warptypes.RegisterInterfaces(registry)
+	authz.RegisterInterfaces(registry)
+}
+
+// BuildUnsignedTx wraps the messages into an unsigned transaction with the given fee settings
+func (g *Generator) BuildUnsignedTx(msgs []sdk.Msg, opts TxOptions) (*tx.Tx, error) {
+	memo := opts.Memo
+	if memo == "" {
+		var err error
+		if memo, err = g.Memo(msgs); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Grantee != "" {
+		exec, err := g.WrapExec(msgs, opts.Grantee)
+		if err != nil {
+			return nil, err
+		}
+		msgs = []sdk.Msg{exec}
+	}
+
+	anys, err := packMsgs(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := &tx.Fee{
+		Amount:   opts.Fee,
+		GasLimit: opts.GasLimit,
+	}
+
+	// Only set the payer when it differs from the first signer (the multisig, or the
+	// grantee under authz), which pays by default
+	if opts.FeePayer != "" && opts.FeePayer != g.firstSigner(opts) {
+		if err := g.chain.ValidateAddress(opts.FeePayer); err != nil {
+			return nil, fmt.Errorf("invalid fee payer: %w", err)
+		}
+		fee.Payer = opts.FeePayer
+	}
+
+	return &tx.Tx{
+		Body: &tx.TxBody{
+			Messages: anys,
+			Memo:     memo,
+		},
+		AuthInfo: &tx.AuthInfo{
+			Fee: fee,
+		},
+	}, nil
+}
+
+// Memo renders the memo of a transaction with msgs from the memo templates of their destinations.
+// It is empty when no destination has a template.
+func (g *Generator) Memo(msgs []sdk.Msg) (string, error) {
+	var transfers []*warptypes.MsgRemoteTransfer
+	for _, msg := range msgs {
+		if transfer, ok := msg.(*warptypes.MsgRemoteTransfer); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return types.RenderMemo(g.destinations, MemoTransfers(transfers))
+}
+
+// MemoTransfers describes transfers for memo templates
+func MemoTransfers(msgs []*warptypes.MsgRemoteTransfer) []types.MemoTransfer {
+	transfers := make([]types.MemoTransfer, 0, len(msgs))
+	for _, msg := range msgs {
+		transfers = append(transfers, types.MemoTransfer{
+			Domain:    msg.DestinationDomain,
+			Recipient: fmt.Sprintf("0x%x", msg.Recipient[:]),
+			Amount:    msg.Amount.String(),
+		})
+	}
+	return transfers
+}
+
+// Signers returns the accounts that must sign a transaction built with opts, in signature order.
+// The multisig (or the authz grantee) signs first; a distinct fee payer takes the next signature slot.
+func (g *Generator) Signers(opts TxOptions) []string {
+	first := g.firstSigner(opts)
+	signers := []string{first}
+	if opts.FeePayer != "" && opts.FeePayer != first {
+		signers = append(signers, opts.FeePayer)
+	}
+	return signers
+}
+
+// firstSigner returns the account whose signature authorizes the messages
+func (g *Generator) firstSigner(opts TxOptions) string {
+	if opts.Grantee != "" {
+		return opts.Grantee
+	}
+	return g.multisigAddr
+}
+
+// packMsgs packs messages into Any values for a transaction body
+func packMsgs(msgs []sdk.Msg) ([]*codectypes.Any, error) {
+	anys := make([]*codectypes.Any, 0, len(msgs))
+	for i, msg := range msgs {
+		anyMsg, err := codectypes.NewAnyWithValue(msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack message %d: %w", i, err)
+		}
+		anys = append(anys, anyMsg)
+	}
+	return anys, nil
+}
+
+// MarshalTxJSON encodes a transaction as indented Cosmos SDK JSON
+func MarshalTxJSON(t *tx.Tx) ([]byte, error) {
+	data, err := cdc.MarshalJSON(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// UnmarshalTxJSON decodes a transaction from Cosmos SDK JSON
+func UnmarshalTxJSON(data []byte) (*tx.Tx, error) {
+	var t tx.Tx
+	if err := cdc.UnmarshalJSON(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// EncodeSignedTx encodes a signed transaction into the raw bytes accepted by BroadcastTx. The body
+// and auth info are encoded separately, as signed, into a TxRaw.
+func EncodeSignedTx(t *tx.Tx) ([]byte, error) {
+	if t.Body == nil || t.AuthInfo == nil {
+		return nil, fmt.Errorf("transaction has no body or auth info")
+	}
+	if len(t.Signatures) == 0 || len(t.Signatures) != len(t.AuthInfo.SignerInfos) {
+		return nil, fmt.Errorf("transaction is not fully signed: %d signatures for %d signers", len(t.Signatures), len(t.AuthInfo.SignerInfos))
+	}
+
+	bodyBytes, err := t.Body.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction body: %w", err)
+	}
+	authInfoBytes, err := t.AuthInfo.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction auth info: %w", err)
+	}
+
+	raw := &tx.TxRaw{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+		Signatures:    t.Signatures,
+	}
+	return raw.Marshal()
+}
+
+// DecodeTxRaw decodes a transaction in any of the formats signers produce: Cosmos SDK JSON
+// (body, auth_info and signatures, as written by generate and celestia-appd), TxRaw JSON, or the
+// encoded TxRaw bytes as base64 or hex text. The body and auth info keep the encoding they were
+// signed with when the input carries it.
+func DecodeTxRaw(data []byte) (*tx.TxRaw, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("transaction is empty")
+	}
+
+	if trimmed[0] == '{' {
+		if t, err := UnmarshalTxJSON(trimmed); err == nil && t.Body != nil {
+			return txRawFromTx(t)
+		}
+
+		var raw tx.TxRaw
+		if err := json.Unmarshal(trimmed, &raw); err == nil && len(raw.BodyBytes) > 0 {
+			return &raw, nil
+		}
+		return nil, fmt.Errorf("transaction is neither Cosmos SDK JSON nor TxRaw JSON")
+	}
+
+	// Encoded bytes, possibly quoted as a JSON string
+	text := string(trimmed)
+	if unquoted, err := jsonString(trimmed); err == nil {
+		text = unquoted
+	}
+	text = strings.TrimSpace(text)
+
+	if raw, err := decodeTxRawText(text); err == nil {
+		return raw, nil
+	}
+	return nil, fmt.Errorf("transaction is neither Cosmos SDK JSON, TxRaw JSON nor base64 or hex encoded TxRaw bytes")
+}
+
+// decodeTxRawText decodes TxRaw bytes encoded as hex, with an optional 0x prefix, or base64
+func decodeTxRawText(text string) (*tx.TxRaw, error) {
+	var candidates [][]byte
+	if b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(text, "0x"), "0X")); err == nil {
+		candidates = append(candidates, b)
+	}
+	if b, err := base64.StdEncoding.DecodeString(text); err == nil {
+		candidates = append(candidates, b)
+	}
+	if b, err := base64.URLEncoding.DecodeString(text); err == nil {
+		candidates = append(candidates, b)
+	}
+
+	for _, b := range candidates {
+		var raw tx.TxRaw
+		if err := raw.Unmarshal(b); err != nil || len(raw.BodyBytes) == 0 {
+			continue
+		}
+		var body tx.TxBody
+		if err := body.Unmarshal(raw.BodyBytes); err != nil {
+			continue
+		}
+		return &raw, nil
+	}
+	return nil, fmt.Errorf("not base64 or hex encoded TxRaw bytes")
+}
+
+// jsonString decodes data as a JSON string literal
+func jsonString(data []byte) (string, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// txRawFromTx encodes the body and auth info of a decoded transaction into a TxRaw
+func txRawFromTx(t *tx.Tx) (*tx.TxRaw, error) {
+	bodyBytes, err := t.Body.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction body: %w", err)
+	}
+	var authInfoBytes []byte
+	if t.AuthInfo != nil {
+		if authInfoBytes, err = t.AuthInfo.Marshal(); err != nil {
+			return nil, fmt.Errorf("failed to encode transaction auth info: %w", err)
+		}
+	}
+	return &tx.TxRaw{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: authInfoBytes,
+		Signatures:    t.Signatures,
+	}, nil
+}
@@ -0,0 +1,233 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+const (
+	testMultisig = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+	testFeePayer = "celestia1yg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zl2r5q4"
+)
+
+func TestBuildUnsignedTx(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+
+	msgs, err := gen.Generate(sampleRoutes())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	fee := sdk.NewCoins(sdk.NewCoin("utia", math.NewInt(20000)))
+	unsignedTx, err := gen.BuildUnsignedTx(msgs, TxOptions{GasLimit: 200000, Fee: fee})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+
+	if len(unsignedTx.Body.Messages) != len(msgs) {
+		t.Errorf("tx has %d messages, want %d", len(unsignedTx.Body.Messages), len(msgs))
+	}
+	if unsignedTx.AuthInfo.Fee.GasLimit != 200000 {
+		t.Errorf("GasLimit = %d, want 200000", unsignedTx.AuthInfo.Fee.GasLimit)
+	}
+	if unsignedTx.AuthInfo.Fee.Payer != "" {
+		t.Errorf("Payer = %s, want empty when multisig pays", unsignedTx.AuthInfo.Fee.Payer)
+	}
+
+	data, err := MarshalTxJSON(unsignedTx)
+	if err != nil {
+		t.Fatalf("MarshalTxJSON() error = %v", err)
+	}
+	if !strings.Contains(string(data), "/hyperlane.warp.v1.MsgRemoteTransfer") {
+		t.Errorf("tx JSON does not contain MsgRemoteTransfer type URL: %s", data)
+	}
+}
+
+func TestBuildUnsignedTxFeePayer(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+
+	msgs, err := gen.Generate(sampleRoutes())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		feePayer    string
+		wantPayer   string
+		wantSigners []string
+		wantErr     bool
+	}{
+		{
+			name:        "distinct fee payer",
+			feePayer:    testFeePayer,
+			wantPayer:   testFeePayer,
+			wantSigners: []string{testMultisig, testFeePayer},
+		},
+		{
+			name:        "fee payer equal to multisig",
+			feePayer:    testMultisig,
+			wantPayer:   "",
+			wantSigners: []string{testMultisig},
+		},
+		{
+			name:     "invalid fee payer",
+			feePayer: "not-an-address",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := TxOptions{FeePayer: tt.feePayer}
+			unsignedTx, err := gen.BuildUnsignedTx(msgs, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildUnsignedTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if unsignedTx.AuthInfo.Fee.Payer != tt.wantPayer {
+				t.Errorf("Payer = %s, want %s", unsignedTx.AuthInfo.Fee.Payer, tt.wantPayer)
+			}
+
+			signers := gen.Signers(opts)
+			if len(signers) != len(tt.wantSigners) {
+				t.Fatalf("Signers() = %v, want %v", signers, tt.wantSigners)
+			}
+			for i := range signers {
+				if signers[i] != tt.wantSigners[i] {
+					t.Errorf("Signers()[%d] = %s, want %s", i, signers[i], tt.wantSigners[i])
+				}
+			}
+		})
+	}
+}
+
+func sampleRoutes() *types.Routes {
+	return &types.Routes{
+		Routes: []types.HyperlaneRoute{
+			{
+				TxHash:      "ABC123",
+				BlockHeight: 1000000,
+				From:        "celestia1sender",
+				Amount:      "1000000",
+				Denom:       "utia",
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: 1380012617,
+					Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+					TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				},
+			},
+		},
+		TotalAmount:  "1000000",
+		MultisigAddr: testMultisig,
+	}
+}
+
+func TestEncodeSignedTx(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+
+	msgs, err := gen.Generate(sampleRoutes())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	signedTx, err := gen.BuildUnsignedTx(msgs, TxOptions{GasLimit: 200000})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+
+	if _, err := EncodeSignedTx(signedTx); err == nil {
+		t.Error("EncodeSignedTx() accepted an unsigned transaction")
+	}
+
+	signedTx.AuthInfo.SignerInfos = []*tx.SignerInfo{{Sequence: 4}}
+	signedTx.Signatures = [][]byte{[]byte("signature")}
+	data, err := EncodeSignedTx(signedTx)
+	if err != nil {
+		t.Fatalf("EncodeSignedTx() error = %v", err)
+	}
+
+	var raw tx.TxRaw
+	if err := raw.Unmarshal(data); err != nil {
+		t.Fatalf("failed to decode TxRaw: %v", err)
+	}
+	var body tx.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if len(body.Messages) != len(msgs) || len(raw.Signatures) != 1 {
+		t.Errorf("decoded %d messages and %d signatures, want %d and 1", len(body.Messages), len(raw.Signatures), len(msgs))
+	}
+}
+
+func TestDecodeTxRaw(t *testing.T) {
+	gen := NewGenerator(testMultisig)
+
+	msgs, err := gen.Generate(sampleRoutes())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	signedTx, err := gen.BuildUnsignedTx(msgs, TxOptions{GasLimit: 200000})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	signedTx.AuthInfo.SignerInfos = []*tx.SignerInfo{{Sequence: 4}}
+	signedTx.Signatures = [][]byte{[]byte("signature")}
+
+	txJSON, err := MarshalTxJSON(signedTx)
+	if err != nil {
+		t.Fatalf("MarshalTxJSON() error = %v", err)
+	}
+	encoded, err := EncodeSignedTx(signedTx)
+	if err != nil {
+		t.Fatalf("EncodeSignedTx() error = %v", err)
+	}
+	var want tx.TxRaw
+	if err := want.Unmarshal(encoded); err != nil {
+		t.Fatalf("failed to decode TxRaw: %v", err)
+	}
+	rawJSON, err := json.Marshal(&want)
+	if err != nil {
+		t.Fatalf("failed to encode TxRaw JSON: %v", err)
+	}
+	b64 := base64.StdEncoding.EncodeToString(encoded)
+
+	inputs := map[string][]byte{
+		"tx json":       txJSON,
+		"txraw json":    rawJSON,
+		"base64":        []byte(b64 + "\n"),
+		"quoted base64": []byte(`"` + b64 + `"`),
+		"hex":           []byte(hex.EncodeToString(encoded)),
+		"prefixed hex":  []byte("0x" + hex.EncodeToString(encoded)),
+	}
+	for name, data := range inputs {
+		raw, err := DecodeTxRaw(data)
+		if err != nil {
+			t.Errorf("%s: DecodeTxRaw() error = %v", name, err)
+			continue
+		}
+		if !bytes.Equal(raw.BodyBytes, want.BodyBytes) || !bytes.Equal(raw.AuthInfoBytes, want.AuthInfoBytes) {
+			t.Errorf("%s: decoded body or auth info differs from the encoded transaction", name)
+		}
+		if len(raw.Signatures) != 1 || string(raw.Signatures[0]) != "signature" {
+			t.Errorf("%s: decoded signatures %q, want the signature", name, raw.Signatures)
+		}
+	}
+
+	for _, data := range []string{"", "{}", "not a transaction", "deadbeef"} {
+		if _, err := DecodeTxRaw([]byte(data)); err == nil {
+			t.Errorf("DecodeTxRaw(%q) accepted an invalid transaction", data)
+		}
+	}
+}
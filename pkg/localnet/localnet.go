@@ -0,0 +1,313 @@
+// Package localnet drives a local hyperlane-cosmos chain running in docker compose, so integration
+// tests can fund a multisig, send deposits with routing metadata and run the rebalancer against
+// real chain data. The chain is defined in integration/localnet.
+package localnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Config describes the localnet's compose project and chain
+type Config struct {
+	ComposeFile string // docker-compose.yml defining the chain
+	Project     string // Compose project name, so concurrent runs do not share containers
+	Service     string // Service running the node
+	Binary      string // Node binary inside the container
+	Home        string // Node home inside the container, holding the test keyring
+
+	ChainID string
+	Denom   string
+	Fees    string // Fees paid by every transaction the harness sends
+
+	GRPCAddr string // gRPC endpoint published on the host
+
+	LocalDomain uint32 // Hyperlane domain of the localnet's mailbox
+
+	// StartTimeout bounds how long Start waits for the chain to produce blocks
+	StartTimeout time.Duration
+}
+
+// DefaultConfig returns the config of the chain defined by composeFile, which is
+// integration/localnet/docker-compose.yml in this repository
+func DefaultConfig(composeFile string) Config {
+	return Config{
+		ComposeFile:  composeFile,
+		Project:      "rebalancer-localnet",
+		Service:      "node",
+		Binary:       "hypd",
+		Home:         "/root/.hypd",
+		ChainID:      "rebalancer-localnet",
+		Denom:        "uhyp",
+		Fees:         "2000uhyp",
+		GRPCAddr:     "localhost:9090",
+		LocalDomain:  1337,
+		StartTimeout: 5 * time.Minute,
+	}
+}
+
+// Localnet is a running localnet
+type Localnet struct {
+	config Config
+	client *client.Client
+
+	// run executes a command on the host and returns its standard output
+	run func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// Start builds and starts the localnet and waits until it produces blocks. Stop removes it.
+func Start(ctx context.Context, config Config) (*Localnet, error) {
+	l := &Localnet{config: config, run: runCommand}
+	if _, err := l.compose(ctx, "up", "--build", "--detach", "--wait"); err != nil {
+		return nil, fmt.Errorf("failed to start localnet: %w", err)
+	}
+	if err := l.connect(ctx); err != nil {
+		l.Stop(context.Background())
+		return nil, err
+	}
+	return l, nil
+}
+
+// Attach connects to a localnet that is already running, e.g. started with make localnet-up
+func Attach(ctx context.Context, config Config) (*Localnet, error) {
+	l := &Localnet{config: config, run: runCommand}
+	if err := l.connect(ctx); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// connect dials the node and waits for its first blocks
+func (l *Localnet) connect(ctx context.Context) error {
+	c, err := client.NewClient(ctx, l.config.GRPCAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to localnet at %s: %w", l.config.GRPCAddr, err)
+	}
+	l.client = c
+
+	waitCtx, cancel := context.WithTimeout(ctx, l.config.StartTimeout)
+	defer cancel()
+	return l.WaitForHeight(waitCtx, 2)
+}
+
+// Config returns the localnet's config
+func (l *Localnet) Config() Config {
+	return l.config
+}
+
+// Client returns a gRPC client of the node
+func (l *Localnet) Client() *client.Client {
+	return l.client
+}
+
+// Close closes the client without stopping the localnet
+func (l *Localnet) Close() error {
+	if l.client == nil {
+		return nil
+	}
+	return l.client.Close()
+}
+
+// Stop closes the client and removes the localnet's containers and volumes
+func (l *Localnet) Stop(ctx context.Context) error {
+	l.Close()
+	if _, err := l.compose(ctx, "down", "--volumes"); err != nil {
+		return fmt.Errorf("failed to stop localnet: %w", err)
+	}
+	return nil
+}
+
+// WaitForHeight waits until the chain has reached height
+func (l *Localnet) WaitForHeight(ctx context.Context, height int64) error {
+	for {
+		latest, err := l.client.LatestHeight()
+		if err == nil && latest >= height {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("localnet did not reach height %d (latest %d, %v): %w", height, latest, err, ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Exec runs the node binary in the container with args and the node's home, returning its output
+func (l *Localnet) Exec(ctx context.Context, args ...string) ([]byte, error) {
+	execArgs := append([]string{"exec", "-T", l.config.Service, l.config.Binary}, args...)
+	execArgs = append(execArgs, "--home", l.config.Home)
+	return l.compose(ctx, execArgs...)
+}
+
+// compose runs docker compose on the localnet's project
+func (l *Localnet) compose(ctx context.Context, args ...string) ([]byte, error) {
+	composeArgs := append([]string{"compose", "--file", l.config.ComposeFile, "--project-name", l.config.Project}, args...)
+	return l.run(ctx, "docker", composeArgs...)
+}
+
+// Address returns the address of key in the container's test keyring
+func (l *Localnet) Address(ctx context.Context, key string) (string, error) {
+	out, err := l.Exec(ctx, "keys", "show", key, "--address", "--keyring-backend", "test")
+	if err != nil {
+		return "", fmt.Errorf("failed to look up key %s: %w", key, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// AddMultisig adds a threshold multisig of keys named name to the test keyring and returns its
+// address. The multisig only exists on chain once it has been funded.
+func (l *Localnet) AddMultisig(ctx context.Context, name string, threshold int, keys ...string) (string, error) {
+	if _, err := l.Exec(ctx, "keys", "add", name, "--multisig", strings.Join(keys, ","),
+		"--multisig-threshold", strconv.Itoa(threshold), "--keyring-backend", "test"); err != nil {
+		return "", fmt.Errorf("failed to add multisig %s: %w", name, err)
+	}
+	return l.Address(ctx, name)
+}
+
+// txResult is the part of the node's JSON transaction output the harness reads
+type txResult struct {
+	TxHash string `json:"txhash"`
+	Code   uint32 `json:"code"`
+	RawLog string `json:"raw_log"`
+}
+
+// parseTxResult decodes the JSON output of a transaction command and checks it was accepted
+func parseTxResult(out []byte) (string, error) {
+	var result txResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return "", fmt.Errorf("failed to decode transaction output %q: %w", strings.TrimSpace(string(out)), err)
+	}
+	if result.TxHash == "" {
+		return "", fmt.Errorf("transaction output has no tx hash: %s", strings.TrimSpace(string(out)))
+	}
+	if result.Code != 0 {
+		return result.TxHash, fmt.Errorf("tx %s rejected with code %d: %s", result.TxHash, result.Code, result.RawLog)
+	}
+	return result.TxHash, nil
+}
+
+// Tx sends a transaction signed by key from the test keyring, such as "bank send ...", waits until
+// it is included and returns its hash
+func (l *Localnet) Tx(ctx context.Context, key string, args ...string) (string, error) {
+	txArgs := append([]string{"tx"}, args...)
+	txArgs = append(txArgs, "--from", key, "--chain-id", l.config.ChainID, "--keyring-backend", "test",
+		"--fees", l.config.Fees, "--yes", "--output", "json")
+	out, err := l.Exec(ctx, txArgs...)
+	if err != nil {
+		return "", err
+	}
+	hash, err := parseTxResult(out)
+	if err != nil {
+		return hash, err
+	}
+	return hash, l.waitForTx(ctx, hash)
+}
+
+// waitForTx waits until hash is included and checks that it succeeded
+func (l *Localnet) waitForTx(ctx context.Context, hash string) error {
+	resp, err := l.client.WaitForTx(hash, time.Minute, 500*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("tx %s failed with code %d: %s", hash, resp.Code, resp.RawLog)
+	}
+	return nil
+}
+
+// Send sends coin from key to address with memo
+func (l *Localnet) Send(ctx context.Context, key, to string, coin sdk.Coin, memo string) (string, error) {
+	args := []string{"bank", "send", key, to, coin.String()}
+	if memo != "" {
+		args = append(args, "--note", memo)
+	}
+	return l.Tx(ctx, key, args...)
+}
+
+// Deposit sends coin from key to multisig with route as the routing metadata in the memo, the way
+// depositors route funds through the multisig
+func (l *Localnet) Deposit(ctx context.Context, key, multisig string, coin sdk.Coin, route client.RoutingMetadata) (string, error) {
+	memo, err := json.Marshal(route)
+	if err != nil {
+		return "", err
+	}
+	return l.Send(ctx, key, multisig, coin, string(memo))
+}
+
+// CreateWarpRoute creates a mailbox on the local domain and a collateral warp token for the chain's
+// denom owned by key, with remoteRouter enrolled for remoteDomain, and returns the token ID
+func (l *Localnet) CreateWarpRoute(ctx context.Context, key string, remoteDomain uint32, remoteRouter string) (string, error) {
+	out, err := l.compose(ctx, "exec", "-T", l.config.Service, "setup-warp.sh", key,
+		strconv.FormatUint(uint64(l.config.LocalDomain), 10), strconv.FormatUint(uint64(remoteDomain), 10), remoteRouter)
+	if err != nil {
+		return "", fmt.Errorf("failed to create warp route: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]), nil
+}
+
+// SignMultisig signs the unsigned transaction in unsignedFile with every signer key for the
+// multisig key and returns the combined, fully signed transaction as JSON
+func (l *Localnet) SignMultisig(ctx context.Context, unsignedFile, multisig string, signers ...string) ([]byte, error) {
+	unsigned, err := l.copyIn(ctx, unsignedFile)
+	if err != nil {
+		return nil, err
+	}
+	multisigAddr, err := l.Address(ctx, multisig)
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []string
+	for _, signer := range signers {
+		signature := path.Join("/tmp", fmt.Sprintf("%s-%s.json", path.Base(unsigned), signer))
+		if _, err := l.Exec(ctx, "tx", "sign", unsigned, "--from", signer, "--multisig", multisigAddr,
+			"--chain-id", l.config.ChainID, "--keyring-backend", "test", "--output-document", signature); err != nil {
+			return nil, fmt.Errorf("failed to sign as %s: %w", signer, err)
+		}
+		signatures = append(signatures, signature)
+	}
+
+	args := append([]string{"tx", "multisign", unsigned, multisig}, signatures...)
+	args = append(args, "--chain-id", l.config.ChainID, "--keyring-backend", "test")
+	signed, err := l.Exec(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine signatures: %w", err)
+	}
+	return signed, nil
+}
+
+// copyIn copies a host file into the container's /tmp and returns its path there
+func (l *Localnet) copyIn(ctx context.Context, file string) (string, error) {
+	target := path.Join("/tmp", fmt.Sprintf("%d-%s", time.Now().UnixNano(), path.Base(file)))
+	if _, err := l.compose(ctx, "cp", file, l.config.Service+":"+target); err != nil {
+		return "", fmt.Errorf("failed to copy %s into the localnet: %w", file, err)
+	}
+	return target, nil
+}
+
+// runCommand runs name with args, returning its standard output or an error with its standard error
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if os.Getenv("LOCALNET_VERBOSE") != "" {
+		fmt.Fprintf(os.Stderr, "+ %s %s\n", name, strings.Join(args, " "))
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package localnet
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// recorder stands in for docker, recording the commands run and answering with fixed output
+type recorder struct {
+	commands []string
+	output   string
+}
+
+func (r *recorder) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	r.commands = append(r.commands, name+" "+strings.Join(args, " "))
+	return []byte(r.output), nil
+}
+
+func TestExec(t *testing.T) {
+	r := &recorder{output: "hyp1multisig\n"}
+	l := &Localnet{config: DefaultConfig("integration/localnet/docker-compose.yml"), run: r.run}
+
+	addr, err := l.AddMultisig(context.Background(), "multisig", 2, "signer1", "signer2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != "hyp1multisig" {
+		t.Errorf("AddMultisig() = %q, want hyp1multisig", addr)
+	}
+
+	want := "docker compose --file integration/localnet/docker-compose.yml --project-name rebalancer-localnet " +
+		"exec -T node hypd keys add multisig --multisig signer1,signer2 --multisig-threshold 2 --keyring-backend test --home /root/.hypd"
+	if len(r.commands) != 2 || r.commands[0] != want {
+		t.Errorf("commands = %q, want %q first", r.commands, want)
+	}
+}
+
+func TestDepositMemo(t *testing.T) {
+	r := &recorder{output: `{"txhash":"ABC","code":5,"raw_log":"insufficient funds"}`}
+	l := &Localnet{config: DefaultConfig("docker-compose.yml"), run: r.run}
+
+	route := client.RoutingMetadata{DestinationDomain: 1380012617, Recipient: "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", TokenID: "0x1234"}
+	_, err := l.Deposit(context.Background(), "depositor", "hyp1multisig", sdk.NewCoin("uhyp", math.NewInt(1000)), route)
+	if err == nil || !strings.Contains(err.Error(), "insufficient funds") {
+		t.Errorf("Deposit() error = %v, want the rejection", err)
+	}
+	if len(r.commands) != 1 || !strings.Contains(r.commands[0], `bank send depositor hyp1multisig 1000uhyp --note {"destination_domain":1380012617,`) {
+		t.Errorf("commands = %q, want a bank send with the routing metadata as note", r.commands)
+	}
+}
+
+func TestParseTxResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    string
+		wantErr bool
+	}{
+		{name: "accepted", out: `{"height":"0","txhash":"ABC123","code":0}`, want: "ABC123"},
+		{name: "rejected", out: `{"txhash":"ABC123","code":13,"raw_log":"insufficient fee"}`, want: "ABC123", wantErr: true},
+		{name: "no hash", out: `{"code":0}`, wantErr: true},
+		{name: "not json", out: "Error: key not found", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := parseTxResult([]byte(tt.out))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTxResult() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if hash != tt.want {
+				t.Errorf("parseTxResult() = %q, want %q", hash, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package metrics exposes the rebalancer's Prometheus metrics. The parser, generator, verifier and
+// client record into the collectors below as they run; long-running commands such as watch serve
+// them over HTTP for Prometheus to scrape.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "rebalancer"
+
+// Registry holds the rebalancer's metrics along with the Go runtime and process metrics. A
+// dedicated registry keeps the metrics of the libraries the rebalancer imports out of /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// BlocksScanned counts the heights whose transactions were queried and parsed
+	BlocksScanned = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_scanned_total",
+		Help:      "Heights whose transactions were queried and parsed.",
+	})
+	// BlockQueryErrors counts the heights that could not be queried
+	BlockQueryErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "block_query_errors_total",
+		Help:      "Heights whose transactions could not be queried.",
+	})
+	// DecodeErrors counts the transactions and messages that could not be decoded
+	DecodeErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "decode_errors_total",
+		Help:      "Transactions and messages that could not be decoded.",
+	})
+	// RoutesDiscovered counts the deposits to the multisig turned into routes
+	RoutesDiscovered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "routes_discovered_total",
+		Help:      "Deposits to the multisig turned into routes.",
+	})
+	// RoutesRejected counts the deposits to the multisig that could not be routed, by reason
+	RoutesRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "routes_rejected_total",
+		Help:      "Deposits to the multisig that could not be routed, by reason.",
+	}, []string{"reason"})
+	// MessagesGenerated counts the MsgRemoteTransfers generated from routes
+	MessagesGenerated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_generated_total",
+		Help:      "MsgRemoteTransfers generated from routes.",
+	})
+	// GenerationErrors counts the routes a MsgRemoteTransfer could not be generated for
+	GenerationErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "generation_errors_total",
+		Help:      "Routes a MsgRemoteTransfer could not be generated for.",
+	})
+	// Verifications counts transaction verifications by result: valid, invalid or error
+	Verifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verifications_total",
+		Help:      "Transaction verifications against their routes, by result.",
+	}, []string{"result"})
+	// BroadcastDuration observes how long broadcasting took, by stage: checktx until the node
+	// accepted the transaction, inclusion until it was found in a block
+	BroadcastDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "broadcast_duration_seconds",
+		Help:      "Time to get a transaction accepted by the node (checktx) and included in a block (inclusion).",
+		Buckets:   []float64{0.1, 0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+	}, []string{"stage"})
+	// CheckpointHeight is the height up to which a watched source has been processed
+	CheckpointHeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "checkpoint_height",
+		Help:      "Height up to which a watched source has been processed.",
+	}, []string{"source"})
+	// ChainHeight is the latest height of a watched source chain
+	ChainHeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_height",
+		Help:      "Latest height of a watched source chain.",
+	}, []string{"source"})
+)
+
+// Reasons a deposit is rejected, the values of the reason label of RoutesRejected
+const (
+	RejectWhitelist    = "whitelist"     // Route not allowed by the config
+	RejectRouting      = "routing"       // Missing or invalid routing information
+	RejectForeignDenom = "foreign_denom" // Deposit of a denom the chain config does not accept
+	RejectAmount       = "amount"        // Metadata amount above the amount deposited
+	RejectMetadataSize = "metadata_size" // Metadata above the configured max_size, not decoded
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		BlocksScanned,
+		BlockQueryErrors,
+		DecodeErrors,
+		RoutesDiscovered,
+		RoutesRejected,
+		MessagesGenerated,
+		GenerationErrors,
+		Verifications,
+		BroadcastDuration,
+		CheckpointHeight,
+		ChainHeight,
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Serve serves the metrics at /metrics on listener until ctx is done
+func Serve(ctx context.Context, listener net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+	return nil
+}
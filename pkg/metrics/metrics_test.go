@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, listener) }()
+
+	BlocksScanned.Add(3)
+	RoutesRejected.WithLabelValues(RejectWhitelist).Inc()
+	CheckpointHeight.WithLabelValues("celestia").Set(2500042)
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"rebalancer_blocks_scanned_total 3",
+		`rebalancer_routes_rejected_total{reason="whitelist"} 1`,
+		`rebalancer_checkpoint_height{source="celestia"} 2.500042e+06`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics does not contain %q", want)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v after shutdown", err)
+	}
+}
@@ -0,0 +1,328 @@
+// Package multisig collects the partial signatures of a multisig's key holders on a generated
+// transaction and combines them into a transaction signed by the LegacyAminoPubKey multisig.
+package multisig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	txsigning "cosmossdk.io/x/tx/signing"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/codec/address"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	cryptomultisig "github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// SignMode is the sign mode key holders sign in. Ledger devices and celestia-appd tx sign
+// --sign-mode amino-json both support it, and LegacyAminoPubKey multisigs require it of members.
+const SignMode = signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+
+// Params identify the multisig account state the signatures are made for
+type Params struct {
+	ChainID       string
+	Bech32Prefix  string
+	AccountNumber uint64
+	Sequence      uint64
+}
+
+// SignDoc describes what each key holder signs, so it can be reviewed and signed offline
+type SignDoc struct {
+	ChainID       string   `json:"chain_id"`
+	AccountNumber uint64   `json:"account_number"`
+	Sequence      uint64   `json:"sequence"`
+	SignMode      string   `json:"sign_mode"`
+	Multisig      string   `json:"multisig"`
+	Threshold     uint32   `json:"threshold"`
+	Members       []string `json:"members"`
+	SignBytes     string   `json:"sign_bytes"` // Exact bytes to sign: the canonical amino JSON document
+	Digest        string   `json:"digest"`     // Hex SHA-256 of SignBytes, to compare between key holders
+}
+
+// Session combines partial signatures on one unsigned transaction
+type Session struct {
+	txConfig client.TxConfig
+	builder  client.TxBuilder
+	pubKey   *kmultisig.LegacyAminoPubKey
+	params   Params
+	address  string
+}
+
+// NewSession loads the unsigned transaction and the multisig public key (the JSON printed by
+// celestia-appd keys show --pubkey). The multisig must be the transaction's only signer.
+func NewSession(txJSON, pubKeyJSON []byte, params Params) (*Session, error) {
+	if params.ChainID == "" {
+		return nil, fmt.Errorf("chain ID is required")
+	}
+	txConfig, cdc, err := newTxConfig(params.Bech32Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var pubKey cryptotypes.PubKey
+	if err := cdc.UnmarshalInterfaceJSON(pubKeyJSON, &pubKey); err != nil {
+		return nil, fmt.Errorf("failed to parse multisig public key: %w", err)
+	}
+	multisigKey, ok := pubKey.(*kmultisig.LegacyAminoPubKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is a %T, not a multisig key", pubKey)
+	}
+	addr, err := sdk.Bech32ifyAddressBytes(params.Bech32Prefix, multisigKey.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	sdkTx, err := txConfig.TxJSONDecoder()(txJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+	builder, err := txConfig.WrapTxBuilder(sdkTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+
+	// A distinct fee payer or an authz grantee adds signers the multisig key cannot sign for
+	signers, err := builder.GetTx().GetSigners()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the transaction's signers: %w", err)
+	}
+	if len(signers) != 1 || !bytes.Equal(signers[0], multisigKey.Address()) {
+		return nil, fmt.Errorf("transaction must be signed by %s alone, it requires %d signers", addr, len(signers))
+	}
+
+	return &Session{
+		txConfig: txConfig,
+		builder:  builder,
+		pubKey:   multisigKey,
+		params:   params,
+		address:  addr,
+	}, nil
+}
+
+// newTxConfig returns a tx config that can decode generated transactions and derive their signers
+func newTxConfig(bech32Prefix string) (client.TxConfig, codec.Codec, error) {
+	registry, err := codectypes.NewInterfaceRegistryWithOptions(codectypes.InterfaceRegistryOptions{
+		ProtoFiles: proto.HybridResolver,
+		SigningOptions: txsigning.Options{
+			AddressCodec:          address.NewBech32Codec(bech32Prefix),
+			ValidatorAddressCodec: address.NewBech32Codec(bech32Prefix + sdk.PrefixValidator + sdk.PrefixOperator),
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create interface registry: %w", err)
+	}
+	generator.RegisterInterfaces(registry)
+	cdc := codec.NewProtoCodec(registry)
+	return authtx.NewTxConfig(cdc, authtx.DefaultSignModes), cdc, nil
+}
+
+// Address returns the bech32 address of the multisig key
+func (s *Session) Address() string {
+	return s.address
+}
+
+// SignBytes returns the bytes each key holder signs
+func (s *Session) SignBytes() ([]byte, error) {
+	signerData := authsigning.SignerData{
+		Address:       s.address,
+		ChainID:       s.params.ChainID,
+		AccountNumber: s.params.AccountNumber,
+		Sequence:      s.params.Sequence,
+		PubKey:        s.pubKey,
+	}
+	signBytes, err := authsigning.GetSignBytesAdapter(context.Background(), s.txConfig.SignModeHandler(), SignMode, signerData, s.builder.GetTx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute sign bytes: %w", err)
+	}
+	return signBytes, nil
+}
+
+// SignDoc returns the sign document to hand to the key holders
+func (s *Session) SignDoc() (*SignDoc, error) {
+	signBytes, err := s.SignBytes()
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.members()
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(signBytes)
+
+	return &SignDoc{
+		ChainID:       s.params.ChainID,
+		AccountNumber: s.params.AccountNumber,
+		Sequence:      s.params.Sequence,
+		SignMode:      SignMode.String(),
+		Multisig:      s.address,
+		Threshold:     s.pubKey.Threshold,
+		Members:       members,
+		SignBytes:     string(signBytes),
+		Digest:        hex.EncodeToString(digest[:]),
+	}, nil
+}
+
+// members returns the addresses of the multisig's keys, in multisig order
+func (s *Session) members() ([]string, error) {
+	var members []string
+	for _, key := range s.pubKey.GetPubKeys() {
+		addr, err := sdk.Bech32ifyAddressBytes(s.params.Bech32Prefix, key.Address())
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, addr)
+	}
+	return members, nil
+}
+
+// ParseSignatures decodes a key holder's partial signature file: the JSON written by celestia-appd
+// tx sign --signature-only, or the full transaction it writes without that flag
+func (s *Session) ParseSignatures(data []byte) ([]signingtypes.SignatureV2, error) {
+	sigs, err := s.txConfig.UnmarshalSignatureJSON(data)
+	if err == nil {
+		return sigs, nil
+	}
+
+	signedTx, txErr := s.txConfig.TxJSONDecoder()(data)
+	if txErr != nil {
+		return nil, fmt.Errorf("not a signature or signed transaction file: %w", err)
+	}
+	sigTx, ok := signedTx.(authsigning.SigVerifiableTx)
+	if !ok {
+		return nil, fmt.Errorf("transaction does not carry signatures")
+	}
+	return sigTx.GetSignaturesV2()
+}
+
+// Combine verifies the partial signatures against the sign bytes and returns the transaction
+// signed by the multisig, in the JSON format of celestia-appd tx multisign. At least the
+// multisig's threshold of distinct members must have signed.
+func (s *Session) Combine(partials []signingtypes.SignatureV2) ([]byte, error) {
+	signBytes, err := s.SignBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := s.pubKey.GetPubKeys()
+	multiSig := cryptomultisig.NewMultisig(len(keys))
+	signed := make(map[int]bool)
+	for _, sig := range partials {
+		member := -1
+		for i, key := range keys {
+			if sig.PubKey != nil && key.Equals(sig.PubKey) {
+				member = i
+				break
+			}
+		}
+		if member < 0 {
+			return nil, fmt.Errorf("signature by %s is not from a member of %s", s.keyAddress(sig.PubKey), s.address)
+		}
+		addr := s.keyAddress(sig.PubKey)
+
+		data, ok := sig.Data.(*signingtypes.SingleSignatureData)
+		if !ok {
+			return nil, fmt.Errorf("signature by %s is not a single-key signature", addr)
+		}
+		if data.SignMode != SignMode {
+			return nil, fmt.Errorf("signature by %s uses sign mode %s, want %s", addr, data.SignMode, SignMode)
+		}
+		if sig.Sequence != s.params.Sequence {
+			return nil, fmt.Errorf("signature by %s is for sequence %d, want %d", addr, sig.Sequence, s.params.Sequence)
+		}
+		if !sig.PubKey.VerifySignature(signBytes, data.Signature) {
+			return nil, fmt.Errorf("signature by %s does not match the sign document", addr)
+		}
+		if signed[member] {
+			return nil, fmt.Errorf("duplicate signature by %s", addr)
+		}
+
+		if err := cryptomultisig.AddSignatureV2(multiSig, sig, keys); err != nil {
+			return nil, fmt.Errorf("failed to add signature by %s: %w", addr, err)
+		}
+		signed[member] = true
+	}
+	if uint32(len(signed)) < s.pubKey.Threshold {
+		return nil, fmt.Errorf("%d of %d required signatures collected", len(signed), s.pubKey.Threshold)
+	}
+
+	err = s.builder.SetSignatures(signingtypes.SignatureV2{
+		PubKey:   s.pubKey,
+		Data:     multiSig,
+		Sequence: s.params.Sequence,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set multisig signature: %w", err)
+	}
+
+	data, err := s.txConfig.TxJSONEncoder()(s.builder.GetTx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed transaction: %w", err)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// Sequence returns the sequence the signatures are made for
+func (s *Session) Sequence() uint64 {
+	return s.params.Sequence
+}
+
+// GasLimit returns the gas limit the transaction was generated with
+func (s *Session) GasLimit() uint64 {
+	return s.builder.GetTx().GetGas()
+}
+
+// SimulationTx returns the encoded transaction carrying a placeholder multisig signature by the
+// threshold of members at sequence, for the node's Simulate endpoint. Simulation skips signature
+// verification but charges the gas of verifying each member signature, so the gas used matches
+// the transaction once signed.
+func (s *Session) SimulationTx(sequence uint64) ([]byte, error) {
+	keys := s.pubKey.GetPubKeys()
+	multiSig := cryptomultisig.NewMultisig(len(keys))
+	for i := 0; i < int(s.pubKey.Threshold) && i < len(keys); i++ {
+		multiSig.BitArray.SetIndex(i, true)
+		// secp256k1 signatures are 64 bytes, so the transaction size gas matches too
+		multiSig.Signatures = append(multiSig.Signatures, &signingtypes.SingleSignatureData{SignMode: SignMode, Signature: make([]byte, 64)})
+	}
+
+	err := s.builder.SetSignatures(signingtypes.SignatureV2{
+		PubKey:   s.pubKey,
+		Data:     multiSig,
+		Sequence: sequence,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set placeholder signature: %w", err)
+	}
+	txBytes, err := s.txConfig.TxEncoder()(s.builder.GetTx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	return txBytes, nil
+}
+
+// keyAddress returns the bech32 address of a key for error messages
+func (s *Session) keyAddress(key cryptotypes.PubKey) string {
+	if key == nil {
+		return "an unknown key"
+	}
+	addr, err := sdk.Bech32ifyAddressBytes(s.params.Bech32Prefix, key.Address())
+	if err != nil {
+		return key.Address().String()
+	}
+	return addr
+}
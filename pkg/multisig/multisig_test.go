@@ -0,0 +1,148 @@
+package multisig
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+)
+
+func TestCombine(t *testing.T) {
+	keys := []*secp256k1.PrivKey{secp256k1.GenPrivKey(), secp256k1.GenPrivKey(), secp256k1.GenPrivKey()}
+	var pubKeys []cryptotypes.PubKey
+	for _, key := range keys {
+		pubKeys = append(pubKeys, key.PubKey())
+	}
+	multisigKey := kmultisig.NewLegacyAminoPubKey(2, pubKeys)
+	multisigAddr := sdk.MustBech32ifyAddressBytes("celestia", multisigKey.Address())
+
+	_, cdc, err := newTxConfig("celestia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKeyJSON, err := cdc.MarshalInterfaceJSON(multisigKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen := generator.NewGenerator(multisigAddr)
+	msgs, err := gen.Generate(&types.Routes{Routes: []types.HyperlaneRoute{{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsignedTx, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{GasLimit: 200000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txJSON, err := generator.MarshalTxJSON(unsignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := Params{ChainID: "celestia", Bech32Prefix: "celestia", AccountNumber: 12, Sequence: 3}
+	session, err := NewSession(txJSON, pubKeyJSON, params)
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	if session.Address() != multisigAddr {
+		t.Errorf("Address() = %s, want %s", session.Address(), multisigAddr)
+	}
+	doc, err := session.SignDoc()
+	if err != nil {
+		t.Fatalf("SignDoc() error = %v", err)
+	}
+	if doc.Threshold != 2 || len(doc.Members) != 3 {
+		t.Errorf("sign doc has threshold %d and %d members, want 2 and 3", doc.Threshold, len(doc.Members))
+	}
+
+	sign := func(key *secp256k1.PrivKey, signBytes []byte, sequence uint64) signingtypes.SignatureV2 {
+		sig, err := key.Sign(signBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signingtypes.SignatureV2{
+			PubKey:   key.PubKey(),
+			Data:     &signingtypes.SingleSignatureData{SignMode: SignMode, Signature: sig},
+			Sequence: sequence,
+		}
+	}
+	signBytes := []byte(doc.SignBytes)
+
+	if _, err := session.Combine([]signingtypes.SignatureV2{sign(keys[0], signBytes, 3)}); err == nil {
+		t.Error("Combine() accepted fewer signatures than the threshold")
+	}
+	if _, err := session.Combine([]signingtypes.SignatureV2{sign(keys[0], signBytes, 3), sign(keys[0], signBytes, 3)}); err == nil {
+		t.Error("Combine() counted a duplicate signature towards the threshold")
+	}
+	if _, err := session.Combine([]signingtypes.SignatureV2{sign(keys[0], signBytes, 3), sign(keys[1], []byte("other"), 3)}); err == nil {
+		t.Error("Combine() accepted a signature over other bytes")
+	}
+	if _, err := session.Combine([]signingtypes.SignatureV2{sign(keys[0], signBytes, 3), sign(keys[1], signBytes, 4)}); err == nil {
+		t.Error("Combine() accepted a signature for another sequence")
+	}
+	if _, err := session.Combine([]signingtypes.SignatureV2{sign(keys[0], signBytes, 3), sign(secp256k1.GenPrivKey(), signBytes, 3)}); err == nil {
+		t.Error("Combine() accepted a signature by a non-member")
+	}
+
+	signed, err := session.Combine([]signingtypes.SignatureV2{sign(keys[2], signBytes, 3), sign(keys[0], signBytes, 3)})
+	if err != nil {
+		t.Fatalf("Combine() error = %v", err)
+	}
+	signedTx, err := generator.UnmarshalTxJSON(signed)
+	if err != nil {
+		t.Fatalf("UnmarshalTxJSON() error = %v", err)
+	}
+	if len(signedTx.Signatures) != 1 || len(signedTx.AuthInfo.SignerInfos) != 1 {
+		t.Fatalf("signed tx has %d signatures and %d signer infos, want 1 each", len(signedTx.Signatures), len(signedTx.AuthInfo.SignerInfos))
+	}
+	if _, err := generator.EncodeSignedTx(signedTx); err != nil {
+		t.Errorf("EncodeSignedTx() error = %v", err)
+	}
+
+	simulated, err := session.SimulationTx(5)
+	if err != nil {
+		t.Fatalf("SimulationTx() error = %v", err)
+	}
+	decoded, err := session.txConfig.TxDecoder()(simulated)
+	if err != nil {
+		t.Fatalf("failed to decode simulation tx: %v", err)
+	}
+	sigs, err := decoded.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 1 || sigs[0].Sequence != 5 {
+		t.Fatalf("simulation tx has signatures %+v, want one at sequence 5", sigs)
+	}
+	if data, ok := sigs[0].Data.(*signingtypes.MultiSignatureData); !ok || len(data.Signatures) != 2 {
+		t.Errorf("simulation tx signature = %+v, want placeholders by the threshold of 2 members", sigs[0].Data)
+	}
+
+	// A transaction with a distinct fee payer needs a second signer
+	feePaid, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{GasLimit: 200000, FeePayer: "celestia1yg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zl2r5q4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feePaidJSON, err := generator.MarshalTxJSON(feePaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSession(feePaidJSON, pubKeyJSON, params); err == nil {
+		t.Error("NewSession() accepted a transaction with a distinct fee payer")
+	}
+}
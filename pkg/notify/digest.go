@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// KindRoutesDiscovered marks events about newly discovered deposits, which digests batch
+const KindRoutesDiscovered = "routes_discovered"
+
+// digestMessages is how many of the batched events' messages a digest quotes
+const digestMessages = 10
+
+// Flusher is a notifier that holds events back until it is flushed
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Flush delivers the events n holds back, if it holds any back
+func Flush(ctx context.Context, n Notifier) error {
+	if f, ok := n.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Digest batches the route discovery events raised within a window into a single digest event
+// with their totals. The window starts with the first held-back event; other events pass through.
+type Digest struct {
+	next      Notifier
+	window    time.Duration
+	reportURL string
+
+	// OnError, if set, is called with errors of digests delivered when a window elapses
+	OnError func(error)
+
+	mu      sync.Mutex
+	pending []Event
+	timer   *time.Timer
+}
+
+// NewDigest wraps next so that route discovery events reach it at most once per window. Each
+// digest links to reportURL if it is set.
+func NewDigest(next Notifier, window time.Duration, reportURL string) *Digest {
+	return &Digest{next: next, window: window, reportURL: reportURL}
+}
+
+// Notify implements Notifier
+func (d *Digest) Notify(ctx context.Context, event Event) error {
+	if event.Kind != KindRoutesDiscovered {
+		return d.next.Notify(ctx, event)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending = append(d.pending, event)
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.window, func() {
+			if err := d.Flush(context.Background()); err != nil && d.OnError != nil {
+				d.OnError(err)
+			}
+		})
+	}
+	return nil
+}
+
+// Flush implements Flusher. A single held-back event is delivered unchanged.
+func (d *Digest) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	events := d.pending
+	d.pending = nil
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.mu.Unlock()
+
+	switch len(events) {
+	case 0:
+		return nil
+	case 1:
+		return d.next.Notify(ctx, events[0])
+	default:
+		return d.next.Notify(ctx, d.digest(events))
+	}
+}
+
+// digest summarizes events into one event
+func (d *Digest) digest(events []Event) Event {
+	first, last := events[0], events[len(events)-1]
+	summary := Event{
+		Kind:     KindRoutesDiscovered,
+		Severity: first.Severity,
+		Title:    fmt.Sprintf("%s (%d notifications)", first.Title, len(events)),
+		Time:     time.Now().UTC(),
+		Multisig: first.Multisig,
+		Fields: map[string]string{
+			"notifications": strconv.Itoa(len(events)),
+			"first_time":    first.Time.Format(time.RFC3339),
+			"last_time":     last.Time.Format(time.RFC3339),
+		},
+	}
+
+	total, totalOK := math.ZeroInt(), true
+	routes, routesOK := 0, true
+	var lines []string
+	for i, event := range events {
+		if severityRank(event.Severity) > severityRank(summary.Severity) {
+			summary.Severity = event.Severity
+		}
+		if event.Multisig != summary.Multisig {
+			summary.Multisig = ""
+		}
+		if amount, ok := math.NewIntFromString(event.Total); ok {
+			total = total.Add(amount)
+		} else {
+			totalOK = false
+		}
+		if n, err := strconv.Atoi(event.Fields["routes"]); err == nil {
+			routes += n
+		} else {
+			routesOK = false
+		}
+		if i < digestMessages {
+			lines = append(lines, "- "+event.Message)
+		}
+	}
+	if len(events) > digestMessages {
+		lines = append(lines, fmt.Sprintf("- and %d more", len(events)-digestMessages))
+	}
+
+	if totalOK {
+		summary.Total = total.String()
+	}
+	header := fmt.Sprintf("%d notifications between %s and %s", len(events), first.Time.Format(time.RFC3339), last.Time.Format(time.RFC3339))
+	if routesOK {
+		summary.Fields["routes"] = strconv.Itoa(routes)
+		header += fmt.Sprintf(", %d routes", routes)
+	}
+	if totalOK {
+		header += ", total " + summary.Total
+	}
+	summary.Message = header + ":\n" + strings.Join(lines, "\n")
+	if d.reportURL != "" {
+		summary.Fields["report_url"] = d.reportURL
+		summary.Message += "\nFull report: " + d.reportURL
+	}
+	return summary
+}
+
+// severityRank orders severities by urgency
+func severityRank(s Severity) int {
+	switch s {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
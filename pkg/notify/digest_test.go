@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDigest(t *testing.T) {
+	var received []Event
+	d := NewDigest(recorder(func(e Event) { received = append(received, e) }), time.Hour, "https://reports.example.com/latest")
+	ctx := context.Background()
+
+	for _, total := range []string{"1000", "2500", "500"} {
+		event := Event{
+			Kind:     KindRoutesDiscovered,
+			Severity: SeverityInfo,
+			Title:    "New deposits to rebalance",
+			Message:  total + " new",
+			Multisig: "celestia1multisig",
+			Total:    total,
+			Fields:   map[string]string{"routes": "2"},
+		}
+		if err := Send(ctx, d, event); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if err := Raise(ctx, d, SeverityCritical, "Broadcast failed", "out of gas"); err != nil {
+		t.Fatalf("Raise() error = %v", err)
+	}
+	if len(received) != 1 || received[0].Title != "Broadcast failed" {
+		t.Fatalf("received %+v before flushing, want only the critical event", received)
+	}
+
+	if err := Flush(ctx, d); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("received %d events, want a single digest after the critical event", len(received))
+	}
+	digest := received[1]
+	if digest.Total != "4000" || digest.Fields["routes"] != "6" || digest.Fields["notifications"] != "3" || digest.Multisig != "celestia1multisig" {
+		t.Errorf("digest = %+v", digest)
+	}
+	if !strings.Contains(digest.Message, "Full report: https://reports.example.com/latest") || !strings.Contains(digest.Message, "2500 new") {
+		t.Errorf("digest message = %q", digest.Message)
+	}
+
+	if err := Flush(ctx, d); err != nil || len(received) != 2 {
+		t.Errorf("Flush() without held-back events = %v, received %d events", err, len(received))
+	}
+}
+
+func TestDigestWindow(t *testing.T) {
+	delivered := make(chan Event, 1)
+	d := NewDigest(recorder(func(e Event) { delivered <- e }), 10*time.Millisecond, "")
+
+	event := Event{Kind: KindRoutesDiscovered, Severity: SeverityInfo, Title: "New deposits to rebalance", Total: "1000"}
+	if err := Send(context.Background(), d, event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case got := <-delivered:
+		if got.Title != event.Title || got.Total != "1000" {
+			t.Errorf("delivered %+v, want the single event unchanged", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered when the window elapsed")
+	}
+}
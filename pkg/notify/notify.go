@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Severity ranks how urgently an event needs an operator's attention
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Event is a notification raised by the rebalancer
+type Event struct {
+	Kind     string    `json:"kind,omitempty"` // Event type, e.g. KindRoutesDiscovered
+	Severity Severity  `json:"severity"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+	Multisig string    `json:"multisig,omitempty"` // Multisig the event concerns
+	Total    string    `json:"total,omitempty"`    // Total amount involved, e.g. "1500000utia"
+	// Fields holds event-specific values such as "tx_hash", available to templates as {{.Fields.tx_hash}}
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Notifier delivers events to operators
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Multi delivers every event to all of its notifiers, returning the combined errors
+type Multi []Notifier
+
+// Notify implements Notifier
+func (m Multi) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Filtered passes on only the events of the listed severities
+type Filtered struct {
+	next       Notifier
+	severities map[Severity]bool
+}
+
+// NewFiltered wraps next so that it only receives events of the given severities
+func NewFiltered(next Notifier, severities []Severity) *Filtered {
+	f := &Filtered{next: next, severities: make(map[Severity]bool)}
+	for _, s := range severities {
+		f.severities[s] = true
+	}
+	return f
+}
+
+// Notify implements Notifier
+func (f *Filtered) Notify(ctx context.Context, event Event) error {
+	if !f.severities[event.Severity] {
+		return nil
+	}
+	return f.next.Notify(ctx, event)
+}
+
+// Webhook posts events as JSON to a URL
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a notifier posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify implements Notifier
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// TemplateData is what notification templates are executed against: the event's fields plus
+// the deployment's network name and links
+type TemplateData struct {
+	Event
+	Network string
+	Links   map[string]string
+}
+
+// Templated rewrites the title and message of events from templates before passing them on
+type Templated struct {
+	next    Notifier
+	title   *template.Template
+	message *template.Template
+	network string
+	links   map[string]string
+}
+
+// NewTemplated wraps next so that events are formatted by the notifier's templates. Templates
+// that are not set leave the event's title or message unchanged.
+func NewTemplated(next Notifier, config types.NotifierConfig, network string, links map[string]string) (*Templated, error) {
+	title, err := types.ParseNotifyTemplate("title", config.Title)
+	if err != nil {
+		return nil, err
+	}
+	message, err := types.ParseNotifyTemplate("message", config.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &Templated{next: next, title: title, message: message, network: network, links: links}, nil
+}
+
+// Notify implements Notifier
+func (t *Templated) Notify(ctx context.Context, event Event) error {
+	data := TemplateData{Event: event, Network: t.network, Links: t.links}
+	var err error
+	if event.Title, err = render(t.title, data, event.Title); err != nil {
+		return err
+	}
+	if event.Message, err = render(t.message, data, event.Message); err != nil {
+		return err
+	}
+	return t.next.Notify(ctx, event)
+}
+
+// render executes tmpl, or returns fallback when no template is set
+func render(tmpl *template.Template, data TemplateData, fallback string) (string, error) {
+	if tmpl == nil {
+		return fallback, nil
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// FromConfig builds the notifier described by the config, each notifiers entry by the factory
+// registered for its type. Notifiers listing severities only receive events of those severities.
+// With a digest window, route discovery events are batched and the returned notifier is a *Digest
+// that must be flushed before exiting. With no notifiers configured, events are dropped.
+func FromConfig(config types.NotifyConfig) (Notifier, error) {
+	var m Multi
+	for _, url := range config.Webhooks {
+		m = append(m, NewWebhook(url))
+	}
+	for i, notifier := range config.Notifiers {
+		channel, err := build(notifier)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %d: %w", i, err)
+		}
+
+		t, err := NewTemplated(channel, notifier, config.Network, config.Links)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %d: %w", i, err)
+		}
+		if len(notifier.Severities) == 0 {
+			m = append(m, t)
+			continue
+		}
+		severities := make([]Severity, len(notifier.Severities))
+		for j, s := range notifier.Severities {
+			severities[j] = Severity(s)
+		}
+		m = append(m, NewFiltered(t, severities))
+	}
+
+	window, err := config.Digest.Duration()
+	if err != nil {
+		return nil, err
+	}
+	if window > 0 {
+		return NewDigest(m, window, config.Digest.ReportURL), nil
+	}
+	return m, nil
+}
+
+// Raise sends an event stamped with the current time
+func Raise(ctx context.Context, n Notifier, severity Severity, title, message string) error {
+	return Send(ctx, n, Event{Severity: severity, Title: title, Message: message})
+}
+
+// Send sends an event, stamping it with the current time
+func Send(ctx context.Context, n Notifier, event Event) error {
+	event.Time = time.Now().UTC()
+	return n.Notify(ctx, event)
+}
@@ -0,0 +1,209 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestWebhook(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	n, err := FromConfig(types.NotifyConfig{Webhooks: []string{server.URL}})
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	if err := Raise(context.Background(), n, SeverityCritical, "Route dead-lettered", "tx A1"); err != nil {
+		t.Fatalf("Raise() error = %v", err)
+	}
+	if received.Severity != SeverityCritical || received.Title != "Route dead-lettered" || received.Time.IsZero() {
+		t.Errorf("received = %+v", received)
+	}
+}
+
+func TestWebhookErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := NewWebhook(server.URL).Notify(context.Background(), Event{}); err == nil {
+		t.Error("Notify() expected error for a failing webhook")
+	}
+}
+
+func TestTemplatedNotifier(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	n, err := FromConfig(types.NotifyConfig{
+		Notifiers: []types.NotifierConfig{{
+			URL:     server.URL,
+			Title:   "[{{.Network}}] {{.Title}}",
+			Message: "{{.Message}} ({{.Multisig}}, {{.Total}}, tx {{.Fields.tx_hash}}{{.Fields.missing}}) {{.Links.runbook}}",
+		}},
+		Network: "mainnet",
+		Links:   map[string]string{"runbook": "https://wiki.example.com/rebalancer"},
+	})
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+
+	event := Event{
+		Severity: SeverityCritical,
+		Title:    "Route dead-lettered",
+		Message:  "out of attempts",
+		Multisig: "celestia1multisig",
+		Total:    "1000utia",
+		Fields:   map[string]string{"tx_hash": "A1"},
+	}
+	if err := Send(context.Background(), n, event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if want := "[mainnet] Route dead-lettered"; received.Title != want {
+		t.Errorf("Title = %q, want %q", received.Title, want)
+	}
+	if want := "out of attempts (celestia1multisig, 1000utia, tx A1) https://wiki.example.com/rebalancer"; received.Message != want {
+		t.Errorf("Message = %q, want %q", received.Message, want)
+	}
+	if received.Severity != SeverityCritical || received.Time.IsZero() {
+		t.Errorf("received = %+v", received)
+	}
+}
+
+func TestTemplatedNotifierKeepsUnsetFields(t *testing.T) {
+	var received []Event
+	n, err := NewTemplated(recorder(func(e Event) { received = append(received, e) }),
+		types.NotifierConfig{Title: "{{.Severity}}: {{.Title}}"}, "", nil)
+	if err != nil {
+		t.Fatalf("NewTemplated() error = %v", err)
+	}
+	if err := Raise(context.Background(), n, SeverityWarning, "Low balance", "top up"); err != nil {
+		t.Fatalf("Raise() error = %v", err)
+	}
+	if len(received) != 1 || received[0].Title != "warning: Low balance" || received[0].Message != "top up" {
+		t.Errorf("received = %+v", received)
+	}
+
+	if _, err := NewTemplated(n, types.NotifierConfig{Message: "{{.Message"}, "", nil); err == nil {
+		t.Error("NewTemplated() expected error for an invalid template")
+	}
+}
+
+// recorder is a Notifier calling a function for each event
+type recorder func(Event)
+
+func (r recorder) Notify(ctx context.Context, event Event) error {
+	r(event)
+	return nil
+}
+
+func TestSeverityRouting(t *testing.T) {
+	var chat, pager []map[string]any
+	chatServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+		chat = append(chat, body)
+	}))
+	defer chatServer.Close()
+	pagerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode PagerDuty event: %v", err)
+		}
+		pager = append(pager, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer pagerServer.Close()
+
+	n, err := FromConfig(types.NotifyConfig{
+		Notifiers: []types.NotifierConfig{
+			{URL: chatServer.URL, Severities: []string{"info", "warning"}},
+			{Type: types.NotifierPagerDuty, URL: pagerServer.URL, RoutingKey: "key", Severities: []string{"critical"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+
+	if err := Raise(context.Background(), n, SeverityInfo, "New deposits", "3 routes"); err != nil {
+		t.Fatalf("Raise() error = %v", err)
+	}
+	event := Event{Severity: SeverityCritical, Title: "Broadcast failed", Message: "out of gas", Multisig: "celestia1multisig", Fields: map[string]string{"tx_hash": "A1"}}
+	if err := Send(context.Background(), n, event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(chat) != 1 || chat[0]["title"] != "New deposits" {
+		t.Errorf("chat received %v, want only the info event", chat)
+	}
+	if len(pager) != 1 {
+		t.Fatalf("PagerDuty received %d events, want only the critical one", len(pager))
+	}
+	payload, _ := pager[0]["payload"].(map[string]any)
+	if pager[0]["routing_key"] != "key" || pager[0]["event_action"] != "trigger" {
+		t.Errorf("PagerDuty event = %v", pager[0])
+	}
+	if payload["summary"] != "Broadcast failed: out of gas" || payload["severity"] != "critical" || payload["source"] != "celestia1multisig" {
+		t.Errorf("PagerDuty payload = %v", payload)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	var received []Event
+	Register("test-recorder", func(config types.NotifierConfig) (Notifier, error) {
+		if config.URL != "chan" {
+			t.Errorf("factory got url %q", config.URL)
+		}
+		return recorder(func(e Event) { received = append(received, e) }), nil
+	})
+
+	config := types.NotifyConfig{Notifiers: []types.NotifierConfig{
+		{Type: "test-recorder", URL: "chan", Title: "[{{.Network}}] {{.Title}}", Severities: []string{"critical"}},
+	}, Network: "mainnet"}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	n, err := FromConfig(config)
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	if err := Raise(context.Background(), n, SeverityInfo, "Routes found", "2 routes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Raise(context.Background(), n, SeverityCritical, "Broadcast failed", "out of gas"); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0].Title != "[mainnet] Broadcast failed" {
+		t.Errorf("received = %+v", received)
+	}
+
+	if _, err := FromConfig(types.NotifyConfig{Notifiers: []types.NotifierConfig{{Type: "sms"}}}); err == nil {
+		t.Error("FromConfig() expected error for an unregistered type")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() expected to panic for a registered type")
+		}
+	}()
+	Register(types.NotifierWebhook, func(types.NotifierConfig) (Notifier, error) { return nil, nil })
+}
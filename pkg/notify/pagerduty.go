@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySource names the rebalancer in alerts about no particular multisig
+const pagerDutySource = "celestia-rebalancer"
+
+// PagerDuty triggers PagerDuty alerts through the Events API v2
+type PagerDuty struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+// NewPagerDuty creates a notifier triggering alerts on the service of routingKey. An empty url
+// uses PagerDutyEventsURL.
+func NewPagerDuty(url, routingKey string) *PagerDuty {
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+	return &PagerDuty{url: url, routingKey: routingKey, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// pagerDutyEvent is the Events API v2 trigger request
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Notify implements Notifier
+func (p *PagerDuty) Notify(ctx context.Context, event Event) error {
+	summary := event.Title
+	if event.Message != "" {
+		summary += ": " + event.Message
+	}
+	// The Events API rejects summaries longer than 1024 characters
+	if len(summary) > 1024 {
+		summary = summary[:1021] + "..."
+	}
+
+	source := event.Multisig
+	if source == "" {
+		source = pagerDutySource
+	}
+
+	details := map[string]string{}
+	for k, v := range event.Fields {
+		details[k] = v
+	}
+	if event.Total != "" {
+		details["total"] = event.Total
+	}
+
+	trigger := pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		Payload: pagerDutyPayload{
+			Summary:       summary,
+			Source:        source,
+			Severity:      string(event.Severity),
+			CustomDetails: details,
+		},
+	}
+	if !event.Time.IsZero() {
+		trigger.Payload.Timestamp = event.Time.Format(time.RFC3339)
+	}
+	if trigger.Payload.Severity == "" {
+		trigger.Payload.Severity = string(SeverityInfo)
+	}
+
+	data, err := json.Marshal(trigger)
+	if err != nil {
+		return fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create PagerDuty request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver PagerDuty event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PagerDuty returned status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Factory builds the notifier of a notifiers entry of the config. The entry's severities and
+// templates are applied by FromConfig around the returned notifier.
+type Factory func(config types.NotifierConfig) (Notifier, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		types.NotifierWebhook: func(config types.NotifierConfig) (Notifier, error) {
+			return NewWebhook(config.URL), nil
+		},
+		types.NotifierPagerDuty: func(config types.NotifierConfig) (Notifier, error) {
+			return NewPagerDuty(config.URL, config.RoutingKey), nil
+		},
+	}
+)
+
+// Register makes notifiers of type typ available to configs, built by factory. Programs embedding
+// the rebalancer call it before loading a config, typically from an init function, to deliver
+// events to their own alerting. Register panics if typ is empty or already registered, as the
+// built-in webhook and pagerduty types are.
+func Register(typ string, factory Factory) {
+	if typ == "" || factory == nil {
+		panic("notify: Register needs a type and a factory")
+	}
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, ok := factories[typ]; ok {
+		panic(fmt.Sprintf("notify: notifier type %q is already registered", typ))
+	}
+	factories[typ] = factory
+	types.RegisterNotifierType(typ, nil)
+}
+
+// build returns the notifier of a notifiers entry from the factory of its type
+func build(config types.NotifierConfig) (Notifier, error) {
+	typ := config.Type
+	if typ == "" {
+		typ = types.NotifierWebhook
+	}
+	factoriesMu.RLock()
+	factory, ok := factories[typ]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", config.Type)
+	}
+	return factory(config)
+}
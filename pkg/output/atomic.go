@@ -0,0 +1,49 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// WriteAtomic writes data to path through a temporary file in the same directory, renamed over
+// path once it is fully written and synced, so a crash leaves either the old or the new file and
+// never a partial one. Missing parent directories are created.
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// Clean up on failure; after the rename the temporary file no longer exists
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// Some network and FUSE mounts cannot fsync; the rename still keeps readers from seeing a
+	// partial file
+	if err := tmp.Sync(); err != nil && !errors.Is(err, errors.ErrUnsupported) && !errors.Is(err, syscall.EINVAL) {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// The temporary file is created 0600. Filesystems without Unix permissions refuse chmod, in
+	// which case the file keeps what the mount gives it.
+	if perm != 0600 {
+		if err := os.Chmod(tmp.Name(), perm); err != nil && !errors.Is(err, errors.ErrUnsupported) && !errors.Is(err, os.ErrPermission) {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockExt is appended to the name of a file to name its lock file
+const LockExt = ".lock"
+
+// DefaultLockWait is how long read-modify-write updates of shared files wait for another process,
+// e.g. an overlapping cron run, to release the file's lock
+const DefaultLockWait = 30 * time.Second
+
+// lockPollInterval is how often a held lock is tried again
+const lockPollInterval = 50 * time.Millisecond
+
+// ErrLocked is returned when another process still holds a lock after the wait
+var ErrLocked = errors.New("locked by another process")
+
+// FileLock is an exclusive advisory lock on a file. It is taken on a lock file next to the file,
+// so the file itself can be replaced by WriteAtomic while it is locked. Only processes taking the
+// same lock are excluded; readers relying on atomic replacement need none.
+type FileLock struct {
+	f *os.File
+}
+
+// LockFile takes the lock of path, waiting up to wait for another process to release it. A zero
+// wait fails at once if the lock is held.
+func LockFile(path string, wait time.Duration) (*FileLock, error) {
+	lockPath := path + LockExt
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(lockPath), err)
+	}
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			return &FileLock{f: f}, nil
+		}
+		if !time.Now().Before(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%s is %w (lock file %s)", path, ErrLocked, lockPath)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Unlock releases the lock; it does nothing on a nil lock. The lock file is left in place, since
+// removing it would race with another process locking it.
+func (l *FileLock) Unlock() error {
+	if l == nil {
+		return nil
+	}
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,16 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package output
+
+import "os"
+
+// tryLock always succeeds: file locks are not supported on this platform, so concurrent runs are
+// not excluded
+func tryLock(f *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlock does nothing on this platform
+func unlock(f *os.File) error {
+	return nil
+}
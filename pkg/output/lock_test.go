@@ -0,0 +1,37 @@
+package output
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "retry.json")
+
+	lock, err := LockFile(path, 0)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	if _, err := LockFile(path, 100*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("LockFile() of a held lock error = %v, want ErrLocked", err)
+	}
+
+	// A waiting locker gets the lock once it is released
+	released := make(chan error, 1)
+	go func() {
+		second, err := LockFile(path, 5*time.Second)
+		if err == nil {
+			err = second.Unlock()
+		}
+		released <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if err := <-released; err != nil {
+		t.Errorf("LockFile() after release error = %v", err)
+	}
+}
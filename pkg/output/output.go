@@ -0,0 +1,101 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// EncryptedExt is appended to the name of encrypted output files. They use the ASCII-armored age
+// format, so recipients can decrypt them with the standard age CLI as well as with this tool.
+const EncryptedExt = ".age"
+
+// WriteFile writes data to path atomically, see WriteAtomic. With recipients, the data is encrypted
+// to them and written to path with EncryptedExt appended. It returns the path actually written.
+func WriteFile(path string, data []byte, recipients []string) (string, error) {
+	if len(recipients) == 0 {
+		if err := WriteAtomic(path, data, 0644); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	parsed, err := types.ParseAgeRecipients(recipients)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armored, parsed...)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	if err := armored.Close(); err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+
+	if !strings.HasSuffix(path, EncryptedExt) {
+		path += EncryptedExt
+	}
+	if err := WriteAtomic(path, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadFile reads a file written by WriteFile, decrypting it with the identity file named by
+// CELESTIA_REBALANCER_AGE_IDENTITY if it is encrypted
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(path, data)
+}
+
+// Decrypt returns data as is if it is not encrypted, and otherwise decrypts it with the identity
+// file named by CELESTIA_REBALANCER_AGE_IDENTITY. The name is only used in error messages.
+func Decrypt(name string, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	identities, err := types.LoadAgeIdentities()
+	if err != nil {
+		return nil, fmt.Errorf("%s is encrypted: %w", name, err)
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(src)
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether data is an age-encrypted file, armored or binary
+func IsEncrypted(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return bytes.HasPrefix(trimmed, []byte(armor.Header)) || bytes.HasPrefix(trimmed, []byte("age-encryption.org/"))
+}
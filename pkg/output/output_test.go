@@ -0,0 +1,98 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestWriteFileEncrypted(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+
+	dir := t.TempDir()
+	identityPath := filepath.Join(dir, "identity.txt")
+	if err := os.WriteFile(identityPath, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write identity: %v", err)
+	}
+	t.Setenv(types.AgeIdentityEnv, identityPath)
+
+	data := []byte(`{"body":{"messages":[]}}`)
+	path, err := WriteFile(filepath.Join(dir, "unsigned-tx.json"), data, []string{identity.Recipient().String()})
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if filepath.Base(path) != "unsigned-tx.json.age" {
+		t.Errorf("WriteFile() path = %s, want unsigned-tx.json.age", path)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read encrypted file: %v", err)
+	}
+	if !IsEncrypted(raw) {
+		t.Error("written file is not encrypted")
+	}
+
+	got, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("ReadFile() = %s, want %s", got, data)
+	}
+}
+
+func TestWriteFilePlain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "unsigned-tx.json")
+	written, err := WriteFile(path, []byte("{}"), nil)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if written != path {
+		t.Errorf("WriteFile() path = %s, want %s", written, path)
+	}
+
+	got, err := ReadFile(path)
+	if err != nil || string(got) != "{}" {
+		t.Errorf("ReadFile() = %s, %v, want {}", got, err)
+	}
+}
+
+func TestWriteAtomic(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out", "run-1")
+	path := filepath.Join(dir, "routes.json")
+
+	if err := WriteAtomic(path, []byte("old"), 0644); err != nil {
+		t.Fatalf("WriteAtomic() error = %v", err)
+	}
+	if err := WriteAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatalf("WriteAtomic() over an existing file error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "new" {
+		t.Errorf("file = %s, %v, want new", got, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only routes.json without temporary files", len(entries))
+	}
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Directions of a parse run, recorded in checkpoints so a run is only resumed by the same kind of run
+const (
+	DirectionInbound  = "inbound"
+	DirectionOutbound = "outbound"
+)
+
+// DefaultCheckpointInterval is the number of heights between checkpoints when none is set
+const DefaultCheckpointInterval = 1000
+
+// Checkpoint records how far a parse run over a height range has got: the last height it completed
+// and everything it collected up to there. A run interrupted after a checkpoint can be resumed from
+// the next height instead of starting over, see SetResume.
+type Checkpoint struct {
+	Direction     string               `json:"direction"`
+	FromHeight    int64                `json:"from_height"`
+	ToHeight      int64                `json:"to_height"`
+	Height        int64                `json:"height"`       // Last completed height
+	Transactions  int                  `json:"transactions"` // Transactions queried up to Height
+	Multisigs     []CheckpointMultisig `json:"multisigs"`
+	FailedHeights []types.FailedHeight `json:"failed_heights,omitempty"`
+	DecodeErrors  []client.DecodeError `json:"decode_errors,omitempty"`
+}
+
+// CheckpointMultisig holds the partial routes and skipped transfers of one multisig of a run
+type CheckpointMultisig struct {
+	MultisigAddr string                 `json:"multisig_address"`
+	Routes       []types.HyperlaneRoute `json:"routes"`
+	Skipped      []types.Skipped        `json:"skipped,omitempty"`
+}
+
+// CheckpointFunc receives the checkpoints of a parse run. It is called synchronously from the run;
+// an error aborts the run.
+type CheckpointFunc func(*Checkpoint) error
+
+// SetCheckpoint registers a function receiving a checkpoint every interval heights of parse runs
+// over a height range, and once more after the last height. An interval of 0 or less uses
+// DefaultCheckpointInterval; a nil function disables checkpoints.
+func (p *Parser) SetCheckpoint(fn CheckpointFunc, interval int64) {
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
+	}
+	p.checkpoint = fn
+	p.checkpointInterval = interval
+}
+
+// SetResume makes the next parse run over a height range continue from cp instead of starting at
+// its first height. The run fails if cp was written by a run with another direction, height range
+// or set of multisigs.
+func (p *Parser) SetResume(cp *Checkpoint) {
+	p.resume = cp
+}
+
+// Validate checks that the checkpoint was written by a run with the given direction, range and
+// multisigs, in the same order
+func (cp *Checkpoint) Validate(direction string, fromHeight, toHeight int64, multisigAddrs []string) error {
+	if cp.Direction != direction {
+		return fmt.Errorf("checkpoint is of an %s parse, not %s", cp.Direction, direction)
+	}
+	if cp.FromHeight != fromHeight || cp.ToHeight != toHeight {
+		return fmt.Errorf("checkpoint covers heights %d to %d, not %d to %d", cp.FromHeight, cp.ToHeight, fromHeight, toHeight)
+	}
+	if cp.Height < fromHeight-1 || cp.Height > toHeight {
+		return fmt.Errorf("checkpoint height %d is outside heights %d to %d", cp.Height, fromHeight, toHeight)
+	}
+	addrs := make([]string, len(cp.Multisigs))
+	for i, m := range cp.Multisigs {
+		addrs[i] = m.MultisigAddr
+	}
+	if !slices.Equal(addrs, multisigAddrs) {
+		return fmt.Errorf("checkpoint is of multisigs %v, not %v", addrs, multisigAddrs)
+	}
+	return nil
+}
+
+// restore seeds the collector of a run, and its groups if any, with the state recorded in cp
+func (cp *Checkpoint) restore(c *collector) {
+	c.priorTxs = cp.Transactions
+	c.failed = slices.Clone(cp.FailedHeights)
+	c.priorDecodeErrs = slices.Clone(cp.DecodeErrors)
+	for i, g := range c.targets() {
+		m := cp.Multisigs[i]
+		g.skipped = slices.Clone(m.Skipped)
+		g.routes = nil
+		g.total = math.ZeroInt()
+		for _, route := range m.Routes {
+			g.add(route)
+		}
+	}
+}
+
+// makeCheckpoint records the state of the collector after height
+func (p *Parser) makeCheckpoint(direction string, fromHeight, toHeight, height int64, c *collector, extract func(*client.Transaction) ([]client.HyperlaneTransfer, error)) (*Checkpoint, error) {
+	decodeErrs, err := collectDecodeErrors(c.txs, extract)
+	if err != nil {
+		return nil, err
+	}
+	targets := c.targets()
+	cp := &Checkpoint{
+		Direction:     direction,
+		FromHeight:    fromHeight,
+		ToHeight:      toHeight,
+		Height:        height,
+		Transactions:  c.transactions(),
+		Multisigs:     make([]CheckpointMultisig, len(targets)),
+		FailedHeights: c.failed,
+		DecodeErrors:  append(slices.Clone(c.priorDecodeErrs), decodeErrs...),
+	}
+	for i, g := range targets {
+		cp.Multisigs[i] = CheckpointMultisig{MultisigAddr: g.multisigAddr, Routes: g.routes, Skipped: g.skipped}
+	}
+	return cp, nil
+}
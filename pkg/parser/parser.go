@@ -2,42 +2,122 @@ package parser
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"cosmossdk.io/math"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/metrics"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
 )
 
 // Parser handles parsing of transactions to extract Hyperlane routing information
 type Parser struct {
-	client *client.Client
-	config *types.Config // Optional whitelist config
+	client       client.Backend
+	config       *types.Config // Optional whitelist config
+	chain        types.ChainConfig
+	query        types.QueryConfig
+	strict       bool         // Abort on the first height that cannot be queried
+	strictDecode bool         // Fail when any transaction or message cannot be decoded
+	blockTimes   bool         // Stamp routes with the time of their block
+	progress     ProgressFunc // Optional progress updates
+
+	checkpoint         CheckpointFunc // Optional checkpoints of height range runs
+	checkpointInterval int64          // Heights between checkpoints
+	resume             *Checkpoint    // Checkpoint the next height range run continues from
+
+	tokenDenoms map[string]string // Denom of each warp token by normalized ID, queried on first use
 }
 
 // NewParser creates a new parser with the given gRPC client
 func NewParser(rpcEndpoint string) (*Parser, error) {
-	c, err := client.NewClient(context.Background(), rpcEndpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Parser{
-		client: c,
-	}, nil
+	return NewParserWithContext(context.Background(), rpcEndpoint, nil)
 }
 
 // NewParserWithConfig creates a new parser with whitelist validation enabled
 func NewParserWithConfig(rpcEndpoint string, config *types.Config) (*Parser, error) {
-	c, err := client.NewClient(context.Background(), rpcEndpoint)
+	return NewParserWithContext(context.Background(), rpcEndpoint, config)
+}
+
+// NewParserWithContext creates a new parser whose chain queries are bounded by ctx. Whitelist
+// validation is enabled if config is not nil, and the endpoint is dialed with its rpc settings,
+// over the CometBFT RPC for http:// and https:// endpoints and over gRPC otherwise.
+func NewParserWithContext(ctx context.Context, rpcEndpoint string, config *types.Config) (*Parser, error) {
+	var rpc types.RPCConfig
+	if config != nil {
+		rpc = config.RPC
+	}
+	c, err := client.Dial(ctx, rpcEndpoint, rpc)
 	if err != nil {
 		return nil, err
 	}
+	return NewParserWithClient(c, config), nil
+}
+
+// NewParserWithClient creates a new parser querying the chain through c, for callers that dial the
+// endpoint themselves. Whitelist validation is enabled if config is not nil.
+func NewParserWithClient(c client.Backend, config *types.Config) *Parser {
+	if config == nil {
+		return &Parser{
+			client: c,
+			chain:  types.DefaultChainConfig(),
+			query:  types.DefaultQueryConfig(),
+		}
+	}
+
+	c.SetQueryConfig(config.Query)
 
 	return &Parser{
 		client: c,
 		config: config,
-	}, nil
+		chain:  config.Chain.WithDefaults(),
+		query:  config.Query.WithDefaults(),
+	}
+}
+
+// SetQueryConfig overrides the transaction query limits
+func (p *Parser) SetQueryConfig(query types.QueryConfig) {
+	p.query = query.WithDefaults()
+	p.client.SetQueryConfig(p.query)
+}
+
+// SetCallTimeout bounds each chain query, see client.Client.SetCallTimeout
+func (p *Parser) SetCallTimeout(timeout time.Duration) {
+	p.client.SetCallTimeout(timeout)
+}
+
+// SetCache enables the on-disk response cache for block queries
+func (p *Parser) SetCache(cache *client.ResponseCache) {
+	p.client.SetCache(cache)
+}
+
+// SetStrict controls how failing block queries are handled. In strict mode ParseRoutes aborts on
+// the first height that cannot be queried; otherwise failed heights are recorded and parsing continues.
+func (p *Parser) SetStrict(strict bool) {
+	p.strict = strict
+}
+
+// SetStrictDecode makes ParseRoutes fail when any transaction or Hyperlane-relevant message in the
+// range cannot be decoded. Decode errors are always reported in the result; strict mode turns them
+// into a failure so silent data loss cannot go unnoticed.
+func (p *Parser) SetStrictDecode(strict bool) {
+	p.strictDecode = strict
+}
+
+// SetBlockTimes makes the parser stamp every route with the time of its block. Block headers are
+// queried once per height and, with a header store, kept across runs.
+func (p *Parser) SetBlockTimes(enabled bool, store storage.HeaderStorage) {
+	p.blockTimes = enabled
+	if store != nil {
+		p.client.SetHeaderStore(store)
+	}
+}
+
+// LatestHeight returns the height of the latest block committed by the node
+func (p *Parser) LatestHeight() (int64, error) {
+	return p.client.LatestHeight()
 }
 
 // Close closes the underlying client connection
@@ -45,47 +125,138 @@ func (p *Parser) Close() error {
 	return p.client.Close()
 }
 
-// ParseRoutes extracts Hyperlane routing information from MsgRemoteTransfer transactions sent to the multisig
-func (p *Parser) ParseRoutes(multisigAddr string, fromHeight, toHeight int64) (*types.Routes, error) {
-	// Query all transactions in the height range
-	txs, err := p.client.GetTransactionsByHeight(fromHeight, toHeight)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query transactions: %w", err)
+// ParseResult holds the routes extracted by ParseRoutes, the transactions that were skipped and
+// the heights that could not be queried
+type ParseResult struct {
+	Routes        *types.Routes
+	Skipped       []types.Skipped
+	FailedHeights []types.FailedHeight
+	DecodeErrors  []client.DecodeError
+}
+
+// ParseRoutes extracts Hyperlane routing information from MsgRemoteTransfer transactions sent to the multisig.
+// Transactions that cannot be turned into a route are reported in the result's Skipped list.
+func (p *Parser) ParseRoutes(multisigAddr string, fromHeight, toHeight int64) (*ParseResult, error) {
+	c := newCollector(multisigAddr, p.collectIncoming)
+	if err := p.queryRange(DirectionInbound, fromHeight, toHeight, c, client.ExtractHyperlaneTransfers); err != nil {
+		return nil, err
 	}
+	return p.finish(c, client.ExtractHyperlaneTransfers)
+}
+
+// ParseRoutesForMultisigs is ParseRoutes for several multisigs, e.g. one per corridor, scanning the
+// height range once. It returns a result per multisig, in the order given. Failed heights and decode
+// errors concern the whole range and are reported in every result.
+func (p *Parser) ParseRoutesForMultisigs(multisigAddrs []string, fromHeight, toHeight int64) ([]*ParseResult, error) {
+	groups := make([]*collector, len(multisigAddrs))
+	for i, multisigAddr := range multisigAddrs {
+		groups[i] = newCollector(multisigAddr, p.collectIncoming)
+	}
+	c := newCollector("", func(c *collector, txs []*client.Transaction) error {
+		for _, g := range c.groups {
+			if err := g.collect(g, txs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	c.groups = groups
+
+	if err := p.queryRange(DirectionInbound, fromHeight, toHeight, c, client.ExtractHyperlaneTransfers); err != nil {
+		return nil, err
+	}
+	return p.finishGroups(c, groups, client.ExtractHyperlaneTransfers)
+}
 
+// ParseRoutesFromTxs is ParseRoutes over the given transactions instead of a height range. The
+// transactions are fetched concurrently; any that cannot be fetched fail the parse.
+func (p *Parser) ParseRoutesFromTxs(multisigAddr string, hashes []string) (*ParseResult, error) {
+	c := newCollector(multisigAddr, p.collectIncoming)
+	if err := p.queryTxs(hashes, c); err != nil {
+		return nil, err
+	}
+	return p.finish(c, client.ExtractHyperlaneTransfers)
+}
+
+// ParseOutgoing extracts the MsgRemoteTransfers sent by the multisig, directly or through an authz
+// MsgExec, into the same Routes schema as ParseRoutes, so auditors can review both sides of the flow
+// with one format. Every successful outgoing transfer is included; the whitelist is not applied.
+// Failed transactions moved no funds and are reported in the result's Skipped list.
+func (p *Parser) ParseOutgoing(multisigAddr string, fromHeight, toHeight int64) (*ParseResult, error) {
+	c := newCollector(multisigAddr, p.collectOutgoing)
+	if err := p.queryRange(DirectionOutbound, fromHeight, toHeight, c, client.ExtractOutgoingTransfers); err != nil {
+		return nil, err
+	}
+	return p.finish(c, client.ExtractOutgoingTransfers)
+}
+
+// ParseOutgoingFromTxs is ParseOutgoing over the given transactions instead of a height range
+func (p *Parser) ParseOutgoingFromTxs(multisigAddr string, hashes []string) (*ParseResult, error) {
+	c := newCollector(multisigAddr, p.collectOutgoing)
+	if err := p.queryTxs(hashes, c); err != nil {
+		return nil, err
+	}
+	return p.finish(c, client.ExtractOutgoingTransfers)
+}
+
+// collectIncoming turns the transfers to the multisig in txs into routes
+func (p *Parser) collectIncoming(c *collector, txs []*client.Transaction) error {
 	// Filter to only transactions with Hyperlane transfers to the multisig
-	filtered, err := client.FilterHyperlaneTransfersToAddress(txs, multisigAddr)
+	filtered, err := client.FilterHyperlaneTransfersToAddress(txs, c.multisigAddr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to filter transactions: %w", err)
+		return fmt.Errorf("failed to filter transactions: %w", err)
 	}
 
-	var routes []types.HyperlaneRoute
-	totalAmount := math.ZeroInt()
-
 	for _, tx := range filtered {
-		// Extract Hyperlane transfers from the transaction
-		transfers, err := client.ExtractHyperlaneTransfers(tx)
-		if err != nil {
-			continue
-		}
+		// Extract Hyperlane transfers from the transaction; decode errors are collected by finish
+		transfers, _ := client.ExtractHyperlaneTransfers(tx)
 
 		// Process each Hyperlane transfer
 		for _, transfer := range transfers {
 			// Skip if the transfer is not to the multisig (sender must match)
-			if transfer.From != multisigAddr {
+			if transfer.From != c.multisigAddr {
+				continue
+			}
+			// Deposits of other tokens, e.g. IBC vouchers, cannot be forwarded as an accepted denom
+			denom, err := p.transferDenom(transfer)
+			if err != nil {
+				return err
+			}
+			if denom == "" {
+				c.skip(tx, transfer, fmt.Sprintf("unknown warp token %s", transfer.TokenID))
+				metrics.RoutesRejected.WithLabelValues(metrics.RejectForeignDenom).Inc()
+				continue
+			}
+			if !p.chain.AcceptsDenom(denom) {
+				c.skip(tx, transfer, fmt.Sprintf("received %s, not an accepted denom", denom))
+				metrics.RoutesRejected.WithLabelValues(metrics.RejectForeignDenom).Inc()
 				continue
 			}
 
 			var routeInfo *types.RouteInfo
 
+			// Metadata is chosen by the depositor, so it is not decoded beyond the size routing needs
+			if limit := p.metadataLimit(); len(transfer.CustomHookMetadata) > limit {
+				skipped := skip(tx, transfer, fmt.Sprintf("custom_hook_metadata of %d bytes exceeds the %d byte limit", len(transfer.CustomHookMetadata), limit))
+				skipped.Metadata = types.NewSkippedMetadata(transfer.CustomHookMetadata)
+				c.skipped = append(c.skipped, skipped)
+				metrics.RoutesRejected.WithLabelValues(metrics.RejectMetadataSize).Inc()
+				continue
+			}
+
 			// Check if we have custom_hook_metadata (for MsgRemoteTransfer)
 			if transfer.CustomHookMetadata != "" {
 				// Parse the custom_hook_metadata for routing information
-				var err error
-				routeInfo, err = types.ParseCustomHookMetadata(transfer.CustomHookMetadata)
+				// With a config, a missing recipient or token ID falls back to the destination's defaults
+				if p.config != nil {
+					routeInfo, err = p.config.ParseCustomHookMetadata(transfer.CustomHookMetadata)
+				} else {
+					routeInfo, err = types.ParseCustomHookMetadata(transfer.CustomHookMetadata)
+				}
 				if err != nil {
 					// Skip transactions without valid routing info
-					fmt.Printf("Warning: tx %s has invalid custom_hook_metadata: %v\n", tx.Hash, err)
+					c.skip(tx, transfer, fmt.Sprintf("invalid custom_hook_metadata: %v", err))
+					metrics.RoutesRejected.WithLabelValues(metrics.RejectRouting).Inc()
 					continue
 				}
 			} else if transfer.DestinationDomain != 0 {
@@ -95,49 +266,364 @@ func (p *Parser) ParseRoutes(multisigAddr string, fromHeight, toHeight int64) (*
 					Recipient:         transfer.To,
 					TokenID:           transfer.TokenID,
 				}
+				if p.config != nil {
+					p.config.ApplyDefaultRecipient(routeInfo)
+					p.config.ApplyDefaultTokenID(routeInfo)
+				}
+				if routeInfo.Recipient == "" {
+					c.skip(tx, transfer, "routing memo has no recipient")
+					metrics.RoutesRejected.WithLabelValues(metrics.RejectRouting).Inc()
+					continue
+				}
 			} else {
 				// No routing information available
-				fmt.Printf("Warning: tx %s has no routing information, skipping\n", tx.Hash)
+				c.skip(tx, transfer, "no routing information")
+				metrics.RoutesRejected.WithLabelValues(metrics.RejectRouting).Inc()
 				continue
 			}
 
 			// Validate against whitelist if config is provided
 			if p.config != nil {
 				if err := p.config.ValidateRoute(routeInfo); err != nil {
-					fmt.Printf("Warning: tx %s failed whitelist validation: %v\n", tx.Hash, err)
+					c.skip(tx, transfer, fmt.Sprintf("failed whitelist validation: %v", err))
+					metrics.RoutesRejected.WithLabelValues(metrics.RejectWhitelist).Inc()
 					continue
 				}
 			}
 
-			// Use the amount from the route info if specified, otherwise use the transfer amount
-			amount := transfer.Amount
+			route := types.HyperlaneRoute{
+				TxHash:             tx.Hash,
+				MsgIndex:           transfer.MsgIndex,
+				BlockHeight:        tx.BlockHeight,
+				From:               transfer.From,
+				Amount:             transfer.Amount,
+				Denom:              denom,
+				CustomHookMetadata: transfer.CustomHookMetadata,
+				RouteInfo:          routeInfo,
+			}
+
+			// Use the amount from the route info if specified, remembering what was deposited. Unless
+			// the config's limits allow it, the amount may not exceed the deposit.
 			if routeInfo.Amount != "" {
-				amount = routeInfo.Amount
+				route.Amount = routeInfo.Amount
+				route.DepositedAmount = transfer.Amount
+				var limits types.LimitsConfig
+				if p.config != nil {
+					limits = p.config.Limits
+				}
+				if err := limits.CheckAmountOverride(&route); err != nil {
+					c.skip(tx, transfer, err.Error())
+					metrics.RoutesRejected.WithLabelValues(metrics.RejectAmount).Inc()
+					continue
+				}
 			}
 
-			route := types.HyperlaneRoute{
+			c.add(route)
+			metrics.RoutesDiscovered.Inc()
+		}
+	}
+	return nil
+}
+
+// collectOutgoing turns the transfers sent by the multisig in txs into routes
+func (p *Parser) collectOutgoing(c *collector, txs []*client.Transaction) error {
+	for _, tx := range txs {
+		// Decode errors are collected by finish
+		transfers, _ := client.ExtractOutgoingTransfers(tx)
+
+		for _, transfer := range transfers {
+			if transfer.From != c.multisigAddr {
+				continue
+			}
+			if tx.Code != 0 {
+				c.skip(tx, transfer, fmt.Sprintf("transaction failed with code %d", tx.Code))
+				continue
+			}
+			denom, err := p.transferDenom(transfer)
+			if err != nil {
+				return err
+			}
+			if denom == "" {
+				denom = p.chain.Denom
+			}
+
+			c.add(types.HyperlaneRoute{
 				TxHash:             tx.Hash,
+				MsgIndex:           transfer.MsgIndex,
 				BlockHeight:        tx.BlockHeight,
 				From:               transfer.From,
-				Amount:             amount,
-				Denom:              "utia", // Hyperlane transfers use native token
+				Amount:             transfer.Amount,
+				Denom:              denom,
 				CustomHookMetadata: transfer.CustomHookMetadata,
-				RouteInfo:          routeInfo,
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: transfer.DestinationDomain,
+					Recipient:         transfer.To,
+					TokenID:           transfer.TokenID,
+				},
+			})
+		}
+	}
+	return nil
+}
+
+// transferDenom returns the denom a transfer moved: the coin of a bank send or IBC transfer, or the
+// denom of the warp token of a MsgRemoteTransfer. It returns "" for a token not registered on the
+// chain. Warp tokens are queried once per parser.
+func (p *Parser) transferDenom(transfer client.HyperlaneTransfer) (string, error) {
+	if transfer.Denom != "" {
+		return transfer.Denom, nil
+	}
+	if p.tokenDenoms == nil {
+		tokens, err := p.client.GetWarpTokens()
+		if err != nil {
+			return "", err
+		}
+		p.tokenDenoms = make(map[string]string, len(tokens))
+		for _, token := range tokens {
+			if tokenID, err := types.NormalizeTokenID(token.Id); err == nil {
+				p.tokenDenoms[tokenID] = client.TokenDenom(token)
 			}
+		}
+	}
+	tokenID, err := types.NormalizeTokenID(transfer.TokenID)
+	if err != nil {
+		return "", nil
+	}
+	return p.tokenDenoms[tokenID], nil
+}
 
-			routes = append(routes, route)
+// queryRange queries the transactions in the height range block by block, collecting routes as it
+// goes. Heights that cannot be queried are recorded as failed unless the parser is strict. A run
+// with a resume checkpoint starts after the checkpoint's height.
+func (p *Parser) queryRange(direction string, fromHeight, toHeight int64, c *collector, extract func(*client.Transaction) ([]client.HyperlaneTransfer, error)) error {
+	start := fromHeight
+	if cp := p.resume; cp != nil {
+		p.resume = nil
+		if err := cp.Validate(direction, fromHeight, toHeight, c.multisigAddrs()); err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		cp.restore(c)
+		start = cp.Height + 1
+	}
 
-			// Add to total
-			amountInt, ok := math.NewIntFromString(amount)
-			if ok {
-				totalAmount = totalAmount.Add(amountInt)
+	for height := start; height <= toHeight; height++ {
+		if err := p.queryHeight(fromHeight, toHeight, height, c); err != nil {
+			return err
+		}
+		if p.checkpoint != nil && ((height-fromHeight+1)%p.checkpointInterval == 0 || height == toHeight) {
+			cp, err := p.makeCheckpoint(direction, fromHeight, toHeight, height, c, extract)
+			if err != nil {
+				return err
+			}
+			if err := p.checkpoint(cp); err != nil {
+				return fmt.Errorf("failed to checkpoint height %d: %w", height, err)
 			}
 		}
 	}
+	return nil
+}
+
+// queryHeight queries and collects the transactions at one height of a range
+func (p *Parser) queryHeight(fromHeight, toHeight, height int64, c *collector) error {
+	heightTxs, err := p.client.GetTransactionsAtHeight(height)
+	if err != nil {
+		if p.strict {
+			return fmt.Errorf("failed to query transactions: %w", err)
+		}
+		c.failed = append(c.failed, types.FailedHeight{Height: height, Error: err.Error()})
+		metrics.BlockQueryErrors.Inc()
+		p.report(c, height, fromHeight, toHeight)
+		return nil
+	}
+	c.txs = append(c.txs, heightTxs...)
+	metrics.BlocksScanned.Inc()
+
+	if p.query.MaxTxs > 0 && c.transactions() > p.query.MaxTxs {
+		return fmt.Errorf("more than %d transactions in heights %d to %d, narrow the range or raise the query limit", p.query.MaxTxs, fromHeight, height)
+	}
+
+	if err := c.collect(c, heightTxs); err != nil {
+		return err
+	}
+	p.report(c, height, fromHeight, toHeight)
+	return nil
+}
+
+// queryTxs fetches the given transactions and collects their routes
+func (p *Parser) queryTxs(hashes []string, c *collector) error {
+	txs, err := p.client.GetTransactions(hashes)
+	if err != nil {
+		return fmt.Errorf("failed to query transactions: %w", err)
+	}
+	c.txs = txs
+	if err := c.collect(c, txs); err != nil {
+		return err
+	}
+	p.report(c, 0, 0, 0)
+	return nil
+}
+
+// finish collects the decode errors of the queried transactions and assembles the result
+func (p *Parser) finish(c *collector, extract func(*client.Transaction) ([]client.HyperlaneTransfer, error)) (*ParseResult, error) {
+	results, err := p.finishGroups(c, []*collector{c}, extract)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// finishGroups assembles a result for each of groups, the collectors fed by the queries of c. The
+// decode errors and failed heights of c are reported in every result.
+func (p *Parser) finishGroups(c *collector, groups []*collector, extract func(*client.Transaction) ([]client.HyperlaneTransfer, error)) ([]*ParseResult, error) {
+	decodeErrs, err := p.decodeErrors(c, extract)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ParseResult, 0, len(groups))
+	for _, g := range groups {
+		if p.blockTimes {
+			for i := range g.routes {
+				header, err := p.client.BlockHeader(g.routes[i].BlockHeight)
+				if err != nil {
+					return nil, fmt.Errorf("failed to query the time of tx %s: %w", g.routes[i].TxHash, err)
+				}
+				blockTime := header.Time
+				g.routes[i].BlockTime = &blockTime
+			}
+		}
+
+		results = append(results, &ParseResult{
+			Routes: &types.Routes{
+				Routes:       g.routes,
+				TotalAmount:  g.total.String(),
+				MultisigAddr: g.multisigAddr,
+			},
+			Skipped:       g.skipped,
+			FailedHeights: c.failed,
+			DecodeErrors:  decodeErrs,
+		})
+	}
+	return results, nil
+}
+
+// decodeErrors collects the transactions and messages of the run of c that extract could not
+// decode, before filtering drops them, including those of a resumed checkpoint. In strict decode
+// mode any decode error fails the parse.
+func (p *Parser) decodeErrors(c *collector, extract func(*client.Transaction) ([]client.HyperlaneTransfer, error)) ([]client.DecodeError, error) {
+	decodeErrs, err := collectDecodeErrors(c.txs, extract)
+	if err != nil {
+		return nil, err
+	}
+	decodeErrs = append(c.priorDecodeErrs, decodeErrs...)
+	metrics.DecodeErrors.Add(float64(len(decodeErrs)))
+	if p.strictDecode && len(decodeErrs) > 0 {
+		return nil, fmt.Errorf("strict decode: %w", client.DecodeErrors(decodeErrs))
+	}
+	return decodeErrs, nil
+}
+
+// collectDecodeErrors collects the transactions and messages of txs that extract could not decode
+func collectDecodeErrors(txs []*client.Transaction, extract func(*client.Transaction) ([]client.HyperlaneTransfer, error)) ([]client.DecodeError, error) {
+	var decodeErrs []client.DecodeError
+	for _, tx := range txs {
+		if tx.DecodeError != nil {
+			decodeErrs = append(decodeErrs, *tx.DecodeError)
+			continue
+		}
+		if _, err := extract(tx); err != nil {
+			var errs client.DecodeErrors
+			if !errors.As(err, &errs) {
+				return nil, fmt.Errorf("failed to extract transfers from tx %s: %w", tx.Hash, err)
+			}
+			decodeErrs = append(decodeErrs, errs...)
+		}
+	}
+	return decodeErrs, nil
+}
+
+// metadataLimit returns the largest custom_hook_metadata the parser decodes
+func (p *Parser) metadataLimit() int {
+	if p.config == nil {
+		return types.DefaultMaxMetadataSize
+	}
+	return p.config.Metadata.SizeLimit()
+}
+
+// skip records a transfer that could not be turned into a route
+func skip(tx *client.Transaction, transfer client.HyperlaneTransfer, reason string) types.Skipped {
+	return types.Skipped{
+		TxHash:      tx.Hash,
+		BlockHeight: tx.BlockHeight,
+		Reason:      reason,
+		Amount:      transfer.Amount,
+	}
+}
+
+// collector accumulates the routes of a parse run as transactions are queried
+type collector struct {
+	multisigAddr string
+	collect      func(c *collector, txs []*client.Transaction) error
+
+	txs     []*client.Transaction
+	routes  []types.HyperlaneRoute
+	skipped []types.Skipped
+	failed  []types.FailedHeight
+	total   math.Int
+
+	groups []*collector // Per-multisig collectors fed by this one's queries, see ParseRoutesForMultisigs
+
+	priorTxs        int                  // Transactions queried before a resumed checkpoint
+	priorDecodeErrs []client.DecodeError // Decode errors found before a resumed checkpoint
+}
+
+func newCollector(multisigAddr string, collect func(c *collector, txs []*client.Transaction) error) *collector {
+	return &collector{multisigAddr: multisigAddr, collect: collect, total: math.ZeroInt()}
+}
+
+// add records a route and adds its amount to the total
+func (c *collector) add(route types.HyperlaneRoute) {
+	c.routes = append(c.routes, route)
+	if amount, ok := math.NewIntFromString(route.Amount); ok {
+		c.total = c.total.Add(amount)
+	}
+}
+
+// counts returns the routes and skipped transfers collected so far, over all groups if any
+func (c *collector) counts() (routes, skipped int) {
+	routes, skipped = len(c.routes), len(c.skipped)
+	for _, g := range c.groups {
+		routes += len(g.routes)
+		skipped += len(g.skipped)
+	}
+	return routes, skipped
+}
+
+// targets returns the collectors holding the routes of a run: the groups if any, else c itself
+func (c *collector) targets() []*collector {
+	if len(c.groups) > 0 {
+		return c.groups
+	}
+	return []*collector{c}
+}
+
+// multisigAddrs returns the multisigs the run of c collects routes for
+func (c *collector) multisigAddrs() []string {
+	targets := c.targets()
+	addrs := make([]string, len(targets))
+	for i, g := range targets {
+		addrs[i] = g.multisigAddr
+	}
+	return addrs
+}
+
+// transactions returns the number of transactions queried so far, including before a resumed
+// checkpoint
+func (c *collector) transactions() int {
+	return c.priorTxs + len(c.txs)
+}
 
-	return &types.Routes{
-		Routes:       routes,
-		TotalAmount:  totalAmount.String(),
-		MultisigAddr: multisigAddr,
-	}, nil
+// skip records a transfer that could not be turned into a route
+func (c *collector) skip(tx *client.Transaction, transfer client.HyperlaneTransfer, reason string) {
+	c.skipped = append(c.skipped, skip(tx, transfer, reason))
 }
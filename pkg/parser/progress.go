@@ -0,0 +1,51 @@
+package parser
+
+// Progress describes how far a parse run has got. It is reported after every queried height, or
+// once for a parse over explicit transactions, in which case the heights are zero.
+type Progress struct {
+	Height        int64 `json:"height"`         // Height just queried
+	FromHeight    int64 `json:"from_height"`    // First height of the range
+	ToHeight      int64 `json:"to_height"`      // Last height of the range
+	Heights       int64 `json:"heights"`        // Heights queried so far, including failed ones
+	Transactions  int   `json:"transactions"`   // Transactions queried so far
+	Routes        int   `json:"routes"`         // Routes found so far
+	Skipped       int   `json:"skipped"`        // Transfers skipped so far
+	FailedHeights int   `json:"failed_heights"` // Heights that could not be queried so far
+}
+
+// Done reports whether the run has queried its whole range
+func (p Progress) Done() bool {
+	return p.Height == p.ToHeight
+}
+
+// ProgressFunc receives progress updates. It is called synchronously from the parse run, so it
+// should return quickly.
+type ProgressFunc func(Progress)
+
+// SetProgress registers a function receiving progress updates during parse runs, so embedding
+// services can stream them to their own UIs. Nil disables progress reporting.
+func (p *Parser) SetProgress(fn ProgressFunc) {
+	p.progress = fn
+}
+
+// report sends the collector's state to the progress function, if one is set
+func (p *Parser) report(c *collector, height, fromHeight, toHeight int64) {
+	if p.progress == nil {
+		return
+	}
+	heights := int64(0)
+	if toHeight > 0 {
+		heights = height - fromHeight + 1
+	}
+	routes, skipped := c.counts()
+	p.progress(Progress{
+		Height:        height,
+		FromHeight:    fromHeight,
+		ToHeight:      toHeight,
+		Heights:       heights,
+		Transactions:  c.transactions(),
+		Routes:        routes,
+		Skipped:       skipped,
+		FailedHeights: len(c.failed),
+	})
+}
@@ -0,0 +1,165 @@
+package quarantine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/strategy"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Entry excludes deposits from generation: either a single source transaction or every deposit
+// from a sender
+type Entry struct {
+	TxHash  string    `json:"tx_hash,omitempty"`
+	Sender  string    `json:"sender,omitempty"`
+	Reason  string    `json:"reason"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// String describes the entry for logs and the audit trail
+func (e Entry) String() string {
+	if e.TxHash != "" {
+		return fmt.Sprintf("tx %s (%s)", e.TxHash, e.Reason)
+	}
+	return fmt.Sprintf("sender %s (%s)", e.Sender, e.Reason)
+}
+
+// List is the quarantine list persisted at a file. Deposits stay quarantined until explicitly
+// released, across any number of runs.
+type List struct {
+	path    string
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the quarantine list at path. A missing file is an empty list.
+func Load(path string) (*List, error) {
+	list := &List{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine list: %w", err)
+	}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("failed to parse quarantine list: %w", err)
+	}
+	return list, nil
+}
+
+// Update locks the quarantine list at path, loads it, applies fn and saves the list if fn succeeds,
+// so updates from overlapping runs are applied one after the other instead of overwriting each other
+func Update(path string, fn func(*List) error) error {
+	lock, err := output.LockFile(path, output.DefaultLockWait)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	list, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(list); err != nil {
+		return err
+	}
+	return list.Save()
+}
+
+// Save writes the list back to its file. Use Update to modify a list other runs may modify too.
+func (l *List) Save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal quarantine list: %w", err)
+	}
+	if err := output.WriteAtomic(l.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write quarantine list: %w", err)
+	}
+	return nil
+}
+
+// Add quarantines a tx hash or a sender. Exactly one of them must be set, with a reason.
+func (l *List) Add(txHash, sender, reason string) (Entry, error) {
+	if (txHash == "") == (sender == "") {
+		return Entry{}, fmt.Errorf("exactly one of tx hash or sender must be given")
+	}
+	if reason == "" {
+		return Entry{}, fmt.Errorf("a reason is required")
+	}
+	if _, found := l.find(txHash, sender); found {
+		return Entry{}, fmt.Errorf("%s is already quarantined", describe(txHash, sender))
+	}
+
+	entry := Entry{TxHash: txHash, Sender: sender, Reason: reason, AddedAt: time.Now().UTC()}
+	l.Entries = append(l.Entries, entry)
+	return entry, nil
+}
+
+// Release removes a tx hash or sender from the list and returns the removed entry
+func (l *List) Release(txHash, sender string) (Entry, error) {
+	i, found := l.find(txHash, sender)
+	if !found {
+		return Entry{}, fmt.Errorf("%s is not quarantined", describe(txHash, sender))
+	}
+
+	entry := l.Entries[i]
+	l.Entries = append(l.Entries[:i], l.Entries[i+1:]...)
+	return entry, nil
+}
+
+// Match returns the entry quarantining the route, if any
+func (l *List) Match(route *types.HyperlaneRoute) (Entry, bool) {
+	for _, e := range l.Entries {
+		if e.TxHash != "" && strings.EqualFold(e.TxHash, route.TxHash) {
+			return e, true
+		}
+		if e.Sender != "" && e.Sender == route.From {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Filter returns a copy of routes without the quarantined routes, and the routes held back
+func (l *List) Filter(routes *types.Routes) (*types.Routes, []types.HyperlaneRoute) {
+	kept := &types.Routes{MultisigAddr: routes.MultisigAddr}
+	var held []types.HyperlaneRoute
+
+	for _, route := range routes.Routes {
+		if _, quarantined := l.Match(&route); quarantined {
+			held = append(held, route)
+			continue
+		}
+		kept.Routes = append(kept.Routes, route)
+	}
+
+	strategy.RecomputeTotal(kept)
+	return kept, held
+}
+
+// find returns the index of the entry for exactly this tx hash or sender
+func (l *List) find(txHash, sender string) (int, bool) {
+	for i, e := range l.Entries {
+		if txHash != "" && strings.EqualFold(e.TxHash, txHash) {
+			return i, true
+		}
+		if sender != "" && e.Sender == sender {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func describe(txHash, sender string) string {
+	if txHash != "" {
+		return "tx " + txHash
+	}
+	return "sender " + sender
+}
@@ -0,0 +1,106 @@
+package quarantine
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestAddReleasePersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+
+	list, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := list.Add("ABC", "", "amount looks manipulated"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := list.Add("", "celestia1bad", "sanctioned sender"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := list.Add("abc", "", "again"); err == nil {
+		t.Error("Add() accepted a duplicate tx hash")
+	}
+	if _, err := list.Add("DEF", "", ""); err == nil {
+		t.Error("Add() accepted an entry without a reason")
+	}
+	if _, err := list.Add("DEF", "celestia1bad", "both"); err == nil {
+		t.Error("Add() accepted both a tx hash and a sender")
+	}
+	if err := list.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 2 {
+		t.Fatalf("loaded %d entries, want 2", len(loaded.Entries))
+	}
+
+	if _, err := loaded.Release("ABC", ""); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := loaded.Release("ABC", ""); err == nil {
+		t.Error("Release() succeeded for an entry that is not quarantined")
+	}
+	if len(loaded.Entries) != 1 || loaded.Entries[0].Sender != "celestia1bad" {
+		t.Errorf("entries = %+v, want only the sender", loaded.Entries)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	list := &List{}
+	list.Add("A1", "", "investigating")
+	list.Add("", "celestia1bad", "sanctioned")
+
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{
+		{TxHash: "A1", From: "celestia1good", Amount: "100"},
+		{TxHash: "A2", From: "celestia1bad", Amount: "50"},
+		{TxHash: "A3", From: "celestia1good", Amount: "30"},
+	}}
+
+	kept, held := list.Filter(routes)
+	if len(kept.Routes) != 1 || kept.Routes[0].TxHash != "A3" || kept.TotalAmount != "30" {
+		t.Errorf("kept = %+v, want only A3 totalling 30", kept)
+	}
+	if len(held) != 2 {
+		t.Errorf("held %d routes, want 2", len(held))
+	}
+	if len(routes.Routes) != 3 {
+		t.Error("Filter() modified the input routes")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quarantine.json")
+
+	err := Update(path, func(list *List) error {
+		_, err := list.Add("ABC", "", "amount looks manipulated")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	failed := errors.New("rejected")
+	err = Update(path, func(list *List) error {
+		list.Add("DEF", "", "not saved")
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Update() error = %v, want the error of fn", err)
+	}
+
+	list, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(list.Entries) != 1 || list.Entries[0].TxHash != "ABC" {
+		t.Errorf("entries = %v, want only ABC", list.Entries)
+	}
+}
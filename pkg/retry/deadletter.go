@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+)
+
+// DeadLetter is a route given up on after its last retry, kept with its full failure history
+type DeadLetter struct {
+	Item
+	DeadAt time.Time `json:"dead_at"`
+}
+
+// DeadLetters is an append-only JSON-lines file of routes given up on, so no deposit silently
+// disappears from the pipeline
+type DeadLetters struct {
+	path string
+}
+
+// NewDeadLetters returns the dead-letter file at path
+func NewDeadLetters(path string) *DeadLetters {
+	return &DeadLetters{path: path}
+}
+
+// Add appends a route given up on to the file, under the file's lock so lines appended by
+// overlapping runs are not interleaved
+func (d *DeadLetters) Add(item Item, now time.Time) error {
+	data, err := json.Marshal(DeadLetter{Item: item, DeadAt: now.UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	lock, err := output.LockFile(d.path, output.DefaultLockWait)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	f, err := os.OpenFile(d.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+	return nil
+}
+
+// Entries reads all routes in the file. A missing file has no entries.
+func (d *DeadLetters) Entries() ([]DeadLetter, error) {
+	f, err := os.Open(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse dead letter: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter file: %w", err)
+	}
+	return entries, nil
+}
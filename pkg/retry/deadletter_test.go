@@ -0,0 +1,43 @@
+package retry
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestDeadLetters(t *testing.T) {
+	d := NewDeadLetters(filepath.Join(t.TempDir(), "dead-letter.jsonl"))
+
+	entries, err := d.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Entries() = %v, %v for a missing file, want none", entries, err)
+	}
+
+	q := &Queue{initial: time.Minute, max: time.Hour, maxAttempts: 2}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := types.HyperlaneRoute{TxHash: "A1", Amount: "100"}
+	q.Fail(route, StageGenerate, errors.New("first"), now)
+	item, exhausted := q.Fail(route, StageGenerate, errors.New("second"), now.Add(time.Minute))
+	if !exhausted {
+		t.Fatal("route not exhausted")
+	}
+
+	if err := d.Add(item, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	entries, err = d.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Route.TxHash != "A1" || len(entries[0].Failures) != 2 {
+		t.Fatalf("entries = %+v, want A1 with two failures", entries)
+	}
+	if entries[0].Failures[1].Error != "second" || entries[0].DeadAt.IsZero() {
+		t.Errorf("entry = %+v, want the failure history and time", entries[0])
+	}
+}
@@ -0,0 +1,183 @@
+package retry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Stages at which a route can fail
+const (
+	StageGenerate  = "generate"
+	StageBroadcast = "broadcast"
+	StageDelivery  = "delivery"
+)
+
+// Failure is one failed attempt to process a route
+type Failure struct {
+	Time  time.Time `json:"time"`
+	Stage string    `json:"stage"`
+	Error string    `json:"error"`
+}
+
+// Item is a route waiting to be retried, with its failure history
+type Item struct {
+	Route       types.HyperlaneRoute `json:"route"`
+	Attempts    int                  `json:"attempts"`
+	NextAttempt time.Time            `json:"next_attempt"`
+	Failures    []Failure            `json:"failures"`
+}
+
+// Queue holds failed routes until they are due for another attempt. It is persisted at a file so
+// retries survive restarts.
+type Queue struct {
+	path        string
+	initial     time.Duration
+	max         time.Duration
+	maxAttempts int
+	lock        *output.FileLock
+
+	Items []Item `json:"items"`
+}
+
+// Load reads the queue at the configured queue file. A missing file is an empty queue. Use
+// LoadLocked to modify a queue other runs may modify too.
+func Load(config types.RetryConfig) (*Queue, error) {
+	if config.QueueFile == "" {
+		return nil, fmt.Errorf("retry queue_file is not configured")
+	}
+	config = config.WithDefaults()
+	initial, max, err := config.Backoff()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Queue{path: config.QueueFile, initial: initial, max: max, maxAttempts: config.MaxAttempts}
+
+	data, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry queue: %w", err)
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("failed to parse retry queue: %w", err)
+	}
+	return q, nil
+}
+
+// LoadLocked locks the queue file and reads the queue like Load. The file stays locked until
+// Close, so an overlapping run waits for this one instead of overwriting its updates.
+func LoadLocked(config types.RetryConfig) (*Queue, error) {
+	if config.QueueFile == "" {
+		return nil, fmt.Errorf("retry queue_file is not configured")
+	}
+	lock, err := output.LockFile(config.QueueFile, output.DefaultLockWait)
+	if err != nil {
+		return nil, err
+	}
+	q, err := Load(config)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+	q.lock = lock
+	return q, nil
+}
+
+// Close releases the lock taken by LoadLocked; it does nothing on a nil or unlocked queue
+func (q *Queue) Close() error {
+	if q == nil {
+		return nil
+	}
+	return q.lock.Unlock()
+}
+
+// Save writes the queue back to its file
+func (q *Queue) Save() error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal retry queue: %w", err)
+	}
+	if err := output.WriteAtomic(q.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write retry queue: %w", err)
+	}
+	return nil
+}
+
+// Fail records a failed attempt for the route and schedules the next one. If the route has used
+// up its attempts, it is removed from the queue and returned with exhausted set.
+func (q *Queue) Fail(route types.HyperlaneRoute, stage string, cause error, now time.Time) (item Item, exhausted bool) {
+	i := q.find(route.TxHash)
+	if i < 0 {
+		q.Items = append(q.Items, Item{Route: route})
+		i = len(q.Items) - 1
+	}
+
+	it := &q.Items[i]
+	it.Route = route
+	it.Attempts++
+	it.Failures = append(it.Failures, Failure{Time: now.UTC(), Stage: stage, Error: cause.Error()})
+	it.NextAttempt = now.UTC().Add(q.backoff(it.Attempts))
+
+	if it.Attempts >= q.maxAttempts {
+		item = *it
+		q.Items = append(q.Items[:i], q.Items[i+1:]...)
+		return item, true
+	}
+	return *it, false
+}
+
+// Succeed removes the route from the queue after it was processed successfully
+func (q *Queue) Succeed(txHash string) {
+	if i := q.find(txHash); i >= 0 {
+		q.Items = append(q.Items[:i], q.Items[i+1:]...)
+	}
+}
+
+// Due returns the queued routes whose next attempt is due
+func (q *Queue) Due(now time.Time) []types.HyperlaneRoute {
+	var due []types.HyperlaneRoute
+	for _, it := range q.Items {
+		if !now.Before(it.NextAttempt) {
+			due = append(due, it.Route)
+		}
+	}
+	return due
+}
+
+// Waiting reports whether the route is queued but not yet due, so it must not be processed early
+func (q *Queue) Waiting(txHash string, now time.Time) (Item, bool) {
+	i := q.find(txHash)
+	if i < 0 || !now.Before(q.Items[i].NextAttempt) {
+		return Item{}, false
+	}
+	return q.Items[i], true
+}
+
+// backoff returns the delay after the given number of failed attempts
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.initial
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= q.max {
+			return q.max
+		}
+	}
+	return delay
+}
+
+func (q *Queue) find(txHash string) int {
+	for i, it := range q.Items {
+		if it.Route.TxHash == txHash {
+			return i
+		}
+	}
+	return -1
+}
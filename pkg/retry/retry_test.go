@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestQueueBackoffAndExhaustion(t *testing.T) {
+	config := types.RetryConfig{
+		QueueFile:      filepath.Join(t.TempDir(), "retry.json"),
+		InitialBackoff: "1m",
+		MaxBackoff:     "3m",
+		MaxAttempts:    4,
+	}
+	q, err := Load(config)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := types.HyperlaneRoute{TxHash: "A1", Amount: "100"}
+	cause := errors.New("invalid token_id")
+
+	wantDelays := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}
+	for i, want := range wantDelays {
+		item, exhausted := q.Fail(route, StageGenerate, cause, now)
+		if exhausted {
+			t.Fatalf("attempt %d: exhausted too early", i+1)
+		}
+		if got := item.NextAttempt.Sub(now); got != want {
+			t.Errorf("attempt %d: backoff = %v, want %v", i+1, got, want)
+		}
+		if _, waiting := q.Waiting("A1", now); !waiting {
+			t.Errorf("attempt %d: route not waiting", i+1)
+		}
+		if len(q.Due(now.Add(want))) != 1 {
+			t.Errorf("attempt %d: route not due after backoff", i+1)
+		}
+	}
+
+	if err := q.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	q, err = Load(config)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	item, exhausted := q.Fail(route, StageGenerate, cause, now)
+	if !exhausted || item.Attempts != 4 || len(item.Failures) != 4 {
+		t.Fatalf("exhausted = %v after %d attempts and %d failures, want true, 4, 4", exhausted, item.Attempts, len(item.Failures))
+	}
+	if len(q.Items) != 0 {
+		t.Errorf("exhausted route still queued")
+	}
+}
+
+func TestQueueSucceed(t *testing.T) {
+	q, err := Load(types.RetryConfig{QueueFile: filepath.Join(t.TempDir(), "retry.json")})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	q.Fail(types.HyperlaneRoute{TxHash: "A1"}, StageGenerate, errors.New("boom"), time.Now())
+	q.Succeed("A1")
+	if len(q.Items) != 0 {
+		t.Errorf("route still queued after Succeed()")
+	}
+}
+
+func TestLoadLocked(t *testing.T) {
+	config := types.RetryConfig{QueueFile: filepath.Join(t.TempDir(), "retry.json")}
+	q, err := LoadLocked(config)
+	if err != nil {
+		t.Fatalf("LoadLocked() error = %v", err)
+	}
+	q.Fail(types.HyperlaneRoute{TxHash: "A1", Amount: "100"}, StageGenerate, errors.New("invalid token_id"), time.Now())
+	if err := q.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	q, err = LoadLocked(config)
+	if err != nil {
+		t.Fatalf("LoadLocked() after Close error = %v", err)
+	}
+	defer q.Close()
+	if len(q.Items) != 1 {
+		t.Errorf("loaded %d items, want 1", len(q.Items))
+	}
+}
@@ -0,0 +1,103 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// RecordDeliveries records the messages dispatched for routes as awaiting delivery. Messages
+// already confirmed as delivered keep that status.
+func (l *Ledger) RecordDeliveries(ctx context.Context, routes []types.HyperlaneRoute) error {
+	for _, route := range routes {
+		if route.Dispatch == nil || route.Dispatch.MessageID == "" {
+			continue
+		}
+		existing, err := l.store.Delivery(ctx, route.Dispatch.MessageID)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("failed to look up message %s: %w", route.Dispatch.MessageID, err)
+		case existing.Delivered:
+			continue
+		}
+
+		delivery := storage.Delivery{Dispatch: *route.Dispatch, DepositTxHash: route.TransferKey()}
+		if err := l.store.SaveDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to record message %s: %w", route.Dispatch.MessageID, err)
+		}
+	}
+	return nil
+}
+
+// InFlight counts the dispatched messages not yet confirmed as delivered, by destination domain
+func (l *Ledger) InFlight(ctx context.Context) (map[uint32]int, error) {
+	pending, err := l.store.PendingDeliveries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending deliveries: %w", err)
+	}
+	counts := make(map[uint32]int)
+	for _, delivery := range pending {
+		counts[delivery.Destination]++
+	}
+	return counts, nil
+}
+
+// ConfirmDeliveries asks delivered about every pending message to a domain in domains, and records
+// the messages it confirms as delivered along with their deposits. Messages that cannot be checked
+// stay pending; their errors are returned together after the others were checked.
+func (l *Ledger) ConfirmDeliveries(ctx context.Context, domains map[uint32]bool, delivered func(context.Context, storage.Delivery) (bool, error)) (int, error) {
+	pending, err := l.store.PendingDeliveries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending deliveries: %w", err)
+	}
+
+	confirmed := 0
+	var errs []error
+	for _, delivery := range pending {
+		if !domains[delivery.Destination] {
+			continue
+		}
+		ok, err := delivered(ctx, delivery)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("message %s to domain %d: %w", delivery.MessageID, delivery.Destination, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		delivery.Delivered = true
+		if err := l.store.SaveDelivery(ctx, delivery); err != nil {
+			return confirmed, fmt.Errorf("failed to record delivery of message %s: %w", delivery.MessageID, err)
+		}
+		if err := l.recordDelivered(ctx, delivery); err != nil {
+			return confirmed, err
+		}
+		confirmed++
+	}
+	return confirmed, errors.Join(errs...)
+}
+
+// recordDelivered moves the deposits a delivered message forwards to delivered
+func (l *Ledger) recordDelivered(ctx context.Context, delivery storage.Delivery) error {
+	for _, key := range DepositKeys(types.HyperlaneRoute{TxHash: delivery.DepositTxHash}) {
+		record, err := l.store.Route(ctx, key)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up deposit %s: %w", key, err)
+		}
+		if statusRank(record.Status) >= statusRank(storage.RouteDelivered) {
+			continue
+		}
+		if err := l.store.SaveRoute(ctx, record.Route, storage.RouteDelivered); err != nil {
+			return fmt.Errorf("failed to record deposit %s as %s: %w", key, storage.RouteDelivered, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestDeliveries(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	ledger := New(store)
+
+	dispatched := func(txHash, messageID string, domain uint32) types.HyperlaneRoute {
+		return types.HyperlaneRoute{TxHash: txHash, Amount: "100", Denom: "utia",
+			Dispatch: &types.Dispatch{MessageID: messageID, Destination: domain}}
+	}
+	routes := []types.HyperlaneRoute{
+		dispatched("A1,B2", "0x01", 2),
+		dispatched("C3", "0x02", 2),
+		dispatched("D4", "0x03", 3),
+	}
+	if err := ledger.RecordDispatched(ctx, routes); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.RecordDeliveries(ctx, routes); err != nil {
+		t.Fatalf("RecordDeliveries() error = %v", err)
+	}
+
+	inFlight, err := ledger.InFlight(ctx)
+	if err != nil {
+		t.Fatalf("InFlight() error = %v", err)
+	}
+	if inFlight[2] != 2 || inFlight[3] != 1 {
+		t.Errorf("InFlight() = %v, want 2 to domain 2 and 1 to domain 3", inFlight)
+	}
+
+	// Only domain 2 is checked: 0x01 was delivered, 0x02 cannot be checked
+	confirmed, err := ledger.ConfirmDeliveries(ctx, map[uint32]bool{2: true}, func(ctx context.Context, d storage.Delivery) (bool, error) {
+		if d.Destination != 2 {
+			t.Errorf("checked message %s to domain %d", d.MessageID, d.Destination)
+		}
+		if d.MessageID == "0x02" {
+			return false, errors.New("rpc unavailable")
+		}
+		return true, nil
+	})
+	if confirmed != 1 || err == nil {
+		t.Errorf("ConfirmDeliveries() = %d, %v, want 1 confirmed and the error of 0x02", confirmed, err)
+	}
+
+	inFlight, err = ledger.InFlight(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inFlight[2] != 1 || inFlight[3] != 1 {
+		t.Errorf("InFlight() after confirming = %v, want 1 to each domain", inFlight)
+	}
+	for _, txHash := range []string{"A1", "B2"} {
+		record, err := store.Route(ctx, txHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Status != storage.RouteDelivered {
+			t.Errorf("deposit %s is %s, want delivered", txHash, record.Status)
+		}
+	}
+
+	// Recording the dispatch again does not make a delivered message pending
+	if err := ledger.RecordDeliveries(ctx, routes[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if delivery, err := store.Delivery(ctx, "0x01"); err != nil || !delivery.Delivered {
+		t.Errorf("Delivery(0x01) = %+v, %v, want delivered", delivery, err)
+	}
+}
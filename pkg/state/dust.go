@@ -0,0 +1,67 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// accumulatedFor is the annotation naming the multisig an accumulating deposit was sent to, so a
+// state database shared by several multisigs never forwards one multisig's dust from another
+const accumulatedFor = "accumulated_for"
+
+// RecordAccumulating records routes below their destination's min_amount as accumulating for
+// multisig. Deposits already generated or broadcast keep their status.
+func (l *Ledger) RecordAccumulating(ctx context.Context, multisig string, routes []types.HyperlaneRoute) error {
+	for _, route := range routes {
+		record, err := l.store.Route(ctx, route.TransferKey())
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("failed to look up deposit %s: %w", route.TransferKey(), err)
+		case rebalancedStatus(record.Status), record.Status == storage.RouteAccumulating:
+			continue
+		}
+
+		annotations := map[string]string{accumulatedFor: multisig}
+		for k, v := range route.Annotations {
+			annotations[k] = v
+		}
+		route.Annotations = annotations
+		if err := l.store.SaveRoute(ctx, route, storage.RouteAccumulating); err != nil {
+			return fmt.Errorf("failed to record deposit %s as %s: %w", route.TransferKey(), storage.RouteAccumulating, err)
+		}
+	}
+	return nil
+}
+
+// Accumulating returns the routes accumulating for multisig, oldest first
+func (l *Ledger) Accumulating(ctx context.Context, multisig string) ([]types.HyperlaneRoute, error) {
+	records, err := l.store.RoutesByStatus(ctx, storage.RouteAccumulating)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s routes: %w", storage.RouteAccumulating, err)
+	}
+
+	var routes []types.HyperlaneRoute
+	for _, record := range records {
+		route := record.Route
+		if route.Annotations[accumulatedFor] != multisig {
+			continue
+		}
+		annotations := make(map[string]string)
+		for k, v := range route.Annotations {
+			if k != accumulatedFor {
+				annotations[k] = v
+			}
+		}
+		route.Annotations = nil
+		if len(annotations) > 0 {
+			route.Annotations = annotations
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
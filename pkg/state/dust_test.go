@@ -0,0 +1,49 @@
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestAccumulating(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	ledger := New(store)
+
+	dust := []types.HyperlaneRoute{
+		{TxHash: "A1", Amount: "10", Denom: "utia", Annotations: map[string]string{"source": "hook"}},
+		{TxHash: "B2", Amount: "20", Denom: "utia"},
+	}
+	if err := ledger.RecordGenerated(ctx, dust[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.RecordAccumulating(ctx, "celestia1multisig", dust); err != nil {
+		t.Fatalf("RecordAccumulating() error = %v", err)
+	}
+	if err := ledger.RecordAccumulating(ctx, "celestia1other", []types.HyperlaneRoute{{TxHash: "C3", Amount: "5"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the multisig's own dust accumulates; the generated deposit keeps its status
+	routes, err := ledger.Accumulating(ctx, "celestia1multisig")
+	if err != nil {
+		t.Fatalf("Accumulating() error = %v", err)
+	}
+	if len(routes) != 1 || routes[0].TxHash != "A1" {
+		t.Fatalf("Accumulating() = %+v, want A1 only", routes)
+	}
+	if len(routes[0].Annotations) != 1 || routes[0].Annotations["source"] != "hook" {
+		t.Errorf("annotations = %v, want the route's own", routes[0].Annotations)
+	}
+
+	// Once generated, the deposit no longer accumulates
+	if err := ledger.RecordGenerated(ctx, routes); err != nil {
+		t.Fatal(err)
+	}
+	if routes, err = ledger.Accumulating(ctx, "celestia1multisig"); err != nil || len(routes) != 0 {
+		t.Errorf("Accumulating() after generating = %+v, %v, want none", routes, err)
+	}
+}
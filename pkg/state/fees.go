@@ -0,0 +1,105 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// FeeReport is what running the corridor cost over a period: the fees of the rebalancing
+// transactions and the interchain gas of the messages they dispatched
+type FeeReport struct {
+	Since         time.Time         `json:"since"`
+	Transactions  int               `json:"transactions"`
+	Messages      int               `json:"messages"`
+	GasUsed       int64             `json:"gas_used"`
+	Fees          string            `json:"fees"`           // Transaction fees paid
+	InterchainGas string            `json:"interchain_gas"` // Upper bound: the MaxFee of every dispatched message
+	Destinations  []DestinationCost `json:"destinations"`
+}
+
+// DestinationCost is the interchain gas spent on the messages to one destination domain
+type DestinationCost struct {
+	Domain        uint32 `json:"domain"`
+	Messages      int    `json:"messages"`
+	InterchainGas string `json:"interchain_gas"`
+}
+
+// Fees reports the fees recorded in store since the given time. store must implement
+// storage.FeeStorage. Interchain gas is read from the dispatches of the dispatched and delivered
+// routes updated since then, counting each message once even when it forwards several deposits.
+func Fees(ctx context.Context, store storage.Storage, since time.Time) (*FeeReport, error) {
+	feeStore, ok := store.(storage.FeeStorage)
+	if !ok {
+		return nil, fmt.Errorf("state store does not record fees")
+	}
+	txFees, err := feeStore.TxFees(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FeeReport{Since: since, Transactions: len(txFees)}
+	fees := sdk.NewCoins()
+	for _, fee := range txFees {
+		report.Messages += fee.Messages
+		report.GasUsed += fee.GasUsed
+		if fee.Fee == "" {
+			continue
+		}
+		coins, err := sdk.ParseCoinsNormalized(fee.Fee)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee %q of tx %s: %w", fee.Fee, fee.TxHash, err)
+		}
+		fees = fees.Add(coins...)
+	}
+	report.Fees = fees.String()
+
+	seen := make(map[string]bool)
+	interchainGas := sdk.NewCoins()
+	destinations := make(map[uint32]*DestinationCost)
+	destinationGas := make(map[uint32]sdk.Coins)
+	for _, status := range []storage.RouteStatus{storage.RouteDispatched, storage.RouteDelivered} {
+		records, err := store.RoutesByStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			d := record.Route.Dispatch
+			if d == nil || record.UpdatedAt.Before(since) || seen[d.MessageID] {
+				continue
+			}
+			seen[d.MessageID] = true
+
+			cost, ok := destinations[d.Destination]
+			if !ok {
+				cost = &DestinationCost{Domain: d.Destination}
+				destinations[d.Destination] = cost
+				destinationGas[d.Destination] = sdk.NewCoins()
+			}
+			cost.Messages++
+			if d.InterchainGas == "" {
+				continue
+			}
+			coin, err := sdk.ParseCoinNormalized(d.InterchainGas)
+			if err != nil {
+				return nil, fmt.Errorf("invalid interchain gas %q of message %s: %w", d.InterchainGas, d.MessageID, err)
+			}
+			interchainGas = interchainGas.Add(coin)
+			destinationGas[d.Destination] = destinationGas[d.Destination].Add(coin)
+		}
+	}
+	report.InterchainGas = interchainGas.String()
+
+	for domain, cost := range destinations {
+		cost.InterchainGas = destinationGas[domain].String()
+		report.Destinations = append(report.Destinations, *cost)
+	}
+	sort.Slice(report.Destinations, func(i, j int) bool {
+		return report.Destinations[i].Domain < report.Destinations[j].Domain
+	})
+	return report, nil
+}
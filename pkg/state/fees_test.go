@@ -0,0 +1,62 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestFees(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	ledger := New(store)
+
+	for _, fee := range []storage.TxFee{
+		{TxHash: "REB1", Height: 200, Fee: "2000utia", GasUsed: 150000, Messages: 2},
+		{TxHash: "REB2", Height: 210, Fee: "1500utia", GasUsed: 90000, Messages: 1},
+	} {
+		if err := store.SaveTxFee(ctx, fee); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A1 and B2 were aggregated into one message, so its interchain gas counts once
+	dispatched := []types.HyperlaneRoute{
+		{TxHash: "A1,B2", Amount: "300", Dispatch: &types.Dispatch{MessageID: "0xaa", Destination: 1380012617, InterchainGas: "5000utia"}},
+		{TxHash: "C3", Amount: "100", Dispatch: &types.Dispatch{MessageID: "0xbb", Destination: 42161, InterchainGas: "7000utia"}},
+		{TxHash: "D4", Amount: "100", Dispatch: &types.Dispatch{MessageID: "0xcc", Destination: 1380012617}},
+	}
+	if err := ledger.RecordDispatched(ctx, dispatched); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Fees(ctx, store, time.Time{})
+	if err != nil {
+		t.Fatalf("Fees() error = %v", err)
+	}
+	if report.Transactions != 2 || report.Messages != 3 || report.GasUsed != 240000 || report.Fees != "3500utia" {
+		t.Errorf("Fees() = %+v", report)
+	}
+	if report.InterchainGas != "12000utia" {
+		t.Errorf("InterchainGas = %s, want 12000utia", report.InterchainGas)
+	}
+	want := []DestinationCost{
+		{Domain: 42161, Messages: 1, InterchainGas: "7000utia"},
+		{Domain: 1380012617, Messages: 2, InterchainGas: "5000utia"},
+	}
+	if len(report.Destinations) != len(want) {
+		t.Fatalf("Destinations = %+v, want %+v", report.Destinations, want)
+	}
+	for i := range want {
+		if report.Destinations[i] != want[i] {
+			t.Errorf("Destinations[%d] = %+v, want %+v", i, report.Destinations[i], want[i])
+		}
+	}
+
+	if report, err := Fees(ctx, store, time.Now().Add(time.Hour)); err != nil || report.Transactions != 0 || len(report.Destinations) != 0 {
+		t.Errorf("Fees() in the future = %+v, %v, want nothing", report, err)
+	}
+}
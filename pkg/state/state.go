@@ -0,0 +1,204 @@
+// Package state records which deposits have already been turned into outgoing MsgRemoteTransfers,
+// so that re-running parse or generate over an overlapping height range does not forward a deposit
+// twice. The records are route statuses in a storage.Storage, usually the SQLite database watch
+// keeps its checkpoint in.
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Rebalanced is a deposit the state has already seen in an outgoing transfer
+type Rebalanced struct {
+	TxHash    string              `json:"tx_hash"`
+	MsgIndex  int                 `json:"msg_index,omitempty"`
+	Status    storage.RouteStatus `json:"status"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// Broadcast reports whether the outgoing transfer was included on-chain, rather than only
+// generated into a transaction that may never have been signed
+func (r Rebalanced) Broadcast() bool {
+	return r.Status == storage.RouteDispatched || r.Status == storage.RouteDelivered
+}
+
+// Ledger records the rebalancing state of deposits
+type Ledger struct {
+	store storage.Storage
+}
+
+// New returns a ledger keeping its records in store
+func New(store storage.Storage) *Ledger {
+	return &Ledger{store: store}
+}
+
+// Check splits routes into those whose deposits were not rebalanced yet and the deposits that
+// were, keyed by transfer key (see types.HyperlaneRoute.TransferKey). A route forwarding several
+// deposits (aggregated by the strategy) counts as rebalanced if any of them was.
+func (l *Ledger) Check(ctx context.Context, routes []types.HyperlaneRoute) ([]types.HyperlaneRoute, map[string]Rebalanced, error) {
+	var fresh []types.HyperlaneRoute
+	rebalanced := make(map[string]Rebalanced)
+	for _, route := range routes {
+		seen := false
+		for _, key := range DepositKeys(route) {
+			record, err := l.store.Route(ctx, key)
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to look up deposit %s: %w", key, err)
+			}
+			if !rebalancedStatus(record.Status) {
+				continue
+			}
+			txHash, msgIndex := types.SplitTransferKey(key)
+			rebalanced[key] = Rebalanced{TxHash: txHash, MsgIndex: msgIndex, Status: record.Status, UpdatedAt: record.UpdatedAt}
+			seen = true
+		}
+		if !seen {
+			fresh = append(fresh, route)
+		}
+	}
+	return fresh, rebalanced, nil
+}
+
+// RecordGenerated records that the deposits of routes were generated into a transaction. Deposits
+// already recorded as broadcast keep that status. Pass the deposits' own routes rather than the
+// routes the strategy aggregated them into, see Deposits.
+func (l *Ledger) RecordGenerated(ctx context.Context, routes []types.HyperlaneRoute) error {
+	return l.record(ctx, routes, storage.RouteGenerated)
+}
+
+// RecordDispatched records that the transfers of routes were broadcast and dispatched
+func (l *Ledger) RecordDispatched(ctx context.Context, routes []types.HyperlaneRoute) error {
+	return l.record(ctx, routes, storage.RouteDispatched)
+}
+
+// record saves every deposit of routes with status, never moving a deposit back in its lifecycle
+func (l *Ledger) record(ctx context.Context, routes []types.HyperlaneRoute, status storage.RouteStatus) error {
+	for _, route := range routes {
+		for _, key := range DepositKeys(route) {
+			deposit := route
+			deposit.TxHash, deposit.MsgIndex = types.SplitTransferKey(key)
+
+			// Keep the deposit's own record, e.g. from watch, over an aggregated route
+			record, err := l.store.Route(ctx, key)
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+			case err != nil:
+				return fmt.Errorf("failed to look up deposit %s: %w", key, err)
+			case statusRank(record.Status) >= statusRank(status):
+				continue
+			default:
+				deposit = record.Route
+				if route.Dispatch != nil {
+					deposit.Dispatch = route.Dispatch
+				}
+			}
+
+			if err := l.store.SaveRoute(ctx, deposit, status); err != nil {
+				return fmt.Errorf("failed to record deposit %s as %s: %w", key, status, err)
+			}
+		}
+	}
+	return nil
+}
+
+// FirstSeenRecipients describes every recipient of routes that no broadcast transfer on the same
+// domain went to before, once each. Every recipient of a fan-out route counts.
+func (l *Ledger) FirstSeenRecipients(ctx context.Context, routes []types.HyperlaneRoute) ([]string, error) {
+	known := make(map[string]bool)
+	for _, status := range []storage.RouteStatus{storage.RouteDispatched, storage.RouteDelivered} {
+		records, err := l.store.RoutesByStatus(ctx, status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s routes: %w", status, err)
+		}
+		for _, record := range records {
+			if info := record.Route.RouteInfo; info != nil {
+				for _, recipient := range info.Recipients() {
+					known[recipientKey(info.DestinationDomain, recipient)] = true
+				}
+			}
+		}
+	}
+
+	var firstSeen []string
+	for _, route := range routes {
+		if route.RouteInfo == nil {
+			continue
+		}
+		for _, recipient := range route.RouteInfo.Recipients() {
+			key := recipientKey(route.RouteInfo.DestinationDomain, recipient)
+			if known[key] {
+				continue
+			}
+			known[key] = true
+			firstSeen = append(firstSeen, fmt.Sprintf("tx %s: recipient %s on domain %d was never sent to before",
+				route.TxHash, recipient, route.RouteInfo.DestinationDomain))
+		}
+	}
+	return firstSeen, nil
+}
+
+// recipientKey identifies a recipient on a domain in the form addresses are compared in
+func recipientKey(domain uint32, recipient string) string {
+	return fmt.Sprintf("%d/%s", domain, types.NormalizeAddress(recipient))
+}
+
+// Deposits returns the routes of input whose deposits are forwarded by one of planned, the routes
+// a strategy produced from input
+func Deposits(input, planned []types.HyperlaneRoute) []types.HyperlaneRoute {
+	forwarded := make(map[string]bool)
+	for _, route := range planned {
+		for _, key := range DepositKeys(route) {
+			forwarded[key] = true
+		}
+	}
+	var deposits []types.HyperlaneRoute
+	for _, route := range input {
+		if forwarded[route.TransferKey()] {
+			deposits = append(deposits, route)
+		}
+	}
+	return deposits
+}
+
+// DepositKeys returns the transfer keys of the deposits a route forwards, one per transfer since a
+// transaction can carry several. Routes aggregated by the strategy list their deposits' keys
+// separated by commas.
+func DepositKeys(route types.HyperlaneRoute) []string {
+	var keys []string
+	for _, key := range strings.Split(route.TransferKey(), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// rebalancedStatus reports whether a route in status was turned into an outgoing transfer
+func rebalancedStatus(status storage.RouteStatus) bool {
+	return statusRank(status) >= statusRank(storage.RouteGenerated)
+}
+
+// statusRank orders the statuses of a deposit that is being rebalanced. Failed routes rank with
+// parsed ones, since they may be retried.
+func statusRank(status storage.RouteStatus) int {
+	switch status {
+	case storage.RouteGenerated:
+		return 1
+	case storage.RouteDispatched:
+		return 2
+	case storage.RouteDelivered:
+		return 3
+	default:
+		return 0
+	}
+}
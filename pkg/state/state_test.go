@@ -0,0 +1,154 @@
+package state
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestLedger(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	ledger := New(store)
+
+	routes := []types.HyperlaneRoute{
+		{TxHash: "A1", Amount: "100", Denom: "utia"},
+		{TxHash: "B2", Amount: "200", Denom: "utia"},
+		{TxHash: "C3", Amount: "300", Denom: "utia"},
+	}
+	if err := store.SaveRoute(ctx, routes[2], storage.RouteParsed); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, rebalanced, err := ledger.Check(ctx, routes)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(fresh) != 3 || len(rebalanced) != 0 {
+		t.Fatalf("Check() of new deposits = %d fresh, %v rebalanced", len(fresh), rebalanced)
+	}
+
+	// A1 and B2 are aggregated into one transfer, which is then dispatched
+	aggregated := types.HyperlaneRoute{TxHash: "A1,B2", Amount: "300", Denom: "utia"}
+	deposits := Deposits(routes, []types.HyperlaneRoute{aggregated})
+	if len(deposits) != 2 {
+		t.Fatalf("Deposits() = %+v, want A1 and B2", deposits)
+	}
+	if err := ledger.RecordGenerated(ctx, deposits); err != nil {
+		t.Fatalf("RecordGenerated() error = %v", err)
+	}
+	fresh, rebalanced, err = ledger.Check(ctx, routes)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(fresh) != 1 || fresh[0].TxHash != "C3" {
+		t.Errorf("Check() fresh = %+v, want only C3", fresh)
+	}
+	if r := rebalanced["B2"]; r.Status != storage.RouteGenerated || r.Broadcast() {
+		t.Errorf("Check() B2 = %+v, want generated", r)
+	}
+
+	if err := ledger.RecordDispatched(ctx, []types.HyperlaneRoute{aggregated}); err != nil {
+		t.Fatalf("RecordDispatched() error = %v", err)
+	}
+	// Generating again does not move a dispatched deposit back
+	if err := ledger.RecordGenerated(ctx, routes[:1]); err != nil {
+		t.Fatalf("RecordGenerated() error = %v", err)
+	}
+	record, err := store.Route(ctx, "A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != storage.RouteDispatched || record.Route.Amount != "100" {
+		t.Errorf("A1 record = %+v, want the dispatched deposit", record)
+	}
+
+	_, rebalanced, err = ledger.Check(ctx, []types.HyperlaneRoute{{TxHash: "C3,A1"}})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if r := rebalanced["A1"]; !r.Broadcast() || len(rebalanced) != 1 {
+		t.Errorf("Check() of a route aggregating A1 = %v, want A1 broadcast", rebalanced)
+	}
+}
+
+func TestLedgerTransfersOfOneTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	ledger := New(store)
+
+	// D4 carries two transfers; only the second was planned
+	routes := []types.HyperlaneRoute{
+		{TxHash: "D4", Amount: "100", Denom: "utia"},
+		{TxHash: "D4", MsgIndex: 1, Amount: "200", Denom: "utia"},
+	}
+	deposits := Deposits(routes, routes[1:])
+	if len(deposits) != 1 || deposits[0].MsgIndex != 1 {
+		t.Fatalf("Deposits() = %+v, want the second transfer of D4", deposits)
+	}
+	if err := ledger.RecordGenerated(ctx, deposits); err != nil {
+		t.Fatalf("RecordGenerated() error = %v", err)
+	}
+
+	fresh, rebalanced, err := ledger.Check(ctx, routes)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(fresh) != 1 || fresh[0].MsgIndex != 0 {
+		t.Errorf("Check() fresh = %+v, want the first transfer of D4", fresh)
+	}
+	if r, ok := rebalanced["D4/1"]; !ok || r.TxHash != "D4" || r.MsgIndex != 1 {
+		t.Errorf("Check() rebalanced = %+v, want the second transfer of D4", rebalanced)
+	}
+
+	// Both transfers aggregated into one route are recorded separately
+	if err := ledger.RecordDispatched(ctx, []types.HyperlaneRoute{{TxHash: "D4,D4/1", Amount: "300", Denom: "utia"}}); err != nil {
+		t.Fatalf("RecordDispatched() error = %v", err)
+	}
+	for _, key := range []string{"D4", "D4/1"} {
+		record, err := store.Route(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash, index := types.SplitTransferKey(key); record.Status != storage.RouteDispatched || record.Route.TxHash != hash || record.Route.MsgIndex != index {
+			t.Errorf("record of %s = %+v, want the dispatched transfer", key, record)
+		}
+	}
+}
+
+func TestFirstSeenRecipients(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemory()
+	ledger := New(store)
+
+	known := "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	sent := types.HyperlaneRoute{TxHash: "A1", Amount: "100", RouteInfo: &types.RouteInfo{DestinationDomain: 2340, Recipient: known}}
+	if err := store.SaveRoute(ctx, sent, storage.RouteDelivered); err != nil {
+		t.Fatal(err)
+	}
+	// Generated but never broadcast, so its recipient was not sent to yet
+	pending := types.HyperlaneRoute{TxHash: "B2", Amount: "100", RouteInfo: &types.RouteInfo{DestinationDomain: 2340, Recipient: "0x00000000000000000000000000000000000000bb"}}
+	if err := store.SaveRoute(ctx, pending, storage.RouteGenerated); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := []types.HyperlaneRoute{
+		// Same recipient, compared case-insensitively
+		{TxHash: "C3", Amount: "5", RouteInfo: &types.RouteInfo{DestinationDomain: 2340, Recipient: strings.ToLower(known)}},
+		// Same address on another domain
+		{TxHash: "D4", Amount: "5", RouteInfo: &types.RouteInfo{DestinationDomain: 1, Recipient: known}},
+		pending,
+		// A second route to a new recipient is reported once
+		{TxHash: "E5", Amount: "5", RouteInfo: &types.RouteInfo{DestinationDomain: 1, Recipient: known}},
+	}
+	firstSeen, err := ledger.FirstSeenRecipients(ctx, routes)
+	if err != nil {
+		t.Fatalf("FirstSeenRecipients() error = %v", err)
+	}
+	if len(firstSeen) != 2 || !strings.HasPrefix(firstSeen[0], "tx D4:") || !strings.HasPrefix(firstSeen[1], "tx B2:") {
+		t.Errorf("FirstSeenRecipients() = %v, want D4 and B2", firstSeen)
+	}
+}
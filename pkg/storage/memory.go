@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Memory is a Storage kept in memory, for tests and short-lived embedders. It is safe for
+// concurrent use.
+type Memory struct {
+	mu          sync.Mutex
+	now         func() time.Time
+	checkpoints map[string]int64
+	processed   map[string]int64
+	routes      map[string]RouteRecord
+	deliveries  map[string]Delivery
+	shards      map[string][]Shard // By job, ordered by height
+	headers     map[int64]types.BlockHeader
+	fees        map[string]TxFee
+	nonces      map[[2]string]Nonce // By sender and nonce
+}
+
+var (
+	_ ShardStorage  = (*Memory)(nil)
+	_ HeaderStorage = (*Memory)(nil)
+	_ FeeStorage    = (*Memory)(nil)
+	_ NonceStorage  = (*Memory)(nil)
+)
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		now:         time.Now,
+		checkpoints: make(map[string]int64),
+		processed:   make(map[string]int64),
+		routes:      make(map[string]RouteRecord),
+		deliveries:  make(map[string]Delivery),
+		shards:      make(map[string][]Shard),
+		headers:     make(map[int64]types.BlockHeader),
+		fees:        make(map[string]TxFee),
+		nonces:      make(map[[2]string]Nonce),
+	}
+}
+
+// Checkpoint implements Storage
+func (m *Memory) Checkpoint(ctx context.Context, source string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoints[source], nil
+}
+
+// SetCheckpoint implements Storage
+func (m *Memory) SetCheckpoint(ctx context.Context, source string, height int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[source] = height
+	return nil
+}
+
+// MarkProcessed implements Storage
+func (m *Memory) MarkProcessed(ctx context.Context, key string, height int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processed[key] = height
+	return nil
+}
+
+// IsProcessed implements Storage
+func (m *Memory) IsProcessed(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.processed[key]
+	return ok, nil
+}
+
+// SaveRoute implements Storage
+func (m *Memory) SaveRoute(ctx context.Context, route types.HyperlaneRoute, status RouteStatus) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes[route.TransferKey()] = RouteRecord{Route: route, Status: status, UpdatedAt: m.now().UTC()}
+	return nil
+}
+
+// Route implements Storage
+func (m *Memory) Route(ctx context.Context, key string) (*RouteRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, ok := m.routes[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &record, nil
+}
+
+// RoutesByStatus implements Storage
+func (m *Memory) RoutesByStatus(ctx context.Context, status RouteStatus) ([]RouteRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var records []RouteRecord
+	for _, record := range m.routes {
+		if record.Status == status {
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].UpdatedAt.Equal(records[j].UpdatedAt) {
+			return records[i].UpdatedAt.Before(records[j].UpdatedAt)
+		}
+		return records[i].Route.TxHash < records[j].Route.TxHash
+	})
+	return records, nil
+}
+
+// SaveDelivery implements Storage
+func (m *Memory) SaveDelivery(ctx context.Context, delivery Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery.UpdatedAt = m.now().UTC()
+	m.deliveries[delivery.MessageID] = delivery
+	return nil
+}
+
+// Delivery implements Storage
+func (m *Memory) Delivery(ctx context.Context, messageID string) (*Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery, ok := m.deliveries[messageID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &delivery, nil
+}
+
+// PendingDeliveries implements Storage
+func (m *Memory) PendingDeliveries(ctx context.Context) ([]Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var pending []Delivery
+	for _, delivery := range m.deliveries {
+		if !delivery.Delivered {
+			pending = append(pending, delivery)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		if !pending[i].UpdatedAt.Equal(pending[j].UpdatedAt) {
+			return pending[i].UpdatedAt.Before(pending[j].UpdatedAt)
+		}
+		return pending[i].MessageID < pending[j].MessageID
+	})
+	return pending, nil
+}
+
+// AddShards implements ShardStorage
+func (m *Memory) AddShards(ctx context.Context, shards []Shard) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, shard := range shards {
+		if m.shardIndex(shard) >= 0 {
+			continue
+		}
+		shard.Worker = ""
+		shard.LeaseUntil = time.Time{}
+		job := append(m.shards[shard.Job], shard)
+		sort.Slice(job, func(i, j int) bool { return job[i].FromHeight < job[j].FromHeight })
+		m.shards[shard.Job] = job
+	}
+	return nil
+}
+
+// ClaimShard implements ShardStorage
+func (m *Memory) ClaimShard(ctx context.Context, job, worker string, leaseUntil time.Time) (*Shard, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	for i, shard := range m.shards[job] {
+		if shard.Done() || (shard.Worker != "" && shard.LeaseUntil.After(now)) {
+			continue
+		}
+		shard.Worker = worker
+		shard.LeaseUntil = leaseUntil.UTC()
+		m.shards[job][i] = shard
+		return &shard, nil
+	}
+	return nil, ErrNotFound
+}
+
+// UpdateShard implements ShardStorage
+func (m *Memory) UpdateShard(ctx context.Context, shard Shard) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, err := m.claimedShard(shard)
+	if err != nil {
+		return err
+	}
+	m.shards[shard.Job][i].Progress = shard.Progress
+	m.shards[shard.Job][i].LeaseUntil = shard.LeaseUntil.UTC()
+	return nil
+}
+
+// ReleaseShard implements ShardStorage
+func (m *Memory) ReleaseShard(ctx context.Context, shard Shard) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, err := m.claimedShard(shard)
+	if err != nil {
+		return err
+	}
+	m.shards[shard.Job][i].Progress = shard.Progress
+	m.shards[shard.Job][i].Worker = ""
+	m.shards[shard.Job][i].LeaseUntil = time.Time{}
+	return nil
+}
+
+// Shards implements ShardStorage
+func (m *Memory) Shards(ctx context.Context, job string) ([]Shard, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Shard(nil), m.shards[job]...), nil
+}
+
+// shardIndex returns the index of shard in its job, or -1
+func (m *Memory) shardIndex(shard Shard) int {
+	for i, s := range m.shards[shard.Job] {
+		if s.FromHeight == shard.FromHeight {
+			return i
+		}
+	}
+	return -1
+}
+
+// claimedShard returns the index of shard if shard.Worker still holds its claim
+func (m *Memory) claimedShard(shard Shard) (int, error) {
+	i := m.shardIndex(shard)
+	if i < 0 {
+		return 0, ErrNotFound
+	}
+	if m.shards[shard.Job][i].Worker != shard.Worker || shard.Worker == "" {
+		return 0, ErrShardLost
+	}
+	return i, nil
+}
+
+// SaveHeader implements HeaderStorage
+func (m *Memory) SaveHeader(ctx context.Context, header types.BlockHeader) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.headers[header.Height] = header
+	return nil
+}
+
+// Header implements HeaderStorage
+func (m *Memory) Header(ctx context.Context, height int64) (*types.BlockHeader, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	header, ok := m.headers[height]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &header, nil
+}
+
+// SaveTxFee implements FeeStorage
+func (m *Memory) SaveTxFee(ctx context.Context, fee TxFee) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fee.RecordedAt = m.now().UTC()
+	m.fees[fee.TxHash] = fee
+	return nil
+}
+
+// TxFees implements FeeStorage
+func (m *Memory) TxFees(ctx context.Context, since time.Time) ([]TxFee, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var fees []TxFee
+	for _, fee := range m.fees {
+		if !fee.RecordedAt.Before(since) {
+			fees = append(fees, fee)
+		}
+	}
+	sort.Slice(fees, func(i, j int) bool {
+		if fees[i].Height != fees[j].Height {
+			return fees[i].Height < fees[j].Height
+		}
+		return fees[i].TxHash < fees[j].TxHash
+	})
+	return fees, nil
+}
+
+// ClaimNonce implements NonceStorage
+func (m *Memory) ClaimNonce(ctx context.Context, nonce Nonce) (*Nonce, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := [2]string{nonce.Sender, nonce.Nonce}
+	if claim, ok := m.nonces[key]; ok {
+		return &claim, nil
+	}
+	nonce.RecordedAt = m.now().UTC()
+	m.nonces[key] = nonce
+	return &nonce, nil
+}
+
+// Close implements Storage
+func (m *Memory) Close() error {
+	return nil
+}
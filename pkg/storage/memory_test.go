@@ -0,0 +1,12 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage/storagetest"
+)
+
+func TestMemory(t *testing.T) {
+	storagetest.Run(t, storage.NewMemory())
+}
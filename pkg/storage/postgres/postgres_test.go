@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage/storagetest"
+)
+
+// TestPostgres runs against the database in REBALANCER_TEST_POSTGRES_DSN, in a fresh schema that is
+// dropped afterwards
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("REBALANCER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("REBALANCER_TEST_POSTGRES_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	schema := fmt.Sprintf("rebalancer_test_%d", time.Now().UnixNano())
+	if _, err := db.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	defer db.Exec("DROP SCHEMA " + schema + " CASCADE")
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	store, err := Open(context.Background(), dsn+separator+"search_path="+schema)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	storagetest.Run(t, store)
+}
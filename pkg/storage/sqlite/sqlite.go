@@ -0,0 +1,28 @@
+// Package sqlite opens a SQLite-backed storage.Storage. It requires cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage/sqlstore"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Open opens or creates the SQLite database at path. Use ":memory:" for a throwaway database.
+func Open(ctx context.Context, path string) (*sqlstore.Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer; one connection also keeps ":memory:" databases shared
+	db.SetMaxOpenConns(1)
+
+	store, err := sqlstore.New(ctx, db, sqlstore.SQLite)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage/storagetest"
+)
+
+func TestSQLite(t *testing.T) {
+	store, err := Open(context.Background(), filepath.Join(t.TempDir(), "rebalancer.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	storagetest.Run(t, store)
+}
+
+func TestSQLiteReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rebalancer.db")
+
+	store, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.SetCheckpoint(ctx, "celestia", 42); err != nil {
+		t.Fatalf("SetCheckpoint() error = %v", err)
+	}
+	store.Close()
+
+	store, err = Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Open() existing database error = %v", err)
+	}
+	defer store.Close()
+	if height, err := store.Checkpoint(ctx, "celestia"); err != nil || height != 42 {
+		t.Errorf("Checkpoint() after reopening = %d, %v, want 42", height, err)
+	}
+}
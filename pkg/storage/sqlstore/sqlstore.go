@@ -0,0 +1,468 @@
+// Package sqlstore implements storage.Storage on a database/sql database. The schema and queries
+// are portable between SQLite and PostgreSQL; the dialect only decides how parameters are bound.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Dialect describes how a database binds query parameters
+type Dialect struct {
+	Name string
+	// Numbered binds parameters as $1, $2, ... instead of ?
+	Numbered bool
+}
+
+var (
+	SQLite   = Dialect{Name: "sqlite"}
+	Postgres = Dialect{Name: "postgres", Numbered: true}
+)
+
+// schema creates the tables if they do not exist yet. Times are stored as Unix nanoseconds. The
+// tx_hash of processed deposits and routes is the deposit's transfer key: its hash, followed by
+// its message index for transfers after the first message of the transaction.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS checkpoints (source TEXT PRIMARY KEY, height BIGINT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS processed_txs (tx_hash TEXT PRIMARY KEY, height BIGINT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS routes (tx_hash TEXT PRIMARY KEY, status TEXT NOT NULL, record TEXT NOT NULL, updated_at BIGINT NOT NULL)`,
+	`CREATE INDEX IF NOT EXISTS routes_status ON routes (status, updated_at)`,
+	`CREATE TABLE IF NOT EXISTS deliveries (message_id TEXT PRIMARY KEY, delivered BOOLEAN NOT NULL, record TEXT NOT NULL, updated_at BIGINT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS shards (job TEXT NOT NULL, from_height BIGINT NOT NULL, to_height BIGINT NOT NULL, progress BIGINT NOT NULL, worker TEXT NOT NULL, lease_until BIGINT NOT NULL, PRIMARY KEY (job, from_height))`,
+	`CREATE TABLE IF NOT EXISTS headers (height BIGINT PRIMARY KEY, hash TEXT NOT NULL, time BIGINT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS tx_fees (tx_hash TEXT PRIMARY KEY, height BIGINT NOT NULL, record TEXT NOT NULL, recorded_at BIGINT NOT NULL)`,
+	`CREATE TABLE IF NOT EXISTS nonces (sender TEXT NOT NULL, nonce TEXT NOT NULL, record TEXT NOT NULL, PRIMARY KEY (sender, nonce))`,
+}
+
+// Store is a storage.Storage backed by a SQL database
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	now     func() time.Time
+}
+
+var (
+	_ storage.Storage       = (*Store)(nil)
+	_ storage.ShardStorage  = (*Store)(nil)
+	_ storage.HeaderStorage = (*Store)(nil)
+	_ storage.FeeStorage    = (*Store)(nil)
+	_ storage.NonceStorage  = (*Store)(nil)
+)
+
+// New creates a store on db, creating its tables if needed. The store takes ownership of db and
+// closes it on Close.
+func New(ctx context.Context, db *sql.DB, dialect Dialect) (*Store, error) {
+	for _, stmt := range schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to create %s schema: %w", dialect.Name, err)
+		}
+	}
+	return &Store{db: db, dialect: dialect, now: time.Now}, nil
+}
+
+// bind rewrites ? placeholders for the dialect
+func (s *Store) bind(query string) string {
+	if !s.dialect.Numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func (s *Store) exec(ctx context.Context, query string, args ...any) error {
+	_, err := s.db.ExecContext(ctx, s.bind(query), args...)
+	return err
+}
+
+// Checkpoint implements storage.Storage
+func (s *Store) Checkpoint(ctx context.Context, source string) (int64, error) {
+	var height int64
+	err := s.db.QueryRowContext(ctx, s.bind(`SELECT height FROM checkpoints WHERE source = ?`), source).Scan(&height)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint of %s: %w", source, err)
+	}
+	return height, nil
+}
+
+// SetCheckpoint implements storage.Storage
+func (s *Store) SetCheckpoint(ctx context.Context, source string, height int64) error {
+	err := s.exec(ctx, `INSERT INTO checkpoints (source, height) VALUES (?, ?)
+		ON CONFLICT (source) DO UPDATE SET height = excluded.height`, source, height)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint of %s: %w", source, err)
+	}
+	return nil
+}
+
+// MarkProcessed implements storage.Storage
+func (s *Store) MarkProcessed(ctx context.Context, key string, height int64) error {
+	err := s.exec(ctx, `INSERT INTO processed_txs (tx_hash, height) VALUES (?, ?)
+		ON CONFLICT (tx_hash) DO UPDATE SET height = excluded.height`, key, height)
+	if err != nil {
+		return fmt.Errorf("failed to mark deposit %s processed: %w", key, err)
+	}
+	return nil
+}
+
+// IsProcessed implements storage.Storage
+func (s *Store) IsProcessed(ctx context.Context, key string) (bool, error) {
+	var height int64
+	err := s.db.QueryRowContext(ctx, s.bind(`SELECT height FROM processed_txs WHERE tx_hash = ?`), key).Scan(&height)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up deposit %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// SaveRoute implements storage.Storage
+func (s *Store) SaveRoute(ctx context.Context, route types.HyperlaneRoute, status storage.RouteStatus) error {
+	record := storage.RouteRecord{Route: route, Status: status, UpdatedAt: s.now().UTC()}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal route: %w", err)
+	}
+	err = s.exec(ctx, `INSERT INTO routes (tx_hash, status, record, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (tx_hash) DO UPDATE SET status = excluded.status, record = excluded.record, updated_at = excluded.updated_at`,
+		route.TransferKey(), string(status), string(data), record.UpdatedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to save route of deposit %s: %w", route.TransferKey(), err)
+	}
+	return nil
+}
+
+// Route implements storage.Storage
+func (s *Store) Route(ctx context.Context, key string) (*storage.RouteRecord, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.bind(`SELECT record FROM routes WHERE tx_hash = ?`), key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read route of deposit %s: %w", key, err)
+	}
+	var record storage.RouteRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, fmt.Errorf("failed to parse route of deposit %s: %w", key, err)
+	}
+	return &record, nil
+}
+
+// RoutesByStatus implements storage.Storage
+func (s *Store) RoutesByStatus(ctx context.Context, status storage.RouteStatus) ([]storage.RouteRecord, error) {
+	var records []storage.RouteRecord
+	err := s.query(ctx, func(data string) error {
+		var record storage.RouteRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	}, `SELECT record FROM routes WHERE status = ? ORDER BY updated_at, tx_hash`, string(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s routes: %w", status, err)
+	}
+	return records, nil
+}
+
+// SaveDelivery implements storage.Storage
+func (s *Store) SaveDelivery(ctx context.Context, delivery storage.Delivery) error {
+	delivery.UpdatedAt = s.now().UTC()
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery: %w", err)
+	}
+	err = s.exec(ctx, `INSERT INTO deliveries (message_id, delivered, record, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (message_id) DO UPDATE SET delivered = excluded.delivered, record = excluded.record, updated_at = excluded.updated_at`,
+		delivery.MessageID, delivery.Delivered, string(data), delivery.UpdatedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to save delivery of message %s: %w", delivery.MessageID, err)
+	}
+	return nil
+}
+
+// Delivery implements storage.Storage
+func (s *Store) Delivery(ctx context.Context, messageID string) (*storage.Delivery, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, s.bind(`SELECT record FROM deliveries WHERE message_id = ?`), messageID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delivery of message %s: %w", messageID, err)
+	}
+	var delivery storage.Delivery
+	if err := json.Unmarshal([]byte(data), &delivery); err != nil {
+		return nil, fmt.Errorf("failed to parse delivery of message %s: %w", messageID, err)
+	}
+	return &delivery, nil
+}
+
+// PendingDeliveries implements storage.Storage
+func (s *Store) PendingDeliveries(ctx context.Context) ([]storage.Delivery, error) {
+	var pending []storage.Delivery
+	err := s.query(ctx, func(data string) error {
+		var delivery storage.Delivery
+		if err := json.Unmarshal([]byte(data), &delivery); err != nil {
+			return err
+		}
+		pending = append(pending, delivery)
+		return nil
+	}, `SELECT record FROM deliveries WHERE delivered = ? ORDER BY updated_at, message_id`, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending deliveries: %w", err)
+	}
+	return pending, nil
+}
+
+// AddShards implements storage.ShardStorage
+func (s *Store) AddShards(ctx context.Context, shards []storage.Shard) error {
+	for _, shard := range shards {
+		err := s.exec(ctx, `INSERT INTO shards (job, from_height, to_height, progress, worker, lease_until) VALUES (?, ?, ?, ?, '', 0)
+			ON CONFLICT (job, from_height) DO NOTHING`, shard.Job, shard.FromHeight, shard.ToHeight, shard.Progress)
+		if err != nil {
+			return fmt.Errorf("failed to add shard %d-%d of %s: %w", shard.FromHeight, shard.ToHeight, shard.Job, err)
+		}
+	}
+	return nil
+}
+
+// ClaimShard implements storage.ShardStorage. The claim is a conditional update, so workers on
+// other connections racing for the same shard cannot both win it; the loser tries the next one.
+func (s *Store) ClaimShard(ctx context.Context, job, worker string, leaseUntil time.Time) (*storage.Shard, error) {
+	now := s.now().UnixNano()
+	for {
+		var from int64
+		err := s.db.QueryRowContext(ctx, s.bind(`SELECT from_height FROM shards
+			WHERE job = ? AND progress < to_height AND (worker = '' OR lease_until <= ?)
+			ORDER BY from_height LIMIT 1`), job, now).Scan(&from)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find a shard of %s to claim: %w", job, err)
+		}
+
+		res, err := s.db.ExecContext(ctx, s.bind(`UPDATE shards SET worker = ?, lease_until = ?
+			WHERE job = ? AND from_height = ? AND progress < to_height AND (worker = '' OR lease_until <= ?)`),
+			worker, leaseUntil.UnixNano(), job, from, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim shard %d of %s: %w", from, job, err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n == 1 {
+			return s.shard(ctx, job, from)
+		}
+	}
+}
+
+// UpdateShard implements storage.ShardStorage
+func (s *Store) UpdateShard(ctx context.Context, shard storage.Shard) error {
+	return s.saveClaimed(ctx, shard, `UPDATE shards SET progress = ?, lease_until = ? WHERE job = ? AND from_height = ? AND worker = ?`,
+		shard.Progress, shard.LeaseUntil.UnixNano(), shard.Job, shard.FromHeight, shard.Worker)
+}
+
+// ReleaseShard implements storage.ShardStorage
+func (s *Store) ReleaseShard(ctx context.Context, shard storage.Shard) error {
+	return s.saveClaimed(ctx, shard, `UPDATE shards SET progress = ?, worker = '', lease_until = 0 WHERE job = ? AND from_height = ? AND worker = ?`,
+		shard.Progress, shard.Job, shard.FromHeight, shard.Worker)
+}
+
+// saveClaimed runs an update of a shard conditioned on its claim
+func (s *Store) saveClaimed(ctx context.Context, shard storage.Shard, query string, args ...any) error {
+	if shard.Worker == "" {
+		return storage.ErrShardLost
+	}
+	res, err := s.db.ExecContext(ctx, s.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("failed to save shard %d of %s: %w", shard.FromHeight, shard.Job, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return storage.ErrShardLost
+	}
+	return nil
+}
+
+// Shards implements storage.ShardStorage
+func (s *Store) Shards(ctx context.Context, job string) ([]storage.Shard, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind(`SELECT job, from_height, to_height, progress, worker, lease_until FROM shards
+		WHERE job = ? ORDER BY from_height`), job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shards of %s: %w", job, err)
+	}
+	defer rows.Close()
+
+	var shards []storage.Shard
+	for rows.Next() {
+		shard, err := scanShard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shards of %s: %w", job, err)
+		}
+		shards = append(shards, *shard)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read shards of %s: %w", job, err)
+	}
+	return shards, nil
+}
+
+// shard reads a single shard
+func (s *Store) shard(ctx context.Context, job string, from int64) (*storage.Shard, error) {
+	row := s.db.QueryRowContext(ctx, s.bind(`SELECT job, from_height, to_height, progress, worker, lease_until FROM shards
+		WHERE job = ? AND from_height = ?`), job, from)
+	shard, err := scanShard(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shard %d of %s: %w", from, job, err)
+	}
+	return shard, nil
+}
+
+// scanShard scans a shard row; a zero lease is an unset time
+func scanShard(row interface{ Scan(...any) error }) (*storage.Shard, error) {
+	var shard storage.Shard
+	var lease int64
+	if err := row.Scan(&shard.Job, &shard.FromHeight, &shard.ToHeight, &shard.Progress, &shard.Worker, &lease); err != nil {
+		return nil, err
+	}
+	if lease != 0 {
+		shard.LeaseUntil = time.Unix(0, lease).UTC()
+	}
+	return &shard, nil
+}
+
+// SaveHeader implements storage.HeaderStorage
+func (s *Store) SaveHeader(ctx context.Context, header types.BlockHeader) error {
+	err := s.exec(ctx, `INSERT INTO headers (height, hash, time) VALUES (?, ?, ?)
+		ON CONFLICT (height) DO UPDATE SET hash = excluded.hash, time = excluded.time`,
+		header.Height, header.Hash, header.Time.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to save header at height %d: %w", header.Height, err)
+	}
+	return nil
+}
+
+// Header implements storage.HeaderStorage
+func (s *Store) Header(ctx context.Context, height int64) (*types.BlockHeader, error) {
+	header := types.BlockHeader{Height: height}
+	var t int64
+	err := s.db.QueryRowContext(ctx, s.bind(`SELECT hash, time FROM headers WHERE height = ?`), height).Scan(&header.Hash, &t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header at height %d: %w", height, err)
+	}
+	header.Time = time.Unix(0, t).UTC()
+	return &header, nil
+}
+
+// SaveTxFee implements storage.FeeStorage
+func (s *Store) SaveTxFee(ctx context.Context, fee storage.TxFee) error {
+	fee.RecordedAt = s.now().UTC()
+	data, err := json.Marshal(fee)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fee: %w", err)
+	}
+	err = s.exec(ctx, `INSERT INTO tx_fees (tx_hash, height, record, recorded_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (tx_hash) DO UPDATE SET height = excluded.height, record = excluded.record, recorded_at = excluded.recorded_at`,
+		fee.TxHash, fee.Height, string(data), fee.RecordedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to save fee of tx %s: %w", fee.TxHash, err)
+	}
+	return nil
+}
+
+// TxFees implements storage.FeeStorage
+func (s *Store) TxFees(ctx context.Context, since time.Time) ([]storage.TxFee, error) {
+	var fees []storage.TxFee
+	err := s.query(ctx, func(data string) error {
+		var fee storage.TxFee
+		if err := json.Unmarshal([]byte(data), &fee); err != nil {
+			return err
+		}
+		fees = append(fees, fee)
+		return nil
+	}, `SELECT record FROM tx_fees WHERE recorded_at >= ? ORDER BY height, tx_hash`, since.UnixNano())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fees: %w", err)
+	}
+	return fees, nil
+}
+
+// ClaimNonce implements storage.NonceStorage
+func (s *Store) ClaimNonce(ctx context.Context, nonce storage.Nonce) (*storage.Nonce, error) {
+	nonce.RecordedAt = s.now().UTC()
+	data, err := json.Marshal(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal nonce: %w", err)
+	}
+	err = s.exec(ctx, `INSERT INTO nonces (sender, nonce, record) VALUES (?, ?, ?) ON CONFLICT (sender, nonce) DO NOTHING`,
+		nonce.Sender, nonce.Nonce, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim nonce %s of %s: %w", nonce.Nonce, nonce.Sender, err)
+	}
+
+	var claim *storage.Nonce
+	err = s.query(ctx, func(data string) error {
+		claim = new(storage.Nonce)
+		return json.Unmarshal([]byte(data), claim)
+	}, `SELECT record FROM nonces WHERE sender = ? AND nonce = ?`, nonce.Sender, nonce.Nonce)
+	if err == nil && claim == nil {
+		err = storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nonce %s of %s: %w", nonce.Nonce, nonce.Sender, err)
+	}
+	return claim, nil
+}
+
+// query runs a query selecting one text column and calls row for each value
+func (s *Store) query(ctx context.Context, row func(string) error, query string, args ...any) error {
+	rows, err := s.db.QueryContext(ctx, s.bind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := row(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Close implements storage.Storage
+func (s *Store) Close() error {
+	return s.db.Close()
+}
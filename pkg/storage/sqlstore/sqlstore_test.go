@@ -0,0 +1,13 @@
+package sqlstore
+
+import "testing"
+
+func TestBind(t *testing.T) {
+	query := `INSERT INTO routes (tx_hash, status) VALUES (?, ?)`
+	if got := (&Store{dialect: SQLite}).bind(query); got != query {
+		t.Errorf("bind() for sqlite = %q", got)
+	}
+	if got, want := (&Store{dialect: Postgres}).bind(query), `INSERT INTO routes (tx_hash, status) VALUES ($1, $2)`; got != want {
+		t.Errorf("bind() for postgres = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// ErrNotFound is returned when a route or delivery is not in the store
+var ErrNotFound = errors.New("not found")
+
+// ErrShardLost is returned when a worker updates a shard whose claim another worker took over
+var ErrShardLost = errors.New("shard claim lost")
+
+// RouteStatus is the stage a route has reached in its lifecycle
+type RouteStatus string
+
+const (
+	RouteParsed     RouteStatus = "parsed"     // Found by parse, not yet in a transaction
+	RouteGenerated  RouteStatus = "generated"  // Included in an unsigned transaction
+	RouteDispatched RouteStatus = "dispatched" // Rebalancing transaction included and message dispatched
+	RouteDelivered  RouteStatus = "delivered"  // Message delivered on the destination chain
+	RouteFailed     RouteStatus = "failed"     // Dead-lettered or otherwise given up on
+	// Below its destination's min_amount, held until deposits to the same recipient add up to it
+	RouteAccumulating RouteStatus = "accumulating"
+)
+
+// RouteRecord is a route with its lifecycle status
+type RouteRecord struct {
+	Route     types.HyperlaneRoute `json:"route"`
+	Status    RouteStatus          `json:"status"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// Delivery tracks a dispatched Hyperlane message until it is delivered on its destination chain
+type Delivery struct {
+	types.Dispatch
+	DepositTxHash string    `json:"deposit_tx_hash"` // Transfer keys of the source deposits the message forwards
+	Delivered     bool      `json:"delivered"`
+	DeliveryTx    string    `json:"delivery_tx,omitempty"` // Destination transaction that processed the message
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Storage persists the rebalancer's state so that long-running and embedded deployments survive
+// restarts: per-source scan checkpoints, processed deposits, route lifecycle and
+// delivery records. Routes are keyed by the transfer key of their deposit (see
+// types.HyperlaneRoute.TransferKey), so each transfer of a transaction has its own, and deliveries
+// by message ID; saving an existing key replaces it.
+type Storage interface {
+	// Checkpoint returns the last height scanned for a source, or 0 if it was never scanned
+	Checkpoint(ctx context.Context, source string) (int64, error)
+	SetCheckpoint(ctx context.Context, source string, height int64) error
+
+	// MarkProcessed records that a deposit was handled, so it is not routed twice. Deposits are
+	// keyed by types.HyperlaneRoute.TransferKey, since a transaction can carry several.
+	MarkProcessed(ctx context.Context, key string, height int64) error
+	IsProcessed(ctx context.Context, key string) (bool, error)
+
+	SaveRoute(ctx context.Context, route types.HyperlaneRoute, status RouteStatus) error
+	// Route returns the record of the route of a deposit by its transfer key, or ErrNotFound
+	Route(ctx context.Context, key string) (*RouteRecord, error)
+	// RoutesByStatus returns the routes with the given status, oldest update first
+	RoutesByStatus(ctx context.Context, status RouteStatus) ([]RouteRecord, error)
+
+	SaveDelivery(ctx context.Context, delivery Delivery) error
+	// Delivery returns the delivery of a message, or ErrNotFound
+	Delivery(ctx context.Context, messageID string) (*Delivery, error)
+	// PendingDeliveries returns the deliveries not yet delivered, oldest update first
+	PendingDeliveries(ctx context.Context) ([]Delivery, error)
+
+	Close() error
+}
+
+// Shard is a height range of a backfill job, parsed by one worker at a time
+type Shard struct {
+	Job        string    `json:"job"`
+	FromHeight int64     `json:"from_height"`
+	ToHeight   int64     `json:"to_height"`
+	Progress   int64     `json:"progress"`         // Last height parsed, FromHeight-1 before the first
+	Worker     string    `json:"worker,omitempty"` // Worker holding the claim, empty if unclaimed
+	LeaseUntil time.Time `json:"lease_until"`      // When the claim expires and other workers may take the shard over
+}
+
+// Done reports whether every height of the shard was parsed
+func (s Shard) Done() bool {
+	return s.Progress >= s.ToHeight
+}
+
+// ShardStorage coordinates backfill workers sharing a store. Workers claim unfinished shards for a
+// lease they keep extending while they make progress; the shard of a worker that stops extending
+// its lease is taken over by the next worker claiming one. Shards are keyed by job and from height.
+type ShardStorage interface {
+	// AddShards adds the shards of a job, keeping shards that already exist and their progress
+	AddShards(ctx context.Context, shards []Shard) error
+	// ClaimShard claims the lowest unfinished shard of job that is unclaimed or whose lease has
+	// expired, for worker until leaseUntil. It returns ErrNotFound if no shard can be claimed.
+	ClaimShard(ctx context.Context, job, worker string, leaseUntil time.Time) (*Shard, error)
+	// UpdateShard saves the progress and lease of a shard claimed by shard.Worker. It returns
+	// ErrShardLost if another worker has claimed the shard since.
+	UpdateShard(ctx context.Context, shard Shard) error
+	// ReleaseShard saves the progress of a shard claimed by shard.Worker and gives up the claim.
+	// It returns ErrShardLost if another worker has claimed the shard since.
+	ReleaseShard(ctx context.Context, shard Shard) error
+	// Shards returns the shards of job, lowest heights first
+	Shards(ctx context.Context, job string) ([]Shard, error)
+}
+
+// HeaderStorage caches block headers across runs. Headers of committed blocks do not change, so
+// saving an existing height replaces it with the same header.
+type HeaderStorage interface {
+	SaveHeader(ctx context.Context, header types.BlockHeader) error
+	// Header returns the header at height, or ErrNotFound
+	Header(ctx context.Context, height int64) (*types.BlockHeader, error)
+}
+
+// TxFee is the fee a rebalancing transaction paid, recorded when its dispatches are tracked
+type TxFee struct {
+	TxHash     string    `json:"tx_hash"`
+	Height     int64     `json:"height"`
+	Fee        string    `json:"fee"` // Transaction fee as coins, e.g. "2000utia"
+	GasWanted  int64     `json:"gas_wanted"`
+	GasUsed    int64     `json:"gas_used"`
+	Messages   int       `json:"messages"` // Hyperlane messages the transaction dispatched
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// FeeStorage records the fees paid by rebalancing transactions, so the cost of running the
+// corridor can be reported. Fees are keyed by tx hash; saving an existing key replaces it and
+// stamps it with the time it was saved.
+type FeeStorage interface {
+	SaveTxFee(ctx context.Context, fee TxFee) error
+	// TxFees returns the fees recorded at or after since, lowest height first
+	TxFees(ctx context.Context, since time.Time) ([]TxFee, error)
+}
+
+// Nonce is a metadata nonce claimed by the first deposit of a sender to use it
+type Nonce struct {
+	Sender     string    `json:"sender"`
+	Nonce      string    `json:"nonce"`
+	TxHash     string    `json:"tx_hash"` // Deposit that claimed the nonce
+	Height     int64     `json:"height"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// NonceStorage remembers the nonces deposits carry in their metadata, so a deposit reusing a nonce
+// its sender already used can be flagged as a replay. Nonces are keyed by sender and nonce; the
+// first deposit to claim a key keeps it.
+type NonceStorage interface {
+	// ClaimNonce claims nonce for its deposit unless the sender already used it, and returns the
+	// claim that holds the key: nonce itself, or the earlier deposit's claim if this is a replay.
+	// Claiming again for the same deposit returns its own claim.
+	ClaimNonce(ctx context.Context, nonce Nonce) (*Nonce, error)
+}
@@ -0,0 +1,283 @@
+// Package storagetest checks that a storage.Storage implementation behaves as the rebalancer expects.
+// Embedders supplying their own store can run it from their tests.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Run exercises a fresh, empty store, including its shard coordination if it implements
+// storage.ShardStorage, its header cache if it implements storage.HeaderStorage, its fee records
+// if it implements storage.FeeStorage and its nonce claims if it implements storage.NonceStorage.
+// The store is closed when the test ends.
+func Run(t *testing.T, s storage.Storage) {
+	t.Helper()
+	ctx := context.Background()
+	t.Cleanup(func() { s.Close() })
+
+	t.Run("checkpoints", func(t *testing.T) {
+		if height, err := s.Checkpoint(ctx, "celestia"); err != nil || height != 0 {
+			t.Fatalf("Checkpoint() of a new source = %d, %v, want 0", height, err)
+		}
+		for _, height := range []int64{100, 250} {
+			if err := s.SetCheckpoint(ctx, "celestia", height); err != nil {
+				t.Fatalf("SetCheckpoint() error = %v", err)
+			}
+		}
+		if height, err := s.Checkpoint(ctx, "celestia"); err != nil || height != 250 {
+			t.Errorf("Checkpoint() = %d, %v, want 250", height, err)
+		}
+		if height, _ := s.Checkpoint(ctx, "neutron"); height != 0 {
+			t.Errorf("Checkpoint() of another source = %d, want 0", height)
+		}
+	})
+
+	t.Run("processed", func(t *testing.T) {
+		if ok, err := s.IsProcessed(ctx, "A1"); err != nil || ok {
+			t.Fatalf("IsProcessed() of a new tx = %v, %v", ok, err)
+		}
+		if err := s.MarkProcessed(ctx, "A1", 100); err != nil {
+			t.Fatalf("MarkProcessed() error = %v", err)
+		}
+		if err := s.MarkProcessed(ctx, "A1", 100); err != nil {
+			t.Fatalf("MarkProcessed() twice error = %v", err)
+		}
+		if ok, err := s.IsProcessed(ctx, "A1"); err != nil || !ok {
+			t.Errorf("IsProcessed() = %v, %v, want true", ok, err)
+		}
+	})
+
+	t.Run("routes", func(t *testing.T) {
+		route := func(hash string) types.HyperlaneRoute {
+			return types.HyperlaneRoute{
+				TxHash:    hash,
+				Amount:    "1000",
+				Denom:     "utia",
+				RouteInfo: &types.RouteInfo{DestinationDomain: 1, Recipient: "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0", TokenID: "0x01"},
+			}
+		}
+
+		if _, err := s.Route(ctx, "R1"); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("Route() of a missing route error = %v, want ErrNotFound", err)
+		}
+		for _, hash := range []string{"R1", "R2", "R3"} {
+			if err := s.SaveRoute(ctx, route(hash), storage.RouteParsed); err != nil {
+				t.Fatalf("SaveRoute() error = %v", err)
+			}
+		}
+		if err := s.SaveRoute(ctx, route("R2"), storage.RouteGenerated); err != nil {
+			t.Fatalf("SaveRoute() update error = %v", err)
+		}
+
+		record, err := s.Route(ctx, "R2")
+		if err != nil {
+			t.Fatalf("Route() error = %v", err)
+		}
+		if record.Status != storage.RouteGenerated || record.Route.RouteInfo == nil || record.Route.Amount != "1000" || record.UpdatedAt.IsZero() {
+			t.Errorf("Route() = %+v", record)
+		}
+
+		parsed, err := s.RoutesByStatus(ctx, storage.RouteParsed)
+		if err != nil {
+			t.Fatalf("RoutesByStatus() error = %v", err)
+		}
+		if len(parsed) != 2 || parsed[0].Route.TxHash != "R1" || parsed[1].Route.TxHash != "R3" {
+			t.Errorf("RoutesByStatus(parsed) = %+v, want R1, R3", parsed)
+		}
+		if delivered, _ := s.RoutesByStatus(ctx, storage.RouteDelivered); len(delivered) != 0 {
+			t.Errorf("RoutesByStatus(delivered) = %+v, want none", delivered)
+		}
+
+		// Each transfer of a transaction has its own record
+		second := route("R3")
+		second.MsgIndex = 1
+		if err := s.SaveRoute(ctx, second, storage.RouteGenerated); err != nil {
+			t.Fatalf("SaveRoute() of a second transfer error = %v", err)
+		}
+		if record, err := s.Route(ctx, "R3"); err != nil || record.Status != storage.RouteParsed {
+			t.Errorf("Route(R3) after saving its second transfer = %+v, %v, want parsed", record, err)
+		}
+		if record, err := s.Route(ctx, second.TransferKey()); err != nil || record.Route.MsgIndex != 1 || record.Status != storage.RouteGenerated {
+			t.Errorf("Route() of the second transfer = %+v, %v", record, err)
+		}
+	})
+
+	t.Run("deliveries", func(t *testing.T) {
+		delivery := func(id string) storage.Delivery {
+			return storage.Delivery{
+				Dispatch:      types.Dispatch{MessageID: id, Nonce: 7, Destination: 1, Amount: "1000", TxHash: "T1", Height: 500},
+				DepositTxHash: "R1",
+			}
+		}
+
+		if _, err := s.Delivery(ctx, "0xaa"); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("Delivery() of a missing message error = %v, want ErrNotFound", err)
+		}
+		for _, id := range []string{"0xaa", "0xbb"} {
+			if err := s.SaveDelivery(ctx, delivery(id)); err != nil {
+				t.Fatalf("SaveDelivery() error = %v", err)
+			}
+		}
+		delivered := delivery("0xaa")
+		delivered.Delivered = true
+		delivered.DeliveryTx = "0xdead"
+		if err := s.SaveDelivery(ctx, delivered); err != nil {
+			t.Fatalf("SaveDelivery() update error = %v", err)
+		}
+
+		got, err := s.Delivery(ctx, "0xaa")
+		if err != nil {
+			t.Fatalf("Delivery() error = %v", err)
+		}
+		if !got.Delivered || got.DeliveryTx != "0xdead" || got.Nonce != 7 || got.DepositTxHash != "R1" {
+			t.Errorf("Delivery() = %+v", got)
+		}
+
+		pending, err := s.PendingDeliveries(ctx)
+		if err != nil {
+			t.Fatalf("PendingDeliveries() error = %v", err)
+		}
+		if len(pending) != 1 || pending[0].MessageID != "0xbb" {
+			t.Errorf("PendingDeliveries() = %+v, want 0xbb", pending)
+		}
+	})
+
+	if headers, ok := s.(storage.HeaderStorage); ok {
+		t.Run("headers", func(t *testing.T) {
+			if _, err := headers.Header(ctx, 100); !errors.Is(err, storage.ErrNotFound) {
+				t.Fatalf("Header() of an unknown height error = %v, want ErrNotFound", err)
+			}
+			header := types.BlockHeader{Height: 100, Hash: "AB12", Time: time.Date(2025, 3, 1, 12, 0, 0, 500, time.UTC)}
+			if err := headers.SaveHeader(ctx, header); err != nil {
+				t.Fatalf("SaveHeader() error = %v", err)
+			}
+			got, err := headers.Header(ctx, 100)
+			if err != nil {
+				t.Fatalf("Header() error = %v", err)
+			}
+			if got.Hash != header.Hash || !got.Time.Equal(header.Time) || got.Height != 100 {
+				t.Errorf("Header() = %+v, want %+v", got, header)
+			}
+		})
+	}
+
+	if fees, ok := s.(storage.FeeStorage); ok {
+		t.Run("fees", func(t *testing.T) {
+			start := time.Now().Add(-time.Second)
+			for _, fee := range []storage.TxFee{
+				{TxHash: "FEE2", Height: 120, Fee: "3000utia", GasUsed: 150000, Messages: 2},
+				{TxHash: "FEE1", Height: 110, Fee: "1000utia", GasUsed: 90000, Messages: 1},
+				{TxHash: "FEE2", Height: 120, Fee: "2500utia", GasUsed: 150000, Messages: 2},
+			} {
+				if err := fees.SaveTxFee(ctx, fee); err != nil {
+					t.Fatalf("SaveTxFee() error = %v", err)
+				}
+			}
+			got, err := fees.TxFees(ctx, start)
+			if err != nil {
+				t.Fatalf("TxFees() error = %v", err)
+			}
+			if len(got) != 2 || got[0].TxHash != "FEE1" || got[1].Fee != "2500utia" || got[1].Messages != 2 {
+				t.Errorf("TxFees() = %+v, want FEE1 then the replaced FEE2", got)
+			}
+			if got, err := fees.TxFees(ctx, time.Now().Add(time.Hour)); err != nil || len(got) != 0 {
+				t.Errorf("TxFees() in the future = %+v, %v, want none", got, err)
+			}
+		})
+	}
+
+	if nonces, ok := s.(storage.NonceStorage); ok {
+		t.Run("nonces", func(t *testing.T) {
+			first := storage.Nonce{Sender: "celestia1alice", Nonce: "42", TxHash: "DEP1", Height: 100}
+			for _, claim := range []storage.Nonce{first, {Sender: "celestia1alice", Nonce: "42", TxHash: "DEP2", Height: 120}, first} {
+				got, err := nonces.ClaimNonce(ctx, claim)
+				if err != nil {
+					t.Fatalf("ClaimNonce() error = %v", err)
+				}
+				if got.TxHash != "DEP1" || got.Height != 100 || got.RecordedAt.IsZero() {
+					t.Errorf("ClaimNonce() of %s = %+v, want the claim of DEP1", claim.TxHash, got)
+				}
+			}
+			other := storage.Nonce{Sender: "celestia1bob", Nonce: "42", TxHash: "DEP3", Height: 130}
+			if got, err := nonces.ClaimNonce(ctx, other); err != nil || got.TxHash != "DEP3" {
+				t.Errorf("ClaimNonce() of another sender's nonce = %+v, %v, want its own claim", got, err)
+			}
+		})
+	}
+
+	shards, ok := s.(storage.ShardStorage)
+	if !ok {
+		return
+	}
+	t.Run("shards", func(t *testing.T) {
+		lease := time.Now().Add(time.Hour)
+		plan := []storage.Shard{
+			{Job: "backfill", FromHeight: 1, ToHeight: 10, Progress: 0},
+			{Job: "backfill", FromHeight: 11, ToHeight: 20, Progress: 10},
+		}
+		if err := shards.AddShards(ctx, plan); err != nil {
+			t.Fatalf("AddShards() error = %v", err)
+		}
+		replanned := plan[0]
+		replanned.Progress = 5
+		if err := shards.AddShards(ctx, []storage.Shard{replanned}); err != nil {
+			t.Fatalf("AddShards() of an existing shard error = %v", err)
+		}
+
+		first, err := shards.ClaimShard(ctx, "backfill", "w1", lease)
+		if err != nil || first.FromHeight != 1 || first.Progress != 0 || first.Worker != "w1" {
+			t.Fatalf("ClaimShard() = %+v, %v, want the first shard unchanged", first, err)
+		}
+		second, err := shards.ClaimShard(ctx, "backfill", "w2", lease)
+		if err != nil || second.FromHeight != 11 {
+			t.Fatalf("ClaimShard() = %+v, %v, want the second shard", second, err)
+		}
+		if _, err := shards.ClaimShard(ctx, "backfill", "w3", lease); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("ClaimShard() with every shard claimed error = %v, want ErrNotFound", err)
+		}
+
+		first.Progress = 5
+		if err := shards.UpdateShard(ctx, *first); err != nil {
+			t.Fatalf("UpdateShard() error = %v", err)
+		}
+		stolen := *first
+		stolen.Worker = "w3"
+		if err := shards.UpdateShard(ctx, stolen); !errors.Is(err, storage.ErrShardLost) {
+			t.Errorf("UpdateShard() by another worker error = %v, want ErrShardLost", err)
+		}
+		first.Progress = 10
+		if err := shards.ReleaseShard(ctx, *first); err != nil {
+			t.Fatalf("ReleaseShard() error = %v", err)
+		}
+		if _, err := shards.ClaimShard(ctx, "backfill", "w3", lease); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("ClaimShard() of a done shard error = %v, want ErrNotFound", err)
+		}
+
+		// A shard whose lease expired is taken over
+		second.LeaseUntil = time.Now().Add(-time.Minute)
+		if err := shards.UpdateShard(ctx, *second); err != nil {
+			t.Fatalf("UpdateShard() error = %v", err)
+		}
+		takeover, err := shards.ClaimShard(ctx, "backfill", "w3", lease)
+		if err != nil || takeover.FromHeight != 11 || takeover.Worker != "w3" {
+			t.Fatalf("ClaimShard() = %+v, %v, want the expired shard", takeover, err)
+		}
+		if err := shards.UpdateShard(ctx, *second); !errors.Is(err, storage.ErrShardLost) {
+			t.Errorf("UpdateShard() after a takeover error = %v, want ErrShardLost", err)
+		}
+
+		all, err := shards.Shards(ctx, "backfill")
+		if err != nil {
+			t.Fatalf("Shards() error = %v", err)
+		}
+		if len(all) != 2 || !all[0].Done() || all[0].Worker != "" || all[1].Worker != "w3" || all[1].Done() {
+			t.Errorf("Shards() = %+v", all)
+		}
+	})
+}
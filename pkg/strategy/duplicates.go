@@ -0,0 +1,94 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Duplicate is a group of deposits with the same sender, amount, destination and recipient made
+// within the duplicate window of each other, which may be an accidental double-send
+type Duplicate struct {
+	From              string   `json:"from"`
+	Amount            string   `json:"amount"`
+	Denom             string   `json:"denom"`
+	DestinationDomain uint32   `json:"destination_domain"`
+	Recipient         string   `json:"recipient"`
+	TxHashes          []string `json:"tx_hashes"`
+	Heights           []int64  `json:"heights"`
+}
+
+// String describes the group for operator review
+func (d Duplicate) String() string {
+	return fmt.Sprintf("%d deposits of %s%s from %s to %s on domain %d at heights %v: %s",
+		len(d.TxHashes), d.Amount, d.Denom, d.From, d.Recipient, d.DestinationDomain, d.Heights, strings.Join(d.TxHashes, ", "))
+}
+
+// FindDuplicates groups routes whose deposits share sender, amount, denom, destination and recipient
+// and follow each other within window blocks. Routes without route info are ignored. A window of
+// zero disables the check.
+func FindDuplicates(routes []types.HyperlaneRoute, window int64) []Duplicate {
+	if window <= 0 {
+		return nil
+	}
+
+	type key struct {
+		from, amount, denom, recipient string
+		destination                    uint32
+	}
+	var order []key
+	byKey := make(map[key][]types.HyperlaneRoute)
+	for _, route := range routes {
+		if route.RouteInfo == nil {
+			continue
+		}
+		k := key{
+			from:        route.From,
+			amount:      route.Amount,
+			denom:       route.Denom,
+			recipient:   strings.ToLower(route.RouteInfo.Recipient),
+			destination: route.RouteInfo.DestinationDomain,
+		}
+		if _, ok := byKey[k]; !ok {
+			order = append(order, k)
+		}
+		byKey[k] = append(byKey[k], route)
+	}
+
+	var duplicates []Duplicate
+	for _, k := range order {
+		group := byKey[k]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].BlockHeight < group[j].BlockHeight })
+
+		// Deposits chain into one group while each follows the previous within the window
+		var current []types.HyperlaneRoute
+		flush := func() {
+			if len(current) < 2 {
+				return
+			}
+			d := Duplicate{
+				From:              current[0].From,
+				Amount:            current[0].Amount,
+				Denom:             current[0].Denom,
+				DestinationDomain: k.destination,
+				Recipient:         current[0].RouteInfo.Recipient,
+			}
+			for _, route := range current {
+				d.TxHashes = append(d.TxHashes, route.TxHash)
+				d.Heights = append(d.Heights, route.BlockHeight)
+			}
+			duplicates = append(duplicates, d)
+		}
+		for _, route := range group {
+			if len(current) > 0 && route.BlockHeight-current[len(current)-1].BlockHeight > window {
+				flush()
+				current = nil
+			}
+			current = append(current, route)
+		}
+		flush()
+	}
+	return duplicates
+}
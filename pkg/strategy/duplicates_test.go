@@ -0,0 +1,46 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	deposit := func(hash string, height int64, from, amount, recipient string) types.HyperlaneRoute {
+		return types.HyperlaneRoute{
+			TxHash:      hash,
+			BlockHeight: height,
+			From:        from,
+			Amount:      amount,
+			Denom:       "utia",
+			RouteInfo:   &types.RouteInfo{DestinationDomain: 1, Recipient: recipient, TokenID: "0x01"},
+		}
+	}
+
+	routes := []types.HyperlaneRoute{
+		deposit("A", 100, "alice", "1000", "0xabc"),
+		deposit("B", 150, "alice", "1000", "0xABC"), // same recipient, different case
+		deposit("C", 180, "alice", "1000", "0xabc"), // chained to B
+		deposit("D", 500, "alice", "1000", "0xabc"), // outside the window
+		deposit("E", 120, "bob", "1000", "0xabc"),   // different sender
+		deposit("F", 130, "alice", "2000", "0xabc"), // different amount
+		{TxHash: "G", BlockHeight: 110, From: "alice", Amount: "1000", Denom: "utia"},
+	}
+
+	duplicates := FindDuplicates(routes, 100)
+	if len(duplicates) != 1 {
+		t.Fatalf("FindDuplicates() = %+v, want 1 group", duplicates)
+	}
+	d := duplicates[0]
+	if len(d.TxHashes) != 3 || d.TxHashes[0] != "A" || d.TxHashes[2] != "C" || d.Heights[1] != 150 {
+		t.Errorf("group = %+v, want A, B, C", d)
+	}
+
+	if got := FindDuplicates(routes, 10); len(got) != 0 {
+		t.Errorf("FindDuplicates() with a 10 block window = %+v, want none", got)
+	}
+	if got := FindDuplicates(routes, 0); got != nil {
+		t.Errorf("FindDuplicates() with the check disabled = %+v", got)
+	}
+}
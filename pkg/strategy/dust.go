@@ -0,0 +1,124 @@
+package strategy
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Dust is the outcome of HoldDust
+type Dust struct {
+	Kept     []types.HyperlaneRoute // Routes to generate
+	Held     []types.HyperlaneRoute // Dust routes held back
+	Released []types.HyperlaneRoute // Pending routes merged into a kept route
+	Merged   int                    // Dust routes, pending or not, merged into kept routes
+}
+
+// HoldDust holds back routes below the min_amount of their destination, given by domain in
+// minAmounts, until deposits to the same recipient, token and denom add up to it. Dust routes are
+// grouped with pending, the dust held in earlier runs; a group reaching its minimum is merged into
+// one route as Aggregate does and kept in place of its first route, while the dust of the other
+// groups is held. Routes at or above their minimum and fan-out routes are kept as they are.
+// Pending routes also in routes are ignored, and pending routes to domains that no longer have a
+// minimum are released.
+func HoldDust(routes, pending []types.HyperlaneRoute, minAmounts map[uint32]math.Int) (*Dust, error) {
+	var kept, held, released []types.HyperlaneRoute
+	merged := 0
+	present := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		present[route.TransferKey()] = true
+	}
+
+	// Buckets of dust by aggregation key, in the order they first appear. An empty route in kept
+	// holds the place of a bucket's merged route.
+	type bucket struct {
+		pending, dust []types.HyperlaneRoute
+		position      int
+	}
+	buckets := make(map[string]*bucket)
+	var keys []string
+	add := func(route types.HyperlaneRoute) *bucket {
+		key := aggregationKey(route)
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{position: -1}
+			buckets[key] = b
+			keys = append(keys, key)
+		}
+		return b
+	}
+
+	for _, route := range routes {
+		if route.RouteInfo == nil || len(route.RouteInfo.Splits) > 0 {
+			kept = append(kept, route)
+			continue
+		}
+		min, ok := minAmounts[route.RouteInfo.DestinationDomain]
+		if !ok || min.IsNil() {
+			kept = append(kept, route)
+			continue
+		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+		if amount.GTE(min) {
+			kept = append(kept, route)
+			continue
+		}
+		b := add(route)
+		if b.position < 0 {
+			b.position = len(kept)
+			kept = append(kept, types.HyperlaneRoute{})
+		}
+		b.dust = append(b.dust, route)
+	}
+	for _, route := range pending {
+		if present[route.TransferKey()] || route.RouteInfo == nil {
+			continue
+		}
+		b := add(route)
+		b.pending = append(b.pending, route)
+	}
+
+	var appended []types.HyperlaneRoute
+	for _, key := range keys {
+		b := buckets[key]
+		members := append(append([]types.HyperlaneRoute(nil), b.pending...), b.dust...)
+		total := math.ZeroInt()
+		for _, route := range members {
+			amount, ok := math.NewIntFromString(route.Amount)
+			if !ok {
+				return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+			}
+			total = total.Add(amount)
+		}
+
+		min := minAmounts[members[0].RouteInfo.DestinationDomain]
+		if !min.IsNil() && total.LT(min) {
+			held = append(held, b.dust...)
+			continue
+		}
+		aggregated, err := Aggregate(members)
+		if err != nil {
+			return nil, err
+		}
+		released = append(released, b.pending...)
+		merged += len(members)
+		if b.position >= 0 {
+			kept[b.position] = aggregated[0]
+		} else {
+			appended = append(appended, aggregated[0])
+		}
+	}
+
+	// Drop the places of the buckets that stay held
+	result := kept[:0]
+	for _, route := range kept {
+		if route.TxHash != "" {
+			result = append(result, route)
+		}
+	}
+	return &Dust{Kept: append(result, appended...), Held: held, Released: released, Merged: merged}, nil
+}
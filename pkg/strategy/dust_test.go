@@ -0,0 +1,66 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestHoldDust(t *testing.T) {
+	minAmounts := map[uint32]math.Int{2: math.NewInt(100)}
+	routes := []types.HyperlaneRoute{
+		route("A1", 2, "40"),
+		route("A2", 3, "10"),
+		route("A3", 2, "150"),
+		route("A4", 2, "30"),
+	}
+
+	// Dust alone stays held until it adds up to the minimum
+	dust, err := HoldDust(routes, nil, minAmounts)
+	if err != nil {
+		t.Fatalf("HoldDust() error = %v", err)
+	}
+	if got := hashes(dust.Kept); got != "A2 A3" {
+		t.Errorf("kept %s, want A2 A3", got)
+	}
+	if got := hashes(dust.Held); got != "A1 A4" || len(dust.Released) != 0 || dust.Merged != 0 {
+		t.Errorf("held %s, released %d and merged %d, want A1 A4 and none", got, len(dust.Released), dust.Merged)
+	}
+
+	// With the dust of earlier runs the bucket reaches the minimum and is merged in place of A1;
+	// a pending route also in routes is not counted twice
+	pending := []types.HyperlaneRoute{route("P1", 2, "30"), route("A4", 2, "30")}
+	dust, err = HoldDust(routes, pending, minAmounts)
+	if err != nil {
+		t.Fatalf("HoldDust() error = %v", err)
+	}
+	if got := hashes(dust.Kept); got != "P1,A1,A4 A2 A3" {
+		t.Errorf("kept %s, want P1,A1,A4 A2 A3", got)
+	}
+	if dust.Kept[0].Amount != "100" || len(dust.Kept[0].Provenance) != 3 || dust.Merged != 3 {
+		t.Errorf("merged route = %s with %d deposits (%d merged), want 100 from 3", dust.Kept[0].Amount, len(dust.Kept[0].Provenance), dust.Merged)
+	}
+	if len(dust.Held) != 0 || hashes(dust.Released) != "P1" {
+		t.Errorf("held %d and released %s, want none and P1", len(dust.Held), hashes(dust.Released))
+	}
+
+	// Pending dust to a domain without a minimum any more is released
+	dust, err = HoldDust(nil, []types.HyperlaneRoute{route("P2", 5, "10")}, minAmounts)
+	if err != nil {
+		t.Fatalf("HoldDust() error = %v", err)
+	}
+	if hashes(dust.Kept) != "P2" || hashes(dust.Released) != "P2" {
+		t.Errorf("kept %s and released %s, want P2 for both", hashes(dust.Kept), hashes(dust.Released))
+	}
+}
+
+// hashes lists the tx hashes of routes separated by spaces
+func hashes(routes []types.HyperlaneRoute) string {
+	var list []string
+	for _, r := range routes {
+		list = append(list, r.TxHash)
+	}
+	return strings.Join(list, " ")
+}
@@ -0,0 +1,75 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// DomainGasEstimate is the estimated interchain gas spend for the transfers to one domain
+type DomainGasEstimate struct {
+	Domain    uint32         `json:"domain"`
+	Transfers int            `json:"transfers"`
+	Gas       uint64         `json:"gas"`                // Destination gas for all transfers
+	Cost      math.Int       `json:"cost"`               // In the smallest unit of the destination gas token
+	USD       math.LegacyDec `json:"usd,omitempty"`      // Nil if the gas token has no configured price
+	Unpriced  bool           `json:"unpriced,omitempty"` // The domain has no gas settings
+}
+
+// GasEstimate is the estimated interchain gas spend for a route set
+type GasEstimate struct {
+	Domains  []DomainGasEstimate `json:"domains"`
+	TotalUSD math.LegacyDec      `json:"total_usd"` // Sum over the domains with a USD price
+}
+
+// EstimateGas estimates the interchain gas spend of one transfer per route, using the per-domain
+// gas settings. Domains without gas settings are reported as unpriced.
+func EstimateGas(routes *types.Routes, destinations map[uint32]types.DestinationConfig) (*GasEstimate, error) {
+	transfers := make(map[uint32]int)
+	for _, route := range routes.Routes {
+		if route.RouteInfo == nil {
+			return nil, fmt.Errorf("route from tx %s has no routing info", route.TxHash)
+		}
+		transfers[route.RouteInfo.DestinationDomain]++
+	}
+
+	estimate := &GasEstimate{TotalUSD: math.LegacyZeroDec()}
+	for domain, count := range transfers {
+		d := DomainGasEstimate{Domain: domain, Transfers: count, Cost: math.ZeroInt()}
+
+		dest := destinations[domain]
+		if dest.GasPerTransfer == 0 || dest.GasPrice == "" {
+			d.Unpriced = true
+			estimate.Domains = append(estimate.Domains, d)
+			continue
+		}
+
+		price, ok := math.NewIntFromString(dest.GasPrice)
+		if !ok {
+			return nil, fmt.Errorf("invalid gas_price %s for domain %d", dest.GasPrice, domain)
+		}
+		d.Gas = dest.GasPerTransfer * uint64(count)
+		d.Cost = price.Mul(math.NewIntFromUint64(d.Gas))
+
+		if dest.GasTokenPriceUSD != "" {
+			tokenPrice, err := math.LegacyNewDecFromStr(dest.GasTokenPriceUSD)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gas_token_price_usd %s for domain %d", dest.GasTokenPriceUSD, domain)
+			}
+			decimals := dest.GasTokenDecimals
+			if decimals == 0 {
+				decimals = types.DefaultGasTokenDecimals
+			}
+			unit := math.LegacyNewDecFromInt(math.NewIntWithDecimal(1, int(decimals)))
+			d.USD = math.LegacyNewDecFromInt(d.Cost).Quo(unit).Mul(tokenPrice)
+			estimate.TotalUSD = estimate.TotalUSD.Add(d.USD)
+		}
+
+		estimate.Domains = append(estimate.Domains, d)
+	}
+
+	sort.Slice(estimate.Domains, func(i, j int) bool { return estimate.Domains[i].Domain < estimate.Domains[j].Domain })
+	return estimate, nil
+}
@@ -0,0 +1,53 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestEstimateGas(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{
+		route("A1", 1, "100"),
+		route("A2", 1, "50"),
+		route("A3", 2, "30"),
+	}}
+	destinations := map[uint32]types.DestinationConfig{
+		1: {GasPerTransfer: 100000, GasPrice: "20000000000", GasTokenPriceUSD: "3000"},
+	}
+
+	estimate, err := EstimateGas(routes, destinations)
+	if err != nil {
+		t.Fatalf("EstimateGas() error = %v", err)
+	}
+	if len(estimate.Domains) != 2 {
+		t.Fatalf("estimated %d domains, want 2", len(estimate.Domains))
+	}
+
+	priced := estimate.Domains[0]
+	if priced.Domain != 1 || priced.Transfers != 2 || priced.Gas != 200000 {
+		t.Errorf("domain 1 = %+v, want 2 transfers using 200000 gas", priced)
+	}
+	if priced.Cost.String() != "4000000000000000" {
+		t.Errorf("cost = %s, want 4000000000000000", priced.Cost)
+	}
+	if !estimate.TotalUSD.Equal(priced.USD) || priced.USD.String() != "12.000000000000000000" {
+		t.Errorf("usd = %s, total = %s, want 12", priced.USD, estimate.TotalUSD)
+	}
+	if !estimate.Domains[1].Unpriced {
+		t.Errorf("domain 2 = %+v, want unpriced", estimate.Domains[1])
+	}
+
+	// Aggregating the two transfers to domain 1 halves its gas spend
+	aggregated, err := Aggregate(routes.Routes)
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	estimate, err = EstimateGas(&types.Routes{Routes: aggregated}, destinations)
+	if err != nil {
+		t.Fatalf("EstimateGas() error = %v", err)
+	}
+	if estimate.TotalUSD.String() != "6.000000000000000000" {
+		t.Errorf("aggregated total = %s, want 6", estimate.TotalUSD)
+	}
+}
@@ -0,0 +1,224 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// RouteContext is the chain context route hooks receive with every route
+type RouteContext struct {
+	MultisigAddr string
+	Chain        types.ChainConfig
+	Height       int64     // Latest height of the chain; zero when it was not queried
+	Time         time.Time // Time of the run
+}
+
+// Decision is a route hook's verdict on a route. The zero Decision accepts the route unchanged.
+type Decision struct {
+	Reject      string            // Reason to hold the route back; empty to accept it
+	Amount      string            // Lower amount to route instead; empty to keep the route's amount
+	Annotations map[string]string // Notes to record on the route
+}
+
+// RouteHook reviews every route before generation. Embedders implement it to plug their own
+// acceptance rules in front of the generator; NewRouteHooks builds the built-ins of the config.
+// A hook may reject a route, lower its amount or annotate it, but never raise its amount.
+type RouteHook interface {
+	Name() string
+	Review(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error)
+}
+
+// RouteHookFunc adapts a function to a RouteHook
+type RouteHookFunc func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error)
+
+// namedHook is a RouteHookFunc with the name it is reported under
+type namedHook struct {
+	name string
+	fn   RouteHookFunc
+}
+
+func (h namedHook) Name() string { return h.name }
+
+func (h namedHook) Review(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+	return h.fn(ctx, route, rc)
+}
+
+// NewRouteHook returns a RouteHook named name that reviews routes with fn
+func NewRouteHook(name string, fn RouteHookFunc) RouteHook {
+	return namedHook{name: name, fn: fn}
+}
+
+// Rejection is a route held back by a route hook
+type Rejection struct {
+	Route  types.HyperlaneRoute `json:"route"`
+	Hook   string               `json:"hook"`
+	Reason string               `json:"reason"`
+}
+
+// HookResult is the outcome of running route hooks over a route set
+type HookResult struct {
+	Routes    *types.Routes `json:"routes"`             // Routes accepted by every hook
+	Rejected  []Rejection   `json:"rejected,omitempty"` // Routes held back, with the hook that rejected them
+	Modified  int           `json:"modified"`           // Number of routes whose amount was lowered
+	Annotated int           `json:"annotated"`          // Number of routes annotated
+}
+
+// Changed reports whether the hooks altered the route set
+func (r *HookResult) Changed() bool {
+	return len(r.Rejected) > 0 || r.Modified > 0 || r.Annotated > 0
+}
+
+// ApplyHooks runs hooks over every route, in order, without modifying routes. A rejected route is
+// not shown to later hooks. A route whose amount is lowered records its original amount in the
+// "original_amount" annotation. A hook error, or an amount that is not a positive integer below the
+// route's, fails the run.
+func ApplyHooks(ctx context.Context, routes *types.Routes, hooks []RouteHook, rc RouteContext) (*HookResult, error) {
+	result := &HookResult{Routes: &types.Routes{MultisigAddr: routes.MultisigAddr}}
+	if rc.MultisigAddr == "" {
+		rc.MultisigAddr = routes.MultisigAddr
+	}
+
+	for _, route := range routes.Routes {
+		modified, annotated, rejected := false, false, false
+		for _, hook := range hooks {
+			decision, err := hook.Review(ctx, route, rc)
+			if err != nil {
+				return nil, fmt.Errorf("route hook %s on tx %s: %w", hook.Name(), route.TxHash, err)
+			}
+			if decision.Reject != "" {
+				result.Rejected = append(result.Rejected, Rejection{Route: route, Hook: hook.Name(), Reason: decision.Reject})
+				rejected = true
+				break
+			}
+			if decision.Amount != "" && decision.Amount != route.Amount {
+				if err := lowerAmount(&route, decision.Amount); err != nil {
+					return nil, fmt.Errorf("route hook %s on tx %s: %w", hook.Name(), route.TxHash, err)
+				}
+				modified = true
+			}
+			if len(decision.Annotations) > 0 {
+				route.Annotations = maps.Clone(route.Annotations)
+				if route.Annotations == nil {
+					route.Annotations = make(map[string]string, len(decision.Annotations))
+				}
+				maps.Copy(route.Annotations, decision.Annotations)
+				annotated = true
+			}
+		}
+		if rejected {
+			continue
+		}
+		if modified {
+			result.Modified++
+		}
+		if annotated {
+			result.Annotated++
+		}
+		result.Routes.Routes = append(result.Routes.Routes, route)
+	}
+
+	RecomputeTotal(result.Routes)
+	return result, nil
+}
+
+// lowerAmount sets the amount of route to amount, which must be positive and below the current
+// amount, and records the original amount the first time
+func lowerAmount(route *types.HyperlaneRoute, amount string) error {
+	current, ok := math.NewIntFromString(route.Amount)
+	if !ok {
+		return fmt.Errorf("invalid amount %s in route", route.Amount)
+	}
+	lowered, ok := math.NewIntFromString(amount)
+	if !ok || !lowered.IsPositive() {
+		return fmt.Errorf("invalid amount %q, must be a positive integer", amount)
+	}
+	if lowered.GT(current) {
+		return fmt.Errorf("amount %s exceeds the route's amount of %s, hooks may only lower it", amount, route.Amount)
+	}
+	if route.RouteInfo != nil && len(route.RouteInfo.Splits) > 0 {
+		return fmt.Errorf("the amount of a fan-out route cannot be changed")
+	}
+
+	if route.Annotations["original_amount"] == "" {
+		route.Annotations = maps.Clone(route.Annotations)
+		if route.Annotations == nil {
+			route.Annotations = make(map[string]string)
+		}
+		route.Annotations["original_amount"] = route.Amount
+	}
+	setRouteAmount(route, amount)
+	return nil
+}
+
+// NewRouteHooks builds the built-in route hooks configured in the policy, in order
+func NewRouteHooks(configs []types.RouteHookConfig) ([]RouteHook, error) {
+	var hooks []RouteHook
+	for i, config := range configs {
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("route_hooks[%d]: %w", i, err)
+		}
+		hooks = append(hooks, NewRouteHook(config.HookName(), builtinHook(config)))
+	}
+	return hooks, nil
+}
+
+// builtinHook returns the review function of a validated built-in hook
+func builtinHook(config types.RouteHookConfig) RouteHookFunc {
+	switch config.Type {
+	case types.HookMinAmount:
+		minimum, _ := config.Threshold()
+		return func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+			amount, ok := math.NewIntFromString(route.Amount)
+			if !ok {
+				return Decision{}, fmt.Errorf("invalid amount %s in route", route.Amount)
+			}
+			if amount.LT(minimum) {
+				return Decision{Reject: fmt.Sprintf("amount %s is below the minimum of %s", route.Amount, minimum)}, nil
+			}
+			return Decision{}, nil
+		}
+	case types.HookCapAmount:
+		maximum, _ := config.Threshold()
+		return func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+			amount, ok := math.NewIntFromString(route.Amount)
+			if !ok {
+				return Decision{}, fmt.Errorf("invalid amount %s in route", route.Amount)
+			}
+			if amount.GT(maximum) {
+				return Decision{Amount: maximum.String()}, nil
+			}
+			return Decision{}, nil
+		}
+	case types.HookDenySenders:
+		denied := make(map[string]bool, len(config.Senders))
+		for _, sender := range config.Senders {
+			denied[strings.ToLower(sender)] = true
+		}
+		return func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+			if denied[strings.ToLower(route.From)] {
+				return Decision{Reject: fmt.Sprintf("sender %s is denied", route.From)}, nil
+			}
+			return Decision{}, nil
+		}
+	case types.HookMaxAgeBlocks:
+		return func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+			if rc.Height == 0 {
+				return Decision{}, fmt.Errorf("%s needs the chain's latest height", types.HookMaxAgeBlocks)
+			}
+			if age := rc.Height - route.BlockHeight; age > config.Blocks {
+				return Decision{Reject: fmt.Sprintf("deposit is %d blocks old, more than %d", age, config.Blocks)}, nil
+			}
+			return Decision{}, nil
+		}
+	default:
+		return func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+			return Decision{Annotations: config.Annotations}, nil
+		}
+	}
+}
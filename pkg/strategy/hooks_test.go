@@ -0,0 +1,114 @@
+package strategy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestApplyHooks(t *testing.T) {
+	dust := route("A1", 2, "5")
+	large := route("A2", 2, "500")
+	denied := route("A3", 2, "100")
+	denied.From = "Celestia1Denied"
+	old := route("A4", 2, "100")
+	old.BlockHeight = 10
+	routes := &types.Routes{MultisigAddr: "celestia1multisig", Routes: []types.HyperlaneRoute{dust, large, denied, old}}
+	for i := range routes.Routes[:3] {
+		routes.Routes[i].BlockHeight = 90
+	}
+
+	hooks, err := NewRouteHooks([]types.RouteHookConfig{
+		{Type: types.HookMinAmount, Amount: "10"},
+		{Type: types.HookCapAmount, Amount: "200"},
+		{Type: types.HookDenySenders, Name: "sanctions", Senders: []string{"celestia1denied"}},
+		{Type: types.HookMaxAgeBlocks, Blocks: 50},
+		{Type: types.HookAnnotate, Annotations: map[string]string{"ticket": "OPS-1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ApplyHooks(context.Background(), routes, hooks, RouteContext{Height: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Rejected) != 3 {
+		t.Fatalf("expected 3 rejected routes, got %+v", result.Rejected)
+	}
+	for i, want := range []string{"min_amount", "sanctions", "max_age_blocks"} {
+		if result.Rejected[i].Hook != want {
+			t.Errorf("rejection %d: expected hook %s, got %s", i, want, result.Rejected[i].Hook)
+		}
+	}
+
+	if len(result.Routes.Routes) != 1 || result.Modified != 1 || result.Annotated != 1 {
+		t.Fatalf("expected 1 modified and annotated route, got %+v", result)
+	}
+	capped := result.Routes.Routes[0]
+	if capped.Amount != "200" || capped.Annotations["original_amount"] != "500" || capped.Annotations["ticket"] != "OPS-1" {
+		t.Errorf("unexpected capped route %+v", capped)
+	}
+	if result.Routes.TotalAmount != "200" {
+		t.Errorf("expected total 200, got %s", result.Routes.TotalAmount)
+	}
+	if routes.Routes[1].Amount != "500" || routes.Routes[1].Annotations != nil {
+		t.Error("ApplyHooks() modified the input routes")
+	}
+}
+
+func TestApplyHooksErrors(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route("A1", 2, "100")}}
+
+	tests := []struct {
+		name string
+		hook RouteHook
+		rc   RouteContext
+		want string
+	}{
+		{
+			name: "raised amount",
+			hook: NewRouteHook("raise", func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+				return Decision{Amount: "101"}, nil
+			}),
+			want: "may only lower it",
+		},
+		{
+			name: "invalid amount",
+			hook: NewRouteHook("zero", func(ctx context.Context, route types.HyperlaneRoute, rc RouteContext) (Decision, error) {
+				return Decision{Amount: "0"}, nil
+			}),
+			want: "positive integer",
+		},
+		{
+			name: "unknown height",
+			hook: mustHook(t, types.RouteHookConfig{Type: types.HookMaxAgeBlocks, Blocks: 10}),
+			want: "latest height",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyHooks(context.Background(), routes, []RouteHook{tt.hook}, tt.rc)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := NewRouteHooks([]types.RouteHookConfig{{Type: "unknown"}}); err == nil {
+		t.Error("expected an error for an unknown hook type")
+	}
+}
+
+func mustHook(t *testing.T, config types.RouteHookConfig) RouteHook {
+	t.Helper()
+	hooks, err := NewRouteHooks([]types.RouteHookConfig{config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hooks[0]
+}
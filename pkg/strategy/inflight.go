@@ -0,0 +1,39 @@
+package strategy
+
+import "github.com/celestiaorg/celestia-rebalancer/pkg/types"
+
+// HoldInFlight splits routes into those that fit under the in-flight limit of their destination
+// domain and those held until deliveries confirm. limits caps the undelivered transfers per
+// domain, domains without a limit are unlimited; inFlight counts the transfers already awaiting
+// delivery. A fan-out route counts one transfer per split recipient. Once a route to a domain is
+// held, the later routes to it are held too, so deposits are forwarded in order.
+func HoldInFlight(routes []types.HyperlaneRoute, limits, inFlight map[uint32]int) (kept, held []types.HyperlaneRoute) {
+	used := make(map[uint32]int, len(inFlight))
+	for domain, count := range inFlight {
+		used[domain] = count
+	}
+	full := make(map[uint32]bool)
+
+	for _, route := range routes {
+		if route.RouteInfo == nil {
+			kept = append(kept, route)
+			continue
+		}
+		domain := route.RouteInfo.DestinationDomain
+		limit, limited := limits[domain]
+		if !limited || limit <= 0 {
+			kept = append(kept, route)
+			continue
+		}
+
+		transfers := max(1, len(route.RouteInfo.Splits))
+		if full[domain] || used[domain]+transfers > limit {
+			full[domain] = true
+			held = append(held, route)
+			continue
+		}
+		used[domain] += transfers
+		kept = append(kept, route)
+	}
+	return kept, held
+}
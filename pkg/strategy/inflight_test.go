@@ -0,0 +1,44 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestHoldInFlight(t *testing.T) {
+	fanOut := route("A3", 2, "90")
+	fanOut.RouteInfo.Splits = []types.RouteSplit{{Percent: "50"}, {Percent: "50"}}
+
+	routes := []types.HyperlaneRoute{
+		route("A1", 2, "100"),
+		route("A2", 3, "50"),
+		fanOut,
+		route("A4", 2, "10"),
+		route("A5", 3, "20"),
+	}
+
+	// Domain 2 has one transfer in flight and room for two more; domain 3 has no limit
+	kept, held := HoldInFlight(routes, map[uint32]int{2: 3}, map[uint32]int{2: 1, 3: 7})
+
+	var keptHashes, heldHashes []string
+	for _, r := range kept {
+		keptHashes = append(keptHashes, r.TxHash)
+	}
+	for _, r := range held {
+		heldHashes = append(heldHashes, r.TxHash)
+	}
+	// The fan-out needs two slots, so it is held, and A4 after it although it would fit
+	if len(kept) != 3 || kept[0].TxHash != "A1" || kept[1].TxHash != "A2" || kept[2].TxHash != "A5" {
+		t.Errorf("kept = %v, want A1, A2 and A5", keptHashes)
+	}
+	if len(held) != 2 || held[0].TxHash != "A3" || held[1].TxHash != "A4" {
+		t.Errorf("held = %v, want A3 and A4", heldHashes)
+	}
+
+	// A domain at its limit holds every route to it
+	kept, held = HoldInFlight(routes, map[uint32]int{3: 2}, map[uint32]int{3: 2})
+	if len(kept) != 3 || len(held) != 2 {
+		t.Errorf("kept %d and held %d routes, want the 2 routes to domain 3 held", len(kept), len(held))
+	}
+}
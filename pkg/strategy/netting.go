@@ -0,0 +1,229 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// NettingResult describes the offset applied between two opposing corridors of the same asset
+type NettingResult struct {
+	DomainA   uint32   `json:"domain_a"`
+	DomainB   uint32   `json:"domain_b"`
+	WarpRoute string   `json:"warp_route"`    // Name of the warp route both corridors transfer over
+	AmountAB  string   `json:"amount_a_to_b"` // Total routed from A to B before netting
+	AmountBA  string   `json:"amount_b_to_a"` // Total routed from B to A before netting
+	Offset    string   `json:"offset"`        // Amount cancelled in both directions
+	NetAmount string   `json:"net_amount"`    // Amount still transferred after netting
+	NetFrom   uint32   `json:"net_from"`      // Origin domain of the remaining net transfer (0 if fully offset)
+	NettedTxs []string `json:"netted_txs"`    // Source tx hashes whose routes were fully or partially offset
+}
+
+// corridor identifies the routes of a route set netted together: those to the same destination
+// domain over the same warp route. Token IDs are local to each chain, so the warp route is what
+// matches a corridor with its reverse. Flows of different assets are never offset against each
+// other, as that would cancel unrelated transfers and leave their recipients unpaid.
+type corridor struct {
+	destination uint32
+	warpRoute   string
+}
+
+// NetCorridors cancels offsetting amounts between opposing corridors. routeSets maps the Hyperlane
+// domain of each source chain to the routes parsed on it. For every pair of domains A and B where
+// routes on A go to B and routes on B go to A over the same warp route, the smaller total is
+// offset against the larger one, so only the net difference is generated as transfers. Routes are
+// consumed smallest-first; a route that is only partially offset keeps its remaining amount.
+// Routes whose token on their source domain is not part of a configured warp route are never netted.
+//
+// The route sets are modified in place and their totals recomputed.
+func NetCorridors(routeSets map[uint32]*types.Routes, warpRoutes types.WarpRouteIndex) ([]NettingResult, error) {
+	domains := make([]uint32, 0, len(routeSets))
+	for domain := range routeSets {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i] < domains[j] })
+
+	var results []NettingResult
+	for i, a := range domains {
+		for _, b := range domains[i+1:] {
+			assets, err := corridorAssets(routeSets[a], a, b, warpRoutes)
+			if err != nil {
+				return nil, err
+			}
+			for _, asset := range assets {
+				ab, err := corridorTotal(routeSets[a], a, asset, warpRoutes)
+				if err != nil {
+					return nil, err
+				}
+				reverse := corridor{destination: a, warpRoute: asset.warpRoute}
+				ba, err := corridorTotal(routeSets[b], b, reverse, warpRoutes)
+				if err != nil {
+					return nil, err
+				}
+				if ab.IsZero() || ba.IsZero() {
+					continue
+				}
+
+				offset := math.MinInt(ab, ba)
+				result := NettingResult{
+					DomainA:   a,
+					DomainB:   b,
+					WarpRoute: asset.warpRoute,
+					AmountAB:  ab.String(),
+					AmountBA:  ba.String(),
+					Offset:    offset.String(),
+				}
+
+				result.NettedTxs = append(result.NettedTxs, offsetCorridor(routeSets[a], a, asset, offset, warpRoutes)...)
+				result.NettedTxs = append(result.NettedTxs, offsetCorridor(routeSets[b], b, reverse, offset, warpRoutes)...)
+
+				switch {
+				case ab.GT(ba):
+					result.NetAmount = ab.Sub(ba).String()
+					result.NetFrom = a
+				case ba.GT(ab):
+					result.NetAmount = ba.Sub(ab).String()
+					result.NetFrom = b
+				default:
+					result.NetAmount = "0"
+				}
+
+				results = append(results, result)
+			}
+		}
+	}
+
+	for _, routes := range routeSets {
+		RecomputeTotal(routes)
+	}
+
+	return results, nil
+}
+
+// RecomputeTotal recalculates the total amount of a route set
+func RecomputeTotal(routes *types.Routes) {
+	total := math.ZeroInt()
+	for _, route := range routes.Routes {
+		if amount, ok := math.NewIntFromString(route.Amount); ok {
+			total = total.Add(amount)
+		}
+	}
+	routes.TotalAmount = total.String()
+}
+
+// corridorOf returns the corridor of a route parsed on the source domain, or false for a route
+// without routing info or whose token is not part of a configured warp route
+func corridorOf(source uint32, route types.HyperlaneRoute, warpRoutes types.WarpRouteIndex) (corridor, bool, error) {
+	if route.RouteInfo == nil {
+		return corridor{}, false, nil
+	}
+	warpRoute, ok, err := warpRoutes.Route(source, route.RouteInfo.TokenID)
+	if err != nil {
+		return corridor{}, false, fmt.Errorf("invalid token ID %s in route from tx %s: %w", route.RouteInfo.TokenID, route.TxHash, err)
+	}
+	if !ok {
+		return corridor{}, false, nil
+	}
+	return corridor{destination: route.RouteInfo.DestinationDomain, warpRoute: warpRoute}, true, nil
+}
+
+// corridorAssets returns the corridors of the routes parsed on the source domain going to the
+// destination domain, one per warp route, sorted
+func corridorAssets(routes *types.Routes, source, destination uint32, warpRoutes types.WarpRouteIndex) ([]corridor, error) {
+	seen := make(map[corridor]bool)
+	var assets []corridor
+	for _, route := range routes.Routes {
+		c, ok, err := corridorOf(source, route, warpRoutes)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || c.destination != destination || seen[c] {
+			continue
+		}
+		seen[c] = true
+		assets = append(assets, c)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].warpRoute < assets[j].warpRoute })
+	return assets, nil
+}
+
+// corridorTotal sums the amounts of all routes in the corridor
+func corridorTotal(routes *types.Routes, source uint32, c corridor, warpRoutes types.WarpRouteIndex) (math.Int, error) {
+	total := math.ZeroInt()
+	for _, route := range routes.Routes {
+		rc, ok, err := corridorOf(source, route, warpRoutes)
+		if err != nil {
+			return math.Int{}, err
+		}
+		if !ok || rc != c {
+			continue
+		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return math.Int{}, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+		total = total.Add(amount)
+	}
+	return total, nil
+}
+
+// offsetCorridor removes offset from the routes in the corridor, smallest route first, and returns
+// the tx hashes of the routes that were affected. The corridor's routes were checked by corridorTotal.
+func offsetCorridor(routes *types.Routes, source uint32, c corridor, offset math.Int, warpRoutes types.WarpRouteIndex) []string {
+	var indices []int
+	for i, route := range routes.Routes {
+		if rc, ok, _ := corridorOf(source, route, warpRoutes); ok && rc == c {
+			indices = append(indices, i)
+		}
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		ai, _ := math.NewIntFromString(routes.Routes[indices[i]].Amount)
+		aj, _ := math.NewIntFromString(routes.Routes[indices[j]].Amount)
+		return ai.LT(aj)
+	})
+
+	var netted []string
+	drop := make(map[int]bool)
+	remaining := offset
+	for _, idx := range indices {
+		if remaining.IsZero() {
+			break
+		}
+
+		route := &routes.Routes[idx]
+		amount, _ := math.NewIntFromString(route.Amount)
+		netted = append(netted, route.TxHash)
+
+		if amount.LTE(remaining) {
+			drop[idx] = true
+			remaining = remaining.Sub(amount)
+			continue
+		}
+
+		setRouteAmount(route, amount.Sub(remaining).String())
+		remaining = math.ZeroInt()
+	}
+
+	kept := routes.Routes[:0]
+	for i, route := range routes.Routes {
+		if !drop[i] {
+			kept = append(kept, route)
+		}
+	}
+	routes.Routes = kept
+
+	return netted
+}
+
+// setRouteAmount updates a route's amount, keeping a metadata amount override in sync
+// so the verifier expects the reduced amount
+func setRouteAmount(route *types.HyperlaneRoute, amount string) {
+	route.Amount = amount
+	if route.RouteInfo != nil && route.RouteInfo.Amount != "" {
+		info := *route.RouteInfo
+		info.Amount = amount
+		route.RouteInfo = &info
+	}
+}
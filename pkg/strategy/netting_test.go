@@ -0,0 +1,196 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func route(txHash string, domain uint32, amount string) types.HyperlaneRoute {
+	return types.HyperlaneRoute{
+		TxHash: txHash,
+		Amount: amount,
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: domain,
+			Recipient:         "0x742d35cc6634c0532925a3b844bc9e7595f0beb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}
+}
+
+// Token IDs of the test warp routes, which differ on each chain as deployed warp tokens do
+const (
+	tiaOn1  = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	tiaOn2  = "0x726f757465725f61707000000000000000000000000000010000000000000000"
+	usdcOn1 = "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
+	usdcOn2 = "0x726f757465725f61707000000000000000000000000000010000000000000001"
+)
+
+func testWarpRoutes(t *testing.T) types.WarpRouteIndex {
+	t.Helper()
+	index, err := types.NewWarpRouteIndex([]types.WarpRouteConfig{
+		{Name: "TIA", Tokens: map[uint32]string{1: tiaOn1, 2: tiaOn2, 3: tiaOn1}},
+		{Name: "USDC", Tokens: map[uint32]string{1: usdcOn1, 2: usdcOn2}},
+	})
+	if err != nil {
+		t.Fatalf("NewWarpRouteIndex() error = %v", err)
+	}
+	return index
+}
+
+// tokenRoute returns a route of the warp token tokenID to domain
+func tokenRoute(txHash, tokenID string, domain uint32, amount string) types.HyperlaneRoute {
+	r := route(txHash, domain, amount)
+	r.RouteInfo.TokenID = tokenID
+	return r
+}
+
+func TestNetCorridors(t *testing.T) {
+	routeSets := map[uint32]*types.Routes{
+		// Domain 1 sends 100 + 50 to domain 2 and 30 to domain 3
+		1: {Routes: []types.HyperlaneRoute{
+			tokenRoute("A1", tiaOn1, 2, "100"),
+			tokenRoute("A2", tiaOn1, 2, "50"),
+			tokenRoute("A3", tiaOn1, 3, "30"),
+		}},
+		// Domain 2 sends 60 + 20 back to domain 1 with its own token of the warp route
+		2: {Routes: []types.HyperlaneRoute{
+			tokenRoute("B1", tiaOn2, 1, "60"),
+			tokenRoute("B2", tiaOn2, 1, "20"),
+		}},
+	}
+
+	results, err := NetCorridors(routeSets, testWarpRoutes(t))
+	if err != nil {
+		t.Fatalf("NetCorridors() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("NetCorridors() returned %d results, want 1", len(results))
+	}
+
+	result := results[0]
+	if result.WarpRoute != "TIA" || result.Offset != "80" || result.NetAmount != "70" || result.NetFrom != 1 {
+		t.Errorf("result = %+v, want TIA offset 80, net 70 from domain 1", result)
+	}
+
+	// Domain 2 is fully offset
+	if len(routeSets[2].Routes) != 0 || routeSets[2].TotalAmount != "0" {
+		t.Errorf("domain 2 routes = %+v, want none", routeSets[2].Routes)
+	}
+
+	// Domain 1: the 50 route is consumed, the 100 route reduced to 70, the corridor to 3 untouched
+	got := map[string]string{}
+	for _, r := range routeSets[1].Routes {
+		got[r.TxHash] = r.Amount
+	}
+	want := map[string]string{"A1": "70", "A3": "30"}
+	if len(got) != len(want) {
+		t.Fatalf("domain 1 routes = %v, want %v", got, want)
+	}
+	for hash, amount := range want {
+		if got[hash] != amount {
+			t.Errorf("route %s amount = %s, want %s", hash, got[hash], amount)
+		}
+	}
+	if routeSets[1].TotalAmount != "100" {
+		t.Errorf("domain 1 total = %s, want 100", routeSets[1].TotalAmount)
+	}
+}
+
+func TestNetCorridorsNoOpposingFlow(t *testing.T) {
+	routeSets := map[uint32]*types.Routes{
+		1: {Routes: []types.HyperlaneRoute{tokenRoute("A1", tiaOn1, 2, "100")}},
+		2: {Routes: []types.HyperlaneRoute{tokenRoute("B1", tiaOn2, 3, "60")}},
+	}
+
+	results, err := NetCorridors(routeSets, testWarpRoutes(t))
+	if err != nil {
+		t.Fatalf("NetCorridors() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("NetCorridors() returned %d results, want 0", len(results))
+	}
+	if len(routeSets[1].Routes) != 1 || len(routeSets[2].Routes) != 1 {
+		t.Error("NetCorridors() modified routes without opposing flows")
+	}
+}
+
+func TestNetCorridorsKeepsAmountOverrideInSync(t *testing.T) {
+	overridden := tokenRoute("A1", tiaOn1, 2, "100")
+	overridden.RouteInfo.Amount = "100"
+
+	routeSets := map[uint32]*types.Routes{
+		1: {Routes: []types.HyperlaneRoute{overridden}},
+		2: {Routes: []types.HyperlaneRoute{tokenRoute("B1", tiaOn2, 1, "40")}},
+	}
+
+	if _, err := NetCorridors(routeSets, testWarpRoutes(t)); err != nil {
+		t.Fatalf("NetCorridors() error = %v", err)
+	}
+
+	remaining := routeSets[1].Routes[0]
+	if remaining.Amount != "60" || remaining.RouteInfo.Amount != "60" {
+		t.Errorf("amount = %s, override = %s, want both 60", remaining.Amount, remaining.RouteInfo.Amount)
+	}
+}
+
+func TestNetCorridorsPerWarpRoute(t *testing.T) {
+	warpRoutes := testWarpRoutes(t)
+	routeSets := map[uint32]*types.Routes{
+		// Domain 1 sends TIA to domain 2 while domain 2 sends USDC back
+		1: {Routes: []types.HyperlaneRoute{tokenRoute("A1", tiaOn1, 2, "100")}},
+		2: {Routes: []types.HyperlaneRoute{tokenRoute("B1", usdcOn2, 1, "60")}},
+	}
+
+	results, err := NetCorridors(routeSets, warpRoutes)
+	if err != nil {
+		t.Fatalf("NetCorridors() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("NetCorridors() = %+v, want different warp routes left unnetted", results)
+	}
+	if routeSets[1].Routes[0].Amount != "100" || routeSets[2].Routes[0].Amount != "60" {
+		t.Errorf("NetCorridors() changed routes of different warp routes: %+v, %+v", routeSets[1].Routes, routeSets[2].Routes)
+	}
+
+	// The same warp route in both directions nets, the other warp route is left alone
+	routeSets[1].Routes = append(routeSets[1].Routes, tokenRoute("A2", usdcOn1, 2, "40"))
+	routeSets[2].Routes = append(routeSets[2].Routes, tokenRoute("B2", tiaOn2, 1, "30"))
+	results, err = NetCorridors(routeSets, warpRoutes)
+	if err != nil {
+		t.Fatalf("NetCorridors() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("NetCorridors() returned %d results, want one per warp route", len(results))
+	}
+	want := map[string]string{"TIA": "30", "USDC": "40"}
+	for _, r := range results {
+		if r.Offset != want[r.WarpRoute] {
+			t.Errorf("warp route %s offset = %s, want %s", r.WarpRoute, r.Offset, want[r.WarpRoute])
+		}
+	}
+	if routeSets[1].TotalAmount != "70" || routeSets[2].TotalAmount != "20" {
+		t.Errorf("totals = %s, %s, want 70 and 20", routeSets[1].TotalAmount, routeSets[2].TotalAmount)
+	}
+}
+
+func TestNetCorridorsUnmappedTokens(t *testing.T) {
+	// Domain 2 routes the token ID of TIA on domain 1, which is not its TIA warp token: equal token
+	// IDs on two chains say nothing about the asset, so nothing is netted
+	routeSets := map[uint32]*types.Routes{
+		1: {Routes: []types.HyperlaneRoute{tokenRoute("A1", tiaOn1, 2, "100")}},
+		2: {Routes: []types.HyperlaneRoute{tokenRoute("B1", tiaOn1, 1, "60")}},
+	}
+
+	results, err := NetCorridors(routeSets, testWarpRoutes(t))
+	if err != nil {
+		t.Fatalf("NetCorridors() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("NetCorridors() = %+v, want tokens outside a warp route left unnetted", results)
+	}
+	if routeSets[1].Routes[0].Amount != "100" || routeSets[2].Routes[0].Amount != "60" {
+		t.Errorf("NetCorridors() changed unmapped routes: %+v, %+v", routeSets[1].Routes, routeSets[2].Routes)
+	}
+}
@@ -0,0 +1,62 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// ApplyOverrides returns a copy of routes with the manual overrides applied. Each overridden route
+// records its original values and the justification, and must still pass the config's whitelist.
+// An override that matches no route is an error, so a typo cannot silently leave a route unchanged.
+func ApplyOverrides(routes *types.Routes, overrides []types.Override, config *types.Config) (*types.Routes, error) {
+	result := &types.Routes{
+		MultisigAddr: routes.MultisigAddr,
+		Routes:       append([]types.HyperlaneRoute(nil), routes.Routes...),
+	}
+
+	for _, o := range overrides {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+
+		matched := false
+		for i := range result.Routes {
+			route := &result.Routes[i]
+			if route.TxHash != o.TxHash {
+				continue
+			}
+			if route.RouteInfo == nil {
+				return nil, fmt.Errorf("route from tx %s has no routing info", route.TxHash)
+			}
+			matched = true
+
+			applied := &types.AppliedOverride{Justification: o.Justification}
+			if o.Amount != "" && o.Amount != route.Amount {
+				applied.OriginalAmount = route.Amount
+				setRouteAmount(route, o.Amount)
+			}
+			if o.Recipient != "" && o.Recipient != route.RouteInfo.Recipient {
+				// A fan-out route is redirected entirely to the override's recipient
+				applied.OriginalRecipient = strings.Join(route.RouteInfo.Recipients(), ",")
+				info := *route.RouteInfo
+				info.Recipient = o.Recipient
+				info.Splits = nil
+				route.RouteInfo = &info
+			}
+			route.Override = applied
+
+			if err := config.ValidateRoute(route.RouteInfo); err != nil {
+				return nil, fmt.Errorf("override for tx %s violates policy: %w", o.TxHash, err)
+			}
+		}
+
+		if !matched {
+			return nil, fmt.Errorf("override for tx %s matches no route", o.TxHash)
+		}
+	}
+
+	RecomputeTotal(result)
+	return result, nil
+}
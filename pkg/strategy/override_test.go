@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	original := route("A1", 2, "100")
+	config := &types.Config{Whitelist: types.AddressWhitelist{Domains: map[uint32][]string{
+		2: {original.RouteInfo.Recipient, "0xreplacement"},
+	}}}
+
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{original, route("A2", 2, "50")}}
+
+	tests := []struct {
+		name     string
+		override types.Override
+		wantErr  bool
+	}{
+		{name: "amount", override: types.Override{TxHash: "A1", Amount: "80", Justification: "fee refund"}},
+		{name: "recipient", override: types.Override{TxHash: "A1", Recipient: "0xreplacement", Justification: "lost key"}},
+		{name: "recipient not whitelisted", override: types.Override{TxHash: "A1", Recipient: "0xother", Justification: "x"}, wantErr: true},
+		{name: "unknown tx", override: types.Override{TxHash: "A9", Amount: "1", Justification: "x"}, wantErr: true},
+		{name: "no justification", override: types.Override{TxHash: "A1", Amount: "1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyOverrides(routes, []types.Override{tt.override}, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			overridden := result.Routes[0]
+			if overridden.Override == nil || overridden.Override.Justification != tt.override.Justification {
+				t.Errorf("override not recorded on route: %+v", overridden.Override)
+			}
+			if routes.Routes[0].Amount != "100" || routes.Routes[0].RouteInfo.Recipient != original.RouteInfo.Recipient {
+				t.Error("ApplyOverrides() modified the input routes")
+			}
+		})
+	}
+
+	result, err := ApplyOverrides(routes, []types.Override{{TxHash: "A1", Amount: "80", Justification: "fee refund"}}, config)
+	if err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+	if result.TotalAmount != "130" || result.Routes[0].Override.OriginalAmount != "100" {
+		t.Errorf("total = %s, original amount = %s, want 130 and 100", result.TotalAmount, result.Routes[0].Override.OriginalAmount)
+	}
+}
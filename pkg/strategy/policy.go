@@ -0,0 +1,240 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Result is the outcome of applying a strategy to a route set
+type Result struct {
+	Routes     *types.Routes          `json:"routes"`               // Routes to generate transfers for
+	Deferred   []types.HyperlaneRoute `json:"deferred,omitempty"`   // Routes held back by the total cap for a later run
+	Aggregated int                    `json:"aggregated"`           // Number of routes merged into another route
+	Split      int                    `json:"split"`                // Number of routes split into several transfers
+	FannedOut  int                    `json:"fanned_out,omitempty"` // Number of routes expanded into one route per split recipient
+	Reordered  int                    `json:"reordered,omitempty"`  // Number of routes moved by the priority order
+	Deducted   int                    `json:"deducted,omitempty"`   // Number of deposits the rebalancing fee was kept from
+}
+
+// Changed reports whether the strategy altered the route set
+func (r *Result) Changed() bool {
+	return len(r.Deferred) > 0 || r.Aggregated > 0 || r.Split > 0 || r.FannedOut > 0 || r.Reordered > 0 || r.Deducted > 0
+}
+
+// ApplyLimits splits routes above the per-transfer maximum into several routes of at most the
+// maximum each, if the limits allow splitting. Routes above the maximum are otherwise left for
+// the generator to refuse.
+func (r *Result) ApplyLimits(limits types.LimitsConfig) error {
+	if !limits.SplitOversized {
+		return nil
+	}
+	max, err := limits.MaxTransfer()
+	if err != nil || max.IsNil() {
+		return err
+	}
+	return r.split(func(types.HyperlaneRoute) math.Int { return max })
+}
+
+// ApplyMessageCaps splits routes above the max_amount_per_message of their destination into
+// several routes of at most the cap each, so every part is generated as its own MsgRemoteTransfer
+func (r *Result) ApplyMessageCaps(destinations map[uint32]types.DestinationConfig) error {
+	caps := make(map[uint32]math.Int)
+	for domain, destination := range destinations {
+		limit, err := destination.MessageCap()
+		if err != nil {
+			return fmt.Errorf("destination %d: %w", domain, err)
+		}
+		if !limit.IsNil() {
+			caps[domain] = limit
+		}
+	}
+	if len(caps) == 0 {
+		return nil
+	}
+	return r.split(func(route types.HyperlaneRoute) math.Int {
+		if route.RouteInfo == nil {
+			return math.Int{}
+		}
+		return caps[route.RouteInfo.DestinationDomain]
+	})
+}
+
+// split replaces every route above the maximum maxFor returns for it with parts of at most the
+// maximum each. Routes whose maximum is a nil Int are kept whole.
+func (r *Result) split(maxFor func(types.HyperlaneRoute) math.Int) error {
+	var routes []types.HyperlaneRoute
+	for _, route := range r.Routes.Routes {
+		max := maxFor(route)
+		if max.IsNil() {
+			routes = append(routes, route)
+			continue
+		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+		if amount.LTE(max) {
+			routes = append(routes, route)
+			continue
+		}
+
+		r.Split++
+		for remaining := amount; remaining.IsPositive(); remaining = remaining.Sub(max) {
+			part := route
+			setRouteAmount(&part, math.MinInt(remaining, max).String())
+			routes = append(routes, part)
+		}
+	}
+
+	r.Routes.Routes = routes
+	RecomputeTotal(r.Routes)
+	return nil
+}
+
+// Apply applies the strategy to routes without modifying them. The rebalancing fee is kept from
+// every deposit first, so each deposit pays it once however its route is divided or merged later.
+// Routes are then put in priority order, if configured. The total cap is applied in that order, so
+// the routes first in line, by default the oldest deposits, are forwarded first and a route is never
+// partially deferred; fan-out routes are then expanded into one route per split recipient, and the
+// remaining routes are aggregated if enabled.
+func Apply(routes *types.Routes, config types.StrategyConfig, fee types.RebalancingFeeConfig) (*Result, error) {
+	result := &Result{
+		Routes: &types.Routes{MultisigAddr: routes.MultisigAddr},
+	}
+
+	ordered := routes.Routes
+	if fee.Enabled() {
+		ordered = append([]types.HyperlaneRoute(nil), routes.Routes...)
+		for i := range ordered {
+			if ordered[i].FeeKept() {
+				continue
+			}
+			if err := fee.DeductFrom(&ordered[i]); err != nil {
+				return nil, err
+			}
+			result.Deducted++
+		}
+	}
+
+	if config.Priority != nil {
+		var err error
+		ordered, result.Reordered, err = Prioritize(ordered, *config.Priority)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kept := ordered
+	if config.MaxTotalAmount != "" {
+		limit, ok := math.NewIntFromString(config.MaxTotalAmount)
+		if !ok || limit.IsNegative() {
+			return nil, fmt.Errorf("invalid max_total_amount %s", config.MaxTotalAmount)
+		}
+
+		total := math.ZeroInt()
+		for i, route := range ordered {
+			amount, ok := math.NewIntFromString(route.Amount)
+			if !ok {
+				return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+			}
+			if total.Add(amount).GT(limit) {
+				kept = ordered[:i]
+				result.Deferred = append(result.Deferred, ordered[i:]...)
+				break
+			}
+			total = total.Add(amount)
+		}
+	}
+
+	var expanded []types.HyperlaneRoute
+	for i := range kept {
+		parts, err := kept[i].ExpandSplits()
+		if err != nil {
+			return nil, err
+		}
+		if kept[i].RouteInfo != nil && len(kept[i].RouteInfo.Splits) > 0 {
+			result.FannedOut++
+		}
+		expanded = append(expanded, parts...)
+	}
+	kept = expanded
+
+	if config.Aggregate {
+		aggregated, err := Aggregate(kept)
+		if err != nil {
+			return nil, err
+		}
+		result.Aggregated = len(kept) - len(aggregated)
+		kept = aggregated
+	}
+
+	result.Routes.Routes = append([]types.HyperlaneRoute(nil), kept...)
+	RecomputeTotal(result.Routes)
+
+	return result, nil
+}
+
+// Aggregate merges routes with the same destination domain, recipient, token ID and denom into a
+// single route carrying the summed amount, so each destination receives one transfer. The merged
+// route lists the transfer keys of its deposits comma-separated as its tx hash, records what each
+// deposit contributed in its provenance and keeps the position of the first route.
+func Aggregate(routes []types.HyperlaneRoute) ([]types.HyperlaneRoute, error) {
+	var merged []types.HyperlaneRoute
+	index := make(map[string]int)
+
+	for _, route := range routes {
+		if route.RouteInfo == nil {
+			return nil, fmt.Errorf("route from tx %s has no routing info", route.TxHash)
+		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+
+		key := aggregationKey(route)
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(merged)
+			merged = append(merged, route)
+			continue
+		}
+
+		existing := &merged[i]
+		if len(existing.Provenance) == 0 {
+			existing.Provenance = provenance(*existing)
+		}
+		existing.Provenance = append(existing.Provenance, provenance(route)...)
+		existing.RebalancingFee = nil
+		sum, _ := math.NewIntFromString(existing.Amount)
+		existing.TxHash = existing.TransferKey() + "," + route.TransferKey()
+		existing.MsgIndex = 0
+		if route.BlockHeight > existing.BlockHeight {
+			existing.BlockHeight = route.BlockHeight
+		}
+		if existing.From != route.From {
+			existing.From = ""
+		}
+		setRouteAmount(existing, sum.Add(amount).String())
+	}
+
+	return merged, nil
+}
+
+// aggregationKey identifies the routes Aggregate merges: those with the same destination domain,
+// recipient, token ID and denom. The route must have routing info.
+func aggregationKey(route types.HyperlaneRoute) string {
+	return fmt.Sprintf("%d/%s/%s/%s", route.RouteInfo.DestinationDomain,
+		strings.ToLower(route.RouteInfo.Recipient), strings.ToLower(route.RouteInfo.TokenID), route.Denom)
+}
+
+// provenance returns the deposits a route forwards: its own provenance if it was already
+// aggregated, or the route itself
+func provenance(route types.HyperlaneRoute) []types.RouteSource {
+	if len(route.Provenance) > 0 {
+		return append([]types.RouteSource(nil), route.Provenance...)
+	}
+	return []types.RouteSource{{TxHash: route.TxHash, MsgIndex: route.MsgIndex, BlockHeight: route.BlockHeight, From: route.From, Amount: route.Amount, RebalancingFee: route.RebalancingFee}}
+}
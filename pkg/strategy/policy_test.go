@@ -0,0 +1,255 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestApplyMaxTotal(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{
+		route("A1", 2, "100"),
+		route("A2", 2, "50"),
+		route("A3", 3, "30"),
+	}}
+
+	result, err := Apply(routes, types.StrategyConfig{MaxTotalAmount: "160"}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	// A3 would fit on its own but is deferred to keep deposits in order
+	if len(result.Routes.Routes) != 2 || result.Routes.TotalAmount != "150" {
+		t.Errorf("routes = %+v, want A1 and A2 totalling 150", result.Routes)
+	}
+	if len(result.Deferred) != 1 || result.Deferred[0].TxHash != "A3" {
+		t.Errorf("deferred = %+v, want A3", result.Deferred)
+	}
+	if len(routes.Routes) != 3 {
+		t.Error("Apply() modified the input routes")
+	}
+}
+
+func TestApplyAggregate(t *testing.T) {
+	overridden := route("A2", 2, "50")
+	overridden.RouteInfo.Amount = "50"
+
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{
+		route("A1", 2, "100"),
+		route("A3", 3, "30"),
+		overridden,
+	}}
+
+	result, err := Apply(routes, types.StrategyConfig{Aggregate: true}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if result.Aggregated != 1 || len(result.Routes.Routes) != 2 {
+		t.Fatalf("aggregated %d into %d routes, want 1 into 2", result.Aggregated, len(result.Routes.Routes))
+	}
+
+	merged := result.Routes.Routes[0]
+	if merged.TxHash != "A1,A2" || merged.Amount != "150" {
+		t.Errorf("merged route = %s %s, want A1,A2 150", merged.TxHash, merged.Amount)
+	}
+	if len(merged.Provenance) != 2 || merged.Provenance[0].Amount != "100" || merged.Provenance[1].TxHash != "A2" || merged.Provenance[1].Amount != "50" {
+		t.Errorf("provenance = %+v, want A1 100 and A2 50", merged.Provenance)
+	}
+	if len(result.Routes.Routes[1].Provenance) != 0 {
+		t.Errorf("route A3 was not aggregated but has provenance %+v", result.Routes.Routes[1].Provenance)
+	}
+	if result.Routes.TotalAmount != "180" {
+		t.Errorf("total = %s, want 180", result.Routes.TotalAmount)
+	}
+	if routes.Routes[0].Amount != "100" || routes.Routes[2].RouteInfo.Amount != "50" {
+		t.Error("Apply() modified the input routes")
+	}
+}
+
+func TestAggregateTransfersOfOneTransaction(t *testing.T) {
+	second := route("A1", 2, "50")
+	second.MsgIndex = 1
+
+	merged, err := Aggregate([]types.HyperlaneRoute{route("A1", 2, "100"), second})
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	if len(merged) != 1 || merged[0].TxHash != "A1,A1/1" || merged[0].Amount != "150" {
+		t.Fatalf("Aggregate() = %+v, want both transfers of A1 as A1,A1/1", merged)
+	}
+	if p := merged[0].Provenance; len(p) != 2 || p[1].TransferKey() != "A1/1" {
+		t.Errorf("provenance = %+v, want the second transfer of A1", p)
+	}
+}
+
+func TestApplyNoStrategy(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route("A1", 2, "100")}}
+
+	result, err := Apply(routes, types.StrategyConfig{}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Changed() {
+		t.Errorf("Changed() = true for an empty strategy")
+	}
+}
+
+func TestApplyFanOut(t *testing.T) {
+	fanOut := route("A2", 2, "100")
+	fanOut.RouteInfo.Recipient = ""
+	fanOut.RouteInfo.Splits = []types.RouteSplit{
+		{Recipient: route("A1", 2, "0").RouteInfo.Recipient, Percent: "70"},
+		{Recipient: "0x1111111111111111111111111111111111111111", Percent: "30"},
+	}
+
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route("A1", 2, "100"), fanOut}}
+	result, err := Apply(routes, types.StrategyConfig{Aggregate: true}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	// The split to A1's recipient is aggregated with A1
+	if result.FannedOut != 1 || result.Aggregated != 1 || !result.Changed() {
+		t.Errorf("fanned out %d and aggregated %d routes, want 1 and 1", result.FannedOut, result.Aggregated)
+	}
+	if len(result.Routes.Routes) != 2 || result.Routes.Routes[0].Amount != "170" || result.Routes.Routes[1].Amount != "30" {
+		t.Errorf("routes = %+v, want 170 to A1's recipient and 30 to the other split", result.Routes.Routes)
+	}
+	if result.Routes.TotalAmount != "200" {
+		t.Errorf("total = %s, want 200", result.Routes.TotalAmount)
+	}
+	if len(routes.Routes[1].RouteInfo.Splits) != 2 {
+		t.Error("Apply() modified the input routes")
+	}
+}
+
+func TestApplyLimitsSplit(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{
+		route("A1", 2, "250"),
+		route("A2", 3, "50"),
+	}}
+
+	result, err := Apply(routes, types.StrategyConfig{}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if err := result.ApplyLimits(types.LimitsConfig{MaxTransferAmount: "100", SplitOversized: true}); err != nil {
+		t.Fatalf("ApplyLimits() error = %v", err)
+	}
+
+	var amounts []string
+	for _, r := range result.Routes.Routes {
+		amounts = append(amounts, r.TxHash+":"+r.Amount)
+	}
+	want := []string{"A1:100", "A1:100", "A1:50", "A2:50"}
+	if len(amounts) != len(want) {
+		t.Fatalf("routes = %v, want %v", amounts, want)
+	}
+	for i := range want {
+		if amounts[i] != want[i] {
+			t.Errorf("routes = %v, want %v", amounts, want)
+			break
+		}
+	}
+	if result.Split != 1 || !result.Changed() {
+		t.Errorf("Split = %d, Changed() = %v, want 1 and true", result.Split, result.Changed())
+	}
+	if result.Routes.TotalAmount != "300" {
+		t.Errorf("total = %s, want 300", result.Routes.TotalAmount)
+	}
+}
+
+func TestApplyMessageCaps(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{
+		route("A1", 2, "250"),
+		route("A2", 3, "250"),
+	}}
+
+	result, err := Apply(routes, types.StrategyConfig{}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if err := result.ApplyMessageCaps(map[uint32]types.DestinationConfig{2: {MaxAmountPerMessage: "100"}, 3: {}}); err != nil {
+		t.Fatalf("ApplyMessageCaps() error = %v", err)
+	}
+
+	var amounts []string
+	for _, r := range result.Routes.Routes {
+		amounts = append(amounts, r.TxHash+":"+r.Amount)
+	}
+	want := "A1:100 A1:100 A1:50 A2:250"
+	if got := strings.Join(amounts, " "); got != want {
+		t.Errorf("routes = %s, want %s", got, want)
+	}
+	if result.Split != 1 || result.Routes.TotalAmount != "500" {
+		t.Errorf("Split = %d, total = %s, want 1 and 500", result.Split, result.Routes.TotalAmount)
+	}
+}
+
+func TestApplyLimitsWithoutSplit(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route("A1", 2, "250")}}
+
+	result, err := Apply(routes, types.StrategyConfig{}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if err := result.ApplyLimits(types.LimitsConfig{MaxTransferAmount: "100"}); err != nil {
+		t.Fatalf("ApplyLimits() error = %v", err)
+	}
+	if len(result.Routes.Routes) != 1 || result.Changed() {
+		t.Errorf("routes were changed without split_oversized")
+	}
+}
+
+func TestApplyRebalancingFeePerDeposit(t *testing.T) {
+	fanOut := route("A3", 2, "1000")
+	fanOut.RouteInfo.Recipient = ""
+	fanOut.RouteInfo.Splits = []types.RouteSplit{
+		{Recipient: route("A1", 2, "0").RouteInfo.Recipient, Percent: "50"},
+		{Recipient: "0x1111111111111111111111111111111111111111", Percent: "50"},
+	}
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route("A1", 2, "1000"), route("A2", 2, "1000"), fanOut}}
+	fee := types.RebalancingFeeConfig{Flat: "100"}
+
+	result, err := Apply(routes, types.StrategyConfig{Aggregate: true}, fee)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Deducted != 3 || !result.Changed() {
+		t.Errorf("Deducted = %d, want the fee kept from each of the 3 deposits", result.Deducted)
+	}
+
+	// Each deposit pays the flat fee once: not once for the aggregated transfer, nor once per
+	// recipient of the fan-out deposit
+	if len(result.Routes.Routes) != 2 || result.Routes.Routes[0].Amount != "2250" || result.Routes.Routes[1].Amount != "450" {
+		t.Fatalf("routes = %+v, want 900 + 900 + 450 aggregated and 450 to the other split", result.Routes.Routes)
+	}
+	aggregated := result.Routes.Routes[0]
+	if aggregated.RebalancingFee != nil || len(aggregated.KeptFees()) != 3 {
+		t.Errorf("aggregated route keeps fees %+v, want those of its 3 deposits", aggregated.KeptFees())
+	}
+	for _, kept := range aggregated.KeptFees() {
+		if err := fee.Check(kept); err != nil {
+			t.Errorf("Check(%+v) error = %v", kept, err)
+		}
+	}
+	if routes.Routes[0].Amount != "1000" || routes.Routes[0].RebalancingFee != nil {
+		t.Error("Apply() modified the input routes")
+	}
+
+	// Splitting by the message caps divides what is forwarded, without keeping the fee again
+	if err := result.ApplyMessageCaps(map[uint32]types.DestinationConfig{2: {MaxAmountPerMessage: "1000"}}); err != nil {
+		t.Fatalf("ApplyMessageCaps() error = %v", err)
+	}
+	if result.Routes.TotalAmount != "2700" {
+		t.Errorf("total = %s, want 2700 after 3 fees of 100", result.Routes.TotalAmount)
+	}
+
+	// A deposit not covering its fee is refused
+	small := &types.Routes{Routes: []types.HyperlaneRoute{route("A1", 2, "100")}}
+	if _, err := Apply(small, types.StrategyConfig{}, fee); err == nil {
+		t.Error("Apply() accepted a deposit not covering the rebalancing fee")
+	}
+}
@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Prioritize returns routes in priority order: routes to domains with a higher weight first, then
+// the oldest or the largest first. Routes equal in priority keep their order. It also returns how
+// many routes changed position.
+func Prioritize(routes []types.HyperlaneRoute, priority types.PriorityConfig) ([]types.HyperlaneRoute, int, error) {
+	if err := priority.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	amounts := make([]math.Int, len(routes))
+	weights := make([]int, len(routes))
+	for i, route := range routes {
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+		amounts[i] = amount
+		if route.RouteInfo != nil {
+			weights[i] = priority.DomainWeights[route.RouteInfo.DestinationDomain]
+		}
+	}
+
+	order := make([]int, len(routes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if weights[i] != weights[j] {
+			return weights[i] > weights[j]
+		}
+		if priority.Order == types.PriorityLargestFirst {
+			return amounts[i].GT(amounts[j])
+		}
+		return routes[i].BlockHeight < routes[j].BlockHeight
+	})
+
+	ordered := make([]types.HyperlaneRoute, len(routes))
+	moved := 0
+	for position, i := range order {
+		ordered[position] = routes[i]
+		if position != i {
+			moved++
+		}
+	}
+	return ordered, moved, nil
+}
@@ -0,0 +1,73 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestPrioritize(t *testing.T) {
+	routes := []types.HyperlaneRoute{
+		route("A1", 2, "100"),
+		route("A2", 3, "300"),
+		route("A3", 2, "200"),
+		route("A4", 4, "300"),
+	}
+	for i := range routes {
+		routes[i].BlockHeight = int64(40 - i)
+	}
+
+	tests := []struct {
+		name      string
+		priority  types.PriorityConfig
+		want      string
+		wantMoved int
+	}{
+		{name: "oldest first", priority: types.PriorityConfig{}, want: "A4 A3 A2 A1", wantMoved: 4},
+		{name: "largest first", priority: types.PriorityConfig{Order: types.PriorityLargestFirst}, want: "A2 A4 A3 A1", wantMoved: 3},
+		{name: "domain weights", priority: types.PriorityConfig{Order: types.PriorityLargestFirst, DomainWeights: map[uint32]int{2: 10, 4: -1}}, want: "A3 A1 A2 A4", wantMoved: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, moved, err := Prioritize(routes, tt.priority)
+			if err != nil {
+				t.Fatalf("Prioritize() error = %v", err)
+			}
+			var hashes []string
+			for _, r := range ordered {
+				hashes = append(hashes, r.TxHash)
+			}
+			if got := strings.Join(hashes, " "); got != tt.want || moved != tt.wantMoved {
+				t.Errorf("Prioritize() = %s with %d moved, want %s with %d", got, moved, tt.want, tt.wantMoved)
+			}
+		})
+	}
+
+	if routes[0].TxHash != "A1" {
+		t.Error("Prioritize() modified its input")
+	}
+	if _, _, err := Prioritize(routes, types.PriorityConfig{Order: "newest_first"}); err == nil {
+		t.Error("Prioritize() expected an error for an unknown order")
+	}
+}
+
+func TestApplyPriorityBeforeCap(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{
+		route("A1", 2, "100"),
+		route("A2", 3, "300"),
+		route("A3", 2, "50"),
+	}}
+
+	// Largest first, the cap forwards the large deposit and defers the small ones
+	result, err := Apply(routes, types.StrategyConfig{MaxTotalAmount: "350", Priority: &types.PriorityConfig{Order: types.PriorityLargestFirst}}, types.RebalancingFeeConfig{})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(result.Routes.Routes) != 1 || result.Routes.Routes[0].TxHash != "A2" {
+		t.Errorf("kept %+v, want only A2", result.Routes.Routes)
+	}
+	if len(result.Deferred) != 2 || result.Reordered == 0 || !result.Changed() {
+		t.Errorf("Deferred = %d, Reordered = %d, want 2 deferred and routes reordered", len(result.Deferred), result.Reordered)
+	}
+}
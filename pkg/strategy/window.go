@@ -0,0 +1,113 @@
+package strategy
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Reasons a batching window is ready to be emitted
+const (
+	ReasonWindowElapsed   = "window elapsed"
+	ReasonRouteThreshold  = "route threshold reached"
+	ReasonAmountThreshold = "amount threshold reached"
+	ReasonUnbatched       = "batching disabled"
+)
+
+// Window accumulates routes discovered across polls until the batching window has elapsed or a
+// threshold is reached, so they are generated as a single transaction. Routes are identified by
+// their deposit tx hash, so rediscovering a pending route does not add it twice.
+type Window struct {
+	duration  time.Duration
+	maxRoutes int
+	maxAmount math.Int
+
+	routes  *types.Routes
+	pending map[string]bool
+	total   math.Int
+	opened  time.Time
+}
+
+// NewWindow creates an empty window for the multisig's routes. With batching disabled, a window
+// is ready as soon as it holds a route.
+func NewWindow(config types.BatchingConfig, multisig string) (*Window, error) {
+	duration, err := config.Duration()
+	if err != nil {
+		return nil, err
+	}
+	maxAmount, err := config.AmountThreshold()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Window{duration: duration, maxRoutes: config.MaxRoutes, maxAmount: maxAmount}
+	w.reset(multisig)
+	return w, nil
+}
+
+// Add adds newly discovered routes to the window. The window opens with its first route.
+func (w *Window) Add(routes []types.HyperlaneRoute, now time.Time) error {
+	for _, route := range routes {
+		if w.pending[route.TransferKey()] {
+			continue
+		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			return fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+		}
+		if len(w.routes.Routes) == 0 {
+			w.opened = now
+		}
+		w.routes.Routes = append(w.routes.Routes, route)
+		w.pending[route.TransferKey()] = true
+		w.total = w.total.Add(amount)
+	}
+	return nil
+}
+
+// Pending returns the number of routes in the window
+func (w *Window) Pending() int {
+	return len(w.routes.Routes)
+}
+
+// Deadline returns when the window elapses; it is the zero time while the window is empty
+func (w *Window) Deadline() time.Time {
+	if w.Pending() == 0 {
+		return time.Time{}
+	}
+	return w.opened.Add(w.duration)
+}
+
+// Ready reports whether the pending routes should be generated now, and why
+func (w *Window) Ready(now time.Time) (bool, string) {
+	switch {
+	case w.Pending() == 0:
+		return false, ""
+	case w.duration == 0:
+		return true, ReasonUnbatched
+	case w.maxRoutes > 0 && w.Pending() >= w.maxRoutes:
+		return true, ReasonRouteThreshold
+	case !w.maxAmount.IsNil() && w.total.GTE(w.maxAmount):
+		return true, ReasonAmountThreshold
+	case !now.Before(w.Deadline()):
+		return true, ReasonWindowElapsed
+	}
+	return false, ""
+}
+
+// Flush returns the pending routes with their total and empties the window
+func (w *Window) Flush() *types.Routes {
+	routes := w.routes
+	routes.TotalAmount = w.total.String()
+	w.reset(routes.MultisigAddr)
+	return routes
+}
+
+func (w *Window) reset(multisig string) {
+	w.routes = &types.Routes{MultisigAddr: multisig}
+	w.pending = make(map[string]bool)
+	w.total = math.ZeroInt()
+	w.opened = time.Time{}
+}
@@ -0,0 +1,74 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestWindow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	route := func(hash, amount string) types.HyperlaneRoute {
+		return types.HyperlaneRoute{TxHash: hash, Amount: amount}
+	}
+
+	tests := []struct {
+		name       string
+		config     types.BatchingConfig
+		routes     []types.HyperlaneRoute
+		at         time.Duration
+		wantReady  bool
+		wantReason string
+	}{
+		{"disabled", types.BatchingConfig{}, []types.HyperlaneRoute{route("A", "10")}, 0, true, ReasonUnbatched},
+		{"within window", types.BatchingConfig{Window: "1h"}, []types.HyperlaneRoute{route("A", "10")}, 59 * time.Minute, false, ""},
+		{"window elapsed", types.BatchingConfig{Window: "1h"}, []types.HyperlaneRoute{route("A", "10")}, time.Hour, true, ReasonWindowElapsed},
+		{"route threshold", types.BatchingConfig{Window: "1h", MaxRoutes: 2}, []types.HyperlaneRoute{route("A", "10"), route("B", "10")}, 0, true, ReasonRouteThreshold},
+		{"duplicate route", types.BatchingConfig{Window: "1h", MaxRoutes: 2}, []types.HyperlaneRoute{route("A", "10"), route("A", "10")}, 0, false, ""},
+		{"amount threshold", types.BatchingConfig{Window: "1h", MaxAmount: "100"}, []types.HyperlaneRoute{route("A", "60"), route("B", "40")}, 0, true, ReasonAmountThreshold},
+		{"empty", types.BatchingConfig{Window: "1h"}, nil, 2 * time.Hour, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := NewWindow(tt.config, "celestia1multisig")
+			if err != nil {
+				t.Fatalf("NewWindow() error = %v", err)
+			}
+			if err := w.Add(tt.routes, start); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			ready, reason := w.Ready(start.Add(tt.at))
+			if ready != tt.wantReady || reason != tt.wantReason {
+				t.Errorf("Ready() = %v, %q, want %v, %q", ready, reason, tt.wantReady, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestWindowFlush(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	w, err := NewWindow(types.BatchingConfig{Window: "1h"}, "celestia1multisig")
+	if err != nil {
+		t.Fatalf("NewWindow() error = %v", err)
+	}
+
+	// The window opens with its first route, not when later routes arrive
+	w.Add([]types.HyperlaneRoute{{TxHash: "A", Amount: "10"}}, start)
+	w.Add([]types.HyperlaneRoute{{TxHash: "B", Amount: "5"}}, start.Add(30*time.Minute))
+	if want := start.Add(time.Hour); !w.Deadline().Equal(want) {
+		t.Errorf("Deadline() = %v, want %v", w.Deadline(), want)
+	}
+
+	routes := w.Flush()
+	if len(routes.Routes) != 2 || routes.TotalAmount != "15" || routes.MultisigAddr != "celestia1multisig" {
+		t.Errorf("Flush() = %+v", routes)
+	}
+	if w.Pending() != 0 || !w.Deadline().IsZero() {
+		t.Errorf("window not emptied: %d pending", w.Pending())
+	}
+
+	if err := w.Add([]types.HyperlaneRoute{{TxHash: "C", Amount: "x"}}, start); err == nil {
+		t.Error("Add() expected error for an invalid amount")
+	}
+}
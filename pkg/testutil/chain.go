@@ -0,0 +1,256 @@
+// Package testutil provides a fake chain node serving the gRPC queries the rebalancer makes, so
+// tests, local soak runs and rehearsals can exercise parse, watch, backfill and broadcast without a
+// live network.
+package testutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxPageSize caps the transactions per GetTxsEvent page, as CometBFT does
+const MaxPageSize = 100
+
+// Block is a block of the fake chain
+type Block struct {
+	Height int64
+	Time   time.Time
+	Hash   []byte
+	Txs    []*sdk.TxResponse
+}
+
+// Chain is an in-memory chain whose blocks are added by the test. Its gRPC services answer the
+// transaction and block queries of the rebalancer's client. It is safe for concurrent use.
+type Chain struct {
+	mu        sync.Mutex
+	genesis   time.Time
+	blockTime time.Duration
+	blocks    []*Block
+	txs       map[string]*sdk.TxResponse
+
+	rng            *rand.Rand
+	queryErrorRate float64
+}
+
+// NewChain creates an empty chain whose first block is produced at genesis, with blocks
+// blockTime apart
+func NewChain(genesis time.Time, blockTime time.Duration) *Chain {
+	return &Chain{
+		genesis:   genesis,
+		blockTime: blockTime,
+		txs:       make(map[string]*sdk.TxResponse),
+		rng:       rand.New(rand.NewSource(1)),
+	}
+}
+
+// SetQueryErrorRate makes that fraction of transaction queries fail as if the node were
+// unavailable, so retries and failed heights can be exercised
+func (c *Chain) SetQueryErrorRate(rate float64, seed int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queryErrorRate = rate
+	c.rng = rand.New(rand.NewSource(seed))
+}
+
+// AddBlock includes txs, all successful, in a new block and returns its height
+func (c *Chain) AddBlock(txs ...*tx.Tx) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	block, err := c.addBlock(txs)
+	if err != nil {
+		return 0, err
+	}
+	return block.Height, nil
+}
+
+// addBlock includes txs in a new block; the caller holds c.mu
+func (c *Chain) addBlock(txs []*tx.Tx) (*Block, error) {
+	height := int64(len(c.blocks)) + 1
+	block := &Block{Height: height, Time: c.genesis.Add(time.Duration(height-1) * c.blockTime)}
+	blockHash := sha256.New()
+	for _, t := range txs {
+		data, err := t.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tx: %w", err)
+		}
+		hash := sha256.Sum256(data)
+		blockHash.Write(hash[:])
+		resp := &sdk.TxResponse{
+			Height:    height,
+			TxHash:    strings.ToUpper(hex.EncodeToString(hash[:])),
+			Tx:        &codectypes.Any{TypeUrl: "/cosmos.tx.v1beta1.Tx", Value: data},
+			Timestamp: block.Time.Format(time.RFC3339),
+		}
+		block.Txs = append(block.Txs, resp)
+		c.txs[resp.TxHash] = resp
+	}
+	block.Hash = blockHash.Sum(nil)
+	c.blocks = append(c.blocks, block)
+	return block, nil
+}
+
+// Height returns the height of the latest block, or 0 before the first
+func (c *Chain) Height() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int64(len(c.blocks))
+}
+
+// Register registers the chain's transaction and block services on server
+func (c *Chain) Register(server *grpc.Server) {
+	tx.RegisterServiceServer(server, &txService{chain: c})
+	cmtservice.RegisterServiceServer(server, &cmtService{chain: c})
+}
+
+// Serve serves the chain's gRPC services on listener until ctx is done
+func (c *Chain) Serve(ctx context.Context, listener net.Listener) error {
+	server := grpc.NewServer()
+	c.Register(server)
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	return server.Serve(listener)
+}
+
+// block returns the block at height, or nil if it was not produced yet
+func (c *Chain) block(height int64) *Block {
+	if height < 1 || height > int64(len(c.blocks)) {
+		return nil
+	}
+	return c.blocks[height-1]
+}
+
+// failQuery reports whether to fail a transaction query
+func (c *Chain) failQuery() bool {
+	return c.queryErrorRate > 0 && c.rng.Float64() < c.queryErrorRate
+}
+
+// txService implements the transaction queries of the tx service
+type txService struct {
+	tx.UnimplementedServiceServer
+	chain *Chain
+}
+
+// GetTxsEvent serves "tx.height=<height>" queries, one page at a time
+func (s *txService) GetTxsEvent(ctx context.Context, req *tx.GetTxsEventRequest) (*tx.GetTxsEventResponse, error) {
+	height, err := strconv.ParseInt(strings.TrimPrefix(req.Query, "tx.height="), 10, 64)
+	if err != nil || !strings.HasPrefix(req.Query, "tx.height=") {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported query %q", req.Query)
+	}
+
+	c := s.chain
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failQuery() {
+		return nil, status.Error(codes.Unavailable, "injected query failure")
+	}
+
+	resp := &tx.GetTxsEventResponse{}
+	block := c.block(height)
+	if block == nil {
+		return resp, nil
+	}
+	limit := req.Limit
+	if limit == 0 || limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	page := max(req.Page, 1)
+	start := min((page-1)*limit, uint64(len(block.Txs)))
+	end := min(start+limit, uint64(len(block.Txs)))
+	resp.TxResponses = block.Txs[start:end]
+	resp.Total = uint64(len(block.Txs))
+	return resp, nil
+}
+
+// BroadcastTx includes a transaction in a new block of its own right away. Signatures are not
+// verified, so a transaction signed with placeholders is accepted.
+func (s *txService) BroadcastTx(ctx context.Context, req *tx.BroadcastTxRequest) (*tx.BroadcastTxResponse, error) {
+	var raw tx.TxRaw
+	if err := raw.Unmarshal(req.TxBytes); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction: %v", err)
+	}
+	t := &tx.Tx{Body: &tx.TxBody{}, AuthInfo: &tx.AuthInfo{}, Signatures: raw.Signatures}
+	if err := t.Body.Unmarshal(raw.BodyBytes); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction body: %v", err)
+	}
+	if err := t.AuthInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction auth info: %v", err)
+	}
+
+	c := s.chain
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	block, err := c.addBlock([]*tx.Tx{t})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &tx.BroadcastTxResponse{TxResponse: &sdk.TxResponse{TxHash: block.Txs[0].TxHash}}, nil
+}
+
+// GetTx serves an included transaction by hash
+func (s *txService) GetTx(ctx context.Context, req *tx.GetTxRequest) (*tx.GetTxResponse, error) {
+	c := s.chain
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, ok := c.txs[strings.ToUpper(req.Hash)]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tx not found: %s", req.Hash)
+	}
+	return &tx.GetTxResponse{TxResponse: resp}, nil
+}
+
+// cmtService implements the block queries of the CometBFT service
+type cmtService struct {
+	cmtservice.UnimplementedServiceServer
+	chain *Chain
+}
+
+// GetLatestBlock serves the header of the latest block
+func (s *cmtService) GetLatestBlock(ctx context.Context, req *cmtservice.GetLatestBlockRequest) (*cmtservice.GetLatestBlockResponse, error) {
+	c := s.chain
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	block := c.block(int64(len(c.blocks)))
+	if block == nil {
+		return nil, status.Error(codes.Unavailable, "no block produced yet")
+	}
+	return &cmtservice.GetLatestBlockResponse{BlockId: block.id(), SdkBlock: block.sdkBlock()}, nil
+}
+
+// GetBlockByHeight serves the header of a block
+func (s *cmtService) GetBlockByHeight(ctx context.Context, req *cmtservice.GetBlockByHeightRequest) (*cmtservice.GetBlockByHeightResponse, error) {
+	c := s.chain
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	block := c.block(req.Height)
+	if block == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "height %d is not available", req.Height)
+	}
+	return &cmtservice.GetBlockByHeightResponse{BlockId: block.id(), SdkBlock: block.sdkBlock()}, nil
+}
+
+func (b *Block) id() *cmtproto.BlockID {
+	return &cmtproto.BlockID{Hash: b.Hash}
+}
+
+func (b *Block) sdkBlock() *cmtservice.Block {
+	return &cmtservice.Block{Header: cmtservice.Header{ChainID: "fake", Height: b.Height, Time: b.Time}}
+}
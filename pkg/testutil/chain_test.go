@@ -0,0 +1,171 @@
+package testutil
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/parser"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+const multisig = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+
+// serve serves chain on a local port until the test ends and returns its address
+func serve(t *testing.T, chain *Chain) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go chain.Serve(ctx, listener)
+	return listener.Addr().String()
+}
+
+func TestChainServesDeposits(t *testing.T) {
+	route := client.RoutingMetadata{
+		DestinationDomain: 1380012617,
+		Recipient:         "0x000000000000000000000000742d35cc6634c0532925a3b844bc9e7595f0beb0",
+		TokenID:           "0x726f757465725f61707000000000000000000000000000010000000000000000",
+	}
+	gen, err := NewDepositGenerator(DepositConfig{
+		Sender:    "celestia1depositor",
+		Multisig:  multisig,
+		Denom:     "utia",
+		Routes:    []client.RoutingMetadata{route},
+		PerBlock:  3,
+		MinAmount: 1000,
+		MaxAmount: 5000,
+	}, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := NewChain(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), 6*time.Second)
+	for i := 0; i < 2; i++ {
+		txs, err := gen.Block()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := chain.AddBlock(txs...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A block spanning two pages
+	gen.config.PerBlock = MaxPageSize + 50
+	txs, err := gen.Block()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.AddBlock(txs...); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := parser.NewParser(serve(t, chain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	latest, err := p.LatestHeight()
+	if err != nil || latest != 3 {
+		t.Fatalf("LatestHeight() = %d, %v, want 3", latest, err)
+	}
+	result, err := p.ParseRoutes(multisig, 1, latest)
+	if err != nil {
+		t.Fatalf("ParseRoutes() error = %v", err)
+	}
+	stats := gen.Stats()
+	if len(result.Routes.Routes) != stats.Deposits || stats.Deposits != 3+3+MaxPageSize+50 {
+		t.Errorf("parsed %d routes, want all %d deposits", len(result.Routes.Routes), stats.Deposits)
+	}
+	if result.Routes.TotalAmount != stats.Amount.String() {
+		t.Errorf("total = %s, want %s", result.Routes.TotalAmount, stats.Amount)
+	}
+	if len(result.Skipped) != 0 || len(result.FailedHeights) != 0 {
+		t.Errorf("skipped %+v, failed heights %+v", result.Skipped, result.FailedHeights)
+	}
+}
+
+func TestChainBroadcastTx(t *testing.T) {
+	chain := NewChain(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), 6*time.Second)
+	if _, err := chain.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	route := client.RoutingMetadata{DestinationDomain: 1, Recipient: "0xaa"}
+	signed, err := BankDeposit("celestia1depositor", multisig, sdk.NewCoin("utia", math.NewInt(1000)), route, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed.AuthInfo.SignerInfos = []*tx.SignerInfo{{}}
+	txBytes, err := generator.EncodeSignedTx(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := client.NewClient(context.Background(), serve(t, chain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	resp, err := c.BroadcastTx(txBytes)
+	if err != nil {
+		t.Fatalf("BroadcastTx() error = %v", err)
+	}
+	included, err := c.WaitForTx(resp.TxHash, time.Second, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForTx() error = %v", err)
+	}
+	if included.Height != 2 || chain.Height() != 2 {
+		t.Errorf("included at height %d of %d, want a new block 2", included.Height, chain.Height())
+	}
+	if err := client.TxFailed(included); err != nil {
+		t.Errorf("TxFailed() = %v", err)
+	}
+
+	if _, err := c.BroadcastTx([]byte{0xff}); err == nil {
+		t.Error("BroadcastTx() accepted undecodable bytes")
+	}
+}
+
+func TestDepositGenerator(t *testing.T) {
+	config := DepositConfig{
+		Multisig:      multisig,
+		Denom:         "utia",
+		Routes:        []client.RoutingMetadata{{DestinationDomain: 1, Recipient: "0xaa"}},
+		PerBlock:      2.5,
+		MinAmount:     1,
+		MaxAmount:     10,
+		DuplicateRate: 0.2,
+		RejectRate:    0.1,
+	}
+	gen, err := NewDepositGenerator(config, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 400; i++ {
+		if _, err := gen.Block(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := gen.Stats()
+	if stats.Deposits < 900 || stats.Deposits > 1100 {
+		t.Errorf("%d deposits in 400 blocks, want about 1000", stats.Deposits)
+	}
+	if stats.Duplicates == 0 || stats.Rejected == 0 {
+		t.Errorf("stats = %+v, want duplicates and rejected deposits", stats)
+	}
+
+	config.MaxAmount = 0
+	if _, err := NewDepositGenerator(config, 7); err == nil {
+		t.Error("NewDepositGenerator() accepted an empty amount range")
+	}
+}
@@ -0,0 +1,157 @@
+package testutil
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// BankDeposit builds a bank send of coin from sender to multisig with routing metadata in its
+// memo, the way depositors route funds through the multisig. nonce makes otherwise identical
+// deposits distinct transactions.
+func BankDeposit(sender, multisig string, coin sdk.Coin, route client.RoutingMetadata, nonce uint64) (*tx.Tx, error) {
+	msg, err := codectypes.NewAnyWithValue(&banktypes.MsgSend{
+		FromAddress: sender,
+		ToAddress:   multisig,
+		Amount:      sdk.NewCoins(coin),
+	})
+	if err != nil {
+		return nil, err
+	}
+	memo, err := json.Marshal(route)
+	if err != nil {
+		return nil, err
+	}
+	// Stands in for the depositor's signature, which differs for every transaction
+	signature := binary.BigEndian.AppendUint64(nil, nonce)
+	return &tx.Tx{
+		Body:       &tx.TxBody{Messages: []*codectypes.Any{msg}, Memo: string(memo)},
+		AuthInfo:   &tx.AuthInfo{Fee: &tx.Fee{}},
+		Signatures: [][]byte{signature},
+	}, nil
+}
+
+// DepositConfig describes the synthetic deposits of a DepositGenerator
+type DepositConfig struct {
+	Sender   string
+	Multisig string
+	Denom    string
+	// Routes are the destinations deposits are routed to, picked at random
+	Routes []client.RoutingMetadata
+	// PerBlock is the average number of deposits per block; fractions are spread over blocks
+	PerBlock             float64
+	MinAmount, MaxAmount int64
+	// DuplicateRate is the fraction of deposits that repeat the route and amount of an earlier
+	// deposit, like an accidental double-send
+	DuplicateRate float64
+	// RejectRate is the fraction of deposits routed to a recipient no whitelist allows
+	RejectRate float64
+}
+
+// DepositStats counts the deposits a DepositGenerator produced
+type DepositStats struct {
+	Deposits   int
+	Duplicates int
+	Rejected   int // Routed to a recipient that is not whitelisted
+	Amount     math.Int
+}
+
+// DepositGenerator produces blocks of synthetic deposits to the multisig
+type DepositGenerator struct {
+	config DepositConfig
+	rng    *rand.Rand
+	nonce  uint64
+	recent []deposit
+	stats  DepositStats
+}
+
+type deposit struct {
+	route  client.RoutingMetadata
+	amount int64
+}
+
+// recentDeposits bounds the earlier deposits duplicates are drawn from
+const recentDeposits = 100
+
+// unlistedRecipient is the recipient of deposits meant to be rejected
+const unlistedRecipient = "0x00000000000000000000000000000000000000000000000000000000deadbeef"
+
+// NewDepositGenerator creates a generator of the deposits described by config, seeded with seed
+// so runs can be reproduced
+func NewDepositGenerator(config DepositConfig, seed int64) (*DepositGenerator, error) {
+	switch {
+	case config.Multisig == "":
+		return nil, fmt.Errorf("multisig address is not set")
+	case len(config.Routes) == 0:
+		return nil, fmt.Errorf("no routes to send deposits to")
+	case config.PerBlock < 0:
+		return nil, fmt.Errorf("deposits per block must not be negative")
+	case config.MinAmount <= 0 || config.MaxAmount < config.MinAmount:
+		return nil, fmt.Errorf("invalid amount range %d to %d", config.MinAmount, config.MaxAmount)
+	case config.DuplicateRate < 0 || config.DuplicateRate > 1 || config.RejectRate < 0 || config.RejectRate > 1:
+		return nil, fmt.Errorf("rates must be between 0 and 1")
+	}
+	return &DepositGenerator{
+		config: config,
+		rng:    rand.New(rand.NewSource(seed)),
+		stats:  DepositStats{Amount: math.ZeroInt()},
+	}, nil
+}
+
+// Block returns the deposits of the next block
+func (g *DepositGenerator) Block() ([]*tx.Tx, error) {
+	count := int(g.config.PerBlock)
+	if g.rng.Float64() < g.config.PerBlock-float64(count) {
+		count++
+	}
+
+	txs := make([]*tx.Tx, 0, count)
+	for i := 0; i < count; i++ {
+		d := g.next()
+		g.nonce++
+		t, err := BankDeposit(g.config.Sender, g.config.Multisig, sdk.NewInt64Coin(g.config.Denom, d.amount), d.route, g.nonce)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, t)
+		g.stats.Deposits++
+		g.stats.Amount = g.stats.Amount.Add(math.NewInt(d.amount))
+	}
+	return txs, nil
+}
+
+// next picks the route and amount of the next deposit
+func (g *DepositGenerator) next() deposit {
+	if len(g.recent) > 0 && g.rng.Float64() < g.config.DuplicateRate {
+		g.stats.Duplicates++
+		return g.recent[g.rng.Intn(len(g.recent))]
+	}
+
+	d := deposit{
+		route:  g.config.Routes[g.rng.Intn(len(g.config.Routes))],
+		amount: g.config.MinAmount + g.rng.Int63n(g.config.MaxAmount-g.config.MinAmount+1),
+	}
+	if g.rng.Float64() < g.config.RejectRate {
+		d.route.Recipient = unlistedRecipient
+		g.stats.Rejected++
+		return d
+	}
+	if len(g.recent) == recentDeposits {
+		g.recent = g.recent[1:]
+	}
+	g.recent = append(g.recent, d)
+	return d
+}
+
+// Stats returns the deposits produced so far
+func (g *DepositGenerator) Stats() DepositStats {
+	return g.stats
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+// Hyperlane carries recipients and token IDs as 32-byte words. The client, generator, verifier and
+// whitelist all go through the functions below so that they agree on what an address means.
+
+// RecipientBytes decodes a transfer recipient: a 0x-prefixed hex address of at most 32 bytes, e.g.
+// a 20-byte EVM address, or a bech32 address of at most 32 bytes with any prefix, since it lives on
+// the destination chain
+func RecipientBytes(addr string) ([]byte, error) {
+	addr = strings.TrimSpace(addr)
+	if hexPart, ok := cutHexPrefix(addr); ok {
+		decoded, err := hex.DecodeString(hexPart)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %s is not valid hex: %w", addr, err)
+		}
+		if len(decoded) == 0 || len(decoded) > 32 {
+			return nil, fmt.Errorf("recipient %s must be 1 to 32 bytes, got %d", addr, len(decoded))
+		}
+		return decoded, nil
+	}
+
+	_, decoded, err := bech32.DecodeAndConvert(addr)
+	if err != nil {
+		return nil, fmt.Errorf("recipient %s is neither 0x-prefixed hex nor bech32: %w", addr, err)
+	}
+	if len(decoded) == 0 || len(decoded) > 32 {
+		return nil, fmt.Errorf("recipient %s must be 1 to 32 bytes, got %d", addr, len(decoded))
+	}
+	return decoded, nil
+}
+
+// PadRecipient returns the 32-byte form of a recipient, left-padded with zeros
+func PadRecipient(addr string) ([32]byte, error) {
+	var padded [32]byte
+	decoded, err := RecipientBytes(addr)
+	if err != nil {
+		return padded, err
+	}
+	copy(padded[32-len(decoded):], decoded)
+	return padded, nil
+}
+
+// NormalizeRecipient returns a recipient as 0x-prefixed lowercase 32-byte hex, the form a
+// MsgRemoteTransfer carries it in
+func NormalizeRecipient(addr string) (string, error) {
+	padded, err := PadRecipient(addr)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(padded[:]), nil
+}
+
+// TokenIDBytes decodes a 32-byte hex token ID; the 0x prefix is optional
+func TokenIDBytes(tokenID string) ([32]byte, error) {
+	var id [32]byte
+	trimmed := strings.TrimSpace(tokenID)
+	if hexPart, ok := cutHexPrefix(trimmed); ok {
+		trimmed = hexPart
+	}
+	decoded, err := hex.DecodeString(trimmed)
+	if err != nil {
+		return id, fmt.Errorf("token ID %s is not valid hex: %w", tokenID, err)
+	}
+	if len(decoded) != 32 {
+		return id, fmt.Errorf("token ID %s must be exactly 32 bytes, got %d", tokenID, len(decoded))
+	}
+	copy(id[:], decoded)
+	return id, nil
+}
+
+// NormalizeTokenID returns a token ID as 0x-prefixed lowercase hex
+func NormalizeTokenID(tokenID string) (string, error) {
+	id, err := TokenIDBytes(tokenID)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(id[:]), nil
+}
+
+// NormalizeAddress returns the form addresses are compared in: the normalized recipient for
+// anything that decodes as one, so that e.g. a 20-byte EVM address and its zero-padded 32-byte form
+// compare equal, and the trimmed, lowercased address otherwise
+func NormalizeAddress(addr string) string {
+	if normalized, err := NormalizeRecipient(addr); err == nil {
+		return normalized
+	}
+	return strings.ToLower(strings.TrimSpace(addr))
+}
+
+// cutHexPrefix returns s without a 0x or 0X prefix, and whether it had one
+func cutHexPrefix(s string) (string, bool) {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:], true
+	}
+	return s, false
+}
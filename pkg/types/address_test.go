@@ -0,0 +1,125 @@
+package types
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRecipient(t *testing.T) {
+	const (
+		evm        = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+		evmPadded  = "0x000000000000000000000000742d35cc6634c0532925a3b844bc9e7595f0beb0"
+		ones       = "0x0000000000000000000000001111111111111111111111111111111111111111"
+		full       = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+		celestia   = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+		neutron    = "neutron1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg39z7qgg"
+		badChecked = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjga"
+	)
+
+	tests := []struct {
+		name    string
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{name: "evm address", addr: evm, want: evmPadded},
+		{name: "evm address lowercase", addr: strings.ToLower(evm), want: evmPadded},
+		{name: "evm address uppercase prefix", addr: "0X" + evm[2:], want: evmPadded},
+		{name: "already padded", addr: evmPadded, want: evmPadded},
+		{name: "padded uppercase", addr: "0x" + strings.ToUpper(evmPadded[2:]), want: evmPadded},
+		{name: "full 32 bytes", addr: full, want: full},
+		{name: "surrounding whitespace", addr: " " + evm + "\n", want: evmPadded},
+		{name: "short hex", addr: "0x1234", want: "0x" + strings.Repeat("0", 60) + "1234"},
+		{name: "bech32", addr: celestia, want: ones},
+		{name: "bech32 with another prefix", addr: neutron, want: ones},
+		{name: "empty", addr: "", wantErr: true},
+		{name: "bare prefix", addr: "0x", wantErr: true},
+		{name: "odd hex", addr: "0x123", wantErr: true},
+		{name: "invalid hex", addr: "0xZZZZ", wantErr: true},
+		{name: "longer than 32 bytes", addr: full + "00", wantErr: true},
+		{name: "hex without prefix", addr: evm[2:], wantErr: true},
+		{name: "bad bech32 checksum", addr: badChecked, wantErr: true},
+		{name: "not an address", addr: "vault", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRecipient(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeRecipient(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeRecipient(%q) = %s, want %s", tt.addr, got, tt.want)
+			}
+
+			padded, err := PadRecipient(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PadRecipient(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+			if err == nil && "0x"+hex.EncodeToString(padded[:]) != tt.want {
+				t.Errorf("PadRecipient(%q) = %x, want %s", tt.addr, padded, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTokenID(t *testing.T) {
+	const id = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	tests := []struct {
+		name    string
+		tokenID string
+		wantErr bool
+	}{
+		{name: "prefixed", tokenID: id},
+		{name: "unprefixed", tokenID: id[2:]},
+		{name: "uppercase", tokenID: "0X" + strings.ToUpper(id[2:])},
+		{name: "whitespace", tokenID: " " + id + " "},
+		{name: "empty", tokenID: "", wantErr: true},
+		{name: "short", tokenID: "0x1234", wantErr: true},
+		{name: "20 bytes", tokenID: "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", wantErr: true},
+		{name: "33 bytes", tokenID: id + "00", wantErr: true},
+		{name: "invalid hex", tokenID: "0x" + strings.Repeat("zz", 32), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeTokenID(tt.tokenID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeTokenID(%q) error = %v, wantErr %v", tt.tokenID, err, tt.wantErr)
+			}
+			if err == nil && got != id {
+				t.Errorf("NormalizeTokenID(%q) = %s, want %s", tt.tokenID, got, id)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	// Every spelling of the same recipient compares equal
+	same := []string{
+		"0x1111111111111111111111111111111111111111",
+		"0x0000000000000000000000001111111111111111111111111111111111111111",
+		"celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz",
+		"neutron1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg39z7qgg",
+	}
+	for _, addr := range same[1:] {
+		if NormalizeAddress(addr) != NormalizeAddress(same[0]) {
+			t.Errorf("NormalizeAddress(%s) = %s, want %s", addr, NormalizeAddress(addr), NormalizeAddress(same[0]))
+		}
+	}
+
+	// Anything else is only trimmed and lowercased
+	if got := NormalizeAddress(" Vault "); got != "vault" {
+		t.Errorf("NormalizeAddress() = %q, want %q", got, "vault")
+	}
+
+	// The whitelist accepts a recipient in any of its spellings
+	config := &Config{Whitelist: AddressWhitelist{Domains: map[uint32][]string{1: {same[0]}}}}
+	for _, addr := range same {
+		if err := config.ValidateRoute(&RouteInfo{DestinationDomain: 1, Recipient: addr}); err != nil {
+			t.Errorf("ValidateRoute(%s) error = %v", addr, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// MsgRemoteTransferTypeURL is the type URL of MsgRemoteTransfer, the message type the multisig
+// grants to the operational grantee. Grants must be scoped to it, e.g. a GenericAuthorization for this type URL.
+const MsgRemoteTransferTypeURL = "/hyperlane.warp.v1.MsgRemoteTransfer"
+
+// AuthzConfig describes the authz grant from the multisig to an operational grantee account.
+// When a grantee is set, generated transfers are wrapped in a MsgExec that only the grantee
+// signs, instead of requiring a multisig signing round for every run.
+type AuthzConfig struct {
+	Grantee    string `json:"grantee,omitempty"`
+	Expiration string `json:"expiration,omitempty"` // Optional RFC 3339 expiry of the grant, e.g. "2026-12-31T00:00:00Z"
+}
+
+// Enabled reports whether transfers are executed through an authz grant
+func (a AuthzConfig) Enabled() bool {
+	return a.Grantee != ""
+}
+
+// ExpiresAt returns the grant expiry; the zero time means the grant does not expire
+func (a AuthzConfig) ExpiresAt() (time.Time, error) {
+	if a.Expiration == "" {
+		return time.Time{}, nil
+	}
+	expiration, err := time.Parse(time.RFC3339, a.Expiration)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiration %q: %w", a.Expiration, err)
+	}
+	return expiration, nil
+}
+
+// Validate checks that the authz settings are well-formed
+func (a AuthzConfig) Validate() error {
+	if a.Expiration != "" && a.Grantee == "" {
+		return fmt.Errorf("expiration is set but grantee is empty")
+	}
+	_, err := a.ExpiresAt()
+	return err
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuthzConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		authz   AuthzConfig
+		wantErr bool
+	}{
+		{"disabled", AuthzConfig{}, false},
+		{"grantee only", AuthzConfig{Grantee: "celestia1grantee"}, false},
+		{"with expiration", AuthzConfig{Grantee: "celestia1grantee", Expiration: "2026-12-31T00:00:00Z"}, false},
+		{"invalid expiration", AuthzConfig{Grantee: "celestia1grantee", Expiration: "next week"}, true},
+		{"expiration without grantee", AuthzConfig{Expiration: "2026-12-31T00:00:00Z"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.authz.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthzConfigExpiresAt(t *testing.T) {
+	expiration, err := AuthzConfig{}.ExpiresAt()
+	if err != nil || !expiration.IsZero() {
+		t.Errorf("ExpiresAt() = %v, %v, want zero time", expiration, err)
+	}
+
+	expiration, err = AuthzConfig{Grantee: "celestia1grantee", Expiration: "2026-12-31T00:00:00Z"}.ExpiresAt()
+	if err != nil {
+		t.Fatalf("ExpiresAt() error = %v", err)
+	}
+	if want := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC); !expiration.Equal(want) {
+		t.Errorf("ExpiresAt() = %v, want %v", expiration, want)
+	}
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"cosmossdk.io/math"
+)
+
+// BatchingConfig controls how a long-running rebalancer accumulates discovered routes before
+// generating a transaction, trading latency for fewer transactions and fees. A batch is emitted
+// when the window has elapsed since its first route, or earlier once a threshold is reached.
+type BatchingConfig struct {
+	Window    string `json:"window,omitempty"`     // Enables batching, e.g. "1h"
+	MaxRoutes int    `json:"max_routes,omitempty"` // Emit early once this many routes are pending
+	MaxAmount string `json:"max_amount,omitempty"` // Emit early once the pending routes total at least this amount
+}
+
+// Enabled reports whether routes are accumulated before generation
+func (b BatchingConfig) Enabled() bool {
+	return b.Window != ""
+}
+
+// Duration returns the parsed batching window, or zero if batching is disabled
+func (b BatchingConfig) Duration() (time.Duration, error) {
+	if b.Window == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(b.Window)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid window %q: must be a positive duration", b.Window)
+	}
+	return window, nil
+}
+
+// AmountThreshold returns the parsed amount threshold; it is nil if unset
+func (b BatchingConfig) AmountThreshold() (math.Int, error) {
+	if b.MaxAmount == "" {
+		return math.Int{}, nil
+	}
+	amount, ok := math.NewIntFromString(b.MaxAmount)
+	if !ok || !amount.IsPositive() {
+		return math.Int{}, fmt.Errorf("invalid max_amount %q", b.MaxAmount)
+	}
+	return amount, nil
+}
+
+// Validate checks the batching settings
+func (b BatchingConfig) Validate() error {
+	if !b.Enabled() && (b.MaxRoutes != 0 || b.MaxAmount != "") {
+		return fmt.Errorf("max_routes and max_amount require a window")
+	}
+	if b.MaxRoutes < 0 {
+		return fmt.Errorf("max_routes must not be negative")
+	}
+	if _, err := b.Duration(); err != nil {
+		return err
+	}
+	_, err := b.AmountThreshold()
+	return err
+}
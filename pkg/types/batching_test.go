@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchingConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  BatchingConfig
+		wantErr bool
+	}{
+		{"disabled", BatchingConfig{}, false},
+		{"window only", BatchingConfig{Window: "1h"}, false},
+		{"thresholds", BatchingConfig{Window: "1h", MaxRoutes: 20, MaxAmount: "1000000"}, false},
+		{"thresholds without window", BatchingConfig{MaxRoutes: 20}, true},
+		{"invalid window", BatchingConfig{Window: "soon"}, true},
+		{"zero window", BatchingConfig{Window: "0s"}, true},
+		{"negative max_routes", BatchingConfig{Window: "1h", MaxRoutes: -1}, true},
+		{"invalid max_amount", BatchingConfig{Window: "1h", MaxAmount: "0"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	window, err := BatchingConfig{Window: "90m"}.Duration()
+	if err != nil || window != 90*time.Minute {
+		t.Errorf("Duration() = %v, %v", window, err)
+	}
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+// Defaults for Celestia, used when the config does not specify chain parameters
+const (
+	DefaultBech32Prefix = "celestia"
+	DefaultDenom        = "utia"
+	DefaultGasPrice     = "0.002utia"
+)
+
+// ChainConfig holds the parameters of the hyperlane-cosmos chain the multisig lives on,
+// so the tool can be used on chains other than Celestia without code changes
+type ChainConfig struct {
+	Bech32Prefix string `json:"bech32_prefix,omitempty"` // Account address prefix, e.g. "celestia" or "neutron"
+	Denom        string `json:"denom,omitempty"`         // Native denom, e.g. "utia"
+	GasPrice     string `json:"gas_price,omitempty"`     // Default gas price, e.g. "0.002utia"
+	Domain       uint32 `json:"domain,omitempty"`        // Hyperlane domain ID, the origin of generated transfers
+	// AcceptedDenoms restricts the denoms of deposits turned into routes, e.g. an IBC voucher or the
+	// collateral of a warp token. Empty accepts Denom only.
+	AcceptedDenoms []string `json:"accepted_denoms,omitempty"`
+}
+
+// DefaultChainConfig returns the chain parameters for Celestia
+func DefaultChainConfig() ChainConfig {
+	return ChainConfig{
+		Bech32Prefix: DefaultBech32Prefix,
+		Denom:        DefaultDenom,
+		GasPrice:     DefaultGasPrice,
+	}
+}
+
+// WithDefaults returns a copy of the chain config with unset fields filled from DefaultChainConfig
+func (c ChainConfig) WithDefaults() ChainConfig {
+	defaults := DefaultChainConfig()
+	if c.Bech32Prefix == "" {
+		c.Bech32Prefix = defaults.Bech32Prefix
+	}
+	if c.Denom == "" {
+		c.Denom = defaults.Denom
+	}
+	if c.GasPrice == "" {
+		c.GasPrice = defaults.GasPrice
+	}
+	return c
+}
+
+// Validate checks that the accepted denoms are valid denoms
+func (c ChainConfig) Validate() error {
+	for _, denom := range c.AcceptedDenoms {
+		if err := sdk.ValidateDenom(denom); err != nil {
+			return fmt.Errorf("invalid accepted denom %q: %w", denom, err)
+		}
+	}
+	return nil
+}
+
+// AcceptsDenom reports whether deposits of denom are turned into routes: any of AcceptedDenoms if
+// set, otherwise only the native denom
+func (c ChainConfig) AcceptsDenom(denom string) bool {
+	if len(c.AcceptedDenoms) == 0 {
+		return denom == c.WithDefaults().Denom
+	}
+	return slices.Contains(c.AcceptedDenoms, denom)
+}
+
+// ValidateAddress checks that addr is a valid bech32 account address on this chain
+func (c ChainConfig) ValidateAddress(addr string) error {
+	hrp, _, err := bech32.DecodeAndConvert(addr)
+	if err != nil {
+		return fmt.Errorf("invalid bech32 address %s: %w", addr, err)
+	}
+	if hrp != c.Bech32Prefix {
+		return fmt.Errorf("address %s has prefix %s, expected %s", addr, hrp, c.Bech32Prefix)
+	}
+	return nil
+}
+
+// FeeForGas computes the fee for the given gas limit at the configured gas price, rounded up
+func (c ChainConfig) FeeForGas(gasLimit uint64) (sdk.Coins, error) {
+	if c.GasPrice == "" || gasLimit == 0 {
+		return sdk.NewCoins(), nil
+	}
+
+	price, err := sdk.ParseDecCoin(c.GasPrice)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas price %s: %w", c.GasPrice, err)
+	}
+
+	amount := price.Amount.MulInt(math.NewIntFromUint64(gasLimit)).Ceil().TruncateInt()
+	return sdk.NewCoins(sdk.NewCoin(price.Denom, amount)), nil
+}
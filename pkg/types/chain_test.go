@@ -0,0 +1,117 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestChainConfigWithDefaults(t *testing.T) {
+	chain := ChainConfig{Bech32Prefix: "neutron"}.WithDefaults()
+
+	if chain.Bech32Prefix != "neutron" {
+		t.Errorf("Bech32Prefix = %s, want neutron", chain.Bech32Prefix)
+	}
+	if chain.Denom != DefaultDenom {
+		t.Errorf("Denom = %s, want %s", chain.Denom, DefaultDenom)
+	}
+	if chain.GasPrice != DefaultGasPrice {
+		t.Errorf("GasPrice = %s, want %s", chain.GasPrice, DefaultGasPrice)
+	}
+}
+
+func TestChainConfigValidateAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		addr    string
+		wantErr bool
+	}{
+		{
+			name:   "celestia address on celestia",
+			prefix: "celestia",
+			addr:   "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz",
+		},
+		{
+			name:   "neutron address on neutron",
+			prefix: "neutron",
+			addr:   "neutron1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg39z7qgg",
+		},
+		{
+			name:    "celestia address on neutron",
+			prefix:  "neutron",
+			addr:    "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz",
+			wantErr: true,
+		},
+		{
+			name:    "invalid checksum",
+			prefix:  "celestia",
+			addr:    "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjga",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ChainConfig{Bech32Prefix: tt.prefix}.ValidateAddress(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChainConfigFeeForGas(t *testing.T) {
+	tests := []struct {
+		name     string
+		gasPrice string
+		gasLimit uint64
+		want     string
+		wantErr  bool
+	}{
+		{name: "exact", gasPrice: "0.002utia", gasLimit: 200000, want: "400utia"},
+		{name: "rounds up", gasPrice: "0.0025untrn", gasLimit: 1001, want: "3untrn"},
+		{name: "no gas limit", gasPrice: "0.002utia", gasLimit: 0, want: ""},
+		{name: "invalid gas price", gasPrice: "abc", gasLimit: 1000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, err := ChainConfig{GasPrice: tt.gasPrice}.FeeForGas(tt.gasLimit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FeeForGas() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fee.String() != tt.want {
+				t.Errorf("FeeForGas() = %s, want %s", fee.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestChainConfigAcceptsDenom(t *testing.T) {
+	voucher := "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+	tests := []struct {
+		name  string
+		chain ChainConfig
+		denom string
+		want  bool
+	}{
+		{"default native denom", ChainConfig{}, "utia", true},
+		{"configured native denom", ChainConfig{Denom: "untrn"}, "utia", false},
+		{"voucher not accepted", ChainConfig{}, voucher, false},
+		{"voucher accepted", ChainConfig{AcceptedDenoms: []string{"utia", voucher}}, voucher, true},
+		{"accepted list replaces native denom", ChainConfig{AcceptedDenoms: []string{voucher}}, "utia", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.chain.AcceptsDenom(tt.denom); got != tt.want {
+				t.Errorf("AcceptsDenom(%s) = %v, want %v", tt.denom, got, tt.want)
+			}
+		})
+	}
+
+	if err := (ChainConfig{AcceptedDenoms: []string{"utia", voucher}}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (ChainConfig{AcceptedDenoms: []string{"1tia"}}).Validate(); err == nil {
+		t.Error("Validate() accepted an invalid denom")
+	}
+}
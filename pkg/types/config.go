@@ -2,20 +2,226 @@ package types
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+
+	"cosmossdk.io/math"
 )
 
 // AddressWhitelist defines allowed recipient addresses for each Hyperlane domain
 type AddressWhitelist struct {
 	// Map of domain ID to list of whitelisted addresses
 	Domains map[uint32][]string `json:"domains"`
+	// TokenIDs lists the warp token IDs routes to each domain may use; domains without a list
+	// accept any token
+	TokenIDs map[uint32][]string `json:"token_ids,omitempty"`
+	// Caps limits the amounts routed to each domain
+	Caps map[uint32]AmountCaps `json:"caps,omitempty"`
+	// Recipients holds settings the generator applies to transfers to whitelisted recipients, by
+	// domain and recipient address
+	Recipients map[uint32]map[string]RecipientConfig `json:"recipients,omitempty"`
+}
+
+// AmountCaps limits the amounts routed to a destination domain, so that a fat-fingered metadata
+// amount cannot silently turn into a giant transfer
+type AmountCaps struct {
+	MaxAmountPerRoute    string `json:"max_amount_per_route,omitempty"`     // Largest amount of a single route
+	MaxTotalAmountPerRun string `json:"max_total_amount_per_run,omitempty"` // Largest total of the routes of one run
+}
+
+// Validate checks that the caps are positive integers
+func (a AmountCaps) Validate() error {
+	if _, err := a.PerRoute(); err != nil {
+		return err
+	}
+	if _, err := a.PerRun(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PerRoute returns the per-route cap, or a nil Int if there is none
+func (a AmountCaps) PerRoute() (math.Int, error) {
+	return parseCap("max_amount_per_route", a.MaxAmountPerRoute)
+}
+
+// PerRun returns the per-run cap, or a nil Int if there is none
+func (a AmountCaps) PerRun() (math.Int, error) {
+	return parseCap("max_total_amount_per_run", a.MaxTotalAmountPerRun)
+}
+
+func parseCap(name, value string) (math.Int, error) {
+	if value == "" {
+		return math.Int{}, nil
+	}
+	limit, ok := math.NewIntFromString(value)
+	if !ok || !limit.IsPositive() {
+		return math.Int{}, fmt.Errorf("invalid %s %s", name, value)
+	}
+	return limit, nil
+}
+
+// Validate checks the token IDs, amount caps and recipient settings of every domain
+func (w AddressWhitelist) Validate() error {
+	for domain, tokenIDs := range w.TokenIDs {
+		for _, tokenID := range tokenIDs {
+			if _, err := NormalizeTokenID(tokenID); err != nil {
+				return fmt.Errorf("token_ids of domain %d: %w", domain, err)
+			}
+		}
+	}
+	for domain, caps := range w.Caps {
+		if err := caps.Validate(); err != nil {
+			return fmt.Errorf("caps of domain %d: %w", domain, err)
+		}
+	}
+	return w.validateRecipients()
+}
+
+// FeeConfig controls the fee section of generated transactions
+type FeeConfig struct {
+	// Payer is an optional account (e.g. an automation key) that pays gas instead of the multisig.
+	// When set, the payer must sign the transaction in addition to the multisig.
+	Payer    string `json:"payer,omitempty"`
+	GasLimit uint64 `json:"gas_limit,omitempty"`
+	Amount   string `json:"amount,omitempty"` // Fee coins, e.g. "20000utia"
+}
+
+// StrategyConfig controls how parsed routes are turned into transfers
+type StrategyConfig struct {
+	// Aggregate merges routes with the same destination domain, recipient, token and denom into a single transfer
+	Aggregate bool `json:"aggregate,omitempty"`
+	// MaxTotalAmount caps the total amount transferred per run; routes beyond the cap are deferred to a later run
+	MaxTotalAmount string `json:"max_total_amount,omitempty"`
+	// DuplicateWindowBlocks is how many blocks apart deposits with the same sender, amount and recipient
+	// are flagged as possible double-sends. Zero uses DefaultDuplicateWindowBlocks; negative disables the check.
+	DuplicateWindowBlocks int64 `json:"duplicate_window_blocks,omitempty"`
+	// Priority orders the routes before the total cap and the in-flight limits pick the ones
+	// generated; without it, routes keep the order of the routes file
+	Priority *PriorityConfig `json:"priority,omitempty"`
+}
+
+// DefaultDuplicateWindowBlocks is the default duplicate deposit window, about ten minutes of Celestia blocks
+const DefaultDuplicateWindowBlocks = 100
+
+// DuplicateWindow returns the duplicate deposit window in blocks; zero means the check is disabled
+func (s StrategyConfig) DuplicateWindow() int64 {
+	switch {
+	case s.DuplicateWindowBlocks < 0:
+		return 0
+	case s.DuplicateWindowBlocks == 0:
+		return DefaultDuplicateWindowBlocks
+	}
+	return s.DuplicateWindowBlocks
+}
+
+// LimitsConfig holds hard safety limits enforced by the generator
+type LimitsConfig struct {
+	// MaxTransferAmount is the largest amount a single generated MsgRemoteTransfer may carry,
+	// limiting the blast radius of manipulated metadata
+	MaxTransferAmount string `json:"max_transfer_amount,omitempty"`
+	// SplitOversized splits routes above MaxTransferAmount into several transfers instead of refusing them
+	SplitOversized bool `json:"split_oversized,omitempty"`
+	// AllowAmountAboveDeposit lets a metadata amount exceed the deposit it came with, e.g. for
+	// corridors topped up from the multisig's own balance. By default such routes are refused.
+	AllowAmountAboveDeposit bool `json:"allow_amount_above_deposit,omitempty"`
+	// WarnRouteAgeBlocks makes verify warn about routes deposited more than this many blocks ago
+	WarnRouteAgeBlocks int64 `json:"warn_route_age_blocks,omitempty"`
+	// MaxRouteAgeBlocks makes verify fail on routes deposited more than this many blocks ago, which
+	// may have been handled manually since and would be paid twice
+	MaxRouteAgeBlocks int64 `json:"max_route_age_blocks,omitempty"`
+}
+
+// ChecksRouteAge reports whether the limits set a route age to warn or fail at
+func (l LimitsConfig) ChecksRouteAge() bool {
+	return l.WarnRouteAgeBlocks > 0 || l.MaxRouteAgeBlocks > 0
+}
+
+// CheckAmountOverride refuses a route whose metadata amount exceeds the amount deposited, unless the
+// limits allow it. Routes without an override, or parsed before deposits were recorded, pass.
+func (l LimitsConfig) CheckAmountOverride(route *HyperlaneRoute) error {
+	if l.AllowAmountAboveDeposit || route.RouteInfo == nil || route.RouteInfo.Amount == "" || route.DepositedAmount == "" {
+		return nil
+	}
+	claimed, ok := math.NewIntFromString(route.RouteInfo.Amount)
+	if !ok {
+		return fmt.Errorf("invalid metadata amount %s in route from tx %s", route.RouteInfo.Amount, route.TxHash)
+	}
+	deposited, ok := math.NewIntFromString(route.DepositedAmount)
+	if !ok {
+		return fmt.Errorf("invalid deposited amount %s in route from tx %s", route.DepositedAmount, route.TxHash)
+	}
+	if claimed.GT(deposited) {
+		return fmt.Errorf("metadata amount %s in route from tx %s exceeds the %s deposited", claimed, route.TxHash, deposited)
+	}
+	return nil
+}
+
+// AmountMismatch describes how the route's metadata amount differs from the amount deposited
+// on-chain, or returns "" if the metadata did not set an amount or both agree. Overrides allowed by
+// the limits still deserve a signer's attention.
+func (r *HyperlaneRoute) AmountMismatch() string {
+	if r.RouteInfo == nil || r.RouteInfo.Amount == "" || r.DepositedAmount == "" {
+		return ""
+	}
+	claimed, claimedOK := math.NewIntFromString(r.RouteInfo.Amount)
+	deposited, depositedOK := math.NewIntFromString(r.DepositedAmount)
+	if claimedOK && depositedOK {
+		if claimed.Equal(deposited) {
+			return ""
+		}
+	} else if r.RouteInfo.Amount == r.DepositedAmount {
+		return ""
+	}
+	return fmt.Sprintf("metadata amount %s differs from the %s deposited", r.RouteInfo.Amount, r.DepositedAmount)
+}
+
+// SourceConfig describes one source chain whose multisig receives deposits to be rebalanced.
+// A single deployment can define several sources to cover an entire warp route family.
+type SourceConfig struct {
+	Name         string            `json:"name"`
+	Domain       uint32            `json:"domain,omitempty"` // Hyperlane domain ID of the source chain
+	RPCURL       string            `json:"rpc_url"`
+	MultisigAddr string            `json:"multisig_address"`
+	Chain        ChainConfig       `json:"chain"`
+	Whitelist    *AddressWhitelist `json:"whitelist,omitempty"` // Optional: overrides the top-level whitelist
+	RPC          *RPCConfig        `json:"rpc,omitempty"`       // Optional: overrides the top-level connection settings
 }
 
 // Config holds the configuration for the rebalancer including address whitelists
 type Config struct {
+	Role      Role             `json:"role,omitempty"`      // Restricts the commands this host may run
+	ReadOnly  bool             `json:"read_only,omitempty"` // Refuses commands that mutate chain or local state
+	Chain     ChainConfig      `json:"chain"`
+	RPC       RPCConfig        `json:"rpc"` // TLS, credentials and timeout of the gRPC endpoint
 	Whitelist AddressWhitelist `json:"whitelist"`
+	Fee       FeeConfig        `json:"fee"`
+	Strategy  StrategyConfig   `json:"strategy"`
+	Limits    LimitsConfig     `json:"limits"`
+	Query     QueryConfig      `json:"query"`
+	Retry     RetryConfig      `json:"retry"`
+	Notify    NotifyConfig     `json:"notify"`
+	Authz     AuthzConfig      `json:"authz"`
+	Batching  BatchingConfig   `json:"batching"`
+	Metadata  MetadataConfig   `json:"metadata"`
+	Policy    PolicyConfig     `json:"policy"` // Warning classes that fail generation
+	// InterchainGas controls the quoting of interchain gas payments before generating
+	InterchainGas InterchainGasConfig `json:"interchain_gas"`
+	// RebalancingFee is the operating fee kept from every forwarded transfer
+	RebalancingFee RebalancingFeeConfig `json:"rebalancing_fee"`
+	// Destinations holds per-domain settings for checks against the destination chains
+	Destinations   map[uint32]DestinationConfig `json:"destinations,omitempty"`
+	AuditLog       string                       `json:"audit_log,omitempty"`       // Append-only audit trail of manual interventions
+	QuarantineFile string                       `json:"quarantine_file,omitempty"` // Deposits excluded from generation until released
+	OutputDir      string                       `json:"output_dir,omitempty"`      // Directory relative output files are written to
+	Sources        []SourceConfig               `json:"sources,omitempty"`
+	// WarpRoutes identify the warp routes connecting the sources, so net can match their tokens
+	WarpRoutes []WarpRouteConfig `json:"warp_routes,omitempty"`
+	// MultisigAddrs are the multisigs parse and generate run for when no --multisig-address is given,
+	// e.g. one per corridor. Routes are grouped per multisig and each gets its own transaction.
+	MultisigAddrs []string `json:"multisig_addresses,omitempty"`
 }
 
 // LoadConfig loads the configuration from a JSON file
@@ -25,24 +231,220 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// Decrypt sops-encrypted files and age-encrypted values before parsing
+	data, err = decryptConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
 	}
 
-	// Normalize all addresses in whitelist to lowercase for case-insensitive comparison
-	for domain, addresses := range config.Whitelist.Domains {
+	if err := config.Role.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Chain.Validate(); err != nil {
+		return nil, fmt.Errorf("chain: %w", err)
+	}
+	if err := config.RPC.Validate(); err != nil {
+		return nil, fmt.Errorf("rpc: %w", err)
+	}
+	if err := config.Limits.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.RebalancingFee.Validate(); err != nil {
+		return nil, fmt.Errorf("rebalancing_fee: %w", err)
+	}
+	if config.Strategy.Priority != nil {
+		if err := config.Strategy.Priority.Validate(); err != nil {
+			return nil, fmt.Errorf("strategy: priority: %w", err)
+		}
+	}
+	if err := config.Retry.Validate(); err != nil {
+		return nil, fmt.Errorf("retry: %w", err)
+	}
+	if err := config.Authz.Validate(); err != nil {
+		return nil, fmt.Errorf("authz: %w", err)
+	}
+	if err := config.Notify.Validate(); err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+	if err := config.Batching.Validate(); err != nil {
+		return nil, fmt.Errorf("batching: %w", err)
+	}
+	if err := config.Metadata.Validate(); err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	if err := config.InterchainGas.Validate(); err != nil {
+		return nil, fmt.Errorf("interchain_gas: %w", err)
+	}
+	if err := config.Policy.Validate(); err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+	for domain, destination := range config.Destinations {
+		if err := destination.Validate(); err != nil {
+			return nil, fmt.Errorf("destination %d: %w", domain, err)
+		}
+	}
+	if err := config.Whitelist.Validate(); err != nil {
+		return nil, fmt.Errorf("whitelist: %w", err)
+	}
+	if _, err := NewWarpRouteIndex(config.WarpRoutes); err != nil {
+		return nil, fmt.Errorf("warp_routes: %w", err)
+	}
+
+	// Normalize all addresses in the whitelist to the form recipients are compared in
+	config.Whitelist.normalize()
+
+	config.Chain = config.Chain.WithDefaults()
+	config.Query = config.Query.WithDefaults()
+	config.Retry = config.Retry.WithDefaults()
+
+	names := make(map[string]bool)
+	for i := range config.Sources {
+		source := &config.Sources[i]
+		if source.Name == "" {
+			return nil, fmt.Errorf("source %d has no name", i)
+		}
+		if names[source.Name] {
+			return nil, fmt.Errorf("duplicate source name %s", source.Name)
+		}
+		names[source.Name] = true
+
+		if err := source.Chain.Validate(); err != nil {
+			return nil, fmt.Errorf("source %s chain: %w", source.Name, err)
+		}
+		if source.RPC != nil {
+			if err := source.RPC.Validate(); err != nil {
+				return nil, fmt.Errorf("source %s rpc: %w", source.Name, err)
+			}
+		}
+		source.Chain = source.Chain.WithDefaults()
+		if source.Whitelist != nil {
+			if err := source.Whitelist.Validate(); err != nil {
+				return nil, fmt.Errorf("source %s whitelist: %w", source.Name, err)
+			}
+			source.Whitelist.normalize()
+		}
+	}
+
+	return &config, nil
+}
+
+// Validate checks that the configured limits are well-formed
+func (l LimitsConfig) Validate() error {
+	if l.WarnRouteAgeBlocks < 0 || l.MaxRouteAgeBlocks < 0 {
+		return fmt.Errorf("limits: route ages must not be negative")
+	}
+	if l.WarnRouteAgeBlocks > 0 && l.MaxRouteAgeBlocks > 0 && l.WarnRouteAgeBlocks >= l.MaxRouteAgeBlocks {
+		return fmt.Errorf("limits: warn_route_age_blocks must be below max_route_age_blocks")
+	}
+	if l.MaxTransferAmount == "" {
+		if l.SplitOversized {
+			return fmt.Errorf("limits: split_oversized requires max_transfer_amount")
+		}
+		return nil
+	}
+	if _, err := l.MaxTransfer(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MaxTransfer returns the per-transfer maximum, or a nil Int if no maximum is configured
+func (l LimitsConfig) MaxTransfer() (math.Int, error) {
+	if l.MaxTransferAmount == "" {
+		return math.Int{}, nil
+	}
+	max, ok := math.NewIntFromString(l.MaxTransferAmount)
+	if !ok || !max.IsPositive() {
+		return math.Int{}, fmt.Errorf("limits: invalid max_transfer_amount %s", l.MaxTransferAmount)
+	}
+	return max, nil
+}
+
+// normalize converts all whitelisted addresses and token IDs to their normalized forms in place
+func (w *AddressWhitelist) normalize() {
+	for domain, addresses := range w.Domains {
 		normalized := make([]string, len(addresses))
 		for i, addr := range addresses {
-			normalized[i] = normalizeAddress(addr)
+			normalized[i] = NormalizeAddress(addr)
+		}
+		w.Domains[domain] = normalized
+	}
+	for domain, tokenIDs := range w.TokenIDs {
+		normalized := make([]string, len(tokenIDs))
+		for i, tokenID := range tokenIDs {
+			normalized[i] = normalizeTokenIDForComparison(tokenID)
+		}
+		w.TokenIDs[domain] = normalized
+	}
+	for domain, recipients := range w.Recipients {
+		normalized := make(map[string]RecipientConfig, len(recipients))
+		for addr, settings := range recipients {
+			normalized[NormalizeAddress(addr)] = settings
+		}
+		w.Recipients[domain] = normalized
+	}
+}
+
+// AllowsTokenID reports whether routes to domain may use the token ID: true if the domain has no
+// token ID list, or if the token is on it
+func (w AddressWhitelist) AllowsTokenID(domain uint32, tokenID string) bool {
+	allowed, ok := w.TokenIDs[domain]
+	if !ok || len(allowed) == 0 {
+		return true
+	}
+	normalized := normalizeTokenIDForComparison(tokenID)
+	for _, id := range allowed {
+		if normalizeTokenIDForComparison(id) == normalized {
+			return true
 		}
-		config.Whitelist.Domains[domain] = normalized
 	}
+	return false
+}
+
+// normalizeTokenIDForComparison normalizes a token ID, keeping invalid ones trimmed and lowercased
+func normalizeTokenIDForComparison(tokenID string) string {
+	if normalized, err := NormalizeTokenID(tokenID); err == nil {
+		return normalized
+	}
+	return strings.ToLower(strings.TrimSpace(tokenID))
+}
 
-	return &config, nil
+// ForSource returns the named source and the effective config for it: the source's chain
+// parameters, whitelist and connection settings (falling back to the top-level ones), with all
+// other settings inherited
+func (c *Config) ForSource(name string) (*Config, *SourceConfig, error) {
+	for i := range c.Sources {
+		source := &c.Sources[i]
+		if source.Name != name {
+			continue
+		}
+
+		sourceConfig := *c
+		sourceConfig.Chain = source.Chain
+		if sourceConfig.Chain.Domain == 0 {
+			sourceConfig.Chain.Domain = source.Domain
+		}
+		if source.Whitelist != nil {
+			sourceConfig.Whitelist = *source.Whitelist
+		}
+		if source.RPC != nil {
+			sourceConfig.RPC = *source.RPC
+		}
+		sourceConfig.Sources = nil
+
+		return &sourceConfig, source, nil
+	}
+
+	return nil, nil, fmt.Errorf("source %s is not configured", name)
 }
 
-// ValidateRoute validates that the route's recipient address is whitelisted for the destination domain
+// ValidateRoute validates that the route's recipient address, and token ID if the domain restricts
+// them, are whitelisted for the destination domain
 func (c *Config) ValidateRoute(route *RouteInfo) error {
 	if c == nil {
 		return fmt.Errorf("config is nil")
@@ -58,31 +460,108 @@ func (c *Config) ValidateRoute(route *RouteInfo) error {
 		return fmt.Errorf("domain %d has no whitelisted addresses", route.DestinationDomain)
 	}
 
-	// Normalize the recipient address for comparison
-	normalizedRecipient := normalizeAddress(route.Recipient)
+	// Check that every recipient, each split's for a fan-out route, is in the whitelist
+	for _, recipient := range route.Recipients() {
+		normalizedRecipient := NormalizeAddress(recipient)
+		whitelisted := false
+		for _, whitelistedAddr := range whitelistedAddresses {
+			if normalizedRecipient == NormalizeAddress(whitelistedAddr) {
+				whitelisted = true
+				break
+			}
+		}
+		if !whitelisted {
+			return fmt.Errorf("recipient %s is not whitelisted for domain %d", recipient, route.DestinationDomain)
+		}
+	}
+	if !c.Whitelist.AllowsTokenID(route.DestinationDomain, route.TokenID) {
+		return fmt.Errorf("token_id %s is not whitelisted for domain %d", route.TokenID, route.DestinationDomain)
+	}
+
+	// An amount claimed by the metadata must respect the domain's per-route cap
+	if route.Amount != "" {
+		if err := c.Whitelist.checkRouteAmount(route.DestinationDomain, route.Amount); err != nil {
+			return fmt.Errorf("metadata %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateRoutes checks the routes of a run against the amount caps of their destination domains:
+// each route against the per-route cap and the total to each domain against the per-run cap. It
+// reports every route and domain over its cap.
+func (c *Config) ValidateRoutes(routes []HyperlaneRoute) error {
+	if c == nil {
+		return fmt.Errorf("config is nil")
+	}
+	return errors.Join(c.Whitelist.AmountViolations(routes)...)
+}
 
-	// Check if recipient is in whitelist
-	for _, whitelistedAddr := range whitelistedAddresses {
-		if normalizedRecipient == whitelistedAddr {
-			return nil // Valid!
+// AmountViolations returns an error for each route over its domain's per-route cap and for each
+// domain whose routes total more than its per-run cap
+func (w AddressWhitelist) AmountViolations(routes []HyperlaneRoute) []error {
+	var errs []error
+	totals := make(map[uint32]math.Int)
+	var order []uint32
+	for i, route := range routes {
+		if route.RouteInfo == nil {
+			continue
+		}
+		domain := route.RouteInfo.DestinationDomain
+		if err := w.checkRouteAmount(domain, route.Amount); err != nil {
+			errs = append(errs, fmt.Errorf("route %d (tx %s): %w", i, route.TxHash, err))
+			continue
+		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			continue
 		}
+		if _, seen := totals[domain]; !seen {
+			order = append(order, domain)
+			totals[domain] = math.ZeroInt()
+		}
+		totals[domain] = totals[domain].Add(amount)
 	}
 
-	return fmt.Errorf("recipient %s is not whitelisted for domain %d", route.Recipient, route.DestinationDomain)
+	for _, domain := range order {
+		limit, err := w.Caps[domain].PerRun()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("caps of domain %d: %w", domain, err))
+			continue
+		}
+		if !limit.IsNil() && totals[domain].GT(limit) {
+			errs = append(errs, fmt.Errorf("routes to domain %d total %s, exceeding its max_total_amount_per_run of %s", domain, totals[domain], limit))
+		}
+	}
+	return errs
 }
 
-// normalizeAddress normalizes an address for comparison (lowercase, trim 0x prefix)
-func normalizeAddress(addr string) string {
-	addr = strings.TrimSpace(addr)
-	addr = strings.ToLower(addr)
-	// Keep 0x prefix if present for consistency
-	return addr
+// checkRouteAmount checks an amount routed to domain against the domain's per-route cap
+func (w AddressWhitelist) checkRouteAmount(domain uint32, value string) error {
+	limit, err := w.Caps[domain].PerRoute()
+	if err != nil {
+		return fmt.Errorf("caps of domain %d: %w", domain, err)
+	}
+	if limit.IsNil() {
+		return nil
+	}
+	amount, ok := math.NewIntFromString(value)
+	if !ok {
+		return fmt.Errorf("invalid amount %s", value)
+	}
+	if amount.GT(limit) {
+		return fmt.Errorf("amount %s exceeds the max_amount_per_route of %s for domain %d", amount, limit, domain)
+	}
+	return nil
 }
 
 // DefaultConfig returns a default configuration with example whitelisted addresses
 // This should be replaced with actual production addresses
 func DefaultConfig() *Config {
 	return &Config{
+		Chain: DefaultChainConfig(),
+		Query: DefaultQueryConfig(),
+		Retry: DefaultRetryConfig(),
 		Whitelist: AddressWhitelist{
 			Domains: map[uint32][]string{
 				// Eden domain
@@ -102,16 +581,71 @@ func DefaultConfig() *Config {
 	}
 }
 
-// SaveConfig saves the configuration to a JSON file
+// SaveConfig saves the configuration to a JSON file. It is written to a temporary file renamed over
+// path, so a crash never leaves a truncated config behind.
 func (c *Config) SaveConfig(path string) error {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
+
+// DefaultRecipient returns the default recipient configured for a destination domain, if any
+func (c *Config) DefaultRecipient(domain uint32) string {
+	return c.Destinations[domain].DefaultRecipient
+}
+
+// ApplyDefaultRecipient fills in the destination's default recipient when the route names a domain
+// but no recipient. It reports whether the default was applied.
+func (c *Config) ApplyDefaultRecipient(routeInfo *RouteInfo) bool {
+	if routeInfo.Recipient != "" || len(routeInfo.Splits) > 0 || routeInfo.DestinationDomain == 0 {
+		return false
+	}
+	recipient := c.DefaultRecipient(routeInfo.DestinationDomain)
+	if recipient == "" {
+		return false
+	}
+	routeInfo.Recipient = recipient
+	routeInfo.RecipientDefaulted = true
+	return true
+}
+
+// ApplyDefaultTokenID fills in the destination's token ID when the route names a domain but no
+// token. It reports whether the default was applied.
+func (c *Config) ApplyDefaultTokenID(routeInfo *RouteInfo) bool {
+	if routeInfo.TokenID != "" || routeInfo.DestinationDomain == 0 {
+		return false
+	}
+	tokenID := c.Destinations[routeInfo.DestinationDomain].TokenID
+	if tokenID == "" {
+		return false
+	}
+	routeInfo.TokenID = tokenID
+	return true
+}
+
+// ParseCustomHookMetadata parses custom_hook_metadata like the package-level function, falling back
+// to the destination's default recipient and token ID when the metadata omits them
+func (c *Config) ParseCustomHookMetadata(metadata string) (*RouteInfo, error) {
+	routeInfo, err := decodeCustomHookMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	c.ApplyDefaultRecipient(routeInfo)
+	c.ApplyDefaultTokenID(routeInfo)
+	if err := routeInfo.Validate(); err != nil {
+		return nil, err
+	}
+	return routeInfo, nil
+}
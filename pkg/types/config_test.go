@@ -3,6 +3,7 @@ package types
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -55,6 +56,31 @@ func TestValidateRoute(t *testing.T) {
 			expectError: true,
 			errorMsg:    "not whitelisted for domain",
 		},
+		{
+			name: "fan-out to whitelisted addresses",
+			route: &RouteInfo{
+				DestinationDomain: 2340,
+				TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				Splits: []RouteSplit{
+					{Recipient: "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", Percent: "50"},
+					{Recipient: "0x1111111111111111111111111111111111111111", Percent: "50"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "fan-out to one address not whitelisted",
+			route: &RouteInfo{
+				DestinationDomain: 2340,
+				TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				Splits: []RouteSplit{
+					{Recipient: "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", Percent: "50"},
+					{Recipient: "0x9999999999999999999999999999999999999999", Percent: "50"},
+				},
+			},
+			expectError: true,
+			errorMsg:    "not whitelisted for domain",
+		},
 		{
 			name: "invalid domain - not configured",
 			route: &RouteInfo{
@@ -185,3 +211,399 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadConfigSources(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	configJSON := `{
+  "whitelist": {
+    "domains": {
+      "2340": ["0x742D35CC6634C0532925A3B844BC9E7595F0BEB0"]
+    }
+  },
+  "sources": [
+    {
+      "name": "celestia",
+      "domain": 1128614981,
+      "rpc_url": "celestia-grpc:9090",
+      "multisig_address": "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+    },
+    {
+      "name": "neutron",
+      "rpc_url": "neutron-grpc:9090",
+      "multisig_address": "neutron1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg39z7qgg",
+      "chain": {"bech32_prefix": "neutron", "denom": "untrn"},
+      "whitelist": {
+        "domains": {
+          "1": ["0xABCDEFABCDEFABCDEFABCDEFABCDEFABCDEFABCD"]
+        }
+      }
+    }
+  ]
+}`
+
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	// Source without overrides inherits the top-level whitelist and default chain
+	celestia, source, err := config.ForSource("celestia")
+	if err != nil {
+		t.Fatalf("ForSource(celestia) failed: %v", err)
+	}
+	if source.RPCURL != "celestia-grpc:9090" {
+		t.Errorf("RPCURL = %s, want celestia-grpc:9090", source.RPCURL)
+	}
+	if celestia.Chain.Bech32Prefix != DefaultBech32Prefix {
+		t.Errorf("Bech32Prefix = %s, want %s", celestia.Chain.Bech32Prefix, DefaultBech32Prefix)
+	}
+	if celestia.Chain.Domain != 1128614981 {
+		t.Errorf("Domain = %d, want the source's domain 1128614981", celestia.Chain.Domain)
+	}
+	if _, ok := celestia.Whitelist.Domains[2340]; !ok {
+		t.Error("celestia source did not inherit top-level whitelist")
+	}
+
+	// Source with overrides uses its own chain and normalized whitelist
+	neutron, _, err := config.ForSource("neutron")
+	if err != nil {
+		t.Fatalf("ForSource(neutron) failed: %v", err)
+	}
+	if neutron.Chain.Denom != "untrn" || neutron.Chain.GasPrice != DefaultGasPrice {
+		t.Errorf("neutron chain = %+v, want denom untrn with default gas price", neutron.Chain)
+	}
+	if _, ok := neutron.Whitelist.Domains[2340]; ok {
+		t.Error("neutron source should not inherit top-level whitelist")
+	}
+	route := &RouteInfo{DestinationDomain: 1, Recipient: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"}
+	if err := neutron.ValidateRoute(route); err != nil {
+		t.Errorf("ValidateRoute failed for neutron source: %v", err)
+	}
+
+	if _, _, err := config.ForSource("osmosis"); err == nil {
+		t.Error("ForSource expected error for unknown source, got nil")
+	}
+}
+
+func TestLoadConfigDuplicateSource(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	configJSON := `{"sources": [{"name": "a"}, {"name": "a"}]}`
+
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("LoadConfig expected error for duplicate source names, got nil")
+	}
+}
+
+func TestLoadConfigRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    Role
+		wantErr bool
+	}{
+		{name: "unset", json: `{}`, want: RoleUnrestricted},
+		{name: "signer", json: `{"role": "signer"}`, want: RoleSigner},
+		{name: "unknown", json: `{"role": "admin"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(configPath, []byte(tt.json), 0644); err != nil {
+				t.Fatalf("failed to write test config: %v", err)
+			}
+
+			config, err := LoadConfig(configPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && config.Role != tt.want {
+				t.Errorf("Role = %q, want %q", config.Role, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigParseCustomHookMetadataDefaultRecipient(t *testing.T) {
+	vault := "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	config := &Config{
+		Destinations: map[uint32]DestinationConfig{
+			1380012617: {DefaultRecipient: vault},
+		},
+	}
+	tokenID := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	tests := []struct {
+		name          string
+		metadata      string
+		wantRecipient string
+		wantDefaulted bool
+		wantErr       bool
+	}{
+		{
+			name:          "explicit recipient",
+			metadata:      `{"destination_domain": 1380012617, "recipient": "0x1111111111111111111111111111111111111111", "token_id": "` + tokenID + `"}`,
+			wantRecipient: "0x1111111111111111111111111111111111111111",
+		},
+		{
+			name:          "missing recipient uses default",
+			metadata:      `{"destination_domain": 1380012617, "token_id": "` + tokenID + `"}`,
+			wantRecipient: vault,
+			wantDefaulted: true,
+		},
+		{
+			name:     "metadata cannot claim a default",
+			metadata: `{"destination_domain": 42161, "token_id": "` + tokenID + `", "recipient_defaulted": true}`,
+			wantErr:  true,
+		},
+		{
+			name:     "no default for domain",
+			metadata: `{"destination_domain": 42161, "token_id": "` + tokenID + `"}`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing domain",
+			metadata: `{"token_id": "` + tokenID + `"}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeInfo, err := config.ParseCustomHookMetadata(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCustomHookMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if routeInfo.Recipient != tt.wantRecipient || routeInfo.RecipientDefaulted != tt.wantDefaulted {
+				t.Errorf("recipient = %s (defaulted %v), want %s (defaulted %v)",
+					routeInfo.Recipient, routeInfo.RecipientDefaulted, tt.wantRecipient, tt.wantDefaulted)
+			}
+		})
+	}
+
+	// The package-level parser never applies defaults
+	if _, err := ParseCustomHookMetadata(`{"destination_domain": 1380012617, "token_id": "` + tokenID + `"}`); err == nil {
+		t.Error("ParseCustomHookMetadata() without a config accepted a missing recipient")
+	}
+}
+
+func TestStrategyConfigDuplicateWindow(t *testing.T) {
+	tests := []struct {
+		blocks int64
+		want   int64
+	}{
+		{0, DefaultDuplicateWindowBlocks},
+		{20, 20},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		if got := (StrategyConfig{DuplicateWindowBlocks: tt.blocks}).DuplicateWindow(); got != tt.want {
+			t.Errorf("DuplicateWindow() with %d blocks = %d, want %d", tt.blocks, got, tt.want)
+		}
+	}
+}
+
+func TestConfigParseCustomHookMetadataDefaultTokenID(t *testing.T) {
+	tokenID := "0x726f757465725f61707000000000000000000000000000010000000000000000"
+	config := &Config{
+		Destinations: map[uint32]DestinationConfig{1: {TokenID: tokenID}},
+	}
+
+	routeInfo, err := config.ParseCustomHookMetadata(`{"destination_domain": 1, "recipient": "0x1111111111111111111111111111111111111111"}`)
+	if err != nil {
+		t.Fatalf("ParseCustomHookMetadata() error = %v", err)
+	}
+	if routeInfo.TokenID != tokenID {
+		t.Errorf("token ID = %s, want the destination's %s", routeInfo.TokenID, tokenID)
+	}
+
+	// An explicit token ID is kept, and domains without a token ID still require one
+	explicit := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	routeInfo, err = config.ParseCustomHookMetadata(`{"destination_domain": 1, "recipient": "0x1111111111111111111111111111111111111111", "token_id": "` + explicit + `"}`)
+	if err != nil || routeInfo.TokenID != explicit {
+		t.Errorf("ParseCustomHookMetadata() = %+v, %v, want token ID %s", routeInfo, err, explicit)
+	}
+	if _, err := config.ParseCustomHookMetadata(`{"destination_domain": 2, "recipient": "0x1111111111111111111111111111111111111111"}`); err == nil {
+		t.Error("ParseCustomHookMetadata() accepted a missing token ID without a default")
+	}
+}
+
+func TestValidateRoutesAmountCaps(t *testing.T) {
+	recipient := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	config := &Config{
+		Whitelist: AddressWhitelist{
+			Domains: map[uint32][]string{1: {recipient}, 2340: {recipient}},
+			Caps: map[uint32]AmountCaps{
+				1: {MaxAmountPerRoute: "1000", MaxTotalAmountPerRun: "1500"},
+			},
+		},
+	}
+	route := func(domain uint32, amount string) HyperlaneRoute {
+		return HyperlaneRoute{TxHash: "TX" + amount, Amount: amount, RouteInfo: &RouteInfo{DestinationDomain: domain, Recipient: recipient}}
+	}
+
+	// A metadata amount over the per-route cap is rejected with the route itself
+	if err := config.ValidateRoute(&RouteInfo{DestinationDomain: 1, Recipient: recipient, Amount: "1001"}); err == nil {
+		t.Error("ValidateRoute() accepted a metadata amount over the per-route cap")
+	}
+	if err := config.ValidateRoute(&RouteInfo{DestinationDomain: 1, Recipient: recipient, Amount: "1000"}); err != nil {
+		t.Errorf("ValidateRoute() error = %v", err)
+	}
+	if err := config.ValidateRoute(&RouteInfo{DestinationDomain: 2340, Recipient: recipient, Amount: "1000000000"}); err != nil {
+		t.Errorf("ValidateRoute() error = %v for a domain without caps", err)
+	}
+
+	tests := []struct {
+		name       string
+		routes     []HyperlaneRoute
+		violations int
+	}{
+		{"within caps", []HyperlaneRoute{route(1, "1000"), route(1, "500"), route(2340, "99999")}, 0},
+		{"route over cap", []HyperlaneRoute{route(1, "1001")}, 1},
+		{"run over cap", []HyperlaneRoute{route(1, "1000"), route(1, "501")}, 1},
+		{"both", []HyperlaneRoute{route(1, "2000"), route(1, "900"), route(1, "900")}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := config.Whitelist.AmountViolations(tt.routes)
+			if len(violations) != tt.violations {
+				t.Errorf("AmountViolations() = %v, want %d violations", violations, tt.violations)
+			}
+			if err := config.ValidateRoutes(tt.routes); (err != nil) != (tt.violations > 0) {
+				t.Errorf("ValidateRoutes() error = %v", err)
+			}
+		})
+	}
+
+	if err := (AmountCaps{MaxTotalAmountPerRun: "-5"}).Validate(); err == nil {
+		t.Error("Validate() accepted a negative cap")
+	}
+}
+
+func TestValidateRouteTokenIDs(t *testing.T) {
+	const (
+		recipient = "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+		tia       = "0x726f757465725f61707000000000000000000000000000010000000000000000"
+		other     = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	)
+	config := &Config{
+		Whitelist: AddressWhitelist{
+			Domains:  map[uint32][]string{1: {recipient}, 2340: {recipient}},
+			TokenIDs: map[uint32][]string{1: {tia}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		route   *RouteInfo
+		wantErr bool
+	}{
+		{"whitelisted token", &RouteInfo{DestinationDomain: 1, Recipient: recipient, TokenID: tia}, false},
+		{"whitelisted token in uppercase", &RouteInfo{DestinationDomain: 1, Recipient: recipient, TokenID: "0x" + strings.ToUpper(tia[2:])}, false},
+		{"unexpected token", &RouteInfo{DestinationDomain: 1, Recipient: recipient, TokenID: other}, true},
+		{"domain without token list", &RouteInfo{DestinationDomain: 2340, Recipient: recipient, TokenID: other}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := config.ValidateRoute(tt.route); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRoute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	invalid := AddressWhitelist{TokenIDs: map[uint32][]string{1: {"0x1234"}}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Validate() accepted a token ID that is not 32 bytes")
+	}
+}
+
+func TestLimitsRouteAges(t *testing.T) {
+	for _, tt := range []struct {
+		limits  LimitsConfig
+		wantErr bool
+	}{
+		{LimitsConfig{WarnRouteAgeBlocks: 100, MaxRouteAgeBlocks: 1000}, false},
+		{LimitsConfig{MaxRouteAgeBlocks: 1000}, false},
+		{LimitsConfig{WarnRouteAgeBlocks: 1000, MaxRouteAgeBlocks: 1000}, true},
+		{LimitsConfig{WarnRouteAgeBlocks: -1}, true},
+	} {
+		if err := tt.limits.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.limits, err, tt.wantErr)
+		}
+	}
+	if (LimitsConfig{}).ChecksRouteAge() {
+		t.Error("ChecksRouteAge() = true without route ages")
+	}
+}
+
+func TestLimitsCheckAmountOverride(t *testing.T) {
+	route := func(claimed, deposited string) *HyperlaneRoute {
+		return &HyperlaneRoute{
+			TxHash:          "ABC",
+			Amount:          claimed,
+			DepositedAmount: deposited,
+			RouteInfo:       &RouteInfo{DestinationDomain: 1, Amount: claimed},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		limits  LimitsConfig
+		route   *HyperlaneRoute
+		wantErr bool
+	}{
+		{"below deposit", LimitsConfig{}, route("900", "1000"), false},
+		{"equal to deposit", LimitsConfig{}, route("1000", "1000"), false},
+		{"above deposit", LimitsConfig{}, route("1001", "1000"), true},
+		{"above deposit allowed", LimitsConfig{AllowAmountAboveDeposit: true}, route("1001", "1000"), false},
+		{"deposit unknown", LimitsConfig{}, route("1001", ""), false},
+		{"no override", LimitsConfig{}, &HyperlaneRoute{Amount: "5", RouteInfo: &RouteInfo{}}, false},
+		{"invalid amount", LimitsConfig{}, route("lots", "1000"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.CheckAmountOverride(tt.route); (err != nil) != tt.wantErr {
+				t.Errorf("CheckAmountOverride() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAmountMismatch(t *testing.T) {
+	route := func(claimed, deposited string) *HyperlaneRoute {
+		return &HyperlaneRoute{
+			Amount:          claimed,
+			DepositedAmount: deposited,
+			RouteInfo:       &RouteInfo{DestinationDomain: 1, Amount: claimed},
+		}
+	}
+
+	if got := route("1001", "1000").AmountMismatch(); !strings.Contains(got, "1001") || !strings.Contains(got, "1000") {
+		t.Errorf("AmountMismatch() = %q, want both amounts", got)
+	}
+	if got := route("900", "1000").AmountMismatch(); got == "" {
+		t.Error("AmountMismatch() missed a metadata amount below the deposit")
+	}
+	for _, r := range []*HyperlaneRoute{
+		route("1000", "1000"),
+		route("1000", ""),
+		{Amount: "5", RouteInfo: &RouteInfo{}},
+		{Amount: "5"},
+	} {
+		if got := r.AmountMismatch(); got != "" {
+			t.Errorf("AmountMismatch() = %q for route %+v, want none", got, r)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVHeader is the header row of routes exported as CSV
+var CSVHeader = []string{"tx_hash", "block_height", "sender", "destination_domain", "recipient", "token_id", "amount", "denom", "status"}
+
+// CSVRecord returns the route as a row under CSVHeader with status as its last column. Recipients
+// of a fan-out route are separated by spaces. Cells that a spreadsheet would read as a formula are
+// prefixed with a quote, since senders and metadata come from depositors.
+func (r HyperlaneRoute) CSVRecord(status string) []string {
+	var domain, recipient, tokenID string
+	if r.RouteInfo != nil {
+		domain = strconv.FormatUint(uint64(r.RouteInfo.DestinationDomain), 10)
+		recipient = strings.Join(r.RouteInfo.Recipients(), " ")
+		tokenID = r.RouteInfo.TokenID
+	}
+	record := []string{r.TxHash, strconv.FormatInt(r.BlockHeight, 10), r.From, domain, recipient, tokenID, r.Amount, r.Denom, status}
+	for i, cell := range record {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			record[i] = "'" + cell
+		}
+	}
+	return record
+}
+
+// WriteRoutesCSV writes routes to w as CSV under CSVHeader, with the status status returns for the
+// route at each index
+func WriteRoutesCSV(w io.Writer, routes []HyperlaneRoute, status func(i int) string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for i, route := range routes {
+		if err := writer.Write(route.CSVRecord(status(i))); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteRoutesCSV(t *testing.T) {
+	routes := []HyperlaneRoute{
+		{
+			TxHash: "ABC", BlockHeight: 100, From: "celestia1sender", Amount: "1000", Denom: "utia",
+			RouteInfo: &RouteInfo{DestinationDomain: 1380012617, Recipient: "0x742d", TokenID: "0x1234"},
+		},
+		{
+			TxHash: "DEF", BlockHeight: 101, From: "=HYPERLINK(\"x\")", Amount: "500", Denom: "utia",
+			RouteInfo: &RouteInfo{DestinationDomain: 2340, TokenID: "0x5678", Splits: []RouteSplit{{Recipient: "0xaa"}, {Recipient: "0xbb"}}},
+		},
+		{TxHash: "GHI", BlockHeight: 102, From: "celestia1other", Amount: "7", Denom: "utia"},
+	}
+	statuses := []string{"matched", "unmatched", "parsed"}
+
+	var buf bytes.Buffer
+	if err := WriteRoutesCSV(&buf, routes, func(i int) string { return statuses[i] }); err != nil {
+		t.Fatal(err)
+	}
+	want := "tx_hash,block_height,sender,destination_domain,recipient,token_id,amount,denom,status\n" +
+		"ABC,100,celestia1sender,1380012617,0x742d,0x1234,1000,utia,matched\n" +
+		"DEF,101,\"'=HYPERLINK(\"\"x\"\")\",2340,0xaa 0xbb,0x5678,500,utia,unmatched\n" +
+		"GHI,102,celestia1other,,,,7,utia,parsed\n"
+	if buf.String() != want {
+		t.Errorf("WriteRoutesCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
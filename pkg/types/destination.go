@@ -0,0 +1,230 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cosmossdk.io/math"
+)
+
+// Warp route router types on the destination chain
+const (
+	RouterSynthetic  = "synthetic"  // Mints on arrival; liquidity is unlimited
+	RouterCollateral = "collateral" // Releases an ERC-20 held by the router
+	RouterNative     = "native"     // Releases the chain's native token held by the router
+)
+
+// DestinationConfig describes a destination domain's warp route contract, used for optional
+// pre-generation checks against the destination chain
+type DestinationConfig struct {
+	RPCURL string `json:"rpc_url,omitempty"` // EVM JSON-RPC endpoint of the destination chain
+	Router string `json:"router,omitempty"`  // Warp route contract receiving the transfers
+	// Mailbox delivers messages to the router; set it to estimate the gas of each delivery
+	Mailbox string `json:"mailbox,omitempty"`
+	// ExpectedISM fingerprints the interchain security module the router should use: its address,
+	// optionally followed by ":" and the module type, e.g. "0xabc...:3"
+	ExpectedISM string `json:"expected_ism,omitempty"`
+	// RouterType is the kind of warp route contract; collateral and native routers can only pay out
+	// what they hold, which is checked before generating
+	RouterType string `json:"router_type,omitempty"`
+	// DefaultRecipient receives transfers whose metadata names this domain but omits the recipient,
+	// for corridors with a single canonical destination vault
+	DefaultRecipient string `json:"default_recipient,omitempty"`
+	// Scale converts a transferred amount into destination units, e.g. "1000000000000" when 6-decimal
+	// utia arrives as an 18-decimal token. Defaults to 1.
+	Scale string `json:"scale,omitempty"`
+	// TokenID is the warp token on this chain that routes to the domain, used for transfers whose
+	// metadata omits token_id. Filled by import-warp from the deployment artifact.
+	TokenID string `json:"token_id,omitempty"`
+	// MemoTemplate renders the memo of generated transactions transferring to this domain, for
+	// destination-side indexers keyed on memos, e.g. "rebalance {{.Routes}} {{.Digest}}". See MemoVars.
+	MemoTemplate string `json:"memo_template,omitempty"`
+	// MaxInFlight caps the transfers to this domain that are dispatched but not yet delivered;
+	// generate holds further routes to the domain until deliveries are confirmed through mailbox.
+	// Zero is unlimited.
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+	// MaxAmountPerMessage caps the amount of a single transfer to this domain, e.g. a per-message
+	// limit of the destination's bridge; generate splits larger routes into several transfers
+	MaxAmountPerMessage string `json:"max_amount_per_message,omitempty"`
+	// MinAmount is the least a transfer to this domain is worth its interchain gas; smaller routes
+	// are skipped, or with --state accumulated until deposits to the same recipient reach it
+	MinAmount string `json:"min_amount,omitempty"`
+
+	// Interchain gas estimate settings, used by plan to compare strategies by fee cost
+	GasPerTransfer   uint64 `json:"gas_per_transfer,omitempty"`    // Destination gas to deliver one transfer
+	GasPrice         string `json:"gas_price,omitempty"`           // In the smallest unit of the destination gas token, e.g. wei
+	GasTokenDecimals uint32 `json:"gas_token_decimals,omitempty"`  // Decimals of the destination gas token; defaults to 18
+	GasTokenPriceUSD string `json:"gas_token_price_usd,omitempty"` // Price of one whole gas token, e.g. "3200.50"
+
+	// Interchain gas payment of generated transfers: the destination gas limit they request and the
+	// most they may pay the IGP for it, as a coin on the source chain, e.g. "5000utia"
+	GasLimit uint64 `json:"gas_limit,omitempty"`
+	MaxFee   string `json:"max_fee,omitempty"`
+
+	// Display sets how amounts sent to the domain and explorer links are shown to operators
+	Display *DisplayConfig `json:"display,omitempty"`
+}
+
+// maxFeePattern matches a single coin: an integer amount followed by a Cosmos SDK denom
+var maxFeePattern = regexp.MustCompile(`^([0-9]+)([a-zA-Z][a-zA-Z0-9/:._-]{2,127})$`)
+
+// ParseMaxFee parses an interchain gas MaxFee such as "5000utia" into its amount and denom
+func ParseMaxFee(fee string) (math.Int, string, error) {
+	m := maxFeePattern.FindStringSubmatch(strings.TrimSpace(fee))
+	if m == nil {
+		return math.Int{}, "", fmt.Errorf("invalid max_fee %q: want an amount followed by a denom, e.g. 5000utia", fee)
+	}
+	amount, ok := math.NewIntFromString(m[1])
+	if !ok {
+		return math.Int{}, "", fmt.Errorf("invalid max_fee %q", fee)
+	}
+	return amount, m[2], nil
+}
+
+// DefaultGasTokenDecimals is used when a destination does not set gas_token_decimals
+const DefaultGasTokenDecimals = 18
+
+// Validate checks that the destination settings are well-formed
+func (d DestinationConfig) Validate() error {
+	if d.Router != "" && !isHexAddress(d.Router) {
+		return fmt.Errorf("router %s is not a 0x-prefixed 20-byte address", d.Router)
+	}
+	if d.DefaultRecipient != "" && !isRecipientAddress(d.DefaultRecipient) {
+		return fmt.Errorf("default_recipient %s is neither a 0x-prefixed 20- or 32-byte address nor a bech32 address", d.DefaultRecipient)
+	}
+	if d.ExpectedISM != "" {
+		addr, _, _ := strings.Cut(d.ExpectedISM, ":")
+		if !isHexAddress(addr) {
+			return fmt.Errorf("expected_ism %s does not start with a 0x-prefixed 20-byte address", d.ExpectedISM)
+		}
+		if d.RPCURL == "" || d.Router == "" {
+			return fmt.Errorf("expected_ism requires rpc_url and router")
+		}
+	}
+	if d.Mailbox != "" {
+		if !isHexAddress(d.Mailbox) {
+			return fmt.Errorf("mailbox %s is not a 0x-prefixed 20-byte address", d.Mailbox)
+		}
+		if d.RPCURL == "" || d.Router == "" {
+			return fmt.Errorf("mailbox requires rpc_url and router")
+		}
+	}
+	if d.MaxInFlight < 0 {
+		return fmt.Errorf("max_in_flight must not be negative")
+	}
+	if d.MaxInFlight > 0 && d.Mailbox == "" {
+		return fmt.Errorf("max_in_flight requires mailbox to confirm deliveries")
+	}
+	switch d.RouterType {
+	case "", RouterSynthetic:
+	case RouterCollateral, RouterNative:
+		if d.RPCURL == "" || d.Router == "" {
+			return fmt.Errorf("router_type %s requires rpc_url and router", d.RouterType)
+		}
+	default:
+		return fmt.Errorf("unknown router_type %s", d.RouterType)
+	}
+	if d.TokenID != "" {
+		if _, err := ParseTokenID(d.TokenID); err != nil {
+			return err
+		}
+	}
+	if _, err := d.ScaleFactor(); err != nil {
+		return err
+	}
+	if _, err := d.MessageCap(); err != nil {
+		return err
+	}
+	if _, err := d.DustThreshold(); err != nil {
+		return err
+	}
+	if _, err := ParseMemoTemplate(d.MemoTemplate); err != nil {
+		return err
+	}
+	if d.GasPrice != "" {
+		if price, ok := math.NewIntFromString(d.GasPrice); !ok || price.IsNegative() {
+			return fmt.Errorf("invalid gas_price %s", d.GasPrice)
+		}
+	}
+	if d.GasTokenPriceUSD != "" {
+		if price, err := math.LegacyNewDecFromStr(d.GasTokenPriceUSD); err != nil || price.IsNegative() {
+			return fmt.Errorf("invalid gas_token_price_usd %s", d.GasTokenPriceUSD)
+		}
+	}
+	if d.MaxFee != "" {
+		if _, _, err := ParseMaxFee(d.MaxFee); err != nil {
+			return err
+		}
+	}
+	if d.Display != nil {
+		if err := d.Display.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScaleFactor returns the parsed scale, defaulting to 1
+func (d DestinationConfig) ScaleFactor() (math.Int, error) {
+	if d.Scale == "" {
+		return math.OneInt(), nil
+	}
+	scale, ok := math.NewIntFromString(d.Scale)
+	if !ok || !scale.IsPositive() {
+		return math.Int{}, fmt.Errorf("invalid scale %s", d.Scale)
+	}
+	return scale, nil
+}
+
+// MessageCap returns the most a single transfer to the domain may carry, or a nil Int if there is
+// no cap
+func (d DestinationConfig) MessageCap() (math.Int, error) {
+	if d.MaxAmountPerMessage == "" {
+		return math.Int{}, nil
+	}
+	limit, ok := math.NewIntFromString(d.MaxAmountPerMessage)
+	if !ok || !limit.IsPositive() {
+		return math.Int{}, fmt.Errorf("invalid max_amount_per_message %s", d.MaxAmountPerMessage)
+	}
+	return limit, nil
+}
+
+// DustThreshold returns the least amount worth transferring to the domain, or a nil Int if every
+// amount is
+func (d DestinationConfig) DustThreshold() (math.Int, error) {
+	if d.MinAmount == "" {
+		return math.Int{}, nil
+	}
+	min, ok := math.NewIntFromString(d.MinAmount)
+	if !ok || !min.IsPositive() {
+		return math.Int{}, fmt.Errorf("invalid min_amount %s", d.MinAmount)
+	}
+	return min, nil
+}
+
+// isRecipientAddress reports whether s is a recipient worth configuring: a 0x-prefixed 20-byte (EVM)
+// or 32-byte address, or a bech32 address with any prefix
+func isRecipientAddress(s string) bool {
+	decoded, err := RecipientBytes(s)
+	if err != nil {
+		return false
+	}
+	if _, isHex := cutHexPrefix(strings.TrimSpace(s)); isHex {
+		return len(decoded) == 20 || len(decoded) == 32
+	}
+	return true
+}
+
+// isHexAddress reports whether s is a 0x-prefixed 20-byte hex address
+func isHexAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	for _, c := range strings.ToLower(s[2:]) {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
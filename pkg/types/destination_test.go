@@ -0,0 +1,51 @@
+package types
+
+import "testing"
+
+func TestDestinationConfigValidate(t *testing.T) {
+	router := "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	ism := "0x1234567890123456789012345678901234567890"
+
+	tests := []struct {
+		name    string
+		config  DestinationConfig
+		wantErr bool
+	}{
+		{name: "empty", config: DestinationConfig{}},
+		{name: "ism address", config: DestinationConfig{RPCURL: "http://rpc", Router: router, ExpectedISM: ism}},
+		{name: "ism with module type", config: DestinationConfig{RPCURL: "http://rpc", Router: router, ExpectedISM: ism + ":3"}},
+		{name: "invalid router", config: DestinationConfig{Router: "0x1234"}, wantErr: true},
+		{name: "invalid ism", config: DestinationConfig{RPCURL: "http://rpc", Router: router, ExpectedISM: "ism"}, wantErr: true},
+		{name: "ism without rpc", config: DestinationConfig{Router: router, ExpectedISM: ism}, wantErr: true},
+		{name: "collateral", config: DestinationConfig{RPCURL: "http://rpc", Router: router, RouterType: RouterCollateral, Scale: "1000"}},
+		{name: "collateral without router", config: DestinationConfig{RPCURL: "http://rpc", RouterType: RouterCollateral}, wantErr: true},
+		{name: "unknown router type", config: DestinationConfig{RouterType: "lockbox"}, wantErr: true},
+		{name: "invalid scale", config: DestinationConfig{Scale: "0"}, wantErr: true},
+		{name: "gas prices", config: DestinationConfig{GasPerTransfer: 120000, GasPrice: "20000000000", GasTokenPriceUSD: "3200.5"}},
+		{name: "invalid gas price", config: DestinationConfig{GasPrice: "fast"}, wantErr: true},
+		{name: "invalid token price", config: DestinationConfig{GasTokenPriceUSD: "-1"}, wantErr: true},
+		{name: "evm default recipient", config: DestinationConfig{DefaultRecipient: router}},
+		{name: "padded default recipient", config: DestinationConfig{DefaultRecipient: "0x000000000000000000000000742d35cc6634c0532925a3b844bc9e7595f0beb0"}},
+		{name: "bech32 default recipient", config: DestinationConfig{DefaultRecipient: "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"}},
+		{name: "invalid default recipient", config: DestinationConfig{DefaultRecipient: "vault"}, wantErr: true},
+		{name: "interchain gas", config: DestinationConfig{GasLimit: 200000, MaxFee: "5000utia"}},
+		{name: "ibc max fee", config: DestinationConfig{MaxFee: "5000ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"}},
+		{name: "max fee without denom", config: DestinationConfig{MaxFee: "5000"}, wantErr: true},
+		{name: "negative max fee", config: DestinationConfig{MaxFee: "-5utia"}, wantErr: true},
+		{name: "max in flight", config: DestinationConfig{RPCURL: "http://rpc", Router: router, Mailbox: ism, MaxInFlight: 3}},
+		{name: "max in flight without mailbox", config: DestinationConfig{RPCURL: "http://rpc", Router: router, MaxInFlight: 3}, wantErr: true},
+		{name: "negative max in flight", config: DestinationConfig{MaxInFlight: -1}, wantErr: true},
+		{name: "max amount per message", config: DestinationConfig{MaxAmountPerMessage: "1000000"}},
+		{name: "zero max amount per message", config: DestinationConfig{MaxAmountPerMessage: "0"}, wantErr: true},
+		{name: "min amount", config: DestinationConfig{MinAmount: "1000"}},
+		{name: "invalid min amount", config: DestinationConfig{MinAmount: "1e3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
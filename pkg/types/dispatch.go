@@ -0,0 +1,16 @@
+package types
+
+// Dispatch records the Hyperlane message dispatched for a route once the rebalancing transaction
+// was included on chain. The message ID is what relayers and the Hyperlane explorer track delivery by.
+type Dispatch struct {
+	MessageID   string `json:"message_id"`
+	Nonce       uint32 `json:"nonce"`
+	Destination uint32 `json:"destination_domain"`
+	Recipient   string `json:"recipient"` // 32-byte hex transfer recipient from the warp payload
+	Amount      string `json:"amount"`
+	TxHash      string `json:"tx_hash"` // Rebalancing transaction that dispatched the message
+	Height      int64  `json:"height"`
+	// InterchainGas is the MaxFee the transfer allowed for interchain gas, e.g. "5000utia". The IGP
+	// charges at most this much, so it bounds what delivering the message cost.
+	InterchainGas string `json:"interchain_gas,omitempty"`
+}
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// MaxDisplayDecimals bounds DisplayConfig.Decimals; no token in practice uses more
+const MaxDisplayDecimals = 36
+
+// DisplayConfig describes how amounts sent to a destination domain and links to its explorer are
+// shown to operators in plans, tracking output, compliance reports and notifications
+type DisplayConfig struct {
+	Name     string `json:"name,omitempty"`     // Chain name shown after amounts, e.g. "Arbitrum"
+	Symbol   string `json:"symbol,omitempty"`   // Token symbol, e.g. "TIA"
+	Decimals uint32 `json:"decimals,omitempty"` // Decimals between the transferred denom and Symbol, e.g. 6
+	// Explorer renders a link for a transfer to the domain, e.g.
+	// "https://explorer.hyperlane.xyz/message/{{.MessageID}}". See ExplorerVars.
+	Explorer string `json:"explorer,omitempty"`
+}
+
+// ExplorerVars are the variables of a display explorer template. Fields unknown where the link is
+// rendered are empty, and a link rendering to an empty string is omitted.
+type ExplorerVars struct {
+	Domain    uint32
+	Recipient string // Recipient address on the destination domain
+	MessageID string // Hyperlane message ID, once dispatched
+	TxHash    string // Source chain transaction, deposit or transfer
+}
+
+// Validate checks that the display settings are well-formed
+func (d *DisplayConfig) Validate() error {
+	if d.Decimals > MaxDisplayDecimals {
+		return fmt.Errorf("display decimals %d exceeds %d", d.Decimals, MaxDisplayDecimals)
+	}
+	if d.Decimals > 0 && d.Symbol == "" {
+		return fmt.Errorf("display decimals requires symbol")
+	}
+	if _, err := parseExplorerTemplate(d.Explorer); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseExplorerTemplate parses an explorer template, checking that it only uses ExplorerVars
+// fields. An empty text yields a nil template.
+func parseExplorerTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("explorer").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid display explorer template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, ExplorerVars{}); err != nil {
+		return nil, fmt.Errorf("invalid display explorer template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Display returns the display settings of a destination domain, nil when it has none
+func (c *Config) Display(domain uint32) *DisplayConfig {
+	return c.Destinations[domain].Display
+}
+
+// FormatAmount shows an amount of denom sent to the domain, e.g. "5.0 TIA (Arbitrum)" for
+// 5000000utia with symbol TIA, 6 decimals and name Arbitrum. Without a symbol the amount is shown
+// in denom, and a nil config shows it as plain "5000000utia".
+func (d *DisplayConfig) FormatAmount(amount, denom string) string {
+	if d == nil {
+		return amount + denom
+	}
+	shown := amount + denom
+	if d.Symbol != "" {
+		shown = shiftDecimals(amount, d.Decimals) + " " + d.Symbol
+	}
+	if d.Name != "" {
+		shown += " (" + d.Name + ")"
+	}
+	return shown
+}
+
+// ExplorerLink renders the explorer link of a transfer, empty when the domain has no explorer
+// template or it fails to render
+func (d *DisplayConfig) ExplorerLink(vars ExplorerVars) string {
+	if d == nil {
+		return ""
+	}
+	tmpl, err := parseExplorerTemplate(d.Explorer)
+	if err != nil || tmpl == nil {
+		return ""
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// shiftDecimals writes an integer amount with decimals digits moved behind the decimal point,
+// keeping at least one fractional digit, e.g. 5000000 with 6 decimals as "5.0". Amounts that are
+// not plain digits are returned unchanged.
+func shiftDecimals(amount string, decimals uint32) string {
+	if decimals == 0 || amount == "" || strings.Trim(amount, "0123456789") != "" {
+		return amount
+	}
+	n := int(decimals)
+	if len(amount) <= n {
+		amount = strings.Repeat("0", n-len(amount)+1) + amount
+	}
+	whole := strings.TrimLeft(amount[:len(amount)-n], "0")
+	if whole == "" {
+		whole = "0"
+	}
+	frac := strings.TrimRight(amount[len(amount)-n:], "0")
+	if frac == "" {
+		frac = "0"
+	}
+	return whole + "." + frac
+}
@@ -0,0 +1,68 @@
+package types
+
+import "testing"
+
+func TestDisplayFormatAmount(t *testing.T) {
+	arbitrum := &DisplayConfig{Name: "Arbitrum", Symbol: "TIA", Decimals: 6}
+	tests := []struct {
+		name    string
+		display *DisplayConfig
+		amount  string
+		want    string
+	}{
+		{"no display", nil, "5000000", "5000000utia"},
+		{"whole amount", arbitrum, "5000000", "5.0 TIA (Arbitrum)"},
+		{"fraction", arbitrum, "5250000", "5.25 TIA (Arbitrum)"},
+		{"below one", arbitrum, "1500", "0.0015 TIA (Arbitrum)"},
+		{"zero", arbitrum, "0", "0.0 TIA (Arbitrum)"},
+		{"name only", &DisplayConfig{Name: "Base"}, "7", "7utia (Base)"},
+		{"symbol without decimals", &DisplayConfig{Symbol: "uTIA"}, "7", "7 uTIA"},
+		{"not an integer", arbitrum, "1e6", "1e6 TIA (Arbitrum)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.display.FormatAmount(tt.amount, "utia"); got != tt.want {
+				t.Errorf("FormatAmount(%s) = %q, want %q", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayExplorerLink(t *testing.T) {
+	display := &DisplayConfig{Explorer: "{{if .MessageID}}https://explorer.hyperlane.xyz/message/{{.MessageID}}{{end}}"}
+	if got, want := display.ExplorerLink(ExplorerVars{MessageID: "0xabc"}), "https://explorer.hyperlane.xyz/message/0xabc"; got != want {
+		t.Errorf("ExplorerLink() = %q, want %q", got, want)
+	}
+	if got := display.ExplorerLink(ExplorerVars{Recipient: "0x1"}); got != "" {
+		t.Errorf("ExplorerLink() without a message ID = %q, want empty", got)
+	}
+	var none *DisplayConfig
+	if got := none.ExplorerLink(ExplorerVars{MessageID: "0xabc"}); got != "" {
+		t.Errorf("ExplorerLink() without display = %q, want empty", got)
+	}
+}
+
+func TestDisplayValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		display DisplayConfig
+		wantErr bool
+	}{
+		{"full", DisplayConfig{Name: "Arbitrum", Symbol: "TIA", Decimals: 6, Explorer: "https://arbiscan.io/address/{{.Recipient}}"}, false},
+		{"decimals without symbol", DisplayConfig{Decimals: 6}, true},
+		{"too many decimals", DisplayConfig{Symbol: "TIA", Decimals: MaxDisplayDecimals + 1}, true},
+		{"unknown template field", DisplayConfig{Explorer: "https://x/{{.Sender}}"}, true},
+		{"malformed template", DisplayConfig{Explorer: "https://x/{{.Recipient"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.display.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			dest := DestinationConfig{Display: &tt.display}
+			if err := dest.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("DestinationConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
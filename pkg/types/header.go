@@ -0,0 +1,10 @@
+package types
+
+import "time"
+
+// BlockHeader is the part of a block header used to timestamp deposits and detect reorgs
+type BlockHeader struct {
+	Height int64     `json:"height"`
+	Hash   string    `json:"hash"` // Upper-case hex block hash
+	Time   time.Time `json:"time"`
+}
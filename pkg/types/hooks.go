@@ -0,0 +1,77 @@
+package types
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+)
+
+// Built-in route hooks, see RouteHookConfig
+const (
+	// HookMinAmount rejects routes below Amount, e.g. dust not worth the interchain gas
+	HookMinAmount = "min_amount"
+	// HookCapAmount lowers routes above Amount to Amount; the rest stays in the multisig
+	HookCapAmount = "cap_amount"
+	// HookDenySenders rejects routes deposited by one of Senders
+	HookDenySenders = "deny_senders"
+	// HookMaxAgeBlocks rejects routes whose deposit is more than Blocks behind the chain's latest height
+	HookMaxAgeBlocks = "max_age_blocks"
+	// HookAnnotate records Annotations on every route
+	HookAnnotate = "annotate"
+)
+
+// hookTypes lists the built-in route hooks
+var hookTypes = []string{HookMinAmount, HookCapAmount, HookDenySenders, HookMaxAgeBlocks, HookAnnotate}
+
+// RouteHookConfig configures a built-in route hook, run on every route before generation. Each
+// type reads only its own fields.
+type RouteHookConfig struct {
+	Type        string            `json:"type"`
+	Name        string            `json:"name,omitempty"`        // Name in reports; defaults to the type
+	Amount      string            `json:"amount,omitempty"`      // Threshold of min_amount and cap_amount
+	Senders     []string          `json:"senders,omitempty"`     // Depositors rejected by deny_senders
+	Blocks      int64             `json:"blocks,omitempty"`      // Maximum deposit age of max_age_blocks
+	Annotations map[string]string `json:"annotations,omitempty"` // Notes recorded by annotate
+}
+
+// HookName returns the name the hook is reported under
+func (h RouteHookConfig) HookName() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Type
+}
+
+// Validate checks that the hook is a known type with the fields it needs
+func (h RouteHookConfig) Validate() error {
+	switch h.Type {
+	case HookMinAmount, HookCapAmount:
+		if _, err := h.Threshold(); err != nil {
+			return err
+		}
+	case HookDenySenders:
+		if len(h.Senders) == 0 {
+			return fmt.Errorf("%s needs senders", h.Type)
+		}
+	case HookMaxAgeBlocks:
+		if h.Blocks <= 0 {
+			return fmt.Errorf("%s needs a positive blocks", h.Type)
+		}
+	case HookAnnotate:
+		if len(h.Annotations) == 0 {
+			return fmt.Errorf("%s needs annotations", h.Type)
+		}
+	default:
+		return fmt.Errorf("unknown route hook type %q, want one of %v", h.Type, hookTypes)
+	}
+	return nil
+}
+
+// Threshold returns the amount of a min_amount or cap_amount hook
+func (h RouteHookConfig) Threshold() (math.Int, error) {
+	amount, ok := math.NewIntFromString(h.Amount)
+	if !ok || !amount.IsPositive() {
+		return math.Int{}, fmt.Errorf("%s needs a positive integer amount, got %q", h.Type, h.Amount)
+	}
+	return amount, nil
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+)
+
+// InterchainGasConfig controls how generate pays the interchain gas paymaster (IGP) for the
+// delivery of each transfer
+type InterchainGasConfig struct {
+	// IGP is the ID of the interchain gas paymaster on the source chain, a 32-byte hex post-dispatch
+	// hook ID. Set, quotes are taken from it for the gas_limit of each destination; otherwise from
+	// the warp token's hooks.
+	IGP string `json:"igp,omitempty"`
+	// Quote queries the payment of every destination before generating, as --igp-quote does
+	Quote bool `json:"quote,omitempty"`
+	// Margin raises quotes by a percentage, e.g. "10", so a gas price update between generating and
+	// broadcasting does not leave the transfer short
+	Margin string `json:"margin,omitempty"`
+}
+
+// Validate checks that the IGP ID and the margin are well-formed
+func (g InterchainGasConfig) Validate() error {
+	if g.IGP != "" {
+		if _, err := TokenIDBytes(g.IGP); err != nil {
+			return fmt.Errorf("invalid igp %s: %w", g.IGP, err)
+		}
+	}
+	if _, err := g.margin(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ApplyMargin raises a quoted amount by the configured margin, rounding up
+func (g InterchainGasConfig) ApplyMargin(amount math.Int) (math.Int, error) {
+	margin, err := g.margin()
+	if err != nil {
+		return math.Int{}, err
+	}
+	if margin.IsZero() {
+		return amount, nil
+	}
+	factor := margin.Add(math.LegacyNewDec(100)).QuoInt64(100)
+	return factor.MulInt(amount).Ceil().TruncateInt(), nil
+}
+
+// margin returns the parsed margin percentage, zero if unset
+func (g InterchainGasConfig) margin() (math.LegacyDec, error) {
+	if g.Margin == "" {
+		return math.LegacyZeroDec(), nil
+	}
+	margin, err := math.LegacyNewDecFromStr(g.Margin)
+	if err != nil || margin.IsNegative() {
+		return math.LegacyDec{}, fmt.Errorf("invalid margin %s", g.Margin)
+	}
+	return margin, nil
+}
+
+// QuoteGasLimit returns the destination gas to quote the IGP payment of a transfer to the
+// destination for: its gas_limit, or else its gas_per_transfer estimate
+func (d DestinationConfig) QuoteGasLimit() uint64 {
+	if d.GasLimit > 0 {
+		return d.GasLimit
+	}
+	return d.GasPerTransfer
+}
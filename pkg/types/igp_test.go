@@ -0,0 +1,53 @@
+package types
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+func TestInterchainGasConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  InterchainGasConfig
+		wantErr bool
+	}{
+		{name: "empty", config: InterchainGasConfig{}},
+		{name: "igp and margin", config: InterchainGasConfig{IGP: "0x726f757465725f706f73745f6469737061746368000000040000000000000001", Quote: true, Margin: "12.5"}},
+		{name: "short igp", config: InterchainGasConfig{IGP: "0x1234"}, wantErr: true},
+		{name: "negative margin", config: InterchainGasConfig{Margin: "-5"}, wantErr: true},
+		{name: "invalid margin", config: InterchainGasConfig{Margin: "ten"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInterchainGasApplyMargin(t *testing.T) {
+	tests := []struct {
+		margin string
+		amount int64
+		want   int64
+	}{
+		{"", 1000, 1000},
+		{"0", 1000, 1000},
+		{"10", 1000, 1100},
+		{"10", 1001, 1102}, // 1101.1 rounded up
+		{"12.5", 80, 90},
+	}
+
+	for _, tt := range tests {
+		got, err := InterchainGasConfig{Margin: tt.margin}.ApplyMargin(math.NewInt(tt.amount))
+		if err != nil {
+			t.Fatalf("ApplyMargin() error = %v", err)
+		}
+		if got.Int64() != tt.want {
+			t.Errorf("ApplyMargin(%d) with margin %q = %s, want %d", tt.amount, tt.margin, got, tt.want)
+		}
+	}
+}
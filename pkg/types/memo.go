@@ -0,0 +1,105 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"cosmossdk.io/math"
+)
+
+// MaxMemoLength is the Cosmos SDK default limit on the length of a transaction memo
+const MaxMemoLength = 256
+
+// MemoTransfer is a transfer of a batch as seen by memo templates
+type MemoTransfer struct {
+	Domain    uint32
+	Recipient string // 32-byte hex
+	Amount    string
+}
+
+// MemoVars are the variables of a destination's memo template. They describe the transfers of
+// the batch to that destination.
+type MemoVars struct {
+	Domain uint32 // Destination domain
+	Routes int    // Transfers to the domain in the batch
+	Amount string // Total amount of those transfers
+	// Digest is the hex SHA-256 of the transfers to the domain, one "<recipient>:<amount>\n" line
+	// each in message order, so the destination side can match the batch it received
+	Digest      string
+	TotalRoutes int // Transfers in the batch, to any domain
+}
+
+// ParseMemoTemplate parses a memo template, checking that it only uses MemoVars fields. An empty
+// text yields a nil template.
+func ParseMemoTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("memo").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memo template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, MemoVars{}); err != nil {
+		return nil, fmt.Errorf("invalid memo template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderMemo renders the transaction memo of a batch of transfers from the memo templates of
+// their destinations. Each destination with a template contributes one line, in the order the
+// destinations first appear in the batch; the memo is empty when none has a template.
+func RenderMemo(destinations map[uint32]DestinationConfig, transfers []MemoTransfer) (string, error) {
+	var order []uint32
+	vars := make(map[uint32]*MemoVars)
+	amounts := make(map[uint32]math.Int)
+	lines := make(map[uint32]*strings.Builder)
+	for _, transfer := range transfers {
+		if destinations[transfer.Domain].MemoTemplate == "" {
+			continue
+		}
+		v, ok := vars[transfer.Domain]
+		if !ok {
+			order = append(order, transfer.Domain)
+			v = &MemoVars{Domain: transfer.Domain}
+			vars[transfer.Domain] = v
+			amounts[transfer.Domain] = math.ZeroInt()
+			lines[transfer.Domain] = &strings.Builder{}
+		}
+		amount, ok := math.NewIntFromString(transfer.Amount)
+		if !ok {
+			return "", fmt.Errorf("invalid amount %q in transfer to domain %d", transfer.Amount, transfer.Domain)
+		}
+		v.Routes++
+		amounts[transfer.Domain] = amounts[transfer.Domain].Add(amount)
+		fmt.Fprintf(lines[transfer.Domain], "%s:%s\n", strings.ToLower(transfer.Recipient), transfer.Amount)
+	}
+
+	var memo []string
+	for _, domain := range order {
+		v := vars[domain]
+		v.Amount = amounts[domain].String()
+		digest := sha256.Sum256([]byte(lines[domain].String()))
+		v.Digest = hex.EncodeToString(digest[:])
+		v.TotalRoutes = len(transfers)
+
+		tmpl, err := ParseMemoTemplate(destinations[domain].MemoTemplate)
+		if err != nil {
+			return "", fmt.Errorf("destination %d: %w", domain, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, v); err != nil {
+			return "", fmt.Errorf("failed to render the memo of destination %d: %w", domain, err)
+		}
+		memo = append(memo, b.String())
+	}
+
+	rendered := strings.Join(memo, "\n")
+	if len(rendered) > MaxMemoLength {
+		return "", fmt.Errorf("rendered memo is %d characters, more than the %d allowed", len(rendered), MaxMemoLength)
+	}
+	return rendered, nil
+}
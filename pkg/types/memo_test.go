@@ -0,0 +1,54 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestRenderMemo(t *testing.T) {
+	destinations := map[uint32]DestinationConfig{
+		1: {MemoTemplate: "d{{.Domain}} {{.Routes}}/{{.TotalRoutes}} {{.Amount}} {{.Digest}}"},
+		3: {MemoTemplate: "third {{.Routes}}"},
+	}
+	transfers := []MemoTransfer{
+		{Domain: 3, Recipient: "0xCC", Amount: "5"},
+		{Domain: 1, Recipient: "0xAA", Amount: "100"},
+		{Domain: 2, Recipient: "0xBB", Amount: "7"}, // No template
+		{Domain: 1, Recipient: "0xAB", Amount: "250"},
+	}
+
+	memo, err := RenderMemo(destinations, transfers)
+	if err != nil {
+		t.Fatalf("RenderMemo() error = %v", err)
+	}
+	digest := sha256.Sum256([]byte("0xaa:100\n0xab:250\n"))
+	want := "third 1\nd1 2/4 350 " + hex.EncodeToString(digest[:])
+	if memo != want {
+		t.Errorf("RenderMemo() = %q, want %q", memo, want)
+	}
+
+	if memo, err := RenderMemo(nil, transfers); err != nil || memo != "" {
+		t.Errorf("RenderMemo() without templates = %q, %v, want an empty memo", memo, err)
+	}
+
+	long := map[uint32]DestinationConfig{1: {MemoTemplate: strings.Repeat("x", MaxMemoLength+1)}}
+	if _, err := RenderMemo(long, transfers); err == nil {
+		t.Error("RenderMemo() accepted a memo longer than the SDK allows")
+	}
+}
+
+func TestParseMemoTemplate(t *testing.T) {
+	if _, err := ParseMemoTemplate("{{.Routes}} {{.Digest}}"); err != nil {
+		t.Errorf("ParseMemoTemplate() error = %v", err)
+	}
+	for _, text := range []string{"{{.Routes", "{{.Sender}}"} {
+		if _, err := ParseMemoTemplate(text); err == nil {
+			t.Errorf("ParseMemoTemplate(%q) succeeded, want an error", text)
+		}
+	}
+	if err := (DestinationConfig{MemoTemplate: "{{.Nonce}}"}).Validate(); err == nil {
+		t.Error("Validate() accepted a memo template with an unknown field")
+	}
+}
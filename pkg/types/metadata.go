@@ -0,0 +1,121 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Metadata forwarding modes
+const (
+	ForwardMetadataOriginal = "original" // Forward the deposit's custom_hook_metadata unchanged
+	ForwardMetadataReceipt  = "receipt"  // Forward a MetadataReceipt identifying the deposit
+)
+
+// MetadataConfig controls the CustomHookMetadata of generated transfers. By default it is left
+// empty; policy can require forwarding the deposit's metadata, or a receipt derived from it, so
+// the destination side can tie each transfer back to its source deposit.
+type MetadataConfig struct {
+	Forward string `json:"forward,omitempty"` // "original" or "receipt"; empty forwards nothing
+	// MaxSize is the largest custom_hook_metadata in bytes the parser decodes. Deposits with larger
+	// metadata are skipped without decoding it. 0 means DefaultMaxMetadataSize.
+	MaxSize int `json:"max_size,omitempty"`
+}
+
+// DefaultMaxMetadataSize is the largest custom_hook_metadata decoded when max_size is not set, far
+// above what routing information needs
+const DefaultMaxMetadataSize = 16 * 1024
+
+// skippedMetadataPrefix is how many bytes of oversize metadata a skipped deposit keeps
+const skippedMetadataPrefix = 256
+
+// MetadataReceipt is the CustomHookMetadata forwarded in receipt mode
+type MetadataReceipt struct {
+	SourceTxHash string `json:"source_tx_hash"` // Comma-separated for aggregated routes
+	SourceHeight int64  `json:"source_height,omitempty"`
+	Amount       string `json:"amount"`
+	MetadataHash string `json:"metadata_sha256,omitempty"` // SHA-256 of the deposit's custom_hook_metadata
+}
+
+// SizeLimit returns the largest custom_hook_metadata in bytes the parser decodes
+func (m MetadataConfig) SizeLimit() int {
+	if m.MaxSize == 0 {
+		return DefaultMaxMetadataSize
+	}
+	return m.MaxSize
+}
+
+// SkippedMetadata identifies the metadata of a deposit skipped without decoding it: its size, its
+// SHA-256 and its first bytes, so operators can look into it without the report holding all of it
+type SkippedMetadata struct {
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+	Prefix string `json:"prefix"`
+}
+
+// NewSkippedMetadata records metadata that was not decoded
+func NewSkippedMetadata(metadata string) *SkippedMetadata {
+	sum := sha256.Sum256([]byte(metadata))
+	prefix := metadata
+	if len(prefix) > skippedMetadataPrefix {
+		prefix = prefix[:skippedMetadataPrefix]
+		// Do not cut a character in two
+		for len(prefix) > 0 && !utf8.ValidString(prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return &SkippedMetadata{Size: len(metadata), SHA256: hex.EncodeToString(sum[:]), Prefix: prefix}
+}
+
+// Enabled reports whether generated transfers carry forwarded metadata
+func (m MetadataConfig) Enabled() bool {
+	return m.Forward != ""
+}
+
+// Validate checks the forwarding mode
+func (m MetadataConfig) Validate() error {
+	if m.MaxSize < 0 {
+		return fmt.Errorf("invalid max_size %d: must not be negative", m.MaxSize)
+	}
+	switch m.Forward {
+	case "", ForwardMetadataOriginal, ForwardMetadataReceipt:
+		return nil
+	}
+	return fmt.Errorf("invalid forward mode %q: must be %s or %s", m.Forward, ForwardMetadataOriginal, ForwardMetadataReceipt)
+}
+
+// ForwardedMetadata returns the CustomHookMetadata the transfer generated for route must carry.
+// Aggregated routes merge several deposits, so their original metadata cannot be forwarded.
+func (m MetadataConfig) ForwardedMetadata(route *HyperlaneRoute) (string, error) {
+	switch m.Forward {
+	case "":
+		return "", nil
+	case ForwardMetadataOriginal:
+		if strings.Contains(route.TxHash, ",") {
+			return "", fmt.Errorf("route from txs %s is aggregated and has no single metadata to forward", route.TxHash)
+		}
+		return route.CustomHookMetadata, nil
+	case ForwardMetadataReceipt:
+		receipt := MetadataReceipt{
+			SourceTxHash: route.TxHash,
+			SourceHeight: route.BlockHeight,
+			Amount:       route.Amount,
+		}
+		if route.RouteInfo != nil && route.RouteInfo.Amount != "" {
+			receipt.Amount = route.RouteInfo.Amount
+		}
+		if route.CustomHookMetadata != "" {
+			sum := sha256.Sum256([]byte(route.CustomHookMetadata))
+			receipt.MetadataHash = hex.EncodeToString(sum[:])
+		}
+		data, err := json.Marshal(receipt)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode metadata receipt: %w", err)
+		}
+		return string(data), nil
+	}
+	return "", m.Validate()
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestForwardedMetadata(t *testing.T) {
+	metadata := `{"destination_domain":2340,"recipient":"0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0","token_id":"0x01"}`
+	route := &HyperlaneRoute{
+		TxHash:             "ABC",
+		BlockHeight:        2500042,
+		Amount:             "1000",
+		CustomHookMetadata: metadata,
+		RouteInfo:          &RouteInfo{Amount: "900"},
+	}
+
+	got, err := MetadataConfig{}.ForwardedMetadata(route)
+	if err != nil || got != "" {
+		t.Errorf("default ForwardedMetadata() = %q, %v, want empty", got, err)
+	}
+
+	got, err = MetadataConfig{Forward: ForwardMetadataOriginal}.ForwardedMetadata(route)
+	if err != nil || got != metadata {
+		t.Errorf("original ForwardedMetadata() = %q, %v, want the deposit metadata", got, err)
+	}
+
+	got, err = MetadataConfig{Forward: ForwardMetadataReceipt}.ForwardedMetadata(route)
+	if err != nil {
+		t.Fatalf("receipt ForwardedMetadata() error = %v", err)
+	}
+	var receipt MetadataReceipt
+	if err := json.Unmarshal([]byte(got), &receipt); err != nil {
+		t.Fatalf("receipt is not JSON: %v", err)
+	}
+	if receipt.SourceTxHash != "ABC" || receipt.SourceHeight != 2500042 || receipt.Amount != "900" || len(receipt.MetadataHash) != 64 {
+		t.Errorf("unexpected receipt %+v", receipt)
+	}
+
+	aggregated := *route
+	aggregated.TxHash = "ABC,DEF"
+	if _, err := (MetadataConfig{Forward: ForwardMetadataOriginal}).ForwardedMetadata(&aggregated); err == nil {
+		t.Error("original ForwardedMetadata() accepted an aggregated route")
+	}
+
+	if err := (MetadataConfig{Forward: "hash"}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown forward mode")
+	}
+}
+
+func TestNewSkippedMetadata(t *testing.T) {
+	// A two-byte character straddles the end of the kept prefix
+	metadata := strings.Repeat("a", 255) + "é" + strings.Repeat("b", 1000)
+	skipped := NewSkippedMetadata(metadata)
+	if skipped.Size != len(metadata) {
+		t.Errorf("Size = %d, want %d", skipped.Size, len(metadata))
+	}
+	if skipped.Prefix != strings.Repeat("a", 255) {
+		t.Errorf("Prefix = %q, want the 255 bytes before the cut character", skipped.Prefix)
+	}
+	sum := sha256.Sum256([]byte(metadata))
+	if skipped.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("SHA256 = %s, want the hash of the whole metadata", skipped.SHA256)
+	}
+
+	if short := NewSkippedMetadata(`{"destination_domain":1}`); short.Prefix != `{"destination_domain":1}` {
+		t.Errorf("Prefix = %q, want short metadata kept whole", short.Prefix)
+	}
+}
+
+func TestMetadataSizeLimit(t *testing.T) {
+	if limit := (MetadataConfig{}).SizeLimit(); limit != DefaultMaxMetadataSize {
+		t.Errorf("SizeLimit() = %d, want the default %d", limit, DefaultMaxMetadataSize)
+	}
+	if limit := (MetadataConfig{MaxSize: 512}).SizeLimit(); limit != 512 {
+		t.Errorf("SizeLimit() = %d, want 512", limit)
+	}
+	if err := (MetadataConfig{MaxSize: -1}).Validate(); err == nil {
+		t.Error("Validate() accepted a negative max_size")
+	}
+}
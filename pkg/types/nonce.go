@@ -0,0 +1,28 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxNonceLength caps the metadata nonce, which is kept in the state store for every deposit
+const MaxNonceLength = 128
+
+// validateNonce checks a metadata nonce, which is optional
+func validateNonce(nonce string) error {
+	if len(nonce) > MaxNonceLength {
+		return fmt.Errorf("nonce is longer than %d characters", MaxNonceLength)
+	}
+	if nonce != strings.TrimSpace(nonce) {
+		return fmt.Errorf("nonce has leading or trailing whitespace")
+	}
+	return nil
+}
+
+// Nonce returns the replay-protection nonce the route's metadata carries, or "" if it has none
+func (r *HyperlaneRoute) Nonce() string {
+	if r.RouteInfo == nil {
+		return ""
+	}
+	return r.RouteInfo.Nonce
+}
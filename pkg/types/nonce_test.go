@@ -0,0 +1,40 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteInfoValidateNonce(t *testing.T) {
+	tests := []struct {
+		name    string
+		nonce   string
+		wantErr bool
+	}{
+		{"none", "", false},
+		{"reference", "invoice-2025-0042", false},
+		{"longest", strings.Repeat("a", MaxNonceLength), false},
+		{"too long", strings.Repeat("a", MaxNonceLength+1), true},
+		{"padded", " 42", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &RouteInfo{DestinationDomain: 1, Recipient: "0x02", TokenID: "0x01", Nonce: tt.nonce}
+			if err := info.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRouteNonce(t *testing.T) {
+	route := HyperlaneRoute{TxHash: "ABC"}
+	if got := route.Nonce(); got != "" {
+		t.Errorf("Nonce() without route info = %q, want none", got)
+	}
+	route.RouteInfo = &RouteInfo{Nonce: "42"}
+	if got := route.Nonce(); got != "42" {
+		t.Errorf("Nonce() = %q, want 42", got)
+	}
+}
@@ -0,0 +1,157 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// NotifyConfig lists where operator notifications are delivered
+type NotifyConfig struct {
+	Webhooks []string `json:"webhooks,omitempty"` // URLs that receive each event as a JSON POST
+	// Notifiers are webhooks whose event title and message are rendered from templates,
+	// so each team can match its runbook format
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+	Network   string           `json:"network,omitempty"` // Deployment name available to templates, e.g. "mainnet"
+	// Links are named URLs available to templates, e.g. {"runbook": "https://wiki.example.com/rebalancer"}
+	Links  map[string]string `json:"links,omitempty"`
+	Digest DigestConfig      `json:"digest,omitempty"`
+}
+
+// DigestConfig batches the notifications about newly discovered deposits into one digest per
+// window, so deposit surges do not flood the notifiers. Other events are delivered right away.
+type DigestConfig struct {
+	Window    string `json:"window,omitempty"`     // Enables digests, e.g. "15m"
+	ReportURL string `json:"report_url,omitempty"` // Link to the full report included in each digest
+}
+
+// Enabled reports whether discovery notifications are batched into digests
+func (d DigestConfig) Enabled() bool {
+	return d.Window != ""
+}
+
+// Duration returns the parsed digest window, or zero if digests are disabled
+func (d DigestConfig) Duration() (time.Duration, error) {
+	if d.Window == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(d.Window)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid digest window %q: must be a positive duration", d.Window)
+	}
+	return window, nil
+}
+
+// Notifier types
+const (
+	NotifierWebhook   = "webhook"   // JSON POST of the event to url
+	NotifierPagerDuty = "pagerduty" // PagerDuty Events API v2 alert
+)
+
+// notifierTypes maps the notifier types a config may use to the check of their settings. Types
+// registered without a check are checked when their notifier is built.
+var (
+	notifierTypesMu sync.RWMutex
+	notifierTypes   = map[string]func(NotifierConfig) error{
+		NotifierWebhook: func(n NotifierConfig) error {
+			if n.URL == "" {
+				return fmt.Errorf("no url")
+			}
+			return nil
+		},
+		NotifierPagerDuty: func(n NotifierConfig) error {
+			if n.RoutingKey == "" {
+				return fmt.Errorf("no routing_key")
+			}
+			return nil
+		},
+	}
+)
+
+// RegisterNotifierType lets configs use notifiers of type typ, whose settings check validates if
+// not nil. notify.Register calls it for notifier types added by programs embedding the rebalancer.
+func RegisterNotifierType(typ string, check func(NotifierConfig) error) {
+	notifierTypesMu.Lock()
+	defer notifierTypesMu.Unlock()
+	notifierTypes[typ] = check
+}
+
+// NotifierTypes returns the notifier types a config may use, sorted
+func NotifierTypes() []string {
+	notifierTypesMu.RLock()
+	defer notifierTypesMu.RUnlock()
+	types := make([]string, 0, len(notifierTypes))
+	for typ := range notifierTypes {
+		types = append(types, typ)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// NotifySeverities are the event severities notifiers can be routed
+var NotifySeverities = []string{"info", "warning", "critical"}
+
+// NotifierConfig is a notification channel with optional Go text/template formats for the events
+// it receives. An empty template keeps the event's own title or message.
+type NotifierConfig struct {
+	Type       string `json:"type,omitempty"`        // NotifierWebhook (default), NotifierPagerDuty or a registered type
+	URL        string `json:"url,omitempty"`         // Webhook URL, or a PagerDuty events endpoint other than the default
+	RoutingKey string `json:"routing_key,omitempty"` // PagerDuty integration key
+	// Severities limits the events the notifier receives, e.g. ["critical"]; empty receives all
+	Severities []string `json:"severities,omitempty"`
+	Title      string   `json:"title,omitempty"`   // e.g. "[{{.Network}}] {{.Title}}"
+	Message    string   `json:"message,omitempty"` // e.g. "{{.Message}}\nRunbook: {{.Links.runbook}}"
+}
+
+// Validate checks that every notifier has a known type with its destination set, that its
+// severities are known and that its templates parse
+func (n NotifyConfig) Validate() error {
+	for i, notifier := range n.Notifiers {
+		typ := notifier.Type
+		if typ == "" {
+			typ = NotifierWebhook
+		}
+		notifierTypesMu.RLock()
+		check, ok := notifierTypes[typ]
+		notifierTypesMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("notifier %d has unknown type %q (want one of %v)", i, notifier.Type, NotifierTypes())
+		}
+		if check != nil {
+			if err := check(notifier); err != nil {
+				return fmt.Errorf("notifier %d: %w", i, err)
+			}
+		}
+		for _, severity := range notifier.Severities {
+			if !slices.Contains(NotifySeverities, severity) {
+				return fmt.Errorf("notifier %d has unknown severity %q (want one of %v)", i, severity, NotifySeverities)
+			}
+		}
+		if _, err := ParseNotifyTemplate("title", notifier.Title); err != nil {
+			return fmt.Errorf("notifier %d: %w", i, err)
+		}
+		if _, err := ParseNotifyTemplate("message", notifier.Message); err != nil {
+			return fmt.Errorf("notifier %d: %w", i, err)
+		}
+	}
+	if !n.Digest.Enabled() && n.Digest.ReportURL != "" {
+		return fmt.Errorf("digest report_url requires a window")
+	}
+	_, err := n.Digest.Duration()
+	return err
+}
+
+// ParseNotifyTemplate parses a notification template. Missing map keys render as empty strings
+// rather than "<no value>". An empty text yields a nil template.
+func ParseNotifyTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNotifyConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  NotifyConfig
+		wantErr bool
+	}{
+		{"empty", NotifyConfig{}, false},
+		{"templates", NotifyConfig{Notifiers: []NotifierConfig{{URL: "https://hooks.example.com", Title: "[{{.Network}}] {{.Title}}"}}}, false},
+		{"no url", NotifyConfig{Notifiers: []NotifierConfig{{Title: "{{.Title}}"}}}, true},
+		{"invalid title", NotifyConfig{Notifiers: []NotifierConfig{{URL: "https://hooks.example.com", Title: "{{.Title"}}}, true},
+		{"pagerduty", NotifyConfig{Notifiers: []NotifierConfig{{Type: NotifierPagerDuty, RoutingKey: "key", Severities: []string{"critical"}}}}, false},
+		{"pagerduty without key", NotifyConfig{Notifiers: []NotifierConfig{{Type: NotifierPagerDuty}}}, true},
+		{"unknown type", NotifyConfig{Notifiers: []NotifierConfig{{Type: "sms", URL: "https://hooks.example.com"}}}, true},
+		{"unknown severity", NotifyConfig{Notifiers: []NotifierConfig{{URL: "https://hooks.example.com", Severities: []string{"error"}}}}, true},
+		{"digest", NotifyConfig{Digest: DigestConfig{Window: "15m", ReportURL: "https://reports.example.com"}}, false},
+		{"invalid digest window", NotifyConfig{Digest: DigestConfig{Window: "-1m"}}, true},
+		{"digest link without window", NotifyConfig{Digest: DigestConfig{ReportURL: "https://reports.example.com"}}, true},
+		{"invalid message", NotifyConfig{Notifiers: []NotifierConfig{{URL: "https://hooks.example.com", Message: "{{end}}"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterNotifierType(t *testing.T) {
+	config := NotifyConfig{Notifiers: []NotifierConfig{{Type: "opsgenie", RoutingKey: "key"}}}
+	if err := config.Validate(); err == nil {
+		t.Fatal("Validate() expected error before the type is registered")
+	}
+	RegisterNotifierType("opsgenie", func(n NotifierConfig) error {
+		if n.RoutingKey == "" {
+			return fmt.Errorf("no routing_key")
+		}
+		return nil
+	})
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	config.Notifiers[0].RoutingKey = ""
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() expected the registered check to reject a missing routing_key")
+	}
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"cosmossdk.io/math"
+)
+
+// Override manually adjusts the amount and/or recipient of the route from a source transaction.
+// Every override must carry a justification, which is recorded in the audit trail.
+type Override struct {
+	TxHash        string `json:"tx_hash"`
+	Amount        string `json:"amount,omitempty"`
+	Recipient     string `json:"recipient,omitempty"`
+	Justification string `json:"justification"`
+}
+
+// Overrides is the contents of an overrides file
+type Overrides struct {
+	Overrides []Override `json:"overrides"`
+}
+
+// AppliedOverride records on a route what an override changed, so verify can highlight it
+type AppliedOverride struct {
+	OriginalAmount    string `json:"original_amount,omitempty"`
+	OriginalRecipient string `json:"original_recipient,omitempty"`
+	Justification     string `json:"justification"`
+}
+
+// Validate checks that the override is well-formed
+func (o *Override) Validate() error {
+	if o.TxHash == "" {
+		return fmt.Errorf("override has no tx_hash")
+	}
+	if o.Justification == "" {
+		return fmt.Errorf("override for tx %s has no justification", o.TxHash)
+	}
+	if o.Amount == "" && o.Recipient == "" {
+		return fmt.Errorf("override for tx %s changes neither amount nor recipient", o.TxHash)
+	}
+	if o.Amount != "" {
+		amount, ok := math.NewIntFromString(o.Amount)
+		if !ok || !amount.IsPositive() {
+			return fmt.Errorf("override for tx %s has invalid amount %s", o.TxHash, o.Amount)
+		}
+	}
+	return nil
+}
+
+// LoadOverrides loads and validates an overrides file
+func LoadOverrides(path string) (*Overrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides file: %w", err)
+	}
+
+	var overrides Overrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides file: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range overrides.Overrides {
+		o := &overrides.Overrides[i]
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[o.TxHash] {
+			return nil, fmt.Errorf("duplicate override for tx %s", o.TxHash)
+		}
+		seen[o.TxHash] = true
+	}
+
+	return &overrides, nil
+}
+
+// OverrideSummary describes what a manual override changed on the route, or returns "" if the
+// route was not overridden
+func (r *HyperlaneRoute) OverrideSummary() string {
+	if r.Override == nil {
+		return ""
+	}
+
+	var changes []string
+	if r.Override.OriginalAmount != "" {
+		changes = append(changes, fmt.Sprintf("amount %s -> %s", r.Override.OriginalAmount, r.Amount))
+	}
+	if r.Override.OriginalRecipient != "" && r.RouteInfo != nil {
+		changes = append(changes, fmt.Sprintf("recipient %s -> %s", r.Override.OriginalRecipient, r.RouteInfo.Recipient))
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "no change")
+	}
+	return fmt.Sprintf("%s (justification: %s)", strings.Join(changes, ", "), r.Override.Justification)
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			content: `{"overrides":[{"tx_hash":"A1","amount":"100","justification":"refund fee"}]}`,
+		},
+		{
+			name:    "missing justification",
+			content: `{"overrides":[{"tx_hash":"A1","amount":"100"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "no change",
+			content: `{"overrides":[{"tx_hash":"A1","justification":"noop"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "zero amount",
+			content: `{"overrides":[{"tx_hash":"A1","amount":"0","justification":"drop"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate",
+			content: `{"overrides":[{"tx_hash":"A1","amount":"1","justification":"a"},{"tx_hash":"A1","amount":"2","justification":"b"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "overrides.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write overrides: %v", err)
+			}
+
+			_, err := LoadOverrides(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
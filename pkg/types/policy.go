@@ -0,0 +1,63 @@
+package types
+
+import "fmt"
+
+// Warning classes generate raises that a policy can promote to errors
+const (
+	// WarningFirstSeenRecipient flags a route to a recipient no earlier transfer on its domain went
+	// to, according to the state database
+	WarningFirstSeenRecipient = "first_seen_recipient"
+	// WarningAmountOverride flags a route whose metadata amount differs from the amount deposited
+	WarningAmountOverride = "amount_override"
+	// WarningDecimalsMismatch flags a destination whose scale does not match the decimals of the
+	// token its router pays out
+	WarningDecimalsMismatch = "decimals_mismatch"
+)
+
+// warningClasses lists the known warning classes
+var warningClasses = []string{WarningFirstSeenRecipient, WarningAmountOverride, WarningDecimalsMismatch}
+
+// PolicyConfig tightens generate for conservative operators
+type PolicyConfig struct {
+	// FailOn lists warning classes that fail generation instead of only being printed, e.g.
+	// ["first_seen_recipient", "amount_override", "decimals_mismatch"]
+	FailOn []string `json:"fail_on,omitempty"`
+	// RouteHooks are run on every route before generation, in order, and may reject a route, lower
+	// its amount or annotate it
+	RouteHooks []RouteHookConfig `json:"route_hooks,omitempty"`
+}
+
+// Validate checks that the policy only names known warning classes and route hooks
+func (p PolicyConfig) Validate() error {
+	for _, class := range p.FailOn {
+		if err := ValidateWarningClass(class); err != nil {
+			return err
+		}
+	}
+	for i, hook := range p.RouteHooks {
+		if err := hook.Validate(); err != nil {
+			return fmt.Errorf("route_hooks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateWarningClass checks that class is a known warning class
+func ValidateWarningClass(class string) error {
+	for _, known := range warningClasses {
+		if class == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown warning class %q, want one of %v", class, warningClasses)
+}
+
+// FailsOn reports whether warnings of class fail generation
+func (p PolicyConfig) FailsOn(class string) bool {
+	for _, c := range p.FailOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
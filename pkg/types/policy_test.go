@@ -0,0 +1,56 @@
+package types
+
+import "testing"
+
+func TestPolicyConfig(t *testing.T) {
+	policy := PolicyConfig{FailOn: []string{WarningAmountOverride, WarningDecimalsMismatch}}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !policy.FailsOn(WarningAmountOverride) || !policy.FailsOn(WarningDecimalsMismatch) {
+		t.Error("FailsOn() = false for a listed class")
+	}
+	if policy.FailsOn(WarningFirstSeenRecipient) {
+		t.Error("FailsOn() = true for a class that is not listed")
+	}
+	if (PolicyConfig{}).FailsOn(WarningAmountOverride) {
+		t.Error("FailsOn() = true for an empty policy")
+	}
+
+	if err := (PolicyConfig{FailOn: []string{"new_recipient"}}).Validate(); err == nil {
+		t.Error("Validate() accepted an unknown warning class")
+	}
+}
+
+func TestRouteHookConfig(t *testing.T) {
+	valid := []RouteHookConfig{
+		{Type: HookMinAmount, Amount: "1000"},
+		{Type: HookCapAmount, Amount: "500000000", Name: "weekly-cap"},
+		{Type: HookDenySenders, Senders: []string{"celestia1blocked"}},
+		{Type: HookMaxAgeBlocks, Blocks: 100},
+		{Type: HookAnnotate, Annotations: map[string]string{"batch": "weekly"}},
+	}
+	if err := (PolicyConfig{RouteHooks: valid}).Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if name := valid[1].HookName(); name != "weekly-cap" {
+		t.Errorf("HookName() = %q, want the configured name", name)
+	}
+	if name := valid[0].HookName(); name != HookMinAmount {
+		t.Errorf("HookName() = %q, want the type", name)
+	}
+
+	invalid := []RouteHookConfig{
+		{Type: "max_amount", Amount: "1000"},
+		{Type: HookMinAmount},
+		{Type: HookCapAmount, Amount: "-5"},
+		{Type: HookDenySenders},
+		{Type: HookMaxAgeBlocks},
+		{Type: HookAnnotate},
+	}
+	for _, hook := range invalid {
+		if err := (PolicyConfig{RouteHooks: []RouteHookConfig{hook}}).Validate(); err == nil {
+			t.Errorf("Validate() accepted %+v", hook)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package types
+
+import "fmt"
+
+// Route priority orders
+const (
+	PriorityOldestFirst  = "oldest_first"  // Lowest block height first
+	PriorityLargestFirst = "largest_first" // Largest amount first
+)
+
+// PriorityConfig orders routes before caps pick the subset generated in a run, so the routes that
+// matter most are not the ones deferred or held
+type PriorityConfig struct {
+	Order string `json:"order,omitempty"` // oldest_first (the default) or largest_first
+	// DomainWeights puts routes to domains with a higher weight first, before Order applies.
+	// Unlisted domains weigh 0.
+	DomainWeights map[uint32]int `json:"domain_weights,omitempty"`
+}
+
+// Validate checks that the order is known
+func (p PriorityConfig) Validate() error {
+	switch p.Order {
+	case "", PriorityOldestFirst, PriorityLargestFirst:
+		return nil
+	}
+	return fmt.Errorf("unknown order %s", p.Order)
+}
@@ -0,0 +1,14 @@
+package types
+
+import "testing"
+
+func TestPriorityConfigValidate(t *testing.T) {
+	for _, order := range []string{"", PriorityOldestFirst, PriorityLargestFirst} {
+		if err := (PriorityConfig{Order: order}).Validate(); err != nil {
+			t.Errorf("Validate() error = %v for order %q", err, order)
+		}
+	}
+	if err := (PriorityConfig{Order: "random"}).Validate(); err == nil {
+		t.Error("Validate() expected an error for an unknown order")
+	}
+}
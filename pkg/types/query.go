@@ -0,0 +1,41 @@
+package types
+
+// Defaults for transaction queries, used when the config does not specify them
+const (
+	DefaultPageSize          = 100
+	DefaultMaxPagesPerHeight = 10
+	DefaultConcurrency       = 8
+)
+
+// QueryConfig bounds the transaction queries made against the node, so operators can tune
+// them to what their node serves
+type QueryConfig struct {
+	PageSize          uint64 `json:"page_size,omitempty"`            // Transactions requested per GetTxsEvent page
+	MaxPagesPerHeight int    `json:"max_pages_per_height,omitempty"` // Pages fetched per height before the height is reported as failed
+	MaxTxs            int    `json:"max_txs,omitempty"`              // Overall transactions per parse run; 0 means unlimited
+	Concurrency       int    `json:"concurrency,omitempty"`          // Transactions fetched by hash in parallel
+}
+
+// DefaultQueryConfig returns the default query limits
+func DefaultQueryConfig() QueryConfig {
+	return QueryConfig{
+		PageSize:          DefaultPageSize,
+		MaxPagesPerHeight: DefaultMaxPagesPerHeight,
+		Concurrency:       DefaultConcurrency,
+	}
+}
+
+// WithDefaults returns a copy of the query config with unset fields filled from DefaultQueryConfig
+func (q QueryConfig) WithDefaults() QueryConfig {
+	defaults := DefaultQueryConfig()
+	if q.PageSize == 0 {
+		q.PageSize = defaults.PageSize
+	}
+	if q.MaxPagesPerHeight == 0 {
+		q.MaxPagesPerHeight = defaults.MaxPagesPerHeight
+	}
+	if q.Concurrency == 0 {
+		q.Concurrency = defaults.Concurrency
+	}
+	return q
+}
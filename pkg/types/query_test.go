@@ -0,0 +1,22 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestQueryConfigWithDefaults(t *testing.T) {
+	query := QueryConfig{PageSize: 50, MaxTxs: 1000}.WithDefaults()
+
+	if query.PageSize != 50 {
+		t.Errorf("PageSize = %d, want 50", query.PageSize)
+	}
+	if query.MaxPagesPerHeight != DefaultMaxPagesPerHeight {
+		t.Errorf("MaxPagesPerHeight = %d, want %d", query.MaxPagesPerHeight, DefaultMaxPagesPerHeight)
+	}
+	if query.MaxTxs != 1000 {
+		t.Errorf("MaxTxs = %d, want 1000", query.MaxTxs)
+	}
+	if query.Concurrency != DefaultConcurrency {
+		t.Errorf("Concurrency = %d, want %d", query.Concurrency, DefaultConcurrency)
+	}
+}
@@ -0,0 +1,168 @@
+package types
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+)
+
+// RebalancingFeeConfig is the operating fee the multisig keeps from every transfer it forwards:
+// a share of the amount in basis points plus a flat amount, bounded by min and max. Amounts are in
+// the smallest unit of the transferred denom.
+type RebalancingFeeConfig struct {
+	BPS  uint32 `json:"bps,omitempty"`  // Basis points of the transferred amount, e.g. 25 for 0.25%
+	Flat string `json:"flat,omitempty"` // Added to every transfer's fee
+	Min  string `json:"min,omitempty"`  // Least fee of a transfer
+	Max  string `json:"max,omitempty"`  // Most fee of a transfer
+}
+
+// Enabled reports whether any fee is kept
+func (f RebalancingFeeConfig) Enabled() bool {
+	return f.BPS > 0 || f.Flat != "" || f.Min != ""
+}
+
+// Validate checks that the basis points are at most 10000 and the amounts are non-negative
+// integers, with min not above max
+func (f RebalancingFeeConfig) Validate() error {
+	if f.BPS > 10000 {
+		return fmt.Errorf("bps %d is above 10000", f.BPS)
+	}
+	amounts := make(map[string]math.Int)
+	for name, value := range map[string]string{"flat": f.Flat, "min": f.Min, "max": f.Max} {
+		amount, err := feeAmount(name, value)
+		if err != nil {
+			return err
+		}
+		amounts[name] = amount
+	}
+	if !amounts["min"].IsNil() && !amounts["max"].IsNil() && amounts["min"].GT(amounts["max"]) {
+		return fmt.Errorf("min %s is above max %s", f.Min, f.Max)
+	}
+	return nil
+}
+
+// Fee returns the fee kept from a transfer of amount. The share in basis points is rounded down.
+func (f RebalancingFeeConfig) Fee(amount math.Int) (math.Int, error) {
+	fee := amount.MulRaw(int64(f.BPS)).QuoRaw(10000)
+	flat, err := feeAmount("flat", f.Flat)
+	if err != nil {
+		return math.Int{}, err
+	}
+	if !flat.IsNil() {
+		fee = fee.Add(flat)
+	}
+	min, err := feeAmount("min", f.Min)
+	if err != nil {
+		return math.Int{}, err
+	}
+	if !min.IsNil() && fee.LT(min) {
+		fee = min
+	}
+	max, err := feeAmount("max", f.Max)
+	if err != nil {
+		return math.Int{}, err
+	}
+	if !max.IsNil() && fee.GT(max) {
+		fee = max
+	}
+	return fee, nil
+}
+
+// Deduct returns the amount a transfer of amount forwards once the fee is kept, and the fee. An
+// amount that does not leave anything to forward is refused.
+func (f RebalancingFeeConfig) Deduct(amount math.Int) (forwarded, fee math.Int, err error) {
+	if !f.Enabled() {
+		return amount, math.ZeroInt(), nil
+	}
+	fee, err = f.Fee(amount)
+	if err != nil {
+		return math.Int{}, math.Int{}, err
+	}
+	forwarded = amount.Sub(fee)
+	if !forwarded.IsPositive() {
+		return math.Int{}, math.Int{}, fmt.Errorf("amount %s does not cover the rebalancing fee of %s", amount, fee)
+	}
+	return forwarded, fee, nil
+}
+
+// KeptFee records the rebalancing fee kept from a deposit before it was forwarded
+type KeptFee struct {
+	Deposited string `json:"deposited"` // Amount of the deposit the fee was computed on
+	Fee       string `json:"fee"`
+}
+
+// DeductFrom keeps the fee from the deposit of route: the route forwards its amount less the fee
+// and records what was kept. The fee is per deposit, so a route must be deducted before its splits
+// are expanded and before it is aggregated or split; routes the fee was already kept from are left
+// alone.
+func (f RebalancingFeeConfig) DeductFrom(route *HyperlaneRoute) error {
+	if !f.Enabled() || route.FeeKept() {
+		return nil
+	}
+	amount, ok := math.NewIntFromString(route.Amount)
+	if !ok {
+		return fmt.Errorf("invalid amount %s in route from tx %s", route.Amount, route.TxHash)
+	}
+	forwarded, fee, err := f.Deduct(amount)
+	if err != nil {
+		return fmt.Errorf("route from tx %s: %w", route.TxHash, err)
+	}
+
+	route.RebalancingFee = &KeptFee{Deposited: amount.String(), Fee: fee.String()}
+	route.Amount = forwarded.String()
+	// Keep a metadata amount override in sync so the verifier expects the forwarded amount
+	if route.RouteInfo != nil && route.RouteInfo.Amount != "" {
+		info := *route.RouteInfo
+		info.Amount = route.Amount
+		route.RouteInfo = &info
+	}
+	return nil
+}
+
+// Check returns an error unless kept is exactly the fee the policy keeps from its deposit
+func (f RebalancingFeeConfig) Check(kept KeptFee) error {
+	deposited, ok := math.NewIntFromString(kept.Deposited)
+	if !ok || !deposited.IsPositive() {
+		return fmt.Errorf("invalid deposited amount %s", kept.Deposited)
+	}
+	want, err := f.Fee(deposited)
+	if err != nil {
+		return err
+	}
+	if kept.Fee != want.String() {
+		return fmt.Errorf("keeps a rebalancing fee of %s from a deposit of %s, the policy's fee is %s", kept.Fee, kept.Deposited, want)
+	}
+	return nil
+}
+
+// FeeKept reports whether the rebalancing fee was kept from the route's deposits
+func (r *HyperlaneRoute) FeeKept() bool {
+	return len(r.KeptFees()) > 0
+}
+
+// KeptFees returns the rebalancing fees kept from the route's deposits: its own, or those of its
+// aggregated deposits
+func (r *HyperlaneRoute) KeptFees() []KeptFee {
+	if r.RebalancingFee != nil {
+		return []KeptFee{*r.RebalancingFee}
+	}
+	var fees []KeptFee
+	for _, source := range r.Provenance {
+		if source.RebalancingFee != nil {
+			fees = append(fees, *source.RebalancingFee)
+		}
+	}
+	return fees
+}
+
+// feeAmount parses a fee amount, returning a nil Int if it is unset
+func feeAmount(name, value string) (math.Int, error) {
+	if value == "" {
+		return math.Int{}, nil
+	}
+	amount, ok := math.NewIntFromString(value)
+	if !ok || amount.IsNegative() {
+		return math.Int{}, fmt.Errorf("invalid %s %s", name, value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+func TestRebalancingFeeConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RebalancingFeeConfig
+		wantErr bool
+	}{
+		{name: "empty", config: RebalancingFeeConfig{}},
+		{name: "bps with bounds", config: RebalancingFeeConfig{BPS: 25, Flat: "100", Min: "500", Max: "100000"}},
+		{name: "bps above 100%", config: RebalancingFeeConfig{BPS: 10001}, wantErr: true},
+		{name: "negative flat", config: RebalancingFeeConfig{Flat: "-1"}, wantErr: true},
+		{name: "invalid max", config: RebalancingFeeConfig{Max: "lots"}, wantErr: true},
+		{name: "min above max", config: RebalancingFeeConfig{Min: "500", Max: "100"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRebalancingFeeDeduct(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        RebalancingFeeConfig
+		amount        int64
+		wantForwarded int64
+		wantErr       bool
+	}{
+		{name: "disabled", config: RebalancingFeeConfig{}, amount: 1000, wantForwarded: 1000},
+		{name: "bps", config: RebalancingFeeConfig{BPS: 25}, amount: 1_000_000, wantForwarded: 997_500},
+		{name: "bps rounded down", config: RebalancingFeeConfig{BPS: 25}, amount: 1_999, wantForwarded: 1_995},
+		{name: "bps plus flat", config: RebalancingFeeConfig{BPS: 100, Flat: "50"}, amount: 10_000, wantForwarded: 9_850},
+		{name: "min", config: RebalancingFeeConfig{BPS: 10, Min: "500"}, amount: 10_000, wantForwarded: 9_500},
+		{name: "max", config: RebalancingFeeConfig{BPS: 100, Max: "1000"}, amount: 1_000_000, wantForwarded: 999_000},
+		{name: "fee takes everything", config: RebalancingFeeConfig{Flat: "1000"}, amount: 1000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded, fee, err := tt.config.Deduct(math.NewInt(tt.amount))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deduct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if forwarded.Int64() != tt.wantForwarded || forwarded.Add(fee).Int64() != tt.amount {
+				t.Errorf("Deduct(%d) = %s + fee %s, want %d forwarded", tt.amount, forwarded, fee, tt.wantForwarded)
+			}
+		})
+	}
+}
+
+func TestRebalancingFeeDeductFrom(t *testing.T) {
+	fee := RebalancingFeeConfig{BPS: 25, Min: "1000"}
+	route := &HyperlaneRoute{TxHash: "ABC", Amount: "1000000", RouteInfo: &RouteInfo{Amount: "1000000"}}
+	info := route.RouteInfo
+
+	if err := fee.DeductFrom(route); err != nil {
+		t.Fatalf("DeductFrom() error = %v", err)
+	}
+	if route.Amount != "997500" || route.RouteInfo.Amount != "997500" || info.Amount != "1000000" {
+		t.Errorf("amount = %s (metadata %s), want 997500 without modifying the route info", route.Amount, route.RouteInfo.Amount)
+	}
+	if kept := route.RebalancingFee; kept == nil || kept.Deposited != "1000000" || kept.Fee != "2500" {
+		t.Errorf("RebalancingFee = %+v, want 2500 of 1000000", kept)
+	}
+	if err := fee.Check(*route.RebalancingFee); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	// A route is deducted once, and aggregated routes carry the fees of their deposits
+	if err := fee.DeductFrom(route); err != nil || route.Amount != "997500" {
+		t.Errorf("DeductFrom() = %v, amount %s, want the fee kept once", err, route.Amount)
+	}
+	aggregated := &HyperlaneRoute{Amount: "98000", Provenance: []RouteSource{
+		{TxHash: "A", Amount: "49000", RebalancingFee: &KeptFee{Deposited: "50000", Fee: "1000"}},
+		{TxHash: "B", Amount: "49000", RebalancingFee: &KeptFee{Deposited: "50000", Fee: "1000"}},
+	}}
+	if err := fee.DeductFrom(aggregated); err != nil || aggregated.Amount != "98000" || len(aggregated.KeptFees()) != 2 {
+		t.Errorf("DeductFrom() = %v, amount %s, want an aggregated route left alone", err, aggregated.Amount)
+	}
+
+	if err := fee.Check(KeptFee{Deposited: "1000000", Fee: "2000"}); err == nil {
+		t.Error("Check() accepted a fee below the policy's")
+	}
+	if err := fee.DeductFrom(&HyperlaneRoute{Amount: "1000"}); err == nil {
+		t.Error("DeductFrom() accepted a deposit not covering the fee")
+	}
+	if err := (RebalancingFeeConfig{}).DeductFrom(&HyperlaneRoute{Amount: "5000"}); err != nil {
+		t.Errorf("DeductFrom() without a fee error = %v", err)
+	}
+}
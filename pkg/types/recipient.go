@@ -0,0 +1,72 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// RecipientConfig holds the settings of transfers to one whitelisted recipient, for destinations
+// with quirks of their own, e.g. a vault contract that needs more gas on delivery than the
+// domain's other recipients
+type RecipientConfig struct {
+	GasLimit uint64 `json:"gas_limit,omitempty"` // Destination gas limit, replacing the domain's gas_limit
+	HookID   string `json:"hook_id,omitempty"`   // Post-dispatch hook of the transfers instead of the token's default
+}
+
+// Hook returns the hook ID as bytes, and false if no hook is set
+func (r RecipientConfig) Hook() ([32]byte, bool, error) {
+	var id [32]byte
+	if r.HookID == "" {
+		return id, false, nil
+	}
+	hexPart, ok := cutHexPrefix(strings.TrimSpace(r.HookID))
+	if !ok {
+		return id, false, fmt.Errorf("hook_id %s is not 0x-prefixed", r.HookID)
+	}
+	decoded, err := hex.DecodeString(hexPart)
+	if err != nil || len(decoded) != 32 {
+		return id, false, fmt.Errorf("hook_id %s is not a 32-byte hex ID", r.HookID)
+	}
+	copy(id[:], decoded)
+	return id, true, nil
+}
+
+// validateRecipients checks that every recipient with settings is whitelisted for its domain and
+// that its hook ID is well-formed
+func (w AddressWhitelist) validateRecipients() error {
+	for domain, recipients := range w.Recipients {
+		for recipient, settings := range recipients {
+			if !w.allowsRecipient(domain, recipient) {
+				return fmt.Errorf("recipients of domain %d: %s is not whitelisted", domain, recipient)
+			}
+			if _, _, err := settings.Hook(); err != nil {
+				return fmt.Errorf("recipients of domain %d: %s: %w", domain, recipient, err)
+			}
+		}
+	}
+	return nil
+}
+
+// allowsRecipient reports whether recipient is whitelisted for domain
+func (w AddressWhitelist) allowsRecipient(domain uint32, recipient string) bool {
+	normalized := NormalizeAddress(recipient)
+	for _, addr := range w.Domains[domain] {
+		if NormalizeAddress(addr) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// RecipientSettings returns the settings of transfers to recipient on domain, empty when the
+// whitelist has none for it
+func (w AddressWhitelist) RecipientSettings(domain uint32, recipient string) RecipientConfig {
+	normalized := NormalizeAddress(recipient)
+	for addr, settings := range w.Recipients[domain] {
+		if NormalizeAddress(addr) == normalized {
+			return settings
+		}
+	}
+	return RecipientConfig{}
+}
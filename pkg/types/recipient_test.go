@@ -0,0 +1,43 @@
+package types
+
+import "testing"
+
+func TestWhitelistRecipients(t *testing.T) {
+	vault := "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+	hookID := "0x726f757465725f706f73745f6469737061746368000000030000000000000001"
+	whitelist := AddressWhitelist{
+		Domains: map[uint32][]string{1: {vault}},
+		Recipients: map[uint32]map[string]RecipientConfig{
+			1: {vault: {GasLimit: 500000, HookID: hookID}},
+		},
+	}
+	if err := whitelist.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	whitelist.normalize()
+	// The padded 32-byte form of the address names the same recipient
+	if got := whitelist.RecipientSettings(1, "0x000000000000000000000000742d35cc6634c0532925a3b844bc9e7595f0beb0"); got.GasLimit != 500000 {
+		t.Errorf("RecipientSettings() = %+v, want the vault's settings", got)
+	}
+	if got := whitelist.RecipientSettings(2, vault); got != (RecipientConfig{}) {
+		t.Errorf("RecipientSettings() on another domain = %+v, want none", got)
+	}
+
+	tests := []struct {
+		name       string
+		recipients map[uint32]map[string]RecipientConfig
+	}{
+		{"recipient not whitelisted", map[uint32]map[string]RecipientConfig{1: {"0x1111111111111111111111111111111111111111": {GasLimit: 1}}}},
+		{"domain not whitelisted", map[uint32]map[string]RecipientConfig{2: {vault: {GasLimit: 1}}}},
+		{"short hook ID", map[uint32]map[string]RecipientConfig{1: {vault: {HookID: "0x1234"}}}},
+		{"hook ID without prefix", map[uint32]map[string]RecipientConfig{1: {vault: {HookID: hookID[2:]}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := AddressWhitelist{Domains: map[uint32][]string{1: {vault}}, Recipients: tt.recipients}
+			if err := w.Validate(); err == nil {
+				t.Error("Validate() accepted invalid recipient settings")
+			}
+		})
+	}
+}
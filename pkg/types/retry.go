@@ -0,0 +1,74 @@
+package types
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Defaults for the retry queue, used when the config does not specify them
+const (
+	DefaultInitialBackoff = time.Minute
+	DefaultMaxBackoff     = time.Hour
+	DefaultMaxAttempts    = 5
+)
+
+// RetryConfig controls the queue that failed routes are retried from. The backoff doubles after
+// every failed attempt, starting at InitialBackoff and capped at MaxBackoff.
+type RetryConfig struct {
+	QueueFile      string `json:"queue_file,omitempty"`       // Enables the retry queue
+	InitialBackoff string `json:"initial_backoff,omitempty"`  // Delay before the first retry, e.g. "1m"
+	MaxBackoff     string `json:"max_backoff,omitempty"`      // Upper bound on the delay between retries
+	MaxAttempts    int    `json:"max_attempts,omitempty"`     // Attempts before a route is moved to the dead-letter file
+	DeadLetterFile string `json:"dead_letter_file,omitempty"` // Routes given up on; defaults to a file next to the queue
+}
+
+// DefaultRetryConfig returns the default retry settings; the queue itself stays disabled
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		InitialBackoff: DefaultInitialBackoff.String(),
+		MaxBackoff:     DefaultMaxBackoff.String(),
+		MaxAttempts:    DefaultMaxAttempts,
+	}
+}
+
+// WithDefaults returns a copy of the retry config with unset fields filled from DefaultRetryConfig
+func (r RetryConfig) WithDefaults() RetryConfig {
+	defaults := DefaultRetryConfig()
+	if r.InitialBackoff == "" {
+		r.InitialBackoff = defaults.InitialBackoff
+	}
+	if r.MaxBackoff == "" {
+		r.MaxBackoff = defaults.MaxBackoff
+	}
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = defaults.MaxAttempts
+	}
+	if r.DeadLetterFile == "" && r.QueueFile != "" {
+		r.DeadLetterFile = strings.TrimSuffix(r.QueueFile, filepath.Ext(r.QueueFile)) + "-dead-letter.jsonl"
+	}
+	return r
+}
+
+// Backoff returns the parsed initial and maximum backoff
+func (r RetryConfig) Backoff() (initial, max time.Duration, err error) {
+	initial, err = time.ParseDuration(r.InitialBackoff)
+	if err != nil || initial <= 0 {
+		return 0, 0, fmt.Errorf("invalid initial_backoff %q", r.InitialBackoff)
+	}
+	max, err = time.ParseDuration(r.MaxBackoff)
+	if err != nil || max < initial {
+		return 0, 0, fmt.Errorf("invalid max_backoff %q: must be a duration of at least initial_backoff", r.MaxBackoff)
+	}
+	return initial, max, nil
+}
+
+// Validate checks the retry settings
+func (r RetryConfig) Validate() error {
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts must not be negative")
+	}
+	_, _, err := r.WithDefaults().Backoff()
+	return err
+}
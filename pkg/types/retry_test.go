@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryConfigWithDefaults(t *testing.T) {
+	r := RetryConfig{MaxBackoff: "10m"}.WithDefaults()
+
+	initial, max, err := r.Backoff()
+	if err != nil {
+		t.Fatalf("Backoff() error = %v", err)
+	}
+	if initial != DefaultInitialBackoff || max != 10*time.Minute || r.MaxAttempts != DefaultMaxAttempts {
+		t.Errorf("got %v/%v/%d, want defaults with a 10m max", initial, max, r.MaxAttempts)
+	}
+}
+
+func TestRetryConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RetryConfig
+		wantErr bool
+	}{
+		{name: "empty", config: RetryConfig{}},
+		{name: "custom", config: RetryConfig{InitialBackoff: "30s", MaxBackoff: "5m", MaxAttempts: 3}},
+		{name: "invalid duration", config: RetryConfig{InitialBackoff: "soon"}, wantErr: true},
+		{name: "max below initial", config: RetryConfig{InitialBackoff: "5m", MaxBackoff: "1m"}, wantErr: true},
+		{name: "negative attempts", config: RetryConfig{MaxAttempts: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package types
+
+import "fmt"
+
+// Role restricts which operations a host may perform, for least-privilege deployments
+type Role string
+
+const (
+	RoleUnrestricted Role = ""            // All commands are allowed
+	RoleParser       Role = "parser-only" // Scans the chain and plans, never builds or moves funds
+	RoleCoordinator  Role = "coordinator" // Parses, generates and distributes transactions
+	RoleSigner       Role = "signer"      // Only verifies transactions before signing them
+)
+
+// Validate checks that the role is known
+func (r Role) Validate() error {
+	switch r {
+	case RoleUnrestricted, RoleParser, RoleCoordinator, RoleSigner:
+		return nil
+	}
+	return fmt.Errorf("unknown role %q, expected %s, %s or %s", r, RoleParser, RoleCoordinator, RoleSigner)
+}
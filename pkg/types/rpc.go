@@ -0,0 +1,128 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// Defaults for retrying gRPC calls, used when the config does not specify them
+const (
+	DefaultRPCMaxAttempts    = 4
+	DefaultRPCInitialBackoff = 500 * time.Millisecond
+	DefaultRPCMaxBackoff     = 10 * time.Second
+)
+
+// RPCConfig holds the connection settings of the chain's gRPC endpoint, for public endpoints that
+// require TLS or an API key. Credentials can be age-encrypted like any other config value.
+//
+// Calls failing with a transient error, e.g. while a node restarts, are retried with a backoff that
+// doubles after every attempt, starting at InitialBackoff and capped at MaxBackoff. With fallback
+// endpoints, the client fails over to the next healthy one before retrying.
+type RPCConfig struct {
+	TLS            bool     `json:"tls,omitempty"`             // Connect over TLS, verified against the system roots
+	CAFile         string   `json:"ca_file,omitempty"`         // PEM bundle to verify the endpoint against instead; implies tls
+	BearerToken    string   `json:"bearer_token,omitempty"`    // Sent as "authorization: Bearer <token>"
+	Username       string   `json:"username,omitempty"`        // Basic auth user, sent with Password
+	Password       string   `json:"password,omitempty"`        // Basic auth password, sent with Username
+	CallTimeout    string   `json:"call_timeout,omitempty"`    // Bound on each gRPC call, e.g. "30s"
+	FallbackURLs   []string `json:"fallback_urls,omitempty"`   // Endpoints of the same chain to fail over to, in order
+	MaxAttempts    int      `json:"max_attempts,omitempty"`    // Attempts per call across endpoints; 1 disables retries
+	InitialBackoff string   `json:"initial_backoff,omitempty"` // Delay before the first retry, e.g. "500ms"
+	MaxBackoff     string   `json:"max_backoff,omitempty"`     // Upper bound on the delay between retries
+}
+
+// DefaultRPCConfig returns the default retry settings; TLS and credentials stay unset
+func DefaultRPCConfig() RPCConfig {
+	return RPCConfig{
+		MaxAttempts:    DefaultRPCMaxAttempts,
+		InitialBackoff: DefaultRPCInitialBackoff.String(),
+		MaxBackoff:     DefaultRPCMaxBackoff.String(),
+	}
+}
+
+// WithDefaults returns a copy of the connection settings with unset retry fields filled from
+// DefaultRPCConfig
+func (r RPCConfig) WithDefaults() RPCConfig {
+	defaults := DefaultRPCConfig()
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = defaults.MaxAttempts
+	}
+	if r.InitialBackoff == "" {
+		r.InitialBackoff = defaults.InitialBackoff
+	}
+	if r.MaxBackoff == "" {
+		r.MaxBackoff = defaults.MaxBackoff
+	}
+	return r
+}
+
+// Validate checks the connection settings. Credentials are only sent over TLS.
+func (r RPCConfig) Validate() error {
+	if r.BearerToken != "" && (r.Username != "" || r.Password != "") {
+		return fmt.Errorf("bearer_token and username/password are mutually exclusive")
+	}
+	if (r.Username == "") != (r.Password == "") {
+		return fmt.Errorf("username and password must be set together")
+	}
+	if r.Authorization() != "" && !r.TLSEnabled() {
+		return fmt.Errorf("credentials require tls, they are not sent in plaintext")
+	}
+	if _, err := r.Timeout(); err != nil {
+		return err
+	}
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts must not be negative")
+	}
+	for _, url := range r.FallbackURLs {
+		if url == "" {
+			return fmt.Errorf("fallback_urls must not contain empty endpoints")
+		}
+	}
+	if _, _, err := r.WithDefaults().Backoff(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TLSEnabled reports whether the endpoint is reached over TLS
+func (r RPCConfig) TLSEnabled() bool {
+	return r.TLS || r.CAFile != ""
+}
+
+// Authorization returns the value of the authorization header sent with every call, empty when no
+// credentials are set
+func (r RPCConfig) Authorization() string {
+	switch {
+	case r.BearerToken != "":
+		return "Bearer " + r.BearerToken
+	case r.Username != "":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(r.Username+":"+r.Password))
+	}
+	return ""
+}
+
+// Timeout returns the call timeout, zero when none is set
+func (r RPCConfig) Timeout() (time.Duration, error) {
+	if r.CallTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(r.CallTimeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid call_timeout %q: must be a positive duration", r.CallTimeout)
+	}
+	return timeout, nil
+}
+
+// Backoff returns the parsed initial and maximum backoff
+func (r RPCConfig) Backoff() (initial, max time.Duration, err error) {
+	initial, err = time.ParseDuration(r.InitialBackoff)
+	if err != nil || initial <= 0 {
+		return 0, 0, fmt.Errorf("invalid initial_backoff %q", r.InitialBackoff)
+	}
+	max, err = time.ParseDuration(r.MaxBackoff)
+	if err != nil || max < initial {
+		return 0, 0, fmt.Errorf("invalid max_backoff %q: must be a duration of at least initial_backoff", r.MaxBackoff)
+	}
+	return initial, max, nil
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRPCConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rpc     RPCConfig
+		wantErr bool
+	}{
+		{name: "empty", rpc: RPCConfig{}},
+		{name: "tls with bearer token", rpc: RPCConfig{TLS: true, BearerToken: "token"}},
+		{name: "ca file with basic auth", rpc: RPCConfig{CAFile: "ca.pem", Username: "user", Password: "pass"}},
+		{name: "token without tls", rpc: RPCConfig{BearerToken: "token"}, wantErr: true},
+		{name: "token and basic auth", rpc: RPCConfig{TLS: true, BearerToken: "token", Username: "user", Password: "pass"}, wantErr: true},
+		{name: "username without password", rpc: RPCConfig{TLS: true, Username: "user"}, wantErr: true},
+		{name: "timeout", rpc: RPCConfig{CallTimeout: "30s"}},
+		{name: "invalid timeout", rpc: RPCConfig{CallTimeout: "soon"}, wantErr: true},
+		{name: "negative timeout", rpc: RPCConfig{CallTimeout: "-1s"}, wantErr: true},
+		{name: "fallbacks", rpc: RPCConfig{FallbackURLs: []string{"grpc-2.example.com:9090"}, MaxAttempts: 5}},
+		{name: "empty fallback", rpc: RPCConfig{FallbackURLs: []string{""}}, wantErr: true},
+		{name: "negative max attempts", rpc: RPCConfig{MaxAttempts: -1}, wantErr: true},
+		{name: "max backoff below initial", rpc: RPCConfig{InitialBackoff: "5s", MaxBackoff: "1s"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rpc.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRPCConfigAuthorization(t *testing.T) {
+	if got := (RPCConfig{}).Authorization(); got != "" {
+		t.Errorf("Authorization() without credentials = %q, want empty", got)
+	}
+	if got := (RPCConfig{BearerToken: "secret"}).Authorization(); got != "Bearer secret" {
+		t.Errorf("Authorization() = %q, want %q", got, "Bearer secret")
+	}
+	// base64("user:pass")
+	if got := (RPCConfig{Username: "user", Password: "pass"}).Authorization(); got != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Authorization() = %q, want %q", got, "Basic dXNlcjpwYXNz")
+	}
+}
+
+func TestRPCConfigTimeout(t *testing.T) {
+	timeout, err := RPCConfig{CallTimeout: "1m30s"}.Timeout()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeout != 90*time.Second {
+		t.Errorf("Timeout() = %s, want 1m30s", timeout)
+	}
+}
+
+func TestRPCConfigWithDefaults(t *testing.T) {
+	rpc := RPCConfig{MaxAttempts: 1}.WithDefaults()
+	if rpc.MaxAttempts != 1 {
+		t.Errorf("MaxAttempts = %d, want 1", rpc.MaxAttempts)
+	}
+	initial, max, err := rpc.Backoff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initial != DefaultRPCInitialBackoff || max != DefaultRPCMaxBackoff {
+		t.Errorf("Backoff() = %s, %s, want %s, %s", initial, max, DefaultRPCInitialBackoff, DefaultRPCMaxBackoff)
+	}
+	if got := (RPCConfig{}).WithDefaults().MaxAttempts; got != DefaultRPCMaxAttempts {
+		t.Errorf("default MaxAttempts = %d, want %d", got, DefaultRPCMaxAttempts)
+	}
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// SecretPrefix marks a config string value as an age-encrypted secret: "age:" followed by the
+// base64-encoded age ciphertext. Such values are decrypted when the config is loaded.
+const SecretPrefix = "age:"
+
+// AgeIdentityEnv names the environment variable holding the path of the age identity file used
+// to decrypt secrets in the config
+const AgeIdentityEnv = "CELESTIA_REBALANCER_AGE_IDENTITY"
+
+// EncryptSecret encrypts value to the given age recipients and returns it in the form accepted in config files
+func EncryptSecret(value string, recipients []string) (string, error) {
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("at least one recipient is required")
+	}
+
+	parsed, err := ParseAgeRecipients(recipients)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, parsed...)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	if _, err := io.WriteString(w, value); err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	return SecretPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// ParseAgeRecipients parses age X25519 recipient public keys ("age1...")
+func ParseAgeRecipients(recipients []string) ([]age.Recipient, error) {
+	var parsed []age.Recipient
+	for _, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %s: %w", r, err)
+		}
+		parsed = append(parsed, recipient)
+	}
+	return parsed, nil
+}
+
+// decryptConfig returns the plaintext config JSON. A file encrypted with sops is decrypted with
+// the sops binary; afterwards every age-encrypted string value is decrypted in place.
+func decryptConfig(path string, data []byte) ([]byte, error) {
+	var probe struct {
+		SOPS json.RawMessage `json:"sops"`
+	}
+	if err := json.Unmarshal(data, &probe); err == nil && len(probe.SOPS) > 0 {
+		out, err := exec.Command("sops", "--decrypt", "--output-type", "json", path).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt config with sops: %w", err)
+		}
+		data = out
+	}
+
+	if !bytes.Contains(data, []byte(`"`+SecretPrefix)) {
+		return data, nil
+	}
+
+	identities, err := LoadAgeIdentities()
+	if err != nil {
+		return nil, fmt.Errorf("config contains encrypted secrets: %w", err)
+	}
+
+	// Decode generically, keeping numbers as written so large amounts survive the round trip
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+
+	tree, err = decryptValues(tree, identities)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tree)
+}
+
+// decryptValues walks a decoded JSON value and decrypts every secret string in it
+func decryptValues(value interface{}, identities []age.Identity) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			decrypted, err := decryptValues(item, identities)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			v[key] = decrypted
+		}
+	case []interface{}:
+		for i, item := range v {
+			decrypted, err := decryptValues(item, identities)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			v[i] = decrypted
+		}
+	case string:
+		if strings.HasPrefix(v, SecretPrefix) {
+			return decryptSecret(v, identities)
+		}
+	}
+	return value, nil
+}
+
+// decryptSecret decrypts a single "age:" value
+func decryptSecret(value string, identities []age.Identity) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, SecretPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid secret encoding: %w", err)
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), identities...)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// LoadAgeIdentities reads the identity file named by AgeIdentityEnv
+func LoadAgeIdentities() ([]age.Identity, error) {
+	path := os.Getenv(AgeIdentityEnv)
+	if path == "" {
+		return nil, fmt.Errorf("%s is not set", AgeIdentityEnv)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open age identity file: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity file: %w", err)
+	}
+	return identities, nil
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestLoadConfigDecryptsSecrets(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("failed to generate identity: %v", err)
+	}
+
+	dir := t.TempDir()
+	identityPath := filepath.Join(dir, "identity.txt")
+	if err := os.WriteFile(identityPath, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write identity: %v", err)
+	}
+
+	secret, err := EncryptSecret("celestia1automation", []string{identity.Recipient().String()})
+	if err != nil {
+		t.Fatalf("EncryptSecret() error = %v", err)
+	}
+	if !strings.HasPrefix(secret, SecretPrefix) {
+		t.Fatalf("EncryptSecret() = %s, want %s prefix", secret, SecretPrefix)
+	}
+
+	configPath := filepath.Join(dir, "config.json")
+	configJSON := `{"fee": {"payer": "` + secret + `", "gas_limit": 400000}}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	// Without an identity the config cannot be loaded
+	t.Setenv(AgeIdentityEnv, "")
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("LoadConfig expected error without an age identity, got nil")
+	}
+
+	t.Setenv(AgeIdentityEnv, identityPath)
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if config.Fee.Payer != "celestia1automation" {
+		t.Errorf("Fee.Payer = %s, want celestia1automation", config.Fee.Payer)
+	}
+	if config.Fee.GasLimit != 400000 {
+		t.Errorf("Fee.GasLimit = %d, want 400000", config.Fee.GasLimit)
+	}
+}
@@ -0,0 +1,188 @@
+package types
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+)
+
+// RouteSplit sends part of a deposit to one recipient of a fan-out route. Exactly one of Amount and
+// Percent is set.
+type RouteSplit struct {
+	Recipient string `json:"recipient"`
+	Amount    string `json:"amount,omitempty"`  // Absolute amount, taken from the route amount first
+	Percent   string `json:"percent,omitempty"` // Share of what the absolute amounts leave, e.g. "33.5"
+}
+
+// Validate checks that the split names a recipient and a positive amount or percentage
+func (s RouteSplit) Validate() error {
+	if s.Recipient == "" {
+		return fmt.Errorf("recipient is required")
+	}
+	switch {
+	case s.Amount != "" && s.Percent != "":
+		return fmt.Errorf("split to %s sets both amount and percent", s.Recipient)
+	case s.Amount != "":
+		if amount, ok := math.NewIntFromString(s.Amount); !ok || !amount.IsPositive() {
+			return fmt.Errorf("invalid amount %s in split to %s", s.Amount, s.Recipient)
+		}
+	case s.Percent != "":
+		percent, err := math.LegacyNewDecFromStr(s.Percent)
+		if err != nil || !percent.IsPositive() || percent.GT(math.LegacyNewDec(100)) {
+			return fmt.Errorf("invalid percent %s in split to %s", s.Percent, s.Recipient)
+		}
+	default:
+		return fmt.Errorf("split to %s sets neither amount nor percent", s.Recipient)
+	}
+	return nil
+}
+
+// Recipients returns the recipients the route pays: those of its splits, or its single recipient
+func (r *RouteInfo) Recipients() []string {
+	if len(r.Splits) == 0 {
+		return []string{r.Recipient}
+	}
+	recipients := make([]string, len(r.Splits))
+	for i, split := range r.Splits {
+		recipients[i] = split.Recipient
+	}
+	return recipients
+}
+
+// SplitAmounts divides amount among the splits: absolute amounts first, then the percentages share
+// what is left. The percentages must add up to 100 and the amounts must add up to amount, so the
+// splits always account for the whole deposit. Rounding dust goes to the last percentage split.
+func SplitAmounts(splits []RouteSplit, amount math.Int) ([]math.Int, error) {
+	parts := make([]math.Int, len(splits))
+	remaining := amount
+	totalPercent := math.LegacyZeroDec()
+	lastPercent := -1
+	for i, split := range splits {
+		if err := split.Validate(); err != nil {
+			return nil, err
+		}
+		if split.Amount == "" {
+			percent, _ := math.LegacyNewDecFromStr(split.Percent)
+			totalPercent = totalPercent.Add(percent)
+			lastPercent = i
+			continue
+		}
+		parts[i], _ = math.NewIntFromString(split.Amount)
+		remaining = remaining.Sub(parts[i])
+	}
+	if remaining.IsNegative() {
+		return nil, fmt.Errorf("split amounts exceed the route amount of %s", amount)
+	}
+
+	if lastPercent < 0 {
+		if !remaining.IsZero() {
+			return nil, fmt.Errorf("split amounts leave %s of the route amount of %s unassigned", remaining, amount)
+		}
+		return parts, nil
+	}
+	if !totalPercent.Equal(math.LegacyNewDec(100)) {
+		return nil, fmt.Errorf("split percentages add up to %s, not 100", totalPercent)
+	}
+
+	shared := remaining
+	for i, split := range splits {
+		if split.Percent == "" || i == lastPercent {
+			continue
+		}
+		percent, _ := math.LegacyNewDecFromStr(split.Percent)
+		parts[i] = percent.MulInt(shared).QuoInt64(100).TruncateInt()
+		remaining = remaining.Sub(parts[i])
+	}
+	parts[lastPercent] = remaining
+
+	for i, part := range parts {
+		if !part.IsPositive() {
+			return nil, fmt.Errorf("split to %s receives nothing of the route amount of %s", splits[i].Recipient, amount)
+		}
+	}
+	return parts, nil
+}
+
+// ExpandSplits returns one route per recipient of a fan-out route, each carrying its split of the
+// route amount, or the route itself if it has a single recipient. The splits must account for the
+// whole route amount, or for the whole deposit once the rebalancing fee was kept from it, each
+// recipient then bearing its share of the fee.
+func (r *HyperlaneRoute) ExpandSplits() ([]HyperlaneRoute, error) {
+	if r.RouteInfo == nil || len(r.RouteInfo.Splits) == 0 {
+		return []HyperlaneRoute{*r}, nil
+	}
+
+	amount, ok := math.NewIntFromString(r.Amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %s in route from tx %s", r.Amount, r.TxHash)
+	}
+	deposited, fee, err := r.depositedAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := SplitAmounts(r.RouteInfo.Splits, deposited)
+	if err != nil {
+		return nil, fmt.Errorf("invalid splits in route from tx %s: %w", r.TxHash, err)
+	}
+	if err := shareFee(parts, deposited, fee); err != nil {
+		return nil, fmt.Errorf("route from tx %s: %w", r.TxHash, err)
+	}
+
+	routes := make([]HyperlaneRoute, len(parts))
+	for i, part := range parts {
+		info := *r.RouteInfo
+		info.Recipient = r.RouteInfo.Splits[i].Recipient
+		info.Splits = nil
+		// Keep a metadata amount override in sync so the verifier expects the split
+		if info.Amount != "" {
+			info.Amount = part.String()
+		}
+
+		routes[i] = *r
+		routes[i].Amount = part.String()
+		routes[i].DepositedAmount = ""
+		routes[i].RouteInfo = &info
+	}
+	return routes, nil
+}
+
+// depositedAmount returns the amount the splits of a route forwarding amount divide, with the
+// rebalancing fee kept from it: the deposit before the fee once one was kept, as the splits
+// account for the whole deposit
+func (r *HyperlaneRoute) depositedAmount(amount math.Int) (deposited, fee math.Int, err error) {
+	if r.RebalancingFee == nil {
+		return amount, math.ZeroInt(), nil
+	}
+	deposited, ok := math.NewIntFromString(r.RebalancingFee.Deposited)
+	if !ok {
+		return math.Int{}, math.Int{}, fmt.Errorf("invalid deposited amount %s in route from tx %s", r.RebalancingFee.Deposited, r.TxHash)
+	}
+	fee, ok = math.NewIntFromString(r.RebalancingFee.Fee)
+	if !ok {
+		return math.Int{}, math.Int{}, fmt.Errorf("invalid rebalancing fee %s in route from tx %s", r.RebalancingFee.Fee, r.TxHash)
+	}
+	if !deposited.Sub(fee).Equal(amount) {
+		return math.Int{}, math.Int{}, fmt.Errorf("route from tx %s forwards %s, not its deposit of %s less the rebalancing fee of %s", r.TxHash, amount, deposited, fee)
+	}
+	return deposited, fee, nil
+}
+
+// shareFee takes the rebalancing fee kept from a deposit out of its split parts, each bearing the
+// share of the fee its part is of the deposit. Rounding dust goes to the last part.
+func shareFee(parts []math.Int, deposited, fee math.Int) error {
+	if fee.IsZero() {
+		return nil
+	}
+	remaining := fee
+	for i := range parts {
+		share := remaining
+		if i < len(parts)-1 {
+			share = fee.Mul(parts[i]).Quo(deposited)
+		}
+		remaining = remaining.Sub(share)
+		if parts[i] = parts[i].Sub(share); !parts[i].IsPositive() {
+			return fmt.Errorf("split %d does not cover its share of the rebalancing fee of %s", i, fee)
+		}
+	}
+	return nil
+}
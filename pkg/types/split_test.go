@@ -0,0 +1,144 @@
+package types
+
+import (
+	"testing"
+
+	"cosmossdk.io/math"
+)
+
+func TestSplitAmounts(t *testing.T) {
+	const (
+		a = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+		b = "0x1234567890123456789012345678901234567890"
+		c = "0x0987654321098765432109876543210987654321"
+	)
+
+	tests := []struct {
+		name    string
+		splits  []RouteSplit
+		amount  int64
+		want    []int64
+		wantErr bool
+	}{
+		{"absolute", []RouteSplit{{Recipient: a, Amount: "300"}, {Recipient: b, Amount: "700"}}, 1000, []int64{300, 700}, false},
+		{"percent", []RouteSplit{{Recipient: a, Percent: "25"}, {Recipient: b, Percent: "75"}}, 1000, []int64{250, 750}, false},
+		{"percent dust to last", []RouteSplit{{Recipient: a, Percent: "33.33"}, {Recipient: b, Percent: "33.33"}, {Recipient: c, Percent: "33.34"}}, 100, []int64{33, 33, 34}, false},
+		{"mixed", []RouteSplit{{Recipient: a, Amount: "100"}, {Recipient: b, Percent: "50"}, {Recipient: c, Percent: "50"}}, 1000, []int64{100, 450, 450}, false},
+		{"absolute short", []RouteSplit{{Recipient: a, Amount: "300"}, {Recipient: b, Amount: "600"}}, 1000, nil, true},
+		{"absolute over", []RouteSplit{{Recipient: a, Amount: "300"}, {Recipient: b, Amount: "800"}}, 1000, nil, true},
+		{"percent short", []RouteSplit{{Recipient: a, Percent: "50"}, {Recipient: b, Percent: "40"}}, 1000, nil, true},
+		{"nothing left for percent", []RouteSplit{{Recipient: a, Amount: "1000"}, {Recipient: b, Percent: "100"}}, 1000, nil, true},
+		{"both amount and percent", []RouteSplit{{Recipient: a, Amount: "500", Percent: "50"}, {Recipient: b, Percent: "50"}}, 1000, nil, true},
+		{"no recipient", []RouteSplit{{Amount: "1000"}}, 1000, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := SplitAmounts(tt.splits, math.NewInt(tt.amount))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitAmounts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for i, want := range tt.want {
+				if parts[i].Int64() != want {
+					t.Errorf("SplitAmounts()[%d] = %s, want %d", i, parts[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandSplits(t *testing.T) {
+	route := &HyperlaneRoute{
+		TxHash:          "ABC",
+		Amount:          "1000",
+		DepositedAmount: "1200",
+		RouteInfo: &RouteInfo{
+			DestinationDomain: 1,
+			TokenID:           "0x01",
+			Amount:            "1000",
+			Splits: []RouteSplit{
+				{Recipient: "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0", Percent: "60"},
+				{Recipient: "0x1234567890123456789012345678901234567890", Percent: "40"},
+			},
+		},
+	}
+
+	parts, err := route.ExpandSplits()
+	if err != nil {
+		t.Fatalf("ExpandSplits() error = %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("ExpandSplits() returned %d routes, want 2", len(parts))
+	}
+	for i, want := range []string{"600", "400"} {
+		part := parts[i]
+		if part.Amount != want || part.RouteInfo.Amount != want {
+			t.Errorf("part %d amount = %s (metadata %s), want %s", i, part.Amount, part.RouteInfo.Amount, want)
+		}
+		if part.RouteInfo.Recipient != route.RouteInfo.Splits[i].Recipient || len(part.RouteInfo.Splits) != 0 {
+			t.Errorf("part %d routes to %s with %d splits, want the split recipient alone", i, part.RouteInfo.Recipient, len(part.RouteInfo.Splits))
+		}
+		if part.TxHash != "ABC" || part.DepositedAmount != "" {
+			t.Errorf("part %d tx %s deposited %q, want tx ABC without the whole deposit", i, part.TxHash, part.DepositedAmount)
+		}
+	}
+	if len(route.RouteInfo.Splits) != 2 || route.Amount != "1000" {
+		t.Error("ExpandSplits() modified the route")
+	}
+
+	single := &HyperlaneRoute{Amount: "5", RouteInfo: &RouteInfo{Recipient: "0x01"}}
+	if parts, err := single.ExpandSplits(); err != nil || len(parts) != 1 || parts[0].Amount != "5" {
+		t.Errorf("ExpandSplits() = %v, %v for a single recipient, want the route itself", parts, err)
+	}
+}
+
+func TestRouteInfoValidateSplits(t *testing.T) {
+	splits := []RouteSplit{{Recipient: "0x01", Percent: "100"}}
+	if err := (&RouteInfo{DestinationDomain: 1, TokenID: "0x01", Splits: splits}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v for splits without recipient", err)
+	}
+	if err := (&RouteInfo{DestinationDomain: 1, TokenID: "0x01", Recipient: "0x02", Splits: splits}).Validate(); err == nil {
+		t.Error("Validate() accepted both recipient and splits")
+	}
+	if err := (&RouteInfo{DestinationDomain: 1, TokenID: "0x01", Splits: []RouteSplit{{Recipient: "0x01"}}}).Validate(); err == nil {
+		t.Error("Validate() accepted a split without amount or percent")
+	}
+}
+
+func TestExpandSplitsSharesRebalancingFee(t *testing.T) {
+	fee := RebalancingFeeConfig{Flat: "100"}
+	route := &HyperlaneRoute{
+		TxHash: "ABC",
+		Amount: "1000",
+		RouteInfo: &RouteInfo{
+			DestinationDomain: 1,
+			TokenID:           "0x01",
+			Splits: []RouteSplit{
+				{Recipient: "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0", Amount: "700"},
+				{Recipient: "0x1234567890123456789012345678901234567890", Amount: "300"},
+			},
+		},
+	}
+	if err := fee.DeductFrom(route); err != nil {
+		t.Fatalf("DeductFrom() error = %v", err)
+	}
+
+	// The absolute splits still divide the deposit, and the fee is kept once from it
+	parts, err := route.ExpandSplits()
+	if err != nil {
+		t.Fatalf("ExpandSplits() error = %v", err)
+	}
+	for i, want := range []string{"630", "270"} {
+		if parts[i].Amount != want {
+			t.Errorf("part %d amount = %s, want %s", i, parts[i].Amount, want)
+		}
+	}
+
+	route.Amount = "950"
+	if _, err := route.ExpandSplits(); err == nil {
+		t.Error("ExpandSplits() accepted a route forwarding other than its deposit less the fee")
+	}
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Warp token IDs are 32-byte hyperlane-cosmos hex addresses: a zero-padded 20-byte class
+// identifier, a 4-byte type and the 8-byte sequence the object was created with, all big-endian
+const (
+	TokenIDClass = "router_app" // Class of the IDs issued by the core app router to warp tokens
+	TokenIDType  = 1            // Type of the warp module in the app router
+)
+
+// TokenIDParams are the creation parameters a warp token ID is derived from
+type TokenIDParams struct {
+	Class    string `json:"class"`
+	Type     uint32 `json:"type"`
+	Sequence uint64 `json:"sequence"` // Position in the app router's creation sequence, starting at 0
+}
+
+// DefaultTokenIDParams returns the parameters of the warp token created at sequence
+func DefaultTokenIDParams(sequence uint64) TokenIDParams {
+	return TokenIDParams{Class: TokenIDClass, Type: TokenIDType, Sequence: sequence}
+}
+
+// DeriveTokenID returns the 0x-prefixed token ID for the creation parameters
+func DeriveTokenID(params TokenIDParams) (string, error) {
+	if params.Class == "" || len(params.Class) > 20 {
+		return "", fmt.Errorf("token ID class must be 1 to 20 bytes, got %q", params.Class)
+	}
+
+	var id [32]byte
+	copy(id[:20], params.Class)
+	binary.BigEndian.PutUint32(id[20:24], params.Type)
+	binary.BigEndian.PutUint64(id[24:], params.Sequence)
+	return "0x" + hex.EncodeToString(id[:]), nil
+}
+
+// ParseTokenID decodes a 0x-prefixed 32-byte token ID into its creation parameters
+func ParseTokenID(tokenID string) (TokenIDParams, error) {
+	if !strings.HasPrefix(tokenID, "0x") {
+		return TokenIDParams{}, fmt.Errorf("token ID %s is not 0x-prefixed", tokenID)
+	}
+	id, err := hex.DecodeString(tokenID[2:])
+	if err != nil {
+		return TokenIDParams{}, fmt.Errorf("token ID %s is not hex: %w", tokenID, err)
+	}
+	if len(id) != 32 {
+		return TokenIDParams{}, fmt.Errorf("token ID %s must be 32 bytes, got %d", tokenID, len(id))
+	}
+
+	return TokenIDParams{
+		Class:    strings.TrimRight(string(id[:20]), "\x00"),
+		Type:     binary.BigEndian.Uint32(id[20:24]),
+		Sequence: binary.BigEndian.Uint64(id[24:]),
+	}, nil
+}
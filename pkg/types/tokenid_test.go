@@ -0,0 +1,39 @@
+package types
+
+import "testing"
+
+func TestDeriveTokenID(t *testing.T) {
+	id, err := DeriveTokenID(DefaultTokenIDParams(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "0x726f757465725f61707000000000000000000000000000010000000000000000"
+	if id != want {
+		t.Errorf("DeriveTokenID() = %s, want %s", id, want)
+	}
+
+	params, err := ParseTokenID(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != DefaultTokenIDParams(0) {
+		t.Errorf("ParseTokenID() = %+v, want the default parameters", params)
+	}
+
+	id, err = DeriveTokenID(DefaultTokenIDParams(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params, err := ParseTokenID(id); err != nil || params.Sequence != 7 {
+		t.Errorf("ParseTokenID(%s) = %+v, %v, want sequence 7", id, params, err)
+	}
+
+	if _, err := DeriveTokenID(TokenIDParams{Class: "a-class-longer-than-twenty-bytes"}); err == nil {
+		t.Error("DeriveTokenID() accepted a class longer than 20 bytes")
+	}
+	for _, bad := range []string{"726f7574", "0x1234", "0xzz6f757465725f61707000000000000000000000000000010000000000000000"} {
+		if _, err := ParseTokenID(bad); err == nil {
+			t.Errorf("ParseTokenID(%s) accepted an invalid token ID", bad)
+		}
+	}
+}
@@ -3,20 +3,89 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // HyperlaneRoute represents routing information extracted from MsgRemoteTransfer custom_hook_metadata
 type HyperlaneRoute struct {
 	// Source transaction information
 	TxHash      string `json:"tx_hash"`
+	MsgIndex    int    `json:"msg_index,omitempty"` // Message of the transaction carrying the transfer
 	BlockHeight int64  `json:"block_height"`
+	BlockTime   *time.Time `json:"block_time,omitempty"` // Set when parsing with block times
 	From        string `json:"from"`
 	Amount      string `json:"amount"`
 	Denom       string `json:"denom"`
 	CustomHookMetadata string `json:"custom_hook_metadata"`
+	// Source names the watched source chain the deposit was recorded for, set by watch and backfill
+	Source string `json:"source,omitempty"`
 
 	// Parsed Hyperlane routing info
 	RouteInfo *RouteInfo `json:"route_info,omitempty"`
+
+	// Set when the route was adjusted by a manual override
+	Override *AppliedOverride `json:"override,omitempty"`
+
+	// Set once the rebalancing transfer for the route was included and dispatched
+	Dispatch *Dispatch `json:"dispatch,omitempty"`
+
+	// DepositedAmount is the amount actually received when the metadata overrode it
+	DepositedAmount string `json:"deposited_amount,omitempty"`
+
+	// Notes recorded by route hooks before generation
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Set when the strategy aggregated several deposits into the route: what each one contributed
+	Provenance []RouteSource `json:"provenance,omitempty"`
+
+	// Set once the rebalancing fee was kept from the deposit: Amount is what is left to forward, or
+	// the part of it the route carries
+	RebalancingFee *KeptFee `json:"rebalancing_fee,omitempty"`
+}
+
+// TransferKey identifies the deposit of the route, since one transaction can carry several
+// transfers: the transaction hash, followed by the message index for transfers after the first
+// message. Routes of the first message keep the plain hash they were recorded under before.
+func (r *HyperlaneRoute) TransferKey() string {
+	return transferKey(r.TxHash, r.MsgIndex)
+}
+
+func transferKey(txHash string, msgIndex int) string {
+	if msgIndex == 0 {
+		return txHash
+	}
+	return fmt.Sprintf("%s/%d", txHash, msgIndex)
+}
+
+// SplitTransferKey returns the transaction hash and message index of a deposit's TransferKey
+func SplitTransferKey(key string) (string, int) {
+	hash, index, found := strings.Cut(key, "/")
+	if !found {
+		return key, 0
+	}
+	msgIndex, err := strconv.Atoi(index)
+	if err != nil {
+		return key, 0
+	}
+	return hash, msgIndex
+}
+
+// RouteSource is a deposit merged into an aggregated route and the amount it contributed
+type RouteSource struct {
+	TxHash      string `json:"tx_hash"`
+	MsgIndex    int    `json:"msg_index,omitempty"`
+	BlockHeight int64  `json:"block_height"`
+	From        string `json:"from"`
+	Amount      string `json:"amount"`
+	// Set once the rebalancing fee was kept from the deposit, which then contributed Amount
+	RebalancingFee *KeptFee `json:"rebalancing_fee,omitempty"`
+}
+
+// TransferKey identifies the deposit like HyperlaneRoute.TransferKey
+func (s RouteSource) TransferKey() string {
+	return transferKey(s.TxHash, s.MsgIndex)
 }
 
 // RouteInfo contains the parsed Hyperlane routing information from custom_hook_metadata
@@ -25,27 +94,81 @@ type RouteInfo struct {
 	Recipient         string `json:"recipient"`
 	TokenID           string `json:"token_id"`
 	Amount            string `json:"amount,omitempty"` // Optional: overrides the received amount
+
+	// Splits fans the deposit out to several recipients instead of Recipient, one transfer each
+	Splits []RouteSplit `json:"splits,omitempty"`
+
+	// Nonce is an optional reference chosen by the sender. A deposit reusing a nonce the same sender
+	// already used is flagged as a possible replay.
+	Nonce string `json:"nonce,omitempty"`
+
+	// Set when the metadata omitted the recipient and the destination's default recipient was used
+	RecipientDefaulted bool `json:"recipient_defaulted,omitempty"`
 }
 
 // ParseCustomHookMetadata attempts to parse the custom_hook_metadata as JSON containing RouteInfo
 func ParseCustomHookMetadata(metadata string) (*RouteInfo, error) {
+	routeInfo, err := decodeCustomHookMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := routeInfo.Validate(); err != nil {
+		return nil, err
+	}
+	return routeInfo, nil
+}
+
+// decodeCustomHookMetadata decodes the custom_hook_metadata JSON without checking required fields
+func decodeCustomHookMetadata(metadata string) (*RouteInfo, error) {
 	var routeInfo RouteInfo
 	if err := json.Unmarshal([]byte(metadata), &routeInfo); err != nil {
 		return nil, fmt.Errorf("failed to parse custom_hook_metadata as JSON: %w", err)
 	}
+	// Only the parser may mark a recipient as defaulted
+	routeInfo.RecipientDefaulted = false
+	return &routeInfo, nil
+}
 
-	// Validate required fields
-	if routeInfo.DestinationDomain == 0 {
-		return nil, fmt.Errorf("destination_domain is required")
+// Validate checks that the required routing fields are set
+func (r *RouteInfo) Validate() error {
+	if r.DestinationDomain == 0 {
+		return fmt.Errorf("destination_domain is required")
+	}
+	if len(r.Splits) > 0 {
+		if r.Recipient != "" {
+			return fmt.Errorf("recipient and splits are mutually exclusive")
+		}
+		for _, split := range r.Splits {
+			if err := split.Validate(); err != nil {
+				return fmt.Errorf("invalid split: %w", err)
+			}
+		}
+	} else if r.Recipient == "" {
+		return fmt.Errorf("recipient is required")
 	}
-	if routeInfo.Recipient == "" {
-		return nil, fmt.Errorf("recipient is required")
+	if r.TokenID == "" {
+		return fmt.Errorf("token_id is required")
 	}
-	if routeInfo.TokenID == "" {
-		return nil, fmt.Errorf("token_id is required")
+	if err := validateNonce(r.Nonce); err != nil {
+		return err
 	}
+	return nil
+}
 
-	return &routeInfo, nil
+// Skipped describes a transaction that was not turned into a route, and why
+type Skipped struct {
+	TxHash      string `json:"tx_hash"`
+	BlockHeight int64  `json:"block_height"`
+	Reason      string `json:"reason"`
+	Amount      string `json:"amount,omitempty"` // Transferred amount, if known, so operators can see what was left behind
+	// Metadata identifies routing metadata that was too large to decode
+	Metadata *SkippedMetadata `json:"metadata,omitempty"`
+}
+
+// FailedHeight records a block height that could not be queried, so it can be retried later
+type FailedHeight struct {
+	Height int64  `json:"height"`
+	Error  string `json:"error"`
 }
 
 // Routes is a collection of HyperlaneRoute with metadata
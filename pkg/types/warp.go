@@ -0,0 +1,192 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cosmossdk.io/math"
+)
+
+// WarpDeployment is the warp route artifact written by the Hyperlane deploy tooling (the warp core
+// config), listing the route's token on every chain and the tokens each one connects to
+type WarpDeployment struct {
+	Tokens []WarpToken `json:"tokens"`
+}
+
+// WarpToken is a warp route token on one chain
+type WarpToken struct {
+	ChainName string `json:"chainName"`
+	Standard  string `json:"standard"`
+	Decimals  uint32 `json:"decimals"`
+	Symbol    string `json:"symbol,omitempty"`
+	// AddressOrDenom is the router contract on EVM chains and the token ID on Cosmos chains
+	AddressOrDenom           string           `json:"addressOrDenom"`
+	CollateralAddressOrDenom string           `json:"collateralAddressOrDenom,omitempty"`
+	Connections              []WarpConnection `json:"connections,omitempty"`
+}
+
+// WarpConnection links a token to a token on another chain, as "<protocol>|<chain>|<address>"
+type WarpConnection struct {
+	Token string `json:"token"`
+}
+
+// KnownDomains are the Hyperlane domain IDs of chains the rebalancer commonly routes to, by the
+// chain names the deploy tooling uses
+var KnownDomains = map[string]uint32{
+	"celestia": 1128614981,
+	"noble":    1313817164,
+	"ethereum": 1,
+	"arbitrum": 42161,
+	"base":     8453,
+	"optimism": 10,
+}
+
+// WarpRoute is what a warp deployment says about one destination of the origin chain's token
+type WarpRoute struct {
+	Chain      string
+	Domain     uint32
+	Router     string // Router contract on the destination, empty for non-EVM destinations
+	RouterType string // RouterSynthetic, RouterCollateral or RouterNative; empty if unknown
+	Scale      string // Destination units per origin unit, empty if the decimals match
+}
+
+// WarpImport is the configuration derived from a warp deployment for its origin chain
+type WarpImport struct {
+	OriginDomain uint32
+	TokenID      string // Warp token ID of the route on the origin chain
+	Routes       []WarpRoute
+}
+
+// LoadWarpDeployment reads a warp deployment artifact in JSON
+func LoadWarpDeployment(path string) (*WarpDeployment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read warp deployment: %w", err)
+	}
+	var deployment WarpDeployment
+	if err := json.Unmarshal(data, &deployment); err != nil {
+		return nil, fmt.Errorf("failed to parse warp deployment JSON: %w", err)
+	}
+	if len(deployment.Tokens) == 0 {
+		return nil, fmt.Errorf("warp deployment has no tokens")
+	}
+	return &deployment, nil
+}
+
+// Import derives the configuration of the origin chain's side of the warp route. domains maps
+// chain names to Hyperlane domain IDs, on top of KnownDomains.
+func (d *WarpDeployment) Import(origin string, domains map[string]uint32) (*WarpImport, error) {
+	domainOf := func(chain string) (uint32, error) {
+		if domain, ok := domains[chain]; ok {
+			return domain, nil
+		}
+		if domain, ok := KnownDomains[chain]; ok {
+			return domain, nil
+		}
+		return 0, fmt.Errorf("domain ID of chain %s is unknown", chain)
+	}
+
+	var token *WarpToken
+	for i := range d.Tokens {
+		if d.Tokens[i].ChainName == origin {
+			token = &d.Tokens[i]
+			break
+		}
+	}
+	if token == nil {
+		return nil, fmt.Errorf("warp deployment has no token on %s", origin)
+	}
+	if _, err := ParseTokenID(token.AddressOrDenom); err != nil {
+		return nil, fmt.Errorf("token on %s: %w", origin, err)
+	}
+	originDomain, err := domainOf(origin)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &WarpImport{OriginDomain: originDomain, TokenID: strings.ToLower(token.AddressOrDenom)}
+	for _, conn := range token.Connections {
+		parts := strings.Split(conn.Token, "|")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid connection %q, want <protocol>|<chain>|<address>", conn.Token)
+		}
+		protocol, chain, address := parts[0], parts[1], parts[2]
+		domain, err := domainOf(chain)
+		if err != nil {
+			return nil, err
+		}
+
+		route := WarpRoute{Chain: chain, Domain: domain}
+		if protocol == "ethereum" {
+			if !isHexAddress(address) {
+				return nil, fmt.Errorf("router %s on %s is not a 0x-prefixed 20-byte address", address, chain)
+			}
+			route.Router = strings.ToLower(address)
+		}
+		for _, remote := range d.Tokens {
+			if remote.ChainName != chain || !strings.EqualFold(remote.AddressOrDenom, address) {
+				continue
+			}
+			route.RouterType = warpRouterType(remote.Standard)
+			if remote.Decimals > token.Decimals {
+				route.Scale = math.NewIntWithDecimal(1, int(remote.Decimals-token.Decimals)).String()
+			}
+		}
+		imp.Routes = append(imp.Routes, route)
+	}
+	if len(imp.Routes) == 0 {
+		return nil, fmt.Errorf("token on %s has no connections", origin)
+	}
+	sort.Slice(imp.Routes, func(i, j int) bool { return imp.Routes[i].Domain < imp.Routes[j].Domain })
+	return imp, nil
+}
+
+// warpRouterType maps a Hyperlane token standard, e.g. "EvmHypCollateral", to a router type
+func warpRouterType(standard string) string {
+	switch {
+	case strings.Contains(standard, "Synthetic"), strings.HasSuffix(standard, "XERC20"):
+		return RouterSynthetic
+	case strings.Contains(standard, "Collateral"), strings.Contains(standard, "Lockbox"):
+		return RouterCollateral
+	case strings.Contains(standard, "Native"):
+		return RouterNative
+	default:
+		return ""
+	}
+}
+
+// ApplyWarpImport fills the chain's domain and the token ID, router, router type and scale of each
+// destination from a warp import, and returns a description of every setting it changed. Router
+// types that need a destination RPC for their checks are only set on destinations that have one.
+func (c *Config) ApplyWarpImport(imp *WarpImport) []string {
+	var changes []string
+	if c.Chain.Domain != imp.OriginDomain {
+		changes = append(changes, fmt.Sprintf("chain.domain: %d -> %d", c.Chain.Domain, imp.OriginDomain))
+		c.Chain.Domain = imp.OriginDomain
+	}
+	if c.Destinations == nil {
+		c.Destinations = make(map[uint32]DestinationConfig)
+	}
+
+	for _, route := range imp.Routes {
+		dest := c.Destinations[route.Domain]
+		set := func(field string, value *string, imported string) {
+			if imported == "" || *value == imported {
+				return
+			}
+			changes = append(changes, fmt.Sprintf("destinations.%d.%s (%s): %q -> %q", route.Domain, field, route.Chain, *value, imported))
+			*value = imported
+		}
+		set("token_id", &dest.TokenID, imp.TokenID)
+		set("router", &dest.Router, route.Router)
+		set("scale", &dest.Scale, route.Scale)
+		if route.RouterType == RouterSynthetic || dest.RPCURL != "" {
+			set("router_type", &dest.RouterType, route.RouterType)
+		}
+		c.Destinations[route.Domain] = dest
+	}
+	return changes
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const warpTokenID = "0x726f757465725f61707000000000000000000000000000010000000000000000"
+
+const warpDeploymentJSON = `{
+  "tokens": [
+    {
+      "chainName": "celestia",
+      "standard": "CosmosNativeHypCollateral",
+      "decimals": 6,
+      "symbol": "TIA",
+      "addressOrDenom": "` + warpTokenID + `",
+      "collateralAddressOrDenom": "utia",
+      "connections": [
+        {"token": "ethereum|arbitrum|0xAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"},
+        {"token": "ethereum|ethereum|0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},
+        {"token": "cosmos|forma|0xcccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"}
+      ]
+    },
+    {
+      "chainName": "arbitrum",
+      "standard": "EvmHypSynthetic",
+      "decimals": 18,
+      "addressOrDenom": "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+      "connections": [{"token": "cosmosnative|celestia|` + warpTokenID + `"}]
+    },
+    {
+      "chainName": "ethereum",
+      "standard": "EvmHypCollateral",
+      "decimals": 6,
+      "addressOrDenom": "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+      "connections": [{"token": "cosmosnative|celestia|` + warpTokenID + `"}]
+    }
+  ]
+}`
+
+func loadTestWarpDeployment(t *testing.T) *WarpDeployment {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "warp.json")
+	if err := os.WriteFile(path, []byte(warpDeploymentJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deployment, err := LoadWarpDeployment(path)
+	if err != nil {
+		t.Fatalf("LoadWarpDeployment() error = %v", err)
+	}
+	return deployment
+}
+
+func TestWarpDeploymentImport(t *testing.T) {
+	deployment := loadTestWarpDeployment(t)
+
+	imp, err := deployment.Import("celestia", map[string]uint32{"forma": 984122})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if imp.OriginDomain != KnownDomains["celestia"] || imp.TokenID != warpTokenID {
+		t.Errorf("Import() origin = %d, %s", imp.OriginDomain, imp.TokenID)
+	}
+	want := []WarpRoute{
+		{Chain: "ethereum", Domain: 1, Router: "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", RouterType: RouterCollateral},
+		{Chain: "arbitrum", Domain: 42161, Router: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", RouterType: RouterSynthetic, Scale: "1000000000000"},
+		{Chain: "forma", Domain: 984122},
+	}
+	if len(imp.Routes) != len(want) {
+		t.Fatalf("Import() routes = %+v, want %+v", imp.Routes, want)
+	}
+	for i := range want {
+		if imp.Routes[i] != want[i] {
+			t.Errorf("route %d = %+v, want %+v", i, imp.Routes[i], want[i])
+		}
+	}
+
+	if _, err := deployment.Import("celestia", nil); err == nil || !strings.Contains(err.Error(), "forma") {
+		t.Errorf("Import() with an unknown chain error = %v, want the chain named", err)
+	}
+	if _, err := deployment.Import("osmosis", nil); err == nil {
+		t.Error("Import() succeeded for a chain the deployment has no token on")
+	}
+	if _, err := deployment.Import("arbitrum", nil); err == nil {
+		t.Error("Import() accepted a 20-byte router as the origin token ID")
+	}
+}
+
+func TestConfigApplyWarpImport(t *testing.T) {
+	imp, err := loadTestWarpDeployment(t).Import("celestia", map[string]uint32{"forma": 984122})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{
+		Chain: DefaultChainConfig(),
+		Destinations: map[uint32]DestinationConfig{
+			1: {RPCURL: "https://eth.example", DefaultRecipient: "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"},
+		},
+	}
+
+	changes := config.ApplyWarpImport(imp)
+	if len(changes) == 0 {
+		t.Fatal("ApplyWarpImport() reported no changes")
+	}
+	if config.Chain.Domain != KnownDomains["celestia"] {
+		t.Errorf("chain domain = %d", config.Chain.Domain)
+	}
+	eth := config.Destinations[1]
+	if eth.Router != "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb" || eth.RouterType != RouterCollateral || eth.TokenID != warpTokenID {
+		t.Errorf("ethereum destination = %+v", eth)
+	}
+	if eth.DefaultRecipient == "" {
+		t.Error("ApplyWarpImport() dropped an existing setting")
+	}
+	if forma := config.Destinations[984122]; forma.Router != "" || forma.TokenID != warpTokenID {
+		t.Errorf("forma destination = %+v", forma)
+	}
+	for domain, dest := range config.Destinations {
+		if err := dest.Validate(); err != nil {
+			t.Errorf("destination %d: %v", domain, err)
+		}
+	}
+
+	// Reapplying the same import changes nothing
+	if changes := config.ApplyWarpImport(imp); len(changes) != 0 {
+		t.Errorf("second ApplyWarpImport() changes = %v, want none", changes)
+	}
+}
+
+func TestWarpRouterType(t *testing.T) {
+	tests := map[string]string{
+		"EvmHypSynthetic":         RouterSynthetic,
+		"EvmHypSyntheticRebase":   RouterSynthetic,
+		"EvmHypXERC20":            RouterSynthetic,
+		"EvmHypCollateral":        RouterCollateral,
+		"EvmHypCollateralFiat":    RouterCollateral,
+		"EvmHypXERC20Lockbox":     RouterCollateral,
+		"EvmHypNative":            RouterNative,
+		"EvmHypNativeScaled":      RouterNative,
+		"SealevelHypSomethingNew": "",
+	}
+	for standard, want := range tests {
+		if got := warpRouterType(standard); got != want {
+			t.Errorf("warpRouterType(%s) = %q, want %q", standard, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package types
+
+import "fmt"
+
+// WarpRouteConfig identifies one warp route across the chains it connects. Token IDs are local to
+// each chain, so the route lists its token on every domain; net only offsets the flows of tokens
+// that belong to the same warp route.
+type WarpRouteConfig struct {
+	Name   string            `json:"name"`
+	Tokens map[uint32]string `json:"tokens"` // Warp token ID of the route on each Hyperlane domain
+}
+
+// WarpRouteIndex maps the token of a warp route on a domain to the name of the route
+type WarpRouteIndex map[WarpRouteToken]string
+
+// WarpRouteToken is a warp token on a domain, with its token ID normalized
+type WarpRouteToken struct {
+	Domain  uint32
+	TokenID string
+}
+
+// NewWarpRouteIndex validates warp routes and indexes their tokens. Every route needs a unique
+// name and its token on at least two domains, and a token may belong to one route only.
+func NewWarpRouteIndex(routes []WarpRouteConfig) (WarpRouteIndex, error) {
+	index := make(WarpRouteIndex)
+	names := make(map[string]bool)
+	for i, route := range routes {
+		if route.Name == "" {
+			return nil, fmt.Errorf("warp route %d has no name", i)
+		}
+		if names[route.Name] {
+			return nil, fmt.Errorf("duplicate warp route name %s", route.Name)
+		}
+		names[route.Name] = true
+		if len(route.Tokens) < 2 {
+			return nil, fmt.Errorf("warp route %s must list its token on at least two domains", route.Name)
+		}
+
+		for domain, tokenID := range route.Tokens {
+			normalized, err := NormalizeTokenID(tokenID)
+			if err != nil {
+				return nil, fmt.Errorf("warp route %s: invalid token ID %s on domain %d: %w", route.Name, tokenID, domain, err)
+			}
+			token := WarpRouteToken{Domain: domain, TokenID: normalized}
+			if other, ok := index[token]; ok {
+				return nil, fmt.Errorf("token %s on domain %d belongs to warp routes %s and %s", normalized, domain, other, route.Name)
+			}
+			index[token] = route.Name
+		}
+	}
+	return index, nil
+}
+
+// Route returns the name of the warp route tokenID belongs to on domain, or false if the token is
+// not part of a configured route
+func (idx WarpRouteIndex) Route(domain uint32, tokenID string) (string, bool, error) {
+	normalized, err := NormalizeTokenID(tokenID)
+	if err != nil {
+		return "", false, err
+	}
+	name, ok := idx[WarpRouteToken{Domain: domain, TokenID: normalized}]
+	return name, ok, nil
+}
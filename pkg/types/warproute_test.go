@@ -0,0 +1,52 @@
+package types
+
+import "testing"
+
+func TestNewWarpRouteIndex(t *testing.T) {
+	const (
+		tokenA = "0x726f757465725f61707000000000000000000000000000010000000000000000"
+		tokenB = "0x000000000000000000000000c0ffee254729296a45a3885639ac7e10f9d54979"
+	)
+	tests := []struct {
+		name    string
+		routes  []WarpRouteConfig
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"one route", []WarpRouteConfig{{Name: "TIA", Tokens: map[uint32]string{1: tokenA, 2: tokenB}}}, false},
+		{"same token ID on two domains", []WarpRouteConfig{{Name: "TIA", Tokens: map[uint32]string{1: tokenA, 2: tokenA}}}, false},
+		{"no name", []WarpRouteConfig{{Tokens: map[uint32]string{1: tokenA, 2: tokenB}}}, true},
+		{"one domain", []WarpRouteConfig{{Name: "TIA", Tokens: map[uint32]string{1: tokenA}}}, true},
+		{"invalid token ID", []WarpRouteConfig{{Name: "TIA", Tokens: map[uint32]string{1: tokenA, 2: "0x1234"}}}, true},
+		{"duplicate name", []WarpRouteConfig{
+			{Name: "TIA", Tokens: map[uint32]string{1: tokenA, 2: tokenB}},
+			{Name: "TIA", Tokens: map[uint32]string{3: tokenA, 4: tokenB}},
+		}, true},
+		{"token in two routes", []WarpRouteConfig{
+			{Name: "TIA", Tokens: map[uint32]string{1: tokenA, 2: tokenB}},
+			{Name: "USDC", Tokens: map[uint32]string{1: tokenA, 3: tokenB}},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWarpRouteIndex(tt.routes); (err != nil) != tt.wantErr {
+				t.Errorf("NewWarpRouteIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	index, err := NewWarpRouteIndex([]WarpRouteConfig{{Name: "TIA", Tokens: map[uint32]string{1: tokenA, 2: tokenB}}})
+	if err != nil {
+		t.Fatalf("NewWarpRouteIndex() error = %v", err)
+	}
+	// Token IDs are matched normalized and per domain
+	if name, ok, err := index.Route(2, "0x000000000000000000000000C0FFEE254729296A45A3885639AC7E10F9D54979"); err != nil || !ok || name != "TIA" {
+		t.Errorf("Route(2, tokenB) = %q, %v, %v, want TIA", name, ok, err)
+	}
+	if _, ok, err := index.Route(1, tokenB); err != nil || ok {
+		t.Errorf("Route(1, tokenB) = %v, %v, want not found", ok, err)
+	}
+	if _, _, err := index.Route(1, "0x1234"); err == nil {
+		t.Error("Route() accepted an invalid token ID")
+	}
+}
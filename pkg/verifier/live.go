@@ -0,0 +1,304 @@
+package verifier
+
+import (
+	"fmt"
+	"slices"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/client"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+)
+
+// Names of the live checks, in the order they run
+const (
+	LiveCheckBalance  = "balance"
+	LiveCheckRouters  = "routers"
+	LiveCheckSequence = "sequence"
+	LiveCheckRefunds  = "refunds"
+)
+
+// LiveChain is the chain state CheckLive reads. It is implemented by the gRPC client.
+type LiveChain interface {
+	// GetBalances queries the balances of address
+	GetBalances(address string) (sdk.Coins, error)
+	// Account queries the account number and current sequence of address
+	Account(address string) (*authtypes.BaseAccount, error)
+	// GetWarpTokens queries all warp tokens registered on the chain
+	GetWarpTokens() ([]warptypes.WrappedHypToken, error)
+	// EnrolledDomains queries the domains warp token tokenID has a remote router enrolled for
+	EnrolledDomains(tokenID string) ([]uint32, error)
+	// BankSendsSince returns the transactions from minHeight on with a bank MsgSend from one address to another
+	BankSendsSince(from, to string, minHeight int64) ([]string, error)
+}
+
+var _ LiveChain = (*client.Client)(nil)
+
+// LiveCheck is the outcome of one check of a transaction against the current chain state
+type LiveCheck struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Problems []string `json:"problems,omitempty"` // Why the check failed
+	Notes    []string `json:"notes,omitempty"`    // Facts signers should know that do not fail the check
+}
+
+// LiveReport is the go/no-go summary of a transaction for its signers: it is only safe to sign
+// when every check passed against the chain's current state
+type LiveReport struct {
+	Go     bool        `json:"go"`
+	Checks []LiveCheck `json:"checks"`
+}
+
+// CheckLive checks a transaction against the current state of the chain: the multisig must hold
+// the transferred amounts, interchain gas and fees it pays; every transfer's token must have a
+// router enrolled for its destination; the transaction must be signed at its signer's current
+// sequence; and no deposit of routes may have been refunded by the multisig since it was made.
+// A check that cannot query the chain fails.
+func (v *Verifier) CheckLive(routes *types.Routes, txRaw *tx.TxRaw, chain LiveChain) (*LiveReport, error) {
+	var body tx.TxBody
+	if err := body.Unmarshal(txRaw.BodyBytes); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction body: %w", err)
+	}
+	var authInfo tx.AuthInfo
+	if err := authInfo.Unmarshal(txRaw.AuthInfoBytes); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction auth info: %w", err)
+	}
+	transfers, grantee, err := liveTransfers(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	multisig := routes.MultisigAddr
+	if multisig == "" && len(transfers) > 0 {
+		multisig = transfers[0].Sender
+	}
+	if multisig == "" {
+		return nil, fmt.Errorf("the routes name no multisig and the transaction has no transfers")
+	}
+
+	// With authz, the grantee signs and pays the fees unless another account does
+	signer := multisig
+	if grantee != "" {
+		signer = grantee
+	}
+	feePayer := signer
+	if authInfo.Fee != nil && authInfo.Fee.Payer != "" {
+		feePayer = authInfo.Fee.Payer
+	}
+	var fees sdk.Coins
+	if authInfo.Fee != nil && feePayer == multisig {
+		fees = authInfo.Fee.Amount
+	}
+
+	report := &LiveReport{Go: true}
+	for _, check := range []LiveCheck{
+		checkBalance(chain, multisig, transfers, fees),
+		checkRouters(chain, transfers),
+		checkSequence(chain, signer, &authInfo),
+		checkRefunds(chain, multisig, routes.Routes),
+	} {
+		check.Passed = len(check.Problems) == 0
+		report.Go = report.Go && check.Passed
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// liveTransfers returns the transfers of a transaction body, including those executed through an
+// authz MsgExec, and the grantee executing them
+func liveTransfers(body *tx.TxBody) ([]*warptypes.MsgRemoteTransfer, string, error) {
+	var transfers []*warptypes.MsgRemoteTransfer
+	grantee := ""
+	decode := func(anyMsg []byte) error {
+		var transfer warptypes.MsgRemoteTransfer
+		if err := transfer.Unmarshal(anyMsg); err != nil {
+			return fmt.Errorf("failed to decode MsgRemoteTransfer: %w", err)
+		}
+		transfers = append(transfers, &transfer)
+		return nil
+	}
+
+	for _, anyMsg := range body.Messages {
+		switch anyMsg.TypeUrl {
+		case types.MsgRemoteTransferTypeURL:
+			if err := decode(anyMsg.Value); err != nil {
+				return nil, "", err
+			}
+		case authzMsgExec:
+			var exec authz.MsgExec
+			if err := exec.Unmarshal(anyMsg.Value); err != nil {
+				return nil, "", fmt.Errorf("failed to decode authz MsgExec: %w", err)
+			}
+			grantee = exec.Grantee
+			for _, inner := range exec.Msgs {
+				if inner.TypeUrl != types.MsgRemoteTransferTypeURL {
+					continue
+				}
+				if err := decode(inner.Value); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+	}
+	return transfers, grantee, nil
+}
+
+// checkBalance compares what the transaction takes from the multisig with its balances. Transfers
+// debit the denom their warp token takes from the sender, and interchain gas is counted at MaxFee.
+func checkBalance(chain LiveChain, multisig string, transfers []*warptypes.MsgRemoteTransfer, fees sdk.Coins) LiveCheck {
+	check := LiveCheck{Name: LiveCheckBalance}
+
+	balances, err := chain.GetBalances(multisig)
+	if err != nil {
+		check.Problems = append(check.Problems, fmt.Sprintf("could not query the balances of %s: %v", multisig, err))
+		return check
+	}
+	tokens, err := chain.GetWarpTokens()
+	if err != nil {
+		check.Problems = append(check.Problems, fmt.Sprintf("could not query the warp tokens: %v", err))
+		return check
+	}
+	denoms := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		if id, err := types.NormalizeTokenID(token.Id); err == nil {
+			denoms[id] = client.TokenDenom(token)
+		}
+	}
+
+	needed := sdk.NewCoins()
+	for _, fee := range fees {
+		needed = needed.Add(fee)
+	}
+	for _, transfer := range transfers {
+		tokenID := fmt.Sprintf("0x%x", transfer.TokenId[:])
+		denom, ok := denoms[tokenID]
+		if !ok {
+			check.Problems = append(check.Problems, fmt.Sprintf("token %s is not registered on the chain", tokenID))
+			continue
+		}
+		if !transfer.Amount.IsNil() {
+			needed = needed.Add(sdk.Coin{Denom: denom, Amount: transfer.Amount})
+		}
+		if transfer.MaxFee.Denom != "" && !transfer.MaxFee.Amount.IsNil() {
+			needed = needed.Add(transfer.MaxFee)
+		}
+	}
+
+	for _, coin := range needed {
+		held := balances.AmountOf(coin.Denom)
+		if held.LT(coin.Amount) {
+			check.Problems = append(check.Problems, fmt.Sprintf("multisig holds %s%s but the transaction needs up to %s", held, coin.Denom, coin))
+		} else {
+			check.Notes = append(check.Notes, fmt.Sprintf("needs up to %s of %s%s held", coin, held, coin.Denom))
+		}
+	}
+	return check
+}
+
+// checkRouters checks that the token of every transfer has a router enrolled for its destination
+func checkRouters(chain LiveChain, transfers []*warptypes.MsgRemoteTransfer) LiveCheck {
+	check := LiveCheck{Name: LiveCheckRouters}
+
+	enrolled := make(map[string][]uint32)
+	reported := make(map[string]bool)
+	for _, transfer := range transfers {
+		tokenID := fmt.Sprintf("0x%x", transfer.TokenId[:])
+		domains, ok := enrolled[tokenID]
+		if !ok {
+			var err error
+			domains, err = chain.EnrolledDomains(tokenID)
+			if err != nil {
+				check.Problems = append(check.Problems, fmt.Sprintf("could not query the routers of token %s: %v", tokenID, err))
+			}
+			enrolled[tokenID] = domains
+		}
+
+		key := fmt.Sprintf("%s/%d", tokenID, transfer.DestinationDomain)
+		if !slices.Contains(domains, transfer.DestinationDomain) && !reported[key] {
+			reported[key] = true
+			check.Problems = append(check.Problems, fmt.Sprintf("token %s has no router enrolled for domain %d", tokenID, transfer.DestinationDomain))
+		}
+	}
+	return check
+}
+
+// checkSequence compares the sequence the transaction is signed at with its signer's current one.
+// A lower sequence was already used and the transaction can never be included; a higher one is
+// expected of later batches and only included once the batches before it are.
+func checkSequence(chain LiveChain, signer string, authInfo *tx.AuthInfo) LiveCheck {
+	check := LiveCheck{Name: LiveCheckSequence}
+
+	account, err := chain.Account(signer)
+	if err != nil {
+		check.Problems = append(check.Problems, fmt.Sprintf("could not query the account of %s: %v", signer, err))
+		return check
+	}
+	var sequence uint64
+	if infos := authInfo.GetSignerInfos(); len(infos) > 0 {
+		sequence = infos[0].Sequence
+	}
+
+	switch {
+	case sequence < account.Sequence:
+		check.Problems = append(check.Problems, fmt.Sprintf("signed at sequence %d, but %s is already at sequence %d; regenerate or resequence the transaction",
+			sequence, signer, account.Sequence))
+	case sequence > account.Sequence:
+		check.Notes = append(check.Notes, fmt.Sprintf("signed at sequence %d, %d ahead of %s; the transactions before it must be included first",
+			sequence, sequence-account.Sequence, signer))
+	default:
+		check.Notes = append(check.Notes, fmt.Sprintf("signed at the current sequence %d of %s", sequence, signer))
+	}
+	return check
+}
+
+// checkRefunds looks for bank sends from the multisig back to the depositor of every route since
+// its deposit. Only depositors on the chain can be refunded this way; other routes are skipped.
+func checkRefunds(chain LiveChain, multisig string, routes []types.HyperlaneRoute) LiveCheck {
+	check := LiveCheck{Name: LiveCheckRefunds}
+
+	for _, route := range routes {
+		if route.From == "" || route.From == multisig || route.BlockHeight <= 0 {
+			continue
+		}
+		if _, _, err := bech32.DecodeAndConvert(route.From); err != nil {
+			continue
+		}
+		refunds, err := chain.BankSendsSince(multisig, route.From, route.BlockHeight)
+		if err != nil {
+			check.Problems = append(check.Problems, fmt.Sprintf("could not query refunds of tx %s: %v", route.TxHash, err))
+			continue
+		}
+		for _, hash := range refunds {
+			check.Problems = append(check.Problems, fmt.Sprintf("deposit tx %s by %s may already be refunded by tx %s", route.TxHash, route.From, hash))
+		}
+	}
+	return check
+}
+
+// PrintLiveReport prints the go/no-go summary of the live checks
+func (v *Verifier) PrintLiveReport(report *LiveReport) {
+	fmt.Fprintln(v.out, "Live chain checks:")
+	for _, check := range report.Checks {
+		marker := "✓"
+		if !check.Passed {
+			marker = "✗"
+		}
+		fmt.Fprintf(v.out, "  %s %s\n", marker, check.Name)
+		for _, problem := range check.Problems {
+			fmt.Fprintf(v.out, "      - %s\n", problem)
+		}
+		for _, note := range check.Notes {
+			fmt.Fprintf(v.out, "      %s\n", note)
+		}
+	}
+
+	if report.Go {
+		fmt.Fprintln(v.out, "\n✓ GO: the transaction is safe to sign against the current chain state")
+	} else {
+		fmt.Fprintln(v.out, "\n✗ NO-GO: resolve the problems above before signing")
+	}
+}
@@ -0,0 +1,155 @@
+package verifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+// fakeLiveChain serves the chain state of CheckLive from fixed values
+type fakeLiveChain struct {
+	balances sdk.Coins
+	sequence uint64
+	tokens   []warptypes.WrappedHypToken
+	routers  map[string][]uint32
+	refunds  map[string][]string // Refund tx hashes, by recipient
+	err      error
+}
+
+func (f *fakeLiveChain) GetBalances(address string) (sdk.Coins, error) {
+	return f.balances, f.err
+}
+
+func (f *fakeLiveChain) Account(address string) (*authtypes.BaseAccount, error) {
+	return &authtypes.BaseAccount{Address: address, Sequence: f.sequence}, f.err
+}
+
+func (f *fakeLiveChain) GetWarpTokens() ([]warptypes.WrappedHypToken, error) {
+	return f.tokens, f.err
+}
+
+func (f *fakeLiveChain) EnrolledDomains(tokenID string) ([]uint32, error) {
+	return f.routers[tokenID], f.err
+}
+
+func (f *fakeLiveChain) BankSendsSince(from, to string, minHeight int64) ([]string, error) {
+	return f.refunds[to], f.err
+}
+
+func TestCheckLive(t *testing.T) {
+	const (
+		multisig  = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+		depositor = "celestia1yg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zl2r5q4"
+		tokenID   = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	)
+	routes := &types.Routes{
+		MultisigAddr: multisig,
+		Routes: []types.HyperlaneRoute{{
+			TxHash:      "ABC123",
+			BlockHeight: 100,
+			From:        depositor,
+			Amount:      "1000000",
+			Denom:       "utia",
+			RouteInfo: &types.RouteInfo{
+				DestinationDomain: 1380012617,
+				Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+				TokenID:           tokenID,
+			},
+		}},
+	}
+
+	gen := generator.NewGenerator(multisig)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{GasLimit: 200000, Fee: sdk.NewCoins(sdk.NewInt64Coin("utia", 5000))})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	generator.SetSequence(unsigned, 7)
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authInfoBytes, err := unsigned.AuthInfo.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	txRaw := &tx.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes}
+
+	healthy := func() *fakeLiveChain {
+		return &fakeLiveChain{
+			balances: sdk.NewCoins(sdk.NewInt64Coin("utia", 1005000)),
+			sequence: 7,
+			tokens:   []warptypes.WrappedHypToken{{Id: tokenID, OriginDenom: "utia"}},
+			routers:  map[string][]uint32{tokenID: {1380012617}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*fakeLiveChain)
+		failed string // Name of the failing check; empty for go
+		want   string
+	}{
+		{name: "go", modify: func(*fakeLiveChain) {}},
+		{name: "fees not covered", modify: func(f *fakeLiveChain) { f.balances = sdk.NewCoins(sdk.NewInt64Coin("utia", 1000000)) }, failed: LiveCheckBalance, want: "needs up to 1005000utia"},
+		{name: "unknown token", modify: func(f *fakeLiveChain) { f.tokens = nil }, failed: LiveCheckBalance, want: "not registered"},
+		{name: "router not enrolled", modify: func(f *fakeLiveChain) { f.routers[tokenID] = []uint32{42} }, failed: LiveCheckRouters, want: "no router enrolled for domain 1380012617"},
+		{name: "stale sequence", modify: func(f *fakeLiveChain) { f.sequence = 8 }, failed: LiveCheckSequence, want: "already at sequence 8"},
+		{name: "refunded deposit", modify: func(f *fakeLiveChain) { f.refunds = map[string][]string{depositor: {"REFUND1"}} }, failed: LiveCheckRefunds, want: "refunded by tx REFUND1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := healthy()
+			tt.modify(chain)
+
+			report, err := NewVerifier().CheckLive(routes, txRaw, chain)
+			if err != nil {
+				t.Fatalf("CheckLive() error = %v", err)
+			}
+			if report.Go != (tt.failed == "") {
+				t.Fatalf("Go = %v, checks = %+v", report.Go, report.Checks)
+			}
+			for _, check := range report.Checks {
+				if check.Passed == (check.Name == tt.failed) {
+					t.Errorf("check %s passed = %v, problems = %v", check.Name, check.Passed, check.Problems)
+				}
+				if check.Name == tt.failed && !strings.Contains(strings.Join(check.Problems, "\n"), tt.want) {
+					t.Errorf("check %s problems = %v, want %q", check.Name, check.Problems, tt.want)
+				}
+			}
+		})
+	}
+
+	// A later batch signed ahead of the account passes with a note
+	chain := healthy()
+	chain.sequence = 5
+	report, err := NewVerifier().CheckLive(routes, txRaw, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Go || !strings.Contains(report.Checks[2].Notes[0], "2 ahead") {
+		t.Errorf("expected a go with the sequence ahead noted, got %+v", report.Checks[2])
+	}
+
+	// Checks that cannot query the chain fail
+	chain = healthy()
+	chain.err = fmt.Errorf("connection refused")
+	report, err = NewVerifier().CheckLive(routes, txRaw, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Go {
+		t.Error("expected a no-go when the chain cannot be queried")
+	}
+}
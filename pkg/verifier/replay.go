@@ -0,0 +1,224 @@
+package verifier
+
+import (
+	"fmt"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// ComplianceReport compares the routes a past window of deposits should have produced with the
+// transfers the multisig actually sent on chain
+type ComplianceReport struct {
+	MultisigAddr     string `json:"multisig_address"`
+	FromHeight       int64  `json:"from_height"`
+	ToHeight         int64  `json:"to_height"`
+	OutboundToHeight int64  `json:"outbound_to_height"` // Outbound transfers were searched up to this height
+	Compliant        bool   `json:"compliant"`
+	ExpectedRoutes   int    `json:"expected_routes"`
+	ActualTransfers  int    `json:"actual_transfers"`
+
+	Matched    []ReplayMatch          `json:"matched,omitempty"`    // Routes forwarded by exactly one transfer
+	Aggregated []AggregateMatch       `json:"aggregated,omitempty"` // Routes forwarded together, e.g. aggregated or split
+	Missing    []types.HyperlaneRoute `json:"missing,omitempty"`    // Routes that were never forwarded
+	Unexpected []types.HyperlaneRoute `json:"unexpected,omitempty"` // Transfers without a deposit in the window
+
+	Skipped       []types.Skipped      `json:"skipped,omitempty"`        // Deposits that did not produce a route
+	FailedHeights []types.FailedHeight `json:"failed_heights,omitempty"` // Heights that could not be queried
+}
+
+// ReplayMatch pairs an expected route with the outbound transfer that forwarded it
+type ReplayMatch struct {
+	Route    types.HyperlaneRoute `json:"route"`
+	Transfer types.HyperlaneRoute `json:"transfer"`
+}
+
+// AggregateMatch records routes to one recipient whose total was forwarded by a different number
+// of transfers, as the aggregate and split strategies do
+type AggregateMatch struct {
+	DestinationDomain uint32   `json:"destination_domain"`
+	Recipient         string   `json:"recipient"`
+	TokenID           string   `json:"token_id"`
+	Amount            string   `json:"amount"`
+	Routes            []string `json:"routes"`    // Deposit tx hashes
+	Transfers         []string `json:"transfers"` // Outbound tx hashes
+}
+
+// transferKey identifies where a transfer goes, independently of the address encoding
+type transferKey struct {
+	destination        uint32
+	tokenID, recipient string
+}
+
+// Replay matches expected routes against the multisig's outbound transfers, parsed with
+// ParseOutgoing. Each transfer forwards at most one route; routes and transfers left over are
+// then compared by total per destination, recipient and token before being reported as missing or
+// unexpected. The report is compliant when nothing is missing or unexpected.
+func (v *Verifier) Replay(expected, actual *types.Routes) *ComplianceReport {
+	report := &ComplianceReport{
+		MultisigAddr:    expected.MultisigAddr,
+		ExpectedRoutes:  len(expected.Routes),
+		ActualTransfers: len(actual.Routes),
+	}
+
+	used := make([]bool, len(actual.Routes))
+	var leftover []types.HyperlaneRoute
+	for _, route := range expected.Routes {
+		if route.RouteInfo == nil {
+			report.Missing = append(report.Missing, route)
+			continue
+		}
+		key, amount := normalizedTransfer(&route)
+
+		matched := false
+		for j := range actual.Routes {
+			if used[j] || actual.Routes[j].RouteInfo == nil {
+				continue
+			}
+			if k, a := normalizedTransfer(&actual.Routes[j]); k == key && a == amount {
+				used[j] = true
+				report.Matched = append(report.Matched, ReplayMatch{Route: route, Transfer: actual.Routes[j]})
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			leftover = append(leftover, route)
+		}
+	}
+
+	var unused []types.HyperlaneRoute
+	for j, transfer := range actual.Routes {
+		if !used[j] {
+			unused = append(unused, transfer)
+		}
+	}
+
+	report.Aggregated, report.Missing, report.Unexpected = matchTotals(leftover, unused, report.Missing)
+	report.Compliant = len(report.Missing) == 0 && len(report.Unexpected) == 0
+	return report
+}
+
+// matchTotals groups unmatched routes and transfers by destination, recipient and token and accepts
+// groups whose totals agree. Everything else is appended to missing or returned as unexpected.
+// Routes must have route info.
+func matchTotals(routes, transfers, missing []types.HyperlaneRoute) ([]AggregateMatch, []types.HyperlaneRoute, []types.HyperlaneRoute) {
+	type group struct {
+		routes, transfers []types.HyperlaneRoute
+		routeTotal        math.Int
+		transferTotal     math.Int
+		valid             bool
+	}
+	var order []transferKey
+	groups := make(map[transferKey]*group)
+	add := func(route types.HyperlaneRoute, isTransfer bool) {
+		key, amount := normalizedTransfer(&route)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{routeTotal: math.ZeroInt(), transferTotal: math.ZeroInt(), valid: true}
+			groups[key] = g
+			order = append(order, key)
+		}
+		value, ok := math.NewIntFromString(amount)
+		if !ok {
+			g.valid = false
+			value = math.ZeroInt()
+		}
+		if isTransfer {
+			g.transfers = append(g.transfers, route)
+			g.transferTotal = g.transferTotal.Add(value)
+		} else {
+			g.routes = append(g.routes, route)
+			g.routeTotal = g.routeTotal.Add(value)
+		}
+	}
+	for _, route := range routes {
+		add(route, false)
+	}
+	var unexpected []types.HyperlaneRoute
+	for _, transfer := range transfers {
+		if transfer.RouteInfo == nil {
+			unexpected = append(unexpected, transfer)
+			continue
+		}
+		add(transfer, true)
+	}
+
+	var aggregated []AggregateMatch
+	for _, key := range order {
+		g := groups[key]
+		if g.valid && len(g.routes) > 0 && len(g.transfers) > 0 && g.routeTotal.Equal(g.transferTotal) {
+			m := AggregateMatch{
+				DestinationDomain: key.destination,
+				Recipient:         key.recipient,
+				TokenID:           key.tokenID,
+				Amount:            g.routeTotal.String(),
+			}
+			for _, route := range g.routes {
+				m.Routes = append(m.Routes, route.TxHash)
+			}
+			for _, transfer := range g.transfers {
+				m.Transfers = append(m.Transfers, transfer.TxHash)
+			}
+			aggregated = append(aggregated, m)
+			continue
+		}
+		missing = append(missing, g.routes...)
+		unexpected = append(unexpected, g.transfers...)
+	}
+	return aggregated, missing, unexpected
+}
+
+// normalizedTransfer returns the destination, token and recipient of a route in a comparable form,
+// and the amount its transfer carries
+func normalizedTransfer(route *types.HyperlaneRoute) (transferKey, string) {
+	amount, tokenID, recipient := expectedTransfer(route)
+	return transferKey{destination: route.RouteInfo.DestinationDomain, tokenID: tokenID, recipient: recipient}, amount
+}
+
+// PrintComplianceReport prints a compliance report in a human-readable format
+func (v *Verifier) PrintComplianceReport(report *ComplianceReport) {
+	if report.Compliant {
+		fmt.Fprintln(v.out, "✓ On-chain replay COMPLIANT")
+	} else {
+		fmt.Fprintln(v.out, "✗ On-chain replay NOT COMPLIANT")
+	}
+	fmt.Fprintf(v.out, "  Deposits from height %d to %d produced %d routes; %d outbound transfers up to height %d\n",
+		report.FromHeight, report.ToHeight, report.ExpectedRoutes, report.ActualTransfers, report.OutboundToHeight)
+	fmt.Fprintf(v.out, "  %d routes forwarded individually, %d groups forwarded together\n", len(report.Matched), len(report.Aggregated))
+
+	if len(report.Missing) > 0 {
+		fmt.Fprintln(v.out, "\nRoutes never forwarded:")
+		for _, route := range report.Missing {
+			fmt.Fprintf(v.out, "  - tx %s (height %d): %s\n", route.TxHash, route.BlockHeight, v.formatAmount(route))
+		}
+	}
+	if len(report.Unexpected) > 0 {
+		fmt.Fprintln(v.out, "\nTransfers without a matching deposit:")
+		for _, transfer := range report.Unexpected {
+			fmt.Fprintf(v.out, "  - tx %s (height %d): %s", transfer.TxHash, transfer.BlockHeight, v.formatAmount(transfer))
+			if transfer.RouteInfo != nil {
+				fmt.Fprintf(v.out, " to %s on domain %d", transfer.RouteInfo.Recipient, transfer.RouteInfo.DestinationDomain)
+			}
+			fmt.Fprintln(v.out)
+		}
+	}
+	if len(report.Skipped) > 0 {
+		fmt.Fprintf(v.out, "\n%d deposits did not produce a route (see the report for reasons)\n", len(report.Skipped))
+	}
+	if len(report.FailedHeights) > 0 {
+		fmt.Fprintf(v.out, "\n⚠ %d heights could not be queried; the report is incomplete\n", len(report.FailedHeights))
+	}
+}
+
+// formatAmount shows the amount of a route with the display settings of its destination
+func (v *Verifier) formatAmount(route types.HyperlaneRoute) string {
+	if route.RouteInfo == nil {
+		return route.Amount + " " + route.Denom
+	}
+	display := v.display[route.RouteInfo.DestinationDomain]
+	if display == nil {
+		return route.Amount + " " + route.Denom
+	}
+	return display.FormatAmount(route.Amount, route.Denom)
+}
@@ -0,0 +1,72 @@
+package verifier
+
+import (
+	"testing"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+func TestReplay(t *testing.T) {
+	const (
+		tokenID = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+		alice   = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+		bob     = "0x1111111111111111111111111111111111111111"
+		carol   = "0x2222222222222222222222222222222222222222"
+	)
+	// Outbound transfers are parsed with 32-byte hex recipients
+	padded := func(recipient string) string {
+		_, _, r := expectedTransfer(&types.HyperlaneRoute{RouteInfo: &types.RouteInfo{Recipient: recipient}})
+		return r
+	}
+	deposit := func(hash, amount, recipient string) types.HyperlaneRoute {
+		return types.HyperlaneRoute{
+			TxHash:    hash,
+			Amount:    amount,
+			RouteInfo: &types.RouteInfo{DestinationDomain: 1, Recipient: recipient, TokenID: tokenID},
+		}
+	}
+	transfer := func(hash, amount, recipient string) types.HyperlaneRoute {
+		return types.HyperlaneRoute{
+			TxHash:    hash,
+			Amount:    amount,
+			RouteInfo: &types.RouteInfo{DestinationDomain: 1, Recipient: padded(recipient), TokenID: tokenID},
+		}
+	}
+
+	expected := &types.Routes{MultisigAddr: "celestia1multisig", Routes: []types.HyperlaneRoute{
+		deposit("D1", "100", alice),
+		deposit("D2", "40", bob), // aggregated with D3
+		deposit("D3", "60", bob),
+		deposit("D4", "70", carol), // never forwarded
+	}}
+	actual := &types.Routes{Routes: []types.HyperlaneRoute{
+		transfer("T1", "100", alice),
+		transfer("T2", "100", bob),
+		transfer("T3", "5", alice), // no deposit
+	}}
+
+	v := NewVerifier()
+	report := v.Replay(expected, actual)
+
+	if report.Compliant {
+		t.Error("report is compliant despite a missing route and an unexpected transfer")
+	}
+	if len(report.Matched) != 1 || report.Matched[0].Route.TxHash != "D1" || report.Matched[0].Transfer.TxHash != "T1" {
+		t.Errorf("Matched = %+v, want D1 -> T1", report.Matched)
+	}
+	if len(report.Aggregated) != 1 || report.Aggregated[0].Amount != "100" || len(report.Aggregated[0].Routes) != 2 {
+		t.Errorf("Aggregated = %+v, want D2 and D3 -> T2", report.Aggregated)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].TxHash != "D4" {
+		t.Errorf("Missing = %+v, want D4", report.Missing)
+	}
+	if len(report.Unexpected) != 1 || report.Unexpected[0].TxHash != "T3" {
+		t.Errorf("Unexpected = %+v, want T3", report.Unexpected)
+	}
+
+	// Forwarding every deposit is compliant
+	actual.Routes = append(actual.Routes[:2], transfer("T4", "70", carol))
+	if report := v.Replay(expected, actual); !report.Compliant {
+		t.Errorf("report not compliant: missing %+v, unexpected %+v", report.Missing, report.Unexpected)
+	}
+}
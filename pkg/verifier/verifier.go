@@ -1,36 +1,190 @@
 package verifier
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"cosmossdk.io/math"
 	warptypes "github.com/bcp-innovations/hyperlane-cosmos/x/warp/types"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/metrics"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/output"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
-	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 )
 
 // Verifier validates that a transaction matches the intended routes
-type Verifier struct{}
+type Verifier struct {
+	grant     types.AuthzConfig    // Expected authz grant for transfers wrapped in MsgExec
+	metadata  types.MetadataConfig // Expected CustomHookMetadata forwarding
+	memos     map[uint32]types.DestinationConfig
+	display   map[uint32]*types.DisplayConfig
+	whitelist types.AddressWhitelist     // Allowed token IDs and amount caps of the destination domains
+	caps      map[uint32]math.Int        // Most a single transfer to each domain may carry
+	fee       types.RebalancingFeeConfig // Operating fee the multisig keeps from every transfer
+	strict    bool                       // Fail on any message not accounted for by a route
+	warnAge   int64                      // Route age in blocks above which a route is reported
+	maxAge    int64                      // Route age in blocks above which a route fails verification
+	height    int64                      // Current chain height route ages are measured at
+	out       io.Writer                  // Where the reports are printed
+	now       func() time.Time
+}
 
 // NewVerifier creates a new transaction verifier
 func NewVerifier() *Verifier {
-	return &Verifier{}
+	return &Verifier{out: os.Stdout, now: time.Now}
+}
+
+// NewVerifierWithGrant creates a transaction verifier that checks transfers executed through
+// authz against the grant described in grant
+func NewVerifierWithGrant(grant types.AuthzConfig) *Verifier {
+	return &Verifier{grant: grant, out: os.Stdout, now: time.Now}
+}
+
+// SetMetadataPolicy makes the verifier check that every transfer forwards the CustomHookMetadata
+// required by policy, the deposit's original metadata or a receipt derived from it
+func (v *Verifier) SetMetadataPolicy(metadata types.MetadataConfig) {
+	v.metadata = metadata
+}
+
+// SetMemoTemplates makes the verifier check that the transaction memo is the one rendered from the
+// memo templates of the destinations the transaction transfers to
+func (v *Verifier) SetMemoTemplates(destinations map[uint32]types.DestinationConfig) {
+	v.memos = nil
+	for domain, destination := range destinations {
+		if destination.MemoTemplate == "" {
+			continue
+		}
+		if v.memos == nil {
+			v.memos = make(map[uint32]types.DestinationConfig)
+		}
+		v.memos[domain] = destination
+	}
+}
+
+// SetDisplay makes the verifier show amounts in its reports with the display settings of the
+// destinations they are sent to
+func (v *Verifier) SetDisplay(destinations map[uint32]types.DestinationConfig) {
+	v.display = nil
+	for domain, destination := range destinations {
+		if destination.Display == nil {
+			continue
+		}
+		if v.display == nil {
+			v.display = make(map[uint32]*types.DisplayConfig)
+		}
+		v.display[domain] = destination.Display
+	}
+}
+
+// SetWhitelist makes the verifier check that every transfer uses a token ID the whitelist allows
+// for its domain, and the routes against the whitelist's per-route and per-run amount caps
+func (v *Verifier) SetWhitelist(whitelist types.AddressWhitelist) {
+	v.whitelist = whitelist
+}
+
+// SetMessageCaps makes the verifier refuse transfers above the max_amount_per_message of their
+// destination. A route split under its cap is matched by its parts, each at most the cap.
+func (v *Verifier) SetMessageCaps(destinations map[uint32]types.DestinationConfig) {
+	v.caps = nil
+	for domain, destination := range destinations {
+		limit, err := destination.MessageCap()
+		if err != nil || limit.IsNil() {
+			continue
+		}
+		if v.caps == nil {
+			v.caps = make(map[uint32]math.Int)
+		}
+		v.caps[domain] = limit
+	}
+}
+
+// SetRebalancingFee makes the verifier expect every deposit to forward its amount less exactly the
+// fee policy's fee. Routes the fee was not kept from yet are deducted as generate deducts them, and
+// the fees recorded in planned routes must be the policy's.
+func (v *Verifier) SetRebalancingFee(fee types.RebalancingFeeConfig) {
+	v.fee = fee
+}
+
+// SetOutput makes the verifier print its reports to w instead of stdout
+func (v *Verifier) SetOutput(w io.Writer) {
+	v.out = w
+}
+
+// SetStrict makes the verifier account for every message in the transaction: messages of unknown
+// type, MsgRemoteTransfer messages that cannot be decoded or are not sent by the multisig, and
+// transfers matching no route all fail verification and are reported in UnexpectedMessages
+func (v *Verifier) SetStrict(strict bool) {
+	v.strict = strict
+}
+
+// SetRouteAges makes the verifier warn about routes deposited more than warnAfter blocks before
+// the chain height, and fail on routes deposited more than maxAge blocks before it, since a stale
+// route may have been handled manually already. Zero disables either check. Ages are only checked
+// once SetChainHeight has given the verifier a height.
+func (v *Verifier) SetRouteAges(warnAfter, maxAge int64) {
+	v.warnAge = warnAfter
+	v.maxAge = maxAge
+}
+
+// SetChainHeight sets the current chain height the ages of routes are measured at
+func (v *Verifier) SetChainHeight(height int64) {
+	v.height = height
 }
 
 // VerifyResult contains the result of transaction verification
 type VerifyResult struct {
-	Valid        bool     `json:"valid"`
-	MatchedCount int      `json:"matched_count"`
-	TotalRoutes  int      `json:"total_routes"`
-	Errors       []string `json:"errors,omitempty"`
-	Warnings     []string `json:"warnings,omitempty"`
+	Valid        bool          `json:"valid"`
+	MatchedCount int           `json:"matched_count"`
+	TotalRoutes  int           `json:"total_routes"`
+	Routes       []RouteResult `json:"routes,omitempty"` // Per-route details, in routes file order
+	Errors       []string      `json:"errors,omitempty"`
+	Warnings     []string      `json:"warnings,omitempty"`
+	// UnexpectedMessages lists the messages strict verification could not account for
+	UnexpectedMessages []UnexpectedMessage `json:"unexpected_messages,omitempty"`
+}
+
+// UnexpectedMessage is a message of the transaction that no route accounts for
+type UnexpectedMessage struct {
+	// Position is the message's index in the transaction body, followed by "/" and its index in the
+	// MsgExec for messages executed through authz, e.g. "1/0"
+	Position string `json:"position"`
+	TypeURL  string `json:"type_url"`
+	Reason   string `json:"reason"`
 }
 
-// VerifyFromFiles reads routes and transaction from files and verifies them
+// RouteResult is the verification outcome for one route
+type RouteResult struct {
+	Index        int               `json:"index"`   // Position in the routes file
+	TxHash       string            `json:"tx_hash"` // Source transaction(s) of the route
+	Matched      bool              `json:"matched"`
+	MessageIndex int               `json:"message_index"` // Matching MsgRemoteTransfer, or the closest candidate if unmatched; -1 if none
+	Overridden   bool              `json:"overridden,omitempty"`
+	Fields       []FieldComparison `json:"fields,omitempty"` // Comparison against the message at MessageIndex
+	// SplitMessages lists the messages carrying the parts of a route split into several transfers,
+	// which add up to its amount; MessageIndex is the first of them
+	SplitMessages []int `json:"split_messages,omitempty"`
+	// Provenance lists the deposits of an aggregated route and what each contributed
+	Provenance []types.RouteSource `json:"provenance,omitempty"`
+}
+
+// FieldComparison compares one field of a route with the corresponding field of a message
+type FieldComparison struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Match    bool   `json:"match"`
+	Error    string `json:"error,omitempty"` // Why the expected value could not be determined; the field then never matches
+}
+
+// VerifyFromFiles reads routes and transaction from files and verifies them. The transaction may be
+// in any format DecodeTxRaw accepts, so the artifact produced by the signer can be checked as is.
 func (v *Verifier) VerifyFromFiles(routesFile, txFile string) (*VerifyResult, error) {
 	// Read routes
 	routesData, err := os.ReadFile(routesFile)
@@ -43,26 +197,46 @@ func (v *Verifier) VerifyFromFiles(routesFile, txFile string) (*VerifyResult, er
 		return nil, fmt.Errorf("failed to parse routes file: %w", err)
 	}
 
-	// Read transaction
-	txData, err := os.ReadFile(txFile)
+	// Read transaction, decrypting it if it was encrypted for distribution
+	txData, err := output.ReadFile(txFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read transaction file: %w", err)
 	}
 
-	var txRaw tx.TxRaw
-	if err := json.Unmarshal(txData, &txRaw); err != nil {
+	txRaw, err := generator.DecodeTxRaw(txData)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse transaction file: %w", err)
 	}
 
-	return v.Verify(&routes, &txRaw)
+	return v.Verify(&routes, txRaw)
 }
 
 // Verify checks if a transaction matches the intended routes
 func (v *Verifier) Verify(routes *types.Routes, txRaw *tx.TxRaw) (*VerifyResult, error) {
-	result := &VerifyResult{
-		Valid:       true,
-		TotalRoutes: len(routes.Routes),
+	result, err := v.verify(routes, txRaw)
+	switch {
+	case err != nil:
+		metrics.Verifications.WithLabelValues("error").Inc()
+	case result.Valid:
+		metrics.Verifications.WithLabelValues("valid").Inc()
+	default:
+		metrics.Verifications.WithLabelValues("invalid").Inc()
 	}
+	return result, err
+}
+
+// verify runs the checks of Verify
+func (v *Verifier) verify(routes *types.Routes, txRaw *tx.TxRaw) (*VerifyResult, error) {
+	result := &VerifyResult{Valid: true}
+
+	// The rebalancing fee is kept per deposit, before its route is divided or merged
+	routes = v.keepFees(routes, result)
+
+	// Fan-out routes are matched one split recipient at a time
+	expanded, parents := expandRoutes(routes, result)
+	result.TotalRoutes = len(expanded)
+	checkProvenance(routes.Routes, result)
+	v.checkRouteAges(routes.Routes, result)
 
 	// Decode transaction body
 	var txBody tx.TxBody
@@ -72,133 +246,648 @@ func (v *Verifier) Verify(routes *types.Routes, txRaw *tx.TxRaw) (*VerifyResult,
 		return result, nil
 	}
 
-	// Extract MsgRemoteTransfer messages
+	// Decode auth info to surface a distinct fee payer, which takes its own signature slot
+	if len(txRaw.AuthInfoBytes) > 0 {
+		var authInfo tx.AuthInfo
+		if err := authInfo.Unmarshal(txRaw.AuthInfoBytes); err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to decode transaction auth info: %v", err))
+			return result, nil
+		}
+		if authInfo.Fee != nil && authInfo.Fee.Payer != "" && authInfo.Fee.Payer != routes.MultisigAddr {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("fee payer %s is distinct from the multisig and must sign after the multisig", authInfo.Fee.Payer))
+		}
+	}
+
+	// In strict mode, every message must be accounted for
+	unexpected := func(position, typeURL, reason string) {
+		if !v.strict {
+			return
+		}
+		result.Valid = false
+		result.UnexpectedMessages = append(result.UnexpectedMessages, UnexpectedMessage{Position: position, TypeURL: typeURL, Reason: reason})
+		result.Errors = append(result.Errors, fmt.Sprintf("unexpected message %s (%s): %s", position, typeURL, reason))
+	}
+
+	// Extract MsgRemoteTransfer messages, including those executed through an authz MsgExec,
+	// remembering where each one is in the transaction
 	var remoteTxs []*warptypes.MsgRemoteTransfer
-	for _, anyMsg := range txBody.Messages {
-		// Check type URL to identify MsgRemoteTransfer
-		if anyMsg.TypeUrl == "/hyperlane.warp.v1.MsgRemoteTransfer" {
+	var positions []string
+	for i, anyMsg := range txBody.Messages {
+		switch anyMsg.TypeUrl {
+		case types.MsgRemoteTransferTypeURL:
 			var remoteMsg warptypes.MsgRemoteTransfer
 			if err := remoteMsg.Unmarshal(anyMsg.Value); err != nil {
+				unexpected(strconv.Itoa(i), anyMsg.TypeUrl, fmt.Sprintf("cannot be decoded: %v", err))
 				continue
 			}
+			if routes.MultisigAddr != "" && remoteMsg.Sender != routes.MultisigAddr {
+				unexpected(strconv.Itoa(i), anyMsg.TypeUrl, fmt.Sprintf("is sent by %s instead of the multisig %s", remoteMsg.Sender, routes.MultisigAddr))
+			}
 			remoteTxs = append(remoteTxs, &remoteMsg)
+			positions = append(positions, strconv.Itoa(i))
+		case authzMsgExec:
+			var exec authz.MsgExec
+			if err := exec.Unmarshal(anyMsg.Value); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to decode authz MsgExec: %v", err))
+				continue
+			}
+			transfers, inner := v.checkExec(&exec, routes.MultisigAddr, result)
+			remoteTxs = append(remoteTxs, transfers...)
+			for _, j := range inner {
+				positions = append(positions, fmt.Sprintf("%d/%d", i, j))
+			}
+		default:
+			unexpected(strconv.Itoa(i), anyMsg.TypeUrl, "is not a MsgRemoteTransfer")
 		}
 	}
 
-	// Check if we have the right number of messages
-	if len(remoteTxs) != len(routes.Routes) {
+	if v.memos != nil {
+		expected, err := types.RenderMemo(v.memos, generator.MemoTransfers(remoteTxs))
+		switch {
+		case err != nil:
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to render the expected memo: %v", err))
+		case txBody.Memo != expected:
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("memo %q does not match the destination memo templates, expected %q", txBody.Memo, expected))
+		}
+	}
+
+	// A transaction matching its routes still must only use whitelisted tokens and stay within the caps
+	for i, msg := range remoteTxs {
+		tokenID := fmt.Sprintf("0x%x", msg.TokenId[:])
+		if !v.whitelist.AllowsTokenID(msg.DestinationDomain, tokenID) {
+			result.Valid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("message %d transfers token %s, which is not whitelisted for domain %d", i, tokenID, msg.DestinationDomain))
+		}
+		if limit, ok := v.caps[msg.DestinationDomain]; ok && !msg.Amount.IsNil() && msg.Amount.GT(limit) {
+			result.Valid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("message %d transfers %s to domain %d, above its max_amount_per_message of %s", i, msg.Amount, msg.DestinationDomain, limit))
+		}
+	}
+	for _, err := range v.whitelist.AmountViolations(expanded) {
 		result.Valid = false
-		result.Errors = append(result.Errors,
-			fmt.Sprintf("transaction has %d MsgRemoteTransfer messages, but routes has %d entries",
-				len(remoteTxs), len(routes.Routes)))
+		result.Errors = append(result.Errors, fmt.Sprintf("amount cap exceeded: %v", err))
 	}
 
-	// Verify each route matches a message
-	for i, route := range routes.Routes {
+	// Verify each route matches a message; a message can only satisfy one route. Routes matching no
+	// single message are tried against split transfers once every exact match is taken.
+	used := make([]bool, len(remoteTxs))
+	var unmatched []int
+	for k, route := range expanded {
+		i := parents[k]
+		routeResult := RouteResult{Index: i, TxHash: route.TxHash, MessageIndex: -1, Overridden: route.Override != nil, Provenance: route.Provenance}
+
 		if route.RouteInfo == nil {
 			result.Valid = false
 			result.Errors = append(result.Errors,
 				fmt.Sprintf("route %d from tx %s has no routing info", i, route.TxHash))
+			result.Routes = append(result.Routes, routeResult)
 			continue
 		}
 
-		// Find matching message
-		found := false
-		for _, msg := range remoteTxs {
-			if v.MatchesRoute(msg, &route) {
-				found = true
-				result.MatchedCount++
+		// Manual overrides are legitimate but must be reviewed by every signer
+		if route.Override != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("route %d (tx: %s) was manually overridden: %s", i, route.TxHash, route.OverrideSummary()))
+		}
+		if mismatch := route.AmountMismatch(); mismatch != "" {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("route %d (tx: %s) %s", i, route.TxHash, mismatch))
+		}
+		if route.RouteInfo.RecipientDefaulted {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("route %d (tx: %s) has no recipient in its metadata and uses the default recipient %s of domain %d",
+					i, route.TxHash, route.RouteInfo.Recipient, route.RouteInfo.DestinationDomain))
+		}
+
+		// Find the matching message, remembering the closest candidate for the report
+		bestScore := -1
+		for j, msg := range remoteTxs {
+			if used[j] {
+				continue
+			}
+			fields := v.CompareRoute(msg, &route)
+			score := 0
+			for _, f := range fields {
+				if f.Match {
+					score++
+				}
+			}
+			if score > bestScore {
+				bestScore = score
+				routeResult.MessageIndex = j
+				routeResult.Fields = fields
+			}
+			if score == len(fields) {
+				routeResult.Matched = true
 				break
 			}
 		}
 
-		if !found {
-			result.Valid = false
-			result.Errors = append(result.Errors,
-				fmt.Sprintf("no matching MsgRemoteTransfer found for route %d (tx: %s, domain: %d, amount: %s)",
-					i, route.TxHash, route.RouteInfo.DestinationDomain, route.Amount))
+		if routeResult.Matched {
+			used[routeResult.MessageIndex] = true
+			result.MatchedCount++
+		} else {
+			unmatched = append(unmatched, len(result.Routes))
+		}
+		result.Routes = append(result.Routes, routeResult)
+	}
+
+	messages := len(expanded)
+	for _, r := range unmatched {
+		routeResult := &result.Routes[r]
+		route := &expanded[r]
+		if parts := v.matchSplit(route, remoteTxs, used); parts != nil {
+			for _, j := range parts {
+				used[j] = true
+			}
+			routeResult.Matched = true
+			routeResult.MessageIndex = parts[0]
+			routeResult.SplitMessages = parts
+			routeResult.Fields = splitFields(v.CompareRoute(remoteTxs[parts[0]], route), remoteTxs, parts)
+			result.MatchedCount++
+			messages += len(parts) - 1
+			continue
+		}
+		result.Valid = false
+		result.Errors = append(result.Errors,
+			fmt.Sprintf("no matching MsgRemoteTransfer found for route %d (tx: %s, domain: %d, amount: %s)",
+				routeResult.Index, route.TxHash, route.RouteInfo.DestinationDomain, route.Amount))
+	}
+
+	// Check if we have the right number of messages
+	if len(remoteTxs) != messages {
+		result.Valid = false
+		result.Errors = append(result.Errors,
+			fmt.Sprintf("transaction has %d MsgRemoteTransfer messages, but routes account for %d",
+				len(remoteTxs), messages))
+	}
+
+	for j, msg := range remoteTxs {
+		if !used[j] {
+			unexpected(positions[j], types.MsgRemoteTransferTypeURL,
+				fmt.Sprintf("transfers %s to 0x%x on domain %d and matches no route", msg.Amount, msg.Recipient[:], msg.DestinationDomain))
 		}
 	}
 
 	return result, nil
 }
 
-// MatchesRoute checks if a MsgRemoteTransfer matches a HyperlaneRoute
-func (v *Verifier) MatchesRoute(msg *warptypes.MsgRemoteTransfer, route *types.HyperlaneRoute) bool {
-	// Check destination domain
-	if msg.DestinationDomain != route.RouteInfo.DestinationDomain {
-		return false
+// matchSplit returns the unused messages carrying route split into several transfers: each matches
+// the route but for its amount, stays within the domain's cap, and together they add up to the
+// route's amount. It returns nil if there are no such messages.
+func (v *Verifier) matchSplit(route *types.HyperlaneRoute, msgs []*warptypes.MsgRemoteTransfer, used []bool) []int {
+	expectedAmount, _, _ := expectedTransfer(route)
+	want, ok := math.NewIntFromString(expectedAmount)
+	if !ok || !want.IsPositive() {
+		return nil
 	}
+	limit := v.caps[route.RouteInfo.DestinationDomain]
 
-	// Check amount
-	expectedAmount := route.Amount
-	if route.RouteInfo.Amount != "" {
-		expectedAmount = route.RouteInfo.Amount
+	var parts []int
+	sum := math.ZeroInt()
+	for j, msg := range msgs {
+		if used[j] || msg.Amount.IsNil() || !msg.Amount.IsPositive() || sum.Add(msg.Amount).GT(want) {
+			continue
+		}
+		if !limit.IsNil() && msg.Amount.GT(limit) {
+			continue
+		}
+		part := withAmount(*route, msg.Amount.String())
+		matches := true
+		for _, f := range v.CompareRoute(msg, &part) {
+			matches = matches && f.Match
+		}
+		if !matches {
+			continue
+		}
+		parts = append(parts, j)
+		if sum = sum.Add(msg.Amount); sum.Equal(want) {
+			break
+		}
 	}
+	if len(parts) < 2 || !sum.Equal(want) {
+		return nil
+	}
+	return parts
+}
 
-	if msg.Amount.String() != expectedAmount {
-		return false
+// withAmount returns route carrying amount instead, keeping a metadata amount override in sync
+func withAmount(route types.HyperlaneRoute, amount string) types.HyperlaneRoute {
+	route.Amount = amount
+	if route.RouteInfo != nil && route.RouteInfo.Amount != "" {
+		info := *route.RouteInfo
+		info.Amount = amount
+		route.RouteInfo = &info
 	}
+	return route
+}
 
-	// Check token ID (convert both to hex strings for comparison)
-	msgTokenIDHex := fmt.Sprintf("%x", msg.TokenId[:])
-	expectedTokenIDHex := strings.TrimPrefix(strings.ToLower(route.RouteInfo.TokenID), "0x")
-	if msgTokenIDHex != expectedTokenIDHex {
-		return false
+// splitFields reports a split route's amount as the sum of its parts, e.g. "100+100+50"
+func splitFields(fields []FieldComparison, msgs []*warptypes.MsgRemoteTransfer, parts []int) []FieldComparison {
+	amounts := make([]string, len(parts))
+	for i, j := range parts {
+		amounts[i] = msgs[j].Amount.String()
 	}
+	for i := range fields {
+		if fields[i].Field == "amount" {
+			fields[i].Actual = strings.Join(amounts, "+")
+			fields[i].Match = true
+		}
+	}
+	return fields
+}
 
-	// Check recipient (convert both to hex strings for comparison)
-	msgRecipientHex := fmt.Sprintf("%x", msg.Recipient[:])
-	expectedRecipientHex := strings.TrimPrefix(strings.ToLower(route.RouteInfo.Recipient), "0x")
+// expandRoutes returns the routes with each fan-out route expanded into one route per split
+// recipient, and the position in the routes file each expanded route comes from. The splits of a
+// fan-out route must add up to its amount; a route whose splits do not fails verification and is
+// kept whole, so it matches no message.
+func expandRoutes(routes *types.Routes, result *VerifyResult) ([]types.HyperlaneRoute, []int) {
+	var (
+		expanded []types.HyperlaneRoute
+		parents  []int
+	)
+	for i := range routes.Routes {
+		route := &routes.Routes[i]
+		parts, err := route.ExpandSplits()
+		if err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("route %d: %v", i, err))
+			parts = []types.HyperlaneRoute{*route}
+		} else if route.RouteInfo != nil && len(route.RouteInfo.Splits) > 0 {
+			// The expanded routes no longer carry the deposit, so compare it here
+			if mismatch := route.AmountMismatch(); mismatch != "" {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("route %d (tx: %s) %s", i, route.TxHash, mismatch))
+			}
+		}
+		for range parts {
+			parents = append(parents, i)
+		}
+		expanded = append(expanded, parts...)
+	}
+	return expanded, parents
+}
+
+// checkRouteAges reports the routes older than the route ages, by the height of their oldest
+// deposit: an aggregated route carries the height of its newest deposit, which would hide a stale
+// deposit merged with a fresh one. Deposits without a height, e.g. added by hand, cannot be aged
+// and are left alone.
+func (v *Verifier) checkRouteAges(routes []types.HyperlaneRoute, result *VerifyResult) {
+	if v.height <= 0 || (v.warnAge <= 0 && v.maxAge <= 0) {
+		return
+	}
+	for i, route := range routes {
+		sources := route.Provenance
+		if len(sources) == 0 {
+			sources = []types.RouteSource{{TxHash: route.TxHash, BlockHeight: route.BlockHeight}}
+		}
+		var oldest, newest *types.RouteSource
+		for k := range sources {
+			source := &sources[k]
+			if source.BlockHeight <= 0 {
+				continue
+			}
+			if oldest == nil || source.BlockHeight < oldest.BlockHeight {
+				oldest = source
+			}
+			if newest == nil || source.BlockHeight > newest.BlockHeight {
+				newest = source
+			}
+		}
+		if oldest == nil {
+			continue
+		}
+		if newest.BlockHeight > v.height {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("route %d: deposit %s was made at height %d, after the chain height %d its age is checked at",
+					i, newest.TxHash, newest.BlockHeight, v.height))
+		}
+		age := v.height - oldest.BlockHeight
+		switch {
+		case v.maxAge > 0 && age > v.maxAge:
+			result.Valid = false
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("route %d: deposit %s was made %d blocks ago at height %d, above the max route age of %d blocks: it may have been handled manually already",
+					i, oldest.TxHash, age, oldest.BlockHeight, v.maxAge))
+		case v.warnAge > 0 && age > v.warnAge:
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("route %d: deposit %s was made %d blocks ago at height %d, check it was not handled manually since",
+					i, oldest.TxHash, age, oldest.BlockHeight))
+		}
+	}
+}
+
+// keepFees returns routes with the rebalancing fee kept from every deposit it was not kept from yet,
+// as generate keeps it, so a transaction can be verified against the routes as parsed as well as
+// against the planned routes. Fees already recorded in the routes must be exactly the policy's.
+func (v *Verifier) keepFees(routes *types.Routes, result *VerifyResult) *types.Routes {
+	deducted := *routes
+	deducted.Routes = append([]types.HyperlaneRoute(nil), routes.Routes...)
+	for i := range deducted.Routes {
+		route := &deducted.Routes[i]
+		kept := route.KeptFees()
+		if len(kept) == 0 {
+			if err := v.fee.DeductFrom(route); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, fmt.Sprintf("route %d: %v", i, err))
+			}
+			continue
+		}
+
+		if !v.fee.Enabled() {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("route %d (tx: %s) kept a rebalancing fee from its deposits that was not checked (set rebalancing_fee in the config)", i, route.TxHash))
+			continue
+		}
+		for _, fee := range kept {
+			if err := v.fee.Check(fee); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, fmt.Sprintf("route %d (tx: %s) %v", i, route.TxHash, err))
+			}
+		}
+	}
+	return &deducted
+}
+
+// checkProvenance checks that the deposits recorded for every aggregated route are the ones its tx
+// hashes name and add up to its amount. An aggregated route split by the limits is checked as a
+// whole, by summing the parts with the same tx hashes and destination.
+func checkProvenance(routes []types.HyperlaneRoute, result *VerifyResult) {
+	type group struct {
+		index  int
+		route  *types.HyperlaneRoute
+		amount math.Int
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
 
-	// Handle Cosmos bech32 addresses - decode and compare bytes
-	if !strings.HasPrefix(route.RouteInfo.Recipient, "0x") {
-		// Attempt to decode as bech32
-		addr, err := sdk.AccAddressFromBech32(route.RouteInfo.Recipient)
-		if err == nil {
-			// Pad to 32 bytes for comparison
-			paddedAddr := make([]byte, 32)
-			copy(paddedAddr[32-len(addr.Bytes()):], addr.Bytes())
-			expectedRecipientHex = fmt.Sprintf("%x", paddedAddr)
+	for i := range routes {
+		route := &routes[i]
+		if len(route.Provenance) == 0 {
+			continue
 		}
+		amount, ok := math.NewIntFromString(route.Amount)
+		if !ok {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("route %d (tx: %s) has invalid amount %s", i, route.TxHash, route.Amount))
+			continue
+		}
+		key := route.TxHash
+		if route.RouteInfo != nil {
+			key = fmt.Sprintf("%s/%d/%s/%s/%s", route.TxHash, route.RouteInfo.DestinationDomain,
+				strings.ToLower(route.RouteInfo.Recipient), strings.ToLower(route.RouteInfo.TokenID), route.Denom)
+		}
+		if g, ok := byKey[key]; ok {
+			g.amount = g.amount.Add(amount)
+			continue
+		}
+		byKey[key] = &group{index: i, route: route, amount: amount}
+		groups = append(groups, byKey[key])
+	}
+
+	for _, g := range groups {
+		fail := func(format string, args ...interface{}) {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("route %d (tx: %s) ", g.index, g.route.TxHash)+fmt.Sprintf(format, args...))
+		}
+
+		hashes := make([]string, len(g.route.Provenance))
+		total := math.ZeroInt()
+		valid := true
+		for j, source := range g.route.Provenance {
+			hashes[j] = source.TransferKey()
+			amount, ok := math.NewIntFromString(source.Amount)
+			if !ok {
+				fail("records invalid amount %s for deposit tx %s", source.Amount, source.TxHash)
+				valid = false
+				continue
+			}
+			total = total.Add(amount)
+		}
+		if joined := strings.Join(hashes, ","); joined != g.route.TxHash {
+			fail("records deposits %s in its provenance", joined)
+		}
+		if valid && !total.Equal(g.amount) {
+			fail("transfers %s but its deposits contributed %s", g.amount, total)
+		}
+	}
+}
+
+// authzMsgExec is the type URL of an authz MsgExec
+const authzMsgExec = "/cosmos.authz.v1beta1.MsgExec"
+
+// checkExec checks that an authz MsgExec stays within the grant from the multisig and returns
+// the transfers it executes with their indices in the MsgExec. Scope violations are recorded as
+// errors on result.
+func (v *Verifier) checkExec(exec *authz.MsgExec, multisig string, result *VerifyResult) ([]*warptypes.MsgRemoteTransfer, []int) {
+	fail := func(format string, args ...interface{}) {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	if !v.grant.Enabled() {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("transfers are executed through authz by grantee %s; the grant was not checked (set authz.grantee in the config)", exec.Grantee))
 	} else {
-		// For EVM addresses, need to pad to 32 bytes
-		recipientBytes, err := hex.DecodeString(expectedRecipientHex)
-		if err == nil && len(recipientBytes) < 32 {
-			paddedRecipient := make([]byte, 32)
-			copy(paddedRecipient[32-len(recipientBytes):], recipientBytes)
-			expectedRecipientHex = fmt.Sprintf("%x", paddedRecipient)
+		if exec.Grantee != v.grant.Grantee {
+			fail("authz MsgExec grantee %s does not match the configured grantee %s", exec.Grantee, v.grant.Grantee)
+		}
+		expiration, err := v.grant.ExpiresAt()
+		if err != nil {
+			fail("invalid authz grant: %v", err)
+		} else if !expiration.IsZero() && !v.now().Before(expiration) {
+			fail("authz grant to %s expired at %s", v.grant.Grantee, expiration.Format(time.RFC3339))
 		}
 	}
 
-	if msgRecipientHex != expectedRecipientHex {
-		return false
+	// The grant only covers MsgRemoteTransfer from the multisig; anything else would either fail
+	// on-chain or, worse, use a broader grant than intended
+	var transfers []*warptypes.MsgRemoteTransfer
+	var indices []int
+	for i, anyMsg := range exec.Msgs {
+		if anyMsg.TypeUrl != types.MsgRemoteTransferTypeURL {
+			fail("authz MsgExec message %d is %s, outside the granted %s scope", i, anyMsg.TypeUrl, types.MsgRemoteTransferTypeURL)
+			continue
+		}
+		var remoteMsg warptypes.MsgRemoteTransfer
+		if err := remoteMsg.Unmarshal(anyMsg.Value); err != nil {
+			fail("failed to decode authz MsgExec message %d: %v", i, err)
+			continue
+		}
+		if multisig != "" && remoteMsg.Sender != multisig {
+			fail("authz MsgExec message %d is sent by %s instead of the multisig %s", i, remoteMsg.Sender, multisig)
+		}
+		transfers = append(transfers, &remoteMsg)
+		indices = append(indices, i)
 	}
+	return transfers, indices
+}
 
+// MatchesRoute checks if a MsgRemoteTransfer matches a HyperlaneRoute
+func (v *Verifier) MatchesRoute(msg *warptypes.MsgRemoteTransfer, route *types.HyperlaneRoute) bool {
+	for _, f := range v.CompareRoute(msg, route) {
+		if !f.Match {
+			return false
+		}
+	}
 	return true
 }
 
+// CompareRoute compares a MsgRemoteTransfer with a HyperlaneRoute field by field. Token IDs and
+// recipients are compared as 32-byte hex. With a metadata policy, the forwarded CustomHookMetadata
+// is compared too.
+func (v *Verifier) CompareRoute(msg *warptypes.MsgRemoteTransfer, route *types.HyperlaneRoute) []FieldComparison {
+	expectedAmount, expectedTokenID, expectedRecipient := expectedTransfer(route)
+
+	msgAmount := ""
+	if !msg.Amount.IsNil() {
+		msgAmount = msg.Amount.String()
+	}
+
+	fields := []FieldComparison{
+		compare("destination_domain", fmt.Sprintf("%d", route.RouteInfo.DestinationDomain), fmt.Sprintf("%d", msg.DestinationDomain)),
+		compare("amount", expectedAmount, msgAmount),
+		compare("token_id", expectedTokenID, fmt.Sprintf("0x%x", msg.TokenId[:])),
+		compare("recipient", expectedRecipient, fmt.Sprintf("0x%x", msg.Recipient[:])),
+	}
+
+	if v.metadata.Enabled() {
+		expected, err := v.metadata.ForwardedMetadata(route)
+		if err != nil {
+			fields = append(fields, FieldComparison{Field: "custom_hook_metadata", Actual: msg.CustomHookMetadata, Error: err.Error()})
+		} else {
+			fields = append(fields, compare("custom_hook_metadata", expected, msg.CustomHookMetadata))
+		}
+	}
+	return fields
+}
+
+// expectedTransfer returns the amount, token ID and recipient a route's transfer must carry, with
+// the token ID and recipient as 0x-prefixed 32-byte hex
+func expectedTransfer(route *types.HyperlaneRoute) (amount, tokenID, recipient string) {
+	// Expected amount, honouring an amount set in the route metadata
+	amount = route.Amount
+	if route.RouteInfo.Amount != "" {
+		amount = route.RouteInfo.Amount
+	}
+
+	// Values that do not normalize are kept as written, so they show up as mismatches
+	tokenID, err := types.NormalizeTokenID(route.RouteInfo.TokenID)
+	if err != nil {
+		tokenID = strings.ToLower(route.RouteInfo.TokenID)
+	}
+	recipient, err = types.NormalizeRecipient(route.RouteInfo.Recipient)
+	if err != nil {
+		recipient = strings.ToLower(route.RouteInfo.Recipient)
+	}
+	return amount, tokenID, recipient
+}
+
+func compare(field, expected, actual string) FieldComparison {
+	return FieldComparison{Field: field, Expected: expected, Actual: actual, Match: expected == actual}
+}
+
+// WriteCSV writes routes, the routes result was verified against, to w as CSV with the outcome of
+// each as its status: "matched" if every transfer it expects matched a message, "unmatched" if one
+// did not, or "unverified" if verification stopped before reaching it
+func WriteCSV(w io.Writer, routes *types.Routes, result *VerifyResult) error {
+	statuses := make([]string, len(routes.Routes))
+	for _, r := range result.Routes {
+		if r.Index < 0 || r.Index >= len(statuses) || statuses[r.Index] == "unmatched" {
+			continue
+		}
+		if r.Matched {
+			statuses[r.Index] = "matched"
+		} else {
+			statuses[r.Index] = "unmatched"
+		}
+	}
+	return types.WriteRoutesCSV(w, routes.Routes, func(i int) string {
+		if statuses[i] == "" {
+			return "unverified"
+		}
+		return statuses[i]
+	})
+}
+
 // PrintResult prints the verification result in a human-readable format
 func (v *Verifier) PrintResult(result *VerifyResult) {
 	if result.Valid {
-		fmt.Println("✓ Transaction verification PASSED")
-		fmt.Printf("  Matched %d/%d routes\n", result.MatchedCount, result.TotalRoutes)
+		fmt.Fprintln(v.out, "✓ Transaction verification PASSED")
+		fmt.Fprintf(v.out, "  Matched %d/%d routes\n", result.MatchedCount, result.TotalRoutes)
 	} else {
-		fmt.Println("✗ Transaction verification FAILED")
-		fmt.Printf("  Matched %d/%d routes\n", result.MatchedCount, result.TotalRoutes)
+		fmt.Fprintln(v.out, "✗ Transaction verification FAILED")
+		fmt.Fprintf(v.out, "  Matched %d/%d routes\n", result.MatchedCount, result.TotalRoutes)
 	}
 
 	if len(result.Errors) > 0 {
-		fmt.Println("\nErrors:")
+		fmt.Fprintln(v.out, "\nErrors:")
 		for _, err := range result.Errors {
-			fmt.Printf("  - %s\n", err)
+			fmt.Fprintf(v.out, "  - %s\n", err)
+		}
+	}
+
+	// Show which fields diverged for unmatched routes
+	for _, r := range result.Routes {
+		if r.Matched || r.MessageIndex < 0 {
+			continue
+		}
+		fmt.Fprintf(v.out, "\nRoute %d (tx: %s) vs closest message %d:\n", r.Index, r.TxHash, r.MessageIndex)
+		for _, f := range r.Fields {
+			marker := "✓"
+			if !f.Match {
+				marker = "✗"
+			}
+			if f.Error != "" {
+				fmt.Fprintf(v.out, "  %s %s: %s, got %s\n", marker, f.Field, f.Error, f.Actual)
+				continue
+			}
+			fmt.Fprintf(v.out, "  %s %s: expected %s, got %s\n", marker, f.Field, f.Expected, f.Actual)
+		}
+	}
+
+	// List the deposits behind aggregated routes, so signers can trace every transfer to its
+	// deposits; the parts of a split route are listed once
+	listed := make(map[string]bool)
+	for _, r := range result.Routes {
+		if len(r.Provenance) == 0 || listed[r.TxHash] {
+			continue
+		}
+		listed[r.TxHash] = true
+		fmt.Fprintf(v.out, "\nRoute %d aggregates %d deposits:\n", r.Index, len(r.Provenance))
+		for _, source := range r.Provenance {
+			fmt.Fprintf(v.out, "  - tx %s at height %d from %s: %s", source.TxHash, source.BlockHeight, source.From, source.Amount)
+			if kept := source.RebalancingFee; kept != nil {
+				fmt.Fprintf(v.out, " (%s deposited, fee %s)", kept.Deposited, kept.Fee)
+			}
+			fmt.Fprintln(v.out)
+		}
+	}
+
+	// Show the messages carrying each route split into several transfers
+	for _, r := range result.Routes {
+		if len(r.SplitMessages) == 0 {
+			continue
+		}
+		for _, f := range r.Fields {
+			if f.Field == "amount" {
+				fmt.Fprintf(v.out, "\nRoute %d (tx: %s) is split into messages %v: %s = %s\n",
+					r.Index, r.TxHash, r.SplitMessages, f.Actual, f.Expected)
+			}
 		}
 	}
 
 	if len(result.Warnings) > 0 {
-		fmt.Println("\nWarnings:")
+		fmt.Fprintln(v.out, "\nWarnings:")
 		for _, warn := range result.Warnings {
-			fmt.Printf("  - %s\n", warn)
+			fmt.Fprintf(v.out, "  - %s\n", warn)
 		}
 	}
 }
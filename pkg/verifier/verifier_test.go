@@ -1,9 +1,18 @@
 package verifier
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/celestiaorg/celestia-rebalancer/pkg/generator"
 	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/gogoproto/proto"
 )
 
 func TestNewVerifier(t *testing.T) {
@@ -162,6 +171,912 @@ func TestVerifyResult(t *testing.T) {
 	}
 }
 
+func TestVerifyHighlightsOverrides(t *testing.T) {
+	routes := &types.Routes{
+		Routes: []types.HyperlaneRoute{
+			{
+				TxHash: "ABC123",
+				Amount: "800000",
+				Denom:  "utia",
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: 1380012617,
+					Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+					TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				},
+				Override: &types.AppliedOverride{OriginalAmount: "1000000", Justification: "refund relayer fee"},
+			},
+		},
+		MultisigAddr: "celestia1multisig",
+	}
+
+	gen := generator.NewGenerator(routes.MultisigAddr)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	result, err := NewVerifier().Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("Verify() errors = %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "amount 1000000 -> 800000") {
+		t.Errorf("Warnings = %v, want the override highlighted", result.Warnings)
+	}
+}
+
+func TestVerifyRouteReport(t *testing.T) {
+	route := types.HyperlaneRoute{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}
+	tampered := route
+	tampered.Amount = "2000000"
+
+	// The transaction carries one correct and one tampered transfer, but the routes expect the
+	// correct transfer twice
+	gen := generator.NewGenerator("celestia1multisig")
+	msgs, err := gen.Generate(&types.Routes{Routes: []types.HyperlaneRoute{route, tampered}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route, route}}
+	result, err := NewVerifier().Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if result.Valid || result.MatchedCount != 1 || len(result.Routes) != 2 {
+		t.Fatalf("result = %+v, want one of two routes matched", result)
+	}
+	if !result.Routes[0].Matched || result.Routes[0].MessageIndex != 0 {
+		t.Errorf("route 0 = %+v, want matched to message 0", result.Routes[0])
+	}
+
+	// The second route cannot reuse message 0 and is reported against the tampered message
+	second := result.Routes[1]
+	if second.Matched || second.MessageIndex != 1 {
+		t.Fatalf("route 1 = %+v, want unmatched with candidate message 1", second)
+	}
+	for _, f := range second.Fields {
+		if wantMatch := f.Field != "amount"; f.Match != wantMatch {
+			t.Errorf("field %s match = %v, want %v", f.Field, f.Match, wantMatch)
+		}
+		if f.Field == "amount" && (f.Expected != "1000000" || f.Actual != "2000000") {
+			t.Errorf("amount comparison = %+v, want 1000000 vs 2000000", f)
+		}
+	}
+}
+
+func TestVerifyForwardedMetadata(t *testing.T) {
+	routes := &types.Routes{
+		Routes: []types.HyperlaneRoute{
+			{
+				TxHash:             "ABC123",
+				BlockHeight:        2500042,
+				Amount:             "1000000",
+				Denom:              "utia",
+				CustomHookMetadata: `{"destination_domain":1380012617,"recipient":"0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"}`,
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: 1380012617,
+					Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+					TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				},
+			},
+		},
+		MultisigAddr: "celestia1multisig",
+	}
+
+	body := func(metadata types.MetadataConfig) []byte {
+		config := types.DefaultConfig()
+		config.Metadata = metadata
+		gen, err := generator.NewGeneratorWithConfig(routes.MultisigAddr, config)
+		if err != nil {
+			t.Fatalf("NewGeneratorWithConfig() error = %v", err)
+		}
+		msgs, err := gen.Generate(routes)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+		if err != nil {
+			t.Fatalf("BuildUnsignedTx() error = %v", err)
+		}
+		bodyBytes, err := unsigned.Body.Marshal()
+		if err != nil {
+			t.Fatalf("failed to marshal body: %v", err)
+		}
+		return bodyBytes
+	}
+
+	for _, mode := range []string{types.ForwardMetadataOriginal, types.ForwardMetadataReceipt} {
+		policy := types.MetadataConfig{Forward: mode}
+		v := NewVerifier()
+		v.SetMetadataPolicy(policy)
+
+		result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: body(policy)})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if !result.Valid {
+			t.Errorf("%s: Verify() errors = %v", mode, result.Errors)
+		}
+
+		// A transfer without the forwarded metadata does not satisfy the policy
+		result, err = v.Verify(routes, &tx.TxRaw{BodyBytes: body(types.MetadataConfig{})})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if result.Valid {
+			t.Errorf("%s: Verify() accepted a transfer without forwarded metadata", mode)
+		}
+		fields := result.Routes[0].Fields
+		if last := fields[len(fields)-1]; last.Field != "custom_hook_metadata" || last.Match {
+			t.Errorf("%s: metadata comparison = %+v, want a mismatch", mode, last)
+		}
+	}
+}
+
+func TestVerifyMemoTemplates(t *testing.T) {
+	routes := &types.Routes{
+		Routes: []types.HyperlaneRoute{
+			{
+				TxHash: "ABC123",
+				Amount: "1000000",
+				Denom:  "utia",
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: 1380012617,
+					Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+					TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				},
+			},
+		},
+		MultisigAddr: "celestia1multisig",
+	}
+	config := types.DefaultConfig()
+	config.Destinations = map[uint32]types.DestinationConfig{
+		1380012617: {MemoTemplate: "rebalance {{.Routes}} {{.Amount}} {{.Digest}}"},
+	}
+	gen, err := generator.NewGeneratorWithConfig(routes.MultisigAddr, config)
+	if err != nil {
+		t.Fatalf("NewGeneratorWithConfig() error = %v", err)
+	}
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	if !strings.HasPrefix(unsigned.Body.Memo, "rebalance 1 1000000 ") {
+		t.Fatalf("memo = %q, want the rendered template", unsigned.Body.Memo)
+	}
+
+	v := NewVerifier()
+	v.SetMemoTemplates(config.Destinations)
+	for _, tt := range []struct {
+		memo  string
+		valid bool
+	}{
+		{unsigned.Body.Memo, true},
+		{"rebalance 1 1000000 forged", false},
+		{"", false},
+	} {
+		unsigned.Body.Memo = tt.memo
+		bodyBytes, err := unsigned.Body.Marshal()
+		if err != nil {
+			t.Fatalf("failed to marshal body: %v", err)
+		}
+		result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if result.Valid != tt.valid {
+			t.Errorf("Verify() with memo %q valid = %v, want %v (errors: %v)", tt.memo, result.Valid, tt.valid, result.Errors)
+		}
+	}
+}
+
+func TestVerifyAmountCaps(t *testing.T) {
+	routes := &types.Routes{MultisigAddr: "celestia1multisig"}
+	for _, hash := range []string{"ABC123", "DEF456"} {
+		routes.Routes = append(routes.Routes, types.HyperlaneRoute{
+			TxHash: hash,
+			Amount: "1000000",
+			Denom:  "utia",
+			RouteInfo: &types.RouteInfo{
+				DestinationDomain: 1380012617,
+				Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+				TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			},
+		})
+	}
+	gen := generator.NewGenerator(routes.MultisigAddr)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	for _, tt := range []struct {
+		caps  types.AmountCaps
+		valid bool
+	}{
+		{types.AmountCaps{MaxAmountPerRoute: "1000000", MaxTotalAmountPerRun: "2000000"}, true},
+		{types.AmountCaps{MaxAmountPerRoute: "999999"}, false},
+		{types.AmountCaps{MaxTotalAmountPerRun: "1500000"}, false},
+	} {
+		v := NewVerifier()
+		v.SetWhitelist(types.AddressWhitelist{Caps: map[uint32]types.AmountCaps{1380012617: tt.caps}})
+		result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if result.Valid != tt.valid {
+			t.Errorf("Verify() with caps %+v valid = %v, want %v (errors: %v)", tt.caps, result.Valid, tt.valid, result.Errors)
+		}
+	}
+}
+
+func TestVerifyRouteAges(t *testing.T) {
+	routes := &types.Routes{MultisigAddr: "celestia1multisig"}
+	for i, height := range []int64{1000, 9500, 0} {
+		routes.Routes = append(routes.Routes, types.HyperlaneRoute{
+			TxHash:      fmt.Sprintf("TX%d", i),
+			BlockHeight: height,
+			Amount:      "1000000",
+			Denom:       "utia",
+			RouteInfo: &types.RouteInfo{
+				DestinationDomain: 1380012617,
+				Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+				TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			},
+		})
+	}
+	gen := generator.NewGenerator(routes.MultisigAddr)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name             string
+		height           int64
+		warnAge, maxAge  int64
+		valid            bool
+		errors, warnings int
+	}{
+		{"no height", 0, 100, 5000, true, 0, 0},
+		{"fresh", 9600, 9000, 10000, true, 0, 0},
+		{"warn", 10000, 600, 0, true, 0, 1},
+		{"stale", 10000, 100, 5000, false, 1, 1},
+		{"height behind deposits", 5000, 100, 0, true, 0, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			v.SetRouteAges(tt.warnAge, tt.maxAge)
+			v.SetChainHeight(tt.height)
+			result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if result.Valid != tt.valid || len(result.Errors) != tt.errors || len(result.Warnings) != tt.warnings {
+				t.Errorf("Verify() valid = %v, errors = %v, warnings = %v", result.Valid, result.Errors, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestVerifyRouteAgesAggregated(t *testing.T) {
+	// A stale deposit merged with a fresh one: the route carries the fresh deposit's height
+	routes := &types.Routes{MultisigAddr: "celestia1multisig", Routes: []types.HyperlaneRoute{{
+		TxHash:      "OLD123,NEW456",
+		BlockHeight: 9900,
+		Amount:      "1500000",
+		Denom:       "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+		Provenance: []types.RouteSource{
+			{TxHash: "OLD123", BlockHeight: 1000, Amount: "1000000"},
+			{TxHash: "NEW456", BlockHeight: 9900, Amount: "500000"},
+		},
+	}}}
+	gen := generator.NewGenerator(routes.MultisigAddr)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	v := NewVerifier()
+	v.SetRouteAges(0, 5000)
+	v.SetChainHeight(10000)
+	result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Valid || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "deposit OLD123 was made 9000 blocks ago") {
+		t.Errorf("Verify() valid = %v, errors = %v, want the stale deposit OLD123 refused", result.Valid, result.Errors)
+	}
+}
+
+func TestVerifySplits(t *testing.T) {
+	route := types.HyperlaneRoute{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			Splits: []types.RouteSplit{
+				{Recipient: "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0", Amount: "100000"},
+				{Recipient: "0x1234567890123456789012345678901234567890", Percent: "60"},
+				{Recipient: "0x0987654321098765432109876543210987654321", Percent: "40"},
+			},
+		},
+	}
+	routes := &types.Routes{MultisigAddr: "celestia1multisig", Routes: []types.HyperlaneRoute{route}}
+
+	gen := generator.NewGenerator(routes.MultisigAddr)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(msgs) != 3 {
+		t.Fatalf("Generate() returned %d messages, want one per split", len(msgs))
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	result, err := NewVerifier().Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Valid || result.MatchedCount != 3 || result.TotalRoutes != 3 {
+		t.Errorf("Verify() valid = %v, matched %d/%d, want all 3 splits matched (errors: %v)", result.Valid, result.MatchedCount, result.TotalRoutes, result.Errors)
+	}
+	for _, r := range result.Routes {
+		if r.Index != 0 {
+			t.Errorf("split reported at routes file index %d, want 0", r.Index)
+		}
+	}
+
+	// Splits that do not add up to the deposit fail verification
+	short := route
+	info := *route.RouteInfo
+	info.Splits = []types.RouteSplit{info.Splits[0], {Recipient: info.Splits[1].Recipient, Amount: "800000"}}
+	short.RouteInfo = &info
+	result, err = NewVerifier().Verify(&types.Routes{MultisigAddr: routes.MultisigAddr, Routes: []types.HyperlaneRoute{short}}, &tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Valid {
+		t.Error("Verify() passed splits leaving part of the deposit unassigned")
+	}
+}
+
+func TestVerifyMessageCaps(t *testing.T) {
+	route := types.HyperlaneRoute{
+		TxHash: "ABC123",
+		Amount: "250",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}
+	routes := &types.Routes{MultisigAddr: "celestia1multisig", Routes: []types.HyperlaneRoute{route}}
+
+	// The route split under a cap of 100 into three transfers
+	txBody := func(amounts ...string) []byte {
+		t.Helper()
+		parts := &types.Routes{MultisigAddr: routes.MultisigAddr}
+		for _, amount := range amounts {
+			part := route
+			part.Amount = amount
+			parts.Routes = append(parts.Routes, part)
+		}
+		gen := generator.NewGenerator(routes.MultisigAddr)
+		msgs, err := gen.Generate(parts)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+		if err != nil {
+			t.Fatalf("BuildUnsignedTx() error = %v", err)
+		}
+		bodyBytes, err := unsigned.Body.Marshal()
+		if err != nil {
+			t.Fatalf("failed to marshal body: %v", err)
+		}
+		return bodyBytes
+	}
+	destinations := map[uint32]types.DestinationConfig{1380012617: {MaxAmountPerMessage: "100"}}
+
+	v := NewVerifier()
+	v.SetMessageCaps(destinations)
+	result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: txBody("100", "100", "50")})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Valid || result.MatchedCount != 1 {
+		t.Fatalf("Verify() valid = %v, matched %d, want the split route matched (errors: %v)", result.Valid, result.MatchedCount, result.Errors)
+	}
+	if got := fmt.Sprint(result.Routes[0].SplitMessages); got != "[0 1 2]" {
+		t.Errorf("SplitMessages = %s, want [0 1 2]", got)
+	}
+
+	// Parts short of the route amount, or above the cap, fail verification
+	for name, amounts := range map[string][]string{"short": {"100", "100"}, "above cap": {"150", "100"}} {
+		result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: txBody(amounts...)})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if result.Valid {
+			t.Errorf("Verify() passed %s parts %v", name, amounts)
+		}
+	}
+}
+
+func TestVerifyRebalancingFee(t *testing.T) {
+	routes := &types.Routes{MultisigAddr: "celestia1multisig", Routes: []types.HyperlaneRoute{{
+		TxHash: "ABC123",
+		Amount: "1000000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+	}}}
+	fee := types.RebalancingFeeConfig{BPS: 25, Flat: "100"}
+
+	// generate keeps the fee from the deposit and forwards 997400, in one transfer or split in two
+	planned := &types.Routes{MultisigAddr: routes.MultisigAddr, Routes: append([]types.HyperlaneRoute(nil), routes.Routes...)}
+	if err := fee.DeductFrom(&planned.Routes[0]); err != nil {
+		t.Fatalf("DeductFrom() error = %v", err)
+	}
+	txBody := func(amounts ...string) []byte {
+		t.Helper()
+		parts := &types.Routes{MultisigAddr: routes.MultisigAddr}
+		for _, amount := range amounts {
+			part := planned.Routes[0]
+			part.Amount = amount
+			parts.Routes = append(parts.Routes, part)
+		}
+		gen := generator.NewGenerator(routes.MultisigAddr)
+		msgs, err := gen.Generate(parts)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+		if err != nil {
+			t.Fatalf("BuildUnsignedTx() error = %v", err)
+		}
+		bodyBytes, err := unsigned.Body.Marshal()
+		if err != nil {
+			t.Fatalf("failed to marshal body: %v", err)
+		}
+		return bodyBytes
+	}
+	whole := txBody("997400")
+	split := txBody("500000", "497400")
+
+	// Only the exact deduction of the policy passes: not the full amount, nor another fee. Split
+	// transfers are matched against the deposit less its one fee.
+	for _, tt := range []struct {
+		name   string
+		routes *types.Routes
+		body   []byte
+		fee    types.RebalancingFeeConfig
+		valid  bool
+	}{
+		{"routes as parsed", routes, whole, fee, true},
+		{"routes as parsed without a fee", routes, whole, types.RebalancingFeeConfig{}, false},
+		{"routes as parsed with another fee", routes, whole, types.RebalancingFeeConfig{BPS: 25}, false},
+		{"split transfers", routes, split, fee, true},
+		{"planned routes", planned, whole, fee, true},
+		{"planned routes with another fee", planned, whole, types.RebalancingFeeConfig{BPS: 25}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			v.SetRebalancingFee(tt.fee)
+			result, err := v.Verify(tt.routes, &tx.TxRaw{BodyBytes: tt.body})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if result.Valid != tt.valid {
+				t.Errorf("Verify() valid = %v, want %v (errors: %v)", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	// A deposit not covering the fee fails verification with the reason, not as an expected amount
+	small := &types.Routes{MultisigAddr: routes.MultisigAddr, Routes: append([]types.HyperlaneRoute(nil), routes.Routes...)}
+	small.Routes[0].Amount = "100"
+	v := NewVerifier()
+	v.SetRebalancingFee(fee)
+	result, err := v.Verify(small, &tx.TxRaw{BodyBytes: whole})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if result.Valid || !strings.Contains(strings.Join(result.Errors, "\n"), "does not cover the rebalancing fee") {
+		t.Errorf("Verify() errors = %v, want the deposit not covering the fee reported", result.Errors)
+	}
+	for _, f := range result.Routes[0].Fields {
+		if f.Field == "amount" && f.Expected != "100" {
+			t.Errorf("expected amount = %q, want the route amount", f.Expected)
+		}
+	}
+}
+
+func TestVerifyProvenance(t *testing.T) {
+	aggregated := types.HyperlaneRoute{
+		TxHash: "ABC123,DEF456",
+		Amount: "1500000",
+		Denom:  "utia",
+		RouteInfo: &types.RouteInfo{
+			DestinationDomain: 1380012617,
+			Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		},
+		Provenance: []types.RouteSource{
+			{TxHash: "ABC123", BlockHeight: 100, Amount: "1000000"},
+			{TxHash: "DEF456", BlockHeight: 101, Amount: "500000"},
+		},
+	}
+	// The limits split the aggregated route in two transfers
+	first, second := aggregated, aggregated
+	first.Amount, second.Amount = "1000000", "500000"
+	routes := &types.Routes{MultisigAddr: "celestia1multisig", Routes: []types.HyperlaneRoute{first, second}}
+
+	gen := generator.NewGenerator(routes.MultisigAddr)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	result, err := NewVerifier().Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.Valid || len(result.Routes[0].Provenance) != 2 {
+		t.Errorf("Verify() valid = %v, provenance %+v, want both deposits (errors: %v)", result.Valid, result.Routes[0].Provenance, result.Errors)
+	}
+
+	// Provenance that does not add up to the transferred amount, or names other deposits, fails
+	short := first
+	short.Provenance = []types.RouteSource{aggregated.Provenance[0], {TxHash: "DEF456", Amount: "400000"}}
+	other := first
+	other.Provenance = []types.RouteSource{aggregated.Provenance[0], {TxHash: "GHI789", Amount: "500000"}}
+	for name, tampered := range map[string]types.HyperlaneRoute{"short": short, "other": other} {
+		tamperedSecond := second
+		tamperedSecond.Provenance = tampered.Provenance
+		tamperedRoutes := &types.Routes{MultisigAddr: routes.MultisigAddr, Routes: []types.HyperlaneRoute{tampered, tamperedSecond}}
+		result, err := NewVerifier().Verify(tamperedRoutes, &tx.TxRaw{BodyBytes: bodyBytes})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if result.Valid {
+			t.Errorf("Verify() passed %s provenance", name)
+		}
+	}
+}
+
+func TestVerifyTokenWhitelist(t *testing.T) {
+	const tokenID = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	routes := &types.Routes{
+		Routes: []types.HyperlaneRoute{
+			{
+				TxHash: "ABC123",
+				Amount: "1000000",
+				Denom:  "utia",
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: 1380012617,
+					Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+					TokenID:           tokenID,
+				},
+			},
+		},
+		MultisigAddr: "celestia1multisig",
+	}
+	gen := generator.NewGenerator(routes.MultisigAddr)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	unsigned, err := gen.BuildUnsignedTx(msgs, generator.TxOptions{})
+	if err != nil {
+		t.Fatalf("BuildUnsignedTx() error = %v", err)
+	}
+	bodyBytes, err := unsigned.Body.Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+
+	for _, tt := range []struct {
+		allowed []string
+		valid   bool
+	}{
+		{nil, true},
+		{[]string{strings.ToUpper(tokenID[2:])}, true},
+		{[]string{"0x726f757465725f61707000000000000000000000000000010000000000000000"}, false},
+	} {
+		v := NewVerifier()
+		v.SetWhitelist(types.AddressWhitelist{TokenIDs: map[uint32][]string{1380012617: tt.allowed}})
+		result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if result.Valid != tt.valid {
+			t.Errorf("Verify() with token IDs %v valid = %v, want %v (errors: %v)", tt.allowed, result.Valid, tt.valid, result.Errors)
+		}
+	}
+}
+
+func TestVerifyStrict(t *testing.T) {
+	const multisig = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+	route := func(hash, amount string) types.HyperlaneRoute {
+		return types.HyperlaneRoute{
+			TxHash: hash,
+			Amount: amount,
+			Denom:  "utia",
+			RouteInfo: &types.RouteInfo{
+				DestinationDomain: 1380012617,
+				Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+				TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			},
+		}
+	}
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{route("ABC123", "1000000")}, MultisigAddr: multisig}
+
+	gen := generator.NewGenerator(multisig)
+	msgs, err := gen.Generate(&types.Routes{Routes: []types.HyperlaneRoute{route("ABC123", "1000000"), route("DEF456", "5")}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	pack := func(msg proto.Message) *codectypes.Any {
+		t.Helper()
+		packed, err := codectypes.NewAnyWithValue(msg)
+		if err != nil {
+			t.Fatalf("failed to pack message: %v", err)
+		}
+		return packed
+	}
+	transfer, extra := pack(msgs[0]), pack(msgs[1])
+	drain := pack(&banktypes.MsgSend{FromAddress: multisig, ToAddress: "celestia1yg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zl2r5q4"})
+
+	tests := []struct {
+		name         string
+		messages     []*codectypes.Any
+		wantValid    bool
+		wantPosition string
+	}{
+		{name: "only the routed transfer", messages: []*codectypes.Any{transfer}, wantValid: true},
+		{name: "bank send draining the multisig", messages: []*codectypes.Any{transfer, drain}, wantPosition: "1"},
+		{name: "unmatched transfer", messages: []*codectypes.Any{extra, transfer}, wantPosition: "0"},
+		{name: "unmatched transfer through authz", messages: []*codectypes.Any{transfer, pack(&authz.MsgExec{Msgs: []*codectypes.Any{extra}})}, wantPosition: "1/0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, err := (&tx.TxBody{Messages: tt.messages}).Marshal()
+			if err != nil {
+				t.Fatalf("failed to marshal body: %v", err)
+			}
+			v := NewVerifier()
+			v.SetStrict(true)
+			result, err := v.Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if result.Valid != tt.wantValid {
+				t.Errorf("Verify() valid = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+			if tt.wantPosition == "" {
+				if len(result.UnexpectedMessages) != 0 {
+					t.Errorf("unexpected messages = %+v, want none", result.UnexpectedMessages)
+				}
+				return
+			}
+			found := false
+			for _, m := range result.UnexpectedMessages {
+				found = found || m.Position == tt.wantPosition
+			}
+			if !found {
+				t.Errorf("unexpected messages = %+v, want one at position %s", result.UnexpectedMessages, tt.wantPosition)
+			}
+		})
+	}
+
+	// Without strict mode, the bank send goes unnoticed as long as the transfers match
+	bodyBytes, err := (&tx.TxBody{Messages: []*codectypes.Any{transfer, drain}}).Marshal()
+	if err != nil {
+		t.Fatalf("failed to marshal body: %v", err)
+	}
+	result, err := NewVerifier().Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+	if err != nil || !result.Valid || len(result.UnexpectedMessages) != 0 {
+		t.Errorf("non-strict Verify() = %+v, %v, want a valid result", result, err)
+	}
+}
+
+func TestVerifyAuthzExec(t *testing.T) {
+	const (
+		multisig = "celestia1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3shxjgz"
+		grantee  = "celestia1yg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3zl2r5q4"
+	)
+	routes := &types.Routes{
+		Routes: []types.HyperlaneRoute{
+			{
+				TxHash: "ABC123",
+				Amount: "1000000",
+				Denom:  "utia",
+				RouteInfo: &types.RouteInfo{
+					DestinationDomain: 1380012617,
+					Recipient:         "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+					TokenID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				},
+			},
+		},
+		MultisigAddr: multisig,
+	}
+
+	gen := generator.NewGenerator(multisig)
+	msgs, err := gen.Generate(routes)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	transferAny, err := codectypes.NewAnyWithValue(msgs[0])
+	if err != nil {
+		t.Fatalf("failed to pack transfer: %v", err)
+	}
+	send := &banktypes.MsgSend{FromAddress: multisig, ToAddress: grantee}
+	sendAny, err := codectypes.NewAnyWithValue(send)
+	if err != nil {
+		t.Fatalf("failed to pack send: %v", err)
+	}
+
+	future := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name         string
+		exec         *authz.MsgExec
+		grant        types.AuthzConfig
+		wantValid    bool
+		wantErr      string
+		wantWarnings int
+	}{
+		{
+			name:      "within grant",
+			exec:      &authz.MsgExec{Grantee: grantee, Msgs: []*codectypes.Any{transferAny}},
+			grant:     types.AuthzConfig{Grantee: grantee, Expiration: future},
+			wantValid: true,
+		},
+		{
+			name:         "grant not configured",
+			exec:         &authz.MsgExec{Grantee: grantee, Msgs: []*codectypes.Any{transferAny}},
+			wantValid:    true,
+			wantWarnings: 1,
+		},
+		{
+			name:    "unexpected grantee",
+			exec:    &authz.MsgExec{Grantee: multisig, Msgs: []*codectypes.Any{transferAny}},
+			grant:   types.AuthzConfig{Grantee: grantee},
+			wantErr: "does not match the configured grantee",
+		},
+		{
+			name:    "expired grant",
+			exec:    &authz.MsgExec{Grantee: grantee, Msgs: []*codectypes.Any{transferAny}},
+			grant:   types.AuthzConfig{Grantee: grantee, Expiration: past},
+			wantErr: "expired",
+		},
+		{
+			name:    "message outside scope",
+			exec:    &authz.MsgExec{Grantee: grantee, Msgs: []*codectypes.Any{transferAny, sendAny}},
+			grant:   types.AuthzConfig{Grantee: grantee},
+			wantErr: "outside the granted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execAny, err := codectypes.NewAnyWithValue(tt.exec)
+			if err != nil {
+				t.Fatalf("failed to pack MsgExec: %v", err)
+			}
+			body := tx.TxBody{Messages: []*codectypes.Any{execAny}}
+			bodyBytes, err := body.Marshal()
+			if err != nil {
+				t.Fatalf("failed to marshal body: %v", err)
+			}
+
+			result, err := NewVerifierWithGrant(tt.grant).Verify(routes, &tx.TxRaw{BodyBytes: bodyBytes})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if result.Valid != tt.wantValid {
+				t.Errorf("Valid = %v, want %v (errors: %v)", result.Valid, tt.wantValid, result.Errors)
+			}
+			if result.MatchedCount != 1 {
+				t.Errorf("MatchedCount = %d, want the wrapped transfer matched", result.MatchedCount)
+			}
+			if tt.wantErr != "" && !strings.Contains(strings.Join(result.Errors, "\n"), tt.wantErr) {
+				t.Errorf("Errors = %v, want one containing %q", result.Errors, tt.wantErr)
+			}
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("Warnings = %v, want %d", result.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
 func TestPrintResult(t *testing.T) {
 	v := NewVerifier()
 
@@ -189,3 +1104,26 @@ func TestPrintResult(t *testing.T) {
 	// This should not panic
 	v.PrintResult(result)
 }
+
+func TestWriteCSV(t *testing.T) {
+	routes := &types.Routes{Routes: []types.HyperlaneRoute{{TxHash: "A"}, {TxHash: "B"}, {TxHash: "C"}}}
+	result := &VerifyResult{Routes: []RouteResult{
+		{Index: 0, Matched: true},
+		{Index: 1, Matched: true},
+		{Index: 1, Matched: false}, // Second recipient of a fan-out route
+	}}
+
+	var buf strings.Builder
+	if err := WriteCSV(&buf, routes, result); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("WriteCSV() wrote %d lines, want a header and 3 routes:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{"matched", "unmatched", "unverified"} {
+		if !strings.HasSuffix(lines[i+1], ","+want) {
+			t.Errorf("route %d = %q, want status %s", i, lines[i+1], want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Defaults for BackfillConfig and PlanShards
+const (
+	DefaultShardBlocks   = 10000
+	DefaultLease         = 2 * time.Minute
+	DefaultRetryInterval = 10 * time.Second
+)
+
+// ShardStore is a store that can coordinate backfill workers
+type ShardStore interface {
+	storage.Storage
+	storage.ShardStorage
+}
+
+// PlanShards splits the heights from to to of a backfill job into shards of size heights and adds
+// them to store. Planning a job again keeps the progress of its existing shards.
+func PlanShards(ctx context.Context, store storage.ShardStorage, job string, from, to, size int64) ([]storage.Shard, error) {
+	if job == "" {
+		return nil, fmt.Errorf("backfill job is not set")
+	}
+	if from <= 0 || to < from {
+		return nil, fmt.Errorf("invalid height range %d to %d", from, to)
+	}
+	if size <= 0 {
+		size = DefaultShardBlocks
+	}
+
+	var shards []storage.Shard
+	for start := from; start <= to; start += size {
+		shards = append(shards, storage.Shard{
+			Job:        job,
+			FromHeight: start,
+			ToHeight:   min(to, start+size-1),
+			Progress:   start - 1,
+		})
+	}
+	if err := store.AddShards(ctx, shards); err != nil {
+		return nil, err
+	}
+	return shards, nil
+}
+
+// BackfillConfig controls a Backfill worker
+type BackfillConfig struct {
+	Job          string
+	Worker       string // Unique name of the worker, e.g. host and process ID
+	MultisigAddr string
+	// Source tags the routes found like Config.Source of the watcher they are handed over to
+	Source string
+	// Lease is how long a claim lasts without progress before other workers may take the shard over
+	Lease time.Duration
+	// MaxBlocks caps the heights parsed per step; progress is saved and the lease extended after each
+	MaxBlocks int64
+	// RetryInterval is how long to wait when every unfinished shard is claimed or a shard failed
+	RetryInterval time.Duration
+}
+
+// Step describes the heights of a shard parsed in one step
+type Step struct {
+	Shard      storage.Shard // The shard after the step
+	FromHeight int64
+	ToHeight   int64
+	// Routes are the deposits found in the step that were not processed before
+	Routes []types.HyperlaneRoute
+	// Replays are new deposits reusing a nonce their sender already used, recorded like Routes
+	Replays       []Replay
+	Skipped       []types.Skipped
+	FailedHeights []types.FailedHeight
+}
+
+// Backfill parses a height range split into shards, in parallel with other workers sharing the
+// store. Each worker claims the lowest unfinished shard, parses it in steps that save its progress
+// and extend its claim, and moves on to the next; shards of workers that stopped are taken over
+// once their lease expires. Routes are recorded as by a Watcher, so no deposit is routed twice.
+type Backfill struct {
+	scanner Scanner
+	store   ShardStore
+	config  BackfillConfig
+
+	// OnStep is called after every step
+	OnStep func(Step)
+	// OnError is called with errors of shards that are handed back to be retried later
+	OnError func(error)
+}
+
+// NewBackfill creates a backfill worker scanning with scanner and coordinating through store
+func NewBackfill(scanner Scanner, store ShardStore, config BackfillConfig) (*Backfill, error) {
+	if config.Job == "" {
+		return nil, fmt.Errorf("backfill job is not set")
+	}
+	if config.Worker == "" {
+		return nil, fmt.Errorf("backfill worker name is not set")
+	}
+	if config.MultisigAddr == "" {
+		return nil, fmt.Errorf("backfill multisig address is not set")
+	}
+	if config.Lease <= 0 {
+		config.Lease = DefaultLease
+	}
+	if config.MaxBlocks <= 0 {
+		config.MaxBlocks = DefaultMaxBlocks
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = DefaultRetryInterval
+	}
+	return &Backfill{scanner: scanner, store: store, config: config}, nil
+}
+
+// Run claims and parses shards until every shard of the job is done or ctx is cancelled. While
+// the remaining shards are claimed by other workers, it waits to take over any that are abandoned.
+func (b *Backfill) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		shard, err := b.store.ClaimShard(ctx, b.config.Job, b.config.Worker, time.Now().Add(b.config.Lease))
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			shards, err := b.store.Shards(ctx, b.config.Job)
+			if err != nil {
+				b.report(err)
+			} else if len(shards) == 0 {
+				return fmt.Errorf("backfill job %s has no shards", b.config.Job)
+			} else if allDone(shards) {
+				return nil
+			}
+		case err != nil:
+			b.report(fmt.Errorf("failed to claim a shard: %w", err))
+		default:
+			err := b.work(ctx, shard)
+			if err == nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			b.report(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(b.config.RetryInterval):
+		}
+	}
+}
+
+// work parses the rest of a claimed shard. A shard with a height that cannot be queried is
+// handed back with its progress up to that height, to be retried later.
+func (b *Backfill) work(ctx context.Context, shard *storage.Shard) error {
+	for !shard.Done() {
+		if ctx.Err() != nil {
+			return b.release(shard, ctx.Err())
+		}
+
+		from := shard.Progress + 1
+		to := min(shard.ToHeight, from+b.config.MaxBlocks-1)
+		result, err := b.scanner.ParseRoutes(b.config.MultisigAddr, from, to)
+		if err != nil {
+			return b.release(shard, fmt.Errorf("failed to parse heights %d to %d: %w", from, to, err))
+		}
+
+		done := to
+		for _, f := range result.FailedHeights {
+			done = min(done, f.Height-1)
+		}
+		routes, replays, err := recordRoutes(ctx, b.store, b.config.Source, result.Routes.Routes, done)
+		if err != nil {
+			return b.release(shard, err)
+		}
+		shard.Progress = max(shard.Progress, done)
+
+		if b.OnStep != nil {
+			b.OnStep(Step{
+				Shard:         *shard,
+				FromHeight:    from,
+				ToHeight:      to,
+				Routes:        routes,
+				Replays:       replays,
+				Skipped:       result.Skipped,
+				FailedHeights: result.FailedHeights,
+			})
+		}
+
+		if done < to {
+			return b.release(shard, fmt.Errorf("shard %d-%d: height %d could not be queried", shard.FromHeight, shard.ToHeight, done+1))
+		}
+		shard.LeaseUntil = time.Now().Add(b.config.Lease)
+		if err := b.store.UpdateShard(ctx, *shard); err != nil {
+			return fmt.Errorf("shard %d-%d: %w", shard.FromHeight, shard.ToHeight, err)
+		}
+	}
+	return b.release(shard, nil)
+}
+
+// release saves the progress of shard and gives up its claim, returning cause
+func (b *Backfill) release(shard *storage.Shard, cause error) error {
+	// Release even when ctx was cancelled, so the shard can be taken over right away
+	if err := b.store.ReleaseShard(context.Background(), *shard); err != nil {
+		return errors.Join(cause, fmt.Errorf("failed to release shard %d-%d: %w", shard.FromHeight, shard.ToHeight, err))
+	}
+	return cause
+}
+
+// report passes err to OnError
+func (b *Backfill) report(err error) {
+	if b.OnError != nil {
+		b.OnError(err)
+	}
+}
+
+// allDone reports whether every shard was parsed
+func allDone(shards []storage.Shard) bool {
+	for _, shard := range shards {
+		if !shard.Done() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+)
+
+func TestBackfillWorkers(t *testing.T) {
+	ctx := context.Background()
+	deposits := make(map[int64]string)
+	for height := int64(100); height < 160; height += 3 {
+		deposits[height] = fmt.Sprintf("D%d", height)
+	}
+	scanner := &fakeScanner{latest: 200, deposits: deposits}
+	store := storage.NewMemory()
+
+	shards, err := PlanShards(ctx, store, "history", 100, 159, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shards) != 6 || shards[5].FromHeight != 150 || shards[5].ToHeight != 159 {
+		t.Fatalf("PlanShards() = %+v", shards)
+	}
+
+	// A worker that died holding the first shard: its lease has expired
+	if _, err := store.ClaimShard(ctx, "history", "dead", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		found = make(map[string]int)
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 3; i++ {
+		b, err := NewBackfill(scanner, store, BackfillConfig{Job: "history", Worker: fmt.Sprintf("w%d", i), MultisigAddr: "celestia1multisig", MaxBlocks: 4, RetryInterval: time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.OnStep = func(step Step) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, route := range step.Routes {
+				found[route.TxHash]++
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.Run(ctx); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(found) != len(deposits) {
+		t.Errorf("found %d deposits, want %d", len(found), len(deposits))
+	}
+	for hash, n := range found {
+		if n != 1 {
+			t.Errorf("deposit %s found %d times", hash, n)
+		}
+	}
+	all, _ := store.Shards(ctx, "history")
+	if !allDone(all) {
+		t.Errorf("shards not done: %+v", all)
+	}
+	parsed, _ := store.RoutesByStatus(ctx, storage.RouteParsed)
+	if len(parsed) != len(deposits) {
+		t.Errorf("store holds %d parsed routes, want %d", len(parsed), len(deposits))
+	}
+}
+
+func TestBackfillRetriesFailedHeights(t *testing.T) {
+	scanner := &fakeScanner{latest: 200, deposits: map[int64]string{101: "A", 107: "B"}, failing: map[int64]bool{105: true}}
+	store := storage.NewMemory()
+	if _, err := PlanShards(context.Background(), store, "history", 100, 109, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := NewBackfill(scanner, store, BackfillConfig{Job: "history", Worker: "w1", MultisigAddr: "celestia1multisig", MaxBlocks: 10, RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := b.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() with a failing height error = %v, want the context error", err)
+	}
+
+	shards, _ := store.Shards(context.Background(), "history")
+	if shards[0].Progress != 104 || shards[0].Worker != "" {
+		t.Fatalf("shard = %+v, want progress before the failed height and no claim", shards[0])
+	}
+	if ok, _ := store.IsProcessed(context.Background(), "B"); ok {
+		t.Error("deposit after the failed height was recorded")
+	}
+
+	scanner.failing = nil
+	if err := b.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if ok, _ := store.IsProcessed(context.Background(), "B"); !ok {
+		t.Error("deposit after the retried height was not recorded")
+	}
+}
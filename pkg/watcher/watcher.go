@@ -0,0 +1,260 @@
+// Package watcher continuously parses new blocks for deposits to the multisig, resuming from the
+// last processed height after a restart
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/metrics"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/parser"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// Defaults for Config
+const (
+	DefaultPollInterval = 6 * time.Second // About one Celestia block
+	DefaultMaxBlocks    = 100
+)
+
+// Scanner queries the chain for new blocks and parses them. *parser.Parser implements it.
+type Scanner interface {
+	LatestHeight() (int64, error)
+	ParseRoutes(multisigAddr string, fromHeight, toHeight int64) (*parser.ParseResult, error)
+}
+
+// Config controls a Watcher
+type Config struct {
+	// Source names the checkpoint in the store, e.g. the source chain name
+	Source       string
+	MultisigAddr string
+	// StartHeight is the first height scanned when the store has no checkpoint for Source. Zero
+	// starts at the latest height.
+	StartHeight  int64
+	PollInterval time.Duration
+	// MaxBlocks caps the heights parsed per pass, so catching up after downtime is done in steps
+	// that each persist their progress
+	MaxBlocks int64
+}
+
+// Pass describes one scan of new heights
+type Pass struct {
+	FromHeight int64
+	ToHeight   int64
+	Latest     int64 // Latest height of the chain when the pass started
+	Checkpoint int64 // Height up to which all blocks have been processed after the pass
+	// Routes are the deposits found in the pass that were not processed before
+	Routes []types.HyperlaneRoute
+	// Replays are new deposits reusing a nonce their sender already used, recorded like Routes
+	Replays       []Replay
+	Skipped       []types.Skipped
+	FailedHeights []types.FailedHeight
+}
+
+// Replay is a deposit whose metadata nonce was already used by an earlier deposit of its sender
+type Replay struct {
+	Route types.HyperlaneRoute
+	First storage.Nonce // Claim of the deposit that used the nonce first
+}
+
+// Watcher polls for new blocks and parses the deposits they contain. New routes are saved to the
+// store with status parsed and their transactions marked processed, and the checkpoint is advanced,
+// so a restarted watcher resumes where it left off without routing a deposit twice.
+type Watcher struct {
+	scanner Scanner
+	store   storage.Storage
+	config  Config
+	trigger <-chan int64 // Heights of new blocks announced by the node, nil to only poll
+
+	// OnPass is called after every pass that scanned at least one height
+	OnPass func(Pass)
+	// OnError is called with the error of a failed pass; Run retries after the poll interval
+	OnError func(error)
+}
+
+// New creates a watcher scanning with scanner and persisting its state in store
+func New(scanner Scanner, store storage.Storage, config Config) (*Watcher, error) {
+	if config.Source == "" {
+		return nil, fmt.Errorf("watcher source is not set")
+	}
+	if config.MultisigAddr == "" {
+		return nil, fmt.Errorf("watcher multisig address is not set")
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MaxBlocks <= 0 {
+		config.MaxBlocks = DefaultMaxBlocks
+	}
+	return &Watcher{scanner: scanner, store: store, config: config}, nil
+}
+
+// SetTrigger makes Run start a pass as soon as a new block height arrives on heights, e.g. from a
+// websocket subscription, instead of waiting for the poll interval. Polling continues as before, so
+// blocks the trigger misses are still scanned.
+func (w *Watcher) SetTrigger(heights <-chan int64) {
+	w.trigger = heights
+}
+
+// Run polls until ctx is cancelled. Passes are repeated without waiting while the watcher is
+// catching up. Failed passes are reported to OnError and retried after the poll interval, so a
+// node that is briefly unavailable does not stop the watcher.
+func (w *Watcher) Run(ctx context.Context) {
+	for {
+		pass, err := w.Poll(ctx)
+		if err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+
+		// Keep going while behind and making progress; otherwise wait for the next block
+		if pass == nil || pass.Checkpoint >= pass.Latest || pass.Checkpoint < pass.FromHeight {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.config.PollInterval):
+			case <-w.trigger:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// Poll scans the heights after the checkpoint up to the latest height, at most MaxBlocks of them.
+// It returns nil if there is no new height. Heights that could not be queried are not skipped: the
+// checkpoint stops before the first of them so they are scanned again by the next pass.
+func (w *Watcher) Poll(ctx context.Context) (*Pass, error) {
+	latest, err := w.scanner.LatestHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, err := w.store.Checkpoint(ctx, w.config.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	metrics.ChainHeight.WithLabelValues(w.config.Source).Set(float64(latest))
+	metrics.CheckpointHeight.WithLabelValues(w.config.Source).Set(float64(checkpoint))
+	from := checkpoint + 1
+	if checkpoint == 0 {
+		from = w.config.StartHeight
+		if from <= 0 {
+			from = latest
+		}
+	}
+	if from > latest {
+		return nil, nil
+	}
+	to := min(latest, from+w.config.MaxBlocks-1)
+
+	result, err := w.scanner.ParseRoutes(w.config.MultisigAddr, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse heights %d to %d: %w", from, to, err)
+	}
+
+	done := to
+	for _, f := range result.FailedHeights {
+		done = min(done, f.Height-1)
+	}
+
+	pass := &Pass{
+		FromHeight:    from,
+		ToHeight:      to,
+		Latest:        latest,
+		Checkpoint:    max(done, checkpoint),
+		Skipped:       result.Skipped,
+		FailedHeights: result.FailedHeights,
+	}
+
+	// Routes after a failed height are parsed again by the next pass
+	pass.Routes, pass.Replays, err = recordRoutes(ctx, w.store, w.config.Source, result.Routes.Routes, done)
+	if err != nil {
+		return nil, err
+	}
+
+	if done > checkpoint {
+		if err := w.store.SetCheckpoint(ctx, w.config.Source, done); err != nil {
+			return nil, fmt.Errorf("failed to save checkpoint: %w", err)
+		}
+		metrics.CheckpointHeight.WithLabelValues(w.config.Source).Set(float64(done))
+	}
+
+	if w.OnPass != nil {
+		w.OnPass(*pass)
+	}
+	return pass, nil
+}
+
+// recordRoutes saves the routes up to height done that were not processed before with status
+// parsed, tagged with source, marks their transfers processed and returns them. If the store
+// implements storage.NonceStorage, the nonces of the routes are claimed and routes reusing a nonce
+// are returned as replays instead.
+func recordRoutes(ctx context.Context, store storage.Storage, source string, routes []types.HyperlaneRoute, done int64) ([]types.HyperlaneRoute, []Replay, error) {
+	nonces, _ := store.(storage.NonceStorage)
+
+	var recorded []types.HyperlaneRoute
+	var replays []Replay
+	for _, route := range routes {
+		if route.BlockHeight > done {
+			continue
+		}
+		route.Source = source
+		processed, err := store.IsProcessed(ctx, route.TransferKey())
+		if err != nil {
+			return nil, nil, err
+		}
+		if processed {
+			continue
+		}
+
+		// Claim before marking processed: a claim is kept by its deposit if the pass is retried
+		var first *storage.Nonce
+		if nonce := route.Nonce(); nonce != "" && nonces != nil {
+			first, err = nonces.ClaimNonce(ctx, storage.Nonce{Sender: route.From, Nonce: nonce, TxHash: route.TxHash, Height: route.BlockHeight})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if err := store.SaveRoute(ctx, route, storage.RouteParsed); err != nil {
+			return nil, nil, err
+		}
+		if err := store.MarkProcessed(ctx, route.TransferKey(), route.BlockHeight); err != nil {
+			return nil, nil, err
+		}
+		if first != nil && !strings.EqualFold(first.TxHash, route.TxHash) {
+			replays = append(replays, Replay{Route: route, First: *first})
+			continue
+		}
+		recorded = append(recorded, route)
+	}
+	return recorded, replays, nil
+}
+
+// PendingRoutes returns the routes the store holds as parsed that were recorded for source, the
+// route set of one source chain waiting to be generated. A store shared by several sources holds
+// the routes of the others too.
+func PendingRoutes(ctx context.Context, store storage.Storage, source, multisigAddr string) (*types.Routes, error) {
+	records, err := store.RoutesByStatus(ctx, storage.RouteParsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load parsed routes: %w", err)
+	}
+
+	routes := &types.Routes{MultisigAddr: multisigAddr}
+	total := math.ZeroInt()
+	for _, record := range records {
+		if record.Route.Source != source {
+			continue
+		}
+		routes.Routes = append(routes.Routes, record.Route)
+		if amount, ok := math.NewIntFromString(record.Route.Amount); ok {
+			total = total.Add(amount)
+		}
+	}
+	routes.TotalAmount = total.String()
+	return routes, nil
+}
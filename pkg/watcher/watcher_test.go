@@ -0,0 +1,346 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/celestiaorg/celestia-rebalancer/pkg/parser"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/storage"
+	"github.com/celestiaorg/celestia-rebalancer/pkg/types"
+)
+
+// fakeScanner serves one deposit per height in deposits, failing the heights in failing. Deposits
+// at the heights in nonces are made by the same sender with the given metadata nonce. The deposit
+// transactions at the heights in transfers carry that many transfers instead of one.
+type fakeScanner struct {
+	latest    int64
+	deposits  map[int64]string
+	failing   map[int64]bool
+	nonces    map[int64]string
+	transfers map[int64]int
+}
+
+func (f *fakeScanner) LatestHeight() (int64, error) {
+	return f.latest, nil
+}
+
+func (f *fakeScanner) ParseRoutes(multisigAddr string, fromHeight, toHeight int64) (*parser.ParseResult, error) {
+	result := &parser.ParseResult{Routes: &types.Routes{MultisigAddr: multisigAddr}}
+	for height := fromHeight; height <= toHeight; height++ {
+		if f.failing[height] {
+			result.FailedHeights = append(result.FailedHeights, types.FailedHeight{Height: height, Error: "timeout"})
+			continue
+		}
+		if hash, ok := f.deposits[height]; ok {
+			route := types.HyperlaneRoute{TxHash: hash, BlockHeight: height, Amount: "100"}
+			if nonce, ok := f.nonces[height]; ok {
+				route.From = "celestia1alice"
+				route.RouteInfo = &types.RouteInfo{Nonce: nonce}
+			}
+			result.Routes.Routes = append(result.Routes.Routes, route)
+			for i := 1; i < f.transfers[height]; i++ {
+				route.MsgIndex = i
+				result.Routes.Routes = append(result.Routes.Routes, route)
+			}
+		}
+	}
+	return result, nil
+}
+
+func TestPollResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	scanner := &fakeScanner{latest: 105, deposits: map[int64]string{101: "A", 104: "B"}}
+	store := storage.NewMemory()
+
+	w, err := New(scanner, store, Config{Source: "celestia", MultisigAddr: "celestia1multisig", StartHeight: 100, MaxBlocks: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass.FromHeight != 100 || pass.ToHeight != 102 || len(pass.Routes) != 1 || pass.Routes[0].TxHash != "A" {
+		t.Fatalf("unexpected first pass %+v", pass)
+	}
+
+	// A new watcher on the same store continues after the checkpoint
+	w, err = New(scanner, store, Config{Source: "celestia", MultisigAddr: "celestia1multisig", StartHeight: 100, MaxBlocks: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass.FromHeight != 103 || pass.ToHeight != 105 || len(pass.Routes) != 1 || pass.Routes[0].TxHash != "B" {
+		t.Fatalf("unexpected second pass %+v", pass)
+	}
+
+	// Nothing new until the chain advances
+	pass, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass != nil {
+		t.Fatalf("expected no pass at the tip, got %+v", pass)
+	}
+
+	records, err := store.RoutesByStatus(ctx, storage.RouteParsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 parsed routes in the store, got %d", len(records))
+	}
+}
+
+func TestPollStopsBeforeFailedHeight(t *testing.T) {
+	ctx := context.Background()
+	scanner := &fakeScanner{
+		latest:   10,
+		deposits: map[int64]string{2: "A", 5: "B"},
+		failing:  map[int64]bool{4: true},
+	}
+	store := storage.NewMemory()
+
+	w, err := New(scanner, store, Config{Source: "celestia", MultisigAddr: "celestia1multisig", StartHeight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass.Checkpoint != 3 || len(pass.Routes) != 1 {
+		t.Fatalf("expected checkpoint 3 with one route, got %+v", pass)
+	}
+
+	// The failed height is retried, and the deposit after it is reported exactly once
+	delete(scanner.failing, 4)
+	pass, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pass.FromHeight != 4 || pass.Checkpoint != 10 || len(pass.Routes) != 1 || pass.Routes[0].TxHash != "B" {
+		t.Fatalf("unexpected retry pass %+v", pass)
+	}
+
+	checkpoint, err := store.Checkpoint(ctx, "celestia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 10 {
+		t.Errorf("expected checkpoint 10, got %d", checkpoint)
+	}
+}
+
+func TestPollSkipsProcessedDeposits(t *testing.T) {
+	ctx := context.Background()
+	scanner := &fakeScanner{latest: 3, deposits: map[int64]string{1: "A", 2: "B"}}
+	store := storage.NewMemory()
+	if err := store.MarkProcessed(ctx, "A", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(scanner, store, Config{Source: "celestia", MultisigAddr: "celestia1multisig", StartHeight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pass.Routes) != 1 || pass.Routes[0].TxHash != "B" {
+		t.Errorf("expected only the unprocessed deposit, got %v", pass.Routes)
+	}
+}
+
+func TestPollRecordsEveryTransferOfATransaction(t *testing.T) {
+	ctx := context.Background()
+	scanner := &fakeScanner{latest: 2, deposits: map[int64]string{1: "A", 2: "B"}, transfers: map[int64]int{1: 2}}
+	store := storage.NewMemory()
+
+	w, err := New(scanner, store, Config{Source: "celestia", MultisigAddr: "celestia1multisig", StartHeight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pass.Routes) != 3 || pass.Routes[0].TxHash != "A" || pass.Routes[1].TxHash != "A" || pass.Routes[1].MsgIndex != 1 {
+		t.Fatalf("expected both transfers of A and the one of B, got %+v", pass.Routes)
+	}
+
+	// Rescanning the heights finds every transfer processed
+	if err := store.SetCheckpoint(ctx, "celestia", 0); err != nil {
+		t.Fatal(err)
+	}
+	pass, err = w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pass.Routes) != 0 {
+		t.Errorf("expected no routes on a rescan, got %+v", pass.Routes)
+	}
+}
+
+func TestPollFlagsReplayedNonces(t *testing.T) {
+	ctx := context.Background()
+	scanner := &fakeScanner{
+		latest:   4,
+		deposits: map[int64]string{1: "A", 2: "B", 3: "C", 4: "D"},
+		nonces:   map[int64]string{1: "42", 2: "43", 3: "42"},
+	}
+	store := storage.NewMemory()
+
+	w, err := New(scanner, store, Config{Source: "celestia", MultisigAddr: "celestia1multisig", StartHeight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pass.Routes) != 3 {
+		t.Errorf("expected the deposits with unused or no nonces, got %v", pass.Routes)
+	}
+	if len(pass.Replays) != 1 || pass.Replays[0].Route.TxHash != "C" || pass.Replays[0].First.TxHash != "A" {
+		t.Fatalf("expected C flagged as replaying the nonce of A, got %+v", pass.Replays)
+	}
+	// Replays are recorded so they are not flagged again, and held back by quarantine downstream
+	if processed, err := store.IsProcessed(ctx, "C"); err != nil || !processed {
+		t.Errorf("replay not marked processed: %v, %v", processed, err)
+	}
+}
+
+func TestRunPassesOnTrigger(t *testing.T) {
+	scanner := &fakeScanner{latest: 100, deposits: map[int64]string{101: "A"}}
+	w, err := New(scanner, storage.NewMemory(), Config{Source: "celestia", MultisigAddr: "celestia1multisig", StartHeight: 100, PollInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passes := make(chan Pass, 1)
+	w.OnPass = func(pass Pass) { passes <- pass }
+	trigger := make(chan int64)
+	w.SetTrigger(trigger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	if pass := <-passes; pass.ToHeight != 100 {
+		t.Fatalf("unexpected first pass %+v", pass)
+	}
+
+	// Without the trigger, the next pass would only start after the hour long poll interval
+	scanner.latest = 101
+	trigger <- 101
+	select {
+	case pass := <-passes:
+		if pass.ToHeight != 101 || len(pass.Routes) != 1 || pass.Routes[0].TxHash != "A" {
+			t.Fatalf("unexpected triggered pass %+v", pass)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the trigger did not start a pass")
+	}
+}
+
+// chainScanner serves the deposits of one source chain to its multisig, skipping those its
+// config's whitelist rejects as the parser does
+type chainScanner struct {
+	config   *types.Config
+	multisig string
+	latest   int64
+	deposits map[int64]types.HyperlaneRoute
+}
+
+func (c *chainScanner) LatestHeight() (int64, error) {
+	return c.latest, nil
+}
+
+func (c *chainScanner) ParseRoutes(multisigAddr string, fromHeight, toHeight int64) (*parser.ParseResult, error) {
+	result := &parser.ParseResult{Routes: &types.Routes{MultisigAddr: multisigAddr}}
+	if multisigAddr != c.multisig {
+		return result, nil
+	}
+	for height := fromHeight; height <= toHeight; height++ {
+		route, ok := c.deposits[height]
+		if !ok {
+			continue
+		}
+		if err := c.config.ValidateRoute(route.RouteInfo); err != nil {
+			result.Skipped = append(result.Skipped, types.Skipped{TxHash: route.TxHash, BlockHeight: height, Reason: err.Error()})
+			continue
+		}
+		route.BlockHeight = height
+		result.Routes.Routes = append(result.Routes.Routes, route)
+	}
+	return result, nil
+}
+
+func TestPendingRoutesPerSource(t *testing.T) {
+	ctx := context.Background()
+	deposit := func(hash string, domain uint32, recipient string) types.HyperlaneRoute {
+		return types.HyperlaneRoute{TxHash: hash, Amount: "100", RouteInfo: &types.RouteInfo{DestinationDomain: domain, Recipient: recipient}}
+	}
+	evm := "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"
+	other := "0x1234567890123456789012345678901234567890"
+	config := &types.Config{
+		Whitelist: types.AddressWhitelist{Domains: map[uint32][]string{2340: {evm}}},
+		Sources: []types.SourceConfig{
+			{Name: "celestia", MultisigAddr: "celestia1multisig"},
+			{Name: "neutron", MultisigAddr: "neutron1multisig", Whitelist: &types.AddressWhitelist{Domains: map[uint32][]string{1: {other}}}},
+		},
+	}
+	scanners := map[string]*chainScanner{
+		// Each chain sees a deposit its own whitelist allows and one only the other's allows
+		"celestia": {multisig: "celestia1multisig", latest: 11, deposits: map[int64]types.HyperlaneRoute{
+			10: deposit("C1", 2340, evm), 11: deposit("C2", 1, other),
+		}},
+		"neutron": {multisig: "neutron1multisig", latest: 21, deposits: map[int64]types.HyperlaneRoute{
+			20: deposit("N1", 1, other), 21: deposit("N2", 2340, evm),
+		}},
+	}
+
+	store := storage.NewMemory()
+	for _, source := range config.Sources {
+		sourceConfig, _, err := config.ForSource(source.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := scanners[source.Name]
+		scanner.config = sourceConfig
+		w, err := New(scanner, store, Config{Source: source.Name, MultisigAddr: source.MultisigAddr, StartHeight: scanner.latest - 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pass, err := w.Poll(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pass.Routes) != 1 || len(pass.Skipped) != 1 {
+			t.Fatalf("%s pass = %+v, want one route and one deposit skipped by its whitelist", source.Name, pass)
+		}
+	}
+
+	for _, tt := range []struct {
+		source, multisig, want string
+	}{
+		{"celestia", "celestia1multisig", "C1"},
+		{"neutron", "neutron1multisig", "N1"},
+	} {
+		routes, err := PendingRoutes(ctx, store, tt.source, tt.multisig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if routes.MultisigAddr != tt.multisig || len(routes.Routes) != 1 || routes.Routes[0].TxHash != tt.want || routes.TotalAmount != "100" {
+			t.Errorf("PendingRoutes(%s) = %+v, want only %s", tt.source, routes, tt.want)
+		}
+	}
+}